@@ -83,19 +83,39 @@ func doJSONReq(method, url string, token string, body interface{}) (map[string]i
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
-	log.Println("Authenticating with Production API...")
 
-	loginBody := map[string]string{
-		"email":    "hemish.patel@inova.krd",
-		"password": "Testing123!",
-	}
-	authRes, err := doJSONReq("POST", APIBase+"/auth/login", "", loginBody)
-	if err != nil {
-		log.Fatalf("Login failed: %v", err)
+	// A service account key (DML_API_KEY=dml_...) is preferred over logging in as a person
+	token := os.Getenv("DML_API_KEY")
+	if token == "" {
+		log.Println("Authenticating with Production API...")
+
+		loginBody := map[string]string{
+			"email":    "hemish.patel@inova.krd",
+			"password": "Testing123!",
+		}
+		authRes, err := doJSONReq("POST", APIBase+"/auth/login", "", loginBody)
+		if err != nil {
+			log.Fatalf("Login failed: %v", err)
+		}
+		var ok bool
+		if token, ok = authRes["token"].(string); !ok {
+			log.Fatalf("Login did not return a token (MFA required?); set DML_API_KEY instead")
+		}
+
+		log.Println("Token acquired successfully.")
 	}
-	token := authRes["token"].(string)
 
-	log.Println("Token acquired successfully.")
+	// Job titles reference their grade by code, so the grades must exist first
+	for level := 1; level <= 5; level++ {
+		grade := map[string]interface{}{
+			"code":  fmt.Sprintf("GRADE-%d", level),
+			"name":  fmt.Sprintf("Grade %d", level),
+			"level": level,
+		}
+		if _, err := doJSONReq("POST", APIBase+"/job-grades", token, grade); err != nil {
+			log.Printf("Create JobGrade %s: %v (May already exist)", grade["code"], err)
+		}
+	}
 
 	// Fetch Job Titles and create them if they don't exist
 	jobTitlesToCreate := []map[string]string{
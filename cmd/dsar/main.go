@@ -0,0 +1,124 @@
+// Command dsar answers data subject requests for one employee from the command line: it
+// writes the subject access archive to a file, or with -erase pseudonymises the employee.
+// -actor names the user the audit entries are recorded against.
+//
+//	go run ./cmd/dsar -tenant TEN-UK-001 -employee <uuid> [-out archive.zip] [-actor dpo@example.com]
+//	go run ./cmd/dsar -tenant TEN-UK-001 -employee <uuid> -erase -confirm UK-00001 [-actor dpo@example.com]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/INOVA/DML/internal/config"
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/envelope"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/logic/privacy"
+	"github.com/INOVA/DML/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func main() {
+	tenantCode := flag.String("tenant", "", "code of the employee's tenant")
+	employee := flag.String("employee", "", "ID of the employee")
+	actorEmail := flag.String("actor", "", "email of the user to record in the audit log")
+	out := flag.String("out", "", "archive path (default dsar-<employee no>-<date>.zip)")
+	erase := flag.Bool("erase", false, "erase the employee instead of exporting")
+	confirm := flag.String("confirm", "", "the employee number, to confirm -erase")
+	flag.Parse()
+
+	if *tenantCode == "" || *employee == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	parsed, err := uuid.Parse(*employee)
+	if err != nil {
+		log.Fatalf("Invalid employee ID %q: %v", *employee, err)
+	}
+	employeeID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	cfg := config.Load()
+	if !*erase && cfg.DataKeysDir == "" {
+		log.Fatal("DATA_KEYS_DIR must be set; an ephemeral key cannot decrypt stored personal details")
+	}
+
+	keys, err := envelope.LoadKeyring(envelope.Options{
+		Dir:         cfg.DataKeysDir,
+		ActiveKeyID: cfg.DataKeyID,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load data encryption keys: %v", err)
+	}
+
+	ctx := context.Background()
+	database, err := db.New(ctx, cfg.DBDSN)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	q := domain.New(database.Pool)
+	tenant, err := q.GetTenantByCode(ctx, *tenantCode)
+	if err != nil {
+		log.Fatalf("Tenant %q not found: %v", *tenantCode, err)
+	}
+
+	var actorID pgtype.UUID
+	if *actorEmail != "" {
+		actor, err := q.GetUserByEmail(ctx, domain.GetUserByEmailParams{TenantID: tenant.ID, Email: *actorEmail})
+		if err != nil {
+			log.Fatalf("User %q not found: %v", *actorEmail, err)
+		}
+		actorID = actor.ID
+	}
+
+	auditSvc := audit.NewAuditService(database)
+	store := storage.NewLocalStore(cfg.StorageDir)
+	svc := privacy.NewPrivacyService(database, store, hr.NewPersonalDetailsService(database, keys, auditSvc), auditSvc)
+
+	if *erase {
+		erasure, err := svc.EraseEmployee(ctx, tenant.ID, actorID, employeeID, *confirm)
+		if err != nil {
+			log.Fatalf("Erasure failed: %v", err)
+		}
+		auditSvc.Close()
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(erasure); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	data, err := svc.CollectSubjectData(ctx, tenant.ID, actorID, employeeID)
+	if err != nil {
+		log.Fatalf("Subject access export failed: %v", err)
+	}
+	auditSvc.Close()
+
+	path := *out
+	if path == "" {
+		path = data.FileName()
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", path, err)
+	}
+	if err := svc.WriteArchive(ctx, f, data); err != nil {
+		f.Close()
+		os.Remove(path)
+		log.Fatalf("Failed to write archive: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Failed to write archive: %v", err)
+	}
+	log.Printf("Wrote subject access archive for employee %s to %s", data.Employee.EmployeeNo, path)
+}
@@ -0,0 +1,65 @@
+// Command grantrole grants a role to a user from the command line. The API only lets a
+// user grant roles whose permissions they hold themselves, so this is how a tenant gets
+// its first HR_ADMIN, or a new ADMIN when none is left.
+//
+//	go run ./cmd/grantrole -tenant TEN-UK-001 -user hr.lead@example.com [-role HR_ADMIN]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/INOVA/DML/internal/config"
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/iam"
+)
+
+func main() {
+	tenantCode := flag.String("tenant", "", "code of the user's tenant")
+	email := flag.String("user", "", "email of the user to grant the role to")
+	roleCode := flag.String("role", "HR_ADMIN", "code of the role to grant")
+	flag.Parse()
+
+	if *tenantCode == "" || *email == "" || *roleCode == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	ctx := context.Background()
+	database, err := db.New(ctx, cfg.DBDSN)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	q := domain.New(database.Pool)
+	tenant, err := q.GetTenantByCode(ctx, *tenantCode)
+	if err != nil {
+		log.Fatalf("Tenant %q not found: %v", *tenantCode, err)
+	}
+	user, err := q.GetUserByEmail(ctx, domain.GetUserByEmailParams{TenantID: tenant.ID, Email: *email})
+	if err != nil {
+		log.Fatalf("User %q not found: %v", *email, err)
+	}
+	if !user.IsActive {
+		log.Fatalf("User %q is deactivated", *email)
+	}
+	role, err := q.GetRoleByCode(ctx, domain.GetRoleByCodeParams{TenantID: tenant.ID, Code: *roleCode})
+	if err != nil {
+		log.Fatalf("Role %q not found: %v", *roleCode, err)
+	}
+
+	auditSvc := audit.NewAuditService(database)
+	if err := iam.NewUserRoleService(database, auditSvc).ProvisionUserRole(ctx, tenant.ID, user.ID, role.ID); err != nil {
+		log.Fatalf("Failed to grant %s: %v", role.Code, err)
+	}
+	auditSvc.Close()
+
+	log.Printf("Granted %s to %s in tenant %s", role.Code, user.Email, tenant.Code)
+}
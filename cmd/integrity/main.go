@@ -0,0 +1,92 @@
+// Command integrity runs the reporting hierarchy integrity checks for one or all tenants
+// and exits with status 1 when any issue is found, so it can be scheduled or used in CI.
+//
+//	go run ./cmd/integrity [-tenant TEN-UK-001] [-json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/INOVA/DML/internal/config"
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/logic/tenancy"
+)
+
+func main() {
+	tenantCode := flag.String("tenant", "", "only check the tenant with this code")
+	asJSON := flag.Bool("json", false, "print the reports as JSON")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	ctx := context.Background()
+	database, err := db.New(ctx, cfg.DBDSN)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	tenants, err := tenancy.NewService(database).ListTenants(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list tenants: %v", err)
+	}
+
+	empSvc := hr.NewEmployeeService(database, nil, nil)
+
+	var reports []hr.IntegrityReport
+	var selected []domain.Tenant
+	for _, t := range tenants {
+		if *tenantCode != "" && t.Code != *tenantCode {
+			continue
+		}
+		report, err := empSvc.CheckIntegrity(ctx, t.ID)
+		if err != nil {
+			log.Fatalf("Integrity check failed for tenant %s: %v", t.Code, err)
+		}
+		reports = append(reports, report)
+		selected = append(selected, t)
+	}
+
+	if *tenantCode != "" && len(selected) == 0 {
+		log.Fatalf("Tenant %q not found", *tenantCode)
+	}
+
+	issues := 0
+	for _, report := range reports {
+		issues += report.IssueCount
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatalf("Failed to encode reports: %v", err)
+		}
+	} else {
+		for i, report := range reports {
+			fmt.Printf("Tenant %s (%s): %d issue(s)\n", selected[i].Code, selected[i].Name, report.IssueCount)
+			if report.IssueCount == 0 {
+				continue
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "  CHECK\tEMPLOYEE\tNAME\tDETAIL")
+			for _, issue := range report.Issues {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", issue.Check, issue.EmployeeNo, issue.Name, issue.Detail)
+			}
+			tw.Flush()
+		}
+	}
+
+	if issues > 0 {
+		database.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,54 @@
+// Command rewrap moves the data keys of personal details and MFA secrets still wrapped
+// with a retired master key onto the active one, after a new key has been added to
+// DATA_KEYS_DIR. The old key file can be deleted once it reports that nothing is left to
+// re-wrap.
+//
+//	go run ./cmd/rewrap [-batch 500]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/INOVA/DML/internal/config"
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/envelope"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/logic/mfa"
+)
+
+func main() {
+	batch := flag.Int("batch", 500, "records to re-wrap per query")
+	flag.Parse()
+
+	cfg := config.Load()
+	if cfg.DataKeysDir == "" {
+		log.Fatal("DATA_KEYS_DIR must be set; an ephemeral key cannot unwrap stored data keys")
+	}
+
+	keys, err := envelope.LoadKeyring(envelope.Options{
+		Dir:         cfg.DataKeysDir,
+		ActiveKeyID: cfg.DataKeyID,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load data encryption keys: %v", err)
+	}
+
+	ctx := context.Background()
+	database, err := db.New(ctx, cfg.DBDSN)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	n, err := hr.NewPersonalDetailsService(database, keys, nil).RewrapKeys(ctx, int32(*batch))
+	if err != nil {
+		log.Fatalf("Re-wrapped %d data keys before failing: %v", n, err)
+	}
+	m, err := mfa.NewMFAService(database, keys, nil, nil, "").RewrapKeys(ctx, int32(*batch))
+	if err != nil {
+		log.Fatalf("Re-wrapped %d data keys before failing: %v", n+m, err)
+	}
+	log.Printf("Re-wrapped %d data keys with master key %s; nothing is left on older keys", n+m, keys.ActiveKeyID())
+}
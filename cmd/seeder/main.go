@@ -11,6 +11,7 @@ import (
 
 	"github.com/INOVA/DML/internal/config"
 	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/logic/audit"
 	"github.com/INOVA/DML/internal/logic/hr"
 	"github.com/INOVA/DML/internal/logic/iam"
@@ -88,10 +89,12 @@ func main() {
 
 	auditSvc := audit.NewAuditService(database)
 	_ = tenancy.NewService(database)
-	orgSvc := org.NewBusinessUnitService(database)
-	deptSvc := org.NewDepartmentService(database)
+	orgSvc := org.NewBusinessUnitService(database, auditSvc)
+	deptSvc := org.NewDepartmentService(database, auditSvc)
 	jobSvc := org.NewJobTitleService(database)
+	gradeSvc := org.NewJobGradeService(database)
 	roleSvc := iam.NewRoleService(database, auditSvc)
+	userRoleSvc := iam.NewUserRoleService(database, auditSvc)
 	onboardSvc := hr.NewOnboardingService(database, auditSvc)
 
 	// --- 1. Tenants & System Account (Get or Create) ---
@@ -139,7 +142,16 @@ func main() {
 	// Super Admin Role limits executing seeder queries natively mapping the system ID seamlessly
 	// --- 2. Roles ---
 	adminRole, _ := roleSvc.CreateRole(ctx, parseUUID(uuid.New().String()), tenant1.ID, sysUserUUID, "SYSTEM_ADMIN", "Super Administrator", nil)
-	hrRole, _ := roleSvc.CreateRole(ctx, parseUUID(uuid.New().String()), tenant1.ID, sysUserUUID, "HR_ADMIN", "Human Resources Admin", nil)
+	// HR_ADMIN grants permissions, so it comes with the tenant rather than from the API
+	hrRole, _ := domain.New(database.Pool).GetRoleByCode(ctx, domain.GetRoleByCodeParams{
+		TenantID: tenant1.ID,
+		Code:     "HR_ADMIN",
+	})
+	// Onboarding only grants roles the actor holds, so the system account needs HR_ADMIN to
+	// onboard the HR managers below
+	if err := userRoleSvc.ProvisionUserRole(ctx, tenant1.ID, sysUserUUID, hrRole.ID); err != nil {
+		log.Fatalf("Fatal: Failed to grant HR_ADMIN to the system account: %v", err)
+	}
 	mgrRole, _ := roleSvc.CreateRole(ctx, parseUUID(uuid.New().String()), tenant1.ID, sysUserUUID, "DEPT_MANAGER", "Departmental Manager", nil)
 	empRole, _ := roleSvc.CreateRole(ctx, parseUUID(uuid.New().String()), tenant1.ID, sysUserUUID, "EMPLOYEE", "Standard Employee", nil)
 
@@ -159,30 +171,48 @@ func main() {
 	deptQA, _ := deptSvc.CreateDepartment(ctx, parseUUID(uuid.New().String()), tenant1.ID, nil, "QA", "Quality")
 	deptHSE, _ := deptSvc.CreateDepartment(ctx, parseUUID(uuid.New().String()), tenant1.ID, nil, "HSE", "Safety")
 
-	// --- 5. Job Titles ---
-	jobCEO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CEO", "Chief Executive Officer", "GRADE-1")
-	jobCTO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CTO", "Chief Technology Officer", "GRADE-1")
-	jobCHRO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CHRO", "Chief HR Officer", "GRADE-1")
-
-	jobITDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-DIR", "Director of IT", "GRADE-2")
-	jobFinDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "FIN-DIR", "Director of Finance", "GRADE-2")
-
-	jobEngMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-MGR", "Engineering Manager", "GRADE-3")
-	jobHRMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HR-MGR", "HR Manager", "GRADE-3")
-
-	jobSrEng, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-SENG", "Senior Software Engineer", "GRADE-4")
-	jobEng, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-ENG", "Software Engineer", "GRADE-5")
-	jobHRBP, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HR-BP", "HR Business Partner", "GRADE-4")
-	jobAcc, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "FIN-ACC", "Accountant", "GRADE-5")
-
-	jobMfgMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MFG-MGR", "Manufacturing Manager", "GRADE-3")
-	jobMfgOp, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MFG-OP", "Machine Operator", "GRADE-5")
-	jobMntSup, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MNT-SUP", "Maintenance Supervisor", "GRADE-4")
-	jobMntTech, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MNT-TECH", "Maintenance Technician", "GRADE-5")
-	jobQaMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "QA-MGR", "Quality Assurance Manager", "GRADE-3")
-	jobQaInsp, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "QA-INSP", "QA Inspector", "GRADE-5")
-	jobHseDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HSE-DIR", "HSE Director", "GRADE-2")
-	jobHseCoord, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HSE-COORD", "Safety Coordinator", "GRADE-4")
+	// Which departments operate at which site; employees can only be placed in these combinations
+	siteDepartments := map[pgtype.UUID][]pgtype.UUID{
+		buLondon.ID: {deptExe.ID, deptHR.ID, deptFin.ID},
+		buMan.ID:    {deptIT.ID, deptFin.ID, deptMFG.ID, deptMNT.ID, deptQA.ID, deptHSE.ID},
+		buEdin.ID:   {deptIT.ID},
+	}
+	for buID, deptIDs := range siteDepartments {
+		for _, deptID := range deptIDs {
+			_, _ = orgSvc.LinkDepartment(ctx, tenant1.ID, sysUserUUID, buID, deptID)
+		}
+	}
+
+	// --- 5. Job Grades & Titles ---
+	grade1, _ := gradeSvc.CreateJobGrade(ctx, parseUUID(uuid.New().String()), tenant1.ID, "GRADE-1", "Executive", 1, org.PayBand{})
+	grade2, _ := gradeSvc.CreateJobGrade(ctx, parseUUID(uuid.New().String()), tenant1.ID, "GRADE-2", "Director", 2, org.PayBand{})
+	grade3, _ := gradeSvc.CreateJobGrade(ctx, parseUUID(uuid.New().String()), tenant1.ID, "GRADE-3", "Manager", 3, org.PayBand{})
+	grade4, _ := gradeSvc.CreateJobGrade(ctx, parseUUID(uuid.New().String()), tenant1.ID, "GRADE-4", "Senior Professional", 4, org.PayBand{})
+	grade5, _ := gradeSvc.CreateJobGrade(ctx, parseUUID(uuid.New().String()), tenant1.ID, "GRADE-5", "Professional", 5, org.PayBand{})
+
+	jobCEO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CEO", "Chief Executive Officer", grade1.ID, deptExe.ID)
+	jobCTO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CTO", "Chief Technology Officer", grade1.ID, deptExe.ID)
+	jobCHRO, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "EXEC-CHRO", "Chief HR Officer", grade1.ID, deptExe.ID)
+
+	jobITDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-DIR", "Director of IT", grade2.ID, deptIT.ID)
+	jobFinDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "FIN-DIR", "Director of Finance", grade2.ID, deptFin.ID)
+
+	jobEngMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-MGR", "Engineering Manager", grade3.ID, deptIT.ID)
+	jobHRMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HR-MGR", "HR Manager", grade3.ID, deptHR.ID)
+
+	jobSrEng, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-SENG", "Senior Software Engineer", grade4.ID, deptIT.ID)
+	jobEng, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "IT-ENG", "Software Engineer", grade5.ID, deptIT.ID)
+	jobHRBP, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HR-BP", "HR Business Partner", grade4.ID, deptHR.ID)
+	jobAcc, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "FIN-ACC", "Accountant", grade5.ID, deptFin.ID)
+
+	jobMfgMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MFG-MGR", "Manufacturing Manager", grade3.ID, deptMFG.ID)
+	jobMfgOp, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MFG-OP", "Machine Operator", grade5.ID, deptMFG.ID)
+	jobMntSup, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MNT-SUP", "Maintenance Supervisor", grade4.ID, deptMNT.ID)
+	jobMntTech, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "MNT-TECH", "Maintenance Technician", grade5.ID, deptMNT.ID)
+	jobQaMgr, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "QA-MGR", "Quality Assurance Manager", grade3.ID, deptQA.ID)
+	jobQaInsp, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "QA-INSP", "QA Inspector", grade5.ID, deptQA.ID)
+	jobHseDir, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HSE-DIR", "HSE Director", grade2.ID, deptHSE.ID)
+	jobHseCoord, _ := jobSvc.CreateJobTitle(ctx, parseUUID(uuid.New().String()), tenant1.ID, "HSE-COORD", "Safety Coordinator", grade4.ID, deptHSE.ID)
 
 	// --- 6. Executive Layer Onboarding ---
 	var existingItDirID string
@@ -3,16 +3,38 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	// AppEnv is "production" or anything else for development
+	AppEnv      string
 	APIPort     string
 	DBDSN       string
 	CORSOrigins []string
-	JWTSecret   string
+	StorageDir  string
+
+	// Session token signing
+	JWTKeysDir      string
+	JWTSigningKeyID string
+	JWTIssuer       string
+	JWTAudience     string
+
+	// Master keys for personal data encryption
+	DataKeysDir string
+	DataKeyID   string
+
+	// Outbound email
+	MailTransport string
+	MailFrom      string
+	MailDir       string
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
 }
 
 // Load loads environment variables into the Config struct.
@@ -45,17 +67,83 @@ func Load() *Config {
 		}
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		// VERY bad practice for prod, but acceptable warning for local scaffolding without it
-		log.Println("WARNING: JWT_SECRET environment variable is not set. Using insecure default secret!")
-		jwtSecret = "super-insecure-local-dev-secret-do-not-use"
+	appEnv := os.Getenv("APP_ENV")
+	if appEnv == "" {
+		appEnv = "development"
+	}
+
+	jwtKeysDir := os.Getenv("JWT_KEYS_DIR")
+	if jwtKeysDir == "" && appEnv == "production" {
+		// Without keys tokens would be signed with a throwaway key
+		log.Fatal("JWT_KEYS_DIR environment variable is strictly required in production")
+	}
+
+	dataKeysDir := os.Getenv("DATA_KEYS_DIR")
+	if dataKeysDir == "" && appEnv == "production" {
+		// Without keys personal data would be lost on restart
+		log.Fatal("DATA_KEYS_DIR environment variable is strictly required in production")
+	}
+
+	jwtIssuer := os.Getenv("JWT_ISSUER")
+	if jwtIssuer == "" {
+		jwtIssuer = "dml"
+	}
+
+	jwtAudience := os.Getenv("JWT_AUDIENCE")
+	if jwtAudience == "" {
+		jwtAudience = "dml-api"
+	}
+
+	storageDir := os.Getenv("STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "./data/storage" // exports and uploaded files
+	}
+
+	mailTransport := os.Getenv("MAIL_TRANSPORT")
+	if mailTransport == "" {
+		mailTransport = "log" // smtp | file | log
+	}
+
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "DML <no-reply@localhost>"
+	}
+
+	mailDir := os.Getenv("MAIL_DIR")
+	if mailDir == "" {
+		mailDir = "./data/mail" // used by the file transport
+	}
+
+	smtpPort := 587
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("SMTP_PORT must be a number, got %q", v)
+		}
+		smtpPort = port
 	}
 
 	return &Config{
+		AppEnv:      appEnv,
 		APIPort:     apiPort,
 		DBDSN:       dbDSN,
 		CORSOrigins: corsOrigins,
-		JWTSecret:   jwtSecret,
+		StorageDir:  storageDir,
+
+		JWTKeysDir:      jwtKeysDir,
+		JWTSigningKeyID: os.Getenv("JWT_SIGNING_KEY_ID"),
+		JWTIssuer:       jwtIssuer,
+		JWTAudience:     jwtAudience,
+
+		DataKeysDir: dataKeysDir,
+		DataKeyID:   os.Getenv("DATA_KEY_ID"),
+
+		MailTransport: mailTransport,
+		MailFrom:      mailFrom,
+		MailDir:       mailDir,
+		SMTPHost:      os.Getenv("SMTP_HOST"),
+		SMTPPort:      smtpPort,
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
 	}
 }
@@ -8,15 +8,98 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID               pgtype.UUID        `json:"id"`
+	TenantID         pgtype.UUID        `json:"tenant_id"`
+	ServiceAccountID pgtype.UUID        `json:"service_account_id"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	Name             string             `json:"name"`
+	Prefix           string             `json:"prefix"`
+	KeyHash          string             `json:"key_hash"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt       pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt        pgtype.Timestamptz `json:"revoked_at"`
+	CreatedByUserID  pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type ApiKeyRole struct {
+	ApiKeyID pgtype.UUID `json:"api_key_id"`
+	RoleID   pgtype.UUID `json:"role_id"`
+}
+
+type AuditChecklistItem struct {
+	ID               pgtype.UUID        `json:"id"`
+	TenantID         pgtype.UUID        `json:"tenant_id"`
+	AuditID          pgtype.UUID        `json:"audit_id"`
+	Position         int32              `json:"position"`
+	Clause           pgtype.Text        `json:"clause"`
+	Question         string             `json:"question"`
+	Result           pgtype.Text        `json:"result"`
+	Notes            pgtype.Text        `json:"notes"`
+	AnsweredByUserID pgtype.UUID        `json:"answered_by_user_id"`
+	AnsweredAt       pgtype.Timestamptz `json:"answered_at"`
+}
+
+type AuditFinding struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	AuditID         pgtype.UUID        `json:"audit_id"`
+	ChecklistItemID pgtype.UUID        `json:"checklist_item_id"`
+	Classification  string             `json:"classification"`
+	Clause          pgtype.Text        `json:"clause"`
+	Description     string             `json:"description"`
+	Evidence        pgtype.Text        `json:"evidence"`
+	NcrID           pgtype.UUID        `json:"ncr_id"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
 type AuditLog struct {
-	ID         pgtype.UUID        `json:"id"`
-	TenantID   pgtype.UUID        `json:"tenant_id"`
-	ActorID    pgtype.UUID        `json:"actor_id"`
-	Action     string             `json:"action"`
-	EntityType string             `json:"entity_type"`
-	EntityID   pgtype.UUID        `json:"entity_id"`
-	Changes    []byte             `json:"changes"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	ActorID        pgtype.UUID        `json:"actor_id"`
+	Action         string             `json:"action"`
+	EntityType     string             `json:"entity_type"`
+	EntityID       pgtype.UUID        `json:"entity_id"`
+	Changes        []byte             `json:"changes"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ActorApiKeyID  pgtype.UUID        `json:"actor_api_key_id"`
+	ImpersonatorID pgtype.UUID        `json:"impersonator_id"`
+}
+
+type AuditProgramme struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Name            string             `json:"name"`
+	Year            int32              `json:"year"`
+	Objectives      pgtype.Text        `json:"objectives"`
+	IsActive        bool               `json:"is_active"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BackgroundJob struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Kind            string             `json:"kind"`
+	Status          string             `json:"status"`
+	Progress        int32              `json:"progress"`
+	Total           int32              `json:"total"`
+	Result          []byte             `json:"result"`
+	Error           pgtype.Text        `json:"error"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	FinishedAt      pgtype.Timestamptz `json:"finished_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	FileKey         pgtype.Text        `json:"file_key"`
+	FileName        pgtype.Text        `json:"file_name"`
+	ContentType     pgtype.Text        `json:"content_type"`
+	LeaseOwner      pgtype.Text        `json:"lease_owner"`
+	LeaseExpiresAt  pgtype.Timestamptz `json:"lease_expires_at"`
 }
 
 type BusinessLine struct {
@@ -39,6 +122,54 @@ type BusinessUnit struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type BusinessUnitDepartment struct {
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	BusinessUnitID  pgtype.UUID        `json:"business_unit_id"`
+	DepartmentID    pgtype.UUID        `json:"department_id"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type Competency struct {
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	Code           string             `json:"code"`
+	Name           string             `json:"name"`
+	Description    pgtype.Text        `json:"description"`
+	Kind           string             `json:"kind"`
+	DocumentRef    pgtype.Text        `json:"document_ref"`
+	ValidityMonths pgtype.Int4        `json:"validity_months"`
+	IsActive       bool               `json:"is_active"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CustomFieldDefinition struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	EntityType      string             `json:"entity_type"`
+	Key             string             `json:"key"`
+	Label           string             `json:"label"`
+	FieldType       string             `json:"field_type"`
+	IsRequired      bool               `json:"is_required"`
+	Options         []byte             `json:"options"`
+	Validation      []byte             `json:"validation"`
+	SortOrder       int32              `json:"sort_order"`
+	IsActive        bool               `json:"is_active"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CustomFieldValue struct {
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	EntityType      string             `json:"entity_type"`
+	EntityID        pgtype.UUID        `json:"entity_id"`
+	FieldValues     []byte             `json:"field_values"`
+	UpdatedByUserID pgtype.UUID        `json:"updated_by_user_id"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Department struct {
 	ID                 pgtype.UUID        `json:"id"`
 	TenantID           pgtype.UUID        `json:"tenant_id"`
@@ -50,6 +181,26 @@ type Department struct {
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
+type EmailOutbox struct {
+	ID            pgtype.UUID        `json:"id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	Kind          string             `json:"kind"`
+	ToAddress     string             `json:"to_address"`
+	Subject       string             `json:"subject"`
+	HtmlBody      string             `json:"html_body"`
+	TextBody      string             `json:"text_body"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     pgtype.Text        `json:"last_error"`
+	SentAt        pgtype.Timestamptz `json:"sent_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	EntityType    pgtype.Text        `json:"entity_type"`
+	EntityID      pgtype.UUID        `json:"entity_id"`
+}
+
 type Employee struct {
 	ID             pgtype.UUID        `json:"id"`
 	TenantID       pgtype.UUID        `json:"tenant_id"`
@@ -66,6 +217,8 @@ type Employee struct {
 	DepartmentID   pgtype.UUID        `json:"department_id"`
 	JobTitleID     pgtype.UUID        `json:"job_title_id"`
 	ManagerID      pgtype.UUID        `json:"manager_id"`
+	ErasedAt       pgtype.Timestamptz `json:"erased_at"`
+	ErasedByUserID pgtype.UUID        `json:"erased_by_user_id"`
 }
 
 type EmployeeAssignment struct {
@@ -84,17 +237,218 @@ type EmployeeAssignment struct {
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
 }
 
-type JobTitle struct {
+type EmployeeChangeRequest struct {
+	ID                 pgtype.UUID        `json:"id"`
+	TenantID           pgtype.UUID        `json:"tenant_id"`
+	EmployeeID         pgtype.UUID        `json:"employee_id"`
+	Changes            []byte             `json:"changes"`
+	Reason             pgtype.Text        `json:"reason"`
+	ApproverEmployeeID pgtype.UUID        `json:"approver_employee_id"`
+	ApproverRoleCode   pgtype.Text        `json:"approver_role_code"`
+	Status             string             `json:"status"`
+	DecisionNotes      pgtype.Text        `json:"decision_notes"`
+	DecidedByUserID    pgtype.UUID        `json:"decided_by_user_id"`
+	DecidedAt          pgtype.Timestamptz `json:"decided_at"`
+	RequestedByUserID  pgtype.UUID        `json:"requested_by_user_id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+}
+
+type EmployeeCompetency struct {
+	ID               pgtype.UUID        `json:"id"`
+	TenantID         pgtype.UUID        `json:"tenant_id"`
+	EmployeeID       pgtype.UUID        `json:"employee_id"`
+	CompetencyID     pgtype.UUID        `json:"competency_id"`
+	AchievedOn       pgtype.Date        `json:"achieved_on"`
+	ExpiresOn        pgtype.Date        `json:"expires_on"`
+	Source           string             `json:"source"`
+	Notes            pgtype.Text        `json:"notes"`
+	RecordedByUserID pgtype.UUID        `json:"recorded_by_user_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type EmployeePersonalDetail struct {
+	EmployeeID        pgtype.UUID        `json:"employee_id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	KeyID             string             `json:"key_id"`
+	WrappedKey        []byte             `json:"wrapped_key"`
+	PersonalEmail     []byte             `json:"personal_email"`
+	Phone             []byte             `json:"phone"`
+	Address           []byte             `json:"address"`
+	DateOfBirth       []byte             `json:"date_of_birth"`
+	EmergencyContacts []byte             `json:"emergency_contacts"`
+	NiNumber          []byte             `json:"ni_number"`
+	UpdatedByUserID   pgtype.UUID        `json:"updated_by_user_id"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type InternalAudit struct {
+	ID                    pgtype.UUID        `json:"id"`
+	TenantID              pgtype.UUID        `json:"tenant_id"`
+	ProgrammeID           pgtype.UUID        `json:"programme_id"`
+	Title                 string             `json:"title"`
+	Scope                 pgtype.Text        `json:"scope"`
+	Criteria              pgtype.Text        `json:"criteria"`
+	BusinessUnitID        pgtype.UUID        `json:"business_unit_id"`
+	DepartmentID          pgtype.UUID        `json:"department_id"`
+	LeadAuditorEmployeeID pgtype.UUID        `json:"lead_auditor_employee_id"`
+	PlannedStart          pgtype.Date        `json:"planned_start"`
+	PlannedEnd            pgtype.Date        `json:"planned_end"`
+	Status                string             `json:"status"`
+	StartedAt             pgtype.Timestamptz `json:"started_at"`
+	CompletedAt           pgtype.Timestamptz `json:"completed_at"`
+	Summary               pgtype.Text        `json:"summary"`
+	CreatedByUserID       pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+type InternalAuditTeam struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	AuditID    pgtype.UUID `json:"audit_id"`
+	EmployeeID pgtype.UUID `json:"employee_id"`
+	Role       string      `json:"role"`
+}
+
+type JobGrade struct {
 	ID        pgtype.UUID        `json:"id"`
 	TenantID  pgtype.UUID        `json:"tenant_id"`
-	Code      pgtype.Text        `json:"code"`
+	Code      string             `json:"code"`
 	Name      string             `json:"name"`
-	Grade     pgtype.Text        `json:"grade"`
+	Level     int32              `json:"level"`
+	Currency  string             `json:"currency"`
+	MinPay    pgtype.Numeric     `json:"min_pay"`
+	MidPay    pgtype.Numeric     `json:"mid_pay"`
+	MaxPay    pgtype.Numeric     `json:"max_pay"`
 	IsActive  bool               `json:"is_active"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type JobTitle struct {
+	ID                  pgtype.UUID        `json:"id"`
+	TenantID            pgtype.UUID        `json:"tenant_id"`
+	Code                pgtype.Text        `json:"code"`
+	Name                string             `json:"name"`
+	IsActive            bool               `json:"is_active"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	GradeID             pgtype.UUID        `json:"grade_id"`
+	DefaultDepartmentID pgtype.UUID        `json:"default_department_id"`
+}
+
+type JobTitleCompetency struct {
+	TenantID     pgtype.UUID        `json:"tenant_id"`
+	JobTitleID   pgtype.UUID        `json:"job_title_id"`
+	CompetencyID pgtype.UUID        `json:"competency_id"`
+	IsMandatory  bool               `json:"is_mandatory"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type MfaRequiredRole struct {
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Ncr struct {
+	ID                   pgtype.UUID        `json:"id"`
+	TenantID             pgtype.UUID        `json:"tenant_id"`
+	Number               int32              `json:"number"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	BusinessUnitID       pgtype.UUID        `json:"business_unit_id"`
+	DepartmentID         pgtype.UUID        `json:"department_id"`
+	ReportedByEmployeeID pgtype.UUID        `json:"reported_by_employee_id"`
+	OwnerEmployeeID      pgtype.UUID        `json:"owner_employee_id"`
+	DetectedOn           pgtype.Date        `json:"detected_on"`
+	Severity             string             `json:"severity"`
+	Classification       string             `json:"classification"`
+	Status               string             `json:"status"`
+	ContainmentAction    pgtype.Text        `json:"containment_action"`
+	RootCauseMethod      pgtype.Text        `json:"root_cause_method"`
+	RootCause            pgtype.Text        `json:"root_cause"`
+	VerifiedByUserID     pgtype.UUID        `json:"verified_by_user_id"`
+	VerifiedAt           pgtype.Timestamptz `json:"verified_at"`
+	VerificationNotes    pgtype.Text        `json:"verification_notes"`
+	IsEffective          pgtype.Bool        `json:"is_effective"`
+	ClosedAt             pgtype.Timestamptz `json:"closed_at"`
+	CreatedByUserID      pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NcrAction struct {
+	ID                 pgtype.UUID        `json:"id"`
+	TenantID           pgtype.UUID        `json:"tenant_id"`
+	NcrID              pgtype.UUID        `json:"ncr_id"`
+	Kind               string             `json:"kind"`
+	Description        string             `json:"description"`
+	AssigneeEmployeeID pgtype.UUID        `json:"assignee_employee_id"`
+	DueDate            pgtype.Date        `json:"due_date"`
+	Status             string             `json:"status"`
+	CompletedAt        pgtype.Timestamptz `json:"completed_at"`
+	CompletionNotes    pgtype.Text        `json:"completion_notes"`
+	CreatedByUserID    pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NcrStatusHistory struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	NcrID       pgtype.UUID        `json:"ncr_id"`
+	FromStatus  pgtype.Text        `json:"from_status"`
+	ToStatus    string             `json:"to_status"`
+	Comment     pgtype.Text        `json:"comment"`
+	ActorUserID pgtype.UUID        `json:"actor_user_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Notification struct {
+	ID         pgtype.UUID        `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Kind       string             `json:"kind"`
+	Title      string             `json:"title"`
+	Body       pgtype.Text        `json:"body"`
+	EntityType pgtype.Text        `json:"entity_type"`
+	EntityID   pgtype.UUID        `json:"entity_id"`
+	ReadAt     pgtype.Timestamptz `json:"read_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Kind      string             `json:"kind"`
+	InApp     bool               `json:"in_app"`
+	Email     bool               `json:"email"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NotificationTemplate struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Kind      string             `json:"kind"`
+	Locale    string             `json:"locale"`
+	Subject   string             `json:"subject"`
+	HtmlBody  string             `json:"html_body"`
+	TextBody  string             `json:"text_body"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type RbacRole struct {
 	ID          pgtype.UUID        `json:"id"`
 	TenantID    pgtype.UUID        `json:"tenant_id"`
@@ -104,6 +458,96 @@ type RbacRole struct {
 	IsActive    bool               `json:"is_active"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ExternalID  pgtype.Text        `json:"external_id"`
+}
+
+type ScimToken struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Name            string             `json:"name"`
+	TokenHash       string             `json:"token_hash"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	LastUsedAt      pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt       pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type ServiceAccount struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Name            string             `json:"name"`
+	Description     pgtype.Text        `json:"description"`
+	IsActive        bool               `json:"is_active"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SsoGroupMapping struct {
+	ID         pgtype.UUID        `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	ProviderID pgtype.UUID        `json:"provider_id"`
+	GroupName  string             `json:"group_name"`
+	RoleID     pgtype.UUID        `json:"role_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type SsoIdentity struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	ProviderID  pgtype.UUID        `json:"provider_id"`
+	Subject     string             `json:"subject"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	Email       pgtype.Text        `json:"email"`
+	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type SsoLoginState struct {
+	State        string             `json:"state"`
+	TenantID     pgtype.UUID        `json:"tenant_id"`
+	ProviderID   pgtype.UUID        `json:"provider_id"`
+	Nonce        string             `json:"nonce"`
+	CodeVerifier string             `json:"code_verifier"`
+	ReturnTo     pgtype.Text        `json:"return_to"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	BrowserHash  string             `json:"browser_hash"`
+}
+
+type SsoProvider struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Protocol        string             `json:"protocol"`
+	Issuer          string             `json:"issuer"`
+	ClientID        string             `json:"client_id"`
+	ClientSecret    string             `json:"client_secret"`
+	Scopes          string             `json:"scopes"`
+	GroupsClaim     string             `json:"groups_claim"`
+	JitProvisioning bool               `json:"jit_provisioning"`
+	IsActive        bool               `json:"is_active"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Task struct {
+	ID                 pgtype.UUID        `json:"id"`
+	TenantID           pgtype.UUID        `json:"tenant_id"`
+	EntityType         string             `json:"entity_type"`
+	EntityID           pgtype.UUID        `json:"entity_id"`
+	Kind               string             `json:"kind"`
+	Title              string             `json:"title"`
+	Description        pgtype.Text        `json:"description"`
+	AssigneeEmployeeID pgtype.UUID        `json:"assignee_employee_id"`
+	AssigneeRoleCode   pgtype.Text        `json:"assignee_role_code"`
+	DueDate            pgtype.Date        `json:"due_date"`
+	Status             string             `json:"status"`
+	Outcome            pgtype.Text        `json:"outcome"`
+	CompletedByUserID  pgtype.UUID        `json:"completed_by_user_id"`
+	CompletedAt        pgtype.Timestamptz `json:"completed_at"`
+	CreatedByUserID    pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Tenant struct {
@@ -113,6 +557,58 @@ type Tenant struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type TrainingCourse struct {
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	Code           string             `json:"code"`
+	Name           string             `json:"name"`
+	Description    pgtype.Text        `json:"description"`
+	CompetencyID   pgtype.UUID        `json:"competency_id"`
+	ValidityMonths pgtype.Int4        `json:"validity_months"`
+	IsMandatory    bool               `json:"is_mandatory"`
+	IsActive       bool               `json:"is_active"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TrainingRecord struct {
+	ID                   pgtype.UUID        `json:"id"`
+	TenantID             pgtype.UUID        `json:"tenant_id"`
+	EmployeeID           pgtype.UUID        `json:"employee_id"`
+	CourseID             pgtype.UUID        `json:"course_id"`
+	SessionID            pgtype.UUID        `json:"session_id"`
+	CompletedOn          pgtype.Date        `json:"completed_on"`
+	ExpiresOn            pgtype.Date        `json:"expires_on"`
+	Result               string             `json:"result"`
+	Notes                pgtype.Text        `json:"notes"`
+	EvidenceKey          pgtype.Text        `json:"evidence_key"`
+	EvidenceFileName     pgtype.Text        `json:"evidence_file_name"`
+	EvidenceContentType  pgtype.Text        `json:"evidence_content_type"`
+	EvidenceSize         pgtype.Int8        `json:"evidence_size"`
+	SignedOffByUserID    pgtype.UUID        `json:"signed_off_by_user_id"`
+	SignedOffAt          pgtype.Timestamptz `json:"signed_off_at"`
+	EmployeeCompetencyID pgtype.UUID        `json:"employee_competency_id"`
+	RecordedByUserID     pgtype.UUID        `json:"recorded_by_user_id"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TrainingSession struct {
+	ID                pgtype.UUID        `json:"id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	CourseID          pgtype.UUID        `json:"course_id"`
+	TrainerEmployeeID pgtype.UUID        `json:"trainer_employee_id"`
+	ExternalTrainer   pgtype.Text        `json:"external_trainer"`
+	BusinessUnitID    pgtype.UUID        `json:"business_unit_id"`
+	Location          pgtype.Text        `json:"location"`
+	StartsAt          pgtype.Timestamptz `json:"starts_at"`
+	EndsAt            pgtype.Timestamptz `json:"ends_at"`
+	Status            string             `json:"status"`
+	CreatedByUserID   pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
 type User struct {
 	ID           pgtype.UUID        `json:"id"`
 	TenantID     pgtype.UUID        `json:"tenant_id"`
@@ -124,6 +620,25 @@ type User struct {
 	LastLoginAt  pgtype.Timestamptz `json:"last_login_at"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Locale       string             `json:"locale"`
+	ExternalID   pgtype.Text        `json:"external_id"`
+	AvatarUrl    pgtype.Text        `json:"avatar_url"`
+	Preferences  []byte             `json:"preferences"`
+}
+
+type UserMfa struct {
+	UserID          pgtype.UUID        `json:"user_id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Secret          pgtype.Text        `json:"secret"`
+	ConfirmedAt     pgtype.Timestamptz `json:"confirmed_at"`
+	LastUsedStep    int64              `json:"last_used_step"`
+	FailedAttempts  int32              `json:"failed_attempts"`
+	LockedUntil     pgtype.Timestamptz `json:"locked_until"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	KeyID           pgtype.Text        `json:"key_id"`
+	WrappedKey      []byte             `json:"wrapped_key"`
+	EncryptedSecret []byte             `json:"encrypted_secret"`
 }
 
 type UserRbacRole struct {
@@ -135,3 +650,35 @@ type UserRbacRole struct {
 	GrantedAt       pgtype.Timestamptz `json:"granted_at"`
 	GrantedByUserID pgtype.UUID        `json:"granted_by_user_id"`
 }
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	EndpointID     pgtype.UUID        `json:"endpoint_id"`
+	EventID        pgtype.UUID        `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	ResponseBody   pgtype.Text        `json:"response_body"`
+	LastError      pgtype.Text        `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	ReplayOfID     pgtype.UUID        `json:"replay_of_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type WebhookEndpoint struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	Url             string             `json:"url"`
+	Description     pgtype.Text        `json:"description"`
+	Secret          string             `json:"secret"`
+	Events          []string           `json:"events"`
+	IsActive        bool               `json:"is_active"`
+	CreatedByUserID pgtype.UUID        `json:"created_by_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
@@ -11,43 +11,322 @@ import (
 )
 
 type Querier interface {
+	AddApiKeyRole(ctx context.Context, arg AddApiKeyRoleParams) error
+	AddInternalAuditTeamMember(ctx context.Context, arg AddInternalAuditTeamMemberParams) error
+	AddJobTitleRequirement(ctx context.Context, arg AddJobTitleRequirementParams) error
+	AddMfaRequiredRole(ctx context.Context, arg AddMfaRequiredRoleParams) error
 	AssignUserRole(ctx context.Context, arg AssignUserRoleParams) error
+	ClaimDueEmails(ctx context.Context, arg ClaimDueEmailsParams) ([]EmailOutbox, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ClaimMfaAttempt(ctx context.Context, arg ClaimMfaAttemptParams) (UserMfa, error)
+	ClearMfaRequiredRoles(ctx context.Context, tenantID pgtype.UUID) error
+	CloseEntityTasks(ctx context.Context, arg CloseEntityTasksParams) error
+	CloseTask(ctx context.Context, arg CloseTaskParams) (Task, error)
+	CompleteBackgroundJob(ctx context.Context, arg CompleteBackgroundJobParams) error
+	ConfirmMfaEnrolment(ctx context.Context, arg ConfirmMfaEnrolmentParams) (UserMfa, error)
+	ConsumeSsoLoginState(ctx context.Context, arg ConsumeSsoLoginStateParams) (SsoLoginState, error)
+	CountActiveEmployeesByDepartment(ctx context.Context, tenantID pgtype.UUID) ([]CountActiveEmployeesByDepartmentRow, error)
+	CountActiveRoleHolders(ctx context.Context, arg CountActiveRoleHoldersParams) (int64, error)
 	CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error)
+	CountAuditProgrammes(ctx context.Context, arg CountAuditProgrammesParams) (int64, error)
+	CountBackgroundJobs(ctx context.Context, arg CountBackgroundJobsParams) (int64, error)
+	CountBusinessUnitDepartmentLinks(ctx context.Context, arg CountBusinessUnitDepartmentLinksParams) (int64, error)
 	CountBusinessUnits(ctx context.Context, arg CountBusinessUnitsParams) (int64, error)
+	CountCompetencies(ctx context.Context, arg CountCompetenciesParams) (int64, error)
 	CountDepartments(ctx context.Context, arg CountDepartmentsParams) (int64, error)
+	CountEmailOutbox(ctx context.Context, arg CountEmailOutboxParams) (int64, error)
+	CountEmployeeChangeRequests(ctx context.Context, arg CountEmployeeChangeRequestsParams) (int64, error)
 	CountEmployees(ctx context.Context, arg CountEmployeesParams) (int64, error)
+	CountEmployeesAtBusinessUnitDepartment(ctx context.Context, arg CountEmployeesAtBusinessUnitDepartmentParams) (int64, error)
+	CountInternalAudits(ctx context.Context, arg CountInternalAuditsParams) (int64, error)
+	CountJobGrades(ctx context.Context, arg CountJobGradesParams) (int64, error)
 	CountJobTitles(ctx context.Context, arg CountJobTitlesParams) (int64, error)
+	CountNCRs(ctx context.Context, arg CountNCRsParams) (int64, error)
+	CountNotifications(ctx context.Context, arg CountNotificationsParams) (int64, error)
+	CountOtherActiveRoleHolders(ctx context.Context, arg CountOtherActiveRoleHoldersParams) (int64, error)
+	CountRolesByCode(ctx context.Context, arg CountRolesByCodeParams) (int64, error)
+	CountTrainingCourses(ctx context.Context, arg CountTrainingCoursesParams) (int64, error)
+	CountTrainingSessions(ctx context.Context, arg CountTrainingSessionsParams) (int64, error)
+	CountUnansweredChecklistItems(ctx context.Context, arg CountUnansweredChecklistItemsParams) (int64, error)
+	CountUnusedMfaRecoveryCodes(ctx context.Context, arg CountUnusedMfaRecoveryCodesParams) (int64, error)
+	CountUserTasks(ctx context.Context, arg CountUserTasksParams) (int64, error)
 	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
+	CountWebhookDeliveries(ctx context.Context, arg CountWebhookDeliveriesParams) (int64, error)
+	CountWebhookEndpoints(ctx context.Context, tenantID pgtype.UUID) (int64, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAuditChecklistItem(ctx context.Context, arg CreateAuditChecklistItemParams) (AuditChecklistItem, error)
+	CreateAuditFinding(ctx context.Context, arg CreateAuditFindingParams) (AuditFinding, error)
+	CreateAuditProgramme(ctx context.Context, arg CreateAuditProgrammeParams) (AuditProgramme, error)
+	CreateBackgroundJob(ctx context.Context, arg CreateBackgroundJobParams) (BackgroundJob, error)
 	CreateBusinessUnit(ctx context.Context, arg CreateBusinessUnitParams) (BusinessUnit, error)
+	CreateCompetency(ctx context.Context, arg CreateCompetencyParams) (Competency, error)
+	CreateCustomFieldDefinition(ctx context.Context, arg CreateCustomFieldDefinitionParams) (CustomFieldDefinition, error)
 	CreateDepartment(ctx context.Context, arg CreateDepartmentParams) (Department, error)
 	CreateEmployee(ctx context.Context, arg CreateEmployeeParams) (Employee, error)
+	CreateEmployeeChangeRequest(ctx context.Context, arg CreateEmployeeChangeRequestParams) (EmployeeChangeRequest, error)
+	CreateEmployeeCompetency(ctx context.Context, arg CreateEmployeeCompetencyParams) (EmployeeCompetency, error)
+	CreateInternalAudit(ctx context.Context, arg CreateInternalAuditParams) (InternalAudit, error)
+	CreateJobGrade(ctx context.Context, arg CreateJobGradeParams) (JobGrade, error)
 	CreateJobTitle(ctx context.Context, arg CreateJobTitleParams) (JobTitle, error)
+	CreateMfaRecoveryCode(ctx context.Context, arg CreateMfaRecoveryCodeParams) error
+	CreateNCR(ctx context.Context, arg CreateNCRParams) (Ncr, error)
+	CreateNCRAction(ctx context.Context, arg CreateNCRActionParams) (NcrAction, error)
+	CreateNCRStatusHistory(ctx context.Context, arg CreateNCRStatusHistoryParams) error
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) error
 	CreateRole(ctx context.Context, arg CreateRoleParams) (RbacRole, error)
+	CreateScimToken(ctx context.Context, arg CreateScimTokenParams) (ScimToken, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error)
+	CreateSsoGroupMapping(ctx context.Context, arg CreateSsoGroupMappingParams) (SsoGroupMapping, error)
+	CreateSsoIdentity(ctx context.Context, arg CreateSsoIdentityParams) (SsoIdentity, error)
+	CreateSsoLoginState(ctx context.Context, arg CreateSsoLoginStateParams) error
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error)
+	CreateTrainingCourse(ctx context.Context, arg CreateTrainingCourseParams) (TrainingCourse, error)
+	CreateTrainingRecord(ctx context.Context, arg CreateTrainingRecordParams) (TrainingRecord, error)
+	CreateTrainingSession(ctx context.Context, arg CreateTrainingSessionParams) (TrainingSession, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	DecideEmployeeChangeRequest(ctx context.Context, arg DecideEmployeeChangeRequestParams) (EmployeeChangeRequest, error)
+	DeleteAuditChecklistItems(ctx context.Context, arg DeleteAuditChecklistItemsParams) error
+	DeleteCustomFieldValues(ctx context.Context, arg DeleteCustomFieldValuesParams) (int64, error)
+	DeleteEmployeeChangeRequestEmails(ctx context.Context, arg DeleteEmployeeChangeRequestEmailsParams) (int64, error)
+	DeleteEmployeePersonalDetails(ctx context.Context, arg DeleteEmployeePersonalDetailsParams) (int64, error)
+	DeleteExpiredSsoLoginStates(ctx context.Context) error
+	DeleteInternalAuditTeam(ctx context.Context, arg DeleteInternalAuditTeamParams) error
+	DeleteJobTitleRequirements(ctx context.Context, arg DeleteJobTitleRequirementsParams) error
+	DeleteMfaRecoveryCodes(ctx context.Context, arg DeleteMfaRecoveryCodesParams) error
+	DeleteNotificationTemplate(ctx context.Context, arg DeleteNotificationTemplateParams) (int64, error)
+	DeleteQueuedBackgroundJob(ctx context.Context, id pgtype.UUID) error
+	DeleteRole(ctx context.Context, arg DeleteRoleParams) (int64, error)
+	DeleteSsoGroupMapping(ctx context.Context, arg DeleteSsoGroupMappingParams) (int64, error)
+	DeleteSsoProvider(ctx context.Context, tenantID pgtype.UUID) (int64, error)
+	DeleteUnlinkedEmailsMentioning(ctx context.Context, arg DeleteUnlinkedEmailsMentioningParams) (int64, error)
+	DeleteUserEmails(ctx context.Context, arg DeleteUserEmailsParams) (int64, error)
+	DeleteUserMfa(ctx context.Context, arg DeleteUserMfaParams) (int64, error)
+	DeleteUserNotificationPreferences(ctx context.Context, arg DeleteUserNotificationPreferencesParams) (int64, error)
+	DeleteUserNotifications(ctx context.Context, arg DeleteUserNotificationsParams) (int64, error)
+	DeleteUserSsoIdentities(ctx context.Context, arg DeleteUserSsoIdentitiesParams) (int64, error)
+	DeleteWebhookEndpoint(ctx context.Context, arg DeleteWebhookEndpointParams) (int64, error)
+	EncryptMfaSecret(ctx context.Context, arg EncryptMfaSecretParams) (int64, error)
+	EnqueueEmail(ctx context.Context, arg EnqueueEmailParams) error
+	EraseEmployee(ctx context.Context, arg EraseEmployeeParams) (Employee, error)
+	EraseUser(ctx context.Context, arg EraseUserParams) error
+	FailBackgroundJob(ctx context.Context, arg FailBackgroundJobParams) error
+	FailEmail(ctx context.Context, arg FailEmailParams) error
+	FailInterruptedBackgroundJobs(ctx context.Context) (int64, error)
+	FailWebhookDelivery(ctx context.Context, arg FailWebhookDeliveryParams) error
+	GetActiveApiKeyByHash(ctx context.Context, keyHash string) (GetActiveApiKeyByHashRow, error)
+	GetActiveScimTokenByHash(ctx context.Context, tokenHash string) (ScimToken, error)
+	GetAuditChecklistItem(ctx context.Context, arg GetAuditChecklistItemParams) (AuditChecklistItem, error)
+	GetAuditFinding(ctx context.Context, arg GetAuditFindingParams) (AuditFinding, error)
+	GetAuditFindingForUpdate(ctx context.Context, arg GetAuditFindingForUpdateParams) (AuditFinding, error)
+	GetAuditProgramme(ctx context.Context, arg GetAuditProgrammeParams) (AuditProgramme, error)
+	GetBackgroundJob(ctx context.Context, arg GetBackgroundJobParams) (BackgroundJob, error)
 	GetBusinessUnit(ctx context.Context, arg GetBusinessUnitParams) (BusinessUnit, error)
+	GetCompetency(ctx context.Context, arg GetCompetencyParams) (Competency, error)
+	GetCustomFieldDefinition(ctx context.Context, arg GetCustomFieldDefinitionParams) (CustomFieldDefinition, error)
+	GetCustomFieldValues(ctx context.Context, arg GetCustomFieldValuesParams) (CustomFieldValue, error)
+	GetCustomFieldValuesForUpdate(ctx context.Context, arg GetCustomFieldValuesForUpdateParams) (CustomFieldValue, error)
 	GetDepartment(ctx context.Context, arg GetDepartmentParams) (Department, error)
+	GetEmail(ctx context.Context, arg GetEmailParams) (EmailOutbox, error)
+	GetEmailRecipient(ctx context.Context, arg GetEmailRecipientParams) (GetEmailRecipientRow, error)
 	GetEmployee(ctx context.Context, arg GetEmployeeParams) (Employee, error)
-	GetEmployeeHierarchy(ctx context.Context, arg GetEmployeeHierarchyParams) ([]GetEmployeeHierarchyRow, error)
+	GetEmployeeChainOfCommand(ctx context.Context, arg GetEmployeeChainOfCommandParams) ([]GetEmployeeChainOfCommandRow, error)
+	GetEmployeeChangeRequest(ctx context.Context, arg GetEmployeeChangeRequestParams) (EmployeeChangeRequest, error)
+	GetEmployeeChangeRequestForUpdate(ctx context.Context, arg GetEmployeeChangeRequestForUpdateParams) (EmployeeChangeRequest, error)
+	GetEmployeeForUpdate(ctx context.Context, arg GetEmployeeForUpdateParams) (Employee, error)
+	GetEmployeePersonalDetails(ctx context.Context, arg GetEmployeePersonalDetailsParams) (EmployeePersonalDetail, error)
+	GetEmployeePersonalDetailsForUpdate(ctx context.Context, arg GetEmployeePersonalDetailsForUpdateParams) (EmployeePersonalDetail, error)
+	GetEmployeeSubtree(ctx context.Context, arg GetEmployeeSubtreeParams) ([]GetEmployeeSubtreeRow, error)
 	GetEmployeeWithDetails(ctx context.Context, arg GetEmployeeWithDetailsParams) (GetEmployeeWithDetailsRow, error)
+	GetInternalAudit(ctx context.Context, arg GetInternalAuditParams) (InternalAudit, error)
+	GetInternalAuditForUpdate(ctx context.Context, arg GetInternalAuditForUpdateParams) (InternalAudit, error)
+	GetJobGrade(ctx context.Context, arg GetJobGradeParams) (JobGrade, error)
+	GetJobGradeByCode(ctx context.Context, arg GetJobGradeByCodeParams) (JobGrade, error)
 	GetJobTitle(ctx context.Context, arg GetJobTitleParams) (JobTitle, error)
+	GetMfaLoginState(ctx context.Context, arg GetMfaLoginStateParams) (GetMfaLoginStateRow, error)
+	GetNCR(ctx context.Context, arg GetNCRParams) (Ncr, error)
+	GetNCRAction(ctx context.Context, arg GetNCRActionParams) (NcrAction, error)
+	GetNCRForUpdate(ctx context.Context, arg GetNCRForUpdateParams) (Ncr, error)
+	GetNotificationPreference(ctx context.Context, arg GetNotificationPreferenceParams) (NotificationPreference, error)
+	GetNotificationTemplate(ctx context.Context, arg GetNotificationTemplateParams) (NotificationTemplate, error)
 	GetRole(ctx context.Context, arg GetRoleParams) (RbacRole, error)
+	GetRoleByCode(ctx context.Context, arg GetRoleByCodeParams) (RbacRole, error)
+	GetScimUser(ctx context.Context, arg GetScimUserParams) (GetScimUserRow, error)
+	GetServiceAccount(ctx context.Context, arg GetServiceAccountParams) (ServiceAccount, error)
+	GetSsoIdentity(ctx context.Context, arg GetSsoIdentityParams) (SsoIdentity, error)
+	GetSsoProvider(ctx context.Context, tenantID pgtype.UUID) (SsoProvider, error)
+	GetTask(ctx context.Context, arg GetTaskParams) (Task, error)
 	GetTenant(ctx context.Context, id pgtype.UUID) (Tenant, error)
+	GetTenantByCode(ctx context.Context, code string) (Tenant, error)
+	GetTrainingCourse(ctx context.Context, arg GetTrainingCourseParams) (TrainingCourse, error)
+	GetTrainingRecord(ctx context.Context, arg GetTrainingRecordParams) (TrainingRecord, error)
+	GetTrainingSession(ctx context.Context, arg GetTrainingSessionParams) (TrainingSession, error)
+	GetUnlinkedEmployeeByEmail(ctx context.Context, arg GetUnlinkedEmployeeByEmailParams) (GetUnlinkedEmployeeByEmailRow, error)
 	GetUser(ctx context.Context, arg GetUserParams) (User, error)
 	GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error)
+	GetUserByEmailFold(ctx context.Context, arg GetUserByEmailFoldParams) (User, error)
+	GetUserByEmployee(ctx context.Context, arg GetUserByEmployeeParams) (User, error)
 	GetUserForLogin(ctx context.Context, email string) (User, error)
+	GetUserMfa(ctx context.Context, arg GetUserMfaParams) (UserMfa, error)
 	GetUserRoles(ctx context.Context, arg GetUserRolesParams) ([]string, error)
+	GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, arg GetWebhookEndpointParams) (WebhookEndpoint, error)
+	GrantUnscopedRole(ctx context.Context, arg GrantUnscopedRoleParams) (int64, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
+	LinkAuditFindingNCR(ctx context.Context, arg LinkAuditFindingNCRParams) (AuditFinding, error)
+	LinkBusinessUnitDepartment(ctx context.Context, arg LinkBusinessUnitDepartmentParams) (BusinessUnitDepartment, error)
+	ListActiveRoleUserIDs(ctx context.Context, arg ListActiveRoleUserIDsParams) ([]pgtype.UUID, error)
+	ListAllBusinessUnits(ctx context.Context, tenantID pgtype.UUID) ([]BusinessUnit, error)
+	ListAllDepartments(ctx context.Context, tenantID pgtype.UUID) ([]Department, error)
+	ListAllJobGrades(ctx context.Context, tenantID pgtype.UUID) ([]JobGrade, error)
+	ListAllJobTitles(ctx context.Context, tenantID pgtype.UUID) ([]JobTitle, error)
+	ListApiKeyRoleCodes(ctx context.Context, apiKeyID pgtype.UUID) ([]string, error)
+	ListApiKeyRoles(ctx context.Context, apiKeyIds []pgtype.UUID) ([]ListApiKeyRolesRow, error)
+	ListApiKeys(ctx context.Context, arg ListApiKeysParams) ([]ApiKey, error)
+	ListAuditCalendar(ctx context.Context, arg ListAuditCalendarParams) ([]ListAuditCalendarRow, error)
+	ListAuditChecklistItems(ctx context.Context, arg ListAuditChecklistItemsParams) ([]AuditChecklistItem, error)
+	ListAuditFindings(ctx context.Context, arg ListAuditFindingsParams) ([]AuditFinding, error)
 	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListAuditProgrammes(ctx context.Context, arg ListAuditProgrammesParams) ([]AuditProgramme, error)
+	ListBackgroundJobs(ctx context.Context, arg ListBackgroundJobsParams) ([]BackgroundJob, error)
+	ListBusinessUnitDepartmentMatrix(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitDepartmentMatrixRow, error)
+	ListBusinessUnitDepartmentRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitDepartmentRefsRow, error)
+	ListBusinessUnitDepartments(ctx context.Context, arg ListBusinessUnitDepartmentsParams) ([]ListBusinessUnitDepartmentsRow, error)
+	ListBusinessUnitRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitRefsRow, error)
 	ListBusinessUnits(ctx context.Context, arg ListBusinessUnitsParams) ([]BusinessUnit, error)
+	ListCompetencies(ctx context.Context, arg ListCompetenciesParams) ([]Competency, error)
+	ListCompetencyRequirementsForEmployees(ctx context.Context, arg ListCompetencyRequirementsForEmployeesParams) ([]ListCompetencyRequirementsForEmployeesRow, error)
+	ListCustomFieldDefinitions(ctx context.Context, arg ListCustomFieldDefinitionsParams) ([]CustomFieldDefinition, error)
+	ListCustomFieldMatches(ctx context.Context, arg ListCustomFieldMatchesParams) ([]pgtype.UUID, error)
+	ListDepartmentRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListDepartmentRefsRow, error)
 	ListDepartments(ctx context.Context, arg ListDepartmentsParams) ([]Department, error)
+	ListDirectReports(ctx context.Context, arg ListDirectReportsParams) ([]ListDirectReportsRow, error)
+	ListEmailOutbox(ctx context.Context, arg ListEmailOutboxParams) ([]EmailOutbox, error)
+	ListEmployeeChangeRequestTaskTitles(ctx context.Context, arg ListEmployeeChangeRequestTaskTitlesParams) ([]string, error)
+	ListEmployeeChangeRequests(ctx context.Context, arg ListEmployeeChangeRequestsParams) ([]EmployeeChangeRequest, error)
+	ListEmployeeCompetencies(ctx context.Context, arg ListEmployeeCompetenciesParams) ([]ListEmployeeCompetenciesRow, error)
+	ListEmployeeRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeeRefsRow, error)
+	ListEmployeeSubjectAuditLogs(ctx context.Context, arg ListEmployeeSubjectAuditLogsParams) ([]AuditLog, error)
+	ListEmployeeTrainingEvidence(ctx context.Context, arg ListEmployeeTrainingEvidenceParams) ([]ListEmployeeTrainingEvidenceRow, error)
+	ListEmployeeTrainingRecords(ctx context.Context, arg ListEmployeeTrainingRecordsParams) ([]ListEmployeeTrainingRecordsRow, error)
 	ListEmployees(ctx context.Context, arg ListEmployeesParams) ([]Employee, error)
+	ListEmployeesInInactiveOrgUnits(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeesInInactiveOrgUnitsRow, error)
 	ListEmployeesWithDetails(ctx context.Context, arg ListEmployeesWithDetailsParams) ([]ListEmployeesWithDetailsRow, error)
+	ListEmployeesWithForeignManager(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeesWithForeignManagerRow, error)
+	ListExpiringTrainingRecords(ctx context.Context, arg ListExpiringTrainingRecordsParams) ([]ListExpiringTrainingRecordsRow, error)
+	ListGrantableApiKeyRoles(ctx context.Context, arg ListGrantableApiKeyRolesParams) ([]ListGrantableApiKeyRolesRow, error)
+	ListInactiveManagersWithActiveReports(ctx context.Context, tenantID pgtype.UUID) ([]ListInactiveManagersWithActiveReportsRow, error)
+	ListInternalAuditTeam(ctx context.Context, arg ListInternalAuditTeamParams) ([]ListInternalAuditTeamRow, error)
+	ListInternalAudits(ctx context.Context, arg ListInternalAuditsParams) ([]ListInternalAuditsRow, error)
+	ListJobGrades(ctx context.Context, arg ListJobGradesParams) ([]JobGrade, error)
+	ListJobTitleRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListJobTitleRefsRow, error)
+	ListJobTitleRequirements(ctx context.Context, arg ListJobTitleRequirementsParams) ([]ListJobTitleRequirementsRow, error)
 	ListJobTitles(ctx context.Context, arg ListJobTitlesParams) ([]JobTitle, error)
+	ListLatestEmployeeCompetencies(ctx context.Context, arg ListLatestEmployeeCompetenciesParams) ([]ListLatestEmployeeCompetenciesRow, error)
+	ListMfaKeysNotWrappedWith(ctx context.Context, arg ListMfaKeysNotWrappedWithParams) ([]ListMfaKeysNotWrappedWithRow, error)
+	ListMfaRequiredRoles(ctx context.Context, tenantID pgtype.UUID) ([]ListMfaRequiredRolesRow, error)
+	ListNCRActions(ctx context.Context, arg ListNCRActionsParams) ([]ListNCRActionsRow, error)
+	ListNCRStatusHistory(ctx context.Context, arg ListNCRStatusHistoryParams) ([]NcrStatusHistory, error)
+	ListNCRs(ctx context.Context, arg ListNCRsParams) ([]ListNCRsRow, error)
+	ListNotificationPreferences(ctx context.Context, arg ListNotificationPreferencesParams) ([]NotificationPreference, error)
+	ListNotificationTemplates(ctx context.Context, tenantID pgtype.UUID) ([]NotificationTemplate, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListOrgChartNodes(ctx context.Context, arg ListOrgChartNodesParams) ([]ListOrgChartNodesRow, error)
+	ListPersonalDetailsKeysNotWrappedWith(ctx context.Context, arg ListPersonalDetailsKeysNotWrappedWithParams) ([]ListPersonalDetailsKeysNotWrappedWithRow, error)
+	ListPlaintextMfaSecrets(ctx context.Context, limit int32) ([]ListPlaintextMfaSecretsRow, error)
+	ListRoleMembers(ctx context.Context, arg ListRoleMembersParams) ([]ListRoleMembersRow, error)
 	ListRoles(ctx context.Context, tenantID pgtype.UUID) ([]RbacRole, error)
+	ListScimTokens(ctx context.Context, tenantID pgtype.UUID) ([]ScimToken, error)
+	ListScimUsers(ctx context.Context, tenantID pgtype.UUID) ([]ListScimUsersRow, error)
+	ListServiceAccounts(ctx context.Context, tenantID pgtype.UUID) ([]ServiceAccount, error)
+	ListSessionTrainingRecords(ctx context.Context, arg ListSessionTrainingRecordsParams) ([]ListSessionTrainingRecordsRow, error)
+	ListSsoGroupMappings(ctx context.Context, arg ListSsoGroupMappingsParams) ([]ListSsoGroupMappingsRow, error)
+	ListSubscribedWebhookEndpoints(ctx context.Context, arg ListSubscribedWebhookEndpointsParams) ([]WebhookEndpoint, error)
 	ListTenants(ctx context.Context) ([]Tenant, error)
+	ListTrainingCourses(ctx context.Context, arg ListTrainingCoursesParams) ([]TrainingCourse, error)
+	ListTrainingSessions(ctx context.Context, arg ListTrainingSessionsParams) ([]TrainingSession, error)
+	ListUserActorAuditLogs(ctx context.Context, arg ListUserActorAuditLogsParams) ([]AuditLog, error)
+	ListUserRoleCodes(ctx context.Context, tenantID pgtype.UUID) ([]ListUserRoleCodesRow, error)
+	ListUserRoleGrants(ctx context.Context, arg ListUserRoleGrantsParams) ([]ListUserRoleGrantsRow, error)
+	ListUserRoleRefs(ctx context.Context, arg ListUserRoleRefsParams) ([]ListUserRoleRefsRow, error)
+	ListUserTasks(ctx context.Context, arg ListUserTasksParams) ([]ListUserTasksRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, arg ListWebhookEndpointsParams) ([]WebhookEndpoint, error)
+	MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) (int64, error)
+	MarkBackgroundJobRunning(ctx context.Context, arg MarkBackgroundJobRunningParams) (int64, error)
+	MarkEmailSent(ctx context.Context, id pgtype.UUID) error
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error)
+	MarkNotificationUnread(ctx context.Context, arg MarkNotificationUnreadParams) (Notification, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error
+	NextNCRNumber(ctx context.Context, tenantID pgtype.UUID) (int32, error)
+	ReassignEntityTasks(ctx context.Context, arg ReassignEntityTasksParams) error
+	RecordAuditChecklistResult(ctx context.Context, arg RecordAuditChecklistResultParams) (AuditChecklistItem, error)
+	RecordMfaSuccess(ctx context.Context, arg RecordMfaSuccessParams) (int64, error)
+	RecordNCRVerification(ctx context.Context, arg RecordNCRVerificationParams) (Ncr, error)
+	RenewBackgroundJobLeases(ctx context.Context, arg RenewBackgroundJobLeasesParams) error
+	RequeueFailedEmail(ctx context.Context, arg RequeueFailedEmailParams) (EmailOutbox, error)
+	RescheduleEmail(ctx context.Context, arg RescheduleEmailParams) error
+	RescheduleWebhookDelivery(ctx context.Context, arg RescheduleWebhookDeliveryParams) error
+	ResetMfaFailures(ctx context.Context, arg ResetMfaFailuresParams) error
+	RevokeAllUserRoles(ctx context.Context, arg RevokeAllUserRolesParams) (int64, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (int64, error)
+	RevokeRoleFromAllUsers(ctx context.Context, arg RevokeRoleFromAllUsersParams) (int64, error)
+	RevokeScimToken(ctx context.Context, arg RevokeScimTokenParams) (int64, error)
+	RevokeUnscopedRole(ctx context.Context, arg RevokeUnscopedRoleParams) (int64, error)
+	RevokeUserApiKeys(ctx context.Context, arg RevokeUserApiKeysParams) (int64, error)
 	RevokeUserRole(ctx context.Context, arg RevokeUserRoleParams) error
+	RewrapEmployeePersonalDetailsKey(ctx context.Context, arg RewrapEmployeePersonalDetailsKeyParams) (int64, error)
+	RewrapUserMfaKey(ctx context.Context, arg RewrapUserMfaKeyParams) (int64, error)
+	ScrubEmployeeChangeRequestNotifications(ctx context.Context, arg ScrubEmployeeChangeRequestNotificationsParams) (int64, error)
+	ScrubEmployeeChangeRequestTasks(ctx context.Context, arg ScrubEmployeeChangeRequestTasksParams) (int64, error)
+	ScrubEmployeeChangeRequests(ctx context.Context, arg ScrubEmployeeChangeRequestsParams) (int64, error)
+	ScrubEmployeeCompetencies(ctx context.Context, arg ScrubEmployeeCompetenciesParams) (int64, error)
+	ScrubEmployeeTrainingRecords(ctx context.Context, arg ScrubEmployeeTrainingRecordsParams) (int64, error)
+	SetAuditLogChanges(ctx context.Context, arg SetAuditLogChangesParams) error
+	SetInternalAuditStatus(ctx context.Context, arg SetInternalAuditStatusParams) (InternalAudit, error)
+	SetNCRActionStatus(ctx context.Context, arg SetNCRActionStatusParams) (NcrAction, error)
+	SetTrainingRecordEvidence(ctx context.Context, arg SetTrainingRecordEvidenceParams) (TrainingRecord, error)
+	SetUserLocale(ctx context.Context, arg SetUserLocaleParams) error
+	SetWebhookDeliveryData(ctx context.Context, arg SetWebhookDeliveryDataParams) (int64, error)
+	SetWebhookEndpointSecret(ctx context.Context, arg SetWebhookEndpointSecretParams) (WebhookEndpoint, error)
+	SignOffTrainingRecord(ctx context.Context, arg SignOffTrainingRecordParams) (TrainingRecord, error)
+	StartMfaEnrolment(ctx context.Context, arg StartMfaEnrolmentParams) (UserMfa, error)
+	TouchApiKey(ctx context.Context, id pgtype.UUID) error
+	TouchScimToken(ctx context.Context, id pgtype.UUID) error
+	TouchSsoIdentity(ctx context.Context, arg TouchSsoIdentityParams) error
+	TouchUserLogin(ctx context.Context, id pgtype.UUID) error
+	UnlinkBusinessUnitDepartment(ctx context.Context, arg UnlinkBusinessUnitDepartmentParams) (int64, error)
+	UpdateAuditProgramme(ctx context.Context, arg UpdateAuditProgrammeParams) (AuditProgramme, error)
+	UpdateBackgroundJobProgress(ctx context.Context, arg UpdateBackgroundJobProgressParams) error
+	UpdateCompetency(ctx context.Context, arg UpdateCompetencyParams) (Competency, error)
+	UpdateCustomFieldDefinition(ctx context.Context, arg UpdateCustomFieldDefinitionParams) (CustomFieldDefinition, error)
+	UpdateDepartmentParent(ctx context.Context, arg UpdateDepartmentParentParams) (Department, error)
+	UpdateEmployeeDetails(ctx context.Context, arg UpdateEmployeeDetailsParams) (Employee, error)
+	UpdateEmployeeManager(ctx context.Context, arg UpdateEmployeeManagerParams) (Employee, error)
+	UpdateInternalAudit(ctx context.Context, arg UpdateInternalAuditParams) (InternalAudit, error)
+	UpdateJobGrade(ctx context.Context, arg UpdateJobGradeParams) (JobGrade, error)
+	UpdateJobTitle(ctx context.Context, arg UpdateJobTitleParams) (JobTitle, error)
+	UpdateNCR(ctx context.Context, arg UpdateNCRParams) (Ncr, error)
+	UpdateNCRAction(ctx context.Context, arg UpdateNCRActionParams) (NcrAction, error)
+	UpdateNCRRootCause(ctx context.Context, arg UpdateNCRRootCauseParams) (Ncr, error)
+	UpdateNCRStatus(ctx context.Context, arg UpdateNCRStatusParams) (Ncr, error)
+	UpdateScimEmployee(ctx context.Context, arg UpdateScimEmployeeParams) (Employee, error)
+	UpdateScimRole(ctx context.Context, arg UpdateScimRoleParams) (RbacRole, error)
+	UpdateScimUser(ctx context.Context, arg UpdateScimUserParams) (User, error)
+	UpdateServiceAccount(ctx context.Context, arg UpdateServiceAccountParams) (ServiceAccount, error)
+	UpdateTrainingCourse(ctx context.Context, arg UpdateTrainingCourseParams) (TrainingCourse, error)
+	UpdateTrainingSessionStatus(ctx context.Context, arg UpdateTrainingSessionStatusParams) (TrainingSession, error)
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error)
+	UpsertCustomFieldValues(ctx context.Context, arg UpsertCustomFieldValuesParams) (CustomFieldValue, error)
+	UpsertEmployeePersonalDetails(ctx context.Context, arg UpsertEmployeePersonalDetailsParams) (EmployeePersonalDetail, error)
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
+	UpsertNotificationTemplate(ctx context.Context, arg UpsertNotificationTemplateParams) (NotificationTemplate, error)
+	UpsertSsoProvider(ctx context.Context, arg UpsertSsoProviderParams) (SsoProvider, error)
+	UseMfaRecoveryCode(ctx context.Context, arg UseMfaRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,6 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addJobTitleRequirement = `-- name: AddJobTitleRequirement :exec
+INSERT INTO
+    job_title_competencies (
+        tenant_id,
+        job_title_id,
+        competency_id,
+        is_mandatory
+    )
+VALUES ($1, $2, $3, $4)
+`
+
+type AddJobTitleRequirementParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	JobTitleID   pgtype.UUID `json:"job_title_id"`
+	CompetencyID pgtype.UUID `json:"competency_id"`
+	IsMandatory  bool        `json:"is_mandatory"`
+}
+
+func (q *Queries) AddJobTitleRequirement(ctx context.Context, arg AddJobTitleRequirementParams) error {
+	_, err := q.db.Exec(ctx, addJobTitleRequirement,
+		arg.TenantID,
+		arg.JobTitleID,
+		arg.CompetencyID,
+		arg.IsMandatory,
+	)
+	return err
+}
+
 const assignUserRole = `-- name: AssignUserRole :exec
 INSERT INTO
     user_rbac_roles (
@@ -52,6 +80,42 @@ func (q *Queries) AssignUserRole(ctx context.Context, arg AssignUserRoleParams)
 	return err
 }
 
+const countActiveEmployeesByDepartment = `-- name: CountActiveEmployeesByDepartment :many
+SELECT department_id, count(*) AS headcount
+FROM employees
+WHERE
+    tenant_id = $1
+    AND is_active
+    AND department_id IS NOT NULL
+GROUP BY
+    department_id
+`
+
+type CountActiveEmployeesByDepartmentRow struct {
+	DepartmentID pgtype.UUID `json:"department_id"`
+	Headcount    int64       `json:"headcount"`
+}
+
+func (q *Queries) CountActiveEmployeesByDepartment(ctx context.Context, tenantID pgtype.UUID) ([]CountActiveEmployeesByDepartmentRow, error) {
+	rows, err := q.db.Query(ctx, countActiveEmployeesByDepartment, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountActiveEmployeesByDepartmentRow
+	for rows.Next() {
+		var i CountActiveEmployeesByDepartmentRow
+		if err := rows.Scan(&i.DepartmentID, &i.Headcount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countAuditLogs = `-- name: CountAuditLogs :one
 SELECT count(*)
 FROM audit_logs
@@ -80,6 +144,28 @@ func (q *Queries) CountAuditLogs(ctx context.Context, arg CountAuditLogsParams)
 	return count, err
 }
 
+const countBusinessUnitDepartmentLinks = `-- name: CountBusinessUnitDepartmentLinks :one
+SELECT count(*)
+FROM business_unit_departments
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3
+`
+
+type CountBusinessUnitDepartmentLinksParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	BusinessUnitID pgtype.UUID `json:"business_unit_id"`
+	DepartmentID   pgtype.UUID `json:"department_id"`
+}
+
+func (q *Queries) CountBusinessUnitDepartmentLinks(ctx context.Context, arg CountBusinessUnitDepartmentLinksParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countBusinessUnitDepartmentLinks, arg.TenantID, arg.BusinessUnitID, arg.DepartmentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countBusinessUnits = `-- name: CountBusinessUnits :one
 SELECT count(*)
 FROM business_units
@@ -90,15 +176,53 @@ WHERE
         OR name ILIKE '%' || $2::text || '%'
         OR code ILIKE '%' || $2::text || '%'
     )
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'business_unit'
+                AND cfv.entity_id = business_units.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
 `
 
 type CountBusinessUnitsParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
+}
+
+func (q *Queries) CountBusinessUnits(ctx context.Context, arg CountBusinessUnitsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countBusinessUnits, arg.TenantID, arg.Search, arg.CustomFields)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCompetencies = `-- name: CountCompetencies :one
+SELECT count(*)
+FROM competencies
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR name ILIKE '%' || $2::text || '%'
+        OR code ILIKE '%' || $2::text || '%'
+        OR document_ref ILIKE '%' || $2::text || '%'
+    )
+`
+
+type CountCompetenciesParams struct {
 	TenantID pgtype.UUID `json:"tenant_id"`
 	Search   string      `json:"search"`
 }
 
-func (q *Queries) CountBusinessUnits(ctx context.Context, arg CountBusinessUnitsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countBusinessUnits, arg.TenantID, arg.Search)
+func (q *Queries) CountCompetencies(ctx context.Context, arg CountCompetenciesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countCompetencies, arg.TenantID, arg.Search)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -114,15 +238,28 @@ WHERE
         OR name ILIKE '%' || $2::text || '%'
         OR code ILIKE '%' || $2::text || '%'
     )
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'department'
+                AND cfv.entity_id = departments.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
 `
 
 type CountDepartmentsParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Search   string      `json:"search"`
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
 }
 
 func (q *Queries) CountDepartments(ctx context.Context, arg CountDepartmentsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countDepartments, arg.TenantID, arg.Search)
+	row := q.db.QueryRow(ctx, countDepartments, arg.TenantID, arg.Search, arg.CustomFields)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -140,15 +277,108 @@ WHERE
         OR display_name ILIKE '%' || $2::text || '%'
         OR work_email ILIKE '%' || $2::text || '%'
     )
+    AND (
+        $3::uuid IS NULL
+        OR department_id = $3::uuid
+        OR (
+            $4::boolean
+            AND department_id IN (
+                WITH RECURSIVE
+                    dept_tree AS (
+                        SELECT d1.id, ARRAY[d1.id]::uuid[] AS path
+                        FROM departments d1
+                        WHERE
+                            d1.tenant_id = $1
+                            AND d1.id = $3::uuid
+                        UNION ALL
+                        SELECT d2.id, dt.path || d2.id
+                        FROM departments d2
+                            INNER JOIN dept_tree dt ON d2.parent_department_id = dt.id
+                        WHERE
+                            d2.tenant_id = $1
+                            AND NOT d2.id = ANY (dt.path)
+                    )
+                SELECT id
+                FROM dept_tree
+            )
+        )
+    )
+    AND (
+        $5::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'employee'
+                AND cfv.entity_id = employees.id
+                AND cfv.field_values @> $5::jsonb
+        )
+    )
 `
 
 type CountEmployeesParams struct {
+	TenantID              pgtype.UUID `json:"tenant_id"`
+	Search                string      `json:"search"`
+	DepartmentID          pgtype.UUID `json:"department_id"`
+	IncludeSubDepartments bool        `json:"include_sub_departments"`
+	CustomFields          []byte      `json:"custom_fields"`
+}
+
+func (q *Queries) CountEmployees(ctx context.Context, arg CountEmployeesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countEmployees,
+		arg.TenantID,
+		arg.Search,
+		arg.DepartmentID,
+		arg.IncludeSubDepartments,
+		arg.CustomFields,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countEmployeesAtBusinessUnitDepartment = `-- name: CountEmployeesAtBusinessUnitDepartment :one
+SELECT count(*)
+FROM employees
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3
+`
+
+type CountEmployeesAtBusinessUnitDepartmentParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	BusinessUnitID pgtype.UUID `json:"business_unit_id"`
+	DepartmentID   pgtype.UUID `json:"department_id"`
+}
+
+func (q *Queries) CountEmployeesAtBusinessUnitDepartment(ctx context.Context, arg CountEmployeesAtBusinessUnitDepartmentParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countEmployeesAtBusinessUnitDepartment, arg.TenantID, arg.BusinessUnitID, arg.DepartmentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countJobGrades = `-- name: CountJobGrades :one
+SELECT count(*)
+FROM job_grades
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR name ILIKE '%' || $2::text || '%'
+        OR code ILIKE '%' || $2::text || '%'
+    )
+`
+
+type CountJobGradesParams struct {
 	TenantID pgtype.UUID `json:"tenant_id"`
 	Search   string      `json:"search"`
 }
 
-func (q *Queries) CountEmployees(ctx context.Context, arg CountEmployeesParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countEmployees, arg.TenantID, arg.Search)
+func (q *Queries) CountJobGrades(ctx context.Context, arg CountJobGradesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countJobGrades, arg.TenantID, arg.Search)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -164,15 +394,28 @@ WHERE
         OR name ILIKE '%' || $2::text || '%'
         OR code ILIKE '%' || $2::text || '%'
     )
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'job_title'
+                AND cfv.entity_id = job_titles.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
 `
 
 type CountJobTitlesParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Search   string      `json:"search"`
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
 }
 
 func (q *Queries) CountJobTitles(ctx context.Context, arg CountJobTitlesParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countJobTitles, arg.TenantID, arg.Search)
+	row := q.db.QueryRow(ctx, countJobTitles, arg.TenantID, arg.Search, arg.CustomFields)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -237,6 +480,62 @@ func (q *Queries) CreateBusinessUnit(ctx context.Context, arg CreateBusinessUnit
 	return i, err
 }
 
+const createCompetency = `-- name: CreateCompetency :one
+INSERT INTO
+    competencies (
+        id,
+        tenant_id,
+        code,
+        name,
+        description,
+        kind,
+        document_ref,
+        validity_months
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    id, tenant_id, code, name, description, kind, document_ref, validity_months, is_active, created_at, updated_at
+`
+
+type CreateCompetencyParams struct {
+	ID             pgtype.UUID `json:"id"`
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Description    pgtype.Text `json:"description"`
+	Kind           string      `json:"kind"`
+	DocumentRef    pgtype.Text `json:"document_ref"`
+	ValidityMonths pgtype.Int4 `json:"validity_months"`
+}
+
+func (q *Queries) CreateCompetency(ctx context.Context, arg CreateCompetencyParams) (Competency, error) {
+	row := q.db.QueryRow(ctx, createCompetency,
+		arg.ID,
+		arg.TenantID,
+		arg.Code,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.DocumentRef,
+		arg.ValidityMonths,
+	)
+	var i Competency
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.DocumentRef,
+		&i.ValidityMonths,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createDepartment = `-- name: CreateDepartment :one
 INSERT INTO
     departments (
@@ -310,7 +609,7 @@ VALUES (
         $11
     )
 RETURNING
-    id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id
+    id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id, erased_at, erased_by_user_id
 `
 
 type CreateEmployeeParams struct {
@@ -358,6 +657,126 @@ func (q *Queries) CreateEmployee(ctx context.Context, arg CreateEmployeeParams)
 		&i.DepartmentID,
 		&i.JobTitleID,
 		&i.ManagerID,
+		&i.ErasedAt,
+		&i.ErasedByUserID,
+	)
+	return i, err
+}
+
+const createEmployeeCompetency = `-- name: CreateEmployeeCompetency :one
+INSERT INTO
+    employee_competencies (
+        id,
+        tenant_id,
+        employee_id,
+        competency_id,
+        achieved_on,
+        expires_on,
+        source,
+        notes,
+        recorded_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    id, tenant_id, employee_id, competency_id, achieved_on, expires_on, source, notes, recorded_by_user_id, created_at
+`
+
+type CreateEmployeeCompetencyParams struct {
+	ID               pgtype.UUID `json:"id"`
+	TenantID         pgtype.UUID `json:"tenant_id"`
+	EmployeeID       pgtype.UUID `json:"employee_id"`
+	CompetencyID     pgtype.UUID `json:"competency_id"`
+	AchievedOn       pgtype.Date `json:"achieved_on"`
+	ExpiresOn        pgtype.Date `json:"expires_on"`
+	Source           string      `json:"source"`
+	Notes            pgtype.Text `json:"notes"`
+	RecordedByUserID pgtype.UUID `json:"recorded_by_user_id"`
+}
+
+func (q *Queries) CreateEmployeeCompetency(ctx context.Context, arg CreateEmployeeCompetencyParams) (EmployeeCompetency, error) {
+	row := q.db.QueryRow(ctx, createEmployeeCompetency,
+		arg.ID,
+		arg.TenantID,
+		arg.EmployeeID,
+		arg.CompetencyID,
+		arg.AchievedOn,
+		arg.ExpiresOn,
+		arg.Source,
+		arg.Notes,
+		arg.RecordedByUserID,
+	)
+	var i EmployeeCompetency
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EmployeeID,
+		&i.CompetencyID,
+		&i.AchievedOn,
+		&i.ExpiresOn,
+		&i.Source,
+		&i.Notes,
+		&i.RecordedByUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createJobGrade = `-- name: CreateJobGrade :one
+INSERT INTO
+    job_grades (
+        id,
+        tenant_id,
+        code,
+        name,
+        level,
+        currency,
+        min_pay,
+        mid_pay,
+        max_pay
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at
+`
+
+type CreateJobGradeParams struct {
+	ID       pgtype.UUID    `json:"id"`
+	TenantID pgtype.UUID    `json:"tenant_id"`
+	Code     string         `json:"code"`
+	Name     string         `json:"name"`
+	Level    int32          `json:"level"`
+	Currency string         `json:"currency"`
+	MinPay   pgtype.Numeric `json:"min_pay"`
+	MidPay   pgtype.Numeric `json:"mid_pay"`
+	MaxPay   pgtype.Numeric `json:"max_pay"`
+}
+
+func (q *Queries) CreateJobGrade(ctx context.Context, arg CreateJobGradeParams) (JobGrade, error) {
+	row := q.db.QueryRow(ctx, createJobGrade,
+		arg.ID,
+		arg.TenantID,
+		arg.Code,
+		arg.Name,
+		arg.Level,
+		arg.Currency,
+		arg.MinPay,
+		arg.MidPay,
+		arg.MaxPay,
+	)
+	var i JobGrade
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Level,
+		&i.Currency,
+		&i.MinPay,
+		&i.MidPay,
+		&i.MaxPay,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -369,19 +788,21 @@ INSERT INTO
         tenant_id,
         code,
         name,
-        grade
+        grade_id,
+        default_department_id
     )
-VALUES ($1, $2, $3, $4, $5)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING
-    id, tenant_id, code, name, grade, is_active, created_at, updated_at
+    id, tenant_id, code, name, is_active, created_at, updated_at, grade_id, default_department_id
 `
 
 type CreateJobTitleParams struct {
-	ID       pgtype.UUID `json:"id"`
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Code     pgtype.Text `json:"code"`
-	Name     string      `json:"name"`
-	Grade    pgtype.Text `json:"grade"`
+	ID                  pgtype.UUID `json:"id"`
+	TenantID            pgtype.UUID `json:"tenant_id"`
+	Code                pgtype.Text `json:"code"`
+	Name                string      `json:"name"`
+	GradeID             pgtype.UUID `json:"grade_id"`
+	DefaultDepartmentID pgtype.UUID `json:"default_department_id"`
 }
 
 func (q *Queries) CreateJobTitle(ctx context.Context, arg CreateJobTitleParams) (JobTitle, error) {
@@ -390,7 +811,8 @@ func (q *Queries) CreateJobTitle(ctx context.Context, arg CreateJobTitleParams)
 		arg.TenantID,
 		arg.Code,
 		arg.Name,
-		arg.Grade,
+		arg.GradeID,
+		arg.DefaultDepartmentID,
 	)
 	var i JobTitle
 	err := row.Scan(
@@ -398,10 +820,11 @@ func (q *Queries) CreateJobTitle(ctx context.Context, arg CreateJobTitleParams)
 		&i.TenantID,
 		&i.Code,
 		&i.Name,
-		&i.Grade,
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.GradeID,
+		&i.DefaultDepartmentID,
 	)
 	return i, err
 }
@@ -417,7 +840,7 @@ INSERT INTO
     )
 VALUES ($1, $2, $3, $4, $5)
 RETURNING
-    id, tenant_id, code, name, description, is_active, created_at, updated_at
+    id, tenant_id, code, name, description, is_active, created_at, updated_at, external_id
 `
 
 type CreateRoleParams struct {
@@ -446,6 +869,7 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (RbacRol
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
@@ -488,7 +912,7 @@ INSERT INTO
     )
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING
-    id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at
+    id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at, locale, external_id, avatar_url, preferences
 `
 
 type CreateUserParams struct {
@@ -521,10 +945,28 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.ExternalID,
+		&i.AvatarUrl,
+		&i.Preferences,
 	)
 	return i, err
 }
 
+const deleteJobTitleRequirements = `-- name: DeleteJobTitleRequirements :exec
+DELETE FROM job_title_competencies WHERE tenant_id = $1 AND job_title_id = $2
+`
+
+type DeleteJobTitleRequirementsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	JobTitleID pgtype.UUID `json:"job_title_id"`
+}
+
+func (q *Queries) DeleteJobTitleRequirements(ctx context.Context, arg DeleteJobTitleRequirementsParams) error {
+	_, err := q.db.Exec(ctx, deleteJobTitleRequirements, arg.TenantID, arg.JobTitleID)
+	return err
+}
+
 const getBusinessUnit = `-- name: GetBusinessUnit :one
 SELECT id, tenant_id, code, name, is_active, created_at, updated_at
 FROM business_units
@@ -554,6 +996,34 @@ func (q *Queries) GetBusinessUnit(ctx context.Context, arg GetBusinessUnitParams
 	return i, err
 }
 
+const getCompetency = `-- name: GetCompetency :one
+SELECT id, tenant_id, code, name, description, kind, document_ref, validity_months, is_active, created_at, updated_at FROM competencies WHERE tenant_id = $1 AND id = $2 LIMIT 1
+`
+
+type GetCompetencyParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) GetCompetency(ctx context.Context, arg GetCompetencyParams) (Competency, error) {
+	row := q.db.QueryRow(ctx, getCompetency, arg.TenantID, arg.ID)
+	var i Competency
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.DocumentRef,
+		&i.ValidityMonths,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getDepartment = `-- name: GetDepartment :one
 SELECT id, tenant_id, parent_department_id, code, name, is_active, created_at, updated_at FROM departments WHERE tenant_id = $1 AND id = $2 LIMIT 1
 `
@@ -580,7 +1050,7 @@ func (q *Queries) GetDepartment(ctx context.Context, arg GetDepartmentParams) (D
 }
 
 const getEmployee = `-- name: GetEmployee :one
-SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id FROM employees WHERE tenant_id = $1 AND id = $2 LIMIT 1
+SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id, erased_at, erased_by_user_id FROM employees WHERE tenant_id = $1 AND id = $2 LIMIT 1
 `
 
 type GetEmployeeParams struct {
@@ -607,65 +1077,117 @@ func (q *Queries) GetEmployee(ctx context.Context, arg GetEmployeeParams) (Emplo
 		&i.DepartmentID,
 		&i.JobTitleID,
 		&i.ManagerID,
+		&i.ErasedAt,
+		&i.ErasedByUserID,
 	)
 	return i, err
 }
 
-const getEmployeeHierarchy = `-- name: GetEmployeeHierarchy :many
+const getEmployeeChainOfCommand = `-- name: GetEmployeeChainOfCommand :many
 WITH RECURSIVE
-    employee_tree AS (
-        SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id
+    chain AS (
+        SELECT e1.id, e1.manager_id, 0::int AS level, ARRAY[e1.id]::uuid[] AS path
         FROM employees e1
         WHERE
             e1.tenant_id = $1
             AND e1.id = $2
         UNION ALL
-        SELECT e2.id, e2.tenant_id, e2.employee_no, e2.first_name, e2.last_name, e2.display_name, e2.work_email, e2.status, e2.is_active, e2.created_at, e2.updated_at, e2.business_unit_id, e2.department_id, e2.job_title_id, e2.manager_id
-        FROM
-            employees e2
-            INNER JOIN employee_tree et ON e2.manager_id = et.id
+        SELECT m.id, m.manager_id, c.level + 1, c.path || m.id
+        FROM employees m
+            INNER JOIN chain c ON m.id = c.manager_id
         WHERE
-            e2.tenant_id = $1
+            m.tenant_id = $1
+            AND NOT m.id = ANY (c.path)
     )
-SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id
-FROM employee_tree
-`
-
-type GetEmployeeHierarchyParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	ID       pgtype.UUID `json:"id"`
-}
-
-type GetEmployeeHierarchyRow struct {
-	ID             pgtype.UUID        `json:"id"`
-	TenantID       pgtype.UUID        `json:"tenant_id"`
-	EmployeeNo     string             `json:"employee_no"`
-	FirstName      string             `json:"first_name"`
-	LastName       string             `json:"last_name"`
-	DisplayName    pgtype.Text        `json:"display_name"`
-	WorkEmail      pgtype.Text        `json:"work_email"`
-	Status         string             `json:"status"`
-	IsActive       bool               `json:"is_active"`
-	CreatedAt      pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
-	BusinessUnitID pgtype.UUID        `json:"business_unit_id"`
-	DepartmentID   pgtype.UUID        `json:"department_id"`
-	JobTitleID     pgtype.UUID        `json:"job_title_id"`
-	ManagerID      pgtype.UUID        `json:"manager_id"`
-}
-
-func (q *Queries) GetEmployeeHierarchy(ctx context.Context, arg GetEmployeeHierarchyParams) ([]GetEmployeeHierarchyRow, error) {
-	rows, err := q.db.Query(ctx, getEmployeeHierarchy, arg.TenantID, arg.ID)
-	if err != nil {
-		return nil, err
+SELECT
+    c.level::int AS level,
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    chain c
+    JOIN employees e ON e.id = c.id
+    AND e.tenant_id = $1
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+WHERE
+    c.level > 0
+ORDER BY c.level
+`
+
+type GetEmployeeChainOfCommandParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+type GetEmployeeChainOfCommandRow struct {
+	Level             int32              `json:"level"`
+	ID                pgtype.UUID        `json:"id"`
+	EmployeeNo        string             `json:"employee_no"`
+	FirstName         string             `json:"first_name"`
+	LastName          string             `json:"last_name"`
+	DisplayName       pgtype.Text        `json:"display_name"`
+	WorkEmail         pgtype.Text        `json:"work_email"`
+	Status            string             `json:"status"`
+	IsActive          bool               `json:"is_active"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ManagerID         pgtype.UUID        `json:"manager_id"`
+	BusinessUnitID    pgtype.UUID        `json:"business_unit_id"`
+	BusinessUnitCode  pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName  pgtype.Text        `json:"business_unit_name"`
+	DepartmentID      pgtype.UUID        `json:"department_id"`
+	DepartmentCode    pgtype.Text        `json:"department_code"`
+	DepartmentName    pgtype.Text        `json:"department_name"`
+	JobTitleID        pgtype.UUID        `json:"job_title_id"`
+	JobTitleCode      pgtype.Text        `json:"job_title_code"`
+	JobTitleName      pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade     pgtype.Text        `json:"job_title_grade"`
+	DirectReportCount int32              `json:"direct_report_count"`
+}
+
+func (q *Queries) GetEmployeeChainOfCommand(ctx context.Context, arg GetEmployeeChainOfCommandParams) ([]GetEmployeeChainOfCommandRow, error) {
+	rows, err := q.db.Query(ctx, getEmployeeChainOfCommand, arg.TenantID, arg.ID)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEmployeeHierarchyRow
+	var items []GetEmployeeChainOfCommandRow
 	for rows.Next() {
-		var i GetEmployeeHierarchyRow
+		var i GetEmployeeChainOfCommandRow
 		if err := rows.Scan(
+			&i.Level,
 			&i.ID,
-			&i.TenantID,
 			&i.EmployeeNo,
 			&i.FirstName,
 			&i.LastName,
@@ -675,10 +1197,156 @@ func (q *Queries) GetEmployeeHierarchy(ctx context.Context, arg GetEmployeeHiera
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ManagerID,
 			&i.BusinessUnitID,
+			&i.BusinessUnitCode,
+			&i.BusinessUnitName,
 			&i.DepartmentID,
+			&i.DepartmentCode,
+			&i.DepartmentName,
 			&i.JobTitleID,
+			&i.JobTitleCode,
+			&i.JobTitleName,
+			&i.JobTitleGrade,
+			&i.DirectReportCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEmployeeSubtree = `-- name: GetEmployeeSubtree :many
+WITH RECURSIVE
+    subtree AS (
+        SELECT e1.id, 0::int AS depth, ARRAY[e1.id]::uuid[] AS path
+        FROM employees e1
+        WHERE
+            e1.tenant_id = $1
+            AND e1.id = $2
+        UNION ALL
+        SELECT e2.id, st.depth + 1, st.path || e2.id
+        FROM
+            employees e2
+            INNER JOIN subtree st ON e2.manager_id = st.id
+        WHERE
+            e2.tenant_id = $1
+            AND NOT e2.id = ANY (st.path)
+            AND st.depth < $3::int
+    )
+SELECT
+    st.depth::int AS depth,
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    subtree st
+    JOIN employees e ON e.id = st.id
+    AND e.tenant_id = $1
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+ORDER BY st.depth, e.last_name, e.first_name, e.id
+`
+
+type GetEmployeeSubtreeParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ID       pgtype.UUID `json:"id"`
+	MaxDepth int32       `json:"max_depth"`
+}
+
+type GetEmployeeSubtreeRow struct {
+	Depth             int32              `json:"depth"`
+	ID                pgtype.UUID        `json:"id"`
+	EmployeeNo        string             `json:"employee_no"`
+	FirstName         string             `json:"first_name"`
+	LastName          string             `json:"last_name"`
+	DisplayName       pgtype.Text        `json:"display_name"`
+	WorkEmail         pgtype.Text        `json:"work_email"`
+	Status            string             `json:"status"`
+	IsActive          bool               `json:"is_active"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ManagerID         pgtype.UUID        `json:"manager_id"`
+	BusinessUnitID    pgtype.UUID        `json:"business_unit_id"`
+	BusinessUnitCode  pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName  pgtype.Text        `json:"business_unit_name"`
+	DepartmentID      pgtype.UUID        `json:"department_id"`
+	DepartmentCode    pgtype.Text        `json:"department_code"`
+	DepartmentName    pgtype.Text        `json:"department_name"`
+	JobTitleID        pgtype.UUID        `json:"job_title_id"`
+	JobTitleCode      pgtype.Text        `json:"job_title_code"`
+	JobTitleName      pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade     pgtype.Text        `json:"job_title_grade"`
+	DirectReportCount int32              `json:"direct_report_count"`
+}
+
+func (q *Queries) GetEmployeeSubtree(ctx context.Context, arg GetEmployeeSubtreeParams) ([]GetEmployeeSubtreeRow, error) {
+	rows, err := q.db.Query(ctx, getEmployeeSubtree, arg.TenantID, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEmployeeSubtreeRow
+	for rows.Next() {
+		var i GetEmployeeSubtreeRow
+		if err := rows.Scan(
+			&i.Depth,
+			&i.ID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DisplayName,
+			&i.WorkEmail,
+			&i.Status,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 			&i.ManagerID,
+			&i.BusinessUnitID,
+			&i.BusinessUnitCode,
+			&i.BusinessUnitName,
+			&i.DepartmentID,
+			&i.DepartmentCode,
+			&i.DepartmentName,
+			&i.JobTitleID,
+			&i.JobTitleCode,
+			&i.JobTitleName,
+			&i.JobTitleGrade,
+			&i.DirectReportCount,
 		); err != nil {
 			return nil, err
 		}
@@ -713,16 +1381,37 @@ SELECT
     d.name AS department_name,
     jt.code AS job_title_code,
     jt.name AS job_title_name,
-    jt.grade AS job_title_grade,
+    jg.code AS job_title_grade,
     m.employee_no AS manager_employee_no,
     m.first_name AS manager_first_name,
     m.last_name AS manager_last_name,
-    m.display_name AS manager_display_name
+    m.display_name AS manager_display_name,
+    EXISTS (
+        SELECT 1
+        FROM
+            training_records tr
+            JOIN training_courses tc ON tc.id = tr.course_id
+        WHERE
+            tr.tenant_id = e.tenant_id
+            AND tr.employee_id = e.id
+            AND tc.is_mandatory
+            AND tc.is_active
+            AND tr.signed_off_at IS NOT NULL
+            AND tr.result = 'pass'
+        GROUP BY
+            tr.course_id
+        HAVING
+            bool_and(tr.expires_on IS NOT NULL)
+            AND max(tr.expires_on) < CURRENT_DATE
+    ) AS has_expired_mandatory_training,
+    COALESCE(cfv.field_values, '{}')::jsonb AS custom_fields
 FROM employees e
 LEFT JOIN business_units bu ON e.business_unit_id = bu.id AND e.tenant_id = bu.tenant_id
 LEFT JOIN departments d ON e.department_id = d.id AND e.tenant_id = d.tenant_id
 LEFT JOIN job_titles jt ON e.job_title_id = jt.id AND e.tenant_id = jt.tenant_id
+LEFT JOIN job_grades jg ON jt.grade_id = jg.id
 LEFT JOIN employees m ON e.manager_id = m.id AND e.tenant_id = m.tenant_id
+LEFT JOIN custom_field_values cfv ON cfv.tenant_id = e.tenant_id AND cfv.entity_type = 'employee' AND cfv.entity_id = e.id
 WHERE e.tenant_id = $1 AND e.id = $2 LIMIT 1
 `
 
@@ -732,32 +1421,34 @@ type GetEmployeeWithDetailsParams struct {
 }
 
 type GetEmployeeWithDetailsRow struct {
-	ID                 pgtype.UUID        `json:"id"`
-	TenantID           pgtype.UUID        `json:"tenant_id"`
-	EmployeeNo         string             `json:"employee_no"`
-	FirstName          string             `json:"first_name"`
-	LastName           string             `json:"last_name"`
-	DisplayName        pgtype.Text        `json:"display_name"`
-	WorkEmail          pgtype.Text        `json:"work_email"`
-	Status             string             `json:"status"`
-	IsActive           bool               `json:"is_active"`
-	CreatedAt          pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
-	BusinessUnitID     pgtype.UUID        `json:"business_unit_id"`
-	DepartmentID       pgtype.UUID        `json:"department_id"`
-	JobTitleID         pgtype.UUID        `json:"job_title_id"`
-	ManagerID          pgtype.UUID        `json:"manager_id"`
-	BusinessUnitCode   pgtype.Text        `json:"business_unit_code"`
-	BusinessUnitName   pgtype.Text        `json:"business_unit_name"`
-	DepartmentCode     pgtype.Text        `json:"department_code"`
-	DepartmentName     pgtype.Text        `json:"department_name"`
-	JobTitleCode       pgtype.Text        `json:"job_title_code"`
-	JobTitleName       pgtype.Text        `json:"job_title_name"`
-	JobTitleGrade      pgtype.Text        `json:"job_title_grade"`
-	ManagerEmployeeNo  pgtype.Text        `json:"manager_employee_no"`
-	ManagerFirstName   pgtype.Text        `json:"manager_first_name"`
-	ManagerLastName    pgtype.Text        `json:"manager_last_name"`
-	ManagerDisplayName pgtype.Text        `json:"manager_display_name"`
+	ID                          pgtype.UUID        `json:"id"`
+	TenantID                    pgtype.UUID        `json:"tenant_id"`
+	EmployeeNo                  string             `json:"employee_no"`
+	FirstName                   string             `json:"first_name"`
+	LastName                    string             `json:"last_name"`
+	DisplayName                 pgtype.Text        `json:"display_name"`
+	WorkEmail                   pgtype.Text        `json:"work_email"`
+	Status                      string             `json:"status"`
+	IsActive                    bool               `json:"is_active"`
+	CreatedAt                   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt                   pgtype.Timestamptz `json:"updated_at"`
+	BusinessUnitID              pgtype.UUID        `json:"business_unit_id"`
+	DepartmentID                pgtype.UUID        `json:"department_id"`
+	JobTitleID                  pgtype.UUID        `json:"job_title_id"`
+	ManagerID                   pgtype.UUID        `json:"manager_id"`
+	BusinessUnitCode            pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName            pgtype.Text        `json:"business_unit_name"`
+	DepartmentCode              pgtype.Text        `json:"department_code"`
+	DepartmentName              pgtype.Text        `json:"department_name"`
+	JobTitleCode                pgtype.Text        `json:"job_title_code"`
+	JobTitleName                pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade               pgtype.Text        `json:"job_title_grade"`
+	ManagerEmployeeNo           pgtype.Text        `json:"manager_employee_no"`
+	ManagerFirstName            pgtype.Text        `json:"manager_first_name"`
+	ManagerLastName             pgtype.Text        `json:"manager_last_name"`
+	ManagerDisplayName          pgtype.Text        `json:"manager_display_name"`
+	HasExpiredMandatoryTraining bool               `json:"has_expired_mandatory_training"`
+	CustomFields                []byte             `json:"custom_fields"`
 }
 
 func (q *Queries) GetEmployeeWithDetails(ctx context.Context, arg GetEmployeeWithDetailsParams) (GetEmployeeWithDetailsRow, error) {
@@ -790,12 +1481,72 @@ func (q *Queries) GetEmployeeWithDetails(ctx context.Context, arg GetEmployeeWit
 		&i.ManagerFirstName,
 		&i.ManagerLastName,
 		&i.ManagerDisplayName,
+		&i.HasExpiredMandatoryTraining,
+		&i.CustomFields,
+	)
+	return i, err
+}
+
+const getJobGrade = `-- name: GetJobGrade :one
+SELECT id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at FROM job_grades WHERE tenant_id = $1 AND id = $2 LIMIT 1
+`
+
+type GetJobGradeParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) GetJobGrade(ctx context.Context, arg GetJobGradeParams) (JobGrade, error) {
+	row := q.db.QueryRow(ctx, getJobGrade, arg.TenantID, arg.ID)
+	var i JobGrade
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Level,
+		&i.Currency,
+		&i.MinPay,
+		&i.MidPay,
+		&i.MaxPay,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getJobGradeByCode = `-- name: GetJobGradeByCode :one
+SELECT id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at FROM job_grades WHERE tenant_id = $1 AND lower(code) = lower($2::text) LIMIT 1
+`
+
+type GetJobGradeByCodeParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Code     string      `json:"code"`
+}
+
+func (q *Queries) GetJobGradeByCode(ctx context.Context, arg GetJobGradeByCodeParams) (JobGrade, error) {
+	row := q.db.QueryRow(ctx, getJobGradeByCode, arg.TenantID, arg.Code)
+	var i JobGrade
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Level,
+		&i.Currency,
+		&i.MinPay,
+		&i.MidPay,
+		&i.MaxPay,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const getJobTitle = `-- name: GetJobTitle :one
-SELECT id, tenant_id, code, name, grade, is_active, created_at, updated_at FROM job_titles WHERE tenant_id = $1 AND id = $2 LIMIT 1
+SELECT id, tenant_id, code, name, is_active, created_at, updated_at, grade_id, default_department_id FROM job_titles WHERE tenant_id = $1 AND id = $2 LIMIT 1
 `
 
 type GetJobTitleParams struct {
@@ -811,16 +1562,17 @@ func (q *Queries) GetJobTitle(ctx context.Context, arg GetJobTitleParams) (JobTi
 		&i.TenantID,
 		&i.Code,
 		&i.Name,
-		&i.Grade,
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.GradeID,
+		&i.DefaultDepartmentID,
 	)
 	return i, err
 }
 
 const getRole = `-- name: GetRole :one
-SELECT id, tenant_id, code, name, description, is_active, created_at, updated_at FROM rbac_roles WHERE tenant_id = $1 AND id = $2 LIMIT 1
+SELECT id, tenant_id, code, name, description, is_active, created_at, updated_at, external_id FROM rbac_roles WHERE tenant_id = $1 AND id = $2 LIMIT 1
 `
 
 type GetRoleParams struct {
@@ -840,6 +1592,33 @@ func (q *Queries) GetRole(ctx context.Context, arg GetRoleParams) (RbacRole, err
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const getRoleByCode = `-- name: GetRoleByCode :one
+SELECT id, tenant_id, code, name, description, is_active, created_at, updated_at, external_id FROM rbac_roles WHERE tenant_id = $1 AND code = $2 LIMIT 1
+`
+
+type GetRoleByCodeParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Code     string      `json:"code"`
+}
+
+func (q *Queries) GetRoleByCode(ctx context.Context, arg GetRoleByCodeParams) (RbacRole, error) {
+	row := q.db.QueryRow(ctx, getRoleByCode, arg.TenantID, arg.Code)
+	var i RbacRole
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
@@ -861,7 +1640,7 @@ func (q *Queries) GetTenant(ctx context.Context, id pgtype.UUID) (Tenant, error)
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at FROM users WHERE tenant_id = $1 AND id = $2 LIMIT 1
+SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at, locale, external_id, avatar_url, preferences FROM users WHERE tenant_id = $1 AND id = $2 LIMIT 1
 `
 
 type GetUserParams struct {
@@ -883,12 +1662,16 @@ func (q *Queries) GetUser(ctx context.Context, arg GetUserParams) (User, error)
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.ExternalID,
+		&i.AvatarUrl,
+		&i.Preferences,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at FROM users WHERE tenant_id = $1 AND email = $2 LIMIT 1
+SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at, locale, external_id, avatar_url, preferences FROM users WHERE tenant_id = $1 AND email = $2 LIMIT 1
 `
 
 type GetUserByEmailParams struct {
@@ -910,12 +1693,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams)
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.ExternalID,
+		&i.AvatarUrl,
+		&i.Preferences,
 	)
 	return i, err
 }
 
 const getUserForLogin = `-- name: GetUserForLogin :one
-SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at FROM users WHERE email = $1 LIMIT 1
+SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at, locale, external_id, avatar_url, preferences FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserForLogin(ctx context.Context, email string) (User, error) {
@@ -932,6 +1719,10 @@ func (q *Queries) GetUserForLogin(ctx context.Context, email string) (User, erro
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.ExternalID,
+		&i.AvatarUrl,
+		&i.Preferences,
 	)
 	return i, err
 }
@@ -981,21 +1772,25 @@ INSERT INTO
         action,
         entity_type,
         entity_id,
-        changes
+        changes,
+        actor_api_key_id,
+        impersonator_id
     )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
-    id, tenant_id, actor_id, action, entity_type, entity_id, changes, created_at
+    id, tenant_id, actor_id, action, entity_type, entity_id, changes, created_at, actor_api_key_id, impersonator_id
 `
 
 type InsertAuditLogParams struct {
-	ID         pgtype.UUID `json:"id"`
-	TenantID   pgtype.UUID `json:"tenant_id"`
-	ActorID    pgtype.UUID `json:"actor_id"`
-	Action     string      `json:"action"`
-	EntityType string      `json:"entity_type"`
-	EntityID   pgtype.UUID `json:"entity_id"`
-	Changes    []byte      `json:"changes"`
+	ID             pgtype.UUID `json:"id"`
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	ActorID        pgtype.UUID `json:"actor_id"`
+	Action         string      `json:"action"`
+	EntityType     string      `json:"entity_type"`
+	EntityID       pgtype.UUID `json:"entity_id"`
+	Changes        []byte      `json:"changes"`
+	ActorApiKeyID  pgtype.UUID `json:"actor_api_key_id"`
+	ImpersonatorID pgtype.UUID `json:"impersonator_id"`
 }
 
 func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error) {
@@ -1007,6 +1802,8 @@ func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams)
 		arg.EntityType,
 		arg.EntityID,
 		arg.Changes,
+		arg.ActorApiKeyID,
+		arg.ImpersonatorID,
 	)
 	var i AuditLog
 	err := row.Scan(
@@ -1018,61 +1815,71 @@ func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams)
 		&i.EntityID,
 		&i.Changes,
 		&i.CreatedAt,
+		&i.ActorApiKeyID,
+		&i.ImpersonatorID,
 	)
 	return i, err
 }
 
-const listAuditLogs = `-- name: ListAuditLogs :many
-SELECT id, tenant_id, actor_id, action, entity_type, entity_id, changes, created_at
-FROM audit_logs
-WHERE
-    tenant_id = $1
-    AND (
-        $2::text = ''
-        OR entity_type = $2::text
-    )
-    AND (
-        $3::text = ''
-        OR action = $3::text
+const linkBusinessUnitDepartment = `-- name: LinkBusinessUnitDepartment :one
+INSERT INTO
+    business_unit_departments (
+        tenant_id,
+        business_unit_id,
+        department_id,
+        created_by_user_id
     )
-ORDER BY created_at DESC
-LIMIT $5
-OFFSET
-    $4
+VALUES ($1, $2, $3, $4)
+RETURNING
+    tenant_id, business_unit_id, department_id, created_by_user_id, created_at
 `
 
-type ListAuditLogsParams struct {
-	TenantID   pgtype.UUID `json:"tenant_id"`
-	EntityType string      `json:"entity_type"`
-	Action     string      `json:"action"`
-	Offset     int32       `json:"offset"`
-	Limit      int32       `json:"limit"`
+type LinkBusinessUnitDepartmentParams struct {
+	TenantID        pgtype.UUID `json:"tenant_id"`
+	BusinessUnitID  pgtype.UUID `json:"business_unit_id"`
+	DepartmentID    pgtype.UUID `json:"department_id"`
+	CreatedByUserID pgtype.UUID `json:"created_by_user_id"`
 }
 
-func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
-	rows, err := q.db.Query(ctx, listAuditLogs,
+func (q *Queries) LinkBusinessUnitDepartment(ctx context.Context, arg LinkBusinessUnitDepartmentParams) (BusinessUnitDepartment, error) {
+	row := q.db.QueryRow(ctx, linkBusinessUnitDepartment,
 		arg.TenantID,
-		arg.EntityType,
-		arg.Action,
-		arg.Offset,
-		arg.Limit,
+		arg.BusinessUnitID,
+		arg.DepartmentID,
+		arg.CreatedByUserID,
 	)
+	var i BusinessUnitDepartment
+	err := row.Scan(
+		&i.TenantID,
+		&i.BusinessUnitID,
+		&i.DepartmentID,
+		&i.CreatedByUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAllBusinessUnits = `-- name: ListAllBusinessUnits :many
+SELECT id, tenant_id, code, name, is_active, created_at, updated_at FROM business_units WHERE tenant_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListAllBusinessUnits(ctx context.Context, tenantID pgtype.UUID) ([]BusinessUnit, error) {
+	rows, err := q.db.Query(ctx, listAllBusinessUnits, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []AuditLog
+	var items []BusinessUnit
 	for rows.Next() {
-		var i AuditLog
+		var i BusinessUnit
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
-			&i.ActorID,
-			&i.Action,
-			&i.EntityType,
-			&i.EntityID,
-			&i.Changes,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1084,33 +1891,1232 @@ func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([
 	return items, nil
 }
 
-const listBusinessUnits = `-- name: ListBusinessUnits :many
-SELECT id, tenant_id, code, name, is_active, created_at, updated_at
-FROM business_units
-WHERE
-    tenant_id = $1
-    AND (
-        $2::text = ''
-        OR name ILIKE '%' || $2::text || '%'
+const listAllDepartments = `-- name: ListAllDepartments :many
+SELECT id, tenant_id, parent_department_id, code, name, is_active, created_at, updated_at FROM departments WHERE tenant_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListAllDepartments(ctx context.Context, tenantID pgtype.UUID) ([]Department, error) {
+	rows, err := q.db.Query(ctx, listAllDepartments, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Department
+	for rows.Next() {
+		var i Department
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ParentDepartmentID,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllJobGrades = `-- name: ListAllJobGrades :many
+SELECT id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at FROM job_grades WHERE tenant_id = $1 ORDER BY level, code
+`
+
+func (q *Queries) ListAllJobGrades(ctx context.Context, tenantID pgtype.UUID) ([]JobGrade, error) {
+	rows, err := q.db.Query(ctx, listAllJobGrades, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobGrade
+	for rows.Next() {
+		var i JobGrade
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Code,
+			&i.Name,
+			&i.Level,
+			&i.Currency,
+			&i.MinPay,
+			&i.MidPay,
+			&i.MaxPay,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllJobTitles = `-- name: ListAllJobTitles :many
+SELECT id, tenant_id, code, name, is_active, created_at, updated_at, grade_id, default_department_id FROM job_titles WHERE tenant_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListAllJobTitles(ctx context.Context, tenantID pgtype.UUID) ([]JobTitle, error) {
+	rows, err := q.db.Query(ctx, listAllJobTitles, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobTitle
+	for rows.Next() {
+		var i JobTitle
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.GradeID,
+			&i.DefaultDepartmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, tenant_id, actor_id, action, entity_type, entity_id, changes, created_at, actor_api_key_id, impersonator_id
+FROM audit_logs
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR entity_type = $2::text
+    )
+    AND (
+        $3::text = ''
+        OR action = $3::text
+    )
+ORDER BY created_at DESC
+LIMIT $5
+OFFSET
+    $4
+`
+
+type ListAuditLogsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	EntityType string      `json:"entity_type"`
+	Action     string      `json:"action"`
+	Offset     int32       `json:"offset"`
+	Limit      int32       `json:"limit"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogs,
+		arg.TenantID,
+		arg.EntityType,
+		arg.Action,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ActorID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Changes,
+			&i.CreatedAt,
+			&i.ActorApiKeyID,
+			&i.ImpersonatorID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBusinessUnitDepartmentMatrix = `-- name: ListBusinessUnitDepartmentMatrix :many
+SELECT
+    bud.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    bud.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    (
+        SELECT count(*)
+        FROM employees e
+        WHERE
+            e.tenant_id = bud.tenant_id
+            AND e.business_unit_id = bud.business_unit_id
+            AND e.department_id = bud.department_id
+            AND e.is_active
+    )::bigint AS headcount
+FROM
+    business_unit_departments bud
+    JOIN business_units bu ON bu.id = bud.business_unit_id
+    JOIN departments d ON d.id = bud.department_id
+WHERE
+    bud.tenant_id = $1
+ORDER BY bu.name, d.name
+`
+
+type ListBusinessUnitDepartmentMatrixRow struct {
+	BusinessUnitID   pgtype.UUID `json:"business_unit_id"`
+	BusinessUnitCode pgtype.Text `json:"business_unit_code"`
+	BusinessUnitName string      `json:"business_unit_name"`
+	DepartmentID     pgtype.UUID `json:"department_id"`
+	DepartmentCode   pgtype.Text `json:"department_code"`
+	DepartmentName   string      `json:"department_name"`
+	Headcount        int64       `json:"headcount"`
+}
+
+func (q *Queries) ListBusinessUnitDepartmentMatrix(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitDepartmentMatrixRow, error) {
+	rows, err := q.db.Query(ctx, listBusinessUnitDepartmentMatrix, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBusinessUnitDepartmentMatrixRow
+	for rows.Next() {
+		var i ListBusinessUnitDepartmentMatrixRow
+		if err := rows.Scan(
+			&i.BusinessUnitID,
+			&i.BusinessUnitCode,
+			&i.BusinessUnitName,
+			&i.DepartmentID,
+			&i.DepartmentCode,
+			&i.DepartmentName,
+			&i.Headcount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBusinessUnitDepartmentRefs = `-- name: ListBusinessUnitDepartmentRefs :many
+SELECT business_unit_id, department_id
+FROM business_unit_departments
+WHERE
+    tenant_id = $1
+`
+
+type ListBusinessUnitDepartmentRefsRow struct {
+	BusinessUnitID pgtype.UUID `json:"business_unit_id"`
+	DepartmentID   pgtype.UUID `json:"department_id"`
+}
+
+func (q *Queries) ListBusinessUnitDepartmentRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitDepartmentRefsRow, error) {
+	rows, err := q.db.Query(ctx, listBusinessUnitDepartmentRefs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBusinessUnitDepartmentRefsRow
+	for rows.Next() {
+		var i ListBusinessUnitDepartmentRefsRow
+		if err := rows.Scan(&i.BusinessUnitID, &i.DepartmentID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBusinessUnitDepartments = `-- name: ListBusinessUnitDepartments :many
+SELECT
+    d.id,
+    d.parent_department_id,
+    d.code,
+    d.name,
+    d.is_active,
+    bud.created_at AS linked_at,
+    (
+        SELECT count(*)
+        FROM employees e
+        WHERE
+            e.tenant_id = bud.tenant_id
+            AND e.business_unit_id = bud.business_unit_id
+            AND e.department_id = bud.department_id
+            AND e.is_active
+    )::bigint AS headcount
+FROM
+    business_unit_departments bud
+    JOIN departments d ON d.id = bud.department_id
+WHERE
+    bud.tenant_id = $1
+    AND bud.business_unit_id = $2
+ORDER BY d.name
+`
+
+type ListBusinessUnitDepartmentsParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	BusinessUnitID pgtype.UUID `json:"business_unit_id"`
+}
+
+type ListBusinessUnitDepartmentsRow struct {
+	ID                 pgtype.UUID        `json:"id"`
+	ParentDepartmentID pgtype.UUID        `json:"parent_department_id"`
+	Code               pgtype.Text        `json:"code"`
+	Name               string             `json:"name"`
+	IsActive           bool               `json:"is_active"`
+	LinkedAt           pgtype.Timestamptz `json:"linked_at"`
+	Headcount          int64              `json:"headcount"`
+}
+
+func (q *Queries) ListBusinessUnitDepartments(ctx context.Context, arg ListBusinessUnitDepartmentsParams) ([]ListBusinessUnitDepartmentsRow, error) {
+	rows, err := q.db.Query(ctx, listBusinessUnitDepartments, arg.TenantID, arg.BusinessUnitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBusinessUnitDepartmentsRow
+	for rows.Next() {
+		var i ListBusinessUnitDepartmentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentDepartmentID,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
+			&i.LinkedAt,
+			&i.Headcount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBusinessUnitRefs = `-- name: ListBusinessUnitRefs :many
+SELECT id, code, is_active
+FROM business_units
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL
+`
+
+type ListBusinessUnitRefsRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Code     pgtype.Text `json:"code"`
+	IsActive bool        `json:"is_active"`
+}
+
+func (q *Queries) ListBusinessUnitRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListBusinessUnitRefsRow, error) {
+	rows, err := q.db.Query(ctx, listBusinessUnitRefs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBusinessUnitRefsRow
+	for rows.Next() {
+		var i ListBusinessUnitRefsRow
+		if err := rows.Scan(&i.ID, &i.Code, &i.IsActive); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBusinessUnits = `-- name: ListBusinessUnits :many
+SELECT id, tenant_id, code, name, is_active, created_at, updated_at
+FROM business_units
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR name ILIKE '%' || $2::text || '%'
+        OR code ILIKE '%' || $2::text || '%'
+    )
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'business_unit'
+                AND cfv.entity_id = business_units.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
+ORDER BY name
+LIMIT $5
+OFFSET
+    $4
+`
+
+type ListBusinessUnitsParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
+	Offset       int32       `json:"offset"`
+	Limit        int32       `json:"limit"`
+}
+
+func (q *Queries) ListBusinessUnits(ctx context.Context, arg ListBusinessUnitsParams) ([]BusinessUnit, error) {
+	rows, err := q.db.Query(ctx, listBusinessUnits,
+		arg.TenantID,
+		arg.Search,
+		arg.CustomFields,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BusinessUnit
+	for rows.Next() {
+		var i BusinessUnit
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetencies = `-- name: ListCompetencies :many
+SELECT id, tenant_id, code, name, description, kind, document_ref, validity_months, is_active, created_at, updated_at
+FROM competencies
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR name ILIKE '%' || $2::text || '%'
+        OR code ILIKE '%' || $2::text || '%'
+        OR document_ref ILIKE '%' || $2::text || '%'
+    )
+ORDER BY code
+LIMIT $4
+OFFSET
+    $3
+`
+
+type ListCompetenciesParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Search   string      `json:"search"`
+	Offset   int32       `json:"offset"`
+	Limit    int32       `json:"limit"`
+}
+
+func (q *Queries) ListCompetencies(ctx context.Context, arg ListCompetenciesParams) ([]Competency, error) {
+	rows, err := q.db.Query(ctx, listCompetencies,
+		arg.TenantID,
+		arg.Search,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Competency
+	for rows.Next() {
+		var i Competency
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.DocumentRef,
+			&i.ValidityMonths,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompetencyRequirementsForEmployees = `-- name: ListCompetencyRequirementsForEmployees :many
+SELECT
+    e.id AS employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    e.job_title_id,
+    jt.name AS job_title_name,
+    c.id AS competency_id,
+    c.code AS competency_code,
+    c.name AS competency_name,
+    c.kind AS competency_kind,
+    jtc.is_mandatory
+FROM
+    employees e
+    JOIN job_titles jt ON jt.id = e.job_title_id
+    JOIN job_title_competencies jtc ON jtc.job_title_id = e.job_title_id
+    JOIN competencies c ON c.id = jtc.competency_id
+WHERE
+    e.tenant_id = $1
+    AND e.is_active
+    AND c.is_active
+    AND (
+        $2::uuid IS NULL
+        OR e.id = $2
+    )
+    AND (
+        $3::uuid IS NULL
+        OR e.department_id = $3
+    )
+    AND (
+        $4::uuid IS NULL
+        OR e.job_title_id = $4
+    )
+ORDER BY e.last_name, e.first_name, e.id, c.code
+`
+
+type ListCompetencyRequirementsForEmployeesParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	EmployeeID   pgtype.UUID `json:"employee_id"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	JobTitleID   pgtype.UUID `json:"job_title_id"`
+}
+
+type ListCompetencyRequirementsForEmployeesRow struct {
+	EmployeeID     pgtype.UUID `json:"employee_id"`
+	EmployeeNo     string      `json:"employee_no"`
+	FirstName      string      `json:"first_name"`
+	LastName       string      `json:"last_name"`
+	DepartmentID   pgtype.UUID `json:"department_id"`
+	JobTitleID     pgtype.UUID `json:"job_title_id"`
+	JobTitleName   string      `json:"job_title_name"`
+	CompetencyID   pgtype.UUID `json:"competency_id"`
+	CompetencyCode string      `json:"competency_code"`
+	CompetencyName string      `json:"competency_name"`
+	CompetencyKind string      `json:"competency_kind"`
+	IsMandatory    bool        `json:"is_mandatory"`
+}
+
+func (q *Queries) ListCompetencyRequirementsForEmployees(ctx context.Context, arg ListCompetencyRequirementsForEmployeesParams) ([]ListCompetencyRequirementsForEmployeesRow, error) {
+	rows, err := q.db.Query(ctx, listCompetencyRequirementsForEmployees,
+		arg.TenantID,
+		arg.EmployeeID,
+		arg.DepartmentID,
+		arg.JobTitleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCompetencyRequirementsForEmployeesRow
+	for rows.Next() {
+		var i ListCompetencyRequirementsForEmployeesRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DepartmentID,
+			&i.JobTitleID,
+			&i.JobTitleName,
+			&i.CompetencyID,
+			&i.CompetencyCode,
+			&i.CompetencyName,
+			&i.CompetencyKind,
+			&i.IsMandatory,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDepartmentRefs = `-- name: ListDepartmentRefs :many
+SELECT id, code, is_active
+FROM departments
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL
+`
+
+type ListDepartmentRefsRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Code     pgtype.Text `json:"code"`
+	IsActive bool        `json:"is_active"`
+}
+
+func (q *Queries) ListDepartmentRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListDepartmentRefsRow, error) {
+	rows, err := q.db.Query(ctx, listDepartmentRefs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDepartmentRefsRow
+	for rows.Next() {
+		var i ListDepartmentRefsRow
+		if err := rows.Scan(&i.ID, &i.Code, &i.IsActive); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDepartments = `-- name: ListDepartments :many
+SELECT id, tenant_id, parent_department_id, code, name, is_active, created_at, updated_at
+FROM departments
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR name ILIKE '%' || $2::text || '%'
         OR code ILIKE '%' || $2::text || '%'
     )
-ORDER BY name
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'department'
+                AND cfv.entity_id = departments.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
+ORDER BY name
+LIMIT $5
+OFFSET
+    $4
+`
+
+type ListDepartmentsParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
+	Offset       int32       `json:"offset"`
+	Limit        int32       `json:"limit"`
+}
+
+func (q *Queries) ListDepartments(ctx context.Context, arg ListDepartmentsParams) ([]Department, error) {
+	rows, err := q.db.Query(ctx, listDepartments,
+		arg.TenantID,
+		arg.Search,
+		arg.CustomFields,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Department
+	for rows.Next() {
+		var i Department
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ParentDepartmentID,
+			&i.Code,
+			&i.Name,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDirectReports = `-- name: ListDirectReports :many
+SELECT
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    employees e
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+WHERE
+    e.tenant_id = $1
+    AND e.manager_id = $2
+ORDER BY e.last_name, e.first_name, e.id
+`
+
+type ListDirectReportsParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	ManagerID pgtype.UUID `json:"manager_id"`
+}
+
+type ListDirectReportsRow struct {
+	ID                pgtype.UUID        `json:"id"`
+	EmployeeNo        string             `json:"employee_no"`
+	FirstName         string             `json:"first_name"`
+	LastName          string             `json:"last_name"`
+	DisplayName       pgtype.Text        `json:"display_name"`
+	WorkEmail         pgtype.Text        `json:"work_email"`
+	Status            string             `json:"status"`
+	IsActive          bool               `json:"is_active"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ManagerID         pgtype.UUID        `json:"manager_id"`
+	BusinessUnitID    pgtype.UUID        `json:"business_unit_id"`
+	BusinessUnitCode  pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName  pgtype.Text        `json:"business_unit_name"`
+	DepartmentID      pgtype.UUID        `json:"department_id"`
+	DepartmentCode    pgtype.Text        `json:"department_code"`
+	DepartmentName    pgtype.Text        `json:"department_name"`
+	JobTitleID        pgtype.UUID        `json:"job_title_id"`
+	JobTitleCode      pgtype.Text        `json:"job_title_code"`
+	JobTitleName      pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade     pgtype.Text        `json:"job_title_grade"`
+	DirectReportCount int32              `json:"direct_report_count"`
+}
+
+func (q *Queries) ListDirectReports(ctx context.Context, arg ListDirectReportsParams) ([]ListDirectReportsRow, error) {
+	rows, err := q.db.Query(ctx, listDirectReports, arg.TenantID, arg.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDirectReportsRow
+	for rows.Next() {
+		var i ListDirectReportsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DisplayName,
+			&i.WorkEmail,
+			&i.Status,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ManagerID,
+			&i.BusinessUnitID,
+			&i.BusinessUnitCode,
+			&i.BusinessUnitName,
+			&i.DepartmentID,
+			&i.DepartmentCode,
+			&i.DepartmentName,
+			&i.JobTitleID,
+			&i.JobTitleCode,
+			&i.JobTitleName,
+			&i.JobTitleGrade,
+			&i.DirectReportCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployeeCompetencies = `-- name: ListEmployeeCompetencies :many
+SELECT
+    ec.id,
+    ec.competency_id,
+    c.code AS competency_code,
+    c.name AS competency_name,
+    c.kind AS competency_kind,
+    ec.achieved_on,
+    ec.expires_on,
+    ec.source,
+    ec.notes,
+    ec.recorded_by_user_id,
+    ec.created_at
+FROM
+    employee_competencies ec
+    JOIN competencies c ON c.id = ec.competency_id
+WHERE
+    ec.tenant_id = $1
+    AND ec.employee_id = $2
+ORDER BY ec.achieved_on DESC, c.code
+`
+
+type ListEmployeeCompetenciesParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	EmployeeID pgtype.UUID `json:"employee_id"`
+}
+
+type ListEmployeeCompetenciesRow struct {
+	ID               pgtype.UUID        `json:"id"`
+	CompetencyID     pgtype.UUID        `json:"competency_id"`
+	CompetencyCode   string             `json:"competency_code"`
+	CompetencyName   string             `json:"competency_name"`
+	CompetencyKind   string             `json:"competency_kind"`
+	AchievedOn       pgtype.Date        `json:"achieved_on"`
+	ExpiresOn        pgtype.Date        `json:"expires_on"`
+	Source           string             `json:"source"`
+	Notes            pgtype.Text        `json:"notes"`
+	RecordedByUserID pgtype.UUID        `json:"recorded_by_user_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListEmployeeCompetencies(ctx context.Context, arg ListEmployeeCompetenciesParams) ([]ListEmployeeCompetenciesRow, error) {
+	rows, err := q.db.Query(ctx, listEmployeeCompetencies, arg.TenantID, arg.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEmployeeCompetenciesRow
+	for rows.Next() {
+		var i ListEmployeeCompetenciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompetencyID,
+			&i.CompetencyCode,
+			&i.CompetencyName,
+			&i.CompetencyKind,
+			&i.AchievedOn,
+			&i.ExpiresOn,
+			&i.Source,
+			&i.Notes,
+			&i.RecordedByUserID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployeeRefs = `-- name: ListEmployeeRefs :many
+SELECT id, employee_no, work_email, is_active
+FROM employees
+WHERE
+    tenant_id = $1
+`
+
+type ListEmployeeRefsRow struct {
+	ID         pgtype.UUID `json:"id"`
+	EmployeeNo string      `json:"employee_no"`
+	WorkEmail  pgtype.Text `json:"work_email"`
+	IsActive   bool        `json:"is_active"`
+}
+
+func (q *Queries) ListEmployeeRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeeRefsRow, error) {
+	rows, err := q.db.Query(ctx, listEmployeeRefs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEmployeeRefsRow
+	for rows.Next() {
+		var i ListEmployeeRefsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EmployeeNo,
+			&i.WorkEmail,
+			&i.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployees = `-- name: ListEmployees :many
+SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id, erased_at, erased_by_user_id
+FROM employees
+WHERE
+    tenant_id = $1
+    AND (
+        $2::text = ''
+        OR first_name ILIKE '%' || $2::text || '%'
+        OR last_name ILIKE '%' || $2::text || '%'
+        OR display_name ILIKE '%' || $2::text || '%'
+        OR work_email ILIKE '%' || $2::text || '%'
+    )
+ORDER BY last_name, first_name
 LIMIT $4
 OFFSET
     $3
 `
 
-type ListBusinessUnitsParams struct {
+type ListEmployeesParams struct {
 	TenantID pgtype.UUID `json:"tenant_id"`
 	Search   string      `json:"search"`
 	Offset   int32       `json:"offset"`
 	Limit    int32       `json:"limit"`
 }
 
-func (q *Queries) ListBusinessUnits(ctx context.Context, arg ListBusinessUnitsParams) ([]BusinessUnit, error) {
-	rows, err := q.db.Query(ctx, listBusinessUnits,
+func (q *Queries) ListEmployees(ctx context.Context, arg ListEmployeesParams) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, listEmployees,
+		arg.TenantID,
+		arg.Search,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DisplayName,
+			&i.WorkEmail,
+			&i.Status,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.BusinessUnitID,
+			&i.DepartmentID,
+			&i.JobTitleID,
+			&i.ManagerID,
+			&i.ErasedAt,
+			&i.ErasedByUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployeesInInactiveOrgUnits = `-- name: ListEmployeesInInactiveOrgUnits :many
+SELECT
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    d.name AS department_name,
+    COALESCE(NOT d.is_active, false)::boolean AS department_inactive,
+    e.business_unit_id,
+    bu.name AS business_unit_name,
+    COALESCE(NOT bu.is_active, false)::boolean AS business_unit_inactive
+FROM
+    employees e
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+WHERE
+    e.tenant_id = $1
+    AND e.is_active
+    AND (
+        NOT d.is_active
+        OR NOT bu.is_active
+    )
+ORDER BY e.employee_no
+`
+
+type ListEmployeesInInactiveOrgUnitsRow struct {
+	ID                   pgtype.UUID `json:"id"`
+	EmployeeNo           string      `json:"employee_no"`
+	FirstName            string      `json:"first_name"`
+	LastName             string      `json:"last_name"`
+	DepartmentID         pgtype.UUID `json:"department_id"`
+	DepartmentName       pgtype.Text `json:"department_name"`
+	DepartmentInactive   bool        `json:"department_inactive"`
+	BusinessUnitID       pgtype.UUID `json:"business_unit_id"`
+	BusinessUnitName     pgtype.Text `json:"business_unit_name"`
+	BusinessUnitInactive bool        `json:"business_unit_inactive"`
+}
+
+func (q *Queries) ListEmployeesInInactiveOrgUnits(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeesInInactiveOrgUnitsRow, error) {
+	rows, err := q.db.Query(ctx, listEmployeesInInactiveOrgUnits, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEmployeesInInactiveOrgUnitsRow
+	for rows.Next() {
+		var i ListEmployeesInInactiveOrgUnitsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DepartmentID,
+			&i.DepartmentName,
+			&i.DepartmentInactive,
+			&i.BusinessUnitID,
+			&i.BusinessUnitName,
+			&i.BusinessUnitInactive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployeesWithDetails = `-- name: ListEmployeesWithDetails :many
+SELECT
+    e.id,
+    e.tenant_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.business_unit_id,
+    e.department_id,
+    e.job_title_id,
+    e.manager_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    d.code AS department_code,
+    d.name AS department_name,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    m.employee_no AS manager_employee_no,
+    m.first_name AS manager_first_name,
+    m.last_name AS manager_last_name,
+    m.display_name AS manager_display_name,
+    EXISTS (
+        SELECT 1
+        FROM
+            training_records tr
+            JOIN training_courses tc ON tc.id = tr.course_id
+        WHERE
+            tr.tenant_id = e.tenant_id
+            AND tr.employee_id = e.id
+            AND tc.is_mandatory
+            AND tc.is_active
+            AND tr.signed_off_at IS NOT NULL
+            AND tr.result = 'pass'
+        GROUP BY
+            tr.course_id
+        HAVING
+            bool_and(tr.expires_on IS NOT NULL)
+            AND max(tr.expires_on) < CURRENT_DATE
+    ) AS has_expired_mandatory_training,
+    COALESCE(cfv.field_values, '{}')::jsonb AS custom_fields
+FROM employees e
+LEFT JOIN business_units bu ON e.business_unit_id = bu.id AND e.tenant_id = bu.tenant_id
+LEFT JOIN departments d ON e.department_id = d.id AND e.tenant_id = d.tenant_id
+LEFT JOIN job_titles jt ON e.job_title_id = jt.id AND e.tenant_id = jt.tenant_id
+LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+LEFT JOIN employees m ON e.manager_id = m.id AND e.tenant_id = m.tenant_id
+LEFT JOIN custom_field_values cfv ON cfv.tenant_id = e.tenant_id AND cfv.entity_type = 'employee' AND cfv.entity_id = e.id
+WHERE
+    e.tenant_id = $1
+    AND (
+        $2::text = ''
+        OR e.first_name ILIKE '%' || $2::text || '%'
+        OR e.last_name ILIKE '%' || $2::text || '%'
+        OR e.display_name ILIKE '%' || $2::text || '%'
+        OR e.work_email ILIKE '%' || $2::text || '%'
+    )
+    AND (
+        $3::uuid IS NULL
+        OR e.department_id = $3::uuid
+        OR (
+            $4::boolean
+            AND e.department_id IN (
+                WITH RECURSIVE
+                    dept_tree AS (
+                        SELECT d1.id, ARRAY[d1.id]::uuid[] AS path
+                        FROM departments d1
+                        WHERE
+                            d1.tenant_id = $1
+                            AND d1.id = $3::uuid
+                        UNION ALL
+                        SELECT d2.id, dt.path || d2.id
+                        FROM departments d2
+                            INNER JOIN dept_tree dt ON d2.parent_department_id = dt.id
+                        WHERE
+                            d2.tenant_id = $1
+                            AND NOT d2.id = ANY (dt.path)
+                    )
+                SELECT id
+                FROM dept_tree
+            )
+        )
+    )
+    AND (
+        $5::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfx
+            WHERE
+                cfx.tenant_id = $1
+                AND cfx.entity_type = 'employee'
+                AND cfx.entity_id = e.id
+                AND cfx.field_values @> $5::jsonb
+        )
+    )
+ORDER BY e.last_name, e.first_name, e.id
+LIMIT $7
+OFFSET
+    $6
+`
+
+type ListEmployeesWithDetailsParams struct {
+	TenantID              pgtype.UUID `json:"tenant_id"`
+	Search                string      `json:"search"`
+	DepartmentID          pgtype.UUID `json:"department_id"`
+	IncludeSubDepartments bool        `json:"include_sub_departments"`
+	CustomFields          []byte      `json:"custom_fields"`
+	Offset                int32       `json:"offset"`
+	Limit                 int32       `json:"limit"`
+}
+
+type ListEmployeesWithDetailsRow struct {
+	ID                          pgtype.UUID        `json:"id"`
+	TenantID                    pgtype.UUID        `json:"tenant_id"`
+	EmployeeNo                  string             `json:"employee_no"`
+	FirstName                   string             `json:"first_name"`
+	LastName                    string             `json:"last_name"`
+	DisplayName                 pgtype.Text        `json:"display_name"`
+	WorkEmail                   pgtype.Text        `json:"work_email"`
+	Status                      string             `json:"status"`
+	IsActive                    bool               `json:"is_active"`
+	CreatedAt                   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt                   pgtype.Timestamptz `json:"updated_at"`
+	BusinessUnitID              pgtype.UUID        `json:"business_unit_id"`
+	DepartmentID                pgtype.UUID        `json:"department_id"`
+	JobTitleID                  pgtype.UUID        `json:"job_title_id"`
+	ManagerID                   pgtype.UUID        `json:"manager_id"`
+	BusinessUnitCode            pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName            pgtype.Text        `json:"business_unit_name"`
+	DepartmentCode              pgtype.Text        `json:"department_code"`
+	DepartmentName              pgtype.Text        `json:"department_name"`
+	JobTitleCode                pgtype.Text        `json:"job_title_code"`
+	JobTitleName                pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade               pgtype.Text        `json:"job_title_grade"`
+	ManagerEmployeeNo           pgtype.Text        `json:"manager_employee_no"`
+	ManagerFirstName            pgtype.Text        `json:"manager_first_name"`
+	ManagerLastName             pgtype.Text        `json:"manager_last_name"`
+	ManagerDisplayName          pgtype.Text        `json:"manager_display_name"`
+	HasExpiredMandatoryTraining bool               `json:"has_expired_mandatory_training"`
+	CustomFields                []byte             `json:"custom_fields"`
+}
+
+func (q *Queries) ListEmployeesWithDetails(ctx context.Context, arg ListEmployeesWithDetailsParams) ([]ListEmployeesWithDetailsRow, error) {
+	rows, err := q.db.Query(ctx, listEmployeesWithDetails,
 		arg.TenantID,
 		arg.Search,
+		arg.DepartmentID,
+		arg.IncludeSubDepartments,
+		arg.CustomFields,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -1118,17 +3124,38 @@ func (q *Queries) ListBusinessUnits(ctx context.Context, arg ListBusinessUnitsPa
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BusinessUnit
+	var items []ListEmployeesWithDetailsRow
 	for rows.Next() {
-		var i BusinessUnit
+		var i ListEmployeesWithDetailsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
-			&i.Code,
-			&i.Name,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DisplayName,
+			&i.WorkEmail,
+			&i.Status,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.BusinessUnitID,
+			&i.DepartmentID,
+			&i.JobTitleID,
+			&i.ManagerID,
+			&i.BusinessUnitCode,
+			&i.BusinessUnitName,
+			&i.DepartmentCode,
+			&i.DepartmentName,
+			&i.JobTitleCode,
+			&i.JobTitleName,
+			&i.JobTitleGrade,
+			&i.ManagerEmployeeNo,
+			&i.ManagerFirstName,
+			&i.ManagerLastName,
+			&i.ManagerDisplayName,
+			&i.HasExpiredMandatoryTraining,
+			&i.CustomFields,
 		); err != nil {
 			return nil, err
 		}
@@ -1140,9 +3167,110 @@ func (q *Queries) ListBusinessUnits(ctx context.Context, arg ListBusinessUnitsPa
 	return items, nil
 }
 
-const listDepartments = `-- name: ListDepartments :many
-SELECT id, tenant_id, parent_department_id, code, name, is_active, created_at, updated_at
-FROM departments
+const listEmployeesWithForeignManager = `-- name: ListEmployeesWithForeignManager :many
+SELECT e.id, e.employee_no, e.first_name, e.last_name, e.manager_id
+FROM employees e
+WHERE
+    e.tenant_id = $1
+    AND e.manager_id IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM employees m
+        WHERE
+            m.id = e.manager_id
+            AND m.tenant_id = e.tenant_id
+    )
+ORDER BY e.employee_no
+`
+
+type ListEmployeesWithForeignManagerRow struct {
+	ID         pgtype.UUID `json:"id"`
+	EmployeeNo string      `json:"employee_no"`
+	FirstName  string      `json:"first_name"`
+	LastName   string      `json:"last_name"`
+	ManagerID  pgtype.UUID `json:"manager_id"`
+}
+
+func (q *Queries) ListEmployeesWithForeignManager(ctx context.Context, tenantID pgtype.UUID) ([]ListEmployeesWithForeignManagerRow, error) {
+	rows, err := q.db.Query(ctx, listEmployeesWithForeignManager, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEmployeesWithForeignManagerRow
+	for rows.Next() {
+		var i ListEmployeesWithForeignManagerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.ManagerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInactiveManagersWithActiveReports = `-- name: ListInactiveManagersWithActiveReports :many
+SELECT m.id, m.employee_no, m.first_name, m.last_name, count(r.id) AS active_report_count
+FROM employees m
+    JOIN employees r ON r.manager_id = m.id
+    AND r.tenant_id = m.tenant_id
+WHERE
+    m.tenant_id = $1
+    AND NOT m.is_active
+    AND r.is_active
+GROUP BY
+    m.id,
+    m.employee_no,
+    m.first_name,
+    m.last_name
+ORDER BY m.employee_no
+`
+
+type ListInactiveManagersWithActiveReportsRow struct {
+	ID                pgtype.UUID `json:"id"`
+	EmployeeNo        string      `json:"employee_no"`
+	FirstName         string      `json:"first_name"`
+	LastName          string      `json:"last_name"`
+	ActiveReportCount int64       `json:"active_report_count"`
+}
+
+func (q *Queries) ListInactiveManagersWithActiveReports(ctx context.Context, tenantID pgtype.UUID) ([]ListInactiveManagersWithActiveReportsRow, error) {
+	rows, err := q.db.Query(ctx, listInactiveManagersWithActiveReports, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInactiveManagersWithActiveReportsRow
+	for rows.Next() {
+		var i ListInactiveManagersWithActiveReportsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EmployeeNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.ActiveReportCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobGrades = `-- name: ListJobGrades :many
+SELECT id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at
+FROM job_grades
 WHERE
     tenant_id = $1
     AND (
@@ -1150,21 +3278,21 @@ WHERE
         OR name ILIKE '%' || $2::text || '%'
         OR code ILIKE '%' || $2::text || '%'
     )
-ORDER BY name
+ORDER BY level, code
 LIMIT $4
 OFFSET
     $3
 `
 
-type ListDepartmentsParams struct {
+type ListJobGradesParams struct {
 	TenantID pgtype.UUID `json:"tenant_id"`
 	Search   string      `json:"search"`
 	Offset   int32       `json:"offset"`
 	Limit    int32       `json:"limit"`
 }
 
-func (q *Queries) ListDepartments(ctx context.Context, arg ListDepartmentsParams) ([]Department, error) {
-	rows, err := q.db.Query(ctx, listDepartments,
+func (q *Queries) ListJobGrades(ctx context.Context, arg ListJobGradesParams) ([]JobGrade, error) {
+	rows, err := q.db.Query(ctx, listJobGrades,
 		arg.TenantID,
 		arg.Search,
 		arg.Offset,
@@ -1174,15 +3302,19 @@ func (q *Queries) ListDepartments(ctx context.Context, arg ListDepartmentsParams
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Department
+	var items []JobGrade
 	for rows.Next() {
-		var i Department
+		var i JobGrade
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
-			&i.ParentDepartmentID,
 			&i.Code,
 			&i.Name,
+			&i.Level,
+			&i.Currency,
+			&i.MinPay,
+			&i.MidPay,
+			&i.MaxPay,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -1197,35 +3329,145 @@ func (q *Queries) ListDepartments(ctx context.Context, arg ListDepartmentsParams
 	return items, nil
 }
 
-const listEmployees = `-- name: ListEmployees :many
-SELECT id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id
-FROM employees
+const listJobTitleRefs = `-- name: ListJobTitleRefs :many
+SELECT id, code, is_active
+FROM job_titles
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL
+`
+
+type ListJobTitleRefsRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Code     pgtype.Text `json:"code"`
+	IsActive bool        `json:"is_active"`
+}
+
+func (q *Queries) ListJobTitleRefs(ctx context.Context, tenantID pgtype.UUID) ([]ListJobTitleRefsRow, error) {
+	rows, err := q.db.Query(ctx, listJobTitleRefs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobTitleRefsRow
+	for rows.Next() {
+		var i ListJobTitleRefsRow
+		if err := rows.Scan(&i.ID, &i.Code, &i.IsActive); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobTitleRequirements = `-- name: ListJobTitleRequirements :many
+SELECT
+    c.id,
+    c.code,
+    c.name,
+    c.kind,
+    c.document_ref,
+    c.validity_months,
+    c.is_active,
+    jtc.is_mandatory
+FROM
+    job_title_competencies jtc
+    JOIN competencies c ON c.id = jtc.competency_id
+WHERE
+    jtc.tenant_id = $1
+    AND jtc.job_title_id = $2
+ORDER BY c.code
+`
+
+type ListJobTitleRequirementsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	JobTitleID pgtype.UUID `json:"job_title_id"`
+}
+
+type ListJobTitleRequirementsRow struct {
+	ID             pgtype.UUID `json:"id"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Kind           string      `json:"kind"`
+	DocumentRef    pgtype.Text `json:"document_ref"`
+	ValidityMonths pgtype.Int4 `json:"validity_months"`
+	IsActive       bool        `json:"is_active"`
+	IsMandatory    bool        `json:"is_mandatory"`
+}
+
+func (q *Queries) ListJobTitleRequirements(ctx context.Context, arg ListJobTitleRequirementsParams) ([]ListJobTitleRequirementsRow, error) {
+	rows, err := q.db.Query(ctx, listJobTitleRequirements, arg.TenantID, arg.JobTitleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobTitleRequirementsRow
+	for rows.Next() {
+		var i ListJobTitleRequirementsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Kind,
+			&i.DocumentRef,
+			&i.ValidityMonths,
+			&i.IsActive,
+			&i.IsMandatory,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobTitles = `-- name: ListJobTitles :many
+SELECT id, tenant_id, code, name, is_active, created_at, updated_at, grade_id, default_department_id
+FROM job_titles
 WHERE
     tenant_id = $1
     AND (
         $2::text = ''
-        OR first_name ILIKE '%' || $2::text || '%'
-        OR last_name ILIKE '%' || $2::text || '%'
-        OR display_name ILIKE '%' || $2::text || '%'
-        OR work_email ILIKE '%' || $2::text || '%'
+        OR name ILIKE '%' || $2::text || '%'
+        OR code ILIKE '%' || $2::text || '%'
     )
-ORDER BY last_name, first_name
-LIMIT $4
+    AND (
+        $3::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'job_title'
+                AND cfv.entity_id = job_titles.id
+                AND cfv.field_values @> $3::jsonb
+        )
+    )
+ORDER BY name
+LIMIT $5
 OFFSET
-    $3
+    $4
 `
 
-type ListEmployeesParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Search   string      `json:"search"`
-	Offset   int32       `json:"offset"`
-	Limit    int32       `json:"limit"`
+type ListJobTitlesParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Search       string      `json:"search"`
+	CustomFields []byte      `json:"custom_fields"`
+	Offset       int32       `json:"offset"`
+	Limit        int32       `json:"limit"`
 }
 
-func (q *Queries) ListEmployees(ctx context.Context, arg ListEmployeesParams) ([]Employee, error) {
-	rows, err := q.db.Query(ctx, listEmployees,
+func (q *Queries) ListJobTitles(ctx context.Context, arg ListJobTitlesParams) ([]JobTitle, error) {
+	rows, err := q.db.Query(ctx, listJobTitles,
 		arg.TenantID,
 		arg.Search,
+		arg.CustomFields,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -1233,25 +3475,19 @@ func (q *Queries) ListEmployees(ctx context.Context, arg ListEmployeesParams) ([
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Employee
+	var items []JobTitle
 	for rows.Next() {
-		var i Employee
+		var i JobTitle
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
-			&i.EmployeeNo,
-			&i.FirstName,
-			&i.LastName,
-			&i.DisplayName,
-			&i.WorkEmail,
-			&i.Status,
+			&i.Code,
+			&i.Name,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.BusinessUnitID,
-			&i.DepartmentID,
-			&i.JobTitleID,
-			&i.ManagerID,
+			&i.GradeID,
+			&i.DefaultDepartmentID,
 		); err != nil {
 			return nil, err
 		}
@@ -1263,10 +3499,85 @@ func (q *Queries) ListEmployees(ctx context.Context, arg ListEmployeesParams) ([
 	return items, nil
 }
 
-const listEmployeesWithDetails = `-- name: ListEmployeesWithDetails :many
+const listLatestEmployeeCompetencies = `-- name: ListLatestEmployeeCompetencies :many
+SELECT
+    ec.employee_id,
+    ec.competency_id,
+    max(ec.achieved_on)::date AS last_achieved_on,
+    bool_or(ec.expires_on IS NULL)::boolean AS has_non_expiring,
+    max(ec.expires_on)::date AS latest_expires_on
+FROM
+    employee_competencies ec
+    JOIN employees e ON e.id = ec.employee_id
+WHERE
+    ec.tenant_id = $1
+    AND e.is_active
+    AND (
+        $2::uuid IS NULL
+        OR e.id = $2
+    )
+    AND (
+        $3::uuid IS NULL
+        OR e.department_id = $3
+    )
+    AND (
+        $4::uuid IS NULL
+        OR e.job_title_id = $4
+    )
+GROUP BY
+    ec.employee_id,
+    ec.competency_id
+`
+
+type ListLatestEmployeeCompetenciesParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	EmployeeID   pgtype.UUID `json:"employee_id"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	JobTitleID   pgtype.UUID `json:"job_title_id"`
+}
+
+type ListLatestEmployeeCompetenciesRow struct {
+	EmployeeID      pgtype.UUID `json:"employee_id"`
+	CompetencyID    pgtype.UUID `json:"competency_id"`
+	LastAchievedOn  pgtype.Date `json:"last_achieved_on"`
+	HasNonExpiring  bool        `json:"has_non_expiring"`
+	LatestExpiresOn pgtype.Date `json:"latest_expires_on"`
+}
+
+func (q *Queries) ListLatestEmployeeCompetencies(ctx context.Context, arg ListLatestEmployeeCompetenciesParams) ([]ListLatestEmployeeCompetenciesRow, error) {
+	rows, err := q.db.Query(ctx, listLatestEmployeeCompetencies,
+		arg.TenantID,
+		arg.EmployeeID,
+		arg.DepartmentID,
+		arg.JobTitleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestEmployeeCompetenciesRow
+	for rows.Next() {
+		var i ListLatestEmployeeCompetenciesRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.CompetencyID,
+			&i.LastAchievedOn,
+			&i.HasNonExpiring,
+			&i.LatestExpiresOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrgChartNodes = `-- name: ListOrgChartNodes :many
 SELECT
     e.id,
-    e.tenant_id,
     e.employee_no,
     e.first_name,
     e.last_name,
@@ -1276,94 +3587,75 @@ SELECT
     e.is_active,
     e.created_at,
     e.updated_at,
-    e.business_unit_id,
-    e.department_id,
-    e.job_title_id,
     e.manager_id,
+    e.business_unit_id,
     bu.code AS business_unit_code,
     bu.name AS business_unit_name,
+    e.department_id,
     d.code AS department_code,
     d.name AS department_name,
+    e.job_title_id,
     jt.code AS job_title_code,
     jt.name AS job_title_name,
-    jt.grade AS job_title_grade,
-    m.employee_no AS manager_employee_no,
-    m.first_name AS manager_first_name,
-    m.last_name AS manager_last_name,
-    m.display_name AS manager_display_name
-FROM employees e
-LEFT JOIN business_units bu ON e.business_unit_id = bu.id AND e.tenant_id = bu.tenant_id
-LEFT JOIN departments d ON e.department_id = d.id AND e.tenant_id = d.tenant_id
-LEFT JOIN job_titles jt ON e.job_title_id = jt.id AND e.tenant_id = jt.tenant_id
-LEFT JOIN employees m ON e.manager_id = m.id AND e.tenant_id = m.tenant_id
+    jg.code AS job_title_grade
+FROM
+    employees e
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
 WHERE
     e.tenant_id = $1
     AND (
-        $2::text = ''
-        OR e.first_name ILIKE '%' || $2::text || '%'
-        OR e.last_name ILIKE '%' || $2::text || '%'
-        OR e.display_name ILIKE '%' || $2::text || '%'
-        OR e.work_email ILIKE '%' || $2::text || '%'
+        $2::boolean
+        OR e.is_active
     )
-ORDER BY e.last_name, e.first_name
-LIMIT $4
-OFFSET
-    $3
+ORDER BY e.last_name, e.first_name, e.id
 `
 
-type ListEmployeesWithDetailsParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Search   string      `json:"search"`
-	Offset   int32       `json:"offset"`
-	Limit    int32       `json:"limit"`
+type ListOrgChartNodesParams struct {
+	TenantID        pgtype.UUID `json:"tenant_id"`
+	IncludeInactive bool        `json:"include_inactive"`
 }
 
-type ListEmployeesWithDetailsRow struct {
-	ID                 pgtype.UUID        `json:"id"`
-	TenantID           pgtype.UUID        `json:"tenant_id"`
-	EmployeeNo         string             `json:"employee_no"`
-	FirstName          string             `json:"first_name"`
-	LastName           string             `json:"last_name"`
-	DisplayName        pgtype.Text        `json:"display_name"`
-	WorkEmail          pgtype.Text        `json:"work_email"`
-	Status             string             `json:"status"`
-	IsActive           bool               `json:"is_active"`
-	CreatedAt          pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
-	BusinessUnitID     pgtype.UUID        `json:"business_unit_id"`
-	DepartmentID       pgtype.UUID        `json:"department_id"`
-	JobTitleID         pgtype.UUID        `json:"job_title_id"`
-	ManagerID          pgtype.UUID        `json:"manager_id"`
-	BusinessUnitCode   pgtype.Text        `json:"business_unit_code"`
-	BusinessUnitName   pgtype.Text        `json:"business_unit_name"`
-	DepartmentCode     pgtype.Text        `json:"department_code"`
-	DepartmentName     pgtype.Text        `json:"department_name"`
-	JobTitleCode       pgtype.Text        `json:"job_title_code"`
-	JobTitleName       pgtype.Text        `json:"job_title_name"`
-	JobTitleGrade      pgtype.Text        `json:"job_title_grade"`
-	ManagerEmployeeNo  pgtype.Text        `json:"manager_employee_no"`
-	ManagerFirstName   pgtype.Text        `json:"manager_first_name"`
-	ManagerLastName    pgtype.Text        `json:"manager_last_name"`
-	ManagerDisplayName pgtype.Text        `json:"manager_display_name"`
+type ListOrgChartNodesRow struct {
+	ID               pgtype.UUID        `json:"id"`
+	EmployeeNo       string             `json:"employee_no"`
+	FirstName        string             `json:"first_name"`
+	LastName         string             `json:"last_name"`
+	DisplayName      pgtype.Text        `json:"display_name"`
+	WorkEmail        pgtype.Text        `json:"work_email"`
+	Status           string             `json:"status"`
+	IsActive         bool               `json:"is_active"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ManagerID        pgtype.UUID        `json:"manager_id"`
+	BusinessUnitID   pgtype.UUID        `json:"business_unit_id"`
+	BusinessUnitCode pgtype.Text        `json:"business_unit_code"`
+	BusinessUnitName pgtype.Text        `json:"business_unit_name"`
+	DepartmentID     pgtype.UUID        `json:"department_id"`
+	DepartmentCode   pgtype.Text        `json:"department_code"`
+	DepartmentName   pgtype.Text        `json:"department_name"`
+	JobTitleID       pgtype.UUID        `json:"job_title_id"`
+	JobTitleCode     pgtype.Text        `json:"job_title_code"`
+	JobTitleName     pgtype.Text        `json:"job_title_name"`
+	JobTitleGrade    pgtype.Text        `json:"job_title_grade"`
 }
 
-func (q *Queries) ListEmployeesWithDetails(ctx context.Context, arg ListEmployeesWithDetailsParams) ([]ListEmployeesWithDetailsRow, error) {
-	rows, err := q.db.Query(ctx, listEmployeesWithDetails,
-		arg.TenantID,
-		arg.Search,
-		arg.Offset,
-		arg.Limit,
-	)
+func (q *Queries) ListOrgChartNodes(ctx context.Context, arg ListOrgChartNodesParams) ([]ListOrgChartNodesRow, error) {
+	rows, err := q.db.Query(ctx, listOrgChartNodes, arg.TenantID, arg.IncludeInactive)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListEmployeesWithDetailsRow
+	var items []ListOrgChartNodesRow
 	for rows.Next() {
-		var i ListEmployeesWithDetailsRow
+		var i ListOrgChartNodesRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.TenantID,
 			&i.EmployeeNo,
 			&i.FirstName,
 			&i.LastName,
@@ -1373,21 +3665,17 @@ func (q *Queries) ListEmployeesWithDetails(ctx context.Context, arg ListEmployee
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.BusinessUnitID,
-			&i.DepartmentID,
-			&i.JobTitleID,
 			&i.ManagerID,
+			&i.BusinessUnitID,
 			&i.BusinessUnitCode,
 			&i.BusinessUnitName,
+			&i.DepartmentID,
 			&i.DepartmentCode,
 			&i.DepartmentName,
+			&i.JobTitleID,
 			&i.JobTitleCode,
 			&i.JobTitleName,
 			&i.JobTitleGrade,
-			&i.ManagerEmployeeNo,
-			&i.ManagerFirstName,
-			&i.ManagerLastName,
-			&i.ManagerDisplayName,
 		); err != nil {
 			return nil, err
 		}
@@ -1399,52 +3687,29 @@ func (q *Queries) ListEmployeesWithDetails(ctx context.Context, arg ListEmployee
 	return items, nil
 }
 
-const listJobTitles = `-- name: ListJobTitles :many
-SELECT id, tenant_id, code, name, grade, is_active, created_at, updated_at
-FROM job_titles
-WHERE
-    tenant_id = $1
-    AND (
-        $2::text = ''
-        OR name ILIKE '%' || $2::text || '%'
-        OR code ILIKE '%' || $2::text || '%'
-    )
-ORDER BY name
-LIMIT $4
-OFFSET
-    $3
+const listRoles = `-- name: ListRoles :many
+SELECT id, tenant_id, code, name, description, is_active, created_at, updated_at, external_id FROM rbac_roles WHERE tenant_id = $1 ORDER BY name
 `
 
-type ListJobTitlesParams struct {
-	TenantID pgtype.UUID `json:"tenant_id"`
-	Search   string      `json:"search"`
-	Offset   int32       `json:"offset"`
-	Limit    int32       `json:"limit"`
-}
-
-func (q *Queries) ListJobTitles(ctx context.Context, arg ListJobTitlesParams) ([]JobTitle, error) {
-	rows, err := q.db.Query(ctx, listJobTitles,
-		arg.TenantID,
-		arg.Search,
-		arg.Offset,
-		arg.Limit,
-	)
+func (q *Queries) ListRoles(ctx context.Context, tenantID pgtype.UUID) ([]RbacRole, error) {
+	rows, err := q.db.Query(ctx, listRoles, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []JobTitle
+	var items []RbacRole
 	for rows.Next() {
-		var i JobTitle
+		var i RbacRole
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
 			&i.Code,
 			&i.Name,
-			&i.Grade,
+			&i.Description,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ExternalID,
 		); err != nil {
 			return nil, err
 		}
@@ -1456,28 +3721,24 @@ func (q *Queries) ListJobTitles(ctx context.Context, arg ListJobTitlesParams) ([
 	return items, nil
 }
 
-const listRoles = `-- name: ListRoles :many
-SELECT id, tenant_id, code, name, description, is_active, created_at, updated_at FROM rbac_roles WHERE tenant_id = $1 ORDER BY name
+const listTenants = `-- name: ListTenants :many
+SELECT id, code, name, created_at FROM tenants ORDER BY name
 `
 
-func (q *Queries) ListRoles(ctx context.Context, tenantID pgtype.UUID) ([]RbacRole, error) {
-	rows, err := q.db.Query(ctx, listRoles, tenantID)
+func (q *Queries) ListTenants(ctx context.Context) ([]Tenant, error) {
+	rows, err := q.db.Query(ctx, listTenants)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacRole
+	var items []Tenant
 	for rows.Next() {
-		var i RbacRole
+		var i Tenant
 		if err := rows.Scan(
 			&i.ID,
-			&i.TenantID,
 			&i.Code,
 			&i.Name,
-			&i.Description,
-			&i.IsActive,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1489,25 +3750,32 @@ func (q *Queries) ListRoles(ctx context.Context, tenantID pgtype.UUID) ([]RbacRo
 	return items, nil
 }
 
-const listTenants = `-- name: ListTenants :many
-SELECT id, code, name, created_at FROM tenants ORDER BY name
+const listUserRoleCodes = `-- name: ListUserRoleCodes :many
+SELECT ur.user_id, r.code
+FROM
+    user_rbac_roles ur
+    JOIN rbac_roles r ON ur.role_id = r.id
+    AND ur.tenant_id = r.tenant_id
+WHERE
+    ur.tenant_id = $1
+ORDER BY r.code
 `
 
-func (q *Queries) ListTenants(ctx context.Context) ([]Tenant, error) {
-	rows, err := q.db.Query(ctx, listTenants)
+type ListUserRoleCodesRow struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Code   string      `json:"code"`
+}
+
+func (q *Queries) ListUserRoleCodes(ctx context.Context, tenantID pgtype.UUID) ([]ListUserRoleCodesRow, error) {
+	rows, err := q.db.Query(ctx, listUserRoleCodes, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Tenant
+	var items []ListUserRoleCodesRow
 	for rows.Next() {
-		var i Tenant
-		if err := rows.Scan(
-			&i.ID,
-			&i.Code,
-			&i.Name,
-			&i.CreatedAt,
-		); err != nil {
+		var i ListUserRoleCodesRow
+		if err := rows.Scan(&i.UserID, &i.Code); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1519,7 +3787,7 @@ func (q *Queries) ListTenants(ctx context.Context) ([]Tenant, error) {
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at
+SELECT id, tenant_id, employee_id, email, display_name, password_hash, is_active, last_login_at, created_at, updated_at, locale, external_id, avatar_url, preferences
 FROM users
 WHERE
     tenant_id = $1
@@ -1566,6 +3834,10 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.LastLoginAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
+			&i.ExternalID,
+			&i.AvatarUrl,
+			&i.Preferences,
 		); err != nil {
 			return nil, err
 		}
@@ -1595,3 +3867,275 @@ func (q *Queries) RevokeUserRole(ctx context.Context, arg RevokeUserRoleParams)
 	_, err := q.db.Exec(ctx, revokeUserRole, arg.TenantID, arg.UserID, arg.RoleID)
 	return err
 }
+
+const unlinkBusinessUnitDepartment = `-- name: UnlinkBusinessUnitDepartment :execrows
+DELETE FROM business_unit_departments
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3
+`
+
+type UnlinkBusinessUnitDepartmentParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	BusinessUnitID pgtype.UUID `json:"business_unit_id"`
+	DepartmentID   pgtype.UUID `json:"department_id"`
+}
+
+func (q *Queries) UnlinkBusinessUnitDepartment(ctx context.Context, arg UnlinkBusinessUnitDepartmentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unlinkBusinessUnitDepartment, arg.TenantID, arg.BusinessUnitID, arg.DepartmentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateCompetency = `-- name: UpdateCompetency :one
+UPDATE competencies
+SET
+    code = $3,
+    name = $4,
+    description = $5,
+    kind = $6,
+    document_ref = $7,
+    validity_months = $8,
+    is_active = $9,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    id, tenant_id, code, name, description, kind, document_ref, validity_months, is_active, created_at, updated_at
+`
+
+type UpdateCompetencyParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	ID             pgtype.UUID `json:"id"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Description    pgtype.Text `json:"description"`
+	Kind           string      `json:"kind"`
+	DocumentRef    pgtype.Text `json:"document_ref"`
+	ValidityMonths pgtype.Int4 `json:"validity_months"`
+	IsActive       bool        `json:"is_active"`
+}
+
+func (q *Queries) UpdateCompetency(ctx context.Context, arg UpdateCompetencyParams) (Competency, error) {
+	row := q.db.QueryRow(ctx, updateCompetency,
+		arg.TenantID,
+		arg.ID,
+		arg.Code,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.DocumentRef,
+		arg.ValidityMonths,
+		arg.IsActive,
+	)
+	var i Competency
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.DocumentRef,
+		&i.ValidityMonths,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateDepartmentParent = `-- name: UpdateDepartmentParent :one
+UPDATE departments
+SET
+    parent_department_id = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    id, tenant_id, parent_department_id, code, name, is_active, created_at, updated_at
+`
+
+type UpdateDepartmentParentParams struct {
+	TenantID           pgtype.UUID `json:"tenant_id"`
+	ID                 pgtype.UUID `json:"id"`
+	ParentDepartmentID pgtype.UUID `json:"parent_department_id"`
+}
+
+func (q *Queries) UpdateDepartmentParent(ctx context.Context, arg UpdateDepartmentParentParams) (Department, error) {
+	row := q.db.QueryRow(ctx, updateDepartmentParent, arg.TenantID, arg.ID, arg.ParentDepartmentID)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ParentDepartmentID,
+		&i.Code,
+		&i.Name,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateEmployeeManager = `-- name: UpdateEmployeeManager :one
+UPDATE employees
+SET
+    manager_id = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    id, tenant_id, employee_no, first_name, last_name, display_name, work_email, status, is_active, created_at, updated_at, business_unit_id, department_id, job_title_id, manager_id, erased_at, erased_by_user_id
+`
+
+type UpdateEmployeeManagerParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	ID        pgtype.UUID `json:"id"`
+	ManagerID pgtype.UUID `json:"manager_id"`
+}
+
+func (q *Queries) UpdateEmployeeManager(ctx context.Context, arg UpdateEmployeeManagerParams) (Employee, error) {
+	row := q.db.QueryRow(ctx, updateEmployeeManager, arg.TenantID, arg.ID, arg.ManagerID)
+	var i Employee
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EmployeeNo,
+		&i.FirstName,
+		&i.LastName,
+		&i.DisplayName,
+		&i.WorkEmail,
+		&i.Status,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BusinessUnitID,
+		&i.DepartmentID,
+		&i.JobTitleID,
+		&i.ManagerID,
+		&i.ErasedAt,
+		&i.ErasedByUserID,
+	)
+	return i, err
+}
+
+const updateJobGrade = `-- name: UpdateJobGrade :one
+UPDATE job_grades
+SET
+    code = $3,
+    name = $4,
+    level = $5,
+    currency = $6,
+    min_pay = $7,
+    mid_pay = $8,
+    max_pay = $9,
+    is_active = $10,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    id, tenant_id, code, name, level, currency, min_pay, mid_pay, max_pay, is_active, created_at, updated_at
+`
+
+type UpdateJobGradeParams struct {
+	TenantID pgtype.UUID    `json:"tenant_id"`
+	ID       pgtype.UUID    `json:"id"`
+	Code     string         `json:"code"`
+	Name     string         `json:"name"`
+	Level    int32          `json:"level"`
+	Currency string         `json:"currency"`
+	MinPay   pgtype.Numeric `json:"min_pay"`
+	MidPay   pgtype.Numeric `json:"mid_pay"`
+	MaxPay   pgtype.Numeric `json:"max_pay"`
+	IsActive bool           `json:"is_active"`
+}
+
+func (q *Queries) UpdateJobGrade(ctx context.Context, arg UpdateJobGradeParams) (JobGrade, error) {
+	row := q.db.QueryRow(ctx, updateJobGrade,
+		arg.TenantID,
+		arg.ID,
+		arg.Code,
+		arg.Name,
+		arg.Level,
+		arg.Currency,
+		arg.MinPay,
+		arg.MidPay,
+		arg.MaxPay,
+		arg.IsActive,
+	)
+	var i JobGrade
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.Level,
+		&i.Currency,
+		&i.MinPay,
+		&i.MidPay,
+		&i.MaxPay,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateJobTitle = `-- name: UpdateJobTitle :one
+UPDATE job_titles
+SET
+    code = $3,
+    name = $4,
+    grade_id = $5,
+    default_department_id = $6,
+    is_active = $7,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    id, tenant_id, code, name, is_active, created_at, updated_at, grade_id, default_department_id
+`
+
+type UpdateJobTitleParams struct {
+	TenantID            pgtype.UUID `json:"tenant_id"`
+	ID                  pgtype.UUID `json:"id"`
+	Code                pgtype.Text `json:"code"`
+	Name                string      `json:"name"`
+	GradeID             pgtype.UUID `json:"grade_id"`
+	DefaultDepartmentID pgtype.UUID `json:"default_department_id"`
+	IsActive            bool        `json:"is_active"`
+}
+
+func (q *Queries) UpdateJobTitle(ctx context.Context, arg UpdateJobTitleParams) (JobTitle, error) {
+	row := q.db.QueryRow(ctx, updateJobTitle,
+		arg.TenantID,
+		arg.ID,
+		arg.Code,
+		arg.Name,
+		arg.GradeID,
+		arg.DefaultDepartmentID,
+		arg.IsActive,
+	)
+	var i JobTitle
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Code,
+		&i.Name,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GradeID,
+		&i.DefaultDepartmentID,
+	)
+	return i, err
+}
@@ -0,0 +1,237 @@
+// Package envelope encrypts sensitive columns with envelope keys: every record gets a
+// random data key that encrypts its values, and the data key is stored encrypted
+// ("wrapped") by a master key that never leaves the application servers. Rotating a
+// master key only needs the data keys re-wrapped.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// keySize is the length of master and data keys; both are AES-256 keys
+const keySize = 32
+
+var (
+	// ErrUnknownKey is returned for data wrapped with a master key that is not loaded
+	ErrUnknownKey = errors.New("envelope: master key not loaded")
+
+	// ErrDecrypt is returned when a ciphertext was tampered with, or moved to another record
+	ErrDecrypt = errors.New("envelope: decryption failed")
+)
+
+// Options locates the master keys
+type Options struct {
+	// Dir holds one master key per file, named <kid>.key and containing 32 random bytes
+	// in base64, e.g. from `openssl rand -base64 32`. Retired keys must stay until every
+	// data key has been re-wrapped. When empty an ephemeral key is generated, for
+	// development only.
+	Dir string
+	// ActiveKeyID picks the master key new data keys are wrapped with. It defaults to the
+	// key whose kid sorts last, so dated names such as 2026-10.key rotate naturally.
+	ActiveKeyID string
+}
+
+// Keyring holds the master keys
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// LoadKeyring reads the master keys from opts.Dir, or generates an ephemeral key when no
+// directory is configured
+func LoadKeyring(opts Options) (*Keyring, error) {
+	if opts.Dir == "" {
+		log.Println("WARNING: DATA_KEYS_DIR is not set. Encrypting personal data with an ephemeral key; it is unreadable after restart!")
+		return generateKeyring()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(opts.Dir, "*.key"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	kr := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("%s: master key is %d bytes; %d are required", path, len(key), keySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), ".key")
+		kr.keys[kid] = aead
+		kr.active = kid
+	}
+
+	if opts.ActiveKeyID != "" {
+		if _, ok := kr.keys[opts.ActiveKeyID]; !ok {
+			return nil, fmt.Errorf("master key %q not found in %s", opts.ActiveKeyID, opts.Dir)
+		}
+		kr.active = opts.ActiveKeyID
+	}
+	if kr.active == "" {
+		return nil, fmt.Errorf("no master key in %s", opts.Dir)
+	}
+	return kr, nil
+}
+
+func generateKeyring() (*Keyring, error) {
+	key, err := randomBytes(keySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomBytes(4)
+	if err != nil {
+		return nil, err
+	}
+	kid := "ephemeral-" + hex.EncodeToString(id)
+	return &Keyring{keys: map[string]cipher.AEAD{kid: aead}, active: kid}, nil
+}
+
+// ActiveKeyID names the master key new data keys are wrapped with
+func (kr *Keyring) ActiveKeyID() string {
+	return kr.active
+}
+
+// DataKey encrypts the values of one record
+type DataKey struct {
+	// KeyID names the master key that wrapped the data key
+	KeyID string
+	// Wrapped is the data key encrypted with the master key, as stored with the record
+	Wrapped []byte
+
+	key  []byte
+	aead cipher.AEAD
+}
+
+// NewDataKey generates a data key wrapped with the active master key
+func (kr *Keyring) NewDataKey() (*DataKey, error) {
+	key, err := randomBytes(keySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	dk := &DataKey{key: key, aead: aead}
+	if err := kr.wrap(dk); err != nil {
+		return nil, err
+	}
+	return dk, nil
+}
+
+// OpenDataKey unwraps a stored data key
+func (kr *Keyring) OpenDataKey(keyID string, wrapped []byte) (*DataKey, error) {
+	master, ok := kr.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	key, err := open(master, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{KeyID: keyID, Wrapped: wrapped, key: key, aead: aead}, nil
+}
+
+// Rewrap wraps dk with the active master key if it is wrapped with another one and
+// reports whether it changed
+func (kr *Keyring) Rewrap(dk *DataKey) (bool, error) {
+	if dk.KeyID == kr.active {
+		return false, nil
+	}
+	return true, kr.wrap(dk)
+}
+
+func (kr *Keyring) wrap(dk *DataKey) error {
+	wrapped, err := seal(kr.keys[kr.active], dk.key, []byte(kr.active))
+	if err != nil {
+		return err
+	}
+	dk.KeyID, dk.Wrapped = kr.active, wrapped
+	return nil
+}
+
+// Encrypt encrypts a value. aad binds the ciphertext to where it is stored, such as the
+// record ID and column, so it cannot be copied into another record. Nil stays nil.
+func (dk *DataKey) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	if plaintext == nil {
+		return nil, nil
+	}
+	return seal(dk.aead, plaintext, aad)
+}
+
+// Decrypt reverses Encrypt given the same aad
+func (dk *DataKey) Decrypt(ciphertext, aad []byte) ([]byte, error) {
+	if ciphertext == nil {
+		return nil, nil
+	}
+	return open(dk.aead, ciphertext, aad)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the nonce followed by the AES-GCM ciphertext
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,268 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeMasterKey stores a random master key as <kid>.key the way operators create them
+func writeMasterKey(t *testing.T, dir, kid string) {
+	t.Helper()
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	writeKeyFile(t, dir, kid, base64.StdEncoding.EncodeToString(key)+"\n")
+}
+
+func writeKeyFile(t *testing.T, dir, kid, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, kid+".key"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadKeyring(t *testing.T, opts Options) *Keyring {
+	t.Helper()
+	kr, err := LoadKeyring(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestRoundTrip(t *testing.T) {
+	kr := loadKeyring(t, Options{})
+	dk, err := kr.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dk.KeyID != kr.ActiveKeyID() {
+		t.Fatalf("NewDataKey() wrapped with %q, want the active key %q", dk.KeyID, kr.ActiveKeyID())
+	}
+
+	plaintext := []byte("AB123456C")
+	aad := []byte("employee-1/ni_number")
+	ciphertext, err := dk.Encrypt(plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	again, err := dk.Encrypt(plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ciphertext, again) {
+		t.Error("Encrypt() reused a nonce: equal plaintexts gave equal ciphertexts")
+	}
+
+	// A data key read back from storage decrypts what the original encrypted
+	stored, err := kr.OpenDataKey(dk.KeyID, dk.Wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := stored.Decrypt(ciphertext, aad)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Decrypt() = %q, %v; want %q", got, err, plaintext)
+	}
+
+	empty, err := dk.Encrypt([]byte{}, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dk.Decrypt(empty, aad); err != nil || len(got) != 0 {
+		t.Fatalf("Decrypt() = %q, %v for an empty value", got, err)
+	}
+}
+
+func TestNilStaysNil(t *testing.T) {
+	dk, err := loadKeyring(t, Options{}).NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dk.Encrypt(nil, []byte("aad")); got != nil || err != nil {
+		t.Fatalf("Encrypt(nil) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := dk.Decrypt(nil, []byte("aad")); got != nil || err != nil {
+		t.Fatalf("Decrypt(nil) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestAADBindsCiphertextToItsPlace(t *testing.T) {
+	dk, err := loadKeyring(t, Options{}).NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := dk.Encrypt([]byte("07700 900123"), []byte("employee-1/phone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, aad := range []string{"employee-2/phone", "employee-1/email", ""} {
+		if _, err := dk.Decrypt(ciphertext, []byte(aad)); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Decrypt() with aad %q = %v, want ErrDecrypt", aad, err)
+		}
+	}
+}
+
+func TestTamperingIsDetected(t *testing.T) {
+	kr := loadKeyring(t, Options{})
+	dk, err := kr.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aad := []byte("employee-1/address")
+	ciphertext, err := dk.Encrypt([]byte("1 High Street"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range ciphertext {
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[i] ^= 0x01
+		if _, err := dk.Decrypt(tampered, aad); !errors.Is(err, ErrDecrypt) {
+			t.Fatalf("Decrypt() with byte %d flipped = %v, want ErrDecrypt", i, err)
+		}
+	}
+	if _, err := dk.Decrypt(ciphertext[:len(ciphertext)-1], aad); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() of a truncated ciphertext = %v, want ErrDecrypt", err)
+	}
+	if _, err := dk.Decrypt([]byte{1, 2, 3}, aad); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() of a ciphertext shorter than a nonce = %v, want ErrDecrypt", err)
+	}
+
+	// Wrapped data keys are authenticated too
+	wrapped := append([]byte(nil), dk.Wrapped...)
+	wrapped[len(wrapped)-1] ^= 0x01
+	if _, err := kr.OpenDataKey(dk.KeyID, wrapped); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("OpenDataKey() of a tampered key = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestUnknownMasterKey(t *testing.T) {
+	dk, err := loadKeyring(t, Options{}).NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another keyring, such as a server restarted with an ephemeral key, cannot open it
+	other := loadKeyring(t, Options{})
+	if _, err := other.OpenDataKey(dk.KeyID, dk.Wrapped); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("OpenDataKey() = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestWrappedKeyIsBoundToItsMasterKeyID(t *testing.T) {
+	dir := t.TempDir()
+	writeMasterKey(t, dir, "2026-01")
+	writeMasterKey(t, dir, "2026-07")
+	kr := loadKeyring(t, Options{Dir: dir})
+
+	dk, err := kr.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kr.OpenDataKey("2026-01", dk.Wrapped); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("OpenDataKey() under another kid = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestLoadKeyringPicksActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	writeMasterKey(t, dir, "2026-01")
+	writeMasterKey(t, dir, "2026-07")
+	// Files without the .key suffix are ignored
+	if err := os.WriteFile(filepath.Join(dir, "2027-01.key.bak"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loadKeyring(t, Options{Dir: dir}).ActiveKeyID(); got != "2026-07" {
+		t.Errorf("ActiveKeyID() = %q, want 2026-07", got)
+	}
+	if got := loadKeyring(t, Options{Dir: dir, ActiveKeyID: "2026-01"}).ActiveKeyID(); got != "2026-01" {
+		t.Errorf("ActiveKeyID() = %q, want 2026-01", got)
+	}
+	if _, err := LoadKeyring(Options{Dir: dir, ActiveKeyID: "2025-01"}); err == nil {
+		t.Error("LoadKeyring() accepted an active key that is not loaded")
+	}
+}
+
+func TestLoadKeyringRefusesBadKeys(t *testing.T) {
+	cases := map[string]string{
+		"short key":   base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"long key":    base64.StdEncoding.EncodeToString(make([]byte, 64)),
+		"not base64":  "this is not base64!",
+		"empty file":  "",
+		"hex encoded": "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff",
+	}
+	for name, content := range cases {
+		dir := t.TempDir()
+		writeKeyFile(t, dir, "bad", content)
+		if _, err := LoadKeyring(Options{Dir: dir}); err == nil {
+			t.Errorf("%s: LoadKeyring() accepted it", name)
+		}
+	}
+
+	if _, err := LoadKeyring(Options{Dir: t.TempDir()}); err == nil {
+		t.Error("LoadKeyring() accepted a directory without master keys")
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	dir := t.TempDir()
+	writeMasterKey(t, dir, "2026-01")
+	before := loadKeyring(t, Options{Dir: dir})
+
+	dk, err := before.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aad := []byte("employee-1/dob")
+	ciphertext, err := dk.Encrypt([]byte("1980-02-29"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: a new master key becomes active while the old one stays loaded
+	writeMasterKey(t, dir, "2026-07")
+	after := loadKeyring(t, Options{Dir: dir})
+
+	stored, err := after.OpenDataKey(dk.KeyID, dk.Wrapped)
+	if err != nil {
+		t.Fatalf("OpenDataKey() of a key wrapped before rotation: %v", err)
+	}
+	changed, err := after.Rewrap(stored)
+	if err != nil || !changed {
+		t.Fatalf("Rewrap() = %v, %v; want true, nil", changed, err)
+	}
+	if stored.KeyID != "2026-07" || bytes.Equal(stored.Wrapped, dk.Wrapped) {
+		t.Fatalf("Rewrap() left the key wrapped with %q", stored.KeyID)
+	}
+	if changed, err := after.Rewrap(stored); err != nil || changed {
+		t.Fatalf("second Rewrap() = %v, %v; want false, nil", changed, err)
+	}
+
+	// The old master key can be retired once the data key is re-wrapped, and values
+	// encrypted before rotation still decrypt
+	if err := os.Remove(filepath.Join(dir, "2026-01.key")); err != nil {
+		t.Fatal(err)
+	}
+	retired := loadKeyring(t, Options{Dir: dir})
+	reopened, err := retired.OpenDataKey(stored.KeyID, stored.Wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Decrypt(ciphertext, aad)
+	if err != nil || string(got) != "1980-02-29" {
+		t.Fatalf("Decrypt() after rotation = %q, %v", got, err)
+	}
+	if _, err := retired.OpenDataKey(dk.KeyID, dk.Wrapped); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("OpenDataKey() with a retired master key = %v, want ErrUnknownKey", err)
+	}
+}
@@ -0,0 +1,492 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/apikeys"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type APIKeyHandler struct {
+	service *logic.APIKeyService
+}
+
+func NewAPIKeyHandler(service *logic.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// RegisterServiceAccountRoutes mounts the admin endpoints for service accounts and their keys
+func (h *APIKeyHandler) RegisterServiceAccountRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleListServiceAccounts)
+	r.With(admin).Post("/", h.HandleCreateServiceAccount)
+	r.With(admin).Get("/{id}", h.HandleGetServiceAccount)
+	r.With(admin).Put("/{id}", h.HandleUpdateServiceAccount)
+	r.With(admin).Get("/{id}/keys", h.HandleListServiceAccountKeys)
+	r.With(admin).Post("/{id}/keys", h.HandleIssueServiceAccountKey)
+}
+
+// RegisterAdminRoutes mounts the admin endpoints over every key of the tenant, so leaked
+// personal keys can be revoked too
+func (h *APIKeyHandler) RegisterAdminRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleListKeys)
+	r.With(admin).Delete("/{id}", h.HandleRevokeKey)
+}
+
+// RegisterMeRoutes mounts the endpoints managing the caller's personal keys
+func (h *APIKeyHandler) RegisterMeRoutes(r chi.Router) {
+	r.Get("/", h.HandleListMyKeys)
+	r.Post("/", h.HandleIssueMyKey)
+	r.Delete("/{id}", h.HandleRevokeMyKey)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, logic.ErrInvalidRoles),
+		errors.Is(err, logic.ErrExpiryInPast):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrRolesExceedIssuer):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrServiceAccountInactive):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// refuseAPIKey stops a key from issuing further keys, so a leaked key cannot be used to
+// outlive its own revocation
+func refuseAPIKey(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := authHTTP.GetAPIKeyIDFromContext(r.Context()); ok {
+		response.Error(w, http.StatusForbidden, "API keys cannot be issued with an API key")
+		return true
+	}
+	return false
+}
+
+type ServiceAccountRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=500"`
+	// IsActive defaults to true; it is ignored on create
+	IsActive *bool `json:"isActive"`
+}
+
+type KeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	RoleIDs   []string   `json:"roleIds" validate:"required,min=1,dive,uuid"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (req KeyRequest) input() logic.KeyInput {
+	in := logic.KeyInput{Name: req.Name, ExpiresAt: req.ExpiresAt}
+	for _, id := range req.RoleIDs {
+		roleID, _ := parseUUIDString(id)
+		in.RoleIDs = append(in.RoleIDs, roleID)
+	}
+	return in
+}
+
+// @Summary List Service Accounts
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/service-accounts [get]
+func (h *APIKeyHandler) HandleListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	accounts, err := h.service.ListServiceAccounts(r.Context(), tenantID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list service accounts")
+		return
+	}
+	response.JSON(w, http.StatusOK, accounts)
+}
+
+// @Summary Create a Service Account
+// @Description Creates an identity for an integration. Issue it keys to call the API with.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ServiceAccountRequest true "Service Account Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/service-accounts [post]
+func (h *APIKeyHandler) HandleCreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	accountID, _ := parseUUIDString(uuid.New().String())
+
+	account, err := h.service.CreateServiceAccount(r.Context(), accountID, tenantID, actorID, req.Name, req.Description)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, account)
+}
+
+// @Summary Get a Service Account
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service Account UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/service-accounts/{id} [get]
+func (h *APIKeyHandler) HandleGetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	accountID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid service account ID format")
+		return
+	}
+
+	account, err := h.service.GetServiceAccount(r.Context(), tenantID, accountID)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, account)
+}
+
+// @Summary Update a Service Account
+// @Description Renames a service account or (de)activates it. A deactivated account's keys stop authenticating.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service Account UUID"
+// @Param request body ServiceAccountRequest true "Service Account Payload"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/service-accounts/{id} [put]
+func (h *APIKeyHandler) HandleUpdateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	accountID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid service account ID format")
+		return
+	}
+
+	var req ServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	account, err := h.service.UpdateServiceAccount(r.Context(), tenantID, actorID, accountID, req.Name, req.Description, isActive)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, account)
+}
+
+// @Summary List a Service Account's Keys
+// @Description Lists the account's keys, including revoked and expired ones. Key values are not included.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service Account UUID"
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/service-accounts/{id}/keys [get]
+func (h *APIKeyHandler) HandleListServiceAccountKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	accountID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid service account ID format")
+		return
+	}
+
+	keys, err := h.service.ListKeys(r.Context(), tenantID, accountID, pgtype.UUID{})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+	response.JSON(w, http.StatusOK, keys)
+}
+
+// @Summary Issue a Service Account Key
+// @Description Issues a key acting as the service account with the given roles. Roles granting permissions you do not hold yourself, such as HR_ADMIN for an ADMIN, are refused with 403. Send it as "Authorization: Bearer dml_...". The key is only shown in this response.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service Account UUID"
+// @Param request body KeyRequest true "Key Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/service-accounts/{id}/keys [post]
+func (h *APIKeyHandler) HandleIssueServiceAccountKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if refuseAPIKey(w, r) {
+		return
+	}
+
+	accountID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid service account ID format")
+		return
+	}
+
+	var req KeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	keyID, _ := parseUUIDString(uuid.New().String())
+
+	key, err := h.service.IssueServiceAccountKey(r.Context(), keyID, tenantID, actorID, accountID, req.input())
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, key)
+}
+
+// @Summary List API Keys
+// @Description Lists every API key of the tenant, personal and service account ones, including revoked and expired keys.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) HandleListKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keys, err := h.service.ListKeys(r.Context(), tenantID, pgtype.UUID{}, pgtype.UUID{})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+	response.JSON(w, http.StatusOK, keys)
+}
+
+// @Summary Revoke an API Key
+// @Description Stops any key of the tenant from authenticating. It stays listed with its revocation time.
+// @Tags API Keys
+// @Security BearerAuth
+// @Param id path string true "Key UUID"
+// @Success 204
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) HandleRevokeKey(w http.ResponseWriter, r *http.Request) {
+	h.revoke(w, r, false)
+}
+
+// @Summary List My API Keys
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/me/api-keys [get]
+func (h *APIKeyHandler) HandleListMyKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok || !userID.Valid {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keys, err := h.service.ListKeys(r.Context(), tenantID, pgtype.UUID{}, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+	response.JSON(w, http.StatusOK, keys)
+}
+
+// @Summary Issue a Personal API Key
+// @Description Issues a key acting as the caller, limited to the given roles out of those the caller holds. Send it as "Authorization: Bearer dml_...". The key is only shown in this response.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body KeyRequest true "Key Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/me/api-keys [post]
+func (h *APIKeyHandler) HandleIssueMyKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok || !userID.Valid {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if refuseAPIKey(w, r) {
+		return
+	}
+
+	var req KeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	keyID, _ := parseUUIDString(uuid.New().String())
+
+	key, err := h.service.IssuePersonalKey(r.Context(), keyID, tenantID, userID, req.input())
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, key)
+}
+
+// @Summary Revoke a Personal API Key
+// @Tags API Keys
+// @Security BearerAuth
+// @Param id path string true "Key UUID"
+// @Success 204
+// @Router /api/v1/me/api-keys/{id} [delete]
+func (h *APIKeyHandler) HandleRevokeMyKey(w http.ResponseWriter, r *http.Request) {
+	h.revoke(w, r, true)
+}
+
+// revoke revokes the key in the path; own limits it to the caller's personal keys
+func (h *APIKeyHandler) revoke(w http.ResponseWriter, r *http.Request, own bool) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok || (own && !actorID.Valid) {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keyID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid key ID format")
+		return
+	}
+
+	var ownerID pgtype.UUID
+	if own {
+		ownerID = actorID
+	}
+
+	if err := h.service.RevokeKey(r.Context(), tenantID, actorID, keyID, ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Key not found or already revoked")
+			return
+		}
+		response.DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -26,14 +26,22 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// LoginResponse represents the token payload
+// LoginResponse represents the token payload with the signed-in user, their roles and
+// tenant. When a second factor is needed these are withheld and mfaToken must be exchanged
+// at /auth/mfa/verify.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token                string             `json:"token,omitempty"`
+	User                 *logic.SessionUser `json:"user,omitempty"`
+	Roles                []string           `json:"roles,omitempty"`
+	TenantID             string             `json:"tenantId,omitempty"`
+	MFARequired          bool               `json:"mfaRequired,omitempty"`
+	MFAEnrolmentRequired bool               `json:"mfaEnrolmentRequired,omitempty"`
+	MFAToken             string             `json:"mfaToken,omitempty"`
 }
 
 // HandleLogin godoc
 // @Summary      Login and get JWT token
-// @Description  Authenticates a user via email and password and returns a JWT token for Authorization.
+// @Description  Authenticates a user via email and password and returns a JWT token for Authorization. Users with MFA, or holding a role that requires it, get an mfaToken instead to complete sign-in at /auth/mfa.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
@@ -54,13 +62,25 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.AuthenticateUser(r.Context(), req.Email, req.Password)
+	result, err := h.service.AuthenticateUser(r.Context(), req.Email, req.Password)
 	if err != nil {
 		response.Error(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
-	response.JSON(w, http.StatusOK, map[string]string{
-		"token": token,
+	response.JSON(w, http.StatusOK, LoginResponse(result))
+}
+
+// HandleJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys that session tokens are signed with, so other services can verify them. Tokens name their key in the kid header; during rotation retired keys stay listed until their tokens expire.
+// @Tags         Authentication
+// @Produce      json
+// @Success      200      {object}  map[string]interface{} "Key set"
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthHandler) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"keys": h.service.JWKS(),
 	})
 }
@@ -2,10 +2,14 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/INOVA/DML/internal/logic/apikeys"
+	"github.com/INOVA/DML/internal/logic/audit"
 	logic "github.com/INOVA/DML/internal/logic/auth"
+	"github.com/INOVA/DML/internal/logic/iam"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -22,7 +26,11 @@ const (
 
 // Config dependencies for the middleware
 type MiddlewareConfig struct {
-	JWTSecret string
+	Keys     *logic.KeySet
+	Issuer   string
+	Audience string
+	// APIKeys authenticates "dml_" bearer keys. When nil only session tokens are accepted.
+	APIKeys *apikeys.APIKeyService
 }
 
 func AuthMiddleware(cfg MiddlewareConfig) func(next http.Handler) http.Handler {
@@ -41,11 +49,19 @@ func AuthMiddleware(cfg MiddlewareConfig) func(next http.Handler) http.Handler {
 			}
 
 			tokenString := parts[1]
+			if cfg.APIKeys != nil && apikeys.IsAPIKey(tokenString) {
+				serveWithAPIKey(cfg.APIKeys, tokenString, next, w, r)
+				return
+			}
+
 			claims := &logic.Claims{}
 
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(cfg.JWTSecret), nil
-			})
+			token, err := jwt.ParseWithClaims(tokenString, claims, cfg.Keys.Keyfunc,
+				jwt.WithValidMethods(cfg.Keys.Methods()),
+				jwt.WithIssuer(cfg.Issuer),
+				jwt.WithAudience(cfg.Audience),
+				jwt.WithExpirationRequired(),
+			)
 
 			if err != nil || !token.Valid {
 				response.Error(w, http.StatusUnauthorized, "Invalid or expired token")
@@ -77,11 +93,42 @@ func AuthMiddleware(cfg MiddlewareConfig) func(next http.Handler) http.Handler {
 			ctx = context.WithValue(ctx, TenantIDKey, pgTenantID)
 			ctx = context.WithValue(ctx, RolesKey, claims.Roles)
 
+			// Impersonation tokens act as the user on behalf of the administrator in act
+			if claims.Act != nil {
+				parsedActor, err := uuid.Parse(claims.Act.UserID)
+				if err != nil {
+					response.Error(w, http.StatusUnauthorized, "Invalid token actor format")
+					return
+				}
+				ctx = audit.WithImpersonator(ctx, pgtype.UUID{Bytes: parsedActor, Valid: true})
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// serveWithAPIKey authenticates a request by API key. Personal keys act as their owner;
+// service account keys have no user, so audit entries name only the key.
+func serveWithAPIKey(keys *apikeys.APIKeyService, key string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	p, err := keys.Authenticate(r.Context(), key)
+	if errors.Is(err, apikeys.ErrInvalidKey) {
+		response.Error(w, http.StatusUnauthorized, "Invalid, expired or revoked API key")
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to verify API key")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, p.UserID)
+	ctx = context.WithValue(ctx, TenantIDKey, p.TenantID)
+	ctx = context.WithValue(ctx, RolesKey, p.Roles)
+	ctx = audit.WithAPIKey(ctx, p.KeyID)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // Helper functions for handlers to pull context
 func GetTenantIDFromContext(ctx context.Context) (pgtype.UUID, bool) {
 	val, ok := ctx.Value(TenantIDKey).(pgtype.UUID)
@@ -93,6 +140,16 @@ func GetUserIDFromContext(ctx context.Context) (pgtype.UUID, bool) {
 	return val, ok
 }
 
+// GetAPIKeyIDFromContext returns the API key the request authenticated with, if any
+func GetAPIKeyIDFromContext(ctx context.Context) (pgtype.UUID, bool) {
+	return audit.APIKeyFromContext(ctx)
+}
+
+// GetImpersonatorIDFromContext returns the administrator impersonating the request's user, if any
+func GetImpersonatorIDFromContext(ctx context.Context) (pgtype.UUID, bool) {
+	return audit.ImpersonatorFromContext(ctx)
+}
+
 func GetRolesFromContext(ctx context.Context) ([]string, bool) {
 	val, ok := ctx.Value(RolesKey).([]string)
 	return val, ok
@@ -130,3 +187,36 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// DenyImpersonation refuses requests made while impersonating a user, for endpoints that
+// manage the user's credentials
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetImpersonatorIDFromContext(r.Context()); ok {
+			response.Error(w, http.StatusForbidden, "Forbidden: not available while impersonating a user")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission allows requests whose roles grant permission, for routes guarded by
+// a permission rather than a role code
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userRoles, ok := GetRolesFromContext(r.Context())
+			if !ok {
+				response.Error(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			if !iam.HasPermission(userRoles, permission) {
+				response.Error(w, http.StatusForbidden, "Forbidden: insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,655 @@
+package capa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/capa"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type NCRHandler struct {
+	service *logic.NCRService
+}
+
+func NewNCRHandler(service *logic.NCRService) *NCRHandler {
+	return &NCRHandler{service: service}
+}
+
+func (h *NCRHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.Post("/", h.HandleCreate)
+	r.Get("/{id}", h.HandleGet)
+	r.Put("/{id}", h.HandleUpdate)
+	r.Put("/{id}/root-cause", h.HandleRecordRootCause)
+	r.Post("/{id}/transitions", h.HandleTransition)
+	r.Post("/{id}/verification", h.HandleVerify)
+	r.Post("/{id}/actions", h.HandleAddAction)
+	r.Put("/{id}/actions/{actionId}", h.HandleUpdateAction)
+	r.Post("/{id}/actions/{actionId}/status", h.HandleSetActionStatus)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func parseOptionalUUID(idStr *string) pgtype.UUID {
+	if idStr == nil || *idStr == "" {
+		return pgtype.UUID{Valid: false}
+	}
+	parsed, err := parseUUIDString(*idStr)
+	if err != nil {
+		return pgtype.UUID{Valid: false}
+	}
+	return parsed
+}
+
+func isAdmin(r *http.Request) bool {
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeNCRError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, notFound)
+	case errors.Is(err, logic.ErrEmployeeNotFound), errors.Is(err, logic.ErrDepartmentNotAtSite):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotAssignee),
+		errors.Is(err, logic.ErrAdminRequired),
+		errors.Is(err, logic.ErrAssigneeVerifier):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrInvalidTransition),
+		errors.Is(err, logic.ErrNCRClosed),
+		errors.Is(err, logic.ErrRootCauseRequired),
+		errors.Is(err, logic.ErrNoActions),
+		errors.Is(err, logic.ErrActionsOpen),
+		errors.Is(err, logic.ErrNotVerified),
+		errors.Is(err, logic.ErrNotInVerification),
+		errors.Is(err, logic.ErrActionsLocked),
+		errors.Is(err, logic.ErrActionClosed):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// @Summary List Non-Conformance Reports
+// @Description Get a paginated list of NCRs with their site, department, reporter and open/overdue action counts.
+// @Tags CAPA
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param search query string false "Search by title or number"
+// @Param status query string false "Only NCRs in this status"
+// @Param severity query string false "minor, major or critical"
+// @Param classification query string false "Only NCRs of this classification"
+// @Param businessUnitId query string false "Only NCRs raised at this business unit"
+// @Param departmentId query string false "Only NCRs raised against this department"
+// @Success 200 {object} map[string]interface{} "Paginated NCR data"
+// @Router /api/v1/ncrs [get]
+func (h *NCRHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	q := r.URL.Query()
+	filter := logic.NCRFilter{
+		Status:         q.Get("status"),
+		Severity:       q.Get("severity"),
+		Classification: q.Get("classification"),
+	}
+	if buStr := q.Get("businessUnitId"); buStr != "" {
+		buID, err := parseUUIDString(buStr)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid business unit ID format")
+			return
+		}
+		filter.BusinessUnitID = buID
+	}
+	if deptStr := q.Get("departmentId"); deptStr != "" {
+		deptID, err := parseUUIDString(deptStr)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+			return
+		}
+		filter.DepartmentID = deptID
+	}
+
+	ncrs, total, err := h.service.ListNCRs(r.Context(), tenantID, params, filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list non-conformance reports")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, ncrs, params.Page, params.Size, int(total))
+}
+
+type NCRRequest struct {
+	Title                string  `json:"title" validate:"required"`
+	Description          string  `json:"description" validate:"required"`
+	BusinessUnitID       string  `json:"businessUnitId" validate:"required,uuid"`
+	DepartmentID         string  `json:"departmentId" validate:"required,uuid"`
+	OwnerEmployeeID      *string `json:"ownerEmployeeId" validate:"omitempty,uuid"`
+	DetectedOn           string  `json:"detectedOn" validate:"required,datetime=2006-01-02"`
+	Severity             string  `json:"severity" validate:"required,oneof=minor major critical"`
+	Classification       string  `json:"classification" validate:"required,oneof=product process supplier customer_complaint audit safety environmental other"`
+	ContainmentAction    *string `json:"containmentAction"`
+	ReportedByEmployeeID *string `json:"reportedByEmployeeId" validate:"omitempty,uuid"`
+}
+
+func (req NCRRequest) input() logic.NCRInput {
+	buID, _ := parseUUIDString(req.BusinessUnitID)
+	deptID, _ := parseUUIDString(req.DepartmentID)
+	detectedOn, _ := time.Parse("2006-01-02", req.DetectedOn)
+	return logic.NCRInput{
+		Title:                req.Title,
+		Description:          req.Description,
+		BusinessUnitID:       buID,
+		DepartmentID:         deptID,
+		ReportedByEmployeeID: parseOptionalUUID(req.ReportedByEmployeeID),
+		OwnerEmployeeID:      parseOptionalUUID(req.OwnerEmployeeID),
+		DetectedOn:           detectedOn,
+		Severity:             req.Severity,
+		Classification:       req.Classification,
+		ContainmentAction:    req.ContainmentAction,
+	}
+}
+
+// @Summary Raise a Non-Conformance Report
+// @Description Records a non-conformance against a business unit and one of the departments operating there. The NCR starts in the open status and is numbered per tenant. The reporter defaults to the employee linked to the caller's account.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body NCRRequest true "NCR Payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid site, department or employee"
+// @Router /api/v1/ncrs [post]
+func (h *NCRHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req NCRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ncrID, _ := parseUUIDString(uuid.New().String())
+
+	ncr, err := h.service.CreateNCR(r.Context(), ncrID, tenantID, actorID, req.input())
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, ncr)
+}
+
+// @Summary Get a Non-Conformance Report
+// @Description Fetch an NCR with its action items, status history and the statuses it can move to next.
+// @Tags CAPA
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/ncrs/{id} [get]
+func (h *NCRHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	ncr, err := h.service.GetNCR(r.Context(), tenantID, ncrID)
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, ncr)
+}
+
+// @Summary Update a Non-Conformance Report
+// @Description Replaces the descriptive fields of an NCR that is not closed or cancelled. The reporter cannot be changed.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param request body NCRRequest true "NCR Payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "NCR is closed or cancelled"
+// @Router /api/v1/ncrs/{id} [put]
+func (h *NCRHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	var req NCRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ncr, err := h.service.UpdateNCR(r.Context(), tenantID, actorID, ncrID, req.input())
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, ncr)
+}
+
+type RootCauseRequest struct {
+	ContainmentAction *string `json:"containmentAction"`
+	Method            *string `json:"method" validate:"omitempty,oneof=five_whys fishbone fault_tree other"`
+	RootCause         *string `json:"rootCause"`
+}
+
+// @Summary Record Root Cause Analysis
+// @Description Stores the containment action and root cause analysis of an NCR. A root cause is required before the NCR can move to action_planned.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param request body RootCauseRequest true "Root cause analysis"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/ncrs/{id}/root-cause [put]
+func (h *NCRHandler) HandleRecordRootCause(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	var req RootCauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ncr, err := h.service.RecordRootCause(r.Context(), tenantID, actorID, ncrID, logic.RootCauseInput{
+		ContainmentAction: req.ContainmentAction,
+		Method:            req.Method,
+		RootCause:         req.RootCause,
+	})
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, ncr)
+}
+
+type TransitionRequest struct {
+	Status  string  `json:"status" validate:"required,oneof=investigation action_planned implementation verification closed cancelled"`
+	Comment *string `json:"comment"`
+}
+
+// @Summary Change NCR Status
+// @Description Moves an NCR through open → investigation → action_planned → implementation → verification → closed, or cancels it before implementation. Each move is recorded in the status history. Closing, cancelling and leaving verification require ADMIN.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param request body TransitionRequest true "Target status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Requires ADMIN"
+// @Failure 409 {object} map[string]interface{} "Transition not allowed"
+// @Router /api/v1/ncrs/{id}/transitions [post]
+func (h *NCRHandler) HandleTransition(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ncr, err := h.service.Transition(r.Context(), tenantID, actorID, ncrID, req.Status, req.Comment, isAdmin(r))
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, ncr)
+}
+
+type VerificationRequest struct {
+	Effective *bool   `json:"effective" validate:"required"`
+	Notes     *string `json:"notes"`
+}
+
+// @Summary Verify CAPA Effectiveness
+// @Description Records whether the actions of an NCR in verification were effective. An effective outcome closes the NCR; an ineffective one returns it to implementation. Requires ADMIN, and assignees of the NCR's actions cannot verify them.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param request body VerificationRequest true "Verification outcome"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Requires ADMIN, or actor is an action assignee"
+// @Failure 409 {object} map[string]interface{} "NCR is not awaiting verification"
+// @Router /api/v1/ncrs/{id}/verification [post]
+func (h *NCRHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	var req VerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ncr, err := h.service.Verify(r.Context(), tenantID, actorID, ncrID, *req.Effective, req.Notes, isAdmin(r))
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, ncr)
+}
+
+type ActionRequest struct {
+	Kind               string `json:"kind" validate:"required,oneof=corrective preventive containment"`
+	Description        string `json:"description" validate:"required"`
+	AssigneeEmployeeID string `json:"assigneeEmployeeId" validate:"required,uuid"`
+	DueDate            string `json:"dueDate" validate:"required,datetime=2006-01-02"`
+}
+
+func (req ActionRequest) input() logic.ActionInput {
+	assigneeID, _ := parseUUIDString(req.AssigneeEmployeeID)
+	dueDate, _ := time.Parse("2006-01-02", req.DueDate)
+	return logic.ActionInput{
+		Kind:               req.Kind,
+		Description:        req.Description,
+		AssigneeEmployeeID: assigneeID,
+		DueDate:            dueDate,
+	}
+}
+
+// @Summary Add a CAPA Action
+// @Description Assigns a corrective, preventive or containment action on an NCR to an employee with a due date. Actions cannot be added while the NCR is in verification or once it is closed.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param request body ActionRequest true "Action Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/ncrs/{id}/actions [post]
+func (h *NCRHandler) HandleAddAction(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	var req ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	actionID, _ := parseUUIDString(uuid.New().String())
+
+	action, err := h.service.AddAction(r.Context(), actionID, tenantID, actorID, ncrID, req.input())
+	if err != nil {
+		writeNCRError(w, err, "Non-conformance report not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, action)
+}
+
+// @Summary Update a CAPA Action
+// @Description Changes the description, assignee or due date of an open action item. The kind of an action cannot be changed.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param actionId path string true "Action UUID"
+// @Param request body ActionRequest true "Action Payload"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/ncrs/{id}/actions/{actionId} [put]
+func (h *NCRHandler) HandleUpdateAction(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	actionID, err := parseUUIDString(chi.URLParam(r, "actionId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid action ID format")
+		return
+	}
+
+	var req ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	action, err := h.service.UpdateAction(r.Context(), tenantID, actorID, ncrID, actionID, req.input())
+	if err != nil {
+		writeNCRError(w, err, "Action not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, action)
+}
+
+type ActionStatusRequest struct {
+	Status string  `json:"status" validate:"required,oneof=done cancelled"`
+	Notes  *string `json:"notes"`
+}
+
+// @Summary Complete or Cancel a CAPA Action
+// @Description Marks an open action item done or cancelled. The assignee may complete their own action; administrators may complete or cancel any action.
+// @Tags CAPA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "NCR UUID"
+// @Param actionId path string true "Action UUID"
+// @Param request body ActionStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Not the assignee"
+// @Router /api/v1/ncrs/{id}/actions/{actionId}/status [post]
+func (h *NCRHandler) HandleSetActionStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ncrID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid NCR ID format")
+		return
+	}
+
+	actionID, err := parseUUIDString(chi.URLParam(r, "actionId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid action ID format")
+		return
+	}
+
+	var req ActionStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	action, err := h.service.SetActionStatus(r.Context(), tenantID, actorID, ncrID, actionID, req.Status, req.Notes, isAdmin(r))
+	if err != nil {
+		writeNCRError(w, err, "Action not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, action)
+}
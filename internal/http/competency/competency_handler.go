@@ -0,0 +1,410 @@
+package competency
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/competency"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const dateLayout = "2006-01-02"
+
+type CompetencyHandler struct {
+	service *logic.CompetencyService
+}
+
+func NewCompetencyHandler(service *logic.CompetencyService) *CompetencyHandler {
+	return &CompetencyHandler{service: service}
+}
+
+func (h *CompetencyHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/", h.HandleCreate)
+	r.Get("/matrix", h.HandleMatrix)
+	r.Get("/employees/{employeeId}", h.HandleEmployeeCompliance)
+	r.Get("/employees/{employeeId}/records", h.HandleListRecords)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/employees/{employeeId}/records", h.HandleRecord)
+	r.Get("/{id}", h.HandleGet)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}", h.HandleUpdate)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+// HandleList godoc
+// @Summary      List competencies
+// @Description  Retrieves a paginated list of competencies (skills, certifications and controlled documents) for the authenticated tenant.
+// @Tags         Competencies
+// @Produce      json
+// @Param        page    query     int     false  "Page number" default(1)
+// @Param        size    query     int     false  "Page size" default(50)
+// @Param        search  query     string  false  "Search term (name/code/document)"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Paginated competency data"
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Router       /api/v1/competencies [get]
+func (h *CompetencyHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	comps, total, err := h.service.ListCompetencies(r.Context(), tenantID, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list competencies")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, comps, params.Page, params.Size, int(total))
+}
+
+// HandleGet godoc
+// @Summary      Get a competency
+// @Description  Retrieves a specific competency by its ID.
+// @Tags         Competencies
+// @Produce      json
+// @Param        id      path      string  true  "Competency ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Competency data"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/competencies/{id} [get]
+func (h *CompetencyHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	compID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid competency ID format")
+		return
+	}
+
+	comp, err := h.service.GetCompetency(r.Context(), tenantID, compID)
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Competency not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, comp)
+}
+
+type CompetencyRequest struct {
+	Code           string  `json:"code" validate:"required"`
+	Name           string  `json:"name" validate:"required"`
+	Kind           string  `json:"kind" validate:"omitempty,oneof=skill certification document"`
+	Description    *string `json:"description"`
+	DocumentRef    *string `json:"documentRef"`
+	ValidityMonths *int32  `json:"validityMonths" validate:"omitempty,gt=0"`
+	IsActive       *bool   `json:"isActive"`
+}
+
+// HandleCreate godoc
+// @Summary      Create a competency
+// @Description  Creates a competency. Document competencies reference a controlled document for read-and-understood training; validityMonths makes achievements expire.
+// @Tags         Competencies
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CompetencyRequest  true  "Competency"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Competency data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/competencies [post]
+func (h *CompetencyHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CompetencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	compID, _ := parseUUIDString(uuid.New().String())
+
+	comp, err := h.service.CreateCompetency(r.Context(), compID, tenantID, actorID, req.Code, req.Name, req.Kind, req.Description, req.DocumentRef, req.ValidityMonths)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, comp)
+}
+
+// HandleUpdate godoc
+// @Summary      Update a competency
+// @Description  Replaces a competency's details. Set isActive to false to retire it from job title requirements and the matrix.
+// @Tags         Competencies
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string             true  "Competency ID"
+// @Param        request  body      CompetencyRequest  true  "Competency"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Competency data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/competencies/{id} [put]
+func (h *CompetencyHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	compID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid competency ID format")
+		return
+	}
+
+	var req CompetencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	isActive := req.IsActive == nil || *req.IsActive
+
+	comp, err := h.service.UpdateCompetency(r.Context(), tenantID, actorID, compID, req.Code, req.Name, req.Kind, req.Description, req.DocumentRef, req.ValidityMonths, isActive)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Competency not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, comp)
+}
+
+// HandleMatrix godoc
+// @Summary      Get the competency matrix
+// @Description  Compares every active employee's competency records against the requirements of their job title. Each requirement is met, expiring, expired or missing; an employee is compliant when all mandatory requirements are met.
+// @Tags         Competencies
+// @Produce      json
+// @Param        departmentId  query     string  false  "Only employees in this department"
+// @Param        jobTitleId    query     string  false  "Only employees with this job title"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.EmployeeCompliance
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Router       /api/v1/competencies/matrix [get]
+func (h *CompetencyHandler) HandleMatrix(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var filter logic.MatrixFilter
+	if v := r.URL.Query().Get("departmentId"); v != "" {
+		id, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+			return
+		}
+		filter.DepartmentID = id
+	}
+	if v := r.URL.Query().Get("jobTitleId"); v != "" {
+		id, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid job title ID format")
+			return
+		}
+		filter.JobTitleID = id
+	}
+
+	matrix, err := h.service.GetMatrix(r.Context(), tenantID, filter)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, matrix)
+}
+
+// HandleEmployeeCompliance godoc
+// @Summary      Get an employee's competency status
+// @Description  Compares one employee's competency records against their job title's requirements.
+// @Tags         Competencies
+// @Produce      json
+// @Param        employeeId  path      string  true  "Employee ID"
+// @Security     BearerAuth
+// @Success      200     {object}  logic.EmployeeCompliance
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/competencies/employees/{employeeId} [get]
+func (h *CompetencyHandler) HandleEmployeeCompliance(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "employeeId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	status, err := h.service.GetEmployeeCompliance(r.Context(), tenantID, empID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// HandleListRecords godoc
+// @Summary      List an employee's competency records
+// @Description  Returns every competency an employee has achieved, most recent first, including expired ones.
+// @Tags         Competencies
+// @Produce      json
+// @Param        employeeId  path      string  true  "Employee ID"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.Record
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/competencies/employees/{employeeId}/records [get]
+func (h *CompetencyHandler) HandleListRecords(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "employeeId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	records, err := h.service.ListEmployeeCompetencies(r.Context(), tenantID, empID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, records)
+}
+
+type RecordRequest struct {
+	CompetencyID string  `json:"competencyId" validate:"required,uuid"`
+	AchievedOn   string  `json:"achievedOn" validate:"required,datetime=2006-01-02"`
+	ExpiresOn    *string `json:"expiresOn" validate:"omitempty,datetime=2006-01-02"`
+	Notes        *string `json:"notes"`
+}
+
+// HandleRecord godoc
+// @Summary      Record a competency for an employee
+// @Description  Records that an employee achieved a competency. When expiresOn is omitted it is derived from the competency's validity period.
+// @Tags         Competencies
+// @Accept       json
+// @Produce      json
+// @Param        employeeId  path      string         true  "Employee ID"
+// @Param        request     body      RecordRequest  true  "Achievement"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Competency record"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/competencies/employees/{employeeId}/records [post]
+func (h *CompetencyHandler) HandleRecord(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "employeeId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	var req RecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	compID, _ := parseUUIDString(req.CompetencyID)
+	achievedOn, _ := time.Parse(dateLayout, req.AchievedOn)
+	var expiresOn *time.Time
+	if req.ExpiresOn != nil {
+		t, _ := time.Parse(dateLayout, *req.ExpiresOn)
+		expiresOn = &t
+	}
+
+	rec, err := h.service.RecordCompetency(r.Context(), tenantID, actorID, empID, compID, achievedOn, expiresOn, logic.SourceManual, req.Notes)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Employee not found")
+	case errors.Is(err, logic.ErrCompetencyNotFound), errors.Is(err, logic.ErrInvalidExpiry):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		response.JSON(w, http.StatusCreated, rec)
+	}
+}
@@ -0,0 +1,252 @@
+package customfields
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefinitionHandler manages the custom fields a tenant has defined
+type DefinitionHandler struct {
+	service *logic.CustomFieldService
+}
+
+func NewDefinitionHandler(service *logic.CustomFieldService) *DefinitionHandler {
+	return &DefinitionHandler{service: service}
+}
+
+func (h *DefinitionHandler) RegisterRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.Get("/", h.HandleList)
+	r.With(admin).Post("/", h.HandleCreate)
+	r.Get("/{id}", h.HandleGet)
+	r.With(admin).Put("/{id}", h.HandleUpdate)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writeCustomFieldError(w http.ResponseWriter, err error, notFound string) {
+	var valueErr *logic.ValueError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, notFound)
+	case errors.Is(err, logic.ErrDuplicateKey),
+		errors.Is(err, logic.ErrErased):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.As(err, &valueErr),
+		errors.Is(err, logic.ErrUnknownEntityType),
+		errors.Is(err, logic.ErrInvalidKey),
+		errors.Is(err, logic.ErrOptionsRequired),
+		errors.Is(err, logic.ErrInvalidRules):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+type DefinitionRequest struct {
+	Label      string      `json:"label" validate:"required,max=200"`
+	IsRequired bool        `json:"isRequired"`
+	Options    []string    `json:"options" validate:"omitempty,max=200,dive,max=200"`
+	Validation logic.Rules `json:"validation"`
+	SortOrder  int32       `json:"sortOrder"`
+	IsActive   *bool       `json:"isActive"`
+}
+
+func (req DefinitionRequest) input() logic.DefinitionInput {
+	in := logic.DefinitionInput{
+		Label:      req.Label,
+		IsRequired: req.IsRequired,
+		Options:    req.Options,
+		Validation: req.Validation,
+		SortOrder:  req.SortOrder,
+		IsActive:   true,
+	}
+	if req.IsActive != nil {
+		in.IsActive = *req.IsActive
+	}
+	return in
+}
+
+type CreateDefinitionRequest struct {
+	EntityType string `json:"entityType" validate:"required,oneof=employee business_unit department job_title"`
+	Key        string `json:"key" validate:"required,max=63"`
+	FieldType  string `json:"fieldType" validate:"required,oneof=text number boolean date select multi_select"`
+	DefinitionRequest
+}
+
+// HandleList godoc
+// @Summary      List custom field definitions
+// @Description  Lists the custom fields the tenant has defined, ordered by entity type and sort order. Forms use this to render and pre-validate the extra inputs.
+// @Tags         Custom Fields
+// @Produce      json
+// @Param        entityType       query     string  false  "employee, business_unit, department or job_title"
+// @Param        includeInactive  query     bool    false  "Also list deactivated fields"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.Definition
+// @Failure      400     {object}  map[string]interface{} "Unknown entity type"
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Router       /api/v1/custom-fields [get]
+func (h *DefinitionHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("includeInactive"))
+
+	defs, err := h.service.ListDefinitions(r.Context(), tenantID, r.URL.Query().Get("entityType"), includeInactive)
+	if err != nil {
+		writeCustomFieldError(w, err, "Custom field not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, defs)
+}
+
+// HandleGet godoc
+// @Summary      Get a custom field definition
+// @Tags         Custom Fields
+// @Produce      json
+// @Param        id      path      string  true  "Custom field ID"
+// @Security     BearerAuth
+// @Success      200     {object}  logic.Definition
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/custom-fields/{id} [get]
+func (h *DefinitionHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid custom field ID format")
+		return
+	}
+
+	def, err := h.service.GetDefinition(r.Context(), tenantID, id)
+	if err != nil {
+		writeCustomFieldError(w, err, "Custom field not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, def)
+}
+
+// HandleCreate godoc
+// @Summary      Define a custom field
+// @Description  Adds a field to employees, business units, departments or job titles. Required fields must be given when a record is created through the API, onboarding or an employee import, and kept set by later updates; employees provisioned by SSO or SCIM are created without them. Select and multi_select fields need options; text fields may set minLength, maxLength and pattern, number fields min and max. The entity type, key and field type cannot be changed later.
+// @Tags         Custom Fields
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateDefinitionRequest  true  "Field definition"
+// @Security     BearerAuth
+// @Success      201      {object}  logic.Definition
+// @Failure      400      {object}  map[string]interface{} "Invalid definition"
+// @Failure      409      {object}  map[string]interface{} "Key already used for the entity type"
+// @Router       /api/v1/custom-fields [post]
+func (h *DefinitionHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	id, _ := parseUUIDString(uuid.New().String())
+
+	def, err := h.service.CreateDefinition(r.Context(), id, tenantID, actorID, req.EntityType, req.Key, req.FieldType, req.input())
+	if err != nil {
+		writeCustomFieldError(w, err, "Custom field not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, def)
+}
+
+// HandleUpdate godoc
+// @Summary      Update a custom field definition
+// @Description  Replaces the label, required flag, options, validation rules, sort order and active flag of a field. Deactivated fields are no longer accepted on writes but their stored values are kept.
+// @Tags         Custom Fields
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string             true  "Custom field ID"
+// @Param        request  body      DefinitionRequest  true  "Field settings"
+// @Security     BearerAuth
+// @Success      200      {object}  logic.Definition
+// @Failure      400      {object}  map[string]interface{} "Invalid definition"
+// @Failure      404      {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/custom-fields/{id} [put]
+func (h *DefinitionHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid custom field ID format")
+		return
+	}
+
+	var req DefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	def, err := h.service.UpdateDefinition(r.Context(), tenantID, actorID, id, req.input())
+	if err != nil {
+		writeCustomFieldError(w, err, "Custom field not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, def)
+}
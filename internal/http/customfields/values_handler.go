@@ -0,0 +1,155 @@
+package customfields
+
+import (
+	"encoding/json"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValuesHandler serves the custom field values of one entity type. The employee and org
+// handlers mount it under /{id}/custom-fields and use it to filter and validate.
+type ValuesHandler struct {
+	service    *logic.CustomFieldService
+	entityType string
+	notFound   string
+}
+
+func NewValuesHandler(service *logic.CustomFieldService, entityType, notFound string) *ValuesHandler {
+	return &ValuesHandler{
+		service:    service,
+		entityType: entityType,
+		notFound:   notFound,
+	}
+}
+
+// RegisterRoutes mounts the value routes on an entity's router
+func (h *ValuesHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/{id}/custom-fields", h.HandleGet)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}/custom-fields", h.HandleUpdate)
+}
+
+// Filter converts the request's cf.<key> parameters into a list filter, writing a 400
+// response and returning false when one names an unknown field or has the wrong type
+func (h *ValuesHandler) Filter(w http.ResponseWriter, r *http.Request, tenantID pgtype.UUID) ([]byte, bool) {
+	filter, err := h.service.Filter(r.Context(), tenantID, h.entityType, query.ParseCustomFields(r))
+	if err != nil {
+		writeCustomFieldError(w, err, h.notFound)
+		return nil, false
+	}
+	return filter, true
+}
+
+// Validate checks custom fields sent with a create or update, writing an error response
+// and returning false when they do not satisfy the tenant's definitions. entityID is left
+// invalid for a record that is about to be created.
+func (h *ValuesHandler) Validate(w http.ResponseWriter, r *http.Request, tenantID, entityID pgtype.UUID, values map[string]interface{}) bool {
+	if values == nil && entityID.Valid {
+		return true
+	}
+	if err := h.service.Validate(r.Context(), tenantID, h.entityType, entityID, values); err != nil {
+		writeCustomFieldError(w, err, h.notFound)
+		return false
+	}
+	return true
+}
+
+// Save stores custom fields sent with a create or update once they have passed Validate
+// and the record itself has been written
+func (h *ValuesHandler) Save(r *http.Request, tenantID, actorID, entityID pgtype.UUID, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, err := h.service.SetValues(r.Context(), tenantID, actorID, h.entityType, entityID, values)
+	return err
+}
+
+// HandleGet godoc
+// @Summary      Get custom field values
+// @Description  Returns the custom field values of a record keyed by field key.
+// @Tags         Custom Fields
+// @Produce      json
+// @Param        id      path      string  true  "Record ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/employees/{id}/custom-fields [get]
+// @Router       /api/v1/business-units/{id}/custom-fields [get]
+// @Router       /api/v1/departments/{id}/custom-fields [get]
+// @Router       /api/v1/job-titles/{id}/custom-fields [get]
+func (h *ValuesHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid ID format")
+		return
+	}
+
+	values, err := h.service.GetValues(r.Context(), tenantID, h.entityType, id)
+	if err != nil {
+		writeCustomFieldError(w, err, h.notFound)
+		return
+	}
+	response.JSON(w, http.StatusOK, values)
+}
+
+// HandleUpdate godoc
+// @Summary      Update custom field values
+// @Description  Merges the given values into a record's custom fields. Keys left out keep their value and null clears a field. Every value is checked against its field's type, options and rules, and required fields must end up set.
+// @Tags         Custom Fields
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Record ID"
+// @Param        request  body      map[string]interface{}  true  "Values keyed by field key"
+// @Security     BearerAuth
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{} "Invalid value or unknown field"
+// @Failure      404      {object}  map[string]interface{} "Not found"
+// @Failure      409      {object}  map[string]interface{} "Employee has been erased"
+// @Router       /api/v1/employees/{id}/custom-fields [put]
+// @Router       /api/v1/business-units/{id}/custom-fields [put]
+// @Router       /api/v1/departments/{id}/custom-fields [put]
+// @Router       /api/v1/job-titles/{id}/custom-fields [put]
+func (h *ValuesHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid ID format")
+		return
+	}
+
+	var values map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	saved, err := h.service.SetValues(r.Context(), tenantID, actorID, h.entityType, id, values)
+	if err != nil {
+		writeCustomFieldError(w, err, h.notFound)
+		return
+	}
+	response.JSON(w, http.StatusOK, saved)
+}
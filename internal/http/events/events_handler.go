@@ -0,0 +1,111 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/events"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies
+const heartbeatInterval = 25 * time.Second
+
+type EventsHandler struct {
+	broker *logic.Broker
+}
+
+func NewEventsHandler(broker *logic.Broker) *EventsHandler {
+	return &EventsHandler{broker: broker}
+}
+
+func (h *EventsHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/stream", h.HandleStream)
+}
+
+// @Summary Stream Entity Changes
+// @Description Server-sent event stream of changes to the tenant's records. Only creates, updates, deletes, onboarding and erasure are streamed, not reads or exports. Each event carries the audit entry id as its SSE id and a JSON body with entityType, entityId, action, actorId, actorApiKeyId, impersonatorId and occurredAt. The actor fields are null unless the caller holds audit-logs:read, and changes to credentials and personal details are only streamed to those callers. A comment line is sent every 25 seconds while idle. Changes are not replayed on reconnect, so clients should refetch what they display after reconnecting.
+// @Tags Events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param entityType query string false "Comma-separated entity types to receive (Employees, Users, ...), all by default"
+// @Success 200 {string} string "Event stream"
+// @Router /api/v1/events/stream [get]
+func (h *EventsHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Who changed what is audit log data, so only its readers see actors
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	auditReader := iam.HasPermission(roles, iam.PermAuditLogsRead)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		response.Error(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	entityTypes := make(map[string]bool)
+	for _, t := range strings.Split(r.URL.Query().Get("entityType"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			entityTypes[t] = true
+		}
+	}
+
+	changes, unsubscribe := h.broker.Subscribe(tenantID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			if len(entityTypes) > 0 && !entityTypes[change.EntityType] {
+				continue
+			}
+			if !auditReader {
+				if change.AuditOnly() {
+					continue
+				}
+				change = change.WithoutActors()
+			}
+			data, err := json.Marshal(change)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", uuid.UUID(change.ID.Bytes), data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
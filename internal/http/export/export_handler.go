@@ -0,0 +1,163 @@
+package export
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/customfields"
+	logic "github.com/INOVA/DML/internal/logic/export"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ExportHandler struct {
+	service *logic.ExportService
+}
+
+func NewExportHandler(service *logic.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+func (h *ExportHandler) RegisterRoutes(r chi.Router) {
+	r.Use(authHTTP.RequirePermission(iam.PermExportsManage))
+	r.Get("/employees", h.HandleExportEmployees)
+	r.Get("/users", h.HandleExportUsers)
+	r.Get("/org", h.HandleExportOrg)
+}
+
+// HandleExportEmployees godoc
+// @Summary      Export employees
+// @Description  Exports employees with their business unit, department, job title and manager. Requires the exports:manage permission. Small exports are streamed directly; exports above the async threshold, or with async=true, run as a background job whose file is downloaded from /api/v1/jobs/{id}/download. In CSV, cells starting with =, +, -, @, tab or carriage return are prefixed with an apostrophe so spreadsheets do not evaluate them; the employee import strips it again. XLSX cells are plain text and never evaluated.
+// @Tags         Exports
+// @Produce      text/csv,application/json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query     string  false  "csv (default), xlsx or json"
+// @Param        search  query     string  false  "Same search filter as GET /employees"
+// @Param        departmentId           query  string  false  "Only employees in this department"
+// @Param        includeSubDepartments  query  bool    false  "Also include sub-departments (default true)"
+// @Param        cf.key  query     string  false  "Same custom field filter as GET /employees; repeat for other keys"
+// @Param        async   query     bool    false  "Force the export to run as a background job"
+// @Security     BearerAuth
+// @Success      200     {file}    file  "Export file"
+// @Success      202     {object}  map[string]interface{} "Export queued as a background job"
+// @Failure      400     {object}  map[string]interface{} "Invalid format or custom field filter"
+// @Failure      403     {object}  map[string]interface{} "Forbidden"
+// @Router       /api/v1/exports/employees [get]
+func (h *ExportHandler) HandleExportEmployees(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, logic.DatasetEmployees)
+}
+
+// HandleExportUsers godoc
+// @Summary      Export users
+// @Description  Exports users with their assigned role codes. Same streaming and background job behaviour as the employee export.
+// @Tags         Exports
+// @Produce      text/csv,application/json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query     string  false  "csv (default), xlsx or json"
+// @Param        search  query     string  false  "Same search filter as GET /users"
+// @Param        async   query     bool    false  "Force the export to run as a background job"
+// @Security     BearerAuth
+// @Success      200     {file}    file  "Export file"
+// @Success      202     {object}  map[string]interface{} "Export queued as a background job"
+// @Failure      400     {object}  map[string]interface{} "Invalid format"
+// @Failure      403     {object}  map[string]interface{} "Forbidden"
+// @Router       /api/v1/exports/users [get]
+func (h *ExportHandler) HandleExportUsers(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, logic.DatasetUsers)
+}
+
+// HandleExportOrg godoc
+// @Summary      Export organisation structure
+// @Description  Exports business units, the department tree (with parent codes and paths) and job titles as one flat table. Custom field filters apply to business units, departments and job titles as on their list endpoints; a type that does not define the filtered fields, and job grades, are left out.
+// @Tags         Exports
+// @Produce      text/csv,application/json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query     string  false  "csv (default), xlsx or json"
+// @Param        search  query     string  false  "Filter nodes by name or code"
+// @Param        cf.key  query     string  false  "Only nodes whose custom field <key> has this value; repeat for other keys"
+// @Param        async   query     bool    false  "Force the export to run as a background job"
+// @Security     BearerAuth
+// @Success      200     {file}    file  "Export file"
+// @Success      202     {object}  map[string]interface{} "Export queued as a background job"
+// @Failure      400     {object}  map[string]interface{} "Invalid format or custom field filter"
+// @Failure      403     {object}  map[string]interface{} "Forbidden"
+// @Router       /api/v1/exports/org [get]
+func (h *ExportHandler) HandleExportOrg(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, logic.DatasetOrg)
+}
+
+// export streams small exports and hands large ones to the job runner
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request, dataset logic.Dataset) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	format, err := logic.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := logic.Filter{
+		Search:                r.URL.Query().Get("search"),
+		IncludeSubDepartments: true,
+	}
+	if deptStr := r.URL.Query().Get("departmentId"); deptStr != "" && dataset == logic.DatasetEmployees {
+		deptID, err := uuid.Parse(deptStr)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+			return
+		}
+		filter.DepartmentID = pgtype.UUID{Bytes: deptID, Valid: true}
+	}
+	if include, err := strconv.ParseBool(r.URL.Query().Get("includeSubDepartments")); err == nil {
+		filter.IncludeSubDepartments = include
+	}
+	if dataset != logic.DatasetUsers {
+		filter.CustomFields = query.ParseCustomFields(r)
+	}
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+
+	total, err := h.service.CountRows(r.Context(), tenantID, dataset, filter)
+	var valueErr *customfields.ValueError
+	if errors.As(err, &valueErr) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to prepare export")
+		return
+	}
+
+	if async || total > logic.AsyncExportThreshold {
+		job, err := h.service.SubmitExport(r.Context(), tenantID, actorID, dataset, format, filter, int(total))
+		if err != nil {
+			response.Error(w, http.StatusServiceUnavailable, "Failed to queue export job")
+			return
+		}
+		w.Header().Set("Location", "/api/v1/jobs/"+uuid.UUID(job.ID.Bytes).String())
+		response.JSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+logic.FileName(dataset, format)+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure midway can only truncate the stream
+	if _, err := h.service.Export(r.Context(), w, tenantID, dataset, format, filter, nil); err != nil {
+		log.Printf("export of %s failed after streaming started: %v", dataset, err)
+	}
+}
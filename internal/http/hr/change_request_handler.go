@@ -0,0 +1,409 @@
+package hr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ChangeRequestHandler struct {
+	service *logic.ChangeRequestService
+}
+
+func NewChangeRequestHandler(service *logic.ChangeRequestService) *ChangeRequestHandler {
+	return &ChangeRequestHandler{service: service}
+}
+
+// RegisterRoutes mounts the review of change requests under /employee-change-requests
+func (h *ChangeRequestHandler) RegisterRoutes(r chi.Router) {
+	r.With(authHTTP.RequireRole("ADMIN")).Get("/", h.HandleList)
+	r.Get("/{id}", h.HandleGet)
+	r.Post("/{id}/approve", h.HandleApprove)
+	r.Post("/{id}/reject", h.HandleReject)
+}
+
+// RegisterMeRoutes mounts the caller's own change requests under /me
+func (h *ChangeRequestHandler) RegisterMeRoutes(r chi.Router) {
+	r.Get("/employee-change-requests", h.HandleListMine)
+	r.Post("/employee-change-requests", h.HandleCreate)
+	r.Post("/employee-change-requests/{id}/cancel", h.HandleCancel)
+}
+
+func isAdmin(r *http.Request) bool {
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeChangeRequestError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Change request not found")
+	case errors.Is(err, logic.ErrNoLinkedEmployee),
+		errors.Is(err, logic.ErrNoChanges),
+		errors.Is(err, logic.ErrOrgUnitNotFound),
+		errors.Is(err, logic.ErrDepartmentNotAtSite),
+		errors.Is(err, logic.ErrNoApprover):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotApprover),
+		errors.Is(err, logic.ErrSelfApproval),
+		errors.Is(err, logic.ErrNotRequester):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrChangePending),
+		errors.Is(err, logic.ErrChangeClosed),
+		errors.Is(err, logic.ErrStaleChange):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// parseChangeStatus reads ?status, which is one of the change request statuses or empty for all
+func parseChangeStatus(r *http.Request) (string, bool) {
+	switch status := r.URL.Query().Get("status"); status {
+	case "", logic.ChangePending, logic.ChangeApproved, logic.ChangeRejected, logic.ChangeCancelled:
+		return status, true
+	}
+	return "", false
+}
+
+type CreateChangeRequestRequest struct {
+	FirstName      *string `json:"firstName" validate:"omitempty,max=200"`
+	LastName       *string `json:"lastName" validate:"omitempty,max=200"`
+	DisplayName    *string `json:"displayName" validate:"omitempty,max=200"`
+	WorkEmail      *string `json:"workEmail" validate:"omitempty,email"`
+	BusinessUnitID *string `json:"businessUnitId" validate:"omitempty,uuid"`
+	DepartmentID   *string `json:"departmentId" validate:"omitempty,uuid"`
+	Reason         *string `json:"reason" validate:"omitempty,max=2000"`
+}
+
+type DecideChangeRequestRequest struct {
+	Notes *string `json:"notes" validate:"omitempty,max=2000"`
+}
+
+type RejectChangeRequestRequest struct {
+	Notes string `json:"notes" validate:"required,max=2000"`
+}
+
+// @Summary Request a Change to My HR Record
+// @Description Proposes changes to the caller's own employee record: name, work email, or a transfer to another department and business unit. Omitted fields stay as they are and an empty display name or work email clears it. The request goes to the employee's manager, or to HR when they have none, as an approval task. Only one request may be pending at a time.
+// @Tags Employee Change Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateChangeRequestRequest true "Proposed changes"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Nothing to change or invalid transfer"
+// @Failure 409 {object} map[string]interface{} "A request is already pending"
+// @Router /api/v1/me/employee-change-requests [post]
+func (h *ChangeRequestHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateChangeRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	cr, err := h.service.RequestChange(r.Context(), id, tenantID, actorID, logic.ChangeInput{
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		DisplayName:    req.DisplayName,
+		WorkEmail:      req.WorkEmail,
+		BusinessUnitID: parseOptionalUUID(req.BusinessUnitID),
+		DepartmentID:   parseOptionalUUID(req.DepartmentID),
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		writeChangeRequestError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, cr)
+}
+
+// @Summary List My HR Change Requests
+// @Description Get a paginated list of the change requests raised for the caller's employee record, newest first.
+// @Tags Employee Change Requests
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Success 200 {object} map[string]interface{} "Paginated change request data"
+// @Router /api/v1/me/employee-change-requests [get]
+func (h *ChangeRequestHandler) HandleListMine(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, ok := parseChangeStatus(r)
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+
+	params := query.ParsePagination(r)
+	items, total, err := h.service.ListMyChangeRequests(r.Context(), tenantID, userID, params, status)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list change requests")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, items, params.Page, params.Size, int(total))
+}
+
+// @Summary List HR Change Requests
+// @Description Get a paginated list of the tenant's employee change requests, newest first. Requires ADMIN.
+// @Tags Employee Change Requests
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Param employeeId query string false "Only requests for this employee"
+// @Success 200 {object} map[string]interface{} "Paginated change request data"
+// @Router /api/v1/employee-change-requests [get]
+func (h *ChangeRequestHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, ok := parseChangeStatus(r)
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+	filter := logic.ChangeRequestFilter{Status: status}
+	if empStr := r.URL.Query().Get("employeeId"); empStr != "" {
+		empID, err := parseUUIDString(empStr)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+			return
+		}
+		filter.EmployeeID = empID
+	}
+
+	params := query.ParsePagination(r)
+	items, total, err := h.service.ListChangeRequests(r.Context(), tenantID, params, filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list change requests")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, items, params.Page, params.Size, int(total))
+}
+
+// @Summary Get an HR Change Request
+// @Description Fetch a change request with its proposed changes. Visible to the employee it is for, its approver and administrators.
+// @Tags Employee Change Requests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Change request UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{} "Change request not found"
+// @Router /api/v1/employee-change-requests/{id} [get]
+func (h *ChangeRequestHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid change request ID format")
+		return
+	}
+
+	cr, err := h.service.GetChangeRequest(r.Context(), tenantID, actorID, id, isAdmin(r))
+	if err != nil {
+		writeChangeRequestError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cr)
+}
+
+// @Summary Approve an HR Change Request
+// @Description Applies a pending change request to the employee record and completes its approval task in one transaction. Only the approver or an administrator may approve, and never the employee themselves. Fails with 409 when the record changed since the request was raised.
+// @Tags Employee Change Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Change request UUID"
+// @Param request body DecideChangeRequestRequest false "Optional notes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Not the approver"
+// @Failure 409 {object} map[string]interface{} "Already decided or record changed"
+// @Router /api/v1/employee-change-requests/{id}/approve [post]
+func (h *ChangeRequestHandler) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid change request ID format")
+		return
+	}
+
+	var req DecideChangeRequestRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	cr, err := h.service.ApproveChangeRequest(r.Context(), tenantID, actorID, id, req.Notes, isAdmin(r))
+	if err != nil {
+		writeChangeRequestError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cr)
+}
+
+// @Summary Reject an HR Change Request
+// @Description Turns a pending change request down and completes its approval task. The reasoning is required and kept on the request.
+// @Tags Employee Change Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Change request UUID"
+// @Param request body RejectChangeRequestRequest true "Reason for rejection"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Not the approver"
+// @Failure 409 {object} map[string]interface{} "Already decided"
+// @Router /api/v1/employee-change-requests/{id}/reject [post]
+func (h *ChangeRequestHandler) HandleReject(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid change request ID format")
+		return
+	}
+
+	var req RejectChangeRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	cr, err := h.service.RejectChangeRequest(r.Context(), tenantID, actorID, id, req.Notes, isAdmin(r))
+	if err != nil {
+		writeChangeRequestError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cr)
+}
+
+// @Summary Cancel My HR Change Request
+// @Description Withdraws one of the caller's pending change requests and cancels its approval task.
+// @Tags Employee Change Requests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Change request UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Not your request"
+// @Failure 409 {object} map[string]interface{} "Already decided"
+// @Router /api/v1/me/employee-change-requests/{id}/cancel [post]
+func (h *ChangeRequestHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid change request ID format")
+		return
+	}
+
+	cr, err := h.service.CancelChangeRequest(r.Context(), tenantID, actorID, id)
+	if err != nil {
+		writeChangeRequestError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cr)
+}
@@ -2,30 +2,50 @@ package hr
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
 	"github.com/INOVA/DML/internal/http/query"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
 	logic "github.com/INOVA/DML/internal/logic/hr"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type EmployeeHandler struct {
-	service *logic.EmployeeService
+	service       *logic.EmployeeService
+	importService *logic.EmployeeImportService
+	customFields  *customFieldsHTTP.ValuesHandler
 }
 
-func NewEmployeeHandler(service *logic.EmployeeService) *EmployeeHandler {
-	return &EmployeeHandler{service: service}
+func NewEmployeeHandler(service *logic.EmployeeService, importService *logic.EmployeeImportService, customFieldService *customFieldsLogic.CustomFieldService) *EmployeeHandler {
+	return &EmployeeHandler{
+		service:       service,
+		importService: importService,
+		customFields:  customFieldsHTTP.NewValuesHandler(customFieldService, customFieldsLogic.EntityEmployee, "Employee not found"),
+	}
 }
 
 func (h *EmployeeHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/", h.HandleList)
 	r.Post("/", h.HandleCreate)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/import", h.HandleImport)
+	r.Get("/org-chart", h.HandleGetOrgChart)
+	r.Get("/span-of-control", h.HandleGetSpanOfControl)
+	r.With(authHTTP.RequireRole("ADMIN")).Get("/integrity", h.HandleCheckIntegrity)
 	r.Get("/{id}", h.HandleGet)
 	r.Get("/{id}/hierarchy", h.HandleGetHierarchy)
+	r.Get("/{id}/org-tree", h.HandleGetOrgTree)
+	r.Get("/{id}/chain-of-command", h.HandleGetChainOfCommand)
+	r.Get("/{id}/direct-reports", h.HandleListDirectReports)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}/manager", h.HandleChangeManager)
+	h.customFields.RegisterRoutes(r)
 }
 
 func parseUUIDString(idStr string) (pgtype.UUID, error) {
@@ -39,6 +59,23 @@ func parseUUIDString(idStr string) (pgtype.UUID, error) {
 	return pgID, nil
 }
 
+// parseEmployeeFilter reads ?departmentId and ?includeSubDepartments (default true)
+func parseEmployeeFilter(r *http.Request) (logic.EmployeeFilter, error) {
+	filter := logic.EmployeeFilter{IncludeSubDepartments: true}
+
+	if deptStr := r.URL.Query().Get("departmentId"); deptStr != "" {
+		deptID, err := parseUUIDString(deptStr)
+		if err != nil {
+			return filter, err
+		}
+		filter.DepartmentID = deptID
+	}
+	if include, err := strconv.ParseBool(r.URL.Query().Get("includeSubDepartments")); err == nil {
+		filter.IncludeSubDepartments = include
+	}
+	return filter, nil
+}
+
 func parseOptionalUUID(idStr *string) pgtype.UUID {
 	if idStr == nil || *idStr == "" {
 		return pgtype.UUID{Valid: false}
@@ -54,7 +91,7 @@ func parseOptionalUUID(idStr *string) pgtype.UUID {
 }
 
 // @Summary Get Employee Hierarchy
-// @Description Fetches an employee and a recursively mapped tree of their direct and indirect reporting subordinates.
+// @Description Fetches an employee followed by a flat list of their direct and indirect reporting subordinates, ordered by reporting depth. Use /org-tree for a nested response.
 // @Tags Employees
 // @Produce json
 // @Security BearerAuth
@@ -91,6 +128,9 @@ func (h *EmployeeHandler) HandleGetHierarchy(w http.ResponseWriter, r *http.Requ
 // @Param page query int false "Page number"
 // @Param pageSize query int false "Items per page"
 // @Param search query string false "Search fuzzy match"
+// @Param departmentId query string false "Only employees in this department"
+// @Param includeSubDepartments query bool false "Also include employees of sub-departments (default true)"
+// @Param cf.key query string false "Only employees whose custom field <key> has this value; repeat for other keys"
 // @Success 200 {object} map[string]interface{} "Paginated Employee data"
 // @Router /api/v1/employees [get]
 func (h *EmployeeHandler) HandleList(w http.ResponseWriter, r *http.Request) {
@@ -102,7 +142,17 @@ func (h *EmployeeHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 
 	params := query.ParsePagination(r)
 
-	emps, total, err := h.service.ListEmployeesWithDetails(r.Context(), tenantID, params)
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+		return
+	}
+
+	if filter.CustomFields, ok = h.customFields.Filter(w, r, tenantID); !ok {
+		return
+	}
+
+	emps, total, err := h.service.ListEmployeesWithDetails(r.Context(), tenantID, params, filter)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to list employees")
 		return
@@ -150,6 +200,8 @@ type CreateEmployeeRequest struct {
 	DepartmentID   *string `json:"departmentId" validate:"omitempty,uuid"`
 	JobTitleID     *string `json:"jobTitleId" validate:"omitempty,uuid"`
 	ManagerID      *string `json:"managerId" validate:"omitempty,uuid"`
+
+	CustomFields map[string]interface{} `json:"customFields"`
 }
 
 // @Summary Create an Employee
@@ -185,13 +237,22 @@ func (h *EmployeeHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.customFields.Validate(w, r, tenantID, pgtype.UUID{}, req.CustomFields) {
+		return
+	}
+
 	empID, _ := parseUUIDString(uuid.New().String())
 	busID := parseOptionalUUID(req.BusinessUnitID)
 	deptID := parseOptionalUUID(req.DepartmentID)
 	jobID := parseOptionalUUID(req.JobTitleID)
 	mgrID := parseOptionalUUID(req.ManagerID)
 
-	emp, err := h.service.CreateEmployee(r.Context(), empID, tenantID, actorID, req.EmployeeNo, req.FirstName, req.LastName, req.DisplayName, req.WorkEmail, busID, deptID, jobID, mgrID)
+	emp, err := h.service.CreateEmployee(r.Context(), empID, tenantID, actorID, req.EmployeeNo, req.FirstName, req.LastName, req.DisplayName, req.WorkEmail, busID, deptID, jobID, mgrID, req.CustomFields)
+	var valueErr *customFieldsLogic.ValueError
+	if errors.Is(err, logic.ErrManagerNotFound) || errors.Is(err, logic.ErrSelfManager) || errors.Is(err, logic.ErrManagerCycle) || errors.Is(err, logic.ErrDepartmentNotAtSite) || errors.As(err, &valueErr) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		response.DBError(w, err)
 		return
@@ -199,3 +260,87 @@ func (h *EmployeeHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusCreated, emp)
 }
+
+type ChangeManagerRequest struct {
+	ManagerID *string `json:"managerId" validate:"omitempty,uuid"`
+}
+
+// @Summary Change an Employee's Manager
+// @Description Moves an employee under a new manager, or removes their manager when managerId is null. Assignments that would make an employee report to themselves, directly or through their own reports, are rejected.
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Employee UUID"
+// @Param request body ChangeManagerRequest true "New manager"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid manager"
+// @Failure 404 {object} map[string]interface{} "Employee not found"
+// @Failure 409 {object} map[string]interface{} "Reporting cycle"
+// @Router /api/v1/employees/{id}/manager [put]
+func (h *EmployeeHandler) HandleChangeManager(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	var req ChangeManagerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	emp, err := h.service.ChangeManager(r.Context(), tenantID, actorID, empID, parseOptionalUUID(req.ManagerID))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Employee not found")
+	case errors.Is(err, logic.ErrManagerCycle):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrSelfManager), errors.Is(err, logic.ErrManagerNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		response.JSON(w, http.StatusOK, emp)
+	}
+}
+
+// @Summary Check Hierarchy Integrity
+// @Description Reports employees whose manager is outside the tenant, inactive managers that still have active reports, active employees assigned to an inactive department or business unit, and reporting cycles.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} logic.IntegrityReport
+// @Router /api/v1/employees/integrity [get]
+func (h *EmployeeHandler) HandleCheckIntegrity(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	report, err := h.service.CheckIntegrity(r.Context(), tenantID)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,100 @@
+package hr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/google/uuid"
+)
+
+// maxImportFileBytes bounds the size of uploaded import spreadsheets
+const maxImportFileBytes = 20 << 20
+
+// @Summary Bulk import Employees
+// @Description Imports employees from a CSV or XLSX file (multipart field "file"). Business units, departments and job titles are resolved by code and managers by employee number, either existing or within the same file. With dryRun=true every row is validated and per-row errors are returned without writing anything. Custom fields are read from cf.<key> columns, e.g. cf.shift_pattern, with multi_select options separated by semicolons; required fields must be filled in on every row. Otherwise all rows are created in a single transaction, or none are when any row is invalid. Files above the async threshold, or requests with async=true, are queued as a background job that can be polled at /api/v1/jobs/{id}.
+// @Tags Employees
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file with a header row"
+// @Param dryRun query bool false "Validate only, do not persist"
+// @Param async query bool false "Force the import to run as a background job"
+// @Success 200 {object} logic.ImportReport "Dry-run validation report"
+// @Success 201 {object} logic.ImportReport "All rows imported"
+// @Success 202 {object} map[string]interface{} "Import queued as a background job"
+// @Failure 400 {object} map[string]interface{} "Unreadable file"
+// @Failure 422 {object} logic.ImportReport "Validation failed, nothing imported"
+// @Router /api/v1/employees/import [post]
+func (h *EmployeeHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes)
+	if err := r.ParseMultipartForm(maxImportFileBytes); err != nil {
+		response.Error(w, http.StatusBadRequest, "Expected a multipart upload no larger than 20 MB")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Missing 'file' form field")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+
+	rows, err := logic.ParseImportFile(header.Filename, data)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+
+	if async || len(rows) > logic.AsyncImportThreshold {
+		job, err := h.importService.SubmitImport(r.Context(), tenantID, actorID, rows, dryRun)
+		if err != nil {
+			response.Error(w, http.StatusServiceUnavailable, "Failed to queue import job")
+			return
+		}
+		w.Header().Set("Location", "/api/v1/jobs/"+uuid.UUID(job.ID.Bytes).String())
+		response.JSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	report, err := h.importService.Import(r.Context(), tenantID, actorID, rows, dryRun, nil)
+	if errors.Is(err, logic.ErrImportInvalid) {
+		response.JSON(w, http.StatusUnprocessableEntity, report)
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+
+	if dryRun {
+		response.JSON(w, http.StatusOK, report)
+		return
+	}
+	response.JSON(w, http.StatusCreated, report)
+}
@@ -2,20 +2,29 @@ package hr
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
 	logic "github.com/INOVA/DML/internal/logic/hr"
+	iamLogic "github.com/INOVA/DML/internal/logic/iam"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type OnboardingHandler struct {
-	service *logic.OnboardingService
+	service      *logic.OnboardingService
+	customFields *customFieldsHTTP.ValuesHandler
 }
 
-func NewOnboardingHandler(service *logic.OnboardingService) *OnboardingHandler {
-	return &OnboardingHandler{service: service}
+func NewOnboardingHandler(service *logic.OnboardingService, customFieldService *customFieldsLogic.CustomFieldService) *OnboardingHandler {
+	return &OnboardingHandler{
+		service:      service,
+		customFields: customFieldsHTTP.NewValuesHandler(customFieldService, customFieldsLogic.EntityEmployee, "Employee not found"),
+	}
 }
 
 func (h *OnboardingHandler) RegisterRoutes(r chi.Router) {
@@ -33,19 +42,22 @@ type OnboardRequest struct {
 	JobTitleID     *string `json:"jobTitleId" validate:"omitempty,uuid"`
 	ManagerID      *string `json:"managerId" validate:"omitempty,uuid"`
 
+	CustomFields map[string]interface{} `json:"customFields"`
+
 	// User / Auth Info
 	Password      string `json:"password" validate:"required,min=8"`
 	InitialRoleID string `json:"initialRoleId" validate:"required,uuid"`
 }
 
 // @Summary Onboard new Staff Member
-// @Description Natively constructs the Employee profile, creates the Identity provider User account securely, assigns the primary RBAC Role, and safely tracks an Audit stream atomically using Postgres Transactions securely bound.
+// @Description Natively constructs the Employee profile, creates the Identity provider User account securely, assigns the primary RBAC Role, and safely tracks an Audit stream atomically using Postgres Transactions securely bound. customFields are checked as on employee creation, so required fields must be given.
 // @Tags Onboarding
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body OnboardRequest true "Comprehensive Onboarding Details"
 // @Success 201 {object} map[string]interface{} "Successfully completed sequence returning the active Identity UUID map structure"
+// @Failure 403 {object} map[string]interface{} "initialRoleId grants permissions the caller does not hold"
 // @Router /api/v1/onboard [post]
 func (h *OnboardingHandler) HandleOnboard(w http.ResponseWriter, r *http.Request) {
 	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
@@ -71,6 +83,10 @@ func (h *OnboardingHandler) HandleOnboard(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !h.customFields.Validate(w, r, tenantID, pgtype.UUID{}, req.CustomFields) {
+		return
+	}
+
 	busID := parseOptionalUUID(req.BusinessUnitID)
 	deptID := parseOptionalUUID(req.DepartmentID)
 	jobID := parseOptionalUUID(req.JobTitleID)
@@ -95,10 +111,24 @@ func (h *OnboardingHandler) HandleOnboard(w http.ResponseWriter, r *http.Request
 		mgrID,
 	)
 
+	if errors.Is(err, logic.ErrDepartmentNotAtSite) {
+		response.Error(w, http.StatusBadRequest, logic.ErrDepartmentNotAtSite.Error())
+		return
+	}
+	if errors.Is(err, iamLogic.ErrRoleExceedsGranter) {
+		response.Error(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		response.DBError(w, err)
 		return
 	}
 
+	empID, _ := parseUUIDString(res.EmployeeID)
+	if err := h.customFields.Save(r, tenantID, actorID, empID, req.CustomFields); err != nil {
+		response.DBError(w, err)
+		return
+	}
+
 	response.JSON(w, http.StatusCreated, res)
 }
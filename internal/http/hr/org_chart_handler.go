@@ -0,0 +1,173 @@
+package hr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// parseDepth reads ?depth, falling back to the default when absent or malformed
+func parseDepth(r *http.Request) int {
+	depth, err := strconv.Atoi(r.URL.Query().Get("depth"))
+	if err != nil {
+		return logic.DefaultOrgChartDepth
+	}
+	return logic.ClampOrgChartDepth(depth)
+}
+
+// @Summary Get Employee Org Tree
+// @Description Returns the employee as the root of a nested reporting tree. Reporting cycles are cut at the first repeated employee and nodes whose reports lie beyond the depth limit are flagged with hasMoreReports.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Employee UUID"
+// @Param depth query int false "Reporting levels below the employee (default 3, max 50)"
+// @Success 200 {object} logic.OrgChartNode
+// @Router /api/v1/employees/{id}/org-tree [get]
+func (h *EmployeeHandler) HandleGetOrgTree(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	tree, err := h.service.GetOrgTree(r.Context(), tenantID, empID, parseDepth(r))
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, tree)
+}
+
+// @Summary Get Employee Chain of Command
+// @Description Lists the managers above an employee, starting with the direct manager (depth 1) up to the top of the hierarchy.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Employee UUID"
+// @Success 200 {array} logic.OrgChartNode
+// @Router /api/v1/employees/{id}/chain-of-command [get]
+func (h *EmployeeHandler) HandleGetChainOfCommand(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	chain, err := h.service.GetChainOfCommand(r.Context(), tenantID, empID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, chain)
+}
+
+// @Summary List Direct Reports
+// @Description Lists only the employees reporting directly to the given employee.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Employee UUID"
+// @Success 200 {array} logic.OrgChartNode
+// @Router /api/v1/employees/{id}/direct-reports [get]
+func (h *EmployeeHandler) HandleListDirectReports(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	reports, err := h.service.ListDirectReports(r.Context(), tenantID, empID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, reports)
+}
+
+// @Summary Get Org Chart
+// @Description Returns the whole tenant as a forest of nested reporting trees with business unit, department and job title labels, suitable for rendering an org chart. Employees without a manager are roots.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param depth query int false "Reporting levels below each root (default 3, max 50)"
+// @Param includeInactive query bool false "Include inactive employees"
+// @Success 200 {array} logic.OrgChartNode
+// @Router /api/v1/employees/org-chart [get]
+func (h *EmployeeHandler) HandleGetOrgChart(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("includeInactive"))
+
+	chart, err := h.service.GetOrgChart(r.Context(), tenantID, parseDepth(r), includeInactive)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, chart)
+}
+
+// @Summary Get Span of Control
+// @Description Lists every manager with their number of direct reports, total headcount below them and the number of reporting layers they span, largest teams first.
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param includeInactive query bool false "Include inactive employees"
+// @Success 200 {array} logic.SpanOfControl
+// @Router /api/v1/employees/span-of-control [get]
+func (h *EmployeeHandler) HandleGetSpanOfControl(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("includeInactive"))
+
+	spans, err := h.service.GetSpanOfControl(r.Context(), tenantID, includeInactive)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, spans)
+}
@@ -0,0 +1,158 @@
+package hr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// PersonalDetailsHandler serves employees' encrypted personal data to holders of the
+// personal data permissions
+type PersonalDetailsHandler struct {
+	service *logic.PersonalDetailsService
+}
+
+func NewPersonalDetailsHandler(service *logic.PersonalDetailsService) *PersonalDetailsHandler {
+	return &PersonalDetailsHandler{service: service}
+}
+
+// RegisterRoutes mounts the personal details under /employees
+func (h *PersonalDetailsHandler) RegisterRoutes(r chi.Router) {
+	r.With(authHTTP.RequirePermission(iam.PermPersonalDataRead), authHTTP.DenyImpersonation).Get("/{id}/personal-details", h.HandleGet)
+	r.With(authHTTP.RequirePermission(iam.PermPersonalDataManage), authHTTP.DenyImpersonation).Put("/{id}/personal-details", h.HandleUpdate)
+}
+
+func writePersonalDetailsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Employee not found")
+	case errors.Is(err, logic.ErrInvalidNINumber),
+		errors.Is(err, logic.ErrInvalidDateOfBirth):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrEmployeeErased):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// writePersonalDetails keeps personal data out of browser and proxy caches
+func writePersonalDetails(w http.ResponseWriter, details logic.PersonalDetails) {
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, details)
+}
+
+// HandleGet godoc
+// @Summary      Get an employee's personal details
+// @Description  Decrypts the employee's personal email, phone, address, date of birth, emergency contacts and National Insurance number. Requires the personal-data:read permission, granted by HR_ADMIN, and is refused while impersonating. Every read is recorded in the audit log as READ on EmployeePersonalDetails with the fields returned.
+// @Tags         Employees
+// @Produce      json
+// @Param        id   path      string  true  "Employee UUID"
+// @Security     BearerAuth
+// @Success      200  {object}  logic.PersonalDetails
+// @Failure      400  {object}  map[string]interface{} "Invalid ID format"
+// @Failure      403  {object}  map[string]interface{} "Missing permission"
+// @Failure      404  {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/employees/{id}/personal-details [get]
+func (h *PersonalDetailsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	details, err := h.service.GetPersonalDetails(r.Context(), tenantID, actorID, empID)
+	if err != nil {
+		writePersonalDetailsError(w, err)
+		return
+	}
+	writePersonalDetails(w, details)
+}
+
+type PersonalDetailsRequest struct {
+	PersonalEmail     *string                  `json:"personalEmail" validate:"omitempty,email,max=320"`
+	Phone             *string                  `json:"phone" validate:"omitempty,max=50"`
+	Address           *logic.Address           `json:"address"`
+	DateOfBirth       *string                  `json:"dateOfBirth"`
+	EmergencyContacts []logic.EmergencyContact `json:"emergencyContacts" validate:"max=10,dive"`
+	NINumber          *string                  `json:"niNumber"`
+}
+
+// HandleUpdate godoc
+// @Summary      Replace an employee's personal details
+// @Description  Replaces all personal details of the employee; fields left out or null are cleared. Values are encrypted before they are stored. Requires the personal-data:manage permission and is refused while impersonating. The audit entry lists the fields that changed, never their values.
+// @Tags         Employees
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Employee UUID"
+// @Param        request  body      PersonalDetailsRequest  true  "Personal details"
+// @Security     BearerAuth
+// @Success      200      {object}  logic.PersonalDetails
+// @Failure      400      {object}  map[string]interface{} "Validation error"
+// @Failure      403      {object}  map[string]interface{} "Missing permission"
+// @Failure      404      {object}  map[string]interface{} "Employee not found"
+// @Failure      409      {object}  map[string]interface{} "Employee has been erased"
+// @Router       /api/v1/employees/{id}/personal-details [put]
+func (h *PersonalDetailsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	var req PersonalDetailsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	details, err := h.service.UpdatePersonalDetails(r.Context(), tenantID, actorID, empID, logic.PersonalDetailsInput{
+		PersonalEmail:     req.PersonalEmail,
+		Phone:             req.Phone,
+		Address:           req.Address,
+		DateOfBirth:       req.DateOfBirth,
+		EmergencyContacts: req.EmergencyContacts,
+		NINumber:          req.NINumber,
+	})
+	if err != nil {
+		writePersonalDetailsError(w, err)
+		return
+	}
+	writePersonalDetails(w, details)
+}
@@ -0,0 +1,156 @@
+package iam
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	hrLogic "github.com/INOVA/DML/internal/logic/hr"
+	logic "github.com/INOVA/DML/internal/logic/iam"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MeHandler serves the signed-in user's own profile
+type MeHandler struct {
+	userService     *logic.UserService
+	employeeService *hrLogic.EmployeeService
+}
+
+func NewMeHandler(userService *logic.UserService, employeeService *hrLogic.EmployeeService) *MeHandler {
+	return &MeHandler{
+		userService:     userService,
+		employeeService: employeeService,
+	}
+}
+
+// RegisterRoutes mounts the profile at the root of /me
+func (h *MeHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleGet)
+	r.Patch("/", h.HandleUpdate)
+}
+
+// MeResponse is the caller's profile with their linked employee record
+type MeResponse struct {
+	logic.Profile
+	Employee *hrLogic.EmployeeWithDetails `json:"employee"`
+	// ImpersonatorID is set while an administrator is impersonating the user
+	ImpersonatorID *pgtype.UUID `json:"impersonatorId,omitempty"`
+}
+
+type UpdateMeRequest struct {
+	DisplayName *string                `json:"displayName" validate:"omitempty,max=200"`
+	AvatarURL   *string                `json:"avatarUrl" validate:"omitempty,url,max=2048"`
+	Preferences map[string]interface{} `json:"preferences"`
+}
+
+// HandleGet godoc
+// @Summary      Get my profile
+// @Description  Returns the signed-in user's account, linked employee, role grants with their site or department scope, and the permissions those roles grant.
+// @Tags         Me
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200      {object}  MeResponse
+// @Failure      401      {object}  map[string]interface{} "Unauthorized"
+// @Failure      404      {object}  map[string]interface{} "No user profile, e.g. for service account keys"
+// @Router       /api/v1/me [get]
+func (h *MeHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := h.userService.GetProfile(r.Context(), tenantID, userID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.respond(w, r, profile)
+}
+
+// HandleUpdate godoc
+// @Summary      Update my profile
+// @Description  Edits the signed-in user's display name, avatar URL and front-end preferences. Omitted fields are left unchanged and empty strings clear them. Preferences are merged key by key into the stored ones; a null value removes a key.
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      UpdateMeRequest  true  "Profile changes"
+// @Success      200      {object}  MeResponse
+// @Failure      400      {object}  map[string]interface{} "Bad request payload"
+// @Failure      401      {object}  map[string]interface{} "Unauthorized"
+// @Router       /api/v1/me [patch]
+func (h *MeHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateMeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	profile, err := h.userService.UpdateProfile(r.Context(), tenantID, userID, logic.ProfileInput{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Preferences: req.Preferences,
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.respond(w, r, profile)
+}
+
+// respond adds the linked employee and any impersonating administrator to a profile
+func (h *MeHandler) respond(w http.ResponseWriter, r *http.Request, profile logic.Profile) {
+	out := MeResponse{Profile: profile}
+
+	emp, err := h.employeeService.GetEmployeeWithDetails(r.Context(), profile.User.TenantID, profile.User.EmployeeID)
+	if err == nil {
+		out.Employee = &emp
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		response.DBError(w, err)
+		return
+	}
+
+	if impersonatorID, ok := authHTTP.GetImpersonatorIDFromContext(r.Context()); ok {
+		out.ImpersonatorID = &impersonatorID
+	}
+	response.JSON(w, http.StatusOK, out)
+}
+
+func (h *MeHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, logic.ErrPreferencesTooLarge):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
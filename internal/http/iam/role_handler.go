@@ -2,6 +2,7 @@ package iam
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
@@ -95,6 +96,10 @@ func (h *RoleHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 	roleID, _ := parseUUIDString(uuid.New().String())
 
 	role, err := h.service.CreateRole(r.Context(), roleID, tenantID, actorID, req.Code, req.Name, req.Description)
+	if errors.Is(err, logic.ErrReservedRoleCode) {
+		response.Error(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		response.DBError(w, err)
 		return
@@ -2,6 +2,7 @@ package iam
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
@@ -10,6 +11,7 @@ import (
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -34,6 +36,9 @@ func (h *UserHandler) RegisterRoutes(r chi.Router) {
 	// Role assignments
 	r.With(authHTTP.RequireRole("ADMIN")).Post("/{userID}/roles", h.HandleAssignRole)
 	r.With(authHTTP.RequireRole("ADMIN")).Delete("/{userID}/roles/{roleID}", h.HandleRevokeRole)
+
+	// Support sessions acting as another user
+	r.With(authHTTP.RequirePermission(logic.PermUsersImpersonate), authHTTP.DenyImpersonation).Post("/{userID}/impersonate", h.HandleImpersonate)
 }
 
 func parseUUIDString(idStr string) (pgtype.UUID, error) {
@@ -203,7 +208,8 @@ type AssignRoleRequest struct {
 // @Success      201      {object}  map[string]interface{} "Role assigned successfully"
 // @Failure      400      {object}  map[string]interface{} "Bad request payload"
 // @Failure      401      {object}  map[string]interface{} "Unauthorized"
-// @Failure      403      {object}  map[string]interface{} "Forbidden (Requires ADMIN)"
+// @Failure      403      {object}  map[string]interface{} "Forbidden (Requires ADMIN, or the role grants a permission you do not hold)"
+// @Failure      404      {object}  map[string]interface{} "Role not found"
 // @Router       /api/v1/users/{userID}/roles [post]
 func (h *UserHandler) HandleAssignRole(w http.ResponseWriter, r *http.Request) {
 	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
@@ -244,7 +250,14 @@ func (h *UserHandler) HandleAssignRole(w http.ResponseWriter, r *http.Request) {
 
 	err = h.userRoleService.AssignUserRole(r.Context(), tenantID, userID, roleID, grantedByUserID)
 	if err != nil {
-		response.DBError(w, err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "Role not found")
+		case errors.Is(err, logic.ErrRoleExceedsGranter):
+			response.Error(w, http.StatusForbidden, err.Error())
+		default:
+			response.DBError(w, err)
+		}
 		return
 	}
 
@@ -258,6 +271,12 @@ func (h *UserHandler) HandleRevokeRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	userIDStr := chi.URLParam(r, "userID")
 	userID, err := parseUUIDString(userIDStr)
 	if err != nil {
@@ -272,7 +291,7 @@ func (h *UserHandler) HandleRevokeRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.userRoleService.RevokeUserRole(r.Context(), tenantID, userID, roleID)
+	err = h.userRoleService.RevokeUserRole(r.Context(), tenantID, actorID, userID, roleID)
 	if err != nil {
 		response.DBError(w, err)
 		return
@@ -280,3 +299,60 @@ func (h *UserHandler) HandleRevokeRole(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Role revoked successfully"})
 }
+
+// HandleImpersonate godoc
+// @Summary      Impersonate a user
+// @Description  Issues a 30 minute session token acting as the user, with their roles, so support staff can reproduce what they see. The token's act claim names the administrator; front-ends should show a banner while it is present. Changes made with it are audited under both users. Credential endpoints such as MFA and API keys, and personal data, are unavailable while impersonating. Requires the users:impersonate permission. Administrators cannot be impersonated, nor can users whose roles grant a permission the caller lacks, such as HR_ADMIN for an ADMIN.
+// @Tags         Users
+// @Produce      json
+// @Param        userID   path      string  true  "User ID"
+// @Security     BearerAuth
+// @Success      200      {object}  map[string]interface{} "Impersonation token"
+// @Failure      403      {object}  map[string]interface{} "Forbidden (Requires ADMIN; not available with API keys or while impersonating)"
+// @Failure      404      {object}  map[string]interface{} "User not found"
+// @Failure      409      {object}  map[string]interface{} "User cannot be impersonated"
+// @Router       /api/v1/users/{userID}/impersonate [post]
+func (h *UserHandler) HandleImpersonate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok || !actorID.Valid {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Impersonation must be traceable to a person signed in, not an integration
+	if _, ok := authHTTP.GetAPIKeyIDFromContext(r.Context()); ok {
+		response.Error(w, http.StatusForbidden, "Forbidden: API keys cannot impersonate users")
+		return
+	}
+
+	userID, err := parseUUIDString(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	session, err := h.userService.Impersonate(r.Context(), tenantID, actorID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, logic.ErrImpersonateForbidden),
+			errors.Is(err, logic.ErrImpersonateElevated):
+			response.Error(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, logic.ErrImpersonateSelf),
+			errors.Is(err, logic.ErrImpersonateAdmin),
+			errors.Is(err, logic.ErrImpersonateInactive):
+			response.Error(w, http.StatusConflict, err.Error())
+		default:
+			response.DBError(w, err)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, session)
+}
@@ -0,0 +1,735 @@
+package internalaudit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/capa"
+	logic "github.com/INOVA/DML/internal/logic/internalaudit"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultCalendarDays is the calendar window when ?to is omitted
+const defaultCalendarDays = 90
+
+type AuditHandler struct {
+	service *logic.InternalAuditService
+}
+
+func NewAuditHandler(service *logic.InternalAuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/", h.HandleCreate)
+	r.Get("/calendar", h.HandleCalendar)
+	r.Get("/{id}", h.HandleGet)
+	r.Put("/{id}", h.HandleUpdate)
+	r.Post("/{id}/start", h.HandleStart)
+	r.Post("/{id}/complete", h.HandleComplete)
+	r.Post("/{id}/cancel", h.HandleCancel)
+	r.Put("/{id}/checklist", h.HandleSetChecklist)
+	r.Put("/{id}/checklist/{itemId}/result", h.HandleRecordResult)
+	r.Post("/{id}/findings", h.HandleAddFinding)
+	r.Post("/{id}/findings/{findingId}/ncr", h.HandleRaiseNCR)
+}
+
+func parseOptionalUUID(idStr *string) pgtype.UUID {
+	if idStr == nil || *idStr == "" {
+		return pgtype.UUID{Valid: false}
+	}
+	parsed, err := parseUUIDString(*idStr)
+	if err != nil {
+		return pgtype.UUID{Valid: false}
+	}
+	return parsed
+}
+
+func isAdmin(r *http.Request) bool {
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAuditError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, notFound)
+	case errors.Is(err, logic.ErrProgrammeNotFound),
+		errors.Is(err, logic.ErrProgrammeInactive),
+		errors.Is(err, logic.ErrEmployeeNotFound),
+		errors.Is(err, logic.ErrDepartmentNotAtSite),
+		errors.Is(err, logic.ErrAuditorNotIndependent),
+		errors.Is(err, logic.ErrInvalidSchedule),
+		errors.Is(err, logic.ErrChecklistItemNotFound),
+		errors.Is(err, logic.ErrObservationNoCAPA),
+		errors.Is(err, logic.ErrDepartmentRequired),
+		errors.Is(err, capa.ErrEmployeeNotFound),
+		errors.Is(err, capa.ErrDepartmentNotAtSite):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotLeadAuditor),
+		errors.Is(err, logic.ErrNotAuditTeam),
+		errors.Is(err, logic.ErrLeadAuditorChange):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrAuditNotPlanned),
+		errors.Is(err, logic.ErrAuditNotInProgress),
+		errors.Is(err, logic.ErrChecklistIncomplete),
+		errors.Is(err, logic.ErrFindingHasNCR):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// @Summary List Internal Audits
+// @Description Get a paginated list of internal audits with their programme, site, lead auditor and finding counts.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param search query string false "Search by title"
+// @Param programmeId query string false "Only audits of this programme"
+// @Param status query string false "planned, in_progress, completed or cancelled"
+// @Param businessUnitId query string false "Only audits of this business unit"
+// @Param departmentId query string false "Only audits of this department"
+// @Success 200 {object} map[string]interface{} "Paginated audit data"
+// @Router /api/v1/internal-audits [get]
+func (h *AuditHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	q := r.URL.Query()
+	filter := logic.AuditFilter{Status: q.Get("status")}
+	for key, dst := range map[string]*pgtype.UUID{
+		"programmeId":    &filter.ProgrammeID,
+		"businessUnitId": &filter.BusinessUnitID,
+		"departmentId":   &filter.DepartmentID,
+	} {
+		if v := q.Get(key); v != "" {
+			id, err := parseUUIDString(v)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "Invalid "+key+" format")
+				return
+			}
+			*dst = id
+		}
+	}
+
+	audits, total, err := h.service.ListAudits(r.Context(), tenantID, params, filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list internal audits")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, audits, params.Page, params.Size, int(total))
+}
+
+// @Summary Internal Audit Calendar
+// @Description Lists the audits whose planned dates overlap the requested range, ordered by start date. Cancelled audits are left out. Defaults to the next 90 days.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD), default today"
+// @Param to query string false "Last day (YYYY-MM-DD), default 90 days after from"
+// @Param businessUnitId query string false "Only audits of this business unit"
+// @Param auditorId query string false "Only audits this employee leads or is on the team of"
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/internal-audits/calendar [get]
+func (h *AuditHandler) HandleCalendar(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	filter := logic.CalendarFilter{From: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid from date, expected YYYY-MM-DD")
+			return
+		}
+		filter.From = from
+	}
+	filter.To = filter.From.AddDate(0, 0, defaultCalendarDays)
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid to date, expected YYYY-MM-DD")
+			return
+		}
+		filter.To = to
+	}
+	if v := q.Get("businessUnitId"); v != "" {
+		buID, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid business unit ID format")
+			return
+		}
+		filter.BusinessUnitID = buID
+	}
+	if v := q.Get("auditorId"); v != "" {
+		auditorID, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid auditor ID format")
+			return
+		}
+		filter.AuditorEmployeeID = auditorID
+	}
+
+	entries, err := h.service.Calendar(r.Context(), tenantID, filter)
+	if err != nil {
+		writeAuditError(w, err, "Calendar not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, entries)
+}
+
+type TeamMemberRequest struct {
+	EmployeeID string `json:"employeeId" validate:"required,uuid"`
+	Role       string `json:"role" validate:"omitempty,oneof=auditor technical_expert observer"`
+}
+
+type AuditRequest struct {
+	ProgrammeID           string              `json:"programmeId" validate:"required,uuid"`
+	Title                 string              `json:"title" validate:"required"`
+	Scope                 *string             `json:"scope"`
+	Criteria              *string             `json:"criteria"`
+	BusinessUnitID        string              `json:"businessUnitId" validate:"required,uuid"`
+	DepartmentID          *string             `json:"departmentId" validate:"omitempty,uuid"`
+	LeadAuditorEmployeeID string              `json:"leadAuditorEmployeeId" validate:"required,uuid"`
+	PlannedStart          string              `json:"plannedStart" validate:"required,datetime=2006-01-02"`
+	PlannedEnd            string              `json:"plannedEnd" validate:"required,datetime=2006-01-02"`
+	Team                  []TeamMemberRequest `json:"team" validate:"dive"`
+}
+
+func (req AuditRequest) input() logic.AuditInput {
+	programmeID, _ := parseUUIDString(req.ProgrammeID)
+	buID, _ := parseUUIDString(req.BusinessUnitID)
+	leadID, _ := parseUUIDString(req.LeadAuditorEmployeeID)
+	start, _ := time.Parse("2006-01-02", req.PlannedStart)
+	end, _ := time.Parse("2006-01-02", req.PlannedEnd)
+
+	team := make([]logic.TeamMember, 0, len(req.Team))
+	for _, m := range req.Team {
+		employeeID, _ := parseUUIDString(m.EmployeeID)
+		role := m.Role
+		if role == "" {
+			role = logic.RoleAuditor
+		}
+		team = append(team, logic.TeamMember{EmployeeID: employeeID, Role: role})
+	}
+
+	return logic.AuditInput{
+		ProgrammeID:           programmeID,
+		Title:                 req.Title,
+		Scope:                 req.Scope,
+		Criteria:              req.Criteria,
+		BusinessUnitID:        buID,
+		DepartmentID:          parseOptionalUUID(req.DepartmentID),
+		LeadAuditorEmployeeID: leadID,
+		PlannedStart:          start,
+		PlannedEnd:            end,
+		Team:                  team,
+	}
+}
+
+// @Summary Schedule an Internal Audit
+// @Description Schedules an audit under a programme for a business unit, optionally narrowed to one of its departments. The lead auditor and team are employees; auditors cannot belong to the audited department. Requires ADMIN.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AuditRequest true "Audit Payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid programme, site or auditor"
+// @Router /api/v1/internal-audits [post]
+func (h *AuditHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req AuditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	auditID, _ := parseUUIDString(uuid.New().String())
+
+	created, err := h.service.CreateAudit(r.Context(), auditID, tenantID, actorID, req.input())
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, created)
+}
+
+// @Summary Get an Internal Audit
+// @Description Fetch an internal audit with its team, checklist and findings.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/internal-audits/{id} [get]
+func (h *AuditHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	detail, err := h.service.GetAudit(r.Context(), tenantID, auditID)
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, detail)
+}
+
+// @Summary Update an Internal Audit
+// @Description Replaces the plan and team of an audit that has not started. The programme of an audit cannot be changed. Requires ADMIN or the lead auditor; only ADMIN can change the lead auditor.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param request body AuditRequest true "Audit Payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Audit already started"
+// @Router /api/v1/internal-audits/{id} [put]
+func (h *AuditHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	var req AuditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	updated, err := h.service.UpdateAudit(r.Context(), tenantID, actorID, auditID, req.input(), isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, updated)
+}
+
+// @Summary Start an Internal Audit
+// @Description Moves a planned audit to in_progress. Checklist results and findings can only be recorded while an audit is in progress. Requires ADMIN or the lead auditor.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/internal-audits/{id}/start [post]
+func (h *AuditHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	updated, err := h.service.StartAudit(r.Context(), tenantID, actorID, auditID, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, updated)
+}
+
+type CloseAuditRequest struct {
+	Summary *string `json:"summary"`
+}
+
+// @Summary Complete an Internal Audit
+// @Description Completes a running audit with an optional summary. Every checklist item must have been answered. Requires ADMIN or the lead auditor.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param request body CloseAuditRequest false "Audit summary"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Checklist incomplete"
+// @Router /api/v1/internal-audits/{id}/complete [post]
+func (h *AuditHandler) HandleComplete(w http.ResponseWriter, r *http.Request) {
+	h.handleClose(w, r, h.service.CompleteAudit)
+}
+
+// @Summary Cancel an Internal Audit
+// @Description Cancels an audit that has not started. The summary records the reason. Requires ADMIN or the lead auditor.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param request body CloseAuditRequest false "Cancellation reason"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/internal-audits/{id}/cancel [post]
+func (h *AuditHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	h.handleClose(w, r, h.service.CancelAudit)
+}
+
+type closeFunc func(ctx context.Context, tenantID, actorID, id pgtype.UUID, summary *string, asAdmin bool) (domain.InternalAudit, error)
+
+func (h *AuditHandler) handleClose(w http.ResponseWriter, r *http.Request, closeAudit closeFunc) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	var req CloseAuditRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	updated, err := closeAudit(r.Context(), tenantID, actorID, auditID, req.Summary, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, updated)
+}
+
+type ChecklistItemRequest struct {
+	Clause   *string `json:"clause"`
+	Question string  `json:"question" validate:"required"`
+}
+
+type SetChecklistRequest struct {
+	Items []ChecklistItemRequest `json:"items" validate:"required,dive"`
+}
+
+// @Summary Set an Audit Checklist
+// @Description Replaces the checklist of a planned audit. Items are numbered in the order given. Requires ADMIN or the lead auditor.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param request body SetChecklistRequest true "Checklist items"
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/internal-audits/{id}/checklist [put]
+func (h *AuditHandler) HandleSetChecklist(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	var req SetChecklistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	items := make([]logic.ChecklistItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, logic.ChecklistItemInput{Clause: item.Clause, Question: item.Question})
+	}
+
+	checklist, err := h.service.SetChecklist(r.Context(), tenantID, actorID, auditID, items, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, checklist)
+}
+
+type ChecklistResultRequest struct {
+	Result string  `json:"result" validate:"required,oneof=conforming nonconforming not_applicable"`
+	Notes  *string `json:"notes"`
+}
+
+// @Summary Record a Checklist Result
+// @Description Answers a checklist item of an audit in progress. Nonconforming answers are usually followed by a finding. Requires ADMIN, the lead auditor, or an auditor or technical expert on the team.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param itemId path string true "Checklist item UUID"
+// @Param request body ChecklistResultRequest true "Result"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/internal-audits/{id}/checklist/{itemId}/result [put]
+func (h *AuditHandler) HandleRecordResult(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	itemID, err := parseUUIDString(chi.URLParam(r, "itemId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid checklist item ID format")
+		return
+	}
+
+	var req ChecklistResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	item, err := h.service.RecordResult(r.Context(), tenantID, actorID, auditID, itemID, req.Result, req.Notes, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Checklist item not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, item)
+}
+
+type FindingRequest struct {
+	ChecklistItemID *string `json:"checklistItemId" validate:"omitempty,uuid"`
+	Classification  string  `json:"classification" validate:"required,oneof=major minor observation"`
+	Clause          *string `json:"clause"`
+	Description     string  `json:"description" validate:"required"`
+	Evidence        *string `json:"evidence"`
+}
+
+// @Summary Record an Audit Finding
+// @Description Records a major or minor nonconformity or an observation on an audit in progress, optionally against a checklist item whose clause it inherits. Requires ADMIN, the lead auditor, or an auditor or technical expert on the team.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param request body FindingRequest true "Finding Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/internal-audits/{id}/findings [post]
+func (h *AuditHandler) HandleAddFinding(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	var req FindingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	findingID, _ := parseUUIDString(uuid.New().String())
+
+	finding, err := h.service.AddFinding(r.Context(), findingID, tenantID, actorID, auditID, logic.FindingInput{
+		ChecklistItemID: parseOptionalUUID(req.ChecklistItemID),
+		Classification:  req.Classification,
+		Clause:          req.Clause,
+		Description:     req.Description,
+		Evidence:        req.Evidence,
+	}, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Internal audit not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, finding)
+}
+
+type RaiseNCRRequest struct {
+	DepartmentID    *string `json:"departmentId" validate:"omitempty,uuid"`
+	OwnerEmployeeID *string `json:"ownerEmployeeId" validate:"omitempty,uuid"`
+}
+
+// @Summary Raise a CAPA from a Finding
+// @Description Opens a non-conformance report classified as "audit" from a major or minor finding, with the lead auditor as reporter. The department defaults to the audited department. A finding can raise only one NCR. Requires ADMIN or the lead auditor.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit UUID"
+// @Param findingId path string true "Finding UUID"
+// @Param request body RaiseNCRRequest false "NCR placement"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "NCR already raised"
+// @Router /api/v1/internal-audits/{id}/findings/{findingId}/ncr [post]
+func (h *AuditHandler) HandleRaiseNCR(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auditID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid audit ID format")
+		return
+	}
+
+	findingID, err := parseUUIDString(chi.URLParam(r, "findingId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid finding ID format")
+		return
+	}
+
+	var req RaiseNCRRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if err := response.Validate.Struct(&req); err != nil {
+			response.ValidationError(w, err)
+			return
+		}
+	}
+
+	ncr, err := h.service.RaiseNCR(r.Context(), tenantID, actorID, auditID, findingID, logic.RaiseNCRInput{
+		DepartmentID:    parseOptionalUUID(req.DepartmentID),
+		OwnerEmployeeID: parseOptionalUUID(req.OwnerEmployeeID),
+	}, isAdmin(r))
+	if err != nil {
+		writeAuditError(w, err, "Finding not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, ncr)
+}
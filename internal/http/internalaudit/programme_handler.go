@@ -0,0 +1,213 @@
+package internalaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/internalaudit"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ProgrammeHandler struct {
+	service *logic.InternalAuditService
+}
+
+func NewProgrammeHandler(service *logic.InternalAuditService) *ProgrammeHandler {
+	return &ProgrammeHandler{service: service}
+}
+
+func (h *ProgrammeHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/", h.HandleCreate)
+	r.Get("/{id}", h.HandleGet)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}", h.HandleUpdate)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+type ProgrammeRequest struct {
+	Name       string  `json:"name" validate:"required"`
+	Year       int32   `json:"year" validate:"required,gte=2000,lte=2100"`
+	Objectives *string `json:"objectives"`
+	IsActive   *bool   `json:"isActive"`
+}
+
+func (req ProgrammeRequest) input() logic.ProgrammeInput {
+	in := logic.ProgrammeInput{
+		Name:       req.Name,
+		Year:       req.Year,
+		Objectives: req.Objectives,
+		IsActive:   true,
+	}
+	if req.IsActive != nil {
+		in.IsActive = *req.IsActive
+	}
+	return in
+}
+
+// @Summary List Audit Programmes
+// @Description Get a paginated list of internal audit programmes, newest year first.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param search query string false "Search by name"
+// @Success 200 {object} map[string]interface{} "Paginated programme data"
+// @Router /api/v1/audit-programmes [get]
+func (h *ProgrammeHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	programmes, total, err := h.service.ListProgrammes(r.Context(), tenantID, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list audit programmes")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, programmes, params.Page, params.Size, int(total))
+}
+
+// @Summary Create an Audit Programme
+// @Description Adds an internal audit programme, typically one per year, under which audits are scheduled.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ProgrammeRequest true "Programme Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/audit-programmes [post]
+func (h *ProgrammeHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ProgrammeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	programmeID, _ := parseUUIDString(uuid.New().String())
+
+	programme, err := h.service.CreateProgramme(r.Context(), programmeID, tenantID, actorID, req.input())
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, programme)
+}
+
+// @Summary Get an Audit Programme
+// @Description Fetch a single internal audit programme.
+// @Tags Internal Audits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Programme UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/audit-programmes/{id} [get]
+func (h *ProgrammeHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	programmeID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid programme ID format")
+		return
+	}
+
+	programme, err := h.service.GetProgramme(r.Context(), tenantID, programmeID)
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Audit programme not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, programme)
+}
+
+// @Summary Update an Audit Programme
+// @Description Renames an audit programme or retires it. Inactive programmes cannot take new audits.
+// @Tags Internal Audits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Programme UUID"
+// @Param request body ProgrammeRequest true "Programme Payload"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/audit-programmes/{id} [put]
+func (h *ProgrammeHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	programmeID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid programme ID format")
+		return
+	}
+
+	var req ProgrammeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	programme, err := h.service.UpdateProgramme(r.Context(), tenantID, actorID, programmeID, req.input())
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Audit programme not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, programme)
+}
@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/jobs"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type JobHandler struct {
+	runner *logic.Runner
+}
+
+func NewJobHandler(runner *logic.Runner) *JobHandler {
+	return &JobHandler{runner: runner}
+}
+
+func (h *JobHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.Get("/{id}", h.HandleGet)
+	r.Get("/{id}/download", h.HandleDownload)
+}
+
+func isAdmin(r *http.Request) bool {
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+// HandleList godoc
+// @Summary      List background jobs
+// @Description  Retrieves a paginated list of background jobs (imports, exports) for the authenticated tenant, newest first. Users other than ADMIN see only the jobs they started.
+// @Tags         Jobs
+// @Produce      json
+// @Param        page    query     int     false  "Page number" default(1)
+// @Param        size    query     int     false  "Page size" default(50)
+// @Param        kind    query     string  false  "Filter by job kind (e.g. employee_import)"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Paginated job data"
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Router       /api/v1/jobs [get]
+func (h *JobHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+	kind := r.URL.Query().Get("kind")
+
+	jobs, total, err := h.runner.ListJobs(r.Context(), tenantID, actorID, kind, params, isAdmin(r))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list jobs")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, jobs, params.Page, params.Size, int(total))
+}
+
+// HandleGet godoc
+// @Summary      Get a background job
+// @Description  Polls the status, progress and result of a background job. Only the user who started the job, or an ADMIN, can see it.
+// @Tags         Jobs
+// @Produce      json
+// @Param        id      path      string  true  "Job ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Job state"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/jobs/{id} [get]
+func (h *JobHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	jobID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job ID format")
+		return
+	}
+
+	job, err := h.runner.GetJob(r.Context(), tenantID, actorID, jobID, isAdmin(r))
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, job)
+}
+
+// HandleDownload godoc
+// @Summary      Download a job's file
+// @Description  Downloads the file produced by a succeeded background job, such as an export. Only the user who started the job, or an ADMIN, can download it.
+// @Tags         Jobs
+// @Produce      octet-stream
+// @Param        id      path      string  true  "Job ID"
+// @Security     BearerAuth
+// @Success      200     {file}    file  "Job output file"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Job has no file (yet)"
+// @Router       /api/v1/jobs/{id}/download [get]
+func (h *JobHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	jobID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job ID format")
+		return
+	}
+
+	file, job, err := h.runner.OpenFile(r.Context(), tenantID, actorID, jobID, isAdmin(r))
+	if errors.Is(err, logic.ErrNoFile) {
+		response.Error(w, http.StatusConflict, "Job has not produced a downloadable file")
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Job file not found")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if job.ContentType.Valid {
+		w.Header().Set("Content-Type", job.ContentType.String)
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+job.FileName.String+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("job file download interrupted: %v", err)
+	}
+}
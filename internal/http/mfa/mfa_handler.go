@@ -0,0 +1,401 @@
+package mfa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	authLogic "github.com/INOVA/DML/internal/logic/auth"
+	logic "github.com/INOVA/DML/internal/logic/mfa"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type MFAHandler struct {
+	service *logic.MFAService
+}
+
+func NewMFAHandler(service *logic.MFAService) *MFAHandler {
+	return &MFAHandler{service: service}
+}
+
+// RegisterRoutes mounts the second sign-in step, authenticated by the mfaToken from the
+// password or single sign-on step
+func (h *MFAHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/enrol", h.HandleLoginEnrol)
+	r.Post("/verify", h.HandleLoginVerify)
+}
+
+// RegisterMeRoutes mounts the caller's own MFA settings under /me/mfa
+func (h *MFAHandler) RegisterMeRoutes(r chi.Router) {
+	r.Get("/", h.HandleStatus)
+	r.Post("/enrol", h.HandleEnrol)
+	r.Post("/confirm", h.HandleConfirm)
+	r.Post("/recovery-codes", h.HandleRegenerateRecoveryCodes)
+	r.Post("/disable", h.HandleDisable)
+}
+
+// RegisterAdminRoutes mounts the tenant's enforcement policy and user resets
+func (h *MFAHandler) RegisterAdminRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/policy", h.HandleGetPolicy)
+	r.With(admin).Put("/policy", h.HandleSetPolicy)
+	r.With(admin).Delete("/users/{userId}", h.HandleReset)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, authLogic.ErrInvalidChallenge):
+		response.Error(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, logic.ErrInvalidCode), errors.Is(err, logic.ErrNotEnrolled):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrAlreadyEnrolled):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrRequiredByPolicy), errors.Is(err, logic.ErrAccountDisabled):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrLocked):
+		response.Error(w, http.StatusTooManyRequests, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+type LoginEnrolRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+}
+
+type LoginVerifyRequest struct {
+	MFAToken     string `json:"mfaToken" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+type CodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type PolicyRequest struct {
+	RoleIDs []string `json:"roleIds" validate:"dive,uuid"`
+}
+
+// @Summary Start MFA Enrolment at Sign-In
+// @Description For a login that answered mfaEnrolmentRequired: returns a new TOTP secret and its otpauth:// URI to show as a QR code. Finish with /auth/mfa/verify.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body LoginEnrolRequest true "MFA token from login"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Invalid or expired MFA token"
+// @Router /api/v1/auth/mfa/enrol [post]
+func (h *MFAHandler) HandleLoginEnrol(w http.ResponseWriter, r *http.Request) {
+	var req LoginEnrolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	enrolment, err := h.service.EnrolForLogin(r.Context(), req.MFAToken)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, enrolment)
+}
+
+// @Summary Verify MFA at Sign-In
+// @Description Completes a login that returned an mfaToken, with a TOTP code or a one-time recovery code. When the login required enrolment the code confirms the new authenticator and the response also carries the recovery codes, shown only once. Five wrong codes lock verification for 15 minutes.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body LoginVerifyRequest true "MFA token and code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid code"
+// @Failure 429 {object} map[string]interface{} "Too many failed attempts"
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *MFAHandler) HandleLoginVerify(w http.ResponseWriter, r *http.Request) {
+	var req LoginVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	result, err := h.service.VerifyLogin(r.Context(), req.MFAToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, result)
+}
+
+// @Summary Get My MFA Status
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/mfa [get]
+func (h *MFAHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), tenantID, userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// @Summary Start MFA Enrolment
+// @Description Returns a new TOTP secret and its otpauth:// URI to show as a QR code. MFA is on once a code is confirmed.
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "MFA is already enabled"
+// @Router /api/v1/me/mfa/enrol [post]
+func (h *MFAHandler) HandleEnrol(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	enrolment, err := h.service.Enrol(r.Context(), tenantID, userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, enrolment)
+}
+
+// @Summary Confirm MFA Enrolment
+// @Description Turns MFA on with a code from the new authenticator and returns ten one-time recovery codes, shown only once.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/mfa/confirm [post]
+func (h *MFAHandler) HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(tenantID, userID pgtype.UUID, code string) {
+		codes, err := h.service.Confirm(r.Context(), tenantID, userID, code)
+		if err != nil {
+			writeMFAError(w, err)
+			return
+		}
+		response.JSON(w, http.StatusOK, map[string][]string{"recoveryCodes": codes})
+	})
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Replaces all recovery codes after checking a current TOTP code.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/mfa/recovery-codes [post]
+func (h *MFAHandler) HandleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(tenantID, userID pgtype.UUID, code string) {
+		codes, err := h.service.RegenerateRecoveryCodes(r.Context(), tenantID, userID, code)
+		if err != nil {
+			writeMFAError(w, err)
+			return
+		}
+		response.JSON(w, http.StatusOK, map[string][]string{"recoveryCodes": codes})
+	})
+}
+
+// @Summary Disable MFA
+// @Description Turns the caller's MFA off after checking a current TOTP code. Not allowed when one of the caller's roles requires MFA.
+// @Tags MFA
+// @Accept json
+// @Security BearerAuth
+// @Param request body CodeRequest true "TOTP code"
+// @Success 204
+// @Failure 403 {object} map[string]interface{} "MFA is required for the caller's role"
+// @Router /api/v1/me/mfa/disable [post]
+func (h *MFAHandler) HandleDisable(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(tenantID, userID pgtype.UUID, code string) {
+		if err := h.service.Disable(r.Context(), tenantID, userID, code); err != nil {
+			writeMFAError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// withCode reads the caller and a TOTP code before running fn
+func (h *MFAHandler) withCode(w http.ResponseWriter, r *http.Request, fn func(tenantID, userID pgtype.UUID, code string)) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	fn(tenantID, userID, req.Code)
+}
+
+// @Summary Get the MFA Policy
+// @Description Lists the roles whose holders must use MFA to sign in with a password.
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/mfa/policy [get]
+func (h *MFAHandler) HandleGetPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	roles, err := h.service.GetPolicy(r.Context(), tenantID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, roles)
+}
+
+// @Summary Set the MFA Policy
+// @Description Replaces the roles whose holders must use MFA. Holders without it are made to enrol at their next sign-in, by password or single sign-on.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PolicyRequest true "Required roles"
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/mfa/policy [put]
+func (h *MFAHandler) HandleSetPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req PolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	roleIDs := make([]pgtype.UUID, 0, len(req.RoleIDs))
+	for _, id := range req.RoleIDs {
+		roleID, _ := parseUUIDString(id)
+		roleIDs = append(roleIDs, roleID)
+	}
+
+	roles, err := h.service.SetPolicy(r.Context(), tenantID, actorID, roleIDs)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, roles)
+}
+
+// @Summary Reset a User's MFA
+// @Description Removes a user's authenticator and recovery codes, e.g. after a lost phone. The reset is audited. If the user's role requires MFA they enrol again at their next sign-in.
+// @Tags MFA
+// @Security BearerAuth
+// @Param userId path string true "User UUID"
+// @Success 204
+// @Router /api/v1/mfa/users/{userId} [delete]
+func (h *MFAHandler) HandleReset(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := parseUUIDString(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	if err := h.service.Reset(r.Context(), tenantID, actorID, userID); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
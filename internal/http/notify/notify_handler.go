@@ -0,0 +1,384 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/notify"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type NotifyHandler struct {
+	mailer *logic.Mailer
+}
+
+func NewNotifyHandler(mailer *logic.Mailer) *NotifyHandler {
+	return &NotifyHandler{mailer: mailer}
+}
+
+// RegisterTemplateRoutes mounts template administration under /notification-templates
+func (h *NotifyHandler) RegisterTemplateRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleListTemplates)
+	r.With(admin).Get("/{kind}/{locale}", h.HandleGetTemplate)
+	r.With(admin).Put("/{kind}/{locale}", h.HandleSaveTemplate)
+	r.With(admin).Delete("/{kind}/{locale}", h.HandleDeleteTemplate)
+}
+
+// RegisterOutboxRoutes mounts the email outbox under /email-outbox
+func (h *NotifyHandler) RegisterOutboxRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleListOutbox)
+	r.With(admin).Post("/{id}/retry", h.HandleRetry)
+}
+
+// RegisterMeRoutes mounts the caller's notification settings under /me
+func (h *NotifyHandler) RegisterMeRoutes(r chi.Router) {
+	r.Get("/notification-settings", h.HandleGetSettings)
+	r.Put("/notification-settings", h.HandleUpdateSettings)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+// templateKey reads and validates the kind and locale path parameters
+func templateKey(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	kind := chi.URLParam(r, "kind")
+	locale := chi.URLParam(r, "locale")
+	if err := response.Validate.Var(locale, "bcp47_language_tag"); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid locale, expected a language tag such as en or de-AT")
+		return "", "", false
+	}
+	return kind, locale, true
+}
+
+func writeNotifyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, logic.ErrUnknownKind),
+		errors.Is(err, logic.ErrInvalidTemplate):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotFailed):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// @Summary List Notification Templates
+// @Description Lists the tenant's overrides of the built-in email templates.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/notification-templates [get]
+func (h *NotifyHandler) HandleListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templates, err := h.mailer.ListTemplates(r.Context(), tenantID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list templates")
+		return
+	}
+	response.JSON(w, http.StatusOK, templates)
+}
+
+// @Summary Get a Notification Template
+// @Description Returns the email template used for a notification kind in a locale: the tenant's override if there is one, otherwise the built-in template. source tells which.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Notification kind, e.g. task_assigned"
+// @Param locale path string true "Language tag, e.g. en or de-AT"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/notification-templates/{kind}/{locale} [get]
+func (h *NotifyHandler) HandleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	kind, locale, ok := templateKey(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.mailer.GetTemplate(r.Context(), tenantID, kind, locale)
+	if err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, tmpl)
+}
+
+type TemplateRequest struct {
+	Subject  string `json:"subject" validate:"required"`
+	HTMLBody string `json:"htmlBody" validate:"required"`
+	TextBody string `json:"textBody" validate:"required"`
+}
+
+// @Summary Save a Notification Template
+// @Description Creates or replaces the tenant's email template for a notification kind in a locale. Subjects and text bodies use Go text/template syntax and HTML bodies html/template, e.g. {{.RecipientName}}, {{.Title}}, {{.Body}}. Recipients get the template of their locale, then of its language, then English.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Notification kind, e.g. task_assigned"
+// @Param locale path string true "Language tag, e.g. en or de-AT"
+// @Param request body TemplateRequest true "Template Payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Unknown kind or template does not parse"
+// @Router /api/v1/notification-templates/{kind}/{locale} [put]
+func (h *NotifyHandler) HandleSaveTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	kind, locale, ok := templateKey(w, r)
+	if !ok {
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	saved, err := h.mailer.SaveTemplate(r.Context(), tenantID, actorID, kind, locale, logic.Template{
+		Subject: req.Subject,
+		HTML:    req.HTMLBody,
+		Text:    req.TextBody,
+	})
+	if err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, saved)
+}
+
+// @Summary Delete a Notification Template
+// @Description Removes the tenant's override so the built-in template applies again.
+// @Tags Notifications
+// @Security BearerAuth
+// @Param kind path string true "Notification kind, e.g. task_assigned"
+// @Param locale path string true "Language tag, e.g. en or de-AT"
+// @Success 204
+// @Router /api/v1/notification-templates/{kind}/{locale} [delete]
+func (h *NotifyHandler) HandleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	kind, locale, ok := templateKey(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.mailer.DeleteTemplate(r.Context(), tenantID, actorID, kind, locale); err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List Outgoing Emails
+// @Description Get a paginated list of queued, sent and failed emails, newest first. Emails are retried with growing delays and marked failed after repeated transport errors.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param status query string false "queued, sending, sent or failed"
+// @Success 200 {object} map[string]interface{} "Paginated email data"
+// @Router /api/v1/email-outbox [get]
+func (h *NotifyHandler) HandleListOutbox(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", logic.StatusQueued, logic.StatusSending, logic.StatusSent, logic.StatusFailed:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+
+	emails, total, err := h.mailer.ListOutbox(r.Context(), tenantID, status, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list emails")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, emails, params.Page, params.Size, int(total))
+}
+
+// @Summary Retry a Failed Email
+// @Description Queues a failed email for another round of delivery attempts.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Email UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Email has not failed"
+// @Router /api/v1/email-outbox/{id}/retry [post]
+func (h *NotifyHandler) HandleRetry(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	emailID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid email ID format")
+		return
+	}
+
+	email, err := h.mailer.Retry(r.Context(), tenantID, actorID, emailID)
+	if err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, email)
+}
+
+// @Summary Get My Notification Settings
+// @Description Returns the caller's notification language and, for every notification kind, whether it is delivered in-app and by email.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/notification-settings [get]
+func (h *NotifyHandler) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	settings, err := h.mailer.Settings(r.Context(), tenantID, userID)
+	if err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, settings)
+}
+
+type PreferenceRequest struct {
+	Kind  string `json:"kind" validate:"required"`
+	InApp bool   `json:"inApp"`
+	Email bool   `json:"email"`
+}
+
+type SettingsRequest struct {
+	Locale      *string             `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	Preferences []PreferenceRequest `json:"preferences" validate:"dive"`
+}
+
+// @Summary Update My Notification Settings
+// @Description Changes the caller's notification language and the channels listed. Kinds that are not listed keep their current setting.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SettingsRequest true "Settings Payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Unknown notification kind"
+// @Router /api/v1/me/notification-settings [put]
+func (h *NotifyHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	prefs := make([]logic.Preference, 0, len(req.Preferences))
+	for _, p := range req.Preferences {
+		prefs = append(prefs, logic.Preference{Kind: p.Kind, InApp: p.InApp, Email: p.Email})
+	}
+
+	settings, err := h.mailer.UpdateSettings(r.Context(), tenantID, userID, req.Locale, prefs)
+	if err != nil {
+		writeNotifyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, settings)
+}
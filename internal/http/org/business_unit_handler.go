@@ -2,23 +2,31 @@ package org
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
 	"github.com/INOVA/DML/internal/http/query"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
 	logic "github.com/INOVA/DML/internal/logic/org"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type BusinessUnitHandler struct {
-	service *logic.BusinessUnitService
+	service      *logic.BusinessUnitService
+	customFields *customFieldsHTTP.ValuesHandler
 }
 
-func NewBusinessUnitHandler(service *logic.BusinessUnitService) *BusinessUnitHandler {
-	return &BusinessUnitHandler{service: service}
+func NewBusinessUnitHandler(service *logic.BusinessUnitService, customFieldService *customFieldsLogic.CustomFieldService) *BusinessUnitHandler {
+	return &BusinessUnitHandler{
+		service:      service,
+		customFields: customFieldsHTTP.NewValuesHandler(customFieldService, customFieldsLogic.EntityBusinessUnit, "Business unit not found"),
+	}
 }
 
 func (h *BusinessUnitHandler) RegisterRoutes(r chi.Router) {
@@ -26,7 +34,12 @@ func (h *BusinessUnitHandler) RegisterRoutes(r chi.Router) {
 	// For this scaffolding phase, we'll accept tenant_id as a header.
 	r.Get("/", h.HandleList)
 	r.Post("/", h.HandleCreate)
+	r.Get("/department-matrix", h.HandleDepartmentMatrix)
 	r.Get("/{id}", h.HandleGet)
+	r.Get("/{id}/departments", h.HandleListDepartments)
+	h.customFields.RegisterRoutes(r)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}/departments/{departmentId}", h.HandleLinkDepartment)
+	r.With(authHTTP.RequireRole("ADMIN")).Delete("/{id}/departments/{departmentId}", h.HandleUnlinkDepartment)
 }
 
 func parseUUIDString(idStr string) (pgtype.UUID, error) {
@@ -49,6 +62,7 @@ func parseUUIDString(idStr string) (pgtype.UUID, error) {
 // @Param        page    query     int     false  "Page number" default(1)
 // @Param        size    query     int     false  "Page size" default(50)
 // @Param        search  query     string  false  "Search term (name/code)"
+// @Param        cf.key  query     string  false  "Only business units whose custom field <key> has this value; repeat for other keys"
 // @Security     BearerAuth
 // @Success      200     {object}  map[string]interface{} "Paginated business unit data"
 // @Failure      401     {object}  map[string]interface{} "Unauthorized"
@@ -63,7 +77,12 @@ func (h *BusinessUnitHandler) HandleList(w http.ResponseWriter, r *http.Request)
 
 	params := query.ParsePagination(r)
 
-	units, total, err := h.service.ListBusinessUnits(r.Context(), tenantID, params)
+	customFields, ok := h.customFields.Filter(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	units, total, err := h.service.ListBusinessUnits(r.Context(), tenantID, params, customFields)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to list business units")
 		return
@@ -107,8 +126,9 @@ func (h *BusinessUnitHandler) HandleGet(w http.ResponseWriter, r *http.Request)
 }
 
 type CreateBURequest struct {
-	Code string `json:"code" validate:"required"`
-	Name string `json:"name" validate:"required"`
+	Code         string                 `json:"code" validate:"required"`
+	Name         string                 `json:"name" validate:"required"`
+	CustomFields map[string]interface{} `json:"customFields"`
 }
 
 func (h *BusinessUnitHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
@@ -118,6 +138,12 @@ func (h *BusinessUnitHandler) HandleCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	var req CreateBURequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "Invalid request payload")
@@ -129,6 +155,10 @@ func (h *BusinessUnitHandler) HandleCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !h.customFields.Validate(w, r, tenantID, pgtype.UUID{}, req.CustomFields) {
+		return
+	}
+
 	buID, _ := parseUUIDString(uuid.New().String())
 
 	unit, err := h.service.CreateBusinessUnit(r.Context(), buID, tenantID, req.Code, req.Name)
@@ -137,5 +167,156 @@ func (h *BusinessUnitHandler) HandleCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := h.customFields.Save(r, tenantID, actorID, buID, req.CustomFields); err != nil {
+		response.DBError(w, err)
+		return
+	}
+
 	response.JSON(w, http.StatusCreated, unit)
 }
+
+// HandleDepartmentMatrix godoc
+// @Summary      Get the site department matrix
+// @Description  Lists every business unit and department pairing in the tenant, i.e. which departments operate at which sites, with the active headcount of each pairing.
+// @Tags         Organization
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200     {array}   logic.MatrixCell
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Failure      500     {object}  map[string]interface{} "Internal server error"
+// @Router       /api/v1/business-units/department-matrix [get]
+func (h *BusinessUnitHandler) HandleDepartmentMatrix(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	matrix, err := h.service.GetDepartmentMatrix(r.Context(), tenantID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to load department matrix")
+		return
+	}
+	response.JSON(w, http.StatusOK, matrix)
+}
+
+// HandleListDepartments godoc
+// @Summary      List a site's departments
+// @Description  Lists the departments that operate at a business unit together with the number of active employees placed in each at that site.
+// @Tags         Organization
+// @Produce      json
+// @Param        id      path      string  true  "Business Unit ID"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.SiteDepartment
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/business-units/{id}/departments [get]
+func (h *BusinessUnitHandler) HandleListDepartments(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	buID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid business unit ID format")
+		return
+	}
+
+	depts, err := h.service.ListSiteDepartments(r.Context(), tenantID, buID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Business unit not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, depts)
+}
+
+// HandleLinkDepartment godoc
+// @Summary      Add a department to a site
+// @Description  Records that a department operates at a business unit, allowing employees to be placed in that combination.
+// @Tags         Organization
+// @Produce      json
+// @Param        id            path      string  true  "Business Unit ID"
+// @Param        departmentId  path      string  true  "Department ID"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Link data"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Business unit or department not found"
+// @Failure      409     {object}  map[string]interface{} "Already linked"
+// @Router       /api/v1/business-units/{id}/departments/{departmentId} [put]
+func (h *BusinessUnitHandler) HandleLinkDepartment(w http.ResponseWriter, r *http.Request) {
+	tenantID, actorID, buID, deptID, ok := h.parseLinkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	link, err := h.service.LinkDepartment(r.Context(), tenantID, actorID, buID, deptID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Business unit or department not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, link)
+}
+
+// HandleUnlinkDepartment godoc
+// @Summary      Remove a department from a site
+// @Description  Removes a department from a business unit. Refused while employees are still placed in that department at that site.
+// @Tags         Organization
+// @Produce      json
+// @Param        id            path      string  true  "Business Unit ID"
+// @Param        departmentId  path      string  true  "Department ID"
+// @Security     BearerAuth
+// @Success      204     "No Content"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not linked"
+// @Failure      409     {object}  map[string]interface{} "Department still has employees at this site"
+// @Router       /api/v1/business-units/{id}/departments/{departmentId} [delete]
+func (h *BusinessUnitHandler) HandleUnlinkDepartment(w http.ResponseWriter, r *http.Request) {
+	tenantID, actorID, buID, deptID, ok := h.parseLinkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.UnlinkDepartment(r.Context(), tenantID, actorID, buID, deptID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Department is not linked to this business unit")
+	case errors.Is(err, logic.ErrDepartmentInUse):
+		response.Error(w, http.StatusConflict, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *BusinessUnitHandler) parseLinkRequest(w http.ResponseWriter, r *http.Request) (tenantID, actorID, buID, deptID pgtype.UUID, ok bool) {
+	if tenantID, ok = authHTTP.GetTenantIDFromContext(r.Context()); !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if actorID, ok = authHTTP.GetUserIDFromContext(r.Context()); !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var err error
+	if buID, err = parseUUIDString(chi.URLParam(r, "id")); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid business unit ID format")
+		return tenantID, actorID, buID, deptID, false
+	}
+	if deptID, err = parseUUIDString(chi.URLParam(r, "departmentId")); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+		return tenantID, actorID, buID, deptID, false
+	}
+	return tenantID, actorID, buID, deptID, true
+}
@@ -1,30 +1,46 @@
 package org
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
 	"github.com/INOVA/DML/internal/http/query"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
 	logic "github.com/INOVA/DML/internal/logic/org"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type DepartmentHandler struct {
-	service *logic.DepartmentService
+	service      *logic.DepartmentService
+	customFields *customFieldsHTTP.ValuesHandler
 }
 
-func NewDepartmentHandler(service *logic.DepartmentService) *DepartmentHandler {
-	return &DepartmentHandler{service: service}
+func NewDepartmentHandler(service *logic.DepartmentService, customFieldService *customFieldsLogic.CustomFieldService) *DepartmentHandler {
+	return &DepartmentHandler{
+		service:      service,
+		customFields: customFieldsHTTP.NewValuesHandler(customFieldService, customFieldsLogic.EntityDepartment, "Department not found"),
+	}
 }
 
 func (h *DepartmentHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/", h.HandleList)
 	r.Post("/", h.HandleCreate)
+	r.Get("/tree", h.HandleTree)
 	r.Get("/{id}", h.HandleGet)
+	r.Get("/{id}/children", h.HandleChildren)
+	r.Get("/{id}/ancestors", h.HandleAncestors)
+	r.Get("/{id}/headcount", h.HandleHeadcount)
+	h.customFields.RegisterRoutes(r)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}/parent", h.HandleMove)
 }
 
 // HandleList godoc
@@ -36,6 +52,7 @@ func (h *DepartmentHandler) RegisterRoutes(r chi.Router) {
 // @Param        page    query     int     false  "Page number" default(1)
 // @Param        size    query     int     false  "Page size" default(50)
 // @Param        search  query     string  false  "Search term (name/code)"
+// @Param        cf.key  query     string  false  "Only departments whose custom field <key> has this value; repeat for other keys"
 // @Security     BearerAuth
 // @Success      200     {object}  map[string]interface{} "Paginated department data"
 // @Failure      401     {object}  map[string]interface{} "Unauthorized"
@@ -50,7 +67,12 @@ func (h *DepartmentHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 
 	params := query.ParsePagination(r)
 
-	depts, total, err := h.service.ListDepartments(r.Context(), tenantID, params)
+	customFields, ok := h.customFields.Filter(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	depts, total, err := h.service.ListDepartments(r.Context(), tenantID, params, customFields)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to list departments")
 		return
@@ -97,6 +119,8 @@ type CreateDeptRequest struct {
 	Code               string  `json:"code" validate:"required"`
 	Name               string  `json:"name" validate:"required"`
 	ParentDepartmentID *string `json:"parentDepartmentId"`
+
+	CustomFields map[string]interface{} `json:"customFields"`
 }
 
 func (h *DepartmentHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
@@ -106,6 +130,12 @@ func (h *DepartmentHandler) HandleCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	var req CreateDeptRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "Invalid request payload")
@@ -117,6 +147,10 @@ func (h *DepartmentHandler) HandleCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.customFields.Validate(w, r, tenantID, pgtype.UUID{}, req.CustomFields) {
+		return
+	}
+
 	deptID, _ := parseUUIDString(uuid.New().String())
 
 	var pgParentID *pgtype.UUID
@@ -128,10 +162,206 @@ func (h *DepartmentHandler) HandleCreate(w http.ResponseWriter, r *http.Request)
 	}
 
 	dept, err := h.service.CreateDepartment(r.Context(), deptID, tenantID, pgParentID, req.Code, req.Name)
+	if errors.Is(err, logic.ErrParentNotFound) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		response.DBError(w, err)
 		return
 	}
 
+	if err := h.customFields.Save(r, tenantID, actorID, deptID, req.CustomFields); err != nil {
+		response.DBError(w, err)
+		return
+	}
+
 	response.JSON(w, http.StatusCreated, dept)
 }
+
+// HandleTree godoc
+// @Summary      Get the department tree
+// @Description  Returns the tenant's departments as a nested hierarchy. Each node carries the active headcount assigned directly to it and a totalHeadcount rolled up from all of its sub-departments.
+// @Tags         Organization
+// @Produce      json
+// @Param        includeInactive  query     bool  false  "Include inactive departments"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.DepartmentNode
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Failure      500     {object}  map[string]interface{} "Internal server error"
+// @Router       /api/v1/departments/tree [get]
+func (h *DepartmentHandler) HandleTree(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("includeInactive"))
+
+	tree, err := h.service.GetDepartmentTree(r.Context(), tenantID, includeInactive)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to build department tree")
+		return
+	}
+	response.JSON(w, http.StatusOK, tree)
+}
+
+// HandleChildren godoc
+// @Summary      List sub-departments
+// @Description  Lists the immediate sub-departments of a department with their rolled-up headcounts.
+// @Tags         Organization
+// @Produce      json
+// @Param        id      path      string  true  "Department ID"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.DepartmentNode
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/departments/{id}/children [get]
+func (h *DepartmentHandler) HandleChildren(w http.ResponseWriter, r *http.Request) {
+	h.handleNodes(w, r, h.service.ListChildren)
+}
+
+// HandleAncestors godoc
+// @Summary      List parent departments
+// @Description  Lists the departments above a department, nearest parent first, up to the root.
+// @Tags         Organization
+// @Produce      json
+// @Param        id      path      string  true  "Department ID"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.DepartmentNode
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/departments/{id}/ancestors [get]
+func (h *DepartmentHandler) HandleAncestors(w http.ResponseWriter, r *http.Request) {
+	h.handleNodes(w, r, h.service.GetAncestors)
+}
+
+func (h *DepartmentHandler) handleNodes(w http.ResponseWriter, r *http.Request, load func(ctx context.Context, tenantID, id pgtype.UUID) ([]*logic.DepartmentNode, error)) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	deptID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+		return
+	}
+
+	nodes, err := load(r.Context(), tenantID, deptID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Department not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, nodes)
+}
+
+// HandleHeadcount godoc
+// @Summary      Get department headcount
+// @Description  Returns the active headcount assigned directly to a department and the total including every sub-department.
+// @Tags         Organization
+// @Produce      json
+// @Param        id      path      string  true  "Department ID"
+// @Security     BearerAuth
+// @Success      200     {object}  logic.DepartmentNode
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/departments/{id}/headcount [get]
+func (h *DepartmentHandler) HandleHeadcount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	deptID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+		return
+	}
+
+	node, err := h.service.GetDepartmentNode(r.Context(), tenantID, deptID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Department not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, node)
+}
+
+type MoveDeptRequest struct {
+	ParentDepartmentID *string `json:"parentDepartmentId" validate:"omitempty,uuid"`
+}
+
+// HandleMove godoc
+// @Summary      Move a department
+// @Description  Moves a department, together with all of its sub-departments, under a new parent. A null parentDepartmentId makes it a top-level department. Moves that would place a department beneath itself are rejected.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string           true  "Department ID"
+// @Param        request  body      MoveDeptRequest  true  "New parent"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Department data"
+// @Failure      400     {object}  map[string]interface{} "Invalid parent"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Hierarchy cycle"
+// @Router       /api/v1/departments/{id}/parent [put]
+func (h *DepartmentHandler) HandleMove(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	deptID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+		return
+	}
+
+	var req MoveDeptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	var parentID pgtype.UUID
+	if req.ParentDepartmentID != nil {
+		parentID, _ = parseUUIDString(*req.ParentDepartmentID)
+	}
+
+	dept, err := h.service.MoveDepartment(r.Context(), tenantID, actorID, deptID, parentID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Department not found")
+	case errors.Is(err, logic.ErrDepartmentCycle):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrSelfParent), errors.Is(err, logic.ErrParentNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		response.JSON(w, http.StatusOK, dept)
+	}
+}
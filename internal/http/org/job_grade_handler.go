@@ -0,0 +1,212 @@
+package org
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/org"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type JobGradeHandler struct {
+	service *logic.JobGradeService
+}
+
+func NewJobGradeHandler(service *logic.JobGradeService) *JobGradeHandler {
+	return &JobGradeHandler{service: service}
+}
+
+func (h *JobGradeHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.HandleList)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/", h.HandleCreate)
+	r.Get("/{id}", h.HandleGet)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}", h.HandleUpdate)
+}
+
+// HandleList godoc
+// @Summary      List job grades
+// @Description  Retrieves a paginated list of job grades for the authenticated tenant, lowest level first.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        page    query     int     false  "Page number" default(1)
+// @Param        size    query     int     false  "Page size" default(50)
+// @Param        search  query     string  false  "Search term (name/code)"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Paginated job grade data"
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Failure      500     {object}  map[string]interface{} "Internal server error"
+// @Router       /api/v1/job-grades [get]
+func (h *JobGradeHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	grades, total, err := h.service.ListJobGrades(r.Context(), tenantID, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list job grades")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, grades, params.Page, params.Size, int(total))
+}
+
+// HandleGet godoc
+// @Summary      Get a job grade
+// @Description  Retrieves a job grade and its pay band by ID.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Job Grade ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Job grade data"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/job-grades/{id} [get]
+func (h *JobGradeHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	gradeID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job grade ID format")
+		return
+	}
+
+	grade, err := h.service.GetJobGrade(r.Context(), tenantID, gradeID)
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Job grade not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, grade)
+}
+
+type JobGradeRequest struct {
+	Code     string   `json:"code" validate:"required"`
+	Name     string   `json:"name" validate:"required"`
+	Level    int32    `json:"level" validate:"gte=0"`
+	Currency string   `json:"currency" validate:"omitempty,len=3"`
+	MinPay   *float64 `json:"minPay"`
+	MidPay   *float64 `json:"midPay"`
+	MaxPay   *float64 `json:"maxPay"`
+	IsActive *bool    `json:"isActive"`
+}
+
+func (req JobGradeRequest) band() logic.PayBand {
+	return logic.PayBand{
+		Currency: req.Currency,
+		Min:      req.MinPay,
+		Mid:      req.MidPay,
+		Max:      req.MaxPay,
+	}
+}
+
+// HandleCreate godoc
+// @Summary      Create a job grade
+// @Description  Creates a job grade with an optional pay band. Currency defaults to GBP and the band must satisfy minPay <= midPay <= maxPay.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        request  body      JobGradeRequest  true  "Job grade"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Job grade data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/job-grades [post]
+func (h *JobGradeHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req JobGradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	gradeID, _ := parseUUIDString(uuid.New().String())
+
+	grade, err := h.service.CreateJobGrade(r.Context(), gradeID, tenantID, req.Code, req.Name, req.Level, req.band())
+	if errors.Is(err, logic.ErrInvalidPayBand) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, grade)
+}
+
+// HandleUpdate godoc
+// @Summary      Update a job grade
+// @Description  Replaces a job grade's details and pay band. isActive defaults to true when omitted.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string           true  "Job Grade ID"
+// @Param        request  body      JobGradeRequest  true  "Job grade"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Job grade data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/job-grades/{id} [put]
+func (h *JobGradeHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	gradeID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job grade ID format")
+		return
+	}
+
+	var req JobGradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	isActive := req.IsActive == nil || *req.IsActive
+
+	grade, err := h.service.UpdateJobGrade(r.Context(), tenantID, gradeID, req.Code, req.Name, req.Level, req.band(), isActive)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Job grade not found")
+	case errors.Is(err, logic.ErrInvalidPayBand):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		response.JSON(w, http.StatusOK, grade)
+	}
+}
@@ -2,28 +2,44 @@ package org
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
 	"github.com/INOVA/DML/internal/http/query"
+	competencyLogic "github.com/INOVA/DML/internal/logic/competency"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
 	logic "github.com/INOVA/DML/internal/logic/org"
 	"github.com/INOVA/DML/internal/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type JobTitleHandler struct {
-	service *logic.JobTitleService
+	service       *logic.JobTitleService
+	competencySvc *competencyLogic.CompetencyService
+	customFields  *customFieldsHTTP.ValuesHandler
 }
 
-func NewJobTitleHandler(service *logic.JobTitleService) *JobTitleHandler {
-	return &JobTitleHandler{service: service}
+func NewJobTitleHandler(service *logic.JobTitleService, competencySvc *competencyLogic.CompetencyService, customFieldService *customFieldsLogic.CustomFieldService) *JobTitleHandler {
+	return &JobTitleHandler{
+		service:       service,
+		competencySvc: competencySvc,
+		customFields:  customFieldsHTTP.NewValuesHandler(customFieldService, customFieldsLogic.EntityJobTitle, "Job title not found"),
+	}
 }
 
 func (h *JobTitleHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/", h.HandleList)
 	r.Post("/", h.HandleCreate)
 	r.Get("/{id}", h.HandleGet)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}", h.HandleUpdate)
+	r.Get("/{id}/requirements", h.HandleListRequirements)
+	r.With(authHTTP.RequireRole("ADMIN")).Put("/{id}/requirements", h.HandleSetRequirements)
+	h.customFields.RegisterRoutes(r)
 }
 
 // HandleList godoc
@@ -35,6 +51,7 @@ func (h *JobTitleHandler) RegisterRoutes(r chi.Router) {
 // @Param        page    query     int     false  "Page number" default(1)
 // @Param        size    query     int     false  "Page size" default(50)
 // @Param        search  query     string  false  "Search term (name/code)"
+// @Param        cf.key  query     string  false  "Only job titles whose custom field <key> has this value; repeat for other keys"
 // @Security     BearerAuth
 // @Success      200     {object}  map[string]interface{} "Paginated job title data"
 // @Failure      401     {object}  map[string]interface{} "Unauthorized"
@@ -49,7 +66,12 @@ func (h *JobTitleHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 
 	params := query.ParsePagination(r)
 
-	jobs, total, err := h.service.ListJobTitles(r.Context(), tenantID, params)
+	customFields, ok := h.customFields.Filter(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	jobs, total, err := h.service.ListJobTitles(r.Context(), tenantID, params, customFields)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to list job titles")
 		return
@@ -93,11 +115,29 @@ func (h *JobTitleHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
 }
 
 type CreateJobTitleRequest struct {
-	Code  string `json:"code" validate:"required"`
-	Name  string `json:"name" validate:"required"`
-	Grade string `json:"grade"`
+	Code                string  `json:"code" validate:"required"`
+	Name                string  `json:"name" validate:"required"`
+	Grade               string  `json:"grade"`
+	GradeID             *string `json:"gradeId" validate:"omitempty,uuid"`
+	DefaultDepartmentID *string `json:"defaultDepartmentId" validate:"omitempty,uuid"`
+
+	// CustomFields are checked against the job title custom field definitions. On update
+	// they are merged into the stored values and may be omitted to leave them unchanged.
+	CustomFields map[string]interface{} `json:"customFields"`
 }
 
+// HandleCreate godoc
+// @Summary      Create a job title
+// @Description  Creates a job title, optionally placed on a job grade and with a default department for new starters. The legacy grade field is accepted as a grade code when gradeId is not given.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateJobTitleRequest  true  "Job title"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Job title data"
+// @Failure      400     {object}  map[string]interface{} "Validation error or unknown grade/department"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/job-titles [post]
 func (h *JobTitleHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
 	if !ok {
@@ -105,6 +145,12 @@ func (h *JobTitleHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	var req CreateJobTitleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "Invalid request payload")
@@ -116,13 +162,243 @@ func (h *JobTitleHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	gradeID, deptID, err := h.resolveReferences(r, tenantID, req.Grade, req.GradeID, req.DefaultDepartmentID)
+	if err != nil {
+		writeJobTitleError(w, err)
+		return
+	}
+
+	if !h.customFields.Validate(w, r, tenantID, pgtype.UUID{}, req.CustomFields) {
+		return
+	}
+
 	jobID, _ := parseUUIDString(uuid.New().String())
 
-	job, err := h.service.CreateJobTitle(r.Context(), jobID, tenantID, req.Code, req.Name, req.Grade)
+	job, err := h.service.CreateJobTitle(r.Context(), jobID, tenantID, req.Code, req.Name, gradeID, deptID)
 	if err != nil {
+		writeJobTitleError(w, err)
+		return
+	}
+
+	if err := h.customFields.Save(r, tenantID, actorID, jobID, req.CustomFields); err != nil {
 		response.DBError(w, err)
 		return
 	}
 
 	response.JSON(w, http.StatusCreated, job)
 }
+
+type UpdateJobTitleRequest struct {
+	CreateJobTitleRequest
+	IsActive *bool `json:"isActive"`
+}
+
+// HandleUpdate godoc
+// @Summary      Update a job title
+// @Description  Replaces a job title's code, name, grade and default department. isActive defaults to true when omitted.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                 true  "Job Title ID"
+// @Param        request  body      UpdateJobTitleRequest  true  "Job title"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Job title data"
+// @Failure      400     {object}  map[string]interface{} "Validation error or unknown grade/department"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/job-titles/{id} [put]
+func (h *JobTitleHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	jobID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job title ID format")
+		return
+	}
+
+	var req UpdateJobTitleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	gradeID, deptID, err := h.resolveReferences(r, tenantID, req.Grade, req.GradeID, req.DefaultDepartmentID)
+	if err != nil {
+		writeJobTitleError(w, err)
+		return
+	}
+
+	if !h.customFields.Validate(w, r, tenantID, jobID, req.CustomFields) {
+		return
+	}
+
+	isActive := req.IsActive == nil || *req.IsActive
+
+	job, err := h.service.UpdateJobTitle(r.Context(), tenantID, jobID, req.Code, req.Name, gradeID, deptID, isActive)
+	if err != nil {
+		writeJobTitleError(w, err)
+		return
+	}
+
+	if err := h.customFields.Save(r, tenantID, actorID, jobID, req.CustomFields); err != nil {
+		response.DBError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, job)
+}
+
+// HandleListRequirements godoc
+// @Summary      List a job title's competency requirements
+// @Description  Returns the competencies an employee holding this job title is expected to have.
+// @Tags         Organization
+// @Produce      json
+// @Param        id   path      string  true  "Job Title ID"
+// @Security     BearerAuth
+// @Success      200     {array}   competencyLogic.Requirement
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/job-titles/{id}/requirements [get]
+func (h *JobTitleHandler) HandleListRequirements(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	jobID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job title ID format")
+		return
+	}
+
+	reqs, err := h.competencySvc.ListJobTitleRequirements(r.Context(), tenantID, jobID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Job title not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, reqs)
+}
+
+type RequirementItem struct {
+	CompetencyID string `json:"competencyId" validate:"required,uuid"`
+	IsMandatory  *bool  `json:"isMandatory"`
+}
+
+type SetRequirementsRequest struct {
+	Requirements []RequirementItem `json:"requirements" validate:"dive"`
+}
+
+// HandleSetRequirements godoc
+// @Summary      Set a job title's competency requirements
+// @Description  Replaces the full list of competencies required by a job title. isMandatory defaults to true; optional requirements are reported in the matrix but do not affect compliance.
+// @Tags         Organization
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Job Title ID"
+// @Param        request  body      SetRequirementsRequest  true  "Requirements"
+// @Security     BearerAuth
+// @Success      200     {array}   competencyLogic.Requirement
+// @Failure      400     {object}  map[string]interface{} "Validation error or unknown competency"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/job-titles/{id}/requirements [put]
+func (h *JobTitleHandler) HandleSetRequirements(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	jobID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job title ID format")
+		return
+	}
+
+	var req SetRequirementsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	inputs := make([]competencyLogic.RequirementInput, 0, len(req.Requirements))
+	for _, item := range req.Requirements {
+		compID, _ := parseUUIDString(item.CompetencyID)
+		inputs = append(inputs, competencyLogic.RequirementInput{
+			CompetencyID: compID,
+			IsMandatory:  item.IsMandatory == nil || *item.IsMandatory,
+		})
+	}
+
+	reqs, err := h.competencySvc.SetJobTitleRequirements(r.Context(), tenantID, actorID, jobID, inputs)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Job title not found")
+	case errors.Is(err, competencyLogic.ErrCompetencyNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		response.DBError(w, err)
+	default:
+		response.JSON(w, http.StatusOK, reqs)
+	}
+}
+
+// resolveReferences parses the optional grade and default department, falling back to the
+// legacy grade code when no grade ID is given
+func (h *JobTitleHandler) resolveReferences(r *http.Request, tenantID pgtype.UUID, gradeCode string, gradeIDStr, deptIDStr *string) (pgtype.UUID, pgtype.UUID, error) {
+	var gradeID, deptID pgtype.UUID
+	if gradeIDStr != nil {
+		gradeID, _ = parseUUIDString(*gradeIDStr)
+	} else if gradeCode != "" {
+		id, err := h.service.ResolveGradeCode(r.Context(), tenantID, gradeCode)
+		if err != nil {
+			return gradeID, deptID, err
+		}
+		gradeID = id
+	}
+	if deptIDStr != nil {
+		deptID, _ = parseUUIDString(*deptIDStr)
+	}
+	return gradeID, deptID, nil
+}
+
+func writeJobTitleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Job title not found")
+	case errors.Is(err, logic.ErrGradeNotFound), errors.Is(err, logic.ErrDepartmentNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
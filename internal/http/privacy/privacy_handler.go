@@ -0,0 +1,164 @@
+package privacy
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/logic/iam"
+	logic "github.com/INOVA/DML/internal/logic/privacy"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PrivacyHandler serves subject access exports and erasures of employees
+type PrivacyHandler struct {
+	service *logic.PrivacyService
+}
+
+func NewPrivacyHandler(service *logic.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// RegisterRoutes mounts the data subject request endpoints under /employees
+func (h *PrivacyHandler) RegisterRoutes(r chi.Router) {
+	r.With(authHTTP.RequirePermission(iam.PermPersonalDataExport), authHTTP.DenyImpersonation).Get("/{id}/subject-access-export", h.HandleExport)
+	r.With(authHTTP.RequirePermission(iam.PermPersonalDataErase), authHTTP.DenyImpersonation).Post("/{id}/erasure", h.HandleErase)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writePrivacyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Employee not found")
+	case errors.Is(err, logic.ErrConfirmationMismatch):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrSelfErasure):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrAlreadyErased):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// HandleExport godoc
+// @Summary      Export everything held about an employee
+// @Description  Compiles a zip archive for a subject access request: the employee record with custom fields, decrypted personal details, the user account, role grants, competencies, training records with their evidence files, change requests, and the audit entries about the employee or made by them. Requires the personal-data:export permission, granted by HR_ADMIN, and is refused while impersonating. The export is recorded in the audit log as EXPORT on the employee.
+// @Tags         Employees
+// @Produce      application/zip
+// @Param        id   path      string  true  "Employee UUID"
+// @Security     BearerAuth
+// @Success      200  {file}    file  "Zip archive"
+// @Failure      400  {object}  map[string]interface{} "Invalid ID format"
+// @Failure      403  {object}  map[string]interface{} "Missing permission"
+// @Failure      404  {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/employees/{id}/subject-access-export [get]
+func (h *PrivacyHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	data, err := h.service.CollectSubjectData(r.Context(), tenantID, actorID, empID)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+data.FileName()+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure midway can only truncate the archive
+	if err := h.service.WriteArchive(r.Context(), w, data); err != nil {
+		log.Printf("subject access export of employee %s failed after streaming started: %v", uuid.UUID(empID.Bytes), err)
+	}
+}
+
+type ErasureRequest struct {
+	// EmployeeNo repeats the employee number to confirm the erasure
+	EmployeeNo string `json:"employeeNo" validate:"required"`
+}
+
+// HandleErase godoc
+// @Summary      Erase an employee
+// @Description  Pseudonymises the employee under the right to erasure and cannot be undone. The employee and user rows are kept, with placeholder names and email, so the records and audit entries referencing them stay intact. Personal details, custom field values, credentials, role grants, notifications and training evidence are deleted, and free text in audit entries about the employee is replaced. Approval tasks and approver notifications for the employee's change requests are re-titled with the placeholder name, open ones cancelled, and the emails about them deleted. Afterwards personal details, custom fields and SCIM updates of the employee are refused with 409. employeeNo must repeat the employee's number. Requires the personal-data:erase permission and is refused while impersonating.
+// @Tags         Employees
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string          true  "Employee UUID"
+// @Param        request  body      ErasureRequest  true  "Confirmation"
+// @Security     BearerAuth
+// @Success      200      {object}  logic.Erasure
+// @Failure      400      {object}  map[string]interface{} "Confirmation does not match"
+// @Failure      403      {object}  map[string]interface{} "Missing permission or own record"
+// @Failure      404      {object}  map[string]interface{} "Employee not found"
+// @Failure      409      {object}  map[string]interface{} "Already erased"
+// @Router       /api/v1/employees/{id}/erasure [post]
+func (h *PrivacyHandler) HandleErase(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	var req ErasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	erasure, err := h.service.EraseEmployee(r.Context(), tenantID, actorID, empID, req.EmployeeNo)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, erasure)
+}
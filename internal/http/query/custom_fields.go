@@ -0,0 +1,25 @@
+package query
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CustomFieldPrefix marks query parameters that filter on custom field values
+const CustomFieldPrefix = "cf."
+
+// ParseCustomFields collects ?cf.<key>=<value> parameters keyed by field key
+func ParseCustomFields(r *http.Request) map[string]string {
+	var out map[string]string
+	for name, values := range r.URL.Query() {
+		key := strings.TrimPrefix(name, CustomFieldPrefix)
+		if key == name || key == "" || len(values) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[key] = values[0]
+	}
+	return out
+}
@@ -0,0 +1,509 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/scim"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// contentType is the SCIM media type (RFC 7644 section 3.1)
+const contentType = "application/scim+json"
+
+type contextKey string
+
+// issuedByKey holds the user who issued the request's SCIM token
+const issuedByKey contextKey = "scimIssuedBy"
+
+type ScimHandler struct {
+	service *logic.ScimService
+}
+
+func NewScimHandler(service *logic.ScimService) *ScimHandler {
+	return &ScimHandler{service: service}
+}
+
+// RegisterRoutes mounts the SCIM service provider endpoints. They authenticate with a
+// tenant SCIM token rather than a user session.
+func (h *ScimHandler) RegisterRoutes(r chi.Router) {
+	r.Use(h.authenticate)
+
+	r.Get("/ServiceProviderConfig", h.HandleServiceProviderConfig)
+	r.Get("/ResourceTypes", h.HandleResourceTypes)
+
+	r.Get("/Users", h.HandleListUsers)
+	r.Post("/Users", h.HandleCreateUser)
+	r.Get("/Users/{id}", h.HandleGetUser)
+	r.Put("/Users/{id}", h.HandleReplaceUser)
+	r.Patch("/Users/{id}", h.HandlePatchUser)
+	r.Delete("/Users/{id}", h.HandleDeleteUser)
+
+	r.Get("/Groups", h.HandleListGroups)
+	r.Post("/Groups", h.HandleCreateGroup)
+	r.Get("/Groups/{id}", h.HandleGetGroup)
+	r.Put("/Groups/{id}", h.HandleReplaceGroup)
+	r.Patch("/Groups/{id}", h.HandlePatchGroup)
+	r.Delete("/Groups/{id}", h.HandleDeleteGroup)
+}
+
+func (h *ScimHandler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			writeError(w, &logic.Error{Status: http.StatusUnauthorized, Detail: "Bearer token required"})
+			return
+		}
+
+		tenantID, issuedBy, err := h.service.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, logic.ErrInvalidToken) {
+				writeError(w, &logic.Error{Status: http.StatusUnauthorized, Detail: "Invalid token"})
+				return
+			}
+			writeError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), authHTTP.TenantIDKey, tenantID)
+		ctx = context.WithValue(ctx, issuedByKey, issuedBy)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func tenantFrom(r *http.Request) pgtype.UUID {
+	tenantID, _ := authHTTP.GetTenantIDFromContext(r.Context())
+	return tenantID
+}
+
+func issuedByFrom(r *http.Request) pgtype.UUID {
+	issuedBy, _ := r.Context().Value(issuedByKey).(pgtype.UUID)
+	return issuedBy
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("scim failed writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var scimErr *logic.Error
+	if !errors.As(err, &scimErr) {
+		log.Printf("scim request failed: %v", err)
+		scimErr = &logic.Error{Status: http.StatusInternalServerError, Detail: "Internal server error"}
+	}
+	body := map[string]interface{}{
+		"schemas": []string{logic.ErrorSchema},
+		"status":  strconv.Itoa(scimErr.Status),
+		"detail":  scimErr.Detail,
+	}
+	if scimErr.ScimType != "" {
+		body["scimType"] = scimErr.ScimType
+	}
+	writeJSON(w, scimErr.Status, body)
+}
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &logic.Error{Status: http.StatusBadRequest, ScimType: logic.ScimTypeInvalidSyntax, Detail: "Invalid request body"}
+	}
+	return nil
+}
+
+// baseURL is the absolute URL of the SCIM root, used for resource locations
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + "/scim/v2"
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func listParams(r *http.Request) logic.ListParams {
+	params := logic.ListParams{
+		Filter:     r.URL.Query().Get("filter"),
+		StartIndex: 1,
+		Count:      logic.DefaultCount,
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("startIndex")); err == nil {
+		params.StartIndex = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil {
+		params.Count = v
+	}
+	return params
+}
+
+// render sets the resource location and applies the attributes and excludedAttributes
+// query parameters before writing a resource
+func render(w http.ResponseWriter, r *http.Request, status int, resource interface{}, endpoint string) {
+	m, err := toMap(resource)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	location := locate(r, m, endpoint)
+	if status == http.StatusCreated {
+		w.Header().Set("Location", location)
+	}
+	writeJSON(w, status, project(r, m))
+}
+
+func renderList(w http.ResponseWriter, r *http.Request, list logic.ListResponse, endpoint string) {
+	for i, res := range list.Resources {
+		if m, ok := res.(map[string]interface{}); ok {
+			locate(r, m, endpoint)
+			list.Resources[i] = project(r, m)
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func locate(r *http.Request, m map[string]interface{}, endpoint string) string {
+	id, _ := m["id"].(string)
+	location := baseURL(r) + endpoint + "/" + id
+	if meta, ok := m["meta"].(map[string]interface{}); ok {
+		meta["location"] = location
+	}
+	return location
+}
+
+func project(r *http.Request, m map[string]interface{}) map[string]interface{} {
+	return logic.Project(m, splitList(r.URL.Query().Get("attributes")), splitList(r.URL.Query().Get("excludedAttributes")))
+}
+
+func toMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// @Summary SCIM Service Provider Configuration
+// @Description Describes the SCIM features this server supports.
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (h *ScimHandler) HandleServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": logic.MaxCount},
+		"changePassword": map[string]bool{"supported": true},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "A tenant SCIM token sent as Authorization: Bearer <token>",
+			"primary":     true,
+		}},
+	})
+}
+
+// @Summary SCIM Resource Types
+// @Description Lists the resource types this server provisions.
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/ResourceTypes [get]
+func (h *ScimHandler) HandleResourceTypes(w http.ResponseWriter, r *http.Request) {
+	base := baseURL(r)
+	types := []interface{}{
+		map[string]interface{}{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   logic.UserSchema,
+			"schemaExtensions": []map[string]interface{}{
+				{"schema": logic.EnterpriseUserSchema, "required": false},
+			},
+			"meta": map[string]string{"resourceType": "ResourceType", "location": base + "/ResourceTypes/User"},
+		},
+		map[string]interface{}{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   logic.GroupSchema,
+			"meta":     map[string]string{"resourceType": "ResourceType", "location": base + "/ResourceTypes/Group"},
+		},
+	}
+	writeJSON(w, http.StatusOK, logic.ListResponse{
+		Schemas:      []string{logic.ListResponseSchema},
+		TotalResults: len(types),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+}
+
+// @Summary List SCIM Users
+// @Description Lists users with their employee records. Supports filter, startIndex, count, attributes and excludedAttributes.
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param filter query string false "SCIM filter, e.g. userName eq \"jane@example.com\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Users [get]
+func (h *ScimHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.ListUsers(r.Context(), tenantFrom(r), listParams(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	renderList(w, r, list, "/Users")
+}
+
+// @Summary Create a SCIM User
+// @Description Provisions an employee and its user account together. userName is the login email; the enterprise extension's employeeNumber and manager map onto the employee record.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "userName, externalId or employeeNumber already in use"
+// @Router /scim/v2/Users [post]
+func (h *ScimHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var in logic.User
+	if err := decode(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	user, err := h.service.CreateUser(r.Context(), tenantFrom(r), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusCreated, user, "/Users")
+}
+
+// @Summary Get a SCIM User
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Users/{id} [get]
+func (h *ScimHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.GetUser(r.Context(), tenantFrom(r), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, user, "/Users")
+}
+
+// @Summary Replace a SCIM User
+// @Description Overwrites the user and its employee record. employeeNumber and manager are kept when the enterprise extension is omitted.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "User has been erased"
+// @Router /scim/v2/Users/{id} [put]
+func (h *ScimHandler) HandleReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var in logic.User
+	if err := decode(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	user, err := h.service.ReplaceUser(r.Context(), tenantFrom(r), chi.URLParam(r, "id"), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, user, "/Users")
+}
+
+// @Summary Patch a SCIM User
+// @Description Applies add, replace and remove operations, including value filters such as emails[type eq "work"].value.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "User has been erased"
+// @Router /scim/v2/Users/{id} [patch]
+func (h *ScimHandler) HandlePatchUser(w http.ResponseWriter, r *http.Request) {
+	var req logic.PatchRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	user, err := h.service.PatchUser(r.Context(), tenantFrom(r), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, user, "/Users")
+}
+
+// @Summary Delete a SCIM User
+// @Description Deprovisions the user: the account is deactivated and its roles revoked. The employee record is kept, so the user stays readable with active false.
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path string true "User UUID"
+// @Success 204
+// @Router /scim/v2/Users/{id} [delete]
+func (h *ScimHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteUser(r.Context(), tenantFrom(r), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List SCIM Groups
+// @Description Lists RBAC roles as groups. Supports filter, startIndex, count, attributes and excludedAttributes.
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param filter query string false "SCIM filter, e.g. displayName eq \"QA Managers\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Groups [get]
+func (h *ScimHandler) HandleListGroups(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.ListGroups(r.Context(), tenantFrom(r), listParams(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	renderList(w, r, list, "/Groups")
+}
+
+// @Summary Create a SCIM Group
+// @Description Creates a role named after the group, with a code derived from displayName, and grants it tenant-wide to the members. Names mapping onto a role that grants permissions, such as "HR Admin", are refused. Members are only added to roles granting permissions the token's issuer holds.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} map[string]interface{}
+// @Router /scim/v2/Groups [post]
+func (h *ScimHandler) HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var in logic.Group
+	if err := decode(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	group, err := h.service.CreateGroup(r.Context(), tenantFrom(r), issuedByFrom(r), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusCreated, group, "/Groups")
+}
+
+// @Summary Get a SCIM Group
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [get]
+func (h *ScimHandler) HandleGetGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := h.service.GetGroup(r.Context(), tenantFrom(r), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, group, "/Groups")
+}
+
+// @Summary Replace a SCIM Group
+// @Description Renames the role and sets its members to exactly those given. Adding or removing members returns 403 if the role grants a permission the token's issuer does not hold. Removing the last active member of the ADMIN role is refused.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [put]
+func (h *ScimHandler) HandleReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	var in logic.Group
+	if err := decode(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	group, err := h.service.ReplaceGroup(r.Context(), tenantFrom(r), issuedByFrom(r), chi.URLParam(r, "id"), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, group, "/Groups")
+}
+
+// @Summary Patch a SCIM Group
+// @Description Applies add, replace and remove operations, e.g. adding members or removing members[value eq "<user id>"]. Member changes follow the same rules as a replace. Removing the last active member of the ADMIN role is refused.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [patch]
+func (h *ScimHandler) HandlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	var req logic.PatchRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	group, err := h.service.PatchGroup(r.Context(), tenantFrom(r), issuedByFrom(r), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	render(w, r, http.StatusOK, group, "/Groups")
+}
+
+// @Summary Delete a SCIM Group
+// @Description Revokes the role from all its holders and deletes it. Roles that grant permissions, such as ADMIN and HR_ADMIN, cannot be deleted, and other roles only while the token's issuer holds every permission they grant.
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path string true "Role UUID"
+// @Success 204
+// @Router /scim/v2/Groups/{id} [delete]
+func (h *ScimHandler) HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteGroup(r.Context(), tenantFrom(r), issuedByFrom(r), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
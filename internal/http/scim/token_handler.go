@@ -0,0 +1,140 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RegisterTokenRoutes mounts the admin endpoints that manage the tenant's SCIM tokens
+func (h *ScimHandler) RegisterTokenRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleListTokens)
+	r.With(admin).Post("/", h.HandleIssueToken)
+	r.With(admin).Delete("/{id}", h.HandleRevokeToken)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+type TokenRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// @Summary List SCIM Tokens
+// @Description Lists the tenant's SCIM tokens, including revoked ones. Token values are not included.
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/scim-tokens [get]
+func (h *ScimHandler) HandleListTokens(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := h.service.ListTokens(r.Context(), tenantID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list SCIM tokens")
+		return
+	}
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// @Summary Issue a SCIM Token
+// @Description Issues a bearer token for an identity provider to call /scim/v2 with. The token is only shown in this response.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TokenRequest true "Token Payload"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/scim-tokens [post]
+func (h *ScimHandler) HandleIssueToken(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	tokenID, _ := parseUUIDString(uuid.New().String())
+
+	token, err := h.service.IssueToken(r.Context(), tokenID, tenantID, actorID, req.Name)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, token)
+}
+
+// @Summary Revoke a SCIM Token
+// @Description Stops a token from authenticating. It stays listed with its revocation time.
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path string true "Token UUID"
+// @Success 204
+// @Router /api/v1/scim-tokens/{id} [delete]
+func (h *ScimHandler) HandleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokenID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid token ID format")
+		return
+	}
+
+	if err := h.service.RevokeToken(r.Context(), tenantID, actorID, tokenID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Token not found or already revoked")
+			return
+		}
+		response.DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
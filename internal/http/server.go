@@ -12,26 +12,60 @@ import (
 
 	"github.com/INOVA/DML/internal/config"
 	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/envelope"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 
 	// Swagger imports
 	_ "github.com/INOVA/DML/docs" // Import generated docs
 
+	apikeysHTTP "github.com/INOVA/DML/internal/http/apikeys"
 	auditHTTP "github.com/INOVA/DML/internal/http/audit"
 	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	capaHTTP "github.com/INOVA/DML/internal/http/capa"
+	competencyHTTP "github.com/INOVA/DML/internal/http/competency"
+	customFieldsHTTP "github.com/INOVA/DML/internal/http/customfields"
+	eventsHTTP "github.com/INOVA/DML/internal/http/events"
+	exportHTTP "github.com/INOVA/DML/internal/http/export"
 	hrHTTP "github.com/INOVA/DML/internal/http/hr"
 	iamHTTP "github.com/INOVA/DML/internal/http/iam"
+	internalAuditHTTP "github.com/INOVA/DML/internal/http/internalaudit"
+	jobsHTTP "github.com/INOVA/DML/internal/http/jobs"
+	mfaHTTP "github.com/INOVA/DML/internal/http/mfa"
+	notifyHTTP "github.com/INOVA/DML/internal/http/notify"
 	orgHTTP "github.com/INOVA/DML/internal/http/org"
+	privacyHTTP "github.com/INOVA/DML/internal/http/privacy"
+	scimHTTP "github.com/INOVA/DML/internal/http/scim"
+	ssoHTTP "github.com/INOVA/DML/internal/http/sso"
+	tasksHTTP "github.com/INOVA/DML/internal/http/tasks"
 	tenancyHTTP "github.com/INOVA/DML/internal/http/tenancy"
+	trainingHTTP "github.com/INOVA/DML/internal/http/training"
+	webhooksHTTP "github.com/INOVA/DML/internal/http/webhooks"
 
+	apikeysLogic "github.com/INOVA/DML/internal/logic/apikeys"
 	auditLogic "github.com/INOVA/DML/internal/logic/audit"
 	authLogic "github.com/INOVA/DML/internal/logic/auth"
+	capaLogic "github.com/INOVA/DML/internal/logic/capa"
+	competencyLogic "github.com/INOVA/DML/internal/logic/competency"
+	customFieldsLogic "github.com/INOVA/DML/internal/logic/customfields"
+	eventsLogic "github.com/INOVA/DML/internal/logic/events"
+	exportLogic "github.com/INOVA/DML/internal/logic/export"
 	hrLogic "github.com/INOVA/DML/internal/logic/hr"
 	iamLogic "github.com/INOVA/DML/internal/logic/iam"
+	internalAuditLogic "github.com/INOVA/DML/internal/logic/internalaudit"
+	jobsLogic "github.com/INOVA/DML/internal/logic/jobs"
+	mfaLogic "github.com/INOVA/DML/internal/logic/mfa"
+	notifyLogic "github.com/INOVA/DML/internal/logic/notify"
 	orgLogic "github.com/INOVA/DML/internal/logic/org"
+	privacyLogic "github.com/INOVA/DML/internal/logic/privacy"
+	scimLogic "github.com/INOVA/DML/internal/logic/scim"
+	ssoLogic "github.com/INOVA/DML/internal/logic/sso"
+	tasksLogic "github.com/INOVA/DML/internal/logic/tasks"
 	tenancyLogic "github.com/INOVA/DML/internal/logic/tenancy"
+	trainingLogic "github.com/INOVA/DML/internal/logic/training"
+	webhooksLogic "github.com/INOVA/DML/internal/logic/webhooks"
 
 	"github.com/INOVA/DML/internal/response"
+	"github.com/INOVA/DML/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/cors"
@@ -42,6 +76,7 @@ type Server struct {
 	router *chi.Mux
 	db     *db.DB
 	config *config.Config
+	events *eventsLogic.Broker
 }
 
 // NewServer creates a new API server
@@ -64,8 +99,8 @@ func (s *Server) setupRoutes() {
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.RedirectSlashes)
 
-	// Set a timeout value on the request context
-	s.router.Use(middleware.Timeout(60 * time.Second))
+	// Set a timeout value on the request context, except for long-lived event streams
+	s.router.Use(timeoutExcept(60*time.Second, "/api/v1/events/stream"))
 
 	// CORS Setup
 	c := cors.New(cors.Options{
@@ -87,40 +122,122 @@ func (s *Server) setupRoutes() {
 
 	// Initialize Services
 	auditSvc := auditLogic.NewAuditService(s.db)
-	authSvc := authLogic.NewAuthService(s.db, s.config.JWTSecret)
+	keys, err := authLogic.LoadKeySet(authLogic.KeyOptions{
+		Dir:          s.config.JWTKeysDir,
+		SigningKeyID: s.config.JWTSigningKeyID,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	dataKeys, err := envelope.LoadKeyring(envelope.Options{
+		Dir:         s.config.DataKeysDir,
+		ActiveKeyID: s.config.DataKeyID,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load data encryption keys: %v", err)
+	}
+	authSvc := authLogic.NewAuthService(s.db, keys, s.config.JWTIssuer, s.config.JWTAudience)
 	tenantSvc := tenancyLogic.NewService(s.db)
-	buSvc := orgLogic.NewBusinessUnitService(s.db)
-	deptSvc := orgLogic.NewDepartmentService(s.db)
+	buSvc := orgLogic.NewBusinessUnitService(s.db, auditSvc)
+	deptSvc := orgLogic.NewDepartmentService(s.db, auditSvc)
 	jobSvc := orgLogic.NewJobTitleService(s.db)
-	empSvc := hrLogic.NewEmployeeService(s.db, auditSvc)
+	gradeSvc := orgLogic.NewJobGradeService(s.db)
+	competencySvc := competencyLogic.NewCompetencyService(s.db, auditSvc)
+	customFieldSvc := customFieldsLogic.NewCustomFieldService(s.db, auditSvc)
+	store := storage.NewLocalStore(s.config.StorageDir)
+	jobRunner := jobsLogic.NewRunner(s.db, store, 2)
+	empSvc := hrLogic.NewEmployeeService(s.db, auditSvc, customFieldSvc)
+	importSvc := hrLogic.NewEmployeeImportService(s.db, auditSvc, customFieldSvc, jobRunner)
 	onboardSvc := hrLogic.NewOnboardingService(s.db, auditSvc)
-	userSvc := iamLogic.NewUserService(s.db, auditSvc)
-	userRoleSvc := iamLogic.NewUserRoleService(s.db)
+	userSvc := iamLogic.NewUserService(s.db, authSvc, auditSvc)
+	userRoleSvc := iamLogic.NewUserRoleService(s.db, auditSvc)
 	roleSvc := iamLogic.NewRoleService(s.db, auditSvc)
+	exportSvc := exportLogic.NewExportService(s.db, jobRunner, customFieldSvc)
+	trainingSvc := trainingLogic.NewTrainingService(s.db, store, competencySvc, auditSvc)
+	mailTransport, err := notifyLogic.NewTransport(notifyLogic.TransportOptions{
+		Kind:     s.config.MailTransport,
+		From:     s.config.MailFrom,
+		SMTPHost: s.config.SMTPHost,
+		SMTPPort: s.config.SMTPPort,
+		Username: s.config.SMTPUsername,
+		Password: s.config.SMTPPassword,
+		Dir:      s.config.MailDir,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure mail transport: %v", err)
+	}
+	mailer := notifyLogic.NewMailer(s.db, mailTransport, auditSvc, 10*time.Second)
+	notificationSvc := tasksLogic.NewNotificationService(s.db, mailer)
+	taskSvc := tasksLogic.NewTaskService(s.db, notificationSvc, auditSvc)
+	ncrSvc := capaLogic.NewNCRService(s.db, taskSvc, auditSvc)
+	changeRequestSvc := hrLogic.NewChangeRequestService(s.db, taskSvc, auditSvc)
+	personalDetailsSvc := hrLogic.NewPersonalDetailsService(s.db, dataKeys, auditSvc)
+	privacySvc := privacyLogic.NewPrivacyService(s.db, store, personalDetailsSvc, auditSvc)
+	internalAuditSvc := internalAuditLogic.NewInternalAuditService(s.db, ncrSvc, auditSvc)
+	webhookSvc := webhooksLogic.NewWebhookService(s.db, auditSvc, 5*time.Second)
+	scimSvc := scimLogic.NewScimService(s.db, auditSvc)
+	ssoSvc := ssoLogic.NewSSOService(s.db, authSvc, auditSvc, 10*time.Second)
+	mfaSvc := mfaLogic.NewMFAService(s.db, dataKeys, authSvc, auditSvc, "DML")
+	if n, err := mfaSvc.EncryptStoredSecrets(context.Background(), 500); err != nil {
+		log.Fatalf("Failed to encrypt stored MFA secrets: %v", err)
+	} else if n > 0 {
+		log.Printf("Encrypted %d stored MFA secrets", n)
+	}
+	apiKeySvc := apikeysLogic.NewAPIKeyService(s.db, auditSvc)
+	s.events = eventsLogic.NewBroker(s.db)
 
 	// Initialize Handlers
 	auditHandler := auditHTTP.NewAuditHandler(auditSvc)
 	authHandler := authHTTP.NewAuthHandler(authSvc)
 	tenantHandler := tenancyHTTP.NewHandler(tenantSvc)
-	buHandler := orgHTTP.NewBusinessUnitHandler(buSvc)
-	deptHandler := orgHTTP.NewDepartmentHandler(deptSvc)
-	jobHandler := orgHTTP.NewJobTitleHandler(jobSvc)
-	empHandler := hrHTTP.NewEmployeeHandler(empSvc)
-	onboardHandler := hrHTTP.NewOnboardingHandler(onboardSvc)
+	buHandler := orgHTTP.NewBusinessUnitHandler(buSvc, customFieldSvc)
+	deptHandler := orgHTTP.NewDepartmentHandler(deptSvc, customFieldSvc)
+	jobHandler := orgHTTP.NewJobTitleHandler(jobSvc, competencySvc, customFieldSvc)
+	gradeHandler := orgHTTP.NewJobGradeHandler(gradeSvc)
+	competencyHandler := competencyHTTP.NewCompetencyHandler(competencySvc)
+	empHandler := hrHTTP.NewEmployeeHandler(empSvc, importSvc, customFieldSvc)
+	personalDetailsHandler := hrHTTP.NewPersonalDetailsHandler(personalDetailsSvc)
+	privacyHandler := privacyHTTP.NewPrivacyHandler(privacySvc)
+	customFieldHandler := customFieldsHTTP.NewDefinitionHandler(customFieldSvc)
+	changeRequestHandler := hrHTTP.NewChangeRequestHandler(changeRequestSvc)
+	onboardHandler := hrHTTP.NewOnboardingHandler(onboardSvc, customFieldSvc)
 	userHandler := iamHTTP.NewUserHandler(userSvc, userRoleSvc)
+	meHandler := iamHTTP.NewMeHandler(userSvc, empSvc)
 	roleHandler := iamHTTP.NewRoleHandler(roleSvc)
+	jobsHandler := jobsHTTP.NewJobHandler(jobRunner)
+	exportHandler := exportHTTP.NewExportHandler(exportSvc)
+	trainingHandler := trainingHTTP.NewTrainingHandler(trainingSvc)
+	ncrHandler := capaHTTP.NewNCRHandler(ncrSvc)
+	programmeHandler := internalAuditHTTP.NewProgrammeHandler(internalAuditSvc)
+	internalAuditHandler := internalAuditHTTP.NewAuditHandler(internalAuditSvc)
+	taskHandler := tasksHTTP.NewTaskHandler(taskSvc, notificationSvc)
+	notifyHandler := notifyHTTP.NewNotifyHandler(mailer)
+	webhookHandler := webhooksHTTP.NewWebhookHandler(webhookSvc)
+	eventsHandler := eventsHTTP.NewEventsHandler(s.events)
+	scimHandler := scimHTTP.NewScimHandler(scimSvc)
+	ssoHandler := ssoHTTP.NewSSOHandler(ssoSvc, s.config.CORSOrigins)
+	mfaHandler := mfaHTTP.NewMFAHandler(mfaSvc)
+	apiKeyHandler := apikeysHTTP.NewAPIKeyHandler(apiKeySvc)
 
 	// JWT Config
 	jwtMiddleware := authHTTP.AuthMiddleware(authHTTP.MiddlewareConfig{
-		JWTSecret: s.config.JWTSecret,
+		Keys:     keys,
+		Issuer:   s.config.JWTIssuer,
+		Audience: s.config.JWTAudience,
+		APIKeys:  apiKeySvc,
 	})
 
+	// Public keys for verifying session tokens
+	s.router.Get("/.well-known/jwks.json", authHandler.HandleJWKS)
+
 	// API version grouping
 	s.router.Route("/api/v1", func(r chi.Router) {
 
 		// Public Routes
 		r.Route("/auth", func(public chi.Router) {
 			authHandler.RegisterRoutes(public)
+			public.Route("/sso", ssoHandler.RegisterRoutes)
+			public.Route("/mfa", mfaHandler.RegisterRoutes)
 		})
 		r.Route("/tenants", tenantHandler.RegisterRoutes) // Tenants might be public to register
 
@@ -132,12 +249,64 @@ func (s *Server) setupRoutes() {
 			protected.Route("/business-units", buHandler.RegisterRoutes)
 			protected.Route("/departments", deptHandler.RegisterRoutes)
 			protected.Route("/job-titles", jobHandler.RegisterRoutes)
-			protected.Route("/employees", empHandler.RegisterRoutes)
+			protected.Route("/job-grades", gradeHandler.RegisterRoutes)
+			protected.Route("/competencies", competencyHandler.RegisterRoutes)
+			protected.Route("/custom-fields", customFieldHandler.RegisterRoutes)
+			protected.Route("/employees", func(emp chi.Router) {
+				empHandler.RegisterRoutes(emp)
+				personalDetailsHandler.RegisterRoutes(emp)
+				privacyHandler.RegisterRoutes(emp)
+			})
+			protected.Route("/employee-change-requests", changeRequestHandler.RegisterRoutes)
 			protected.Route("/onboard", onboardHandler.RegisterRoutes)
 			protected.Route("/users", userHandler.RegisterRoutes)
 			protected.Route("/roles", roleHandler.RegisterRoutes)
+			protected.Route("/jobs", jobsHandler.RegisterRoutes)
+			protected.Route("/exports", exportHandler.RegisterRoutes)
+			protected.Route("/training", trainingHandler.RegisterRoutes)
+			protected.Route("/ncrs", ncrHandler.RegisterRoutes)
+			protected.Route("/audit-programmes", programmeHandler.RegisterRoutes)
+			protected.Route("/internal-audits", internalAuditHandler.RegisterRoutes)
+			protected.Route("/tasks", taskHandler.RegisterRoutes)
+			protected.Route("/notification-templates", notifyHandler.RegisterTemplateRoutes)
+			protected.Route("/email-outbox", notifyHandler.RegisterOutboxRoutes)
+			protected.Route("/webhooks", webhookHandler.RegisterRoutes)
+			protected.Route("/events", eventsHandler.RegisterRoutes)
+			// Credential management stays with the real user, never an impersonating administrator
+			protected.With(authHTTP.DenyImpersonation).Route("/scim-tokens", scimHandler.RegisterTokenRoutes)
+			protected.With(authHTTP.DenyImpersonation).Route("/sso", ssoHandler.RegisterAdminRoutes)
+			protected.With(authHTTP.DenyImpersonation).Route("/mfa", mfaHandler.RegisterAdminRoutes)
+			protected.With(authHTTP.DenyImpersonation).Route("/service-accounts", apiKeyHandler.RegisterServiceAccountRoutes)
+			protected.With(authHTTP.DenyImpersonation).Route("/api-keys", apiKeyHandler.RegisterAdminRoutes)
+			protected.Route("/me", func(me chi.Router) {
+				meHandler.RegisterRoutes(me)
+				taskHandler.RegisterMeRoutes(me)
+				changeRequestHandler.RegisterMeRoutes(me)
+				notifyHandler.RegisterMeRoutes(me)
+				me.With(authHTTP.DenyImpersonation).Route("/mfa", mfaHandler.RegisterMeRoutes)
+				me.With(authHTTP.DenyImpersonation).Route("/api-keys", apiKeyHandler.RegisterMeRoutes)
+			})
 		})
 	})
+
+	// SCIM 2.0 provisioning, authenticated with tenant SCIM tokens
+	s.router.Route("/scim/v2", scimHandler.RegisterRoutes)
+}
+
+// timeoutExcept applies middleware.Timeout to every request but those for the given paths
+func timeoutExcept(timeout time.Duration, paths ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withTimeout := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, p := range paths {
+				if r.URL.Path == p {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			withTimeout.ServeHTTP(w, r)
+		})
+	}
 }
 
 func (s *Server) handleHealthCheck() http.HandlerFunc {
@@ -163,6 +332,8 @@ func (s *Server) Run() error {
 		Addr:    fmt.Sprintf(":%s", s.config.APIPort),
 		Handler: s.router,
 	}
+	// Shutdown waits for open requests, so end the event streams when it starts
+	srv.RegisterOnShutdown(s.events.Close)
 
 	// Server run context
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
@@ -0,0 +1,428 @@
+package sso
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	logic "github.com/INOVA/DML/internal/logic/sso"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// bindingCookieName is the cookie tying a pending sign-in to the browser that started it
+const bindingCookieName = "dml_sso_binding"
+
+type SSOHandler struct {
+	service *logic.SSOService
+	// allowedOrigins are the front-ends a sign-in may return to with its token
+	allowedOrigins []string
+}
+
+func NewSSOHandler(service *logic.SSOService, allowedOrigins []string) *SSOHandler {
+	return &SSOHandler{service: service, allowedOrigins: allowedOrigins}
+}
+
+// RegisterRoutes mounts the public sign-in endpoints
+func (h *SSOHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/{tenantCode}/start", h.HandleStart)
+	r.Get("/{tenantCode}/callback", h.HandleCallback)
+}
+
+// RegisterAdminRoutes mounts the endpoints that configure the tenant's identity provider
+func (h *SSOHandler) RegisterAdminRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/provider", h.HandleGetProvider)
+	r.With(admin).Put("/provider", h.HandleSaveProvider)
+	r.With(admin).Delete("/provider", h.HandleDeleteProvider)
+	r.With(admin).Get("/group-mappings", h.HandleListGroupMappings)
+	r.With(admin).Post("/group-mappings", h.HandleCreateGroupMapping)
+	r.With(admin).Delete("/group-mappings/{id}", h.HandleDeleteGroupMapping)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writeSSOError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, logic.ErrNotConfigured):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, logic.ErrInvalidState),
+		errors.Is(err, logic.ErrInvalidIssuer),
+		errors.Is(err, logic.ErrClientSecretRequired):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNoAccount), errors.Is(err, logic.ErrAccountDisabled),
+		errors.Is(err, logic.ErrRoleExceedsActor):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrProvider):
+		response.Error(w, http.StatusBadGateway, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// callbackURL is the redirect URI registered with the provider for a tenant
+func callbackURL(r *http.Request, tenantCode string) string {
+	return requestScheme(r) + "://" + r.Host + "/api/v1/auth/sso/" + url.PathEscape(tenantCode) + "/callback"
+}
+
+func requestScheme(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme
+}
+
+// bindingCookie holds the browser binding of a pending sign-in. Lax still sends it on the
+// provider's top-level redirect to the callback.
+func bindingCookie(r *http.Request, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     bindingCookieName,
+		Value:    value,
+		Path:     "/api/v1/auth/sso/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   requestScheme(r) == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// allowedReturn reports whether a sign-in may hand its token to returnTo, which must be on
+// one of the front-end origins
+func (h *SSOHandler) allowedReturn(returnTo string) bool {
+	u, err := url.Parse(returnTo)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	origin := u.Scheme + "://" + u.Host
+	for _, o := range h.allowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// @Summary Start Single Sign-On
+// @Description Redirects the browser to the tenant's OpenID Connect provider and sets an HttpOnly cookie binding the sign-in to this browser. After sign-in the callback redirects to returnTo with the session token in the URL fragment (#token=...), or an MFA challenge (#mfaRequired=true&mfaToken=... or #mfaEnrolmentRequired=true&mfaToken=...), or responds as /auth/login does when returnTo is omitted. returnTo must be on an allowed front-end origin.
+// @Tags Authentication
+// @Param tenantCode path string true "Tenant code"
+// @Param returnTo query string false "Front-end URL to return to"
+// @Success 302
+// @Failure 404 {object} map[string]interface{} "Single sign-on is not configured"
+// @Router /api/v1/auth/sso/{tenantCode}/start [get]
+func (h *SSOHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
+	tenantCode := chi.URLParam(r, "tenantCode")
+
+	returnTo := r.URL.Query().Get("returnTo")
+	if returnTo != "" && !h.allowedReturn(returnTo) {
+		response.Error(w, http.StatusBadRequest, "returnTo is not an allowed origin")
+		return
+	}
+
+	authURL, binding, err := h.service.Start(r.Context(), tenantCode, callbackURL(r, tenantCode), returnTo)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	http.SetCookie(w, bindingCookie(r, binding, int(logic.StateTTL.Seconds())))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// @Summary Single Sign-On Callback
+// @Description Completes sign-in with the provider's authorization code. It must be reached in the browser that called start, which holds the binding cookie; otherwise the sign-in is refused. The external subject is linked to the user with the same email on first sign-in when the provider marks it verified (email_verified true) and the user holds no administrative role or permission, or a new employee and user are provisioned when the provider allows it. Roles mapped from the provider's groups claim are granted or revoked, except that ADMIN is never revoked from the tenant's last active administrator. Users with MFA, or holding a role that requires it, get mfaRequired (or mfaEnrolmentRequired) and an mfaToken to complete at /auth/mfa/verify, as after a password login.
+// @Tags Authentication
+// @Produce json
+// @Param tenantCode path string true "Tenant code"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the start request"
+// @Success 200 {object} map[string]interface{} "Session or MFA challenge, as from /auth/login, when no returnTo was given"
+// @Success 302
+// @Failure 403 {object} map[string]interface{} "No account for this identity, or account disabled"
+// @Router /api/v1/auth/sso/{tenantCode}/callback [get]
+func (h *SSOHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	tenantCode := chi.URLParam(r, "tenantCode")
+
+	if providerErr := r.URL.Query().Get("error"); providerErr != "" {
+		response.Error(w, http.StatusUnauthorized, "Sign-in was not completed: "+providerErr)
+		return
+	}
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	if code == "" || state == "" {
+		response.Error(w, http.StatusBadRequest, "code and state are required")
+		return
+	}
+
+	// The sign-in must finish in the browser that started it
+	var binding string
+	if c, err := r.Cookie(bindingCookieName); err == nil {
+		binding = c.Value
+	}
+	http.SetCookie(w, bindingCookie(r, "", -1))
+
+	result, err := h.service.Callback(r.Context(), tenantCode, callbackURL(r, tenantCode), code, state, binding)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	if result.ReturnTo != "" {
+		// The fragment keeps the token out of server logs and Referer headers
+		target, _ := url.Parse(result.ReturnTo)
+		fragment := url.Values{}
+		switch {
+		case result.MFARequired:
+			fragment.Set("mfaRequired", "true")
+			fragment.Set("mfaToken", result.MFAToken)
+		case result.MFAEnrolmentRequired:
+			fragment.Set("mfaEnrolmentRequired", "true")
+			fragment.Set("mfaToken", result.MFAToken)
+		default:
+			fragment.Set("token", result.Token)
+		}
+		target.Fragment = fragment.Encode()
+		http.Redirect(w, r, target.String(), http.StatusFound)
+		return
+	}
+	response.JSON(w, http.StatusOK, result.LoginResult)
+}
+
+type ProviderRequest struct {
+	Issuer          string `json:"issuer" validate:"required,url"`
+	ClientID        string `json:"clientId" validate:"required"`
+	ClientSecret    string `json:"clientSecret"`
+	Scopes          string `json:"scopes"`
+	GroupsClaim     string `json:"groupsClaim"`
+	JitProvisioning bool   `json:"jitProvisioning"`
+	IsActive        *bool  `json:"isActive"`
+}
+
+type GroupMappingRequest struct {
+	GroupName string `json:"groupName" validate:"required"`
+	RoleID    string `json:"roleId" validate:"required,uuid"`
+}
+
+// @Summary Get the Identity Provider
+// @Description Returns the tenant's OpenID Connect provider configuration. The client secret is not included.
+// @Tags Single Sign-On
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sso/provider [get]
+func (h *SSOHandler) HandleGetProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	provider, err := h.service.GetProvider(r.Context(), tenantID)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, provider)
+}
+
+// @Summary Configure the Identity Provider
+// @Description Creates or replaces the tenant's OpenID Connect provider. The issuer must serve /.well-known/openid-configuration. Leave clientSecret empty to keep the stored one. Register <api>/api/v1/auth/sso/{tenantCode}/callback as the redirect URI.
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ProviderRequest true "Provider Payload"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sso/provider [put]
+func (h *SSOHandler) HandleSaveProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	in := logic.ProviderInput{
+		Issuer:          req.Issuer,
+		ClientID:        req.ClientID,
+		ClientSecret:    req.ClientSecret,
+		Scopes:          req.Scopes,
+		GroupsClaim:     req.GroupsClaim,
+		JitProvisioning: req.JitProvisioning,
+		IsActive:        true,
+	}
+	if req.IsActive != nil {
+		in.IsActive = *req.IsActive
+	}
+
+	providerID, _ := parseUUIDString(uuid.New().String())
+
+	provider, err := h.service.SaveProvider(r.Context(), providerID, tenantID, actorID, in)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, provider)
+}
+
+// @Summary Remove the Identity Provider
+// @Description Removes the provider with its group mappings and linked identities. Users keep their accounts and roles.
+// @Tags Single Sign-On
+// @Security BearerAuth
+// @Success 204
+// @Router /api/v1/sso/provider [delete]
+func (h *SSOHandler) HandleDeleteProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.service.DeleteProvider(r.Context(), tenantID, actorID); err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List Group Mappings
+// @Description Lists the provider groups that grant roles on sign-in.
+// @Tags Single Sign-On
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} map[string]interface{}
+// @Router /api/v1/sso/group-mappings [get]
+func (h *SSOHandler) HandleListGroupMappings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	mappings, err := h.service.ListGroupMappings(r.Context(), tenantID)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, mappings)
+}
+
+// @Summary Map a Group to a Role
+// @Description Members of the group, as sent in the provider's groups claim, are granted the role tenant-wide when they sign in. Mapped roles are revoked from users who are no longer in any group mapped to them.
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body GroupMappingRequest true "Group Mapping Payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "The role grants a permission you do not hold"
+// @Router /api/v1/sso/group-mappings [post]
+func (h *SSOHandler) HandleCreateGroupMapping(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req GroupMappingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	roleID, _ := parseUUIDString(req.RoleID)
+	mappingID, _ := parseUUIDString(uuid.New().String())
+
+	mapping, err := h.service.CreateGroupMapping(r.Context(), mappingID, tenantID, actorID, req.GroupName, roleID)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, mapping)
+}
+
+// @Summary Delete a Group Mapping
+// @Tags Single Sign-On
+// @Security BearerAuth
+// @Param id path string true "Mapping UUID"
+// @Success 204
+// @Router /api/v1/sso/group-mappings/{id} [delete]
+func (h *SSOHandler) HandleDeleteGroupMapping(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	mappingID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid mapping ID format")
+		return
+	}
+
+	if err := h.service.DeleteGroupMapping(r.Context(), tenantID, actorID, mappingID); err != nil {
+		writeSSOError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
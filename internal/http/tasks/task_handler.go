@@ -0,0 +1,475 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/tasks"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type TaskHandler struct {
+	service         *logic.TaskService
+	notificationSvc *logic.NotificationService
+}
+
+func NewTaskHandler(service *logic.TaskService, notificationSvc *logic.NotificationService) *TaskHandler {
+	return &TaskHandler{
+		service:         service,
+		notificationSvc: notificationSvc,
+	}
+}
+
+// RegisterRoutes mounts task management under /tasks
+func (h *TaskHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/", h.HandleCreate)
+	r.Get("/{id}", h.HandleGet)
+	r.Post("/{id}/complete", h.HandleComplete)
+	r.With(authHTTP.RequireRole("ADMIN")).Post("/{id}/cancel", h.HandleCancel)
+}
+
+// RegisterMeRoutes mounts the caller's inbox under /me
+func (h *TaskHandler) RegisterMeRoutes(r chi.Router) {
+	r.Get("/tasks", h.HandleListMine)
+	r.Get("/notifications", h.HandleListNotifications)
+	r.Get("/notifications/unread-count", h.HandleUnreadCount)
+	r.Post("/notifications/read-all", h.HandleMarkAllRead)
+	r.Post("/notifications/{id}/read", h.HandleMarkRead)
+	r.Post("/notifications/{id}/unread", h.HandleMarkUnread)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func isAdmin(r *http.Request) bool {
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTaskError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Task not found")
+	case errors.Is(err, logic.ErrAssigneeRequired),
+		errors.Is(err, logic.ErrEmployeeNotFound),
+		errors.Is(err, logic.ErrRoleNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotAssignee):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrTaskClosed):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// @Summary List My Tasks
+// @Description Get a paginated list of tasks assigned to the caller's employee record or to any of their roles, soonest due first. Only open tasks are returned unless status is given.
+// @Tags Tasks
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param status query string false "open (default), completed, cancelled or all"
+// @Param overdue query bool false "Only tasks past their due date"
+// @Success 200 {object} map[string]interface{} "Paginated task data"
+// @Router /api/v1/me/tasks [get]
+func (h *TaskHandler) HandleListMine(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	filter := logic.MyTaskFilter{Status: logic.StatusOpen}
+	switch status := r.URL.Query().Get("status"); status {
+	case "":
+	case "all":
+		filter.Status = ""
+	case logic.StatusOpen, logic.StatusCompleted, logic.StatusCancelled:
+		filter.Status = status
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+	if overdue, err := strconv.ParseBool(r.URL.Query().Get("overdue")); err == nil {
+		filter.OverdueOnly = overdue
+	}
+
+	tasks, total, err := h.service.ListMyTasks(r.Context(), tenantID, userID, params, filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list tasks")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, tasks, params.Page, params.Size, int(total))
+}
+
+type CreateTaskRequest struct {
+	EntityType         string  `json:"entityType" validate:"required"`
+	EntityID           string  `json:"entityId" validate:"required,uuid"`
+	Kind               string  `json:"kind" validate:"required,oneof=approval acknowledgement review action other"`
+	Title              string  `json:"title" validate:"required"`
+	Description        *string `json:"description"`
+	AssigneeEmployeeID *string `json:"assigneeEmployeeId" validate:"omitempty,uuid"`
+	AssigneeRoleCode   *string `json:"assigneeRoleCode"`
+	DueDate            *string `json:"dueDate" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// @Summary Create a Task
+// @Description Assigns a task about any entity to an employee or to everyone holding a role. entityType uses the audit log entity names. The assignee, or each holder of the role, is notified in-app.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateTaskRequest true "Task Payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid assignee"
+// @Router /api/v1/tasks [post]
+func (h *TaskHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	entityID, _ := parseUUIDString(req.EntityID)
+	in := logic.TaskInput{
+		EntityType:  req.EntityType,
+		EntityID:    entityID,
+		Kind:        req.Kind,
+		Title:       req.Title,
+		Description: req.Description,
+	}
+	if req.AssigneeEmployeeID != nil && *req.AssigneeEmployeeID != "" {
+		in.AssigneeEmployeeID, _ = parseUUIDString(*req.AssigneeEmployeeID)
+	}
+	if req.AssigneeRoleCode != nil {
+		in.AssigneeRoleCode = *req.AssigneeRoleCode
+	}
+	if req.DueDate != nil && *req.DueDate != "" {
+		due, _ := time.Parse("2006-01-02", *req.DueDate)
+		in.DueDate = &due
+	}
+
+	taskID, _ := parseUUIDString(uuid.New().String())
+
+	task, err := h.service.CreateTask(r.Context(), taskID, tenantID, actorID, in)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, task)
+}
+
+// @Summary Get a Task
+// @Description Fetch a single task.
+// @Tags Tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/tasks/{id} [get]
+func (h *TaskHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	taskID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid task ID format")
+		return
+	}
+
+	task, err := h.service.GetTask(r.Context(), tenantID, taskID)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, task)
+}
+
+type CloseTaskRequest struct {
+	Outcome *string `json:"outcome"`
+}
+
+// @Summary Complete a Task
+// @Description Completes an open task with an optional outcome, e.g. approved or rejected. Only the assignee, a holder of the assignee role, or an administrator can complete a task. Tasks that track another record, such as CAPA actions, are closed automatically when that record is resolved.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task UUID"
+// @Param request body CloseTaskRequest false "Outcome"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Not assigned to the caller"
+// @Router /api/v1/tasks/{id}/complete [post]
+func (h *TaskHandler) HandleComplete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	taskID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid task ID format")
+		return
+	}
+
+	var req CloseTaskRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	task, err := h.service.CompleteTask(r.Context(), tenantID, actorID, taskID, req.Outcome, isAdmin(r))
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, task)
+}
+
+// @Summary Cancel a Task
+// @Description Withdraws an open task. The outcome records the reason.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task UUID"
+// @Param request body CloseTaskRequest false "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/tasks/{id}/cancel [post]
+func (h *TaskHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	taskID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid task ID format")
+		return
+	}
+
+	var req CloseTaskRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	task, err := h.service.CancelTask(r.Context(), tenantID, actorID, taskID, req.Outcome)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, task)
+}
+
+// @Summary List My Notifications
+// @Description Get a paginated list of the caller's in-app notifications, newest first.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} map[string]interface{} "Paginated notification data"
+// @Router /api/v1/me/notifications [get]
+func (h *TaskHandler) HandleListNotifications(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+
+	notifications, total, err := h.notificationSvc.List(r.Context(), tenantID, userID, params, unreadOnly)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, notifications, params.Page, params.Size, int(total))
+}
+
+// @Summary Count Unread Notifications
+// @Description Returns how many of the caller's notifications are unread, for badge counters.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/notifications/unread-count [get]
+func (h *TaskHandler) HandleUnreadCount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	count, err := h.notificationSvc.UnreadCount(r.Context(), tenantID, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count notifications")
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]int64{"unread": count})
+}
+
+// @Summary Mark a Notification Read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/notifications/{id}/read [post]
+func (h *TaskHandler) HandleMarkRead(w http.ResponseWriter, r *http.Request) {
+	h.setRead(w, r, true)
+}
+
+// @Summary Mark a Notification Unread
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/notifications/{id}/unread [post]
+func (h *TaskHandler) HandleMarkUnread(w http.ResponseWriter, r *http.Request) {
+	h.setRead(w, r, false)
+}
+
+func (h *TaskHandler) setRead(w http.ResponseWriter, r *http.Request, read bool) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	notificationID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid notification ID format")
+		return
+	}
+
+	notification, err := h.notificationSvc.SetRead(r.Context(), tenantID, userID, notificationID, read)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "Notification not found")
+		return
+	}
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, notification)
+}
+
+// @Summary Mark All Notifications Read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/me/notifications/read-all [post]
+func (h *TaskHandler) HandleMarkAllRead(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	updated, err := h.notificationSvc.MarkAllRead(r.Context(), tenantID, userID)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
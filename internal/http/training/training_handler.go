@@ -0,0 +1,888 @@
+package training
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/competency"
+	logic "github.com/INOVA/DML/internal/logic/training"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxEvidenceFileBytes bounds the size of uploaded training evidence
+const maxEvidenceFileBytes = 10 << 20
+
+type TrainingHandler struct {
+	service *logic.TrainingService
+}
+
+func NewTrainingHandler(service *logic.TrainingService) *TrainingHandler {
+	return &TrainingHandler{service: service}
+}
+
+func (h *TrainingHandler) RegisterRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.Get("/courses", h.HandleListCourses)
+	r.With(admin).Post("/courses", h.HandleCreateCourse)
+	r.Get("/courses/{id}", h.HandleGetCourse)
+	r.With(admin).Put("/courses/{id}", h.HandleUpdateCourse)
+
+	r.Get("/sessions", h.HandleListSessions)
+	r.With(admin).Post("/sessions", h.HandleCreateSession)
+	r.Get("/sessions/{id}", h.HandleGetSession)
+	r.With(admin).Post("/sessions/{id}/complete", h.HandleCompleteSession)
+	r.With(admin).Post("/sessions/{id}/cancel", h.HandleCancelSession)
+
+	r.With(admin).Post("/records", h.HandleCreateRecord)
+	r.Get("/records/{id}", h.HandleGetRecord)
+	r.Post("/records/{id}/sign-off", h.HandleSignOff)
+	r.With(admin).Put("/records/{id}/evidence", h.HandleUploadEvidence)
+	r.Get("/records/{id}/evidence", h.HandleDownloadEvidence)
+
+	r.Get("/employees/{employeeId}/records", h.HandleEmployeeHistory)
+	r.Get("/expiring", h.HandleExpiring)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+// parseOptionalUUID parses an optional, already validated UUID string
+func parseOptionalUUID(v *string) pgtype.UUID {
+	if v == nil {
+		return pgtype.UUID{}
+	}
+	id, _ := parseUUIDString(*v)
+	return id
+}
+
+// writeTrainingError maps training errors onto HTTP statuses. notFound is the message used
+// when the addressed resource itself does not exist.
+func writeTrainingError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, notFound)
+	case errors.Is(err, logic.ErrCourseNotFound),
+		errors.Is(err, logic.ErrCourseInactive),
+		errors.Is(err, logic.ErrSessionNotFound),
+		errors.Is(err, logic.ErrSessionCourseMismatch),
+		errors.Is(err, logic.ErrTrainerNotFound),
+		errors.Is(err, logic.ErrInvalidExpiry),
+		errors.Is(err, logic.ErrSelfSignOff),
+		errors.Is(err, competency.ErrCompetencyNotFound):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrNotTrainer):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, logic.ErrSessionClosed), errors.Is(err, logic.ErrAlreadySignedOff):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+// HandleListCourses godoc
+// @Summary      List training courses
+// @Description  Retrieves a paginated list of training courses for the authenticated tenant.
+// @Tags         Training
+// @Produce      json
+// @Param        page    query     int     false  "Page number" default(1)
+// @Param        size    query     int     false  "Page size" default(50)
+// @Param        search  query     string  false  "Search term (name/code)"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Paginated course data"
+// @Failure      401     {object}  map[string]interface{} "Unauthorized"
+// @Router       /api/v1/training/courses [get]
+func (h *TrainingHandler) HandleListCourses(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	courses, total, err := h.service.ListCourses(r.Context(), tenantID, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list training courses")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, courses, params.Page, params.Size, int(total))
+}
+
+// HandleGetCourse godoc
+// @Summary      Get a training course
+// @Description  Retrieves a training course by its ID.
+// @Tags         Training
+// @Produce      json
+// @Param        id      path      string  true  "Course ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Course data"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/training/courses/{id} [get]
+func (h *TrainingHandler) HandleGetCourse(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	courseID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid course ID format")
+		return
+	}
+
+	course, err := h.service.GetCourse(r.Context(), tenantID, courseID)
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Training course not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, course)
+}
+
+type CourseRequest struct {
+	Code           string  `json:"code" validate:"required"`
+	Name           string  `json:"name" validate:"required"`
+	Description    *string `json:"description"`
+	CompetencyID   *string `json:"competencyId" validate:"omitempty,uuid"`
+	ValidityMonths *int32  `json:"validityMonths" validate:"omitempty,gt=0"`
+	IsMandatory    bool    `json:"isMandatory"`
+	IsActive       *bool   `json:"isActive"`
+}
+
+func (req CourseRequest) input() logic.CourseInput {
+	return logic.CourseInput{
+		Code:           req.Code,
+		Name:           req.Name,
+		Description:    req.Description,
+		CompetencyID:   parseOptionalUUID(req.CompetencyID),
+		ValidityMonths: req.ValidityMonths,
+		IsMandatory:    req.IsMandatory,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+}
+
+// HandleCreateCourse godoc
+// @Summary      Create a training course
+// @Description  Creates a training course. validityMonths sets how long a completion stays current; competencyId links the course to the competency it grants once a completion is signed off. Mandatory courses drive the hasExpiredMandatoryTraining flag on employees.
+// @Tags         Training
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CourseRequest  true  "Course"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Course data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      409     {object}  map[string]interface{} "Code already exists"
+// @Router       /api/v1/training/courses [post]
+func (h *TrainingHandler) HandleCreateCourse(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	courseID, _ := parseUUIDString(uuid.New().String())
+
+	course, err := h.service.CreateCourse(r.Context(), courseID, tenantID, actorID, req.input())
+	if err != nil {
+		writeTrainingError(w, err, "Training course not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, course)
+}
+
+// HandleUpdateCourse godoc
+// @Summary      Update a training course
+// @Description  Replaces a training course's details. isActive defaults to true when omitted.
+// @Tags         Training
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string         true  "Course ID"
+// @Param        request  body      CourseRequest  true  "Course"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Course data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/training/courses/{id} [put]
+func (h *TrainingHandler) HandleUpdateCourse(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	courseID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid course ID format")
+		return
+	}
+
+	var req CourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	course, err := h.service.UpdateCourse(r.Context(), tenantID, actorID, courseID, req.input())
+	if err != nil {
+		writeTrainingError(w, err, "Training course not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, course)
+}
+
+// HandleListSessions godoc
+// @Summary      List training sessions
+// @Description  Retrieves a paginated list of training sessions, most recent first.
+// @Tags         Training
+// @Produce      json
+// @Param        page      query     int     false  "Page number" default(1)
+// @Param        size      query     int     false  "Page size" default(50)
+// @Param        courseId  query     string  false  "Only sessions of this course"
+// @Param        status    query     string  false  "scheduled, completed or cancelled"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Paginated session data"
+// @Failure      400     {object}  map[string]interface{} "Invalid filter"
+// @Router       /api/v1/training/sessions [get]
+func (h *TrainingHandler) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	filter := logic.SessionFilter{Status: r.URL.Query().Get("status")}
+	switch filter.Status {
+	case "", logic.SessionScheduled, logic.SessionCompleted, logic.SessionCancelled:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+	if v := r.URL.Query().Get("courseId"); v != "" {
+		id, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid course ID format")
+			return
+		}
+		filter.CourseID = id
+	}
+
+	sessions, total, err := h.service.ListSessions(r.Context(), tenantID, params, filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list training sessions")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, sessions, params.Page, params.Size, int(total))
+}
+
+// HandleGetSession godoc
+// @Summary      Get a training session
+// @Description  Retrieves a training session with the records of its attendees.
+// @Tags         Training
+// @Produce      json
+// @Param        id      path      string  true  "Session ID"
+// @Security     BearerAuth
+// @Success      200     {object}  logic.SessionDetail
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/training/sessions/{id} [get]
+func (h *TrainingHandler) HandleGetSession(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid session ID format")
+		return
+	}
+
+	session, err := h.service.GetSession(r.Context(), tenantID, sessionID)
+	if err != nil {
+		writeTrainingError(w, err, "Training session not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, session)
+}
+
+type SessionRequest struct {
+	CourseID          string     `json:"courseId" validate:"required,uuid"`
+	TrainerEmployeeID *string    `json:"trainerEmployeeId" validate:"omitempty,uuid"`
+	ExternalTrainer   *string    `json:"externalTrainer"`
+	BusinessUnitID    *string    `json:"businessUnitId" validate:"omitempty,uuid"`
+	Location          *string    `json:"location"`
+	StartsAt          time.Time  `json:"startsAt" validate:"required"`
+	EndsAt            *time.Time `json:"endsAt" validate:"omitempty,gtfield=StartsAt"`
+}
+
+// HandleCreateSession godoc
+// @Summary      Schedule a training session
+// @Description  Schedules a delivery of a course. An internal trainer (trainerEmployeeId) may later sign off attendees' records; external providers are recorded by name only.
+// @Tags         Training
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SessionRequest  true  "Session"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Session data"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Router       /api/v1/training/sessions [post]
+func (h *TrainingHandler) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	sessionID, _ := parseUUIDString(uuid.New().String())
+	courseID, _ := parseUUIDString(req.CourseID)
+
+	session, err := h.service.CreateSession(r.Context(), sessionID, tenantID, actorID, logic.SessionInput{
+		CourseID:          courseID,
+		TrainerEmployeeID: parseOptionalUUID(req.TrainerEmployeeID),
+		ExternalTrainer:   req.ExternalTrainer,
+		BusinessUnitID:    parseOptionalUUID(req.BusinessUnitID),
+		Location:          req.Location,
+		StartsAt:          req.StartsAt,
+		EndsAt:            req.EndsAt,
+	})
+	if err != nil {
+		writeTrainingError(w, err, "Training session not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, session)
+}
+
+type AttendeeRequest struct {
+	EmployeeID string  `json:"employeeId" validate:"required,uuid"`
+	Result     string  `json:"result" validate:"omitempty,oneof=pass fail"`
+	Notes      *string `json:"notes"`
+}
+
+type CompleteSessionRequest struct {
+	CompletedOn string            `json:"completedOn" validate:"required,datetime=2006-01-02"`
+	Attendees   []AttendeeRequest `json:"attendees" validate:"required,min=1,dive"`
+}
+
+// HandleCompleteSession godoc
+// @Summary      Complete a training session
+// @Description  Closes a scheduled session and creates a training record for each attendee. Records still need the trainer's sign-off before they count.
+// @Tags         Training
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Session ID"
+// @Param        request  body      CompleteSessionRequest  true  "Attendance"
+// @Security     BearerAuth
+// @Success      200     {object}  logic.SessionDetail
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Session already closed"
+// @Router       /api/v1/training/sessions/{id}/complete [post]
+func (h *TrainingHandler) HandleCompleteSession(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid session ID format")
+		return
+	}
+
+	var req CompleteSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	completedOn, _ := time.Parse(dateLayout, req.CompletedOn)
+	attendees := make([]logic.Attendee, 0, len(req.Attendees))
+	for _, a := range req.Attendees {
+		empID, _ := parseUUIDString(a.EmployeeID)
+		attendees = append(attendees, logic.Attendee{
+			EmployeeID: empID,
+			Result:     a.Result,
+			Notes:      a.Notes,
+		})
+	}
+
+	session, err := h.service.CompleteSession(r.Context(), tenantID, actorID, sessionID, completedOn, attendees)
+	if err != nil {
+		writeTrainingError(w, err, "Training session or attendee not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, session)
+}
+
+// HandleCancelSession godoc
+// @Summary      Cancel a training session
+// @Description  Cancels a scheduled training session.
+// @Tags         Training
+// @Produce      json
+// @Param        id      path      string  true  "Session ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Session data"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Session already closed"
+// @Router       /api/v1/training/sessions/{id}/cancel [post]
+func (h *TrainingHandler) HandleCancelSession(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid session ID format")
+		return
+	}
+
+	session, err := h.service.CancelSession(r.Context(), tenantID, actorID, sessionID)
+	if err != nil {
+		writeTrainingError(w, err, "Training session not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, session)
+}
+
+type RecordRequest struct {
+	EmployeeID  string  `json:"employeeId" validate:"required,uuid"`
+	CourseID    string  `json:"courseId" validate:"required,uuid"`
+	SessionID   *string `json:"sessionId" validate:"omitempty,uuid"`
+	CompletedOn string  `json:"completedOn" validate:"required,datetime=2006-01-02"`
+	ExpiresOn   *string `json:"expiresOn" validate:"omitempty,datetime=2006-01-02"`
+	Result      string  `json:"result" validate:"omitempty,oneof=pass fail"`
+	Notes       *string `json:"notes"`
+}
+
+// HandleCreateRecord godoc
+// @Summary      Record a training completion
+// @Description  Records that an employee completed a course, for example external training. When expiresOn is omitted it is derived from the course's validity period. The record counts once signed off.
+// @Tags         Training
+// @Accept       json
+// @Produce      json
+// @Param        request  body      RecordRequest  true  "Completion"
+// @Security     BearerAuth
+// @Success      201     {object}  map[string]interface{} "Training record"
+// @Failure      400     {object}  map[string]interface{} "Validation error"
+// @Failure      404     {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/training/records [post]
+func (h *TrainingHandler) HandleCreateRecord(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req RecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	empID, _ := parseUUIDString(req.EmployeeID)
+	courseID, _ := parseUUIDString(req.CourseID)
+	completedOn, _ := time.Parse(dateLayout, req.CompletedOn)
+	var expiresOn *time.Time
+	if req.ExpiresOn != nil {
+		t, _ := time.Parse(dateLayout, *req.ExpiresOn)
+		expiresOn = &t
+	}
+
+	rec, err := h.service.RecordTraining(r.Context(), tenantID, actorID, logic.RecordInput{
+		EmployeeID:  empID,
+		CourseID:    courseID,
+		SessionID:   parseOptionalUUID(req.SessionID),
+		CompletedOn: completedOn,
+		ExpiresOn:   expiresOn,
+		Result:      req.Result,
+		Notes:       req.Notes,
+	})
+	if err != nil {
+		writeTrainingError(w, err, "Employee not found")
+		return
+	}
+	response.JSON(w, http.StatusCreated, rec)
+}
+
+// HandleGetRecord godoc
+// @Summary      Get a training record
+// @Description  Retrieves a single training record.
+// @Tags         Training
+// @Produce      json
+// @Param        id      path      string  true  "Record ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Training record"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Router       /api/v1/training/records/{id} [get]
+func (h *TrainingHandler) HandleGetRecord(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recordID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid record ID format")
+		return
+	}
+
+	rec, err := h.service.GetRecord(r.Context(), tenantID, recordID)
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Training record not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, rec)
+}
+
+// HandleSignOff godoc
+// @Summary      Sign off a training record
+// @Description  Confirms a training record. Allowed for the internal trainer of the record's session, or any administrator; never for the trainee. Signing off a pass of a course linked to a competency records that competency.
+// @Tags         Training
+// @Produce      json
+// @Param        id      path      string  true  "Record ID"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Signed-off training record"
+// @Failure      400     {object}  map[string]interface{} "Trainee cannot sign off"
+// @Failure      403     {object}  map[string]interface{} "Not the session trainer"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Already signed off"
+// @Router       /api/v1/training/records/{id}/sign-off [post]
+func (h *TrainingHandler) HandleSignOff(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recordID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid record ID format")
+		return
+	}
+
+	asAdmin := false
+	roles, _ := authHTTP.GetRolesFromContext(r.Context())
+	for _, role := range roles {
+		if role == "ADMIN" {
+			asAdmin = true
+			break
+		}
+	}
+
+	rec, err := h.service.SignOff(r.Context(), tenantID, actorID, recordID, asAdmin)
+	if err != nil {
+		writeTrainingError(w, err, "Training record not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, rec)
+}
+
+// HandleUploadEvidence godoc
+// @Summary      Attach training evidence
+// @Description  Uploads a certificate or attendance sheet (multipart field "file", up to 10 MB) to a training record, replacing any earlier file. Evidence cannot change after sign-off.
+// @Tags         Training
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id      path      string  true  "Record ID"
+// @Param        file    formData  file    true  "Evidence file"
+// @Security     BearerAuth
+// @Success      200     {object}  map[string]interface{} "Training record"
+// @Failure      400     {object}  map[string]interface{} "Missing or oversized file"
+// @Failure      404     {object}  map[string]interface{} "Not found"
+// @Failure      409     {object}  map[string]interface{} "Already signed off"
+// @Router       /api/v1/training/records/{id}/evidence [put]
+func (h *TrainingHandler) HandleUploadEvidence(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recordID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid record ID format")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxEvidenceFileBytes)
+	if err := r.ParseMultipartForm(maxEvidenceFileBytes); err != nil {
+		response.Error(w, http.StatusBadRequest, "Expected a multipart upload no larger than 10 MB")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Missing 'file' form field")
+		return
+	}
+	defer file.Close()
+
+	rec, err := h.service.AttachEvidence(r.Context(), tenantID, actorID, recordID, header.Filename, header.Header.Get("Content-Type"), file)
+	if err != nil {
+		writeTrainingError(w, err, "Training record not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, rec)
+}
+
+// HandleDownloadEvidence godoc
+// @Summary      Download training evidence
+// @Description  Downloads the evidence file attached to a training record.
+// @Tags         Training
+// @Produce      octet-stream
+// @Param        id      path      string  true  "Record ID"
+// @Security     BearerAuth
+// @Success      200     {file}    file  "Evidence file"
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Not found or no evidence"
+// @Router       /api/v1/training/records/{id}/evidence [get]
+func (h *TrainingHandler) HandleDownloadEvidence(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	recordID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid record ID format")
+		return
+	}
+
+	file, rec, err := h.service.OpenEvidence(r.Context(), tenantID, recordID)
+	if errors.Is(err, logic.ErrNoEvidence) {
+		response.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Training record not found")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if rec.EvidenceContentType.Valid {
+		w.Header().Set("Content-Type", rec.EvidenceContentType.String)
+	}
+	fileName := "evidence"
+	if rec.EvidenceFileName.Valid {
+		fileName = rec.EvidenceFileName.String
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("training evidence download interrupted: %v", err)
+	}
+}
+
+// HandleEmployeeHistory godoc
+// @Summary      Get an employee's training history
+// @Description  Returns every training record of an employee, most recent first, including unsigned and expired ones.
+// @Tags         Training
+// @Produce      json
+// @Param        employeeId  path      string  true  "Employee ID"
+// @Security     BearerAuth
+// @Success      200     {array}   logic.HistoryEntry
+// @Failure      400     {object}  map[string]interface{} "Invalid ID format"
+// @Failure      404     {object}  map[string]interface{} "Employee not found"
+// @Router       /api/v1/training/employees/{employeeId}/records [get]
+func (h *TrainingHandler) HandleEmployeeHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	empID, err := parseUUIDString(chi.URLParam(r, "employeeId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid employee ID format")
+		return
+	}
+
+	history, err := h.service.ListEmployeeHistory(r.Context(), tenantID, empID)
+	if err != nil {
+		writeTrainingError(w, err, "Employee not found")
+		return
+	}
+	response.JSON(w, http.StatusOK, history)
+}
+
+// HandleExpiring godoc
+// @Summary      List expiring certifications by department
+// @Description  Lists each employee's latest signed-off pass of a course that expires within the given number of days, grouped by department. Renewed certifications are not listed.
+// @Tags         Training
+// @Produce      json
+// @Param        days                   query     int     false  "Look-ahead window in days" default(30)
+// @Param        includeExpired         query     bool    false  "Also list certifications that have already expired"
+// @Param        departmentId           query     string  false  "Only employees in this department"
+// @Param        includeSubDepartments  query     bool    false  "With departmentId, also include sub-departments" default(true)
+// @Security     BearerAuth
+// @Success      200     {array}   logic.DepartmentExpiries
+// @Failure      400     {object}  map[string]interface{} "Invalid filter"
+// @Router       /api/v1/training/expiring [get]
+func (h *TrainingHandler) HandleExpiring(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := logic.ExpiringFilter{IncludeSubDepartments: true}
+	if v := q.Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			response.Error(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		filter.WithinDays = days
+	}
+	if v := q.Get("includeExpired"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid includeExpired value")
+			return
+		}
+		filter.IncludeExpired = b
+	}
+	if v := q.Get("departmentId"); v != "" {
+		id, err := parseUUIDString(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid department ID format")
+			return
+		}
+		filter.DepartmentID = id
+	}
+	if v := q.Get("includeSubDepartments"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid includeSubDepartments value")
+			return
+		}
+		filter.IncludeSubDepartments = b
+	}
+
+	expiring, err := h.service.ListExpiring(r.Context(), tenantID, filter)
+	if err != nil {
+		response.DBError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, expiring)
+}
@@ -0,0 +1,427 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authHTTP "github.com/INOVA/DML/internal/http/auth"
+	"github.com/INOVA/DML/internal/http/query"
+	logic "github.com/INOVA/DML/internal/logic/webhooks"
+	"github.com/INOVA/DML/internal/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhookHandler struct {
+	service *logic.WebhookService
+}
+
+func NewWebhookHandler(service *logic.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	admin := authHTTP.RequireRole("ADMIN")
+
+	r.With(admin).Get("/", h.HandleList)
+	r.With(admin).Post("/", h.HandleCreate)
+	r.With(admin).Get("/events", h.HandleEventTypes)
+	r.With(admin).Get("/{id}", h.HandleGet)
+	r.With(admin).Put("/{id}", h.HandleUpdate)
+	r.With(admin).Delete("/{id}", h.HandleDelete)
+	r.With(admin).Post("/{id}/rotate-secret", h.HandleRotateSecret)
+	r.With(admin).Get("/{id}/deliveries", h.HandleListDeliveries)
+	r.With(admin).Get("/{id}/deliveries/{deliveryId}", h.HandleGetDelivery)
+	r.With(admin).Post("/{id}/deliveries/{deliveryId}/replay", h.HandleReplay)
+}
+
+func parseUUIDString(idStr string) (pgtype.UUID, error) {
+	var pgID pgtype.UUID
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		return pgID, err
+	}
+	pgID.Bytes = parsed
+	pgID.Valid = true
+	return pgID, nil
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		response.Error(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, logic.ErrUnknownEvent), errors.Is(err, logic.ErrUnsafeURL):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.DBError(w, err)
+	}
+}
+
+type EndpointRequest struct {
+	URL         string   `json:"url" validate:"required,http_url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events" validate:"required,min=1,dive,required"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+func (req EndpointRequest) input() logic.EndpointInput {
+	in := logic.EndpointInput{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		in.IsActive = *req.IsActive
+	}
+	return in
+}
+
+// @Summary List Webhook Endpoints
+// @Description Get a paginated list of the tenant's webhook endpoints. Secrets are not included.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Success 200 {object} map[string]interface{} "Paginated endpoint data"
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	endpoints, total, err := h.service.ListEndpoints(r.Context(), tenantID, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list webhook endpoints")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, endpoints, params.Page, params.Size, int(total))
+}
+
+// @Summary List Webhook Event Types
+// @Description Lists the event types endpoints can subscribe to. Subscribing to "*" receives all of them.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string
+// @Router /api/v1/webhooks/events [get]
+func (h *WebhookHandler) HandleEventTypes(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, logic.EventTypes())
+}
+
+// @Summary Register a Webhook Endpoint
+// @Description Registers a URL to receive the listed event types as JSON POSTs. The response contains the signing secret, which is not shown again. Each request carries X-DML-Event, X-DML-Delivery and X-DML-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed with the secret>. Non-2xx responses, including redirects, are retried with exponential backoff. The URL must be a public http(s) address; loopback, private and link-local addresses are refused, also when a host name resolves to one.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EndpointRequest true "Endpoint Payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Unknown event type or non-public URL"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req EndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	endpointID, _ := parseUUIDString(uuid.New().String())
+
+	endpoint, err := h.service.CreateEndpoint(r.Context(), endpointID, tenantID, actorID, req.input())
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, endpoint)
+}
+
+// @Summary Get a Webhook Endpoint
+// @Description Fetch a single webhook endpoint.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(r.Context(), tenantID, endpointID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, endpoint)
+}
+
+// @Summary Update a Webhook Endpoint
+// @Description Changes the URL, event filter or active flag of an endpoint. Pending deliveries to a deactivated endpoint fail.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Param request body EndpointRequest true "Endpoint Payload"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	var req EndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := response.Validate.Struct(&req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(r.Context(), tenantID, actorID, endpointID, req.input())
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, endpoint)
+}
+
+// @Summary Delete a Webhook Endpoint
+// @Description Removes an endpoint and its delivery log.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Success 204
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(r.Context(), tenantID, actorID, endpointID); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Rotate a Webhook Secret
+// @Description Replaces the endpoint's signing secret. The response contains the new secret, which is not shown again; deliveries from now on are signed with it.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) HandleRotateSecret(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	endpoint, err := h.service.RotateSecret(r.Context(), tenantID, actorID, endpointID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, endpoint)
+}
+
+// @Summary List Webhook Deliveries
+// @Description Get a paginated delivery log of an endpoint, newest first, with the payload, attempts, last response status and error of each delivery. Response bodies are not kept.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Items per page"
+// @Param status query string false "pending, delivering, succeeded or failed"
+// @Success 200 {object} map[string]interface{} "Paginated delivery data"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) HandleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	params := query.ParsePagination(r)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", logic.StatusPending, logic.StatusDelivering, logic.StatusSucceeded, logic.StatusFailed:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status filter")
+		return
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(r.Context(), tenantID, endpointID, status, params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list deliveries")
+		return
+	}
+	response.PaginatedJSON(w, http.StatusOK, deliveries, params.Page, params.Size, int(total))
+}
+
+// @Summary Get a Webhook Delivery
+// @Description Fetch a single delivery from an endpoint's log.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Param deliveryId path string true "Delivery UUID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId} [get]
+func (h *WebhookHandler) HandleGetDelivery(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	deliveryID, err := parseUUIDString(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid delivery ID format")
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(r.Context(), tenantID, endpointID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, delivery)
+}
+
+// @Summary Replay a Webhook Delivery
+// @Description Queues the event of a past delivery for sending again, signed with the current secret. The event keeps its original id so receivers can deduplicate.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint UUID"
+// @Param deliveryId path string true "Delivery UUID"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func (h *WebhookHandler) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := authHTTP.GetTenantIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	actorID, ok := authHTTP.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpointID, err := parseUUIDString(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid endpoint ID format")
+		return
+	}
+
+	deliveryID, err := parseUUIDString(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid delivery ID format")
+		return
+	}
+
+	delivery, err := h.service.Replay(r.Context(), tenantID, actorID, endpointID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, delivery)
+}
@@ -0,0 +1,347 @@
+// Package apikeys issues the API keys integrations authenticate with, either on behalf of a
+// tenant service account or as personal keys of a user.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// KeyPrefix starts every API key, so keys are told apart from session tokens and are
+// recognisable when leaked
+const KeyPrefix = "dml_"
+
+// displayPrefixLen is how much of a key is kept in clear to identify it in listings
+const displayPrefixLen = len(KeyPrefix) + 8
+
+var (
+	// ErrInvalidKey is returned when an API key is unknown, revoked, expired or its owner
+	// is deactivated
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrInvalidRoles is returned when a key is given a role that does not exist or, for a
+	// personal key, that its owner does not hold
+	ErrInvalidRoles = errors.New("roles must exist and, for personal keys, be held by the key owner")
+	// ErrExpiryInPast is returned when a key would already be expired
+	ErrExpiryInPast = errors.New("expiresAt must be in the future")
+	// ErrServiceAccountInactive is returned when issuing a key to a deactivated service account
+	ErrServiceAccountInactive = errors.New("service account is deactivated")
+	// ErrRolesExceedIssuer is returned when a service account key would be given a role
+	// granting permissions its issuer does not hold
+	ErrRolesExceedIssuer = errors.New("a service account key cannot be given permissions you do not hold")
+)
+
+// IsAPIKey reports whether a bearer token is an API key rather than a session token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, KeyPrefix)
+}
+
+// KeyRole is a role an API key acts with
+type KeyRole struct {
+	ID   pgtype.UUID `json:"id"`
+	Code string      `json:"code"`
+}
+
+// Key is the API representation of an API key. The key itself is only revealed when it is
+// issued.
+type Key struct {
+	ID               pgtype.UUID        `json:"id"`
+	Name             string             `json:"name"`
+	Prefix           string             `json:"prefix"`
+	Key              string             `json:"key,omitempty"`
+	ServiceAccountID pgtype.UUID        `json:"serviceAccountId"`
+	UserID           pgtype.UUID        `json:"userId"`
+	Roles            []KeyRole          `json:"roles"`
+	ExpiresAt        pgtype.Timestamptz `json:"expiresAt"`
+	LastUsedAt       pgtype.Timestamptz `json:"lastUsedAt"`
+	RevokedAt        pgtype.Timestamptz `json:"revokedAt"`
+	CreatedByUserID  pgtype.UUID        `json:"createdByUserId"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+}
+
+func toKey(k domain.ApiKey, roles []KeyRole) Key {
+	if roles == nil {
+		roles = []KeyRole{}
+	}
+	return Key{
+		ID:               k.ID,
+		Name:             k.Name,
+		Prefix:           k.Prefix,
+		ServiceAccountID: k.ServiceAccountID,
+		UserID:           k.UserID,
+		Roles:            roles,
+		ExpiresAt:        k.ExpiresAt,
+		LastUsedAt:       k.LastUsedAt,
+		RevokedAt:        k.RevokedAt,
+		CreatedByUserID:  k.CreatedByUserID,
+		CreatedAt:        k.CreatedAt,
+	}
+}
+
+// KeyInput describes a key to issue. ExpiresAt is optional.
+type KeyInput struct {
+	Name      string
+	RoleIDs   []pgtype.UUID
+	ExpiresAt *time.Time
+}
+
+// Principal is who a request authenticated with an API key acts as
+type Principal struct {
+	KeyID    pgtype.UUID
+	TenantID pgtype.UUID
+	// UserID is the owner of a personal key and is empty for service account keys
+	UserID           pgtype.UUID
+	ServiceAccountID pgtype.UUID
+	Roles            []string
+}
+
+type APIKeyService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
+}
+
+func NewAPIKeyService(database *db.DB, auditSvc *audit.AuditService) *APIKeyService {
+	return &APIKeyService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
+	}
+}
+
+// newKey generates a key: the prefix followed by 256 random bits in hex
+func newKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return KeyPrefix + hex.EncodeToString(b), nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListKeys lists the tenant's keys, including revoked and expired ones. A valid
+// serviceAccountID or userID narrows the list to that owner's keys.
+func (s *APIKeyService) ListKeys(ctx context.Context, tenantID, serviceAccountID, userID pgtype.UUID) ([]Key, error) {
+	keys, err := s.queries.ListApiKeys(ctx, domain.ListApiKeysParams{
+		TenantID:         tenantID,
+		ServiceAccountID: serviceAccountID,
+		UserID:           userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]pgtype.UUID, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.ID)
+	}
+	rows, err := s.queries.ListApiKeyRoles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[[16]byte][]KeyRole, len(keys))
+	for _, r := range rows {
+		roles[r.ApiKeyID.Bytes] = append(roles[r.ApiKeyID.Bytes], KeyRole{ID: r.RoleID, Code: r.RoleCode})
+	}
+
+	items := make([]Key, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, toKey(k, roles[k.ID.Bytes]))
+	}
+	return items, nil
+}
+
+// IssueServiceAccountKey creates a key for a service account. It may be given the tenant's
+// roles whose permissions the issuing user holds themselves.
+func (s *APIKeyService) IssueServiceAccountKey(ctx context.Context, id, tenantID, actorID, serviceAccountID pgtype.UUID, in KeyInput) (Key, error) {
+	sa, err := s.queries.GetServiceAccount(ctx, domain.GetServiceAccountParams{
+		TenantID: tenantID,
+		ID:       serviceAccountID,
+	})
+	if err != nil {
+		return Key{}, err
+	}
+	if !sa.IsActive {
+		return Key{}, ErrServiceAccountInactive
+	}
+	return s.issue(ctx, domain.CreateApiKeyParams{
+		ID:               id,
+		TenantID:         tenantID,
+		ServiceAccountID: sa.ID,
+		CreatedByUserID:  actorID,
+	}, in)
+}
+
+// IssuePersonalKey creates a key acting as userID. It may only be given roles the user
+// holds, and loses any of them the user is later stripped of.
+func (s *APIKeyService) IssuePersonalKey(ctx context.Context, id, tenantID, userID pgtype.UUID, in KeyInput) (Key, error) {
+	return s.issue(ctx, domain.CreateApiKeyParams{
+		ID:              id,
+		TenantID:        tenantID,
+		UserID:          userID,
+		CreatedByUserID: userID,
+	}, in)
+}
+
+func (s *APIKeyService) issue(ctx context.Context, params domain.CreateApiKeyParams, in KeyInput) (Key, error) {
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(time.Now()) {
+			return Key{}, ErrExpiryInPast
+		}
+		params.ExpiresAt = pgtype.Timestamptz{Time: *in.ExpiresAt, Valid: true}
+	}
+
+	raw, err := newKey()
+	if err != nil {
+		return Key{}, err
+	}
+	params.Name = strings.TrimSpace(in.Name)
+	params.Prefix = raw[:displayPrefixLen]
+	params.KeyHash = hashKey(raw)
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to begin API key transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	roleIDs := make([]pgtype.UUID, 0, len(in.RoleIDs))
+	seen := make(map[[16]byte]bool, len(in.RoleIDs))
+	for _, id := range in.RoleIDs {
+		if !seen[id.Bytes] {
+			seen[id.Bytes] = true
+			roleIDs = append(roleIDs, id)
+		}
+	}
+	grantable, err := qtx.ListGrantableApiKeyRoles(ctx, domain.ListGrantableApiKeyRolesParams{
+		TenantID: params.TenantID,
+		RoleIds:  roleIDs,
+		UserID:   params.UserID,
+	})
+	if err != nil {
+		return Key{}, err
+	}
+	if len(grantable) != len(roleIDs) {
+		return Key{}, ErrInvalidRoles
+	}
+	if params.ServiceAccountID.Valid {
+		// Otherwise an administrator could mint a key holding, say, HR_ADMIN and read the
+		// personal data ADMIN deliberately does not grant
+		held, err := qtx.GetUserRoles(ctx, domain.GetUserRolesParams{
+			TenantID: params.TenantID,
+			UserID:   params.CreatedByUserID,
+		})
+		if err != nil {
+			return Key{}, err
+		}
+		requested := make([]string, 0, len(grantable))
+		for _, r := range grantable {
+			requested = append(requested, r.Code)
+		}
+		if !iam.Covers(held, requested) {
+			return Key{}, ErrRolesExceedIssuer
+		}
+	}
+
+	key, err := qtx.CreateApiKey(ctx, params)
+	if err != nil {
+		return Key{}, err
+	}
+	roles := make([]KeyRole, 0, len(grantable))
+	codes := make([]string, 0, len(grantable))
+	for _, r := range grantable {
+		if err := qtx.AddApiKeyRole(ctx, domain.AddApiKeyRoleParams{ApiKeyID: key.ID, RoleID: r.ID}); err != nil {
+			return Key{}, fmt.Errorf("failed to assign API key role: %w", err)
+		}
+		roles = append(roles, KeyRole{ID: r.ID, Code: r.Code})
+		codes = append(codes, r.Code)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Key{}, fmt.Errorf("failed to commit API key: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, key.TenantID, params.CreatedByUserID, "CREATE", "ApiKeys", key.ID.Bytes, map[string]interface{}{
+			"name":               key.Name,
+			"prefix":             key.Prefix,
+			"service_account_id": key.ServiceAccountID,
+			"user_id":            key.UserID,
+			"roles":              codes,
+			"expires_at":         key.ExpiresAt,
+		})
+	}
+
+	out := toKey(key, roles)
+	out.Key = raw
+	return out, nil
+}
+
+// RevokeKey stops a key from authenticating; it stays listed for reference. A valid
+// ownerID restricts revocation to that user's personal keys.
+func (s *APIKeyService) RevokeKey(ctx context.Context, tenantID, actorID, id, ownerID pgtype.UUID) error {
+	rows, err := s.queries.RevokeApiKey(ctx, domain.RevokeApiKeyParams{
+		TenantID: tenantID,
+		ID:       id,
+		UserID:   ownerID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "ApiKeys", id.Bytes, nil)
+	}
+	return nil
+}
+
+// Authenticate resolves an API key to the principal it acts as
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (Principal, error) {
+	if !IsAPIKey(raw) {
+		return Principal{}, ErrInvalidKey
+	}
+	key, err := s.queries.GetActiveApiKeyByHash(ctx, hashKey(raw))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrInvalidKey
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	roles, err := s.queries.ListApiKeyRoleCodes(ctx, key.ID)
+	if err != nil {
+		return Principal{}, err
+	}
+	if err := s.queries.TouchApiKey(ctx, key.ID); err != nil {
+		log.Printf("api keys failed recording key use: %v", err)
+	}
+	return Principal{
+		KeyID:            key.ID,
+		TenantID:         key.TenantID,
+		UserID:           key.UserID,
+		ServiceAccountID: key.ServiceAccountID,
+		Roles:            roles,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package apikeys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestNewKey(t *testing.T) {
+	a, err := newKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatal("newKey() returned the same key twice")
+	}
+	if !strings.HasPrefix(a, KeyPrefix) || len(a) != len(KeyPrefix)+64 {
+		t.Fatalf("newKey() = %q, want %s followed by 64 hex digits", a, KeyPrefix)
+	}
+	if _, err := hex.DecodeString(strings.TrimPrefix(a, KeyPrefix)); err != nil {
+		t.Fatalf("newKey() body is not hex: %v", err)
+	}
+	if !IsAPIKey(a) {
+		t.Fatal("IsAPIKey() does not recognise a generated key")
+	}
+}
+
+func TestIsAPIKey(t *testing.T) {
+	for token, want := range map[string]bool{
+		"dml_0123abcd":                 true,
+		"eyJhbGciOiJSUzI1NiJ9.e30.sig": false,
+		"DML_0123abcd":                 false,
+		"":                             false,
+	} {
+		if got := IsAPIKey(token); got != want {
+			t.Errorf("IsAPIKey(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestHashKey(t *testing.T) {
+	key := "dml_" + strings.Repeat("ab", 32)
+	sum := sha256.Sum256([]byte(key))
+	if got, want := hashKey(key), hex.EncodeToString(sum[:]); got != want {
+		t.Fatalf("hashKey() = %s, want the SHA-256 hex %s", got, want)
+	}
+	if strings.Contains(hashKey(key), strings.Repeat("ab", 32)) {
+		t.Fatal("hash contains the key")
+	}
+	if hashKey(key) == hashKey(key[:len(key)-1]+"c") {
+		t.Fatal("different keys hash the same")
+	}
+}
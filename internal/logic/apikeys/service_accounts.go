@@ -0,0 +1,114 @@
+package apikeys
+
+import (
+	"context"
+	"strings"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ServiceAccount is the API representation of an integration's identity
+type ServiceAccount struct {
+	ID              pgtype.UUID        `json:"id"`
+	Name            string             `json:"name"`
+	Description     *string            `json:"description"`
+	IsActive        bool               `json:"isActive"`
+	CreatedByUserID pgtype.UUID        `json:"createdByUserId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func toServiceAccount(sa domain.ServiceAccount) ServiceAccount {
+	return ServiceAccount{
+		ID:              sa.ID,
+		Name:            sa.Name,
+		Description:     textPtr(sa.Description),
+		IsActive:        sa.IsActive,
+		CreatedByUserID: sa.CreatedByUserID,
+		CreatedAt:       sa.CreatedAt,
+		UpdatedAt:       sa.UpdatedAt,
+	}
+}
+
+func (s *APIKeyService) ListServiceAccounts(ctx context.Context, tenantID pgtype.UUID) ([]ServiceAccount, error) {
+	rows, err := s.queries.ListServiceAccounts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ServiceAccount, 0, len(rows))
+	for _, sa := range rows {
+		items = append(items, toServiceAccount(sa))
+	}
+	return items, nil
+}
+
+func (s *APIKeyService) GetServiceAccount(ctx context.Context, tenantID, id pgtype.UUID) (ServiceAccount, error) {
+	sa, err := s.queries.GetServiceAccount(ctx, domain.GetServiceAccountParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+	return toServiceAccount(sa), nil
+}
+
+func (s *APIKeyService) CreateServiceAccount(ctx context.Context, id, tenantID, actorID pgtype.UUID, name string, description *string) (ServiceAccount, error) {
+	sa, err := s.queries.CreateServiceAccount(ctx, domain.CreateServiceAccountParams{
+		ID:              id,
+		TenantID:        tenantID,
+		Name:            strings.TrimSpace(name),
+		Description:     optionalText(description),
+		CreatedByUserID: actorID,
+	})
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "ServiceAccounts", id.Bytes, map[string]interface{}{
+			"name":        sa.Name,
+			"description": textPtr(sa.Description),
+		})
+	}
+	return toServiceAccount(sa), nil
+}
+
+// UpdateServiceAccount edits a service account. Deactivating it stops all of its keys from
+// authenticating until it is reactivated.
+func (s *APIKeyService) UpdateServiceAccount(ctx context.Context, tenantID, actorID, id pgtype.UUID, name string, description *string, isActive bool) (ServiceAccount, error) {
+	sa, err := s.queries.UpdateServiceAccount(ctx, domain.UpdateServiceAccountParams{
+		TenantID:    tenantID,
+		ID:          id,
+		Name:        strings.TrimSpace(name),
+		Description: optionalText(description),
+		IsActive:    isActive,
+	})
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "ServiceAccounts", id.Bytes, map[string]interface{}{
+			"name":        sa.Name,
+			"description": textPtr(sa.Description),
+			"is_active":   sa.IsActive,
+		})
+	}
+	return toServiceAccount(sa), nil
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
@@ -15,17 +16,32 @@ import (
 
 // AuditEvent represents the internal payload sent to the logging channel
 type AuditEvent struct {
-	TenantID   pgtype.UUID
-	ActorID    pgtype.UUID
-	Action     string
-	EntityType string
-	EntityID   uuid.UUID
-	Changes    interface{} // Will be serialized to JSONB
+	TenantID pgtype.UUID
+	ActorID  pgtype.UUID
+	// ActorAPIKeyID is the API key the actor authenticated with, if any
+	ActorAPIKeyID pgtype.UUID
+	// ImpersonatorID is the administrator acting as the actor, if any
+	ImpersonatorID pgtype.UUID
+	Action         string
+	EntityType     string
+	EntityID       uuid.UUID
+	Changes        interface{} // Will be serialized to JSONB
+}
+
+// Subscriber is handed every audit entry once it is stored, so other channels such as
+// webhooks can be fed from the same mutations. It runs on the audit worker and should not
+// block for long.
+type Subscriber interface {
+	HandleAuditLog(ctx context.Context, entry domain.AuditLog)
 }
 
 type AuditService struct {
 	queries *domain.Queries
 	events  chan AuditEvent
+	done    chan struct{}
+
+	mu          sync.RWMutex
+	subscribers []Subscriber
 }
 
 // NewAuditService creates a new audit service and starts the background worker pool
@@ -33,25 +49,74 @@ func NewAuditService(database *db.DB) *AuditService {
 	svc := &AuditService{
 		queries: domain.New(database.Pool),
 		events:  make(chan AuditEvent, 1000), // Buffered channel to prevent blocking the HTTP handlers
+		done:    make(chan struct{}),
 	}
 	go svc.worker() // Start background processing
 	return svc
 }
 
 // Log pushes an event to the background channel instantly mapping the HTTP thread execution speed natively.
-func (s *AuditService) Log(tenantID, actorID pgtype.UUID, action, entityType string, entityID uuid.UUID, changes interface{}) {
+// The API key the request authenticated with and any impersonating administrator are taken from ctx.
+func (s *AuditService) Log(ctx context.Context, tenantID, actorID pgtype.UUID, action, entityType string, entityID uuid.UUID, changes interface{}) {
+	keyID, _ := APIKeyFromContext(ctx)
+	impersonatorID, _ := ImpersonatorFromContext(ctx)
 	s.events <- AuditEvent{
-		TenantID:   tenantID,
-		ActorID:    actorID,
-		Action:     action,
-		EntityType: entityType,
-		EntityID:   entityID,
-		Changes:    changes,
+		TenantID:       tenantID,
+		ActorID:        actorID,
+		ActorAPIKeyID:  keyID,
+		ImpersonatorID: impersonatorID,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Changes:        changes,
 	}
 }
 
+type apiKeyContextKey struct{}
+
+// WithAPIKey records on ctx that the request authenticated with an API key, so audit entries
+// logged with it name the key as well as the actor
+func WithAPIKey(ctx context.Context, keyID pgtype.UUID) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, keyID)
+}
+
+// APIKeyFromContext returns the API key set by WithAPIKey
+func APIKeyFromContext(ctx context.Context) (pgtype.UUID, bool) {
+	val, ok := ctx.Value(apiKeyContextKey{}).(pgtype.UUID)
+	return val, ok
+}
+
+type impersonatorContextKey struct{}
+
+// WithImpersonator records on ctx the administrator impersonating the request's user, so
+// audit entries logged with it name both
+func WithImpersonator(ctx context.Context, userID pgtype.UUID) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey{}, userID)
+}
+
+// ImpersonatorFromContext returns the administrator set by WithImpersonator
+func ImpersonatorFromContext(ctx context.Context) (pgtype.UUID, bool) {
+	val, ok := ctx.Value(impersonatorContextKey{}).(pgtype.UUID)
+	return val, ok
+}
+
+// Close stops the worker once every queued event is stored. Command line tools call it
+// before exiting so their audit entries are not lost; nothing may be logged afterwards.
+func (s *AuditService) Close() {
+	close(s.events)
+	<-s.done
+}
+
+// Subscribe registers a subscriber for all audit entries persisted from now on
+func (s *AuditService) Subscribe(sub Subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, sub)
+}
+
 // worker processes the channel stream securely committing records to Postgres natively decoupled from requests
 func (s *AuditService) worker() {
+	defer close(s.done)
 	ctx := context.Background()
 	for event := range s.events {
 		var pgChanges []byte
@@ -72,19 +137,28 @@ func (s *AuditService) worker() {
 		eventIDBytes.Bytes = uuid.New()
 		eventIDBytes.Valid = true
 
-		_, err := s.queries.InsertAuditLog(ctx, domain.InsertAuditLogParams{
-			ID:         eventIDBytes,
-			TenantID:   event.TenantID,
-			ActorID:    event.ActorID,
-			Action:     event.Action,
-			EntityType: event.EntityType,
-			EntityID:   entityIDBytes,
-			Changes:    pgChanges,
+		entry, err := s.queries.InsertAuditLog(ctx, domain.InsertAuditLogParams{
+			ID:             eventIDBytes,
+			TenantID:       event.TenantID,
+			ActorID:        event.ActorID,
+			ActorApiKeyID:  event.ActorAPIKeyID,
+			ImpersonatorID: event.ImpersonatorID,
+			Action:         event.Action,
+			EntityType:     event.EntityType,
+			EntityID:       entityIDBytes,
+			Changes:        pgChanges,
 		})
 
 		if err != nil {
 			log.Printf("audit worker failed persisting record natively: %v", err)
+			continue
+		}
+
+		s.mu.RLock()
+		for _, sub := range s.subscribers {
+			sub.HandleAuditLog(ctx, entry)
 		}
+		s.mu.RUnlock()
 	}
 }
 
@@ -13,17 +13,28 @@ import (
 )
 
 type AuthService struct {
-	queries   *domain.Queries
-	jwtSecret string
+	queries  *domain.Queries
+	keys     *KeySet
+	issuer   string
+	audience string
 }
 
-func NewAuthService(database *db.DB, secret string) *AuthService {
+// NewAuthService creates the service. Tokens are signed with keys and carry issuer and
+// audience, which AuthMiddleware checks.
+func NewAuthService(database *db.DB, keys *KeySet, issuer, audience string) *AuthService {
 	return &AuthService{
-		queries:   domain.New(database.Pool),
-		jwtSecret: secret,
+		queries:  domain.New(database.Pool),
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
 	}
 }
 
+// JWKS returns the public keys session tokens can be verified with
+func (s *AuthService) JWKS() []JWK {
+	return s.keys.JWKS()
+}
+
 func (s *AuthService) HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -38,22 +49,88 @@ type Claims struct {
 	UserID   string   `json:"userId"`
 	TenantID string   `json:"tenantId"`
 	Roles    []string `json:"roles"`
+	// Act is set on impersonation tokens and names the administrator acting as the user
+	Act *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (s *AuthService) AuthenticateUser(ctx context.Context, email, password string) (string, error) {
+// AuthenticateUser checks a password and returns a session token, or an MFA challenge when
+// the user has a second factor or a role that requires one
+func (s *AuthService) AuthenticateUser(ctx context.Context, email, password string) (LoginResult, error) {
 	// 1. Fetch user by email
 	user, err := s.queries.GetUserForLogin(ctx, email)
 	if err != nil {
-		return "", errors.New("invalid credentials") // Prevent user enumeration
+		return LoginResult{}, errors.New("invalid credentials") // Prevent user enumeration
 	}
 
 	// 2. Verify hashed password
 	if !user.PasswordHash.Valid || !s.CheckPassword(password, user.PasswordHash.String) {
-		return "", errors.New("invalid credentials")
+		return LoginResult{}, errors.New("invalid credentials")
+	}
+
+	// Deactivated accounts, e.g. deprovisioned over SCIM, cannot sign in
+	if !user.IsActive {
+		return LoginResult{}, errors.New("invalid credentials")
+	}
+
+	// 3. Require the second factor, or enrolment in one, before issuing a session
+	return s.CompleteLogin(ctx, user)
+}
+
+// CompleteLogin finishes a first-factor sign-in, by password or single sign-on: it returns
+// an MFA challenge when the user has a second factor or a role that requires one, and a
+// session otherwise
+func (s *AuthService) CompleteLogin(ctx context.Context, user domain.User) (LoginResult, error) {
+	mfa, err := s.queries.GetMfaLoginState(ctx, domain.GetMfaLoginStateParams{
+		UserID:   user.ID,
+		TenantID: user.TenantID,
+	})
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if mfa.Enrolled || mfa.Required {
+		challenge, err := s.issueChallenge(user, !mfa.Enrolled)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		return LoginResult{
+			MFARequired:          mfa.Enrolled,
+			MFAEnrolmentRequired: !mfa.Enrolled,
+			MFAToken:             challenge,
+		}, nil
+	}
+
+	return s.StartSession(ctx, user)
+}
+
+// StartSession issues a session token for an authenticated user and describes the session
+// to the front-end. It completes password and MFA sign-ins.
+func (s *AuthService) StartSession(ctx context.Context, user domain.User) (LoginResult, error) {
+	token, roles, err := s.issue(ctx, user, time.Now().Add(24*time.Hour), nil)
+	if err != nil {
+		return LoginResult{}, err
 	}
 
-	// 3. Fetch User Roles
+	var displayName *string
+	if user.DisplayName.Valid {
+		displayName = &user.DisplayName.String
+	}
+	tenantID, _ := uuid.FromBytes(user.TenantID.Bytes[:])
+	return LoginResult{
+		Token: token,
+		User: &SessionUser{
+			ID:          user.ID,
+			Email:       user.Email,
+			DisplayName: displayName,
+		},
+		Roles:    roles,
+		TenantID: tenantID.String(),
+	}, nil
+}
+
+// issue signs a session token and returns it with the roles it carries
+func (s *AuthService) issue(ctx context.Context, user domain.User, expirationTime time.Time, act *Actor) (string, []string, error) {
+	// Fetch User Roles
 	roles, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{
 		TenantID: user.TenantID,
 		UserID:   user.ID,
@@ -62,9 +139,6 @@ func (s *AuthService) AuthenticateUser(ctx context.Context, email, password stri
 		roles = []string{} // Default to empty array on failure
 	}
 
-	// 4. Generate JWT Token
-	expirationTime := time.Now().Add(24 * time.Hour)
-
 	// Convert UUID bytes directly to 36 char string format
 	parsedUserID, _ := uuid.FromBytes(user.ID.Bytes[:])
 	userIDStr := parsedUserID.String()
@@ -76,17 +150,20 @@ func (s *AuthService) AuthenticateUser(ctx context.Context, email, password stri
 		UserID:   userIDStr,
 		TenantID: tenantIDStr,
 		Roles:    roles,
+		Act:      act,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   userIDStr,
+			Audience:  jwt.ClaimStrings{s.audience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := s.keys.Sign(claims)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return tokenString, nil
+	return tokenString, roles, nil
 }
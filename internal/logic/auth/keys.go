@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA key accepted for signing tokens
+const minRSABits = 2048
+
+// KeyOptions locates the token signing keys
+type KeyOptions struct {
+	// Dir holds one PEM key per file, named <kid>.pem. Private keys (RSA or Ed25519) can
+	// sign; public keys only verify, so a retired key keeps its tokens valid until they
+	// expire. When empty an ephemeral key is generated, for development only.
+	Dir string
+	// SigningKeyID picks the private key new tokens are signed with. It defaults to the
+	// private key whose kid sorts last, so dated names such as 2026-10.pem rotate naturally.
+	SigningKeyID string
+}
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	public  interface{}
+	private interface{} // nil for verify-only keys
+}
+
+// KeySet signs session tokens with one key and verifies them against every loaded key
+type KeySet struct {
+	keys   map[string]*signingKey
+	kids   []string
+	signer *signingKey
+}
+
+// LoadKeySet reads the keys from opts.Dir, or generates an ephemeral Ed25519 key when no
+// directory is configured
+func LoadKeySet(opts KeyOptions) (*KeySet, error) {
+	if opts.Dir == "" {
+		log.Println("WARNING: JWT_KEYS_DIR is not set. Signing tokens with an ephemeral key; sessions end on restart!")
+		return generateKeySet()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(opts.Dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+	for _, path := range paths {
+		k, err := readKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ks.keys[k.kid] = k
+		ks.kids = append(ks.kids, k.kid)
+		if k.private != nil && opts.SigningKeyID == "" {
+			ks.signer = k
+		}
+	}
+
+	if opts.SigningKeyID != "" {
+		k, ok := ks.keys[opts.SigningKeyID]
+		if !ok || k.private == nil {
+			return nil, fmt.Errorf("signing key %q not found among the private keys in %s", opts.SigningKeyID, opts.Dir)
+		}
+		ks.signer = k
+	}
+	if ks.signer == nil {
+		return nil, fmt.Errorf("no private key in %s", opts.Dir)
+	}
+	return ks, nil
+}
+
+func generateKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	k := &signingKey{
+		kid:     "ephemeral-" + hex.EncodeToString(id),
+		method:  jwt.SigningMethodEdDSA,
+		public:  public,
+		private: private,
+	}
+	return &KeySet{keys: map[string]*signingKey{k.kid: k}, kids: []string{k.kid}, signer: k}, nil
+}
+
+func readKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	k := &signingKey{kid: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.public, k.private = jwt.SigningMethodRS256, &key.PublicKey, key
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, key
+	case ed25519.PrivateKey:
+		k.method, k.public, k.private = jwt.SigningMethodEdDSA, key.Public(), key
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, key
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use RSA or Ed25519", parsed)
+	}
+	if pub, ok := k.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key is %d bits; at least %d are required", pub.N.BitLen(), minRSABits)
+	}
+	return k, nil
+}
+
+// Sign signs claims with the current signing key, naming it in the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signer.method, claims)
+	token.Header["kid"] = ks.signer.kid
+	return token.SignedString(ks.signer.private)
+}
+
+// Keyfunc resolves the verification key for a token from its kid header. It is meant for
+// jwt.Parse together with Methods.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign %s", kid, token.Method.Alg())
+	}
+	return k.public, nil
+}
+
+// Methods lists the algorithms tokens may be signed with
+func (ks *KeySet) Methods() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517, RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the public half of the key set, for services that verify tokens
+func (ks *KeySet) JWKS() []JWK {
+	out := make([]JWK, 0, len(ks.kids))
+	for _, kid := range ks.kids {
+		k := ks.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		out = append(out, jwk)
+	}
+	return out
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// writePrivateKey stores key as <kid>.pem in PKCS#8 form and returns it
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) interface{} {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return key
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    "dml",
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+// verify checks a token's signature and algorithm as AuthMiddleware does
+func verify(ks *KeySet, token string) error {
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, ks.Keyfunc, jwt.WithValidMethods(ks.Methods()))
+	return err
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestLoadKeySetSignsWithLastPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "2026-01", newRSAKey(t, 2048))
+	writePrivateKey(t, dir, "2026-06", newEd25519Key(t))
+	// A public key only verifies, so it is never picked to sign even though it sorts last
+	writePublicKey(t, dir, "2027-01", newEd25519Key(t).Public())
+
+	ks, err := LoadKeySet(KeyOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, token); kid != "2026-06" {
+		t.Fatalf("signed with %q, want 2026-06", kid)
+	}
+	if err := verify(ks, token); err != nil {
+		t.Fatalf("token does not verify: %v", err)
+	}
+}
+
+func TestLoadKeySetSigningKeyID(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "a", newEd25519Key(t))
+	writePrivateKey(t, dir, "b", newEd25519Key(t))
+	writePublicKey(t, dir, "c", newEd25519Key(t).Public())
+
+	ks, err := LoadKeySet(KeyOptions{Dir: dir, SigningKeyID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, token); kid != "a" {
+		t.Fatalf("signed with %q, want a", kid)
+	}
+
+	if _, err := LoadKeySet(KeyOptions{Dir: dir, SigningKeyID: "missing"}); err == nil {
+		t.Error("LoadKeySet() accepted an unknown signing key")
+	}
+	if _, err := LoadKeySet(KeyOptions{Dir: dir, SigningKeyID: "c"}); err == nil {
+		t.Error("LoadKeySet() accepted a public key as the signing key")
+	}
+}
+
+func TestLoadKeySetRefusesWeakOrMissingKeys(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "weak", newRSAKey(t, 1024))
+	if _, err := LoadKeySet(KeyOptions{Dir: dir}); err == nil {
+		t.Error("LoadKeySet() accepted a 1024-bit RSA key")
+	}
+
+	dir = t.TempDir()
+	writePublicKey(t, dir, "only-public", newEd25519Key(t).Public())
+	if _, err := LoadKeySet(KeyOptions{Dir: dir}); err == nil {
+		t.Error("LoadKeySet() accepted a directory without a private key")
+	}
+}
+
+func TestRotationKeepsOldTokensValid(t *testing.T) {
+	dir := t.TempDir()
+	old := writePrivateKey(t, dir, "2026-01", newRSAKey(t, 2048)).(*rsa.PrivateKey)
+	before, err := LoadKeySet(KeyOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: a new private key is added and the old one is kept, public half only
+	dir = t.TempDir()
+	writePublicKey(t, dir, "2026-01", &old.PublicKey)
+	writePrivateKey(t, dir, "2026-07", newEd25519Key(t))
+	after, err := LoadKeySet(KeyOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verify(after, oldToken); err != nil {
+		t.Fatalf("token signed before rotation no longer verifies: %v", err)
+	}
+	newToken, err := after.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, newToken); kid != "2026-07" {
+		t.Fatalf("signed with %q after rotation, want 2026-07", kid)
+	}
+	if err := verify(before, newToken); err == nil {
+		t.Fatal("a key set without the new key verified its token")
+	}
+}
+
+func TestKeyfuncRefusesUnknownKeysAndAlgorithmSwaps(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writePrivateKey(t, dir, "rsa", newRSAKey(t, 2048)).(*rsa.PrivateKey)
+	writePrivateKey(t, dir, "ed", newEd25519Key(t))
+	ks, err := LoadKeySet(KeyOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		tok := jwt.NewWithClaims(method, testClaims())
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	cases := map[string]string{
+		"unknown kid":    sign(jwt.SigningMethodEdDSA, "other", newEd25519Key(t)),
+		"no kid":         sign(jwt.SigningMethodEdDSA, "", newEd25519Key(t)),
+		"forged":         sign(jwt.SigningMethodEdDSA, "ed", newEd25519Key(t)),
+		"wrong alg":      sign(jwt.SigningMethodEdDSA, "rsa", newEd25519Key(t)),
+		"hmac with key":  sign(jwt.SigningMethodHS256, "rsa", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)),
+		"other rsa algo": sign(jwt.SigningMethodRS512, "rsa", rsaKey),
+	}
+	for name, token := range cases {
+		if err := verify(ks, token); err == nil {
+			t.Errorf("%s: token verified", name)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writePrivateKey(t, dir, "rsa", newRSAKey(t, 2048)).(*rsa.PrivateKey)
+	edKey := writePrivateKey(t, dir, "ed", newEd25519Key(t)).(ed25519.PrivateKey)
+	ks, err := LoadKeySet(KeyOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byKid := map[string]JWK{}
+	for _, k := range ks.JWKS() {
+		byKid[k.Kid] = k
+	}
+	if len(byKid) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2", len(byKid))
+	}
+
+	r := byKid["rsa"]
+	if r.Kty != "RSA" || r.Alg != "RS256" || r.Use != "sig" {
+		t.Errorf("RSA JWK = %+v", r)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(r.N)
+	e, _ := base64.RawURLEncoding.DecodeString(r.E)
+	if new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != rsaKey.E {
+		t.Error("RSA JWK does not match the public key")
+	}
+
+	ed := byKid["ed"]
+	if ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(ed.X)
+	if !edKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Error("Ed25519 JWK does not match the public key")
+	}
+}
+
+func TestMFAChallengeIsNotASession(t *testing.T) {
+	ks, err := LoadKeySet(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &AuthService{keys: ks, issuer: "dml", audience: "dml-api"}
+	user := domain.User{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TenantID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+	}
+
+	token, err := s.issueChallenge(user, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := s.ParseMFAChallenge(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.UserID != user.ID || challenge.TenantID != user.TenantID || !challenge.Enrol {
+		t.Fatalf("ParseMFAChallenge() = %+v", challenge)
+	}
+
+	// The challenge is not accepted where a session token is expected
+	_, err = jwt.ParseWithClaims(token, &Claims{}, ks.Keyfunc,
+		jwt.WithValidMethods(ks.Methods()), jwt.WithAudience(s.audience))
+	if err == nil {
+		t.Fatal("MFA challenge passed as a session token")
+	}
+
+	// Nor is a session token accepted as a challenge
+	session, err := ks.Sign(&Claims{
+		UserID:   uuid.UUID(user.ID.Bytes).String(),
+		TenantID: uuid.UUID(user.TenantID.Bytes).String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   uuid.UUID(user.ID.Bytes).String(),
+			Audience:  jwt.ClaimStrings{s.audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ParseMFAChallenge(session); err == nil {
+		t.Fatal("session token passed as an MFA challenge")
+	}
+}
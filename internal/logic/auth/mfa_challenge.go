@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// mfaChallengeTTL is how long a user has to enter their code after the first sign-in step
+const mfaChallengeTTL = 5 * time.Minute
+
+// mfaAudience marks challenge tokens. AuthMiddleware only accepts the session audience, so a
+// challenge never passes as a session.
+const mfaAudience = "mfa"
+
+// ErrInvalidChallenge is returned when an MFA token is malformed, forged or expired
+var ErrInvalidChallenge = errors.New("invalid or expired MFA token")
+
+// LoginResult is the outcome of the first sign-in step, by password or single sign-on.
+// Token, with the signed-in user, their roles and tenant, is set when no second factor is
+// needed; otherwise MFAToken carries the user on to verification or enrolment.
+type LoginResult struct {
+	Token                string       `json:"token,omitempty"`
+	User                 *SessionUser `json:"user,omitempty"`
+	Roles                []string     `json:"roles,omitempty"`
+	TenantID             string       `json:"tenantId,omitempty"`
+	MFARequired          bool         `json:"mfaRequired,omitempty"`
+	MFAEnrolmentRequired bool         `json:"mfaEnrolmentRequired,omitempty"`
+	MFAToken             string       `json:"mfaToken,omitempty"`
+}
+
+// SessionUser identifies the user a session was started for
+type SessionUser struct {
+	ID          pgtype.UUID `json:"id"`
+	Email       string      `json:"email"`
+	DisplayName *string     `json:"displayName"`
+}
+
+// MFAChallenge is a short-lived token proving the first sign-in step passed. Enrol is set
+// when the user must set up a second factor before signing in.
+type MFAChallenge struct {
+	UserID   pgtype.UUID `json:"-"`
+	TenantID pgtype.UUID `json:"-"`
+	Enrol    bool        `json:"enrol"`
+}
+
+type challengeClaims struct {
+	TenantID string `json:"tenantId"`
+	Enrol    bool   `json:"enrol"`
+	jwt.RegisteredClaims
+}
+
+func (s *AuthService) issueChallenge(user domain.User, enrol bool) (string, error) {
+	now := time.Now()
+	claims := &challengeClaims{
+		TenantID: uuid.UUID(user.TenantID.Bytes).String(),
+		Enrol:    enrol,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   uuid.UUID(user.ID.Bytes).String(),
+			Audience:  jwt.ClaimStrings{mfaAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	return s.keys.Sign(claims)
+}
+
+// ParseMFAChallenge verifies a challenge token from the first sign-in step
+func (s *AuthService) ParseMFAChallenge(token string) (MFAChallenge, error) {
+	claims := &challengeClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Methods()),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(mfaAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return MFAChallenge{}, ErrInvalidChallenge
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return MFAChallenge{}, ErrInvalidChallenge
+	}
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return MFAChallenge{}, ErrInvalidChallenge
+	}
+	return MFAChallenge{
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		TenantID: pgtype.UUID{Bytes: tenantID, Valid: true},
+		Enrol:    claims.Enrol,
+	}, nil
+}
@@ -0,0 +1,252 @@
+package capa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/tasks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ActionInput holds the fields of a corrective, preventive or containment action item
+type ActionInput struct {
+	Kind               string
+	Description        string
+	AssigneeEmployeeID pgtype.UUID
+	DueDate            time.Time
+}
+
+// editableNCR loads an NCR that can still take action item changes. Once in verification
+// the actions are frozen; a failed effectiveness check reopens them via implementation.
+func (s *NCRService) editableNCR(ctx context.Context, tenantID, ncrID pgtype.UUID) (domain.Ncr, error) {
+	ncr, err := s.queries.GetNCR(ctx, domain.GetNCRParams{
+		TenantID: tenantID,
+		ID:       ncrID,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if isTerminal(ncr.Status) {
+		return domain.Ncr{}, ErrNCRClosed
+	}
+	if ncr.Status == StatusVerification {
+		return domain.Ncr{}, ErrActionsLocked
+	}
+	return ncr, nil
+}
+
+// actionEntity is the audit log entity type of action items, also used for their tasks
+const actionEntity = "NCRActions"
+
+// AddAction assigns a new action item on an NCR to an employee and puts it in their task inbox
+func (s *NCRService) AddAction(ctx context.Context, id, tenantID, actorID, ncrID pgtype.UUID, in ActionInput) (domain.NcrAction, error) {
+	ncr, err := s.editableNCR(ctx, tenantID, ncrID)
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+	if err := s.checkEmployee(ctx, s.queries, tenantID, in.AssigneeEmployeeID); err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to begin action transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	action, err := qtx.CreateNCRAction(ctx, domain.CreateNCRActionParams{
+		ID:                 id,
+		TenantID:           tenantID,
+		NcrID:              ncrID,
+		Kind:               in.Kind,
+		Description:        in.Description,
+		AssigneeEmployeeID: in.AssigneeEmployeeID,
+		DueDate:            pgtype.Date{Time: in.DueDate, Valid: true},
+		CreatedByUserID:    actorID,
+	})
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	if s.taskSvc != nil {
+		var taskID pgtype.UUID
+		taskID.Bytes = uuid.New()
+		taskID.Valid = true
+
+		if _, err := s.taskSvc.CreateTaskTx(ctx, qtx, taskID, tenantID, actorID, tasks.TaskInput{
+			EntityType:         actionEntity,
+			EntityID:           id,
+			Kind:               tasks.KindAction,
+			Title:              actionTaskTitle(ncr, in.Kind),
+			Description:        &in.Description,
+			AssigneeEmployeeID: in.AssigneeEmployeeID,
+			DueDate:            &in.DueDate,
+		}); err != nil {
+			return domain.NcrAction{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to commit action: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", actionEntity, id.Bytes, map[string]interface{}{
+			"ncr_id":      ncrID,
+			"kind":        in.Kind,
+			"assignee_id": in.AssigneeEmployeeID,
+			"due_date":    in.DueDate.Format("2006-01-02"),
+		})
+	}
+	return action, nil
+}
+
+// UpdateAction changes the description, assignee or due date of an open action item. Its
+// open task follows the new assignee and due date.
+func (s *NCRService) UpdateAction(ctx context.Context, tenantID, actorID, ncrID, id pgtype.UUID, in ActionInput) (domain.NcrAction, error) {
+	ncr, err := s.editableNCR(ctx, tenantID, ncrID)
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	current, err := s.queries.GetNCRAction(ctx, domain.GetNCRActionParams{
+		TenantID: tenantID,
+		NcrID:    ncrID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+	if current.Status != ActionOpen {
+		return domain.NcrAction{}, ErrActionClosed
+	}
+	if err := s.checkEmployee(ctx, s.queries, tenantID, in.AssigneeEmployeeID); err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to begin action transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	action, err := qtx.UpdateNCRAction(ctx, domain.UpdateNCRActionParams{
+		TenantID:           tenantID,
+		NcrID:              ncrID,
+		ID:                 id,
+		Description:        in.Description,
+		AssigneeEmployeeID: in.AssigneeEmployeeID,
+		DueDate:            pgtype.Date{Time: in.DueDate, Valid: true},
+	})
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	if s.taskSvc != nil && (current.AssigneeEmployeeID != in.AssigneeEmployeeID || !current.DueDate.Time.Equal(in.DueDate)) {
+		if err := s.taskSvc.ReassignEntityTasksTx(ctx, qtx, tenantID, actionEntity, id, in.AssigneeEmployeeID, &in.DueDate, actionTaskTitle(ncr, current.Kind)); err != nil {
+			return domain.NcrAction{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to commit action: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", actionEntity, id.Bytes, map[string]interface{}{
+			"ncr_id":      ncrID,
+			"assignee_id": map[string]interface{}{"from": current.AssigneeEmployeeID, "to": in.AssigneeEmployeeID},
+			"due_date":    in.DueDate.Format("2006-01-02"),
+		})
+	}
+	return action, nil
+}
+
+// SetActionStatus marks an open action item done or cancelled and closes its task. Completing
+// an action is reserved for its assignee or an administrator; cancelling is an administrator decision.
+func (s *NCRService) SetActionStatus(ctx context.Context, tenantID, actorID, ncrID, id pgtype.UUID, status string, notes *string, asAdmin bool) (domain.NcrAction, error) {
+	if _, err := s.editableNCR(ctx, tenantID, ncrID); err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	current, err := s.queries.GetNCRAction(ctx, domain.GetNCRActionParams{
+		TenantID: tenantID,
+		NcrID:    ncrID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+	if current.Status != ActionOpen {
+		return domain.NcrAction{}, ErrActionClosed
+	}
+
+	if !asAdmin {
+		if status != ActionDone {
+			return domain.NcrAction{}, ErrNotAssignee
+		}
+		actor, err := s.queries.GetUser(ctx, domain.GetUserParams{
+			TenantID: tenantID,
+			ID:       actorID,
+		})
+		if err != nil {
+			return domain.NcrAction{}, fmt.Errorf("failed to load actor: %w", err)
+		}
+		if actor.EmployeeID != current.AssigneeEmployeeID {
+			return domain.NcrAction{}, ErrNotAssignee
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to begin action transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	action, err := qtx.SetNCRActionStatus(ctx, domain.SetNCRActionStatusParams{
+		TenantID:        tenantID,
+		NcrID:           ncrID,
+		ID:              id,
+		Status:          status,
+		CompletionNotes: optionalText(notes),
+	})
+	if err != nil {
+		return domain.NcrAction{}, err
+	}
+
+	if s.taskSvc != nil {
+		taskStatus := tasks.StatusCompleted
+		if status == ActionCancelled {
+			taskStatus = tasks.StatusCancelled
+		}
+		if err := s.taskSvc.CloseEntityTasksTx(ctx, qtx, tenantID, actorID, actionEntity, id, taskStatus, notes); err != nil {
+			return domain.NcrAction{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.NcrAction{}, fmt.Errorf("failed to commit action: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", actionEntity, id.Bytes, map[string]interface{}{
+			"ncr_id": ncrID,
+			"status": map[string]interface{}{"from": current.Status, "to": status},
+			"notes":  notes,
+		})
+	}
+	return action, nil
+}
+
+func actionTaskTitle(ncr domain.Ncr, kind string) string {
+	return fmt.Sprintf("%s: %s action", Reference(ncr.Number), kind)
+}
@@ -0,0 +1,649 @@
+package capa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/tasks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrInvalidTransition is returned when the requested status is not reachable from the current one
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+
+	// ErrNCRClosed is returned when editing an NCR that is closed or cancelled
+	ErrNCRClosed = errors.New("non-conformance is closed or cancelled")
+
+	// ErrRootCauseRequired is returned when planning actions before the root cause is recorded
+	ErrRootCauseRequired = errors.New("root cause must be recorded before actions are planned")
+
+	// ErrNoActions is returned when starting implementation without a corrective or preventive action
+	ErrNoActions = errors.New("at least one corrective or preventive action is required")
+
+	// ErrActionsOpen is returned when moving to verification while action items are still open
+	ErrActionsOpen = errors.New("all action items must be completed or cancelled first")
+
+	// ErrNotVerified is returned when closing an NCR whose actions have not been verified as effective
+	ErrNotVerified = errors.New("effectiveness must be verified before the non-conformance is closed")
+
+	// ErrNotInVerification is returned when verifying effectiveness outside the verification stage
+	ErrNotInVerification = errors.New("non-conformance is not awaiting verification")
+
+	// ErrEmployeeNotFound is returned when a reporter, owner or assignee is not an employee of the tenant
+	ErrEmployeeNotFound = errors.New("employee does not exist or is inaccessible")
+
+	// ErrDepartmentNotAtSite is returned when the department does not operate at the business unit
+	ErrDepartmentNotAtSite = errors.New("department does not operate at the selected business unit")
+
+	// ErrActionClosed is returned when changing an action item that is already done or cancelled
+	ErrActionClosed = errors.New("action item is already done or cancelled")
+
+	// ErrActionsLocked is returned when changing action items while effectiveness is being verified
+	ErrActionsLocked = errors.New("action items cannot change during verification")
+
+	// ErrNotAssignee is returned when someone other than the assignee or an administrator completes an action
+	ErrNotAssignee = errors.New("only the assignee or an administrator can complete this action")
+
+	// ErrAdminRequired is returned when someone other than an administrator verifies
+	// effectiveness, or closes or cancels an NCR
+	ErrAdminRequired = errors.New("only an administrator can verify, close or cancel a non-conformance")
+
+	// ErrAssigneeVerifier is returned when an assignee of the NCR's actions verifies their effectiveness
+	ErrAssigneeVerifier = errors.New("assignees of the actions cannot verify their effectiveness")
+)
+
+// numberRetries bounds how often creating an NCR is retried when two writers pick the same number
+const numberRetries = 3
+
+type NCRService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	taskSvc  *tasks.TaskService
+	auditSvc *audit.AuditService
+}
+
+func NewNCRService(database *db.DB, taskSvc *tasks.TaskService, auditSvc *audit.AuditService) *NCRService {
+	return &NCRService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		taskSvc:  taskSvc,
+		auditSvc: auditSvc,
+	}
+}
+
+// Reference formats an NCR number for display, e.g. NCR-000042
+func Reference(number int32) string {
+	return fmt.Sprintf("NCR-%06d", number)
+}
+
+// NCRInput holds the editable fields of an NCR. The reporter is only used on creation.
+type NCRInput struct {
+	Title                string
+	Description          string
+	BusinessUnitID       pgtype.UUID
+	DepartmentID         pgtype.UUID
+	ReportedByEmployeeID pgtype.UUID
+	OwnerEmployeeID      pgtype.UUID
+	DetectedOn           time.Time
+	Severity             string
+	Classification       string
+	ContainmentAction    *string
+}
+
+// CreateNCR raises a non-conformance in the open status and assigns it the tenant's next
+// number. Without an explicit reporter the actor's own employee record is used.
+func (s *NCRService) CreateNCR(ctx context.Context, id, tenantID, actorID pgtype.UUID, in NCRInput) (domain.Ncr, error) {
+	if !in.ReportedByEmployeeID.Valid {
+		actor, err := s.queries.GetUser(ctx, domain.GetUserParams{
+			TenantID: tenantID,
+			ID:       actorID,
+		})
+		if err != nil {
+			return domain.Ncr{}, fmt.Errorf("failed to load reporter: %w", err)
+		}
+		in.ReportedByEmployeeID = actor.EmployeeID
+	}
+	if err := s.checkReferences(ctx, s.queries, tenantID, in); err != nil {
+		return domain.Ncr{}, err
+	}
+	if !in.ReportedByEmployeeID.Valid {
+		return domain.Ncr{}, ErrEmployeeNotFound
+	}
+	if err := s.checkEmployee(ctx, s.queries, tenantID, in.ReportedByEmployeeID); err != nil {
+		return domain.Ncr{}, err
+	}
+
+	var ncr domain.Ncr
+	var err error
+	for attempt := 0; attempt < numberRetries; attempt++ {
+		ncr, err = s.createNCR(ctx, id, tenantID, actorID, in)
+		if !isNumberClash(err) {
+			break
+		}
+	}
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "NCRs", id.Bytes, map[string]interface{}{
+			"reference":      Reference(ncr.Number),
+			"title":          ncr.Title,
+			"severity":       ncr.Severity,
+			"classification": ncr.Classification,
+		})
+	}
+	return ncr, nil
+}
+
+func (s *NCRService) createNCR(ctx context.Context, id, tenantID, actorID pgtype.UUID, in NCRInput) (domain.Ncr, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to begin NCR transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	number, err := qtx.NextNCRNumber(ctx, tenantID)
+	if err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to allocate NCR number: %w", err)
+	}
+
+	ncr, err := qtx.CreateNCR(ctx, domain.CreateNCRParams{
+		ID:                   id,
+		TenantID:             tenantID,
+		Number:               number,
+		Title:                in.Title,
+		Description:          in.Description,
+		BusinessUnitID:       in.BusinessUnitID,
+		DepartmentID:         in.DepartmentID,
+		ReportedByEmployeeID: in.ReportedByEmployeeID,
+		OwnerEmployeeID:      in.OwnerEmployeeID,
+		DetectedOn:           pgtype.Date{Time: in.DetectedOn, Valid: true},
+		Severity:             in.Severity,
+		Classification:       in.Classification,
+		ContainmentAction:    optionalText(in.ContainmentAction),
+		CreatedByUserID:      actorID,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if err := recordHistory(ctx, qtx, tenantID, actorID, id, "", StatusOpen, nil); err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to commit NCR: %w", err)
+	}
+	return ncr, nil
+}
+
+// UpdateNCR replaces an NCR's descriptive fields while it is still in progress
+func (s *NCRService) UpdateNCR(ctx context.Context, tenantID, actorID, id pgtype.UUID, in NCRInput) (domain.Ncr, error) {
+	current, err := s.queries.GetNCR(ctx, domain.GetNCRParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if isTerminal(current.Status) {
+		return domain.Ncr{}, ErrNCRClosed
+	}
+	if err := s.checkReferences(ctx, s.queries, tenantID, in); err != nil {
+		return domain.Ncr{}, err
+	}
+
+	ncr, err := s.queries.UpdateNCR(ctx, domain.UpdateNCRParams{
+		TenantID:        tenantID,
+		ID:              id,
+		Title:           in.Title,
+		Description:     in.Description,
+		BusinessUnitID:  in.BusinessUnitID,
+		DepartmentID:    in.DepartmentID,
+		OwnerEmployeeID: in.OwnerEmployeeID,
+		DetectedOn:      pgtype.Date{Time: in.DetectedOn, Valid: true},
+		Severity:        in.Severity,
+		Classification:  in.Classification,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "NCRs", id.Bytes, map[string]interface{}{
+			"title":          in.Title,
+			"severity":       map[string]interface{}{"from": current.Severity, "to": in.Severity},
+			"classification": map[string]interface{}{"from": current.Classification, "to": in.Classification},
+		})
+	}
+	return ncr, err
+}
+
+// RootCauseInput holds the containment and root cause analysis of an NCR
+type RootCauseInput struct {
+	ContainmentAction *string
+	Method            *string
+	RootCause         *string
+}
+
+// RecordRootCause stores the containment action and root cause analysis of an NCR
+func (s *NCRService) RecordRootCause(ctx context.Context, tenantID, actorID, id pgtype.UUID, in RootCauseInput) (domain.Ncr, error) {
+	current, err := s.queries.GetNCR(ctx, domain.GetNCRParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if isTerminal(current.Status) {
+		return domain.Ncr{}, ErrNCRClosed
+	}
+
+	ncr, err := s.queries.UpdateNCRRootCause(ctx, domain.UpdateNCRRootCauseParams{
+		TenantID:          tenantID,
+		ID:                id,
+		ContainmentAction: optionalText(in.ContainmentAction),
+		RootCauseMethod:   optionalText(in.Method),
+		RootCause:         optionalText(in.RootCause),
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "NCRs", id.Bytes, map[string]interface{}{
+			"root_cause_method":  in.Method,
+			"root_cause":         in.RootCause,
+			"containment_action": in.ContainmentAction,
+		})
+	}
+	return ncr, err
+}
+
+// Transition moves an NCR to another status. Besides the allowed moves listed in
+// AllowedTransitions, each stage has an entry condition: a recorded root cause before
+// action_planned, a corrective or preventive action before implementation, no open actions
+// before verification, and a positive effectiveness check before closed. Closing,
+// cancelling and leaving verification are reserved for administrators.
+func (s *NCRService) Transition(ctx context.Context, tenantID, actorID, id pgtype.UUID, to string, comment *string, asAdmin bool) (domain.Ncr, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to begin transition transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	current, err := qtx.GetNCRForUpdate(ctx, domain.GetNCRForUpdateParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if !asAdmin && (to == StatusClosed || to == StatusCancelled || current.Status == StatusVerification) {
+		return domain.Ncr{}, ErrAdminRequired
+	}
+
+	ncr, err := s.transition(ctx, qtx, tenantID, actorID, current, to, comment)
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to commit transition: %w", err)
+	}
+
+	s.logTransition(ctx, tenantID, actorID, current, to, comment)
+	return ncr, nil
+}
+
+func (s *NCRService) transition(ctx context.Context, q *domain.Queries, tenantID, actorID pgtype.UUID, current domain.Ncr, to string, comment *string) (domain.Ncr, error) {
+	if !canTransition(current.Status, to) {
+		return domain.Ncr{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, current.Status, to)
+	}
+
+	switch to {
+	case StatusActionPlanned:
+		if !current.RootCause.Valid || strings.TrimSpace(current.RootCause.String) == "" {
+			return domain.Ncr{}, ErrRootCauseRequired
+		}
+	case StatusImplementation, StatusVerification:
+		actions, err := q.ListNCRActions(ctx, domain.ListNCRActionsParams{
+			TenantID: tenantID,
+			NcrID:    current.ID,
+		})
+		if err != nil {
+			return domain.Ncr{}, fmt.Errorf("failed to load actions: %w", err)
+		}
+		if err := checkActions(actions, to); err != nil {
+			return domain.Ncr{}, err
+		}
+	case StatusClosed:
+		if !current.IsEffective.Valid || !current.IsEffective.Bool {
+			return domain.Ncr{}, ErrNotVerified
+		}
+	}
+
+	ncr, err := q.UpdateNCRStatus(ctx, domain.UpdateNCRStatusParams{
+		TenantID: tenantID,
+		ID:       current.ID,
+		Status:   to,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if err := recordHistory(ctx, q, tenantID, actorID, current.ID, current.Status, to, comment); err != nil {
+		return domain.Ncr{}, err
+	}
+	return ncr, nil
+}
+
+// checkActions applies the action item entry conditions of implementation and verification
+func checkActions(actions []domain.ListNCRActionsRow, to string) error {
+	var planned, open, done int
+	for _, a := range actions {
+		switch a.Status {
+		case ActionOpen:
+			open++
+		case ActionDone:
+			done++
+		}
+		if a.Status != ActionCancelled && a.Kind != "containment" {
+			planned++
+		}
+	}
+
+	switch to {
+	case StatusImplementation:
+		if planned == 0 {
+			return ErrNoActions
+		}
+	case StatusVerification:
+		if open > 0 {
+			return fmt.Errorf("%w (%d open)", ErrActionsOpen, open)
+		}
+		if done == 0 {
+			return ErrNoActions
+		}
+	}
+	return nil
+}
+
+// Verify records the effectiveness check of an NCR in verification. An effective outcome
+// closes the NCR; an ineffective one returns it to implementation for further actions.
+// Only an administrator may verify, and not one who was assigned any of the NCR's actions.
+func (s *NCRService) Verify(ctx context.Context, tenantID, actorID, id pgtype.UUID, effective bool, notes *string, asAdmin bool) (domain.Ncr, error) {
+	if !asAdmin {
+		return domain.Ncr{}, ErrAdminRequired
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to begin verification transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	current, err := qtx.GetNCRForUpdate(ctx, domain.GetNCRForUpdateParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if current.Status != StatusVerification {
+		return domain.Ncr{}, ErrNotInVerification
+	}
+	if err := checkVerifier(ctx, qtx, tenantID, actorID, id); err != nil {
+		return domain.Ncr{}, err
+	}
+
+	verified, err := qtx.RecordNCRVerification(ctx, domain.RecordNCRVerificationParams{
+		TenantID:          tenantID,
+		ID:                id,
+		VerifiedByUserID:  actorID,
+		VerificationNotes: optionalText(notes),
+		IsEffective:       pgtype.Bool{Bool: effective, Valid: true},
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	to := StatusImplementation
+	if effective {
+		to = StatusClosed
+	}
+	ncr, err := s.transition(ctx, qtx, tenantID, actorID, verified, to, notes)
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to commit verification: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "NCRs", id.Bytes, map[string]interface{}{
+			"is_effective":       effective,
+			"verification_notes": notes,
+		})
+	}
+	s.logTransition(ctx, tenantID, actorID, current, to, notes)
+	return ncr, nil
+}
+
+// checkVerifier refuses an actor whose employee record is assigned any of the NCR's
+// actions that were not cancelled. Service account keys have no user and are never assignees.
+func checkVerifier(ctx context.Context, q *domain.Queries, tenantID, actorID, ncrID pgtype.UUID) error {
+	if !actorID.Valid {
+		return nil
+	}
+	actor, err := q.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load actor: %w", err)
+	}
+	if !actor.EmployeeID.Valid {
+		return nil
+	}
+
+	actions, err := q.ListNCRActions(ctx, domain.ListNCRActionsParams{
+		TenantID: tenantID,
+		NcrID:    ncrID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load actions: %w", err)
+	}
+	for _, a := range actions {
+		if a.Status != ActionCancelled && a.AssigneeEmployeeID == actor.EmployeeID {
+			return ErrAssigneeVerifier
+		}
+	}
+	return nil
+}
+
+func (s *NCRService) logTransition(ctx context.Context, tenantID, actorID pgtype.UUID, from domain.Ncr, to string, comment *string) {
+	if s.auditSvc == nil {
+		return
+	}
+	s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "NCRs", from.ID.Bytes, map[string]interface{}{
+		"status":  map[string]interface{}{"from": from.Status, "to": to},
+		"comment": comment,
+	})
+}
+
+func recordHistory(ctx context.Context, q *domain.Queries, tenantID, actorID, ncrID pgtype.UUID, from, to string, comment *string) error {
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	err := q.CreateNCRStatusHistory(ctx, domain.CreateNCRStatusHistoryParams{
+		ID:          id,
+		TenantID:    tenantID,
+		NcrID:       ncrID,
+		FromStatus:  pgtype.Text{String: from, Valid: from != ""},
+		ToStatus:    to,
+		Comment:     optionalText(comment),
+		ActorUserID: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+	return nil
+}
+
+// NCRFilter narrows the NCR list; empty fields do not filter
+type NCRFilter struct {
+	Status         string
+	Severity       string
+	Classification string
+	BusinessUnitID pgtype.UUID
+	DepartmentID   pgtype.UUID
+}
+
+// NCRSummary is an NCR as listed, with its site, department, reporter and action counts
+type NCRSummary struct {
+	domain.ListNCRsRow
+	Reference string `json:"reference"`
+}
+
+func (s *NCRService) ListNCRs(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, filter NCRFilter) ([]NCRSummary, int64, error) {
+	rows, err := s.queries.ListNCRs(ctx, domain.ListNCRsParams{
+		TenantID:       tenantID,
+		Status:         filter.Status,
+		Severity:       filter.Severity,
+		Classification: filter.Classification,
+		BusinessUnitID: filter.BusinessUnitID,
+		DepartmentID:   filter.DepartmentID,
+		Search:         params.Search,
+		Limit:          params.Limit(),
+		Offset:         params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountNCRs(ctx, domain.CountNCRsParams{
+		TenantID:       tenantID,
+		Status:         filter.Status,
+		Severity:       filter.Severity,
+		Classification: filter.Classification,
+		BusinessUnitID: filter.BusinessUnitID,
+		DepartmentID:   filter.DepartmentID,
+		Search:         params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]NCRSummary, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, NCRSummary{ListNCRsRow: row, Reference: Reference(row.Number)})
+	}
+	return out, total, nil
+}
+
+// NCRDetail is a full NCR with its action items, status history and the statuses it may move to next
+type NCRDetail struct {
+	domain.Ncr
+	Reference          string                     `json:"reference"`
+	AllowedTransitions []string                   `json:"allowed_transitions"`
+	Actions            []domain.ListNCRActionsRow `json:"actions"`
+	History            []domain.NcrStatusHistory  `json:"history"`
+}
+
+func (s *NCRService) GetNCR(ctx context.Context, tenantID, id pgtype.UUID) (NCRDetail, error) {
+	ncr, err := s.queries.GetNCR(ctx, domain.GetNCRParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return NCRDetail{}, err
+	}
+
+	actions, err := s.queries.ListNCRActions(ctx, domain.ListNCRActionsParams{
+		TenantID: tenantID,
+		NcrID:    id,
+	})
+	if err != nil {
+		return NCRDetail{}, err
+	}
+	if actions == nil {
+		actions = []domain.ListNCRActionsRow{}
+	}
+
+	history, err := s.queries.ListNCRStatusHistory(ctx, domain.ListNCRStatusHistoryParams{
+		TenantID: tenantID,
+		NcrID:    id,
+	})
+	if err != nil {
+		return NCRDetail{}, err
+	}
+	if history == nil {
+		history = []domain.NcrStatusHistory{}
+	}
+
+	return NCRDetail{
+		Ncr:                ncr,
+		Reference:          Reference(ncr.Number),
+		AllowedTransitions: AllowedTransitions(ncr.Status),
+		Actions:            actions,
+		History:            history,
+	}, nil
+}
+
+// checkReferences makes sure the department operates at the business unit and that the
+// owner, when given, is an employee of the tenant
+func (s *NCRService) checkReferences(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, in NCRInput) error {
+	links, err := q.CountBusinessUnitDepartmentLinks(ctx, domain.CountBusinessUnitDepartmentLinksParams{
+		TenantID:       tenantID,
+		BusinessUnitID: in.BusinessUnitID,
+		DepartmentID:   in.DepartmentID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check site departments: %w", err)
+	}
+	if links == 0 {
+		return ErrDepartmentNotAtSite
+	}
+
+	if in.OwnerEmployeeID.Valid {
+		return s.checkEmployee(ctx, q, tenantID, in.OwnerEmployeeID)
+	}
+	return nil
+}
+
+func (s *NCRService) checkEmployee(ctx context.Context, q *domain.Queries, tenantID, employeeID pgtype.UUID) error {
+	if _, err := q.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	}); errors.Is(err, pgx.ErrNoRows) {
+		return ErrEmployeeNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func isNumberClash(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "ncrs_tenant_number_key"
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
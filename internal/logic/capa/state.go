@@ -0,0 +1,52 @@
+package capa
+
+// NCR lifecycle statuses
+const (
+	StatusOpen           = "open"
+	StatusInvestigation  = "investigation"
+	StatusActionPlanned  = "action_planned"
+	StatusImplementation = "implementation"
+	StatusVerification   = "verification"
+	StatusClosed         = "closed"
+	StatusCancelled      = "cancelled"
+)
+
+// Action item statuses
+const (
+	ActionOpen      = "open"
+	ActionDone      = "done"
+	ActionCancelled = "cancelled"
+)
+
+// transitions lists the statuses an NCR may move to from each status. Moving back from
+// action_planned to investigation allows the root cause to be revisited, and a failed
+// effectiveness check returns the NCR from verification to implementation.
+var transitions = map[string][]string{
+	StatusOpen:           {StatusInvestigation, StatusCancelled},
+	StatusInvestigation:  {StatusActionPlanned, StatusCancelled},
+	StatusActionPlanned:  {StatusImplementation, StatusInvestigation, StatusCancelled},
+	StatusImplementation: {StatusVerification},
+	StatusVerification:   {StatusClosed, StatusImplementation},
+}
+
+// AllowedTransitions returns the statuses an NCR in status may move to
+func AllowedTransitions(status string) []string {
+	next := transitions[status]
+	out := make([]string, len(next))
+	copy(out, next)
+	return out
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isTerminal reports whether an NCR in status can no longer be edited
+func isTerminal(status string) bool {
+	return status == StatusClosed || status == StatusCancelled
+}
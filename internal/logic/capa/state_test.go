@@ -0,0 +1,89 @@
+package capa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/INOVA/DML/internal/domain"
+)
+
+var allStatuses = []string{
+	StatusOpen,
+	StatusInvestigation,
+	StatusActionPlanned,
+	StatusImplementation,
+	StatusVerification,
+	StatusClosed,
+	StatusCancelled,
+}
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{StatusOpen, StatusInvestigation}:           true,
+		{StatusOpen, StatusCancelled}:               true,
+		{StatusInvestigation, StatusActionPlanned}:  true,
+		{StatusInvestigation, StatusCancelled}:      true,
+		{StatusActionPlanned, StatusImplementation}: true,
+		{StatusActionPlanned, StatusInvestigation}:  true,
+		{StatusActionPlanned, StatusCancelled}:      true,
+		{StatusImplementation, StatusVerification}:  true,
+		{StatusVerification, StatusClosed}:          true,
+		{StatusVerification, StatusImplementation}:  true,
+	}
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := allowed[[2]string{from, to}]
+			if got := canTransition(from, to); got != want {
+				t.Errorf("canTransition(%q, %q) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	if canTransition("unknown", StatusOpen) || canTransition(StatusOpen, "unknown") {
+		t.Error("canTransition allowed an unknown status")
+	}
+}
+
+func TestTerminalStatusesHaveNoTransitions(t *testing.T) {
+	for _, status := range allStatuses {
+		next := AllowedTransitions(status)
+		if terminal := isTerminal(status); terminal != (len(next) == 0) {
+			t.Errorf("isTerminal(%q) = %v with transitions %v", status, terminal, next)
+		}
+	}
+}
+
+func TestAllowedTransitionsReturnsCopy(t *testing.T) {
+	next := AllowedTransitions(StatusOpen)
+	next[0] = StatusClosed
+	if canTransition(StatusOpen, StatusClosed) {
+		t.Error("changing the returned slice changed the transitions")
+	}
+}
+
+func TestCheckActions(t *testing.T) {
+	action := func(kind, status string) domain.ListNCRActionsRow {
+		return domain.ListNCRActionsRow{Kind: kind, Status: status}
+	}
+	cases := []struct {
+		name    string
+		actions []domain.ListNCRActionsRow
+		to      string
+		want    error
+	}{
+		{"implementation without actions", nil, StatusImplementation, ErrNoActions},
+		{"implementation with containment only", []domain.ListNCRActionsRow{action("containment", ActionOpen)}, StatusImplementation, ErrNoActions},
+		{"implementation with cancelled actions only", []domain.ListNCRActionsRow{action("corrective", ActionCancelled)}, StatusImplementation, ErrNoActions},
+		{"implementation with a corrective action", []domain.ListNCRActionsRow{action("containment", ActionDone), action("corrective", ActionOpen)}, StatusImplementation, nil},
+		{"implementation with a preventive action", []domain.ListNCRActionsRow{action("preventive", ActionDone)}, StatusImplementation, nil},
+		{"verification with open actions", []domain.ListNCRActionsRow{action("corrective", ActionDone), action("containment", ActionOpen)}, StatusVerification, ErrActionsOpen},
+		{"verification with nothing done", []domain.ListNCRActionsRow{action("corrective", ActionCancelled)}, StatusVerification, ErrNoActions},
+		{"verification with actions done", []domain.ListNCRActionsRow{action("corrective", ActionDone), action("preventive", ActionCancelled)}, StatusVerification, nil},
+		{"other statuses ignore actions", nil, StatusInvestigation, nil},
+	}
+	for _, tc := range cases {
+		err := checkActions(tc.actions, tc.to)
+		if tc.want == nil && err != nil || tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("%s: checkActions() = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package competency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Competency kinds
+const (
+	KindSkill         = "skill"
+	KindCertification = "certification"
+	KindDocument      = "document"
+)
+
+// Sources of an employee competency record
+const (
+	SourceManual   = "manual"
+	SourceTraining = "training"
+)
+
+var (
+	// ErrCompetencyNotFound is returned when a referenced competency does not exist in the tenant
+	ErrCompetencyNotFound = errors.New("competency does not exist or is inaccessible")
+
+	// ErrInvalidExpiry is returned when a record expires before it was achieved
+	ErrInvalidExpiry = errors.New("expiry date must not be before the achievement date")
+)
+
+type CompetencyService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
+}
+
+func NewCompetencyService(database *db.DB, auditSvc *audit.AuditService) *CompetencyService {
+	return &CompetencyService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
+	}
+}
+
+func (s *CompetencyService) CreateCompetency(ctx context.Context, id, tenantID, actorID pgtype.UUID, code, name, kind string, description, documentRef *string, validityMonths *int32) (domain.Competency, error) {
+	comp, err := s.queries.CreateCompetency(ctx, domain.CreateCompetencyParams{
+		ID:             id,
+		TenantID:       tenantID,
+		Code:           code,
+		Name:           name,
+		Description:    optionalText(description),
+		Kind:           kindOrDefault(kind),
+		DocumentRef:    optionalText(documentRef),
+		ValidityMonths: optionalInt4(validityMonths),
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Competencies", id.Bytes, map[string]interface{}{
+			"code": code,
+			"name": name,
+			"kind": comp.Kind,
+		})
+	}
+	return comp, err
+}
+
+func (s *CompetencyService) UpdateCompetency(ctx context.Context, tenantID, actorID, id pgtype.UUID, code, name, kind string, description, documentRef *string, validityMonths *int32, isActive bool) (domain.Competency, error) {
+	comp, err := s.queries.UpdateCompetency(ctx, domain.UpdateCompetencyParams{
+		TenantID:       tenantID,
+		ID:             id,
+		Code:           code,
+		Name:           name,
+		Description:    optionalText(description),
+		Kind:           kindOrDefault(kind),
+		DocumentRef:    optionalText(documentRef),
+		ValidityMonths: optionalInt4(validityMonths),
+		IsActive:       isActive,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "Competencies", id.Bytes, map[string]interface{}{
+			"code":      code,
+			"name":      name,
+			"kind":      comp.Kind,
+			"is_active": isActive,
+		})
+	}
+	return comp, err
+}
+
+func (s *CompetencyService) ListCompetencies(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.Competency, int64, error) {
+	comps, err := s.queries.ListCompetencies(ctx, domain.ListCompetenciesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountCompetencies(ctx, domain.CountCompetenciesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comps, total, nil
+}
+
+func (s *CompetencyService) GetCompetency(ctx context.Context, tenantID, id pgtype.UUID) (domain.Competency, error) {
+	return s.queries.GetCompetency(ctx, domain.GetCompetencyParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+// Requirement is a competency required by a job title
+type Requirement struct {
+	CompetencyID   pgtype.UUID `json:"competencyId"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Kind           string      `json:"kind"`
+	DocumentRef    *string     `json:"documentRef"`
+	ValidityMonths *int32      `json:"validityMonths"`
+	IsActive       bool        `json:"isActive"`
+	IsMandatory    bool        `json:"isMandatory"`
+}
+
+// RequirementInput is one entry of a job title's requirement list
+type RequirementInput struct {
+	CompetencyID pgtype.UUID
+	IsMandatory  bool
+}
+
+func (s *CompetencyService) ListJobTitleRequirements(ctx context.Context, tenantID, jobTitleID pgtype.UUID) ([]Requirement, error) {
+	if _, err := s.queries.GetJobTitle(ctx, domain.GetJobTitleParams{
+		TenantID: tenantID,
+		ID:       jobTitleID,
+	}); err != nil {
+		return nil, err
+	}
+	return s.listRequirements(ctx, s.queries, tenantID, jobTitleID)
+}
+
+// SetJobTitleRequirements replaces the full list of competencies required by a job title
+func (s *CompetencyService) SetJobTitleRequirements(ctx context.Context, tenantID, actorID, jobTitleID pgtype.UUID, reqs []RequirementInput) ([]Requirement, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin requirements transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	if _, err := qtx.GetJobTitle(ctx, domain.GetJobTitleParams{
+		TenantID: tenantID,
+		ID:       jobTitleID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := qtx.DeleteJobTitleRequirements(ctx, domain.DeleteJobTitleRequirementsParams{
+		TenantID:   tenantID,
+		JobTitleID: jobTitleID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to clear requirements: %w", err)
+	}
+
+	seen := make(map[[16]byte]bool, len(reqs))
+	for _, req := range reqs {
+		if seen[req.CompetencyID.Bytes] {
+			continue
+		}
+		seen[req.CompetencyID.Bytes] = true
+
+		if _, err := qtx.GetCompetency(ctx, domain.GetCompetencyParams{
+			TenantID: tenantID,
+			ID:       req.CompetencyID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrCompetencyNotFound, uuid.UUID(req.CompetencyID.Bytes))
+		} else if err != nil {
+			return nil, err
+		}
+
+		if err := qtx.AddJobTitleRequirement(ctx, domain.AddJobTitleRequirementParams{
+			TenantID:     tenantID,
+			JobTitleID:   jobTitleID,
+			CompetencyID: req.CompetencyID,
+			IsMandatory:  req.IsMandatory,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to add requirement: %w", err)
+		}
+	}
+
+	requirements, err := s.listRequirements(ctx, qtx, tenantID, jobTitleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit requirements: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		codes := make([]string, 0, len(requirements))
+		for _, r := range requirements {
+			codes = append(codes, r.Code)
+		}
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "JobTitles", jobTitleID.Bytes, map[string]interface{}{
+			"requirements": codes,
+		})
+	}
+
+	return requirements, nil
+}
+
+func (s *CompetencyService) listRequirements(ctx context.Context, q *domain.Queries, tenantID, jobTitleID pgtype.UUID) ([]Requirement, error) {
+	rows, err := q.ListJobTitleRequirements(ctx, domain.ListJobTitleRequirementsParams{
+		TenantID:   tenantID,
+		JobTitleID: jobTitleID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Requirement, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, Requirement{
+			CompetencyID:   row.ID,
+			Code:           row.Code,
+			Name:           row.Name,
+			Kind:           row.Kind,
+			DocumentRef:    textPtr(row.DocumentRef),
+			ValidityMonths: int4Ptr(row.ValidityMonths),
+			IsActive:       row.IsActive,
+			IsMandatory:    row.IsMandatory,
+		})
+	}
+	return out, nil
+}
+
+// RecordCompetency stores that an employee achieved a competency. When expiresOn is nil the
+// expiry is derived from the competency's validity period, if it has one.
+func (s *CompetencyService) RecordCompetency(ctx context.Context, tenantID, actorID, employeeID, competencyID pgtype.UUID, achievedOn time.Time, expiresOn *time.Time, source string, notes *string) (domain.EmployeeCompetency, error) {
+	return s.recordCompetency(ctx, s.queries, tenantID, actorID, employeeID, competencyID, achievedOn, expiresOn, source, notes)
+}
+
+// RecordCompetencyTx is RecordCompetency run on q, so callers can record the achievement
+// in the same transaction as the change that earned it
+func (s *CompetencyService) RecordCompetencyTx(ctx context.Context, q *domain.Queries, tenantID, actorID, employeeID, competencyID pgtype.UUID, achievedOn time.Time, expiresOn *time.Time, source string, notes *string) (domain.EmployeeCompetency, error) {
+	return s.recordCompetency(ctx, q, tenantID, actorID, employeeID, competencyID, achievedOn, expiresOn, source, notes)
+}
+
+func (s *CompetencyService) recordCompetency(ctx context.Context, q *domain.Queries, tenantID, actorID, employeeID, competencyID pgtype.UUID, achievedOn time.Time, expiresOn *time.Time, source string, notes *string) (domain.EmployeeCompetency, error) {
+	if _, err := q.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	}); err != nil {
+		return domain.EmployeeCompetency{}, err
+	}
+
+	comp, err := q.GetCompetency(ctx, domain.GetCompetencyParams{
+		TenantID: tenantID,
+		ID:       competencyID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.EmployeeCompetency{}, ErrCompetencyNotFound
+	}
+	if err != nil {
+		return domain.EmployeeCompetency{}, err
+	}
+
+	var expires pgtype.Date
+	switch {
+	case expiresOn != nil:
+		if expiresOn.Before(achievedOn) {
+			return domain.EmployeeCompetency{}, ErrInvalidExpiry
+		}
+		expires = pgtype.Date{Time: *expiresOn, Valid: true}
+	case comp.ValidityMonths.Valid:
+		expires = pgtype.Date{Time: achievedOn.AddDate(0, int(comp.ValidityMonths.Int32), 0), Valid: true}
+	}
+
+	if source == "" {
+		source = SourceManual
+	}
+
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	rec, err := q.CreateEmployeeCompetency(ctx, domain.CreateEmployeeCompetencyParams{
+		ID:               id,
+		TenantID:         tenantID,
+		EmployeeID:       employeeID,
+		CompetencyID:     competencyID,
+		AchievedOn:       pgtype.Date{Time: achievedOn, Valid: true},
+		ExpiresOn:        expires,
+		Source:           source,
+		Notes:            optionalText(notes),
+		RecordedByUserID: actorID,
+	})
+	if err != nil {
+		return domain.EmployeeCompetency{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "EmployeeCompetencies", id.Bytes, map[string]interface{}{
+			"employee_id":   employeeID,
+			"competency_id": competencyID,
+			"achieved_on":   rec.AchievedOn,
+			"expires_on":    rec.ExpiresOn,
+			"source":        source,
+		})
+	}
+
+	return rec, nil
+}
+
+// Record is one achievement in an employee's competency history
+type Record struct {
+	ID               pgtype.UUID        `json:"id"`
+	CompetencyID     pgtype.UUID        `json:"competencyId"`
+	CompetencyCode   string             `json:"competencyCode"`
+	CompetencyName   string             `json:"competencyName"`
+	CompetencyKind   string             `json:"competencyKind"`
+	AchievedOn       pgtype.Date        `json:"achievedOn"`
+	ExpiresOn        pgtype.Date        `json:"expiresOn"`
+	Source           string             `json:"source"`
+	Notes            *string            `json:"notes"`
+	RecordedByUserID pgtype.UUID        `json:"recordedByUserId"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+}
+
+// ListEmployeeCompetencies returns an employee's competency history, most recent first
+func (s *CompetencyService) ListEmployeeCompetencies(ctx context.Context, tenantID, employeeID pgtype.UUID) ([]Record, error) {
+	if _, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	}); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListEmployeeCompetencies(ctx, domain.ListEmployeeCompetenciesParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, Record{
+			ID:               row.ID,
+			CompetencyID:     row.CompetencyID,
+			CompetencyCode:   row.CompetencyCode,
+			CompetencyName:   row.CompetencyName,
+			CompetencyKind:   row.CompetencyKind,
+			AchievedOn:       row.AchievedOn,
+			ExpiresOn:        row.ExpiresOn,
+			Source:           row.Source,
+			Notes:            textPtr(row.Notes),
+			RecordedByUserID: row.RecordedByUserID,
+			CreatedAt:        row.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func kindOrDefault(kind string) string {
+	if kind == "" {
+		return KindSkill
+	}
+	return kind
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
+
+func int4Ptr(i pgtype.Int4) *int32 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int32
+}
@@ -0,0 +1,181 @@
+package competency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ExpiringWithinDays is how far ahead a held competency is reported as expiring
+const ExpiringWithinDays = 30
+
+// Requirement statuses reported in the competency matrix
+const (
+	StatusMet      = "met"
+	StatusExpiring = "expiring"
+	StatusExpired  = "expired"
+	StatusMissing  = "missing"
+)
+
+// MatrixFilter narrows the competency matrix; unset fields do not filter
+type MatrixFilter struct {
+	EmployeeID   pgtype.UUID
+	DepartmentID pgtype.UUID
+	JobTitleID   pgtype.UUID
+}
+
+// RequirementStatus is how one employee stands against one of their job title's requirements
+type RequirementStatus struct {
+	CompetencyID   pgtype.UUID `json:"competencyId"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Kind           string      `json:"kind"`
+	IsMandatory    bool        `json:"isMandatory"`
+	Status         string      `json:"status"`
+	LastAchievedOn pgtype.Date `json:"lastAchievedOn"`
+	ExpiresOn      pgtype.Date `json:"expiresOn"`
+}
+
+// EmployeeCompliance is one row of the competency matrix. Compliant means every mandatory
+// requirement is currently met (expiring counts as met until the expiry date passes).
+type EmployeeCompliance struct {
+	EmployeeID   pgtype.UUID         `json:"employeeId"`
+	EmployeeNo   string              `json:"employeeNo"`
+	FirstName    string              `json:"firstName"`
+	LastName     string              `json:"lastName"`
+	DepartmentID pgtype.UUID         `json:"departmentId"`
+	JobTitleID   pgtype.UUID         `json:"jobTitleId"`
+	JobTitleName string              `json:"jobTitleName"`
+	Compliant    bool                `json:"compliant"`
+	Gaps         int                 `json:"gaps"`
+	Requirements []RequirementStatus `json:"requirements"`
+}
+
+// GetMatrix compares active employees' competency records against the requirements of their
+// job titles. Employees whose job title has no active requirements are not listed.
+func (s *CompetencyService) GetMatrix(ctx context.Context, tenantID pgtype.UUID, filter MatrixFilter) ([]EmployeeCompliance, error) {
+	reqs, err := s.queries.ListCompetencyRequirementsForEmployees(ctx, domain.ListCompetencyRequirementsForEmployeesParams{
+		TenantID:     tenantID,
+		EmployeeID:   filter.EmployeeID,
+		DepartmentID: filter.DepartmentID,
+		JobTitleID:   filter.JobTitleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load requirements: %w", err)
+	}
+
+	held, err := s.queries.ListLatestEmployeeCompetencies(ctx, domain.ListLatestEmployeeCompetenciesParams{
+		TenantID:     tenantID,
+		EmployeeID:   filter.EmployeeID,
+		DepartmentID: filter.DepartmentID,
+		JobTitleID:   filter.JobTitleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load competency records: %w", err)
+	}
+
+	type key struct{ employee, competency [16]byte }
+	latest := make(map[key]domain.ListLatestEmployeeCompetenciesRow, len(held))
+	for _, h := range held {
+		latest[key{h.EmployeeID.Bytes, h.CompetencyID.Bytes}] = h
+	}
+
+	today := truncateDay(time.Now())
+	out := []EmployeeCompliance{}
+	var current *EmployeeCompliance
+	for _, row := range reqs {
+		if current == nil || current.EmployeeID != row.EmployeeID {
+			out = append(out, EmployeeCompliance{
+				EmployeeID:   row.EmployeeID,
+				EmployeeNo:   row.EmployeeNo,
+				FirstName:    row.FirstName,
+				LastName:     row.LastName,
+				DepartmentID: row.DepartmentID,
+				JobTitleID:   row.JobTitleID,
+				JobTitleName: row.JobTitleName,
+				Compliant:    true,
+				Requirements: []RequirementStatus{},
+			})
+			current = &out[len(out)-1]
+		}
+
+		status := RequirementStatus{
+			CompetencyID: row.CompetencyID,
+			Code:         row.CompetencyCode,
+			Name:         row.CompetencyName,
+			Kind:         row.CompetencyKind,
+			IsMandatory:  row.IsMandatory,
+			Status:       StatusMissing,
+		}
+		if h, ok := latest[key{row.EmployeeID.Bytes, row.CompetencyID.Bytes}]; ok {
+			status.LastAchievedOn = h.LastAchievedOn
+			if !h.HasNonExpiring {
+				status.ExpiresOn = h.LatestExpiresOn
+			}
+			status.Status = statusOf(h, today)
+		}
+
+		if status.Status == StatusMissing || status.Status == StatusExpired {
+			current.Gaps++
+			if status.IsMandatory {
+				current.Compliant = false
+			}
+		}
+		current.Requirements = append(current.Requirements, status)
+	}
+	return out, nil
+}
+
+// GetEmployeeCompliance is GetMatrix for a single employee. An employee whose job title has
+// no requirements is reported as compliant with an empty requirement list.
+func (s *CompetencyService) GetEmployeeCompliance(ctx context.Context, tenantID, employeeID pgtype.UUID) (EmployeeCompliance, error) {
+	emp, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	})
+	if err != nil {
+		return EmployeeCompliance{}, err
+	}
+
+	rows, err := s.GetMatrix(ctx, tenantID, MatrixFilter{EmployeeID: employeeID})
+	if err != nil {
+		return EmployeeCompliance{}, err
+	}
+	if len(rows) > 0 {
+		return rows[0], nil
+	}
+
+	return EmployeeCompliance{
+		EmployeeID:   emp.ID,
+		EmployeeNo:   emp.EmployeeNo,
+		FirstName:    emp.FirstName,
+		LastName:     emp.LastName,
+		DepartmentID: emp.DepartmentID,
+		JobTitleID:   emp.JobTitleID,
+		Compliant:    true,
+		Requirements: []RequirementStatus{},
+	}, nil
+}
+
+func statusOf(h domain.ListLatestEmployeeCompetenciesRow, today time.Time) string {
+	if h.HasNonExpiring || !h.LatestExpiresOn.Valid {
+		return StatusMet
+	}
+	expires := truncateDay(h.LatestExpiresOn.Time)
+	switch {
+	case expires.Before(today):
+		return StatusExpired
+	case !expires.After(today.AddDate(0, 0, ExpiringWithinDays)):
+		return StatusExpiring
+	default:
+		return StatusMet
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
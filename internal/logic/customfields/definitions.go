@@ -0,0 +1,315 @@
+// Package customfields lets tenants define extra attributes of employees and org entities,
+// such as a shift pattern or cost centre, and validates and stores their values.
+package customfields
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Entity types custom fields can be defined for
+const (
+	EntityEmployee     = "employee"
+	EntityBusinessUnit = "business_unit"
+	EntityDepartment   = "department"
+	EntityJobTitle     = "job_title"
+)
+
+// Field types
+const (
+	TypeText        = "text"
+	TypeNumber      = "number"
+	TypeBoolean     = "boolean"
+	TypeDate        = "date"
+	TypeSelect      = "select"
+	TypeMultiSelect = "multi_select"
+)
+
+// auditEntities maps entity types onto the audit log entity names of the records
+var auditEntities = map[string]string{
+	EntityEmployee:     "Employees",
+	EntityBusinessUnit: "BusinessUnits",
+	EntityDepartment:   "Departments",
+	EntityJobTitle:     "JobTitles",
+}
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+var (
+	// ErrUnknownEntityType is returned for an entity type custom fields cannot be defined for
+	ErrUnknownEntityType = errors.New("entityType must be one of employee, business_unit, department or job_title")
+
+	// ErrInvalidKey is returned when a key is not a lower-case identifier
+	ErrInvalidKey = errors.New("key must start with a letter and contain only lower-case letters, digits and underscores")
+
+	// ErrDuplicateKey is returned when the entity type already has a field with the key
+	ErrDuplicateKey = errors.New("a custom field with this key already exists")
+
+	// ErrOptionsRequired is returned when a select field has no options, or another type has some
+	ErrOptionsRequired = errors.New("select and multi_select fields need at least one option; other types take none")
+
+	// ErrInvalidRules is returned when validation rules do not apply to the field type or contradict each other
+	ErrInvalidRules = errors.New("validation rules do not fit the field type")
+
+	// ErrErased is returned for writes to an employee erased under the right to erasure
+	ErrErased = errors.New("employee has been erased")
+)
+
+// Rules are the optional validation rules of a field. Length and pattern rules apply to
+// text fields, Min and Max to numbers.
+type Rules struct {
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Pattern   *string  `json:"pattern,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+}
+
+// Definition is the API representation of a custom field
+type Definition struct {
+	ID              pgtype.UUID        `json:"id"`
+	EntityType      string             `json:"entityType"`
+	Key             string             `json:"key"`
+	Label           string             `json:"label"`
+	FieldType       string             `json:"fieldType"`
+	IsRequired      bool               `json:"isRequired"`
+	Options         []string           `json:"options"`
+	Validation      Rules              `json:"validation"`
+	SortOrder       int32              `json:"sortOrder"`
+	IsActive        bool               `json:"isActive"`
+	CreatedByUserID pgtype.UUID        `json:"createdByUserId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func toDefinition(d domain.CustomFieldDefinition) Definition {
+	def := Definition{
+		ID:              d.ID,
+		EntityType:      d.EntityType,
+		Key:             d.Key,
+		Label:           d.Label,
+		FieldType:       d.FieldType,
+		IsRequired:      d.IsRequired,
+		Options:         []string{},
+		SortOrder:       d.SortOrder,
+		IsActive:        d.IsActive,
+		CreatedByUserID: d.CreatedByUserID,
+		CreatedAt:       d.CreatedAt,
+		UpdatedAt:       d.UpdatedAt,
+	}
+	_ = json.Unmarshal(d.Options, &def.Options)
+	_ = json.Unmarshal(d.Validation, &def.Validation)
+	return def
+}
+
+// DefinitionInput holds the editable settings of a field. The entity type, key and field
+// type are fixed once the field exists, so stored values keep their meaning.
+type DefinitionInput struct {
+	Label      string
+	IsRequired bool
+	Options    []string
+	Validation Rules
+	SortOrder  int32
+	IsActive   bool
+}
+
+type CustomFieldService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
+}
+
+func NewCustomFieldService(database *db.DB, auditSvc *audit.AuditService) *CustomFieldService {
+	return &CustomFieldService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
+	}
+}
+
+// IsEntityType reports whether custom fields can be defined for entityType
+func IsEntityType(entityType string) bool {
+	_, ok := auditEntities[entityType]
+	return ok
+}
+
+// ListDefinitions lists the fields of an entity type, or of every entity type when it is empty
+func (s *CustomFieldService) ListDefinitions(ctx context.Context, tenantID pgtype.UUID, entityType string, includeInactive bool) ([]Definition, error) {
+	if entityType != "" && !IsEntityType(entityType) {
+		return nil, ErrUnknownEntityType
+	}
+	rows, err := s.queries.ListCustomFieldDefinitions(ctx, domain.ListCustomFieldDefinitionsParams{
+		TenantID:        tenantID,
+		EntityType:      entityType,
+		IncludeInactive: includeInactive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Definition, 0, len(rows))
+	for _, d := range rows {
+		items = append(items, toDefinition(d))
+	}
+	return items, nil
+}
+
+func (s *CustomFieldService) GetDefinition(ctx context.Context, tenantID, id pgtype.UUID) (Definition, error) {
+	d, err := s.queries.GetCustomFieldDefinition(ctx, domain.GetCustomFieldDefinitionParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return Definition{}, err
+	}
+	return toDefinition(d), nil
+}
+
+// CreateDefinition adds a custom field to an entity type
+func (s *CustomFieldService) CreateDefinition(ctx context.Context, id, tenantID, actorID pgtype.UUID, entityType, key, fieldType string, in DefinitionInput) (Definition, error) {
+	if !IsEntityType(entityType) {
+		return Definition{}, ErrUnknownEntityType
+	}
+	if !keyPattern.MatchString(key) {
+		return Definition{}, ErrInvalidKey
+	}
+	options, rules, err := checkDefinition(fieldType, in)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	d, err := s.queries.CreateCustomFieldDefinition(ctx, domain.CreateCustomFieldDefinitionParams{
+		ID:              id,
+		TenantID:        tenantID,
+		EntityType:      entityType,
+		Key:             key,
+		Label:           strings.TrimSpace(in.Label),
+		FieldType:       fieldType,
+		IsRequired:      in.IsRequired,
+		Options:         options,
+		Validation:      rules,
+		SortOrder:       in.SortOrder,
+		CreatedByUserID: actorID,
+	})
+	if err != nil {
+		return Definition{}, mapDefinitionError(err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "CustomFieldDefinitions", id.Bytes, map[string]interface{}{
+			"entity_type": entityType,
+			"key":         key,
+			"field_type":  fieldType,
+			"is_required": in.IsRequired,
+		})
+	}
+	return toDefinition(d), nil
+}
+
+// UpdateDefinition edits a custom field. Deactivating it stops the key being accepted on
+// writes without deleting stored values. Making it required only applies to later writes
+// of each record.
+func (s *CustomFieldService) UpdateDefinition(ctx context.Context, tenantID, actorID, id pgtype.UUID, in DefinitionInput) (Definition, error) {
+	current, err := s.queries.GetCustomFieldDefinition(ctx, domain.GetCustomFieldDefinitionParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return Definition{}, err
+	}
+	options, rules, err := checkDefinition(current.FieldType, in)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	d, err := s.queries.UpdateCustomFieldDefinition(ctx, domain.UpdateCustomFieldDefinitionParams{
+		TenantID:   tenantID,
+		ID:         id,
+		Label:      strings.TrimSpace(in.Label),
+		IsRequired: in.IsRequired,
+		Options:    options,
+		Validation: rules,
+		SortOrder:  in.SortOrder,
+		IsActive:   in.IsActive,
+	})
+	if err != nil {
+		return Definition{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "CustomFieldDefinitions", id.Bytes, map[string]interface{}{
+			"label":       d.Label,
+			"is_required": d.IsRequired,
+			"options":     in.Options,
+			"is_active":   d.IsActive,
+		})
+	}
+	return toDefinition(d), nil
+}
+
+// checkDefinition validates the options and rules of a field and encodes them for storage
+func checkDefinition(fieldType string, in DefinitionInput) ([]byte, []byte, error) {
+	isSelect := fieldType == TypeSelect || fieldType == TypeMultiSelect
+	switch fieldType {
+	case TypeText, TypeNumber, TypeBoolean, TypeDate, TypeSelect, TypeMultiSelect:
+	default:
+		return nil, nil, fmt.Errorf("unknown field type %q", fieldType)
+	}
+
+	options := make([]string, 0, len(in.Options))
+	seen := make(map[string]bool, len(in.Options))
+	for _, o := range in.Options {
+		o = strings.TrimSpace(o)
+		if o != "" && !seen[o] {
+			seen[o] = true
+			options = append(options, o)
+		}
+	}
+	if isSelect != (len(options) > 0) {
+		return nil, nil, ErrOptionsRequired
+	}
+
+	r := in.Validation
+	textRules := r.MinLength != nil || r.MaxLength != nil || r.Pattern != nil
+	numberRules := r.Min != nil || r.Max != nil
+	if (textRules && fieldType != TypeText) || (numberRules && fieldType != TypeNumber) {
+		return nil, nil, ErrInvalidRules
+	}
+	if (r.MinLength != nil && *r.MinLength < 0) || (r.MaxLength != nil && *r.MaxLength < 0) ||
+		(r.MinLength != nil && r.MaxLength != nil && *r.MinLength > *r.MaxLength) ||
+		(r.Min != nil && r.Max != nil && *r.Min > *r.Max) {
+		return nil, nil, ErrInvalidRules
+	}
+	if r.Pattern != nil {
+		if _, err := regexp.Compile(*r.Pattern); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+		}
+	}
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	rulesJSON, err := json.Marshal(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return optionsJSON, rulesJSON, nil
+}
+
+func mapDefinitionError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "custom_field_definitions_key_unique" {
+		return ErrDuplicateKey
+	}
+	return err
+}
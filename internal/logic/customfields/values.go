@@ -0,0 +1,446 @@
+package customfields
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValueError reports a custom field value that does not satisfy its definition
+type ValueError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("customFields.%s: %s", e.Key, e.Message)
+}
+
+// Validate checks values against the active fields of entityType without storing them.
+// For a new record entityID is left invalid and every required field must be given; for
+// an existing one the values are merged into the stored ones as SetValues would.
+func (s *CustomFieldService) Validate(ctx context.Context, tenantID pgtype.UUID, entityType string, entityID pgtype.UUID, values map[string]interface{}) error {
+	defs, err := s.activeDefinitions(ctx, s.queries, tenantID, entityType)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]interface{}{}
+	if entityID.Valid {
+		if err := s.checkWritable(ctx, s.queries, tenantID, entityType, entityID); err != nil {
+			return err
+		}
+		row, err := s.queries.GetCustomFieldValues(ctx, domain.GetCustomFieldValuesParams{
+			TenantID:   tenantID,
+			EntityType: entityType,
+			EntityID:   entityID,
+		})
+		if err == nil {
+			if err := json.Unmarshal(row.FieldValues, &current); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+
+	_, err = validateValues(defs, current, values)
+	return err
+}
+
+// GetValues returns the stored custom field values of a record
+func (s *CustomFieldService) GetValues(ctx context.Context, tenantID pgtype.UUID, entityType string, entityID pgtype.UUID) (json.RawMessage, error) {
+	if err := s.checkEntity(ctx, s.queries, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	row, err := s.queries.GetCustomFieldValues(ctx, domain.GetCustomFieldValuesParams{
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return json.RawMessage(`{}`), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(row.FieldValues), nil
+}
+
+// SetValues merges values into the stored custom fields of a record. Keys that are left
+// out keep their value and a null value clears a field. The merged set must satisfy every
+// active field, including required ones.
+func (s *CustomFieldService) SetValues(ctx context.Context, tenantID, actorID pgtype.UUID, entityType string, entityID pgtype.UUID, values map[string]interface{}) (json.RawMessage, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin custom field transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := domain.New(tx)
+
+	if err := s.checkWritable(ctx, qtx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	defs, err := s.activeDefinitions(ctx, qtx, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]interface{}{}
+	row, err := qtx.GetCustomFieldValuesForUpdate(ctx, domain.GetCustomFieldValuesForUpdateParams{
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	})
+	if err == nil {
+		if err := json.Unmarshal(row.FieldValues, &current); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	merged, err := validateValues(defs, current, values)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := qtx.UpsertCustomFieldValues(ctx, domain.UpsertCustomFieldValuesParams{
+		TenantID:        tenantID,
+		EntityType:      entityType,
+		EntityID:        entityID,
+		FieldValues:     encoded,
+		UpdatedByUserID: actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit custom field transaction: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", auditEntities[entityType], entityID.Bytes, map[string]interface{}{
+			"custom_fields": map[string]interface{}{"from": current, "to": merged},
+		})
+	}
+	return json.RawMessage(saved.FieldValues), nil
+}
+
+// CreateValues checks the custom fields of a record that is being created and stores them
+// with q, so they are written in the same transaction as the record itself. Every required
+// field must be given. It returns the values as stored.
+func (s *CustomFieldService) CreateValues(ctx context.Context, q *domain.Queries, tenantID, actorID pgtype.UUID, entityType string, entityID pgtype.UUID, values map[string]interface{}) (map[string]interface{}, error) {
+	defs, err := s.activeDefinitions(ctx, q, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	checked, err := validateValues(defs, map[string]interface{}{}, values)
+	if err != nil || len(checked) == 0 {
+		return checked, err
+	}
+	encoded, err := json.Marshal(checked)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := q.UpsertCustomFieldValues(ctx, domain.UpsertCustomFieldValuesParams{
+		TenantID:        tenantID,
+		EntityType:      entityType,
+		EntityID:        entityID,
+		FieldValues:     encoded,
+		UpdatedByUserID: actorID,
+	}); err != nil {
+		return nil, err
+	}
+	return checked, nil
+}
+
+// Filter turns cf.<key>=<value> query parameters into a JSONB containment document for
+// the list queries. Values are converted to the field's type; a multi_select filter
+// matches records that have the option among their selections.
+func (s *CustomFieldService) Filter(ctx context.Context, tenantID pgtype.UUID, entityType string, raw map[string]string) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	defs, err := s.activeDefinitions(ctx, s.queries, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string]interface{}, len(raw))
+	for key, v := range raw {
+		def, ok := defs[key]
+		if !ok {
+			return nil, &ValueError{Key: key, Message: "unknown field"}
+		}
+		if def.FieldType == TypeMultiSelect {
+			filter[key] = []string{v}
+			continue
+		}
+		value, err := textValue(def, v)
+		if err != nil {
+			return nil, err
+		}
+		filter[key] = value
+	}
+	return json.Marshal(filter)
+}
+
+// ActiveDefinitions returns the fields of entityType that accept values, keyed by key, for
+// callers such as the employee import that check many records against them at once
+func (s *CustomFieldService) ActiveDefinitions(ctx context.Context, tenantID pgtype.UUID, entityType string) (map[string]Definition, error) {
+	return s.activeDefinitions(ctx, s.queries, tenantID, entityType)
+}
+
+// FromText converts values read as text, such as the cells of an import file, to the types
+// of their fields and checks them as Validate does for a new record. A blank value leaves
+// its field unset, so every required field must have a non-blank one. Multi-select options
+// are separated by semicolons.
+func FromText(defs map[string]Definition, raw map[string]string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(raw))
+	for key, v := range raw {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		def, ok := defs[key]
+		if !ok {
+			return nil, &ValueError{Key: key, Message: "unknown field"}
+		}
+		value, err := textValue(def, v)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return validateValues(defs, map[string]interface{}{}, values)
+}
+
+// textValue converts v to the JSON type checkValue expects for def
+func textValue(def Definition, v string) (interface{}, error) {
+	switch def.FieldType {
+	case TypeNumber:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, &ValueError{Key: def.Key, Message: "must be a number"}
+		}
+		return n, nil
+	case TypeBoolean:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, &ValueError{Key: def.Key, Message: "must be true or false"}
+		}
+		return b, nil
+	case TypeMultiSelect:
+		parts := strings.Split(v, ";")
+		items := make([]interface{}, 0, len(parts))
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				items = append(items, p)
+			}
+		}
+		return items, nil
+	}
+	return v, nil
+}
+
+func (s *CustomFieldService) activeDefinitions(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, entityType string) (map[string]Definition, error) {
+	if !IsEntityType(entityType) {
+		return nil, ErrUnknownEntityType
+	}
+	rows, err := q.ListCustomFieldDefinitions(ctx, domain.ListCustomFieldDefinitionsParams{
+		TenantID:   tenantID,
+		EntityType: entityType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defs := make(map[string]Definition, len(rows))
+	for _, row := range rows {
+		defs[row.Key] = toDefinition(row)
+	}
+	return defs, nil
+}
+
+// checkEntity returns pgx.ErrNoRows when the record does not exist in the tenant
+func (s *CustomFieldService) checkEntity(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, entityType string, id pgtype.UUID) error {
+	var err error
+	switch entityType {
+	case EntityEmployee:
+		_, err = q.GetEmployee(ctx, domain.GetEmployeeParams{TenantID: tenantID, ID: id})
+	case EntityBusinessUnit:
+		_, err = q.GetBusinessUnit(ctx, domain.GetBusinessUnitParams{TenantID: tenantID, ID: id})
+	case EntityDepartment:
+		_, err = q.GetDepartment(ctx, domain.GetDepartmentParams{TenantID: tenantID, ID: id})
+	case EntityJobTitle:
+		_, err = q.GetJobTitle(ctx, domain.GetJobTitleParams{TenantID: tenantID, ID: id})
+	default:
+		return ErrUnknownEntityType
+	}
+	return err
+}
+
+// checkWritable is checkEntity for writes, which erased employees no longer accept. The
+// employee is locked so that, in SetValues, the write cannot interleave with an erasure.
+func (s *CustomFieldService) checkWritable(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, entityType string, id pgtype.UUID) error {
+	if entityType != EntityEmployee {
+		return s.checkEntity(ctx, q, tenantID, entityType, id)
+	}
+	emp, err := q.GetEmployeeForUpdate(ctx, domain.GetEmployeeForUpdateParams{TenantID: tenantID, ID: id})
+	if err != nil {
+		return err
+	}
+	if emp.ErasedAt.Valid {
+		return ErrErased
+	}
+	return nil
+}
+
+// validateValues applies changes to current and checks the result. Stored values of
+// fields that have since been deactivated are kept as they are.
+func validateValues(defs map[string]Definition, current, changes map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(current)+len(changes))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for key, v := range changes {
+		if v == nil {
+			delete(merged, key)
+			continue
+		}
+		def, ok := defs[key]
+		if !ok {
+			return nil, &ValueError{Key: key, Message: "unknown field"}
+		}
+		clean, err := checkValue(def, v)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = clean
+	}
+
+	for key, def := range defs {
+		if _, ok := merged[key]; !ok && def.IsRequired {
+			return nil, &ValueError{Key: key, Message: "is required"}
+		}
+	}
+	return merged, nil
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func checkValue(def Definition, v interface{}) (interface{}, error) {
+	fail := func(msg string) (interface{}, error) {
+		return nil, &ValueError{Key: def.Key, Message: msg}
+	}
+	r := def.Validation
+
+	switch def.FieldType {
+	case TypeText:
+		s, ok := v.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if def.IsRequired && s == "" {
+			return fail("is required")
+		}
+		n := utf8.RuneCountInString(s)
+		if r.MinLength != nil && n < *r.MinLength {
+			return fail(fmt.Sprintf("must be at least %d characters", *r.MinLength))
+		}
+		if r.MaxLength != nil && n > *r.MaxLength {
+			return fail(fmt.Sprintf("must be at most %d characters", *r.MaxLength))
+		}
+		if r.Pattern != nil {
+			if re, err := regexp.Compile(*r.Pattern); err == nil && !re.MatchString(s) {
+				return fail("does not match the required format")
+			}
+		}
+		return s, nil
+
+	case TypeNumber:
+		n, ok := v.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return fail("must be a number")
+		}
+		if r.Min != nil && n < *r.Min {
+			return fail(fmt.Sprintf("must be at least %v", *r.Min))
+		}
+		if r.Max != nil && n > *r.Max {
+			return fail(fmt.Sprintf("must be at most %v", *r.Max))
+		}
+		return n, nil
+
+	case TypeBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return fail("must be true or false")
+		}
+		return b, nil
+
+	case TypeDate:
+		s, ok := v.(string)
+		if !ok || !datePattern.MatchString(s) {
+			return fail("must be a date in YYYY-MM-DD format")
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return fail("must be a date in YYYY-MM-DD format")
+		}
+		return s, nil
+
+	case TypeSelect:
+		s, ok := v.(string)
+		if !ok || !hasOption(def.Options, s) {
+			return fail("must be one of the field's options")
+		}
+		return s, nil
+
+	case TypeMultiSelect:
+		items, ok := v.([]interface{})
+		if !ok {
+			return fail("must be a list of the field's options")
+		}
+		if def.IsRequired && len(items) == 0 {
+			return fail("is required")
+		}
+		out := make([]string, 0, len(items))
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok || !hasOption(def.Options, s) {
+				return fail("must be a list of the field's options")
+			}
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+		return out, nil
+	}
+	return fail("has an unsupported field type")
+}
+
+func hasOption(options []string, v string) bool {
+	for _, o := range options {
+		if o == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package customfields
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testDefinitions() map[string]Definition {
+	return map[string]Definition{
+		"shift_pattern": {Key: "shift_pattern", FieldType: TypeSelect, IsRequired: true, Options: []string{"Days", "Nights"}},
+		"hours":         {Key: "hours", FieldType: TypeNumber},
+		"first_aider":   {Key: "first_aider", FieldType: TypeBoolean},
+		"start_date":    {Key: "start_date", FieldType: TypeDate},
+		"licences":      {Key: "licences", FieldType: TypeMultiSelect, Options: []string{"FLT", "MEWP", "CPC"}},
+	}
+}
+
+func TestFromText(t *testing.T) {
+	got, err := FromText(testDefinitions(), map[string]string{
+		"shift_pattern": "Nights",
+		"hours":         " 37.5 ",
+		"first_aider":   "true",
+		"start_date":    "2026-03-01",
+		"licences":      "FLT; MEWP;;FLT",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"shift_pattern": "Nights",
+		"hours":         37.5,
+		"first_aider":   true,
+		"start_date":    "2026-03-01",
+		"licences":      []string{"FLT", "MEWP"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FromText() = %#v, want %#v", got, want)
+	}
+}
+
+func TestFromTextEnforcesDefinitions(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  map[string]string
+		key  string
+	}{
+		{"required field missing", map[string]string{"hours": "40"}, "shift_pattern"},
+		{"required field blank", map[string]string{"shift_pattern": "  "}, "shift_pattern"},
+		{"no values at all", nil, "shift_pattern"},
+		{"not an option", map[string]string{"shift_pattern": "Lates"}, "shift_pattern"},
+		{"not a number", map[string]string{"shift_pattern": "Days", "hours": "forty"}, "hours"},
+		{"not a boolean", map[string]string{"shift_pattern": "Days", "first_aider": "maybe"}, "first_aider"},
+		{"bad date", map[string]string{"shift_pattern": "Days", "start_date": "01/03/2026"}, "start_date"},
+		{"unknown option in list", map[string]string{"shift_pattern": "Days", "licences": "FLT;HGV"}, "licences"},
+		{"unknown field", map[string]string{"shift_pattern": "Days", "shoe_size": "9"}, "shoe_size"},
+	}
+	for _, tc := range cases {
+		_, err := FromText(testDefinitions(), tc.raw)
+		var valueErr *ValueError
+		if !errors.As(err, &valueErr) {
+			t.Errorf("%s: FromText() error = %v, want a ValueError", tc.name, err)
+			continue
+		}
+		if valueErr.Key != tc.key {
+			t.Errorf("%s: FromText() failed on %q, want %q", tc.name, valueErr.Key, tc.key)
+		}
+	}
+}
+
+func TestFromTextLeavesOptionalFieldsUnset(t *testing.T) {
+	got, err := FromText(testDefinitions(), map[string]string{"shift_pattern": "Days", "hours": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["hours"]; ok {
+		t.Errorf("FromText() set a blank optional field: %#v", got)
+	}
+}
@@ -0,0 +1,193 @@
+// Package events relays entity change notifications from Postgres to in-process subscribers
+// such as the server-sent event stream.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Channel is the Postgres notification channel raised for every stored audit entry
+const Channel = "entity_changes"
+
+const (
+	// subscriberBuffer is how many changes may queue for a slow subscriber before new ones are dropped
+	subscriberBuffer = 64
+	// reconnectDelay is how long the listener waits before re-establishing a lost connection
+	reconnectDelay = 5 * time.Second
+)
+
+// Change describes a single mutation of a tenant record, named as in the audit log
+type Change struct {
+	ID         pgtype.UUID `json:"id"`
+	TenantID   pgtype.UUID `json:"tenantId"`
+	EntityType string      `json:"entityType"`
+	EntityID   pgtype.UUID `json:"entityId"`
+	Action     string      `json:"action"`
+	ActorID    pgtype.UUID `json:"actorId"`
+	// ActorAPIKeyID is set when the change was made through an API key
+	ActorAPIKeyID pgtype.UUID `json:"actorApiKeyId"`
+	// ImpersonatorID is set when an administrator made the change acting as the actor
+	ImpersonatorID pgtype.UUID `json:"impersonatorId"`
+	OccurredAt     time.Time   `json:"occurredAt"`
+}
+
+// auditOnlyEntities are the entity types whose changes are only streamed to subscribers who
+// may read the audit log, as they reveal when someone's credentials or personal data changed
+var auditOnlyEntities = map[string]bool{
+	"ApiKeys":                 true,
+	"EmployeePersonalDetails": true,
+	"MfaPolicies":             true,
+	"ServiceAccounts":         true,
+	"SsoIdentities":           true,
+	"UserMfa":                 true,
+}
+
+// AuditOnly reports whether the change may only be streamed to subscribers who can read
+// the audit log
+func (c Change) AuditOnly() bool {
+	return auditOnlyEntities[c.EntityType]
+}
+
+// WithoutActors returns the change without who made it, for subscribers who cannot read
+// the audit log
+func (c Change) WithoutActors() Change {
+	c.ActorID = pgtype.UUID{}
+	c.ActorAPIKeyID = pgtype.UUID{}
+	c.ImpersonatorID = pgtype.UUID{}
+	return c
+}
+
+type subscriber struct {
+	tenantID pgtype.UUID
+	ch       chan Change
+}
+
+// Broker holds one LISTEN connection per process and fans its notifications out to the
+// subscribers of the matching tenant
+type Broker struct {
+	db     *db.DB
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[*subscriber]struct{}
+}
+
+// NewBroker creates a broker and starts listening for changes
+func NewBroker(database *db.DB) *Broker {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Broker{
+		db:          database,
+		ctx:         ctx,
+		cancel:      cancel,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	go b.listen()
+	return b
+}
+
+// Subscribe returns a channel receiving the changes of a tenant and a function that ends the
+// subscription. The channel is closed when the subscription ends or the broker shuts down.
+func (b *Broker) Subscribe(tenantID pgtype.UUID) (<-chan Change, func()) {
+	sub := &subscriber{tenantID: tenantID, ch: make(chan Change, subscriberBuffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close stops listening and ends every subscription, letting open streams finish so the
+// server can shut down
+func (b *Broker) Close() {
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+func (b *Broker) publish(change Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		if sub.tenantID != change.TenantID {
+			continue
+		}
+		select {
+		case sub.ch <- change:
+		default:
+			log.Printf("events dropped change %s for a slow subscriber", change.EntityType)
+		}
+	}
+}
+
+// listen keeps a LISTEN connection open, reconnecting after failures until the broker is closed.
+// Changes raised while the connection is down are not recovered.
+func (b *Broker) listen() {
+	for {
+		err := b.listenOnce()
+		if b.ctx.Err() != nil {
+			return
+		}
+		log.Printf("events listener lost its connection, retrying in %s: %v", reconnectDelay, err)
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Broker) listenOnce() error {
+	pooled, err := b.db.Pool.Acquire(b.ctx)
+	if err != nil {
+		return err
+	}
+	// The connection is taken out of the pool for good; it is dedicated to LISTEN
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(b.ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(b.ctx)
+		if err != nil {
+			return err
+		}
+
+		var change Change
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			log.Printf("events failed decoding notification: %v", err)
+			continue
+		}
+		b.publish(change)
+	}
+}
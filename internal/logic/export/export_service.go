@@ -0,0 +1,582 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/INOVA/DML/internal/logic/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Dataset string
+
+const (
+	DatasetEmployees Dataset = "employees"
+	DatasetUsers     Dataset = "users"
+	DatasetOrg       Dataset = "org"
+)
+
+const (
+	// AsyncExportThreshold is the row count above which exports are written to storage
+	// by the background job runner instead of being streamed in the request
+	AsyncExportThreshold = 5000
+
+	// exportBatchSize is the page size used while streaming rows out of the database
+	exportBatchSize = 1000
+)
+
+// JobKind returns the background_jobs kind used for exports of the dataset
+func (d Dataset) JobKind() string {
+	switch d {
+	case DatasetEmployees:
+		return "employee_export"
+	case DatasetUsers:
+		return "user_export"
+	default:
+		return "org_export"
+	}
+}
+
+// Filter mirrors the filters accepted by the corresponding list endpoints.
+// Department filtering only applies to employee exports. CustomFields holds the cf.<key>
+// values, which apply to employee and org exports.
+type Filter struct {
+	Search                string            `json:"search,omitempty"`
+	DepartmentID          pgtype.UUID       `json:"departmentId"`
+	IncludeSubDepartments bool              `json:"includeSubDepartments"`
+	CustomFields          map[string]string `json:"customFields,omitempty"`
+}
+
+type ExportService struct {
+	db           *db.DB
+	runner       *jobs.Runner
+	customFields *customfields.CustomFieldService
+}
+
+func NewExportService(database *db.DB, runner *jobs.Runner, customFieldService *customfields.CustomFieldService) *ExportService {
+	return &ExportService{
+		db:           database,
+		runner:       runner,
+		customFields: customFieldService,
+	}
+}
+
+// customFieldFilter holds the JSONB containment documents the list queries take for a
+// filter's cf.<key> values, by entity type. An entity type that is missing cannot match.
+type customFieldFilter map[string][]byte
+
+// active reports whether the export is filtered on custom fields at all
+func (f customFieldFilter) active() bool {
+	return f != nil
+}
+
+// excludes reports whether no record of entityType can match the filter
+func (f customFieldFilter) excludes(entityType string) bool {
+	_, ok := f[entityType]
+	return f.active() && !ok
+}
+
+// customFieldFilter converts the filter's cf.<key> values as the list endpoints do. The org
+// export spans several entity types: a type that does not define every key, or whose field
+// types reject the values, cannot match and is left out, but at least one must accept them.
+func (s *ExportService) customFieldFilter(ctx context.Context, tenantID pgtype.UUID, dataset Dataset, filter Filter) (customFieldFilter, error) {
+	if len(filter.CustomFields) == 0 {
+		return nil, nil
+	}
+
+	var entityTypes []string
+	switch dataset {
+	case DatasetEmployees:
+		entityTypes = []string{customfields.EntityEmployee}
+	case DatasetOrg:
+		entityTypes = []string{customfields.EntityBusinessUnit, customfields.EntityDepartment, customfields.EntityJobTitle}
+	default:
+		return nil, nil
+	}
+
+	out := make(customFieldFilter, len(entityTypes))
+	var firstErr error
+	for _, entityType := range entityTypes {
+		doc, err := s.customFields.Filter(ctx, tenantID, entityType, filter.CustomFields)
+		var valueErr *customfields.ValueError
+		if errors.As(err, &valueErr) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[entityType] = doc
+	}
+	if len(out) == 0 {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// FileName builds the download name for an export, e.g. employees-20240131-0930.csv
+func FileName(dataset Dataset, format Format) string {
+	return fmt.Sprintf("%s-%s.%s", dataset, time.Now().UTC().Format("20060102-1504"), format)
+}
+
+// CountRows estimates the size of an export so callers can decide whether to run it in the background.
+// Custom field filters the dataset cannot satisfy are reported as a *customfields.ValueError.
+func (s *ExportService) CountRows(ctx context.Context, tenantID pgtype.UUID, dataset Dataset, filter Filter) (int64, error) {
+	q := domain.New(s.db.Pool)
+
+	cf, err := s.customFieldFilter(ctx, tenantID, dataset, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	switch dataset {
+	case DatasetEmployees:
+		return q.CountEmployees(ctx, domain.CountEmployeesParams{
+			TenantID:              tenantID,
+			Search:                filter.Search,
+			DepartmentID:          filter.DepartmentID,
+			IncludeSubDepartments: filter.IncludeSubDepartments,
+			CustomFields:          cf[customfields.EntityEmployee],
+		})
+	case DatasetUsers:
+		return q.CountUsers(ctx, domain.CountUsersParams{TenantID: tenantID, Search: filter.Search})
+	}
+
+	var total int64
+	if !cf.excludes(customfields.EntityBusinessUnit) {
+		bus, err := q.CountBusinessUnits(ctx, domain.CountBusinessUnitsParams{TenantID: tenantID, Search: filter.Search, CustomFields: cf[customfields.EntityBusinessUnit]})
+		if err != nil {
+			return 0, err
+		}
+		total += bus
+	}
+	if !cf.excludes(customfields.EntityDepartment) {
+		depts, err := q.CountDepartments(ctx, domain.CountDepartmentsParams{TenantID: tenantID, Search: filter.Search, CustomFields: cf[customfields.EntityDepartment]})
+		if err != nil {
+			return 0, err
+		}
+		total += depts
+	}
+	if !cf.excludes(customfields.EntityJobTitle) {
+		titles, err := q.CountJobTitles(ctx, domain.CountJobTitlesParams{TenantID: tenantID, Search: filter.Search, CustomFields: cf[customfields.EntityJobTitle]})
+		if err != nil {
+			return 0, err
+		}
+		total += titles
+	}
+	// Job grades have no custom fields
+	if !cf.active() {
+		grades, err := q.CountJobGrades(ctx, domain.CountJobGradesParams{TenantID: tenantID, Search: filter.Search})
+		if err != nil {
+			return 0, err
+		}
+		total += grades
+	}
+	return total, nil
+}
+
+// Export streams a dataset to w in the given format and returns the number of data rows written.
+// Everything is read from a single read-only snapshot so that batches stay consistent with each other.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, tenantID pgtype.UUID, dataset Dataset, format Format, filter Filter, progress *jobs.Progress) (int, error) {
+	tx, err := s.db.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start export snapshot: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	cf, err := s.customFieldFilter(ctx, tenantID, dataset, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	switch dataset {
+	case DatasetEmployees:
+		return exportEmployees(ctx, qtx, w, tenantID, format, filter, cf, progress)
+	case DatasetUsers:
+		return exportUsers(ctx, qtx, w, tenantID, format, filter, progress)
+	default:
+		return exportOrg(ctx, qtx, w, tenantID, format, filter, cf, progress)
+	}
+}
+
+// SubmitExport runs Export on the background job runner, writing the file to storage
+// from where it can be downloaded through the job once it has succeeded.
+func (s *ExportService) SubmitExport(ctx context.Context, tenantID, actorID pgtype.UUID, dataset Dataset, format Format, filter Filter, total int) (jobs.Job, error) {
+	fileName := FileName(dataset, format)
+	key := fmt.Sprintf("%s/exports/%s.%s", uuid.UUID(tenantID.Bytes), uuid.New(), format)
+
+	return s.runner.Submit(ctx, tenantID, actorID, dataset.JobKind(), total, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		store := s.runner.Store()
+
+		f, err := store.Create(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create export file: %w", err)
+		}
+
+		rows, err := s.Export(ctx, f, tenantID, dataset, format, filter, progress)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = store.Delete(ctx, key)
+			return nil, err
+		}
+
+		return jobs.FileResult{
+			StorageKey:  key,
+			FileName:    fileName,
+			ContentType: format.ContentType(),
+			Rows:        rows,
+		}, nil
+	})
+}
+
+var employeeColumns = []column{
+	{"employeeNo", "Employee No"},
+	{"firstName", "First Name"},
+	{"lastName", "Last Name"},
+	{"displayName", "Display Name"},
+	{"workEmail", "Work Email"},
+	{"status", "Status"},
+	{"isActive", "Active"},
+	{"businessUnitCode", "Business Unit Code"},
+	{"businessUnitName", "Business Unit Name"},
+	{"departmentCode", "Department Code"},
+	{"departmentName", "Department Name"},
+	{"jobTitleCode", "Job Title Code"},
+	{"jobTitleName", "Job Title Name"},
+	{"jobTitleGrade", "Job Title Grade"},
+	{"managerEmployeeNo", "Manager Employee No"},
+	{"managerName", "Manager Name"},
+	{"createdAt", "Created At"},
+}
+
+func exportEmployees(ctx context.Context, q *domain.Queries, w io.Writer, tenantID pgtype.UUID, format Format, filter Filter, cf customFieldFilter, progress *jobs.Progress) (int, error) {
+	tw, err := newTableWriter(w, format, "Employees", employeeColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for offset := int32(0); ; offset += exportBatchSize {
+		rows, err := q.ListEmployeesWithDetails(ctx, domain.ListEmployeesWithDetailsParams{
+			TenantID:              tenantID,
+			Search:                filter.Search,
+			DepartmentID:          filter.DepartmentID,
+			IncludeSubDepartments: filter.IncludeSubDepartments,
+			CustomFields:          cf[customfields.EntityEmployee],
+			Limit:                 exportBatchSize,
+			Offset:                offset,
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to read employees: %w", err)
+		}
+
+		for _, row := range rows {
+			managerName := text(row.ManagerDisplayName)
+			if managerName == "" && row.ManagerFirstName.Valid {
+				managerName = strings.TrimSpace(row.ManagerFirstName.String + " " + text(row.ManagerLastName))
+			}
+
+			if err := tw.WriteRow([]string{
+				row.EmployeeNo,
+				row.FirstName,
+				row.LastName,
+				text(row.DisplayName),
+				text(row.WorkEmail),
+				row.Status,
+				strconv.FormatBool(row.IsActive),
+				text(row.BusinessUnitCode),
+				text(row.BusinessUnitName),
+				text(row.DepartmentCode),
+				text(row.DepartmentName),
+				text(row.JobTitleCode),
+				text(row.JobTitleName),
+				text(row.JobTitleGrade),
+				text(row.ManagerEmployeeNo),
+				managerName,
+				timestamp(row.CreatedAt),
+			}); err != nil {
+				return written, err
+			}
+			written++
+		}
+		progress.Add(len(rows))
+
+		if len(rows) < exportBatchSize {
+			break
+		}
+	}
+
+	return written, tw.Close()
+}
+
+var userColumns = []column{
+	{"email", "Email"},
+	{"displayName", "Display Name"},
+	{"isActive", "Active"},
+	{"roles", "Roles"},
+	{"employeeId", "Employee ID"},
+	{"lastLoginAt", "Last Login At"},
+	{"createdAt", "Created At"},
+}
+
+func exportUsers(ctx context.Context, q *domain.Queries, w io.Writer, tenantID pgtype.UUID, format Format, filter Filter, progress *jobs.Progress) (int, error) {
+	assignments, err := q.ListUserRoleCodes(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read role assignments: %w", err)
+	}
+	roles := make(map[[16]byte][]string)
+	for _, a := range assignments {
+		roles[a.UserID.Bytes] = append(roles[a.UserID.Bytes], a.Code)
+	}
+
+	tw, err := newTableWriter(w, format, "Users", userColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for offset := int32(0); ; offset += exportBatchSize {
+		users, err := q.ListUsers(ctx, domain.ListUsersParams{
+			TenantID: tenantID,
+			Search:   filter.Search,
+			Limit:    exportBatchSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to read users: %w", err)
+		}
+
+		for _, u := range users {
+			if err := tw.WriteRow([]string{
+				u.Email,
+				text(u.DisplayName),
+				strconv.FormatBool(u.IsActive),
+				strings.Join(roles[u.ID.Bytes], ";"),
+				uuidString(u.EmployeeID),
+				timestamp(u.LastLoginAt),
+				timestamp(u.CreatedAt),
+			}); err != nil {
+				return written, err
+			}
+			written++
+		}
+		progress.Add(len(users))
+
+		if len(users) < exportBatchSize {
+			break
+		}
+	}
+
+	return written, tw.Close()
+}
+
+var orgColumns = []column{
+	{"type", "Type"},
+	{"code", "Code"},
+	{"name", "Name"},
+	{"parentCode", "Parent Code"},
+	{"path", "Path"},
+	{"depth", "Depth"},
+	{"grade", "Grade"},
+	{"isActive", "Active"},
+}
+
+// exportOrg flattens the organisation structure: business units (sites), the department
+// hierarchy in depth-first order with materialised paths, job grades and job titles.
+func exportOrg(ctx context.Context, q *domain.Queries, w io.Writer, tenantID pgtype.UUID, format Format, filter Filter, cf customFieldFilter, progress *jobs.Progress) (int, error) {
+	matched, err := customFieldMatches(ctx, q, tenantID, cf)
+	if err != nil {
+		return 0, err
+	}
+
+	bus, err := q.ListAllBusinessUnits(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read business units: %w", err)
+	}
+	depts, err := q.ListAllDepartments(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read departments: %w", err)
+	}
+	titles, err := q.ListAllJobTitles(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read job titles: %w", err)
+	}
+	grades, err := q.ListAllJobGrades(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read job grades: %w", err)
+	}
+
+	tw, err := newTableWriter(w, format, "Organisation", orgColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	emit := func(entityType string, id pgtype.UUID, code pgtype.Text, name string, values []string) error {
+		if !matches(filter.Search, text(code), name) {
+			return nil
+		}
+		if cf.active() && !matched[entityType][id.Bytes] {
+			return nil
+		}
+		written++
+		return tw.WriteRow(values)
+	}
+
+	for _, bu := range bus {
+		if err := emit(customfields.EntityBusinessUnit, bu.ID, bu.Code, bu.Name, []string{
+			"business_unit", text(bu.Code), bu.Name, "", bu.Name, "0", "", strconv.FormatBool(bu.IsActive),
+		}); err != nil {
+			return written, err
+		}
+	}
+
+	byID := make(map[[16]byte]domain.Department, len(depts))
+	children := make(map[[16]byte][]domain.Department)
+	var roots []domain.Department
+	for _, d := range depts {
+		byID[d.ID.Bytes] = d
+	}
+	for _, d := range depts {
+		if _, ok := byID[d.ParentDepartmentID.Bytes]; d.ParentDepartmentID.Valid && ok {
+			children[d.ParentDepartmentID.Bytes] = append(children[d.ParentDepartmentID.Bytes], d)
+		} else {
+			roots = append(roots, d)
+		}
+	}
+
+	visited := make(map[[16]byte]bool, len(depts))
+	var walk func(d domain.Department, parentCode string, path []string) error
+	walk = func(d domain.Department, parentCode string, path []string) error {
+		if visited[d.ID.Bytes] {
+			return nil
+		}
+		visited[d.ID.Bytes] = true
+
+		path = append(path, d.Name)
+		if err := emit(customfields.EntityDepartment, d.ID, d.Code, d.Name, []string{
+			"department", text(d.Code), d.Name, parentCode, strings.Join(path, " / "), strconv.Itoa(len(path) - 1), "", strconv.FormatBool(d.IsActive),
+		}); err != nil {
+			return err
+		}
+		for _, child := range children[d.ID.Bytes] {
+			if err := walk(child, text(d.Code), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := walk(root, "", nil); err != nil {
+			return written, err
+		}
+	}
+	// Departments caught in a parent cycle are unreachable from any root; export them flat
+	for _, d := range depts {
+		if !visited[d.ID.Bytes] {
+			parentCode := ""
+			if parent, ok := byID[d.ParentDepartmentID.Bytes]; ok {
+				parentCode = text(parent.Code)
+			}
+			if err := walk(d, parentCode, nil); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	gradeCodes := make(map[[16]byte]string, len(grades))
+	for _, g := range grades {
+		gradeCodes[g.ID.Bytes] = g.Code
+		if err := emit("", g.ID, pgtype.Text{String: g.Code, Valid: true}, g.Name, []string{
+			"job_grade", g.Code, g.Name, "", g.Name, "0", g.Code, strconv.FormatBool(g.IsActive),
+		}); err != nil {
+			return written, err
+		}
+	}
+
+	for _, jt := range titles {
+		grade := ""
+		if jt.GradeID.Valid {
+			grade = gradeCodes[jt.GradeID.Bytes]
+		}
+		if err := emit(customfields.EntityJobTitle, jt.ID, jt.Code, jt.Name, []string{
+			"job_title", text(jt.Code), jt.Name, "", jt.Name, "0", grade, strconv.FormatBool(jt.IsActive),
+		}); err != nil {
+			return written, err
+		}
+	}
+
+	progress.Add(written)
+	return written, tw.Close()
+}
+
+// customFieldMatches applies the list endpoints' custom field filter for each entity type
+// of the org export, returning the IDs of the records that match it
+func customFieldMatches(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, cf customFieldFilter) (map[string]map[[16]byte]bool, error) {
+	matched := make(map[string]map[[16]byte]bool, len(cf))
+	for entityType, doc := range cf {
+		ids, err := q.ListCustomFieldMatches(ctx, domain.ListCustomFieldMatchesParams{
+			TenantID:     tenantID,
+			EntityType:   entityType,
+			CustomFields: doc,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read custom field values: %w", err)
+		}
+		matched[entityType] = make(map[[16]byte]bool, len(ids))
+		for _, id := range ids {
+			matched[entityType][id.Bytes] = true
+		}
+	}
+	return matched, nil
+}
+
+// matches applies the list endpoints' case-insensitive name/code search in memory
+func matches(search, code, name string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	return strings.Contains(strings.ToLower(code), search) || strings.Contains(strings.ToLower(name), search)
+}
+
+func text(t pgtype.Text) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.String
+}
+
+func timestamp(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func uuidString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/INOVA/DML/internal/xlsx"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates a requested export format; an empty value means CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q, expected csv, xlsx or json", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// column pairs the JSON key of an exported field with its spreadsheet header.
+// Headers are chosen so that employee exports can be fed back into the importer.
+type column struct {
+	key   string
+	label string
+}
+
+// tableWriter receives rows in column order and renders them in one format
+type tableWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+func newTableWriter(w io.Writer, format Format, sheet string, columns []column) (tableWriter, error) {
+	switch format {
+	case FormatXLSX:
+		// Cells are written as inline strings, which spreadsheets never evaluate
+		xw, err := xlsx.NewWriter(w, sheet)
+		if err != nil {
+			return nil, err
+		}
+		if err := xw.WriteRow(labels(columns)); err != nil {
+			return nil, err
+		}
+		return xw, nil
+	case FormatJSON:
+		return newJSONWriter(w, columns)
+	default:
+		sw := formulaEscaper{&csvWriter{w: csv.NewWriter(w)}}
+		if err := sw.WriteRow(labels(columns)); err != nil {
+			return nil, err
+		}
+		return sw, nil
+	}
+}
+
+func labels(columns []column) []string {
+	out := make([]string, len(columns))
+	for i, c := range columns {
+		out[i] = c.label
+	}
+	return out
+}
+
+// formulaEscaper guards a CSV writer against formula injection: a cell that starts like a
+// formula is prefixed with an apostrophe, so spreadsheet applications show employee-controlled
+// text such as names and custom field values instead of evaluating it. The importer strips
+// the apostrophe again.
+type formulaEscaper struct {
+	tableWriter
+}
+
+func (s formulaEscaper) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = escapeFormula(v)
+	}
+	return s.tableWriter.WriteRow(escaped)
+}
+
+func escapeFormula(v string) string {
+	if v == "" {
+		return v
+	}
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + v
+	}
+	return v
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	return c.w.Write(values)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter streams a JSON array of objects keyed by column key
+type jsonWriter struct {
+	w       *bufio.Writer
+	keys    [][]byte
+	written bool
+}
+
+func newJSONWriter(w io.Writer, columns []column) (*jsonWriter, error) {
+	jw := &jsonWriter{w: bufio.NewWriter(w)}
+	for _, c := range columns {
+		key, err := json.Marshal(c.key)
+		if err != nil {
+			return nil, err
+		}
+		jw.keys = append(jw.keys, key)
+	}
+	if _, err := jw.w.WriteString("["); err != nil {
+		return nil, err
+	}
+	return jw, nil
+}
+
+func (j *jsonWriter) WriteRow(values []string) error {
+	if j.written {
+		if _, err := j.w.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	j.written = true
+
+	j.w.WriteByte('{')
+	for i, key := range j.keys {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		j.w.Write(key)
+		j.w.WriteByte(':')
+
+		if i >= len(values) || values[i] == "" {
+			j.w.WriteString("null")
+			continue
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		j.w.Write(value)
+	}
+	return j.w.WriteByte('}')
+}
+
+func (j *jsonWriter) Close() error {
+	if _, err := j.w.WriteString("]\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
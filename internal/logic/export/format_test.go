@@ -0,0 +1,126 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/xlsx"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Ada", "Ada"},
+		{"ada@example.com", "ada@example.com"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{" =1", " =1"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTableWriterEscapesCSVOnly(t *testing.T) {
+	columns := []column{{key: "firstName", label: "First Name"}}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatCSV, "First Name\n'=1+1\n"},
+		{FormatJSON, "[{\"firstName\":\"=1+1\"}]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := newTableWriter(&buf, tt.format, "Employees", columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow([]string{"=1+1"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s export = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXCellsAreNotEscaped(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newTableWriter(&buf, FormatXLSX, "Employees", []column{{key: "firstName", label: "First Name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"-5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := xlsx.ReadFirstSheet(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"First Name"}, {"-5"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("XLSX export = %q, want %q", rows, want)
+	}
+}
+
+// Employee exports feed back into the importer, so the CSV escaping must not change values
+func TestCSVExportImportRoundTrip(t *testing.T) {
+	columns := append(append([]column{}, employeeColumns...), column{key: "shiftOffset", label: "cf.shift_offset"})
+	row := make([]string, len(columns))
+	row[0] = "-E001"
+	row[1] = "=Ada"
+	row[2] = "+Lovelace"
+	row[4] = "@ada@example.com"
+	row[len(row)-1] = "-5"
+
+	var buf bytes.Buffer
+	w, err := newTableWriter(&buf, FormatCSV, "Employees", columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("'=Ada")) {
+		t.Fatalf("export = %q, want the first name escaped", buf.String())
+	}
+
+	rows, err := hr.ParseImportFile("employees.csv", buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseImportFile() error = %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("ParseImportFile() = %d rows, want 1", len(rows))
+	}
+	got := rows[0]
+	want := hr.ImportRow{
+		Row:          2,
+		EmployeeNo:   "-E001",
+		FirstName:    "=Ada",
+		LastName:     "+Lovelace",
+		WorkEmail:    "@ada@example.com",
+		CustomFields: map[string]string{"shift_offset": "-5"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImportFile() = %+v, want %+v", got, want)
+	}
+}
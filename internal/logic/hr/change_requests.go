@@ -0,0 +1,663 @@
+package hr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/tasks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Change request statuses
+const (
+	ChangePending   = "pending"
+	ChangeApproved  = "approved"
+	ChangeRejected  = "rejected"
+	ChangeCancelled = "cancelled"
+)
+
+// ChangeRequestEntity is the audit log entity type of change requests, also used for their
+// tasks, notifications and emails
+const ChangeRequestEntity = "EmployeeChangeRequests"
+
+// changeApproverRoles are the roles that decide requests of employees without a manager,
+// in order of preference. The first one the tenant has is used.
+var changeApproverRoles = []string{"HR_ADMIN", "ADMIN"}
+
+var (
+	// ErrNoLinkedEmployee is returned when the requesting user has no employee record
+	ErrNoLinkedEmployee = errors.New("your account is not linked to an employee record")
+
+	// ErrNoChanges is returned when a request would not change anything
+	ErrNoChanges = errors.New("the request does not change any field")
+
+	// ErrChangePending is returned when the employee already has a request awaiting a decision
+	ErrChangePending = errors.New("a change request is already awaiting a decision")
+
+	// ErrChangeClosed is returned when deciding or cancelling a request that is no longer pending
+	ErrChangeClosed = errors.New("change request is already approved, rejected or cancelled")
+
+	// ErrNotApprover is returned when someone other than the approver or an administrator decides a request
+	ErrNotApprover = errors.New("only the approver or an administrator can decide this request")
+
+	// ErrSelfApproval is returned when an employee decides their own request
+	ErrSelfApproval = errors.New("you cannot decide your own change request")
+
+	// ErrNotRequester is returned when someone other than the employee cancels a request
+	ErrNotRequester = errors.New("only the employee can cancel their change request")
+
+	// ErrStaleChange is returned on approval when a field was changed since the request was raised
+	ErrStaleChange = errors.New("the employee record has changed since the request was raised")
+
+	// ErrOrgUnitNotFound is returned when a proposed business unit or department does not exist
+	ErrOrgUnitNotFound = errors.New("business unit or department does not exist or is inactive")
+
+	// ErrNoApprover is returned when an employee has no manager and the tenant has no HR role
+	ErrNoApprover = errors.New("no manager or HR role is available to approve the request")
+)
+
+// FieldChange is one field of a change request, with its value when the request was raised
+type FieldChange struct {
+	From *string `json:"from"`
+	To   *string `json:"to"`
+}
+
+// ChangeRequest is the API representation of a proposed change to an employee record.
+// Changes is keyed by the employee column, e.g. "work_email".
+type ChangeRequest struct {
+	ID                 pgtype.UUID            `json:"id"`
+	EmployeeID         pgtype.UUID            `json:"employeeId"`
+	Changes            map[string]FieldChange `json:"changes"`
+	Reason             *string                `json:"reason"`
+	ApproverEmployeeID pgtype.UUID            `json:"approverEmployeeId"`
+	ApproverRoleCode   *string                `json:"approverRoleCode"`
+	Status             string                 `json:"status"`
+	DecisionNotes      *string                `json:"decisionNotes"`
+	DecidedByUserID    pgtype.UUID            `json:"decidedByUserId"`
+	DecidedAt          pgtype.Timestamptz     `json:"decidedAt"`
+	RequestedByUserID  pgtype.UUID            `json:"requestedByUserId"`
+	CreatedAt          pgtype.Timestamptz     `json:"createdAt"`
+	UpdatedAt          pgtype.Timestamptz     `json:"updatedAt"`
+}
+
+func toChangeRequest(r domain.EmployeeChangeRequest) ChangeRequest {
+	changes := map[string]FieldChange{}
+	_ = json.Unmarshal(r.Changes, &changes)
+	return ChangeRequest{
+		ID:                 r.ID,
+		EmployeeID:         r.EmployeeID,
+		Changes:            changes,
+		Reason:             textPtr(r.Reason),
+		ApproverEmployeeID: r.ApproverEmployeeID,
+		ApproverRoleCode:   textPtr(r.ApproverRoleCode),
+		Status:             r.Status,
+		DecisionNotes:      textPtr(r.DecisionNotes),
+		DecidedByUserID:    r.DecidedByUserID,
+		DecidedAt:          r.DecidedAt,
+		RequestedByUserID:  r.RequestedByUserID,
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}
+}
+
+// ChangeInput holds the fields an employee proposes to change. Nil fields are left as they
+// are; an empty display name or work email clears it. Transfers move the employee to
+// another department and, optionally, business unit.
+type ChangeInput struct {
+	FirstName      *string
+	LastName       *string
+	DisplayName    *string
+	WorkEmail      *string
+	BusinessUnitID pgtype.UUID
+	DepartmentID   pgtype.UUID
+	Reason         *string
+}
+
+// ChangeRequestFilter narrows change request listings. Status is empty for every status.
+type ChangeRequestFilter struct {
+	Status     string
+	EmployeeID pgtype.UUID
+}
+
+type ChangeRequestService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	taskSvc  *tasks.TaskService
+	auditSvc *audit.AuditService
+}
+
+func NewChangeRequestService(database *db.DB, taskSvc *tasks.TaskService, auditSvc *audit.AuditService) *ChangeRequestService {
+	return &ChangeRequestService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		taskSvc:  taskSvc,
+		auditSvc: auditSvc,
+	}
+}
+
+// RequestChange records a change the signed-in user proposes to their own employee record
+// and puts it in the task inbox of their manager, or of HR when they have none
+func (s *ChangeRequestService) RequestChange(ctx context.Context, id, tenantID, actorID pgtype.UUID, in ChangeInput) (ChangeRequest, error) {
+	emp, err := s.linkedEmployee(ctx, tenantID, actorID)
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+
+	changes := diffEmployee(emp, in)
+	if len(changes) == 0 {
+		return ChangeRequest{}, ErrNoChanges
+	}
+	if err := s.checkTransfer(ctx, tenantID, emp, in); err != nil {
+		return ChangeRequest{}, err
+	}
+
+	params := domain.CreateEmployeeChangeRequestParams{
+		ID:                id,
+		TenantID:          tenantID,
+		EmployeeID:        emp.ID,
+		Reason:            optionalText(stringValue(in.Reason)),
+		RequestedByUserID: actorID,
+	}
+	if params.Changes, err = json.Marshal(changes); err != nil {
+		return ChangeRequest{}, err
+	}
+
+	task := tasks.TaskInput{
+		EntityType: ChangeRequestEntity,
+		EntityID:   id,
+		Kind:       tasks.KindApproval,
+		Title:      ChangeRequestTaskTitle(emp),
+		Description: optionalString(fmt.Sprintf("Proposed changes to %s.",
+			strings.Join(sortedKeys(changes), ", "))),
+	}
+	if emp.ManagerID.Valid {
+		params.ApproverEmployeeID = emp.ManagerID
+		task.AssigneeEmployeeID = emp.ManagerID
+	} else {
+		role, err := s.approverRole(ctx, tenantID)
+		if err != nil {
+			return ChangeRequest{}, err
+		}
+		params.ApproverRoleCode = pgtype.Text{String: role, Valid: true}
+		task.AssigneeRoleCode = role
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to begin change request transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	req, err := qtx.CreateEmployeeChangeRequest(ctx, params)
+	if err != nil {
+		return ChangeRequest{}, mapChangeRequestError(err)
+	}
+
+	if s.taskSvc != nil {
+		var taskID pgtype.UUID
+		taskID.Bytes = uuid.New()
+		taskID.Valid = true
+
+		if _, err := s.taskSvc.CreateTaskTx(ctx, qtx, taskID, tenantID, actorID, task); err != nil {
+			return ChangeRequest{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to commit change request: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", ChangeRequestEntity, id.Bytes, map[string]interface{}{
+			"employee_id": emp.ID,
+			"changes":     changes,
+			"approver_id": params.ApproverEmployeeID,
+			"role":        textPtr(params.ApproverRoleCode),
+		})
+	}
+	return toChangeRequest(req), nil
+}
+
+// ListChangeRequests lists the tenant's change requests, newest first
+func (s *ChangeRequestService) ListChangeRequests(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, filter ChangeRequestFilter) ([]ChangeRequest, int64, error) {
+	rows, err := s.queries.ListEmployeeChangeRequests(ctx, domain.ListEmployeeChangeRequestsParams{
+		TenantID:   tenantID,
+		Status:     filter.Status,
+		EmployeeID: filter.EmployeeID,
+		Limit:      params.Limit(),
+		Offset:     params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountEmployeeChangeRequests(ctx, domain.CountEmployeeChangeRequestsParams{
+		TenantID:   tenantID,
+		Status:     filter.Status,
+		EmployeeID: filter.EmployeeID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]ChangeRequest, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toChangeRequest(r))
+	}
+	return items, total, nil
+}
+
+// ListMyChangeRequests lists the change requests raised for the signed-in user's employee record
+func (s *ChangeRequestService) ListMyChangeRequests(ctx context.Context, tenantID, userID pgtype.UUID, params query.PaginationParams, status string) ([]ChangeRequest, int64, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return nil, 0, err
+	}
+	if !user.EmployeeID.Valid {
+		return []ChangeRequest{}, 0, nil
+	}
+	return s.ListChangeRequests(ctx, tenantID, params, ChangeRequestFilter{
+		Status:     status,
+		EmployeeID: user.EmployeeID,
+	})
+}
+
+// GetChangeRequest returns a change request to its employee, its approver or an
+// administrator. Anyone else gets pgx.ErrNoRows.
+func (s *ChangeRequestService) GetChangeRequest(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (ChangeRequest, error) {
+	req, err := s.queries.GetEmployeeChangeRequest(ctx, domain.GetEmployeeChangeRequestParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+	if !asAdmin {
+		actor, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: actorID})
+		if err != nil {
+			return ChangeRequest{}, fmt.Errorf("failed to load actor: %w", err)
+		}
+		if !actor.EmployeeID.Valid || actor.EmployeeID != req.EmployeeID {
+			approver, err := s.isApprover(ctx, s.queries, req, actor)
+			if err != nil {
+				return ChangeRequest{}, err
+			}
+			if !approver {
+				return ChangeRequest{}, pgx.ErrNoRows
+			}
+		}
+	}
+	return toChangeRequest(req), nil
+}
+
+// ApproveChangeRequest applies a pending request to the employee record and closes its task
+// in one transaction. It fails with ErrStaleChange when a field no longer holds the value
+// it had when the request was raised.
+func (s *ChangeRequestService) ApproveChangeRequest(ctx context.Context, tenantID, actorID, id pgtype.UUID, notes *string, asAdmin bool) (ChangeRequest, error) {
+	return s.decide(ctx, tenantID, actorID, id, ChangeApproved, notes, asAdmin)
+}
+
+// RejectChangeRequest turns a pending request down, keeping the reasoning given in notes
+func (s *ChangeRequestService) RejectChangeRequest(ctx context.Context, tenantID, actorID, id pgtype.UUID, notes string, asAdmin bool) (ChangeRequest, error) {
+	return s.decide(ctx, tenantID, actorID, id, ChangeRejected, &notes, asAdmin)
+}
+
+// CancelChangeRequest withdraws a pending request. Only the employee it is for may cancel it.
+func (s *ChangeRequestService) CancelChangeRequest(ctx context.Context, tenantID, actorID, id pgtype.UUID) (ChangeRequest, error) {
+	return s.decide(ctx, tenantID, actorID, id, ChangeCancelled, nil, false)
+}
+
+func (s *ChangeRequestService) decide(ctx context.Context, tenantID, actorID, id pgtype.UUID, status string, notes *string, asAdmin bool) (ChangeRequest, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to begin change request transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	req, err := qtx.GetEmployeeChangeRequestForUpdate(ctx, domain.GetEmployeeChangeRequestForUpdateParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+	if req.Status != ChangePending {
+		return ChangeRequest{}, ErrChangeClosed
+	}
+
+	actor, err := qtx.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: actorID})
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to load actor: %w", err)
+	}
+	own := actor.EmployeeID.Valid && actor.EmployeeID == req.EmployeeID
+	if status == ChangeCancelled {
+		if !own {
+			return ChangeRequest{}, ErrNotRequester
+		}
+	} else {
+		if own {
+			return ChangeRequest{}, ErrSelfApproval
+		}
+		if !asAdmin {
+			approver, err := s.isApprover(ctx, qtx, req, actor)
+			if err != nil {
+				return ChangeRequest{}, err
+			}
+			if !approver {
+				return ChangeRequest{}, ErrNotApprover
+			}
+		}
+	}
+
+	var changes map[string]FieldChange
+	if err := json.Unmarshal(req.Changes, &changes); err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to read proposed changes: %w", err)
+	}
+	if status == ChangeApproved {
+		if err := s.apply(ctx, qtx, req, changes); err != nil {
+			return ChangeRequest{}, err
+		}
+	}
+
+	decided, err := qtx.DecideEmployeeChangeRequest(ctx, domain.DecideEmployeeChangeRequestParams{
+		TenantID:        tenantID,
+		ID:              id,
+		Status:          status,
+		DecisionNotes:   optionalText(stringValue(notes)),
+		DecidedByUserID: actorID,
+	})
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+
+	if s.taskSvc != nil {
+		taskStatus, outcome := tasks.StatusCompleted, &status
+		if status == ChangeCancelled {
+			taskStatus, outcome = tasks.StatusCancelled, notes
+		}
+		if err := s.taskSvc.CloseEntityTasksTx(ctx, qtx, tenantID, actorID, ChangeRequestEntity, id, taskStatus, outcome); err != nil {
+			return ChangeRequest{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to commit change request: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", ChangeRequestEntity, id.Bytes, map[string]interface{}{
+			"status":         map[string]interface{}{"from": req.Status, "to": status},
+			"decision_notes": notes,
+		})
+		if status == ChangeApproved {
+			diff := make(map[string]interface{}, len(changes)+1)
+			for field, change := range changes {
+				diff[field] = change
+			}
+			diff["change_request_id"] = id
+			s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "Employees", req.EmployeeID.Bytes, diff)
+		}
+	}
+	return toChangeRequest(decided), nil
+}
+
+// apply writes approved changes to the locked employee record
+func (s *ChangeRequestService) apply(ctx context.Context, q *domain.Queries, req domain.EmployeeChangeRequest, changes map[string]FieldChange) error {
+	emp, err := q.GetEmployeeForUpdate(ctx, domain.GetEmployeeForUpdateParams{
+		TenantID: req.TenantID,
+		ID:       req.EmployeeID,
+	})
+	if err != nil {
+		return err
+	}
+
+	params := domain.UpdateEmployeeDetailsParams{
+		TenantID:       emp.TenantID,
+		ID:             emp.ID,
+		FirstName:      emp.FirstName,
+		LastName:       emp.LastName,
+		DisplayName:    emp.DisplayName,
+		WorkEmail:      emp.WorkEmail,
+		BusinessUnitID: emp.BusinessUnitID,
+		DepartmentID:   emp.DepartmentID,
+	}
+	current := employeeFields(emp)
+	for field, change := range changes {
+		if !sameValue(current[field], change.From) {
+			return ErrStaleChange
+		}
+		switch field {
+		case "first_name":
+			params.FirstName = *change.To
+		case "last_name":
+			params.LastName = *change.To
+		case "display_name":
+			params.DisplayName = optionalText(stringValue(change.To))
+		case "work_email":
+			params.WorkEmail = optionalText(stringValue(change.To))
+		case "business_unit_id":
+			params.BusinessUnitID = parseOptionalUUID(change.To)
+		case "department_id":
+			params.DepartmentID = parseOptionalUUID(change.To)
+		}
+	}
+
+	if err := checkSitePlacement(ctx, q, req.TenantID, params.BusinessUnitID, params.DepartmentID); err != nil {
+		return err
+	}
+	_, err = q.UpdateEmployeeDetails(ctx, params)
+	return mapEmployeeConstraintError(err)
+}
+
+// checkTransfer verifies that a proposed business unit and department exist and fit together
+func (s *ChangeRequestService) checkTransfer(ctx context.Context, tenantID pgtype.UUID, emp domain.Employee, in ChangeInput) error {
+	if !in.BusinessUnitID.Valid && !in.DepartmentID.Valid {
+		return nil
+	}
+	busID, deptID := emp.BusinessUnitID, emp.DepartmentID
+	if in.BusinessUnitID.Valid {
+		bu, err := s.queries.GetBusinessUnit(ctx, domain.GetBusinessUnitParams{TenantID: tenantID, ID: in.BusinessUnitID})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !bu.IsActive) {
+			return ErrOrgUnitNotFound
+		} else if err != nil {
+			return err
+		}
+		busID = in.BusinessUnitID
+	}
+	if in.DepartmentID.Valid {
+		dept, err := s.queries.GetDepartment(ctx, domain.GetDepartmentParams{TenantID: tenantID, ID: in.DepartmentID})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !dept.IsActive) {
+			return ErrOrgUnitNotFound
+		} else if err != nil {
+			return err
+		}
+		deptID = in.DepartmentID
+	}
+	return checkSitePlacement(ctx, s.queries, tenantID, busID, deptID)
+}
+
+func (s *ChangeRequestService) linkedEmployee(ctx context.Context, tenantID, userID pgtype.UUID) (domain.Employee, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return domain.Employee{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	if !user.EmployeeID.Valid {
+		return domain.Employee{}, ErrNoLinkedEmployee
+	}
+	emp, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{TenantID: tenantID, ID: user.EmployeeID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Employee{}, ErrNoLinkedEmployee
+	}
+	return emp, err
+}
+
+// approverRole picks the role that decides requests of employees without a manager
+func (s *ChangeRequestService) approverRole(ctx context.Context, tenantID pgtype.UUID) (string, error) {
+	for _, code := range changeApproverRoles {
+		n, err := s.queries.CountRolesByCode(ctx, domain.CountRolesByCodeParams{TenantID: tenantID, Code: code})
+		if err != nil {
+			return "", fmt.Errorf("failed to check role: %w", err)
+		}
+		if n > 0 {
+			return code, nil
+		}
+	}
+	return "", ErrNoApprover
+}
+
+// isApprover reports whether user is the approver of a request, either as the employee it
+// was routed to or as a holder of its approver role
+func (s *ChangeRequestService) isApprover(ctx context.Context, q *domain.Queries, req domain.EmployeeChangeRequest, user domain.User) (bool, error) {
+	if req.ApproverEmployeeID.Valid {
+		return user.EmployeeID.Valid && user.EmployeeID == req.ApproverEmployeeID, nil
+	}
+	codes, err := q.GetUserRoles(ctx, domain.GetUserRolesParams{
+		TenantID: req.TenantID,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to load roles: %w", err)
+	}
+	for _, code := range codes {
+		if code == req.ApproverRoleCode.String {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// employeeFields reads the self-editable fields of an employee as they are stored in
+// change requests
+func employeeFields(emp domain.Employee) map[string]*string {
+	return map[string]*string{
+		"first_name":       &emp.FirstName,
+		"last_name":        &emp.LastName,
+		"display_name":     textPtr(emp.DisplayName),
+		"work_email":       textPtr(emp.WorkEmail),
+		"business_unit_id": uuidPtr(emp.BusinessUnitID),
+		"department_id":    uuidPtr(emp.DepartmentID),
+	}
+}
+
+// diffEmployee returns the proposed fields that differ from the employee record
+func diffEmployee(emp domain.Employee, in ChangeInput) map[string]FieldChange {
+	proposed := map[string]*string{}
+	if in.FirstName != nil {
+		proposed["first_name"] = optionalString(strings.TrimSpace(*in.FirstName))
+	}
+	if in.LastName != nil {
+		proposed["last_name"] = optionalString(strings.TrimSpace(*in.LastName))
+	}
+	if in.DisplayName != nil {
+		proposed["display_name"] = optionalString(strings.TrimSpace(*in.DisplayName))
+	}
+	if in.WorkEmail != nil {
+		proposed["work_email"] = optionalString(strings.TrimSpace(*in.WorkEmail))
+	}
+	if in.BusinessUnitID.Valid {
+		proposed["business_unit_id"] = uuidPtr(in.BusinessUnitID)
+	}
+	if in.DepartmentID.Valid {
+		proposed["department_id"] = uuidPtr(in.DepartmentID)
+	}
+
+	current := employeeFields(emp)
+	changes := make(map[string]FieldChange, len(proposed))
+	for field, to := range proposed {
+		if sameValue(current[field], to) {
+			continue
+		}
+		// Names are required; an empty one is not a change
+		if to == nil && (field == "first_name" || field == "last_name") {
+			continue
+		}
+		changes[field] = FieldChange{From: current[field], To: to}
+	}
+	return changes
+}
+
+// mapChangeRequestError translates the one-pending-request index into ErrChangePending
+func mapChangeRequestError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "idx_employee_change_requests_pending" {
+		return ErrChangePending
+	}
+	return err
+}
+
+// ChangeRequestTaskTitle is the title of the approval task, and so of the notifications
+// and emails, for a change request to emp's record
+func ChangeRequestTaskTitle(emp domain.Employee) string {
+	return "Review HR record change for " + employeeName(emp)
+}
+
+func employeeName(emp domain.Employee) string {
+	if emp.DisplayName.Valid && emp.DisplayName.String != "" {
+		return emp.DisplayName.String
+	}
+	return emp.FirstName + " " + emp.LastName
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func sortedKeys(m map[string]FieldChange) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func uuidPtr(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	s := uuid.UUID(id.Bytes).String()
+	return &s
+}
+
+func parseOptionalUUID(v *string) pgtype.UUID {
+	if v == nil {
+		return pgtype.UUID{}
+	}
+	parsed, err := uuid.Parse(*v)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
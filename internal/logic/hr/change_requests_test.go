@@ -0,0 +1,135 @@
+package hr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	london     = "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e"
+	manchester = "8e2d6b1a-0f3c-4d5e-a6b7-c8d9e0f1a2b3"
+	finance    = "5b9e2c7d-1a4f-4e8b-9c3d-2f6a1b0e7d5c"
+	quality    = "c4d8a1e6-7b2f-4a9c-8e5d-3b1f0a6c9e2d"
+)
+
+func testUUID(s string) pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.MustParse(s), Valid: true}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func testEmployee() domain.Employee {
+	return domain.Employee{
+		FirstName:      "Ada",
+		LastName:       "Lovelace",
+		DisplayName:    pgtype.Text{String: "Ada L", Valid: true},
+		WorkEmail:      pgtype.Text{String: "ada@example.com", Valid: true},
+		BusinessUnitID: testUUID(london),
+		DepartmentID:   testUUID(finance),
+	}
+}
+
+func TestDiffEmployee(t *testing.T) {
+	cases := []struct {
+		name string
+		in   ChangeInput
+		want map[string]FieldChange
+	}{
+		{
+			"nothing proposed",
+			ChangeInput{},
+			map[string]FieldChange{},
+		},
+		{
+			"current values",
+			ChangeInput{
+				FirstName:      strPtr(" Ada "),
+				LastName:       strPtr("Lovelace"),
+				DisplayName:    strPtr("Ada L"),
+				WorkEmail:      strPtr("ada@example.com"),
+				BusinessUnitID: testUUID(london),
+				DepartmentID:   testUUID(finance),
+			},
+			map[string]FieldChange{},
+		},
+		{
+			"changed names",
+			ChangeInput{FirstName: strPtr("Augusta"), DisplayName: strPtr("  Augusta Ada ")},
+			map[string]FieldChange{
+				"first_name":   {From: strPtr("Ada"), To: strPtr("Augusta")},
+				"display_name": {From: strPtr("Ada L"), To: strPtr("Augusta Ada")},
+			},
+		},
+		{
+			"cleared optional fields",
+			ChangeInput{DisplayName: strPtr(""), WorkEmail: strPtr("   ")},
+			map[string]FieldChange{
+				"display_name": {From: strPtr("Ada L"), To: nil},
+				"work_email":   {From: strPtr("ada@example.com"), To: nil},
+			},
+		},
+		{
+			"cleared names are ignored",
+			ChangeInput{FirstName: strPtr(""), LastName: strPtr("  ")},
+			map[string]FieldChange{},
+		},
+		{
+			"transfer",
+			ChangeInput{BusinessUnitID: testUUID(manchester), DepartmentID: testUUID(quality)},
+			map[string]FieldChange{
+				"business_unit_id": {From: strPtr(london), To: strPtr(manchester)},
+				"department_id":    {From: strPtr(finance), To: strPtr(quality)},
+			},
+		},
+		{
+			"transfer within the site",
+			ChangeInput{BusinessUnitID: testUUID(london), DepartmentID: testUUID(quality)},
+			map[string]FieldChange{
+				"department_id": {From: strPtr(finance), To: strPtr(quality)},
+			},
+		},
+	}
+	for _, tc := range cases {
+		if got := diffEmployee(testEmployee(), tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: diffEmployee() = %s, want %s", tc.name, describeChanges(got), describeChanges(tc.want))
+		}
+	}
+}
+
+func TestDiffEmployeeFillsUnsetFields(t *testing.T) {
+	emp := testEmployee()
+	emp.WorkEmail = pgtype.Text{}
+	emp.DepartmentID = pgtype.UUID{}
+
+	got := diffEmployee(emp, ChangeInput{WorkEmail: strPtr("ada@example.com"), DepartmentID: testUUID(quality)})
+	want := map[string]FieldChange{
+		"work_email":    {From: nil, To: strPtr("ada@example.com")},
+		"department_id": {From: nil, To: strPtr(quality)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffEmployee() = %s, want %s", describeChanges(got), describeChanges(want))
+	}
+}
+
+// describeChanges prints the values behind the pointers in a change set
+func describeChanges(changes map[string]FieldChange) string {
+	out := "{"
+	for _, field := range sortedKeys(changes) {
+		c := changes[field]
+		out += " " + field + ": " + quoted(c.From) + " -> " + quoted(c.To)
+	}
+	return out + " }"
+}
+
+func quoted(v *string) string {
+	if v == nil {
+		return "nil"
+	}
+	return `"` + *v + `"`
+}
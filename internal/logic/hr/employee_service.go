@@ -2,15 +2,37 @@ package hr
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/http/query"
 	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+var (
+	// ErrSelfManager is returned when an employee is designated as their own manager
+	ErrSelfManager = errors.New("an employee cannot be their own manager")
+
+	// ErrManagerNotFound is returned when the designated manager does not exist in the tenant
+	ErrManagerNotFound = errors.New("designated manager does not exist or is inaccessible")
+
+	// ErrManagerCycle is returned when a manager assignment would close a reporting loop
+	ErrManagerCycle = errors.New("manager assignment would create a reporting cycle")
+
+	// ErrDepartmentNotAtSite is returned when a department does not operate at the chosen business unit
+	ErrDepartmentNotAtSite = errors.New("department does not operate at the selected business unit")
+
+	// ErrEmployeeErased is returned for writes to an employee erased under the right to erasure
+	ErrEmployeeErased = errors.New("employee has been erased")
+)
+
 type BusinessUnitSummary struct {
 	ID   pgtype.UUID `json:"id"`
 	Code *string     `json:"code"`
@@ -54,6 +76,13 @@ type EmployeeWithDetails struct {
 	Department   *DepartmentSummary   `json:"department"`
 	JobTitle     *JobTitleSummary     `json:"jobTitle"`
 	Manager      *ManagerSummary      `json:"manager"`
+
+	// HasExpiredMandatoryTraining is set when the latest signed-off completion of any
+	// active mandatory training course has passed its expiry date
+	HasExpiredMandatoryTraining bool `json:"hasExpiredMandatoryTraining"`
+
+	// CustomFields holds the tenant-defined field values keyed by field key
+	CustomFields json.RawMessage `json:"customFields"`
 }
 
 func mapRowToEmployeeWithDetails(row domain.GetEmployeeWithDetailsRow) EmployeeWithDetails {
@@ -69,6 +98,9 @@ func mapRowToEmployeeWithDetails(row domain.GetEmployeeWithDetailsRow) EmployeeW
 		IsActive:    row.IsActive,
 		CreatedAt:   row.CreatedAt,
 		UpdatedAt:   row.UpdatedAt,
+
+		HasExpiredMandatoryTraining: row.HasExpiredMandatoryTraining,
+		CustomFields:                json.RawMessage(row.CustomFields),
 	}
 
 	if row.BusinessUnitID.Valid {
@@ -144,6 +176,9 @@ func mapListRowToEmployeeWithDetails(row domain.ListEmployeesWithDetailsRow) Emp
 		IsActive:    row.IsActive,
 		CreatedAt:   row.CreatedAt,
 		UpdatedAt:   row.UpdatedAt,
+
+		HasExpiredMandatoryTraining: row.HasExpiredMandatoryTraining,
+		CustomFields:                json.RawMessage(row.CustomFields),
 	}
 
 	if row.BusinessUnitID.Valid {
@@ -207,18 +242,24 @@ func mapListRowToEmployeeWithDetails(row domain.ListEmployeesWithDetailsRow) Emp
 }
 
 type EmployeeService struct {
-	queries  *domain.Queries
-	auditSvc *audit.AuditService
+	db             *db.DB
+	queries        *domain.Queries
+	auditSvc       *audit.AuditService
+	customFieldSvc *customfields.CustomFieldService
 }
 
-func NewEmployeeService(database *db.DB, auditSvc *audit.AuditService) *EmployeeService {
+func NewEmployeeService(database *db.DB, auditSvc *audit.AuditService, customFieldSvc *customfields.CustomFieldService) *EmployeeService {
 	return &EmployeeService{
-		queries:  domain.New(database.Pool),
-		auditSvc: auditSvc,
+		db:             database,
+		queries:        domain.New(database.Pool),
+		auditSvc:       auditSvc,
+		customFieldSvc: customFieldSvc,
 	}
 }
 
-func (s *EmployeeService) CreateEmployee(ctx context.Context, id, tenantID, actorID pgtype.UUID, empNo, first, last string, display, email *string, busID, deptID, jobID, mgrID pgtype.UUID) (domain.Employee, error) {
+// CreateEmployee creates an employee together with its custom field values, so a request
+// that fails leaves neither behind
+func (s *EmployeeService) CreateEmployee(ctx context.Context, id, tenantID, actorID pgtype.UUID, empNo, first, last string, display, email *string, busID, deptID, jobID, mgrID pgtype.UUID, customFields map[string]interface{}) (domain.Employee, error) {
 	var pgDisplay pgtype.Text
 	if display != nil && *display != "" {
 		pgDisplay.String = *display
@@ -232,17 +273,32 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, id, tenantID, acto
 	}
 
 	// Structural enforcement tracking
+	if mgrID.Valid && mgrID == id {
+		return domain.Employee{}, ErrSelfManager
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Employee{}, fmt.Errorf("failed to begin employee transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := domain.New(tx)
+
 	if mgrID.Valid {
-		_, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		_, err := qtx.GetEmployee(ctx, domain.GetEmployeeParams{
 			TenantID: tenantID,
 			ID:       mgrID,
 		})
 		if err != nil {
-			return domain.Employee{}, fmt.Errorf("designated manager does not exist or is inaccessible: %w", err)
+			return domain.Employee{}, fmt.Errorf("%w: %w", ErrManagerNotFound, err)
 		}
 	}
 
-	emp, err := s.queries.CreateEmployee(ctx, domain.CreateEmployeeParams{
+	if err := checkSitePlacement(ctx, qtx, tenantID, busID, deptID); err != nil {
+		return domain.Employee{}, err
+	}
+
+	emp, err := qtx.CreateEmployee(ctx, domain.CreateEmployeeParams{
 		ID:             id,
 		TenantID:       tenantID,
 		EmployeeNo:     empNo,
@@ -255,17 +311,134 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, id, tenantID, acto
 		JobTitleID:     jobID,
 		ManagerID:      mgrID,
 	})
+	if err != nil {
+		return domain.Employee{}, mapEmployeeConstraintError(err)
+	}
+
+	changes := map[string]interface{}{
+		"employee_no": empNo,
+		"first_name":  first,
+		"last_name":   last,
+		"work_email":  email,
+	}
+	if s.customFieldSvc != nil {
+		values, err := s.customFieldSvc.CreateValues(ctx, qtx, tenantID, actorID, customfields.EntityEmployee, id, customFields)
+		if err != nil {
+			return domain.Employee{}, err
+		}
+		if len(values) > 0 {
+			changes["custom_fields"] = values
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Employee{}, fmt.Errorf("failed to commit employee: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Employees", id.Bytes, changes)
+	}
+
+	return emp, nil
+}
+
+// ChangeManager moves an employee under a new manager, or detaches them when managerID is
+// not valid. The new manager must belong to the tenant and must not report, directly or
+// indirectly, to the employee. A database trigger enforces the same rules for concurrent writes.
+func (s *EmployeeService) ChangeManager(ctx context.Context, tenantID, actorID, employeeID, managerID pgtype.UUID) (domain.Employee, error) {
+	current, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	})
+	if err != nil {
+		return domain.Employee{}, err
+	}
 
-	if err == nil && s.auditSvc != nil {
-		s.auditSvc.Log(tenantID, actorID, "CREATE", "Employees", id.Bytes, map[string]interface{}{
-			"employee_no": empNo,
-			"first_name":  first,
-			"last_name":   last,
-			"work_email":  email,
+	if managerID.Valid {
+		if managerID == employeeID {
+			return domain.Employee{}, ErrSelfManager
+		}
+
+		if _, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+			TenantID: tenantID,
+			ID:       managerID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return domain.Employee{}, ErrManagerNotFound
+		} else if err != nil {
+			return domain.Employee{}, err
+		}
+
+		// The employee must not appear anywhere above the proposed manager
+		chain, err := s.queries.GetEmployeeChainOfCommand(ctx, domain.GetEmployeeChainOfCommandParams{
+			TenantID: tenantID,
+			ID:       managerID,
 		})
+		if err != nil {
+			return domain.Employee{}, fmt.Errorf("failed to resolve chain of command: %w", err)
+		}
+		for _, link := range chain {
+			if link.ID == employeeID {
+				return domain.Employee{}, ErrManagerCycle
+			}
+		}
 	}
 
-	return emp, err
+	emp, err := s.queries.UpdateEmployeeManager(ctx, domain.UpdateEmployeeManagerParams{
+		TenantID:  tenantID,
+		ID:        employeeID,
+		ManagerID: managerID,
+	})
+	if err != nil {
+		return domain.Employee{}, mapEmployeeConstraintError(err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "Employees", employeeID.Bytes, map[string]interface{}{
+			"manager_id": map[string]interface{}{
+				"from": current.ManagerID,
+				"to":   managerID,
+			},
+		})
+	}
+
+	return emp, nil
+}
+
+// mapEmployeeConstraintError translates violations raised by the manager cycle trigger
+// into the service's sentinel errors
+func mapEmployeeConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "employees_manager_no_cycle":
+		return ErrManagerCycle
+	case "employees_manager_same_tenant":
+		return ErrManagerNotFound
+	case "employees_business_unit_department_fk":
+		return ErrDepartmentNotAtSite
+	}
+	return err
+}
+
+// checkSitePlacement verifies that deptID operates at busID when an employee is given both
+func checkSitePlacement(ctx context.Context, q *domain.Queries, tenantID, busID, deptID pgtype.UUID) error {
+	if !busID.Valid || !deptID.Valid {
+		return nil
+	}
+	links, err := q.CountBusinessUnitDepartmentLinks(ctx, domain.CountBusinessUnitDepartmentLinksParams{
+		TenantID:       tenantID,
+		BusinessUnitID: busID,
+		DepartmentID:   deptID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check site departments: %w", err)
+	}
+	if links == 0 {
+		return ErrDepartmentNotAtSite
+	}
+	return nil
 }
 
 func (s *EmployeeService) ListEmployees(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.Employee, int64, error) {
@@ -308,20 +481,35 @@ func (s *EmployeeService) GetEmployeeWithDetails(ctx context.Context, tenantID,
 	return mapRowToEmployeeWithDetails(row), nil
 }
 
-func (s *EmployeeService) ListEmployeesWithDetails(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]EmployeeWithDetails, int64, error) {
+// EmployeeFilter narrows employee listings beyond the generic search term
+type EmployeeFilter struct {
+	DepartmentID pgtype.UUID
+	// IncludeSubDepartments widens DepartmentID to every department below it
+	IncludeSubDepartments bool
+	// CustomFields is a JSON document the employee's custom field values must contain
+	CustomFields []byte
+}
+
+func (s *EmployeeService) ListEmployeesWithDetails(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, filter EmployeeFilter) ([]EmployeeWithDetails, int64, error) {
 	rows, err := s.queries.ListEmployeesWithDetails(ctx, domain.ListEmployeesWithDetailsParams{
-		TenantID: tenantID,
-		Search:   params.Search,
-		Limit:    params.Limit(),
-		Offset:   params.Offset(),
+		TenantID:              tenantID,
+		Search:                params.Search,
+		DepartmentID:          filter.DepartmentID,
+		IncludeSubDepartments: filter.IncludeSubDepartments,
+		CustomFields:          filter.CustomFields,
+		Limit:                 params.Limit(),
+		Offset:                params.Offset(),
 	})
 	if err != nil {
 		return nil, 0, err
 	}
 
 	total, err := s.queries.CountEmployees(ctx, domain.CountEmployeesParams{
-		TenantID: tenantID,
-		Search:   params.Search,
+		TenantID:              tenantID,
+		Search:                params.Search,
+		DepartmentID:          filter.DepartmentID,
+		IncludeSubDepartments: filter.IncludeSubDepartments,
+		CustomFields:          filter.CustomFields,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -335,10 +523,13 @@ func (s *EmployeeService) ListEmployeesWithDetails(ctx context.Context, tenantID
 	return emps, total, nil
 }
 
+// GetEmployeeHierarchy returns the employee followed by every direct and indirect report,
+// ordered by reporting depth. Reporting cycles are cut at the first repeated employee.
 func (s *EmployeeService) GetEmployeeHierarchy(ctx context.Context, tenantID, employeeID pgtype.UUID) ([]domain.Employee, error) {
-	rows, err := s.queries.GetEmployeeHierarchy(ctx, domain.GetEmployeeHierarchyParams{
+	rows, err := s.queries.GetEmployeeSubtree(ctx, domain.GetEmployeeSubtreeParams{
 		TenantID: tenantID,
 		ID:       employeeID,
+		MaxDepth: MaxOrgChartDepth,
 	})
 	if err != nil {
 		return nil, err
@@ -348,7 +539,7 @@ func (s *EmployeeService) GetEmployeeHierarchy(ctx context.Context, tenantID, em
 	for i, r := range rows {
 		emps[i] = domain.Employee{
 			ID:             r.ID,
-			TenantID:       r.TenantID,
+			TenantID:       tenantID,
 			EmployeeNo:     r.EmployeeNo,
 			FirstName:      r.FirstName,
 			LastName:       r.LastName,
@@ -0,0 +1,636 @@
+package hr
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/INOVA/DML/internal/logic/jobs"
+	"github.com/INOVA/DML/internal/xlsx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// JobKindEmployeeImport identifies bulk import jobs in background_jobs
+	JobKindEmployeeImport = "employee_import"
+
+	// AsyncImportThreshold is the row count above which imports are always run as background jobs
+	AsyncImportThreshold = 500
+)
+
+// ErrImportInvalid is returned when a non dry-run import contains at least one invalid row.
+// Nothing is written in that case; the accompanying report lists every problem.
+var ErrImportInvalid = errors.New("import contains invalid rows")
+
+// ErrUnsupportedImportFormat is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedImportFormat = errors.New("unsupported import file format, expected .csv or .xlsx")
+
+// ImportRow is a single employee line from an uploaded file. Organisational references
+// are expressed by code and the manager by employee number so that files can be authored
+// without knowing any UUIDs. Custom field values come from cf.<key> columns as text.
+type ImportRow struct {
+	Row               int               `json:"row"`
+	EmployeeNo        string            `json:"employeeNo"`
+	FirstName         string            `json:"firstName"`
+	LastName          string            `json:"lastName"`
+	DisplayName       string            `json:"displayName"`
+	WorkEmail         string            `json:"workEmail"`
+	BusinessUnitCode  string            `json:"businessUnitCode"`
+	DepartmentCode    string            `json:"departmentCode"`
+	JobTitleCode      string            `json:"jobTitleCode"`
+	ManagerEmployeeNo string            `json:"managerEmployeeNo"`
+	CustomFields      map[string]string `json:"customFields,omitempty"`
+}
+
+type ImportRowError struct {
+	Row        int    `json:"row"`
+	EmployeeNo string `json:"employeeNo,omitempty"`
+	Field      string `json:"field"`
+	Message    string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun    bool             `json:"dryRun"`
+	TotalRows int              `json:"totalRows"`
+	ValidRows int              `json:"validRows"`
+	Created   int              `json:"created"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// importHeaders maps normalised header names onto ImportRow fields
+var importHeaders = map[string]string{
+	"employeeno":        "employeeNo",
+	"employeenumber":    "employeeNo",
+	"firstname":         "firstName",
+	"lastname":          "lastName",
+	"surname":           "lastName",
+	"displayname":       "displayName",
+	"workemail":         "workEmail",
+	"email":             "workEmail",
+	"businessunit":      "businessUnitCode",
+	"businessunitcode":  "businessUnitCode",
+	"site":              "businessUnitCode",
+	"department":        "departmentCode",
+	"departmentcode":    "departmentCode",
+	"jobtitle":          "jobTitleCode",
+	"jobtitlecode":      "jobTitleCode",
+	"manager":           "managerEmployeeNo",
+	"managerno":         "managerEmployeeNo",
+	"manageremployeeno": "managerEmployeeNo",
+}
+
+// ParseImportFile decodes a CSV or XLSX upload into import rows. The format is
+// chosen by file extension, falling back to sniffing the ZIP signature.
+func ParseImportFile(filename string, data []byte) ([]ImportRow, error) {
+	var records [][]string
+	var err error
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch {
+	case ext == ".xlsx" || (ext == "" && bytes.HasPrefix(data, []byte("PK\x03\x04"))):
+		records, err = xlsx.ReadFirstSheet(data)
+	case ext == ".csv" || ext == "":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err = reader.ReadAll()
+		for _, record := range records {
+			for i, value := range record {
+				record[i] = unescapeFormula(value)
+			}
+		}
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading import file: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("import file is empty")
+	}
+
+	columns := make(map[int]string)
+	customColumns := make(map[int]string)
+	for i, header := range records[0] {
+		if key, ok := customFieldHeader(header); ok {
+			customColumns[i] = key
+		} else if field, ok := importHeaders[normaliseHeader(header)]; ok {
+			columns[i] = field
+		}
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("import file header row does not contain any recognised columns")
+	}
+
+	rows := make([]ImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		// Row numbers are 1-based and include the header, matching what users see in a spreadsheet
+		row := ImportRow{Row: i + 2}
+		for col, value := range record {
+			if key, ok := customColumns[col]; ok {
+				if row.CustomFields == nil {
+					row.CustomFields = make(map[string]string, len(customColumns))
+				}
+				row.CustomFields[key] = value
+				continue
+			}
+			field, ok := columns[col]
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch field {
+			case "employeeNo":
+				row.EmployeeNo = value
+			case "firstName":
+				row.FirstName = value
+			case "lastName":
+				row.LastName = value
+			case "displayName":
+				row.DisplayName = value
+			case "workEmail":
+				row.WorkEmail = value
+			case "businessUnitCode":
+				row.BusinessUnitCode = value
+			case "departmentCode":
+				row.DepartmentCode = value
+			case "jobTitleCode":
+				row.JobTitleCode = value
+			case "managerEmployeeNo":
+				row.ManagerEmployeeNo = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// unescapeFormula strips the apostrophe CSV exports put before cells that start like a
+// formula, so exported files import unchanged
+func unescapeFormula(v string) string {
+	if len(v) < 2 || v[0] != '\'' {
+		return v
+	}
+	switch v[1] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return v[1:]
+	}
+	return v
+}
+
+// customFieldHeader returns the field key of a cf.<key> column, matching the cf.<key> list
+// filters. The key is kept as written apart from case, since keys may contain underscores.
+func customFieldHeader(h string) (string, bool) {
+	h = strings.ToLower(strings.TrimSpace(h))
+	if !strings.HasPrefix(h, "cf.") || len(h) == len("cf.") {
+		return "", false
+	}
+	return h[len("cf."):], true
+}
+
+func normaliseHeader(h string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type EmployeeImportService struct {
+	db             *db.DB
+	queries        *domain.Queries
+	auditSvc       *audit.AuditService
+	customFieldSvc *customfields.CustomFieldService
+	runner         *jobs.Runner
+}
+
+func NewEmployeeImportService(database *db.DB, auditSvc *audit.AuditService, customFieldSvc *customfields.CustomFieldService, runner *jobs.Runner) *EmployeeImportService {
+	return &EmployeeImportService{
+		db:             database,
+		queries:        domain.New(database.Pool),
+		auditSvc:       auditSvc,
+		customFieldSvc: customFieldSvc,
+		runner:         runner,
+	}
+}
+
+// plannedEmployee is a validated row with every reference resolved to a UUID
+type plannedEmployee struct {
+	row          ImportRow
+	id           pgtype.UUID
+	busID        pgtype.UUID
+	deptID       pgtype.UUID
+	jobID        pgtype.UUID
+	managerID    pgtype.UUID
+	customFields map[string]interface{}
+}
+
+type importRef struct {
+	id       pgtype.UUID
+	isActive bool
+}
+
+// SubmitImport runs Import on the background job runner and returns the queued job for polling.
+func (s *EmployeeImportService) SubmitImport(ctx context.Context, tenantID, actorID pgtype.UUID, rows []ImportRow, dryRun bool) (jobs.Job, error) {
+	return s.runner.Submit(ctx, tenantID, actorID, JobKindEmployeeImport, len(rows), func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		report, err := s.Import(ctx, tenantID, actorID, rows, dryRun, progress)
+		return report, err
+	})
+}
+
+// Import validates every row and, unless dryRun is set, creates all employees in a single
+// transaction. Either every row is imported or none is.
+func (s *EmployeeImportService) Import(ctx context.Context, tenantID, actorID pgtype.UUID, rows []ImportRow, dryRun bool, progress *jobs.Progress) (ImportReport, error) {
+	plan, report, err := s.validate(ctx, tenantID, rows)
+	if err != nil {
+		return report, err
+	}
+	report.DryRun = dryRun
+
+	if len(report.Errors) > 0 {
+		if dryRun {
+			return report, nil
+		}
+		return report, ErrImportInvalid
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	for _, p := range plan {
+		_, err := qtx.CreateEmployee(ctx, domain.CreateEmployeeParams{
+			ID:             p.id,
+			TenantID:       tenantID,
+			EmployeeNo:     p.row.EmployeeNo,
+			FirstName:      p.row.FirstName,
+			LastName:       p.row.LastName,
+			DisplayName:    optionalText(p.row.DisplayName),
+			WorkEmail:      optionalText(p.row.WorkEmail),
+			BusinessUnitID: p.busID,
+			DepartmentID:   p.deptID,
+			JobTitleID:     p.jobID,
+			ManagerID:      p.managerID,
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed creating employee from row %d: %w", p.row.Row, err)
+		}
+		if len(p.customFields) > 0 {
+			encoded, err := json.Marshal(p.customFields)
+			if err != nil {
+				return report, err
+			}
+			if _, err := qtx.UpsertCustomFieldValues(ctx, domain.UpsertCustomFieldValuesParams{
+				TenantID:        tenantID,
+				EntityType:      customfields.EntityEmployee,
+				EntityID:        p.id,
+				FieldValues:     encoded,
+				UpdatedByUserID: actorID,
+			}); err != nil {
+				return report, fmt.Errorf("failed storing custom fields of row %d: %w", p.row.Row, err)
+			}
+		}
+		progress.Add(1)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("failed committing import transaction: %w", err)
+	}
+	report.Created = len(plan)
+
+	if s.auditSvc != nil {
+		for _, p := range plan {
+			s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Employees", p.id.Bytes, map[string]interface{}{
+				"employee_no":   p.row.EmployeeNo,
+				"first_name":    p.row.FirstName,
+				"last_name":     p.row.LastName,
+				"work_email":    p.row.WorkEmail,
+				"custom_fields": p.customFields,
+				"source":        "import",
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// importRefs is the tenant's current data that import rows are checked against. Codes,
+// employee numbers and emails are keyed in lower case.
+type importRefs struct {
+	businessUnits   map[string]importRef
+	departments     map[string]importRef
+	jobTitles       map[string]importRef
+	siteDepartments map[[2][16]byte]bool
+	employees       map[string]importRef
+	emails          map[string]bool
+	customFields    map[string]customfields.Definition
+}
+
+// validate checks every row against the file itself and the tenant's current data, and
+// returns the insert plan ordered so that managers are always created before their reports.
+func (s *EmployeeImportService) validate(ctx context.Context, tenantID pgtype.UUID, rows []ImportRow) ([]plannedEmployee, ImportReport, error) {
+	var refs importRefs
+	var err error
+	if refs.businessUnits, err = s.loadBusinessUnitRefs(ctx, tenantID); err != nil {
+		return nil, ImportReport{}, err
+	}
+	if refs.departments, err = s.loadDepartmentRefs(ctx, tenantID); err != nil {
+		return nil, ImportReport{}, err
+	}
+	if refs.jobTitles, err = s.loadJobTitleRefs(ctx, tenantID); err != nil {
+		return nil, ImportReport{}, err
+	}
+	if refs.siteDepartments, err = s.loadSiteDepartments(ctx, tenantID); err != nil {
+		return nil, ImportReport{}, err
+	}
+	if refs.customFields, err = s.customFieldSvc.ActiveDefinitions(ctx, tenantID, customfields.EntityEmployee); err != nil {
+		return nil, ImportReport{}, fmt.Errorf("loading custom fields: %w", err)
+	}
+
+	existing, err := s.queries.ListEmployeeRefs(ctx, tenantID)
+	if err != nil {
+		return nil, ImportReport{}, fmt.Errorf("loading existing employees: %w", err)
+	}
+	refs.employees = make(map[string]importRef, len(existing))
+	refs.emails = make(map[string]bool, len(existing))
+	for _, e := range existing {
+		refs.employees[strings.ToLower(e.EmployeeNo)] = importRef{id: e.ID, isActive: e.IsActive}
+		if e.WorkEmail.Valid {
+			refs.emails[strings.ToLower(e.WorkEmail.String)] = true
+		}
+	}
+
+	return planImport(rows, refs)
+}
+
+// planImport checks every row against the file itself and refs, and orders the valid rows
+// so that managers are always created before their reports
+func planImport(rows []ImportRow, refs importRefs) ([]plannedEmployee, ImportReport, error) {
+	report := ImportReport{TotalRows: len(rows), Errors: []ImportRowError{}}
+	busByCode, deptsByCode, jobsByCode := refs.businessUnits, refs.departments, refs.jobTitles
+	siteDepts, customDefs := refs.siteDepartments, refs.customFields
+	existingByNo, existingEmails := refs.employees, refs.emails
+
+	invalid := make(map[int]bool)
+	addError := func(idx int, field, msg string) {
+		invalid[idx] = true
+		report.Errors = append(report.Errors, ImportRowError{
+			Row:        rows[idx].Row,
+			EmployeeNo: rows[idx].EmployeeNo,
+			Field:      field,
+			Message:    msg,
+		})
+	}
+
+	plans := make([]plannedEmployee, len(rows))
+	rowByNo := make(map[string]int, len(rows))
+	seenEmails := make(map[string]int, len(rows))
+
+	// Pass 1: per-row checks and references to existing data
+	for i, row := range rows {
+		plans[i] = plannedEmployee{row: row}
+		plans[i].id.Bytes = uuid.New()
+		plans[i].id.Valid = true
+
+		if row.EmployeeNo == "" {
+			addError(i, "employeeNo", "is required")
+		} else {
+			key := strings.ToLower(row.EmployeeNo)
+			if prev, dup := rowByNo[key]; dup {
+				addError(i, "employeeNo", fmt.Sprintf("duplicates row %d", rows[prev].Row))
+			} else {
+				rowByNo[key] = i
+			}
+			if _, exists := existingByNo[key]; exists {
+				addError(i, "employeeNo", "an employee with this number already exists")
+			}
+		}
+		if row.FirstName == "" {
+			addError(i, "firstName", "is required")
+		}
+		if row.LastName == "" {
+			addError(i, "lastName", "is required")
+		}
+
+		if row.WorkEmail != "" {
+			key := strings.ToLower(row.WorkEmail)
+			if _, err := mail.ParseAddress(row.WorkEmail); err != nil {
+				addError(i, "workEmail", "is not a valid email address")
+			} else if prev, dup := seenEmails[key]; dup {
+				addError(i, "workEmail", fmt.Sprintf("duplicates row %d", rows[prev].Row))
+			} else if existingEmails[key] {
+				addError(i, "workEmail", "an employee with this work email already exists")
+			} else {
+				seenEmails[key] = i
+			}
+		}
+
+		if row.BusinessUnitCode != "" {
+			if ref, ok := busByCode[strings.ToLower(row.BusinessUnitCode)]; !ok {
+				addError(i, "businessUnitCode", fmt.Sprintf("unknown business unit %q", row.BusinessUnitCode))
+			} else if !ref.isActive {
+				addError(i, "businessUnitCode", fmt.Sprintf("business unit %q is inactive", row.BusinessUnitCode))
+			} else {
+				plans[i].busID = ref.id
+			}
+		}
+		if row.DepartmentCode != "" {
+			if ref, ok := deptsByCode[strings.ToLower(row.DepartmentCode)]; !ok {
+				addError(i, "departmentCode", fmt.Sprintf("unknown department %q", row.DepartmentCode))
+			} else if !ref.isActive {
+				addError(i, "departmentCode", fmt.Sprintf("department %q is inactive", row.DepartmentCode))
+			} else {
+				plans[i].deptID = ref.id
+			}
+		}
+		if plans[i].busID.Valid && plans[i].deptID.Valid && !siteDepts[[2][16]byte{plans[i].busID.Bytes, plans[i].deptID.Bytes}] {
+			addError(i, "departmentCode", fmt.Sprintf("department %q does not operate at business unit %q", row.DepartmentCode, row.BusinessUnitCode))
+		}
+		if row.JobTitleCode != "" {
+			if ref, ok := jobsByCode[strings.ToLower(row.JobTitleCode)]; !ok {
+				addError(i, "jobTitleCode", fmt.Sprintf("unknown job title %q", row.JobTitleCode))
+			} else if !ref.isActive {
+				addError(i, "jobTitleCode", fmt.Sprintf("job title %q is inactive", row.JobTitleCode))
+			} else {
+				plans[i].jobID = ref.id
+			}
+		}
+
+		// Required custom fields apply to imported employees as to those entered by hand
+		values, err := customfields.FromText(customDefs, row.CustomFields)
+		var valueErr *customfields.ValueError
+		switch {
+		case errors.As(err, &valueErr):
+			addError(i, "customFields."+valueErr.Key, valueErr.Message)
+		case err != nil:
+			return nil, report, err
+		default:
+			plans[i].customFields = values
+		}
+	}
+
+	// Pass 2: managers may be existing employees or other rows of the same file
+	managerRow := make(map[int]int)
+	for i, row := range rows {
+		if row.ManagerEmployeeNo == "" {
+			continue
+		}
+		key := strings.ToLower(row.ManagerEmployeeNo)
+		if key == strings.ToLower(row.EmployeeNo) {
+			addError(i, "managerEmployeeNo", "an employee cannot manage themselves")
+			continue
+		}
+		if idx, ok := rowByNo[key]; ok {
+			managerRow[i] = idx
+			plans[i].managerID = plans[idx].id
+			continue
+		}
+		if ref, ok := existingByNo[key]; !ok {
+			addError(i, "managerEmployeeNo", fmt.Sprintf("unknown manager %q", row.ManagerEmployeeNo))
+		} else if !ref.isActive {
+			addError(i, "managerEmployeeNo", fmt.Sprintf("manager %q is inactive", row.ManagerEmployeeNo))
+		} else {
+			plans[i].managerID = ref.id
+		}
+	}
+
+	// Pass 3: reject reporting cycles within the file
+	for start := range managerRow {
+		seen := map[int]bool{start: true}
+		for cur, ok := managerRow[start]; ok; cur, ok = managerRow[cur] {
+			if cur == start {
+				addError(start, "managerEmployeeNo", "reporting line forms a cycle within the file")
+				break
+			}
+			if seen[cur] {
+				break
+			}
+			seen[cur] = true
+		}
+	}
+
+	// Pass 4: a row whose in-file manager is invalid cannot be imported either
+	for changed := true; changed; {
+		changed = false
+		for i, m := range managerRow {
+			if invalid[m] && !invalid[i] {
+				addError(i, "managerEmployeeNo", fmt.Sprintf("manager row %d is invalid", rows[m].Row))
+				changed = true
+			}
+		}
+	}
+
+	report.ValidRows = len(rows) - len(invalid)
+
+	// Order the plan so that managers precede their reports for FK integrity
+	ordered := make([]plannedEmployee, 0, report.ValidRows)
+	placed := make(map[int]bool, len(rows))
+	var place func(i int)
+	place = func(i int) {
+		if placed[i] || invalid[i] {
+			return
+		}
+		placed[i] = true
+		if m, ok := managerRow[i]; ok {
+			place(m)
+		}
+		ordered = append(ordered, plans[i])
+	}
+	for i := range rows {
+		place(i)
+	}
+
+	return ordered, report, nil
+}
+
+func (s *EmployeeImportService) loadBusinessUnitRefs(ctx context.Context, tenantID pgtype.UUID) (map[string]importRef, error) {
+	rows, err := s.queries.ListBusinessUnitRefs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("loading business units: %w", err)
+	}
+	refs := make(map[string]importRef, len(rows))
+	for _, r := range rows {
+		refs[strings.ToLower(r.Code.String)] = importRef{id: r.ID, isActive: r.IsActive}
+	}
+	return refs, nil
+}
+
+func (s *EmployeeImportService) loadDepartmentRefs(ctx context.Context, tenantID pgtype.UUID) (map[string]importRef, error) {
+	rows, err := s.queries.ListDepartmentRefs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("loading departments: %w", err)
+	}
+	refs := make(map[string]importRef, len(rows))
+	for _, r := range rows {
+		refs[strings.ToLower(r.Code.String)] = importRef{id: r.ID, isActive: r.IsActive}
+	}
+	return refs, nil
+}
+
+// loadSiteDepartments returns the set of business unit / department pairs that employees may be placed in
+func (s *EmployeeImportService) loadSiteDepartments(ctx context.Context, tenantID pgtype.UUID) (map[[2][16]byte]bool, error) {
+	rows, err := s.queries.ListBusinessUnitDepartmentRefs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("loading site departments: %w", err)
+	}
+	links := make(map[[2][16]byte]bool, len(rows))
+	for _, r := range rows {
+		links[[2][16]byte{r.BusinessUnitID.Bytes, r.DepartmentID.Bytes}] = true
+	}
+	return links, nil
+}
+
+func (s *EmployeeImportService) loadJobTitleRefs(ctx context.Context, tenantID pgtype.UUID) (map[string]importRef, error) {
+	rows, err := s.queries.ListJobTitleRefs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("loading job titles: %w", err)
+	}
+	refs := make(map[string]importRef, len(rows))
+	for _, r := range rows {
+		refs[strings.ToLower(r.Code.String)] = importRef{id: r.ID, isActive: r.IsActive}
+	}
+	return refs, nil
+}
+
+func optionalText(v string) pgtype.Text {
+	if v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: v, Valid: true}
+}
@@ -0,0 +1,220 @@
+package hr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/INOVA/DML/internal/logic/customfields"
+)
+
+const (
+	existingManager = "0d5e8f2a-6b1c-4e3d-9a7f-1c2b3d4e5f60"
+	leaver          = "7a6b5c4d-3e2f-4a1b-8c9d-0e1f2a3b4c5d"
+	engineering     = "e1f2a3b4-c5d6-4e7f-8a9b-0c1d2e3f4a5b"
+	closedSite      = "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"
+)
+
+func testImportRefs() importRefs {
+	return importRefs{
+		businessUnits: map[string]importRef{
+			"lon": {id: testUUID(london), isActive: true},
+			"man": {id: testUUID(manchester), isActive: true},
+			"old": {id: testUUID(closedSite), isActive: false},
+		},
+		departments: map[string]importRef{
+			"fin": {id: testUUID(finance), isActive: true},
+			"qa":  {id: testUUID(quality), isActive: false},
+			"eng": {id: testUUID(engineering), isActive: true},
+		},
+		jobTitles: map[string]importRef{},
+		siteDepartments: map[[2][16]byte]bool{
+			{testUUID(london).Bytes, testUUID(finance).Bytes}: true,
+		},
+		employees: map[string]importRef{
+			"e001": {id: testUUID(existingManager), isActive: true},
+			"e002": {id: testUUID(leaver), isActive: false},
+		},
+		emails: map[string]bool{"taken@example.com": true},
+		customFields: map[string]customfields.Definition{
+			"shift": {Key: "shift", FieldType: customfields.TypeText, IsActive: true},
+		},
+	}
+}
+
+// importRow builds a row that passes every check unless the test changes it
+func importRow(row int, no, manager string) ImportRow {
+	return ImportRow{Row: row, EmployeeNo: no, FirstName: "Ada", LastName: "Lovelace", ManagerEmployeeNo: manager}
+}
+
+func errorKeys(errs []ImportRowError) []string {
+	keys := make([]string, len(errs))
+	for i, e := range errs {
+		keys[i] = fmt.Sprintf("%d %s: %s", e.Row, e.Field, e.Message)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestPlanImportErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		rows  []ImportRow
+		valid int
+		want  []string
+	}{
+		{
+			"valid rows",
+			[]ImportRow{importRow(2, "N1", "E001"), importRow(3, "N2", "")},
+			2,
+			nil,
+		},
+		{
+			"required fields",
+			[]ImportRow{{Row: 2}},
+			0,
+			[]string{"2 employeeNo: is required", "2 firstName: is required", "2 lastName: is required"},
+		},
+		{
+			"duplicate and existing employee numbers",
+			[]ImportRow{importRow(2, "N1", ""), importRow(3, "n1", ""), importRow(4, "e001", "")},
+			1,
+			[]string{"3 employeeNo: duplicates row 2", "4 employeeNo: an employee with this number already exists"},
+		},
+		{
+			"work emails",
+			[]ImportRow{
+				{Row: 2, EmployeeNo: "N1", FirstName: "A", LastName: "B", WorkEmail: "not an email"},
+				{Row: 3, EmployeeNo: "N2", FirstName: "A", LastName: "B", WorkEmail: "ada@example.com"},
+				{Row: 4, EmployeeNo: "N3", FirstName: "A", LastName: "B", WorkEmail: "ADA@example.com"},
+				{Row: 5, EmployeeNo: "N4", FirstName: "A", LastName: "B", WorkEmail: "taken@example.com"},
+			},
+			1,
+			[]string{
+				"2 workEmail: is not a valid email address",
+				"4 workEmail: duplicates row 3",
+				"5 workEmail: an employee with this work email already exists",
+			},
+		},
+		{
+			"org references",
+			[]ImportRow{
+				{Row: 2, EmployeeNo: "N1", FirstName: "A", LastName: "B", BusinessUnitCode: "NYC"},
+				{Row: 3, EmployeeNo: "N2", FirstName: "A", LastName: "B", BusinessUnitCode: "OLD"},
+				{Row: 4, EmployeeNo: "N3", FirstName: "A", LastName: "B", DepartmentCode: "QA"},
+				{Row: 5, EmployeeNo: "N4", FirstName: "A", LastName: "B", BusinessUnitCode: "MAN", DepartmentCode: "FIN"},
+				{Row: 6, EmployeeNo: "N5", FirstName: "A", LastName: "B", JobTitleCode: "CEO"},
+				{Row: 7, EmployeeNo: "N6", FirstName: "A", LastName: "B", BusinessUnitCode: "lon", DepartmentCode: "fin"},
+			},
+			1,
+			[]string{
+				`2 businessUnitCode: unknown business unit "NYC"`,
+				`3 businessUnitCode: business unit "OLD" is inactive`,
+				`4 departmentCode: department "QA" is inactive`,
+				`5 departmentCode: department "FIN" does not operate at business unit "MAN"`,
+				`6 jobTitleCode: unknown job title "CEO"`,
+			},
+		},
+		{
+			"custom fields",
+			[]ImportRow{
+				{Row: 2, EmployeeNo: "N1", FirstName: "A", LastName: "B", CustomFields: map[string]string{"shoe_size": "9"}},
+				{Row: 3, EmployeeNo: "N2", FirstName: "A", LastName: "B", CustomFields: map[string]string{"shift": "Nights"}},
+			},
+			1,
+			[]string{"2 customFields.shoe_size: unknown field"},
+		},
+		{
+			"managers",
+			[]ImportRow{
+				importRow(2, "N1", "N1"),
+				importRow(3, "N2", "E999"),
+				importRow(4, "N3", "E002"),
+				importRow(5, "N4", "e001"),
+			},
+			1,
+			[]string{
+				"2 managerEmployeeNo: an employee cannot manage themselves",
+				`3 managerEmployeeNo: unknown manager "E999"`,
+				`4 managerEmployeeNo: manager "E002" is inactive`,
+			},
+		},
+		{
+			"cycle within the file",
+			[]ImportRow{importRow(2, "N1", "N2"), importRow(3, "N2", "N3"), importRow(4, "N3", "N1"), importRow(5, "N4", "N1")},
+			0,
+			[]string{
+				"2 managerEmployeeNo: reporting line forms a cycle within the file",
+				"3 managerEmployeeNo: reporting line forms a cycle within the file",
+				"4 managerEmployeeNo: reporting line forms a cycle within the file",
+				"5 managerEmployeeNo: manager row 2 is invalid",
+			},
+		},
+		{
+			"invalid managers invalidate their reports",
+			[]ImportRow{
+				importRow(2, "N3", "N2"),
+				importRow(3, "N2", "N1"),
+				{Row: 4, EmployeeNo: "N1", FirstName: "A"},
+				importRow(5, "N4", "E001"),
+			},
+			1,
+			[]string{
+				"2 managerEmployeeNo: manager row 3 is invalid",
+				"3 managerEmployeeNo: manager row 4 is invalid",
+				"4 lastName: is required",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, report, err := planImport(tc.rows, testImportRefs())
+			if err != nil {
+				t.Fatalf("planImport() error = %v", err)
+			}
+			if report.TotalRows != len(tc.rows) || report.ValidRows != tc.valid || len(plan) != tc.valid {
+				t.Errorf("planImport() total %d, valid %d, planned %d; want %d, %d, %d",
+					report.TotalRows, report.ValidRows, len(plan), len(tc.rows), tc.valid, tc.valid)
+			}
+			if got := errorKeys(report.Errors); !reflect.DeepEqual(got, append([]string{}, tc.want...)) {
+				t.Errorf("planImport() errors = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlanImportOrdersManagersFirst(t *testing.T) {
+	rows := []ImportRow{
+		importRow(2, "N4", "N3"),
+		importRow(3, "N3", "N1"),
+		importRow(4, "N2", "E001"),
+		importRow(5, "N1", ""),
+	}
+	plan, report, err := planImport(rows, testImportRefs())
+	if err != nil {
+		t.Fatalf("planImport() error = %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("planImport() errors = %v", report.Errors)
+	}
+
+	var order []string
+	byNo := make(map[string]plannedEmployee)
+	for _, p := range plan {
+		order = append(order, p.row.EmployeeNo)
+		byNo[p.row.EmployeeNo] = p
+	}
+	if want := []string{"N1", "N3", "N4", "N2"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("planned order = %v, want %v", order, want)
+	}
+	if byNo["N4"].managerID != byNo["N3"].id || byNo["N3"].managerID != byNo["N1"].id {
+		t.Error("in-file managers are not linked to the planned ids")
+	}
+	if byNo["N2"].managerID != testUUID(existingManager) {
+		t.Errorf("N2 manager = %v, want the existing employee", byNo["N2"].managerID)
+	}
+	if byNo["N1"].managerID.Valid {
+		t.Error("N1 has a manager")
+	}
+}
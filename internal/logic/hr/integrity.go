@@ -0,0 +1,186 @@
+package hr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Integrity check identifiers reported in IntegrityIssue.Check
+const (
+	IntegrityOrphanedManager      = "orphaned_manager"
+	IntegrityInactiveManager      = "inactive_manager_with_active_reports"
+	IntegrityInactiveDepartment   = "inactive_department"
+	IntegrityInactiveBusinessUnit = "inactive_business_unit"
+	IntegrityReportingCycle       = "reporting_cycle"
+)
+
+type IntegrityIssue struct {
+	Check      string      `json:"check"`
+	EmployeeID pgtype.UUID `json:"employeeId"`
+	EmployeeNo string      `json:"employeeNo"`
+	Name       string      `json:"name"`
+	Detail     string      `json:"detail"`
+}
+
+type IntegrityReport struct {
+	TenantID   pgtype.UUID      `json:"tenantId"`
+	CheckedAt  time.Time        `json:"checkedAt"`
+	IssueCount int              `json:"issueCount"`
+	Counts     map[string]int   `json:"counts"`
+	Issues     []IntegrityIssue `json:"issues"`
+}
+
+// CheckIntegrity scans a tenant's reporting hierarchy and organisational assignments for
+// data that the write paths should have prevented: managers outside the tenant, inactive
+// managers still holding active reports, active employees in inactive departments or
+// business units, and reporting cycles that predate the database guard.
+func (s *EmployeeService) CheckIntegrity(ctx context.Context, tenantID pgtype.UUID) (IntegrityReport, error) {
+	report := IntegrityReport{
+		TenantID:  tenantID,
+		CheckedAt: time.Now().UTC(),
+		Counts: map[string]int{
+			IntegrityOrphanedManager:      0,
+			IntegrityInactiveManager:      0,
+			IntegrityInactiveDepartment:   0,
+			IntegrityInactiveBusinessUnit: 0,
+			IntegrityReportingCycle:       0,
+		},
+		Issues: []IntegrityIssue{},
+	}
+	add := func(issue IntegrityIssue) {
+		report.Issues = append(report.Issues, issue)
+		report.Counts[issue.Check]++
+	}
+
+	orphans, err := s.queries.ListEmployeesWithForeignManager(ctx, tenantID)
+	if err != nil {
+		return report, fmt.Errorf("checking orphaned managers: %w", err)
+	}
+	for _, row := range orphans {
+		add(IntegrityIssue{
+			Check:      IntegrityOrphanedManager,
+			EmployeeID: row.ID,
+			EmployeeNo: row.EmployeeNo,
+			Name:       row.FirstName + " " + row.LastName,
+			Detail:     "manager does not exist in this tenant",
+		})
+	}
+
+	inactiveManagers, err := s.queries.ListInactiveManagersWithActiveReports(ctx, tenantID)
+	if err != nil {
+		return report, fmt.Errorf("checking inactive managers: %w", err)
+	}
+	for _, row := range inactiveManagers {
+		add(IntegrityIssue{
+			Check:      IntegrityInactiveManager,
+			EmployeeID: row.ID,
+			EmployeeNo: row.EmployeeNo,
+			Name:       row.FirstName + " " + row.LastName,
+			Detail:     fmt.Sprintf("inactive manager has %d active direct report(s)", row.ActiveReportCount),
+		})
+	}
+
+	misplaced, err := s.queries.ListEmployeesInInactiveOrgUnits(ctx, tenantID)
+	if err != nil {
+		return report, fmt.Errorf("checking inactive org units: %w", err)
+	}
+	for _, row := range misplaced {
+		name := row.FirstName + " " + row.LastName
+		if row.DepartmentInactive {
+			add(IntegrityIssue{
+				Check:      IntegrityInactiveDepartment,
+				EmployeeID: row.ID,
+				EmployeeNo: row.EmployeeNo,
+				Name:       name,
+				Detail:     fmt.Sprintf("assigned to inactive department %q", row.DepartmentName.String),
+			})
+		}
+		if row.BusinessUnitInactive {
+			add(IntegrityIssue{
+				Check:      IntegrityInactiveBusinessUnit,
+				EmployeeID: row.ID,
+				EmployeeNo: row.EmployeeNo,
+				Name:       name,
+				Detail:     fmt.Sprintf("assigned to inactive business unit %q", row.BusinessUnitName.String),
+			})
+		}
+	}
+
+	g, err := s.loadOrgGraph(ctx, tenantID, true)
+	if err != nil {
+		return report, fmt.Errorf("checking reporting cycles: %w", err)
+	}
+	for _, cycle := range g.cycles() {
+		names := make([]string, 0, len(cycle)+1)
+		for _, node := range cycle {
+			names = append(names, node.EmployeeNo)
+		}
+		names = append(names, cycle[0].EmployeeNo)
+		detail := "reporting cycle " + strings.Join(names, " -> ")
+
+		for _, node := range cycle {
+			add(IntegrityIssue{
+				Check:      IntegrityReportingCycle,
+				EmployeeID: node.ID,
+				EmployeeNo: node.EmployeeNo,
+				Name:       node.FirstName + " " + node.LastName,
+				Detail:     detail,
+			})
+		}
+	}
+
+	report.IssueCount = len(report.Issues)
+	return report, nil
+}
+
+// cycles returns every reporting loop in the graph, each listed once in manager order.
+// Every employee has at most one manager, so following manager pointers from each node
+// either terminates or runs into exactly one loop.
+func (g *orgGraph) cycles() [][]*OrgChartNode {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+
+	state := make(map[[16]byte]int, len(g.nodes))
+	var cycles [][]*OrgChartNode
+
+	for _, start := range g.nodes {
+		if state[start.ID.Bytes] != unvisited {
+			continue
+		}
+
+		var path []*OrgChartNode
+		node := start
+		for node != nil && state[node.ID.Bytes] == unvisited {
+			state[node.ID.Bytes] = onPath
+			path = append(path, node)
+			node = g.manager(node)
+		}
+
+		if node != nil && state[node.ID.Bytes] == onPath {
+			for i, n := range path {
+				if n.ID == node.ID {
+					cycles = append(cycles, append([]*OrgChartNode(nil), path[i:]...))
+					break
+				}
+			}
+		}
+		for _, n := range path {
+			state[n.ID.Bytes] = done
+		}
+	}
+	return cycles
+}
+
+func (g *orgGraph) manager(node *OrgChartNode) *OrgChartNode {
+	if !node.ManagerID.Valid {
+		return nil
+	}
+	return g.byID[node.ManagerID.Bytes]
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/iam"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
@@ -65,6 +66,10 @@ func (s *OnboardingService) ExecuteOnboarding(
 	pgEmail.String = email
 	pgEmail.Valid = true
 
+	if err := checkSitePlacement(ctx, qtx, tenantID, busID, deptID); err != nil {
+		return OnboardingResult{}, err
+	}
+
 	_, err = qtx.CreateEmployee(ctx, domain.CreateEmployeeParams{
 		ID:             newEmpID,
 		TenantID:       tenantID,
@@ -79,7 +84,7 @@ func (s *OnboardingService) ExecuteOnboarding(
 		ManagerID:      mgrID,
 	})
 	if err != nil {
-		return OnboardingResult{}, fmt.Errorf("failed creating employee record: %w", err)
+		return OnboardingResult{}, fmt.Errorf("failed creating employee record: %w", mapEmployeeConstraintError(err))
 	}
 
 	// 2. Create User Identity
@@ -112,13 +117,21 @@ func (s *OnboardingService) ExecuteOnboarding(
 
 	// 3. Assign Default Role
 	// Ensure the role actually belongs to this tenant natively
-	_, err = qtx.GetRole(ctx, domain.GetRoleParams{
+	role, err := qtx.GetRole(ctx, domain.GetRoleParams{
 		TenantID: tenantID,
 		ID:       initialRoleID,
 	})
 	if err != nil {
 		return OnboardingResult{}, fmt.Errorf("failed resolving target role context: %w", err)
 	}
+	// Onboarding grants a role just like UserRoleService.AssignUserRole, so it is bounded the same way.
+	actorRoles, err := qtx.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: actorID})
+	if err != nil {
+		return OnboardingResult{}, fmt.Errorf("failed resolving actor roles: %w", err)
+	}
+	if !iam.Covers(actorRoles, []string{role.Code}) {
+		return OnboardingResult{}, iam.ErrRoleExceedsGranter
+	}
 
 	err = qtx.AssignUserRole(ctx, domain.AssignUserRoleParams{
 		TenantID:        tenantID,
@@ -139,11 +152,21 @@ func (s *OnboardingService) ExecuteOnboarding(
 
 	// Asynchronous Audit Logging safely triggered upon transaction completion bounds securely
 	if s.auditSvc != nil {
-		s.auditSvc.Log(tenantID, actorID, "ONBOARD", "Users", newUserID.Bytes, map[string]interface{}{
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Employees", empIDBytes, map[string]interface{}{
+			"employee_no": empNo,
+			"first_name":  first,
+			"last_name":   last,
+			"work_email":  email,
+			"source":      "onboarding",
+		})
+		s.auditSvc.Log(ctx, tenantID, actorID, "ONBOARD", "Users", newUserID.Bytes, map[string]interface{}{
 			"action":         "Complete Onboarding Flow",
 			"employee_no":    empNo,
 			"target_role_id": initialRoleID.Bytes,
 		})
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "UserRoles", userIDBytes, map[string]interface{}{
+			"role_id": initialRoleID,
+		})
 	}
 
 	return OnboardingResult{
@@ -0,0 +1,374 @@
+package hr
+
+import (
+	"context"
+	"sort"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultOrgChartDepth is the number of reporting levels returned when no depth is requested
+	DefaultOrgChartDepth = 3
+
+	// MaxOrgChartDepth caps requested depths and bounds the recursive hierarchy queries
+	MaxOrgChartDepth = 50
+)
+
+// OrgChartNode is an employee positioned in the reporting hierarchy. Depth is relative
+// to the requested root; in a chain of command it is the number of levels above the employee.
+type OrgChartNode struct {
+	ID                pgtype.UUID          `json:"id"`
+	EmployeeNo        string               `json:"employeeNo"`
+	FirstName         string               `json:"firstName"`
+	LastName          string               `json:"lastName"`
+	DisplayName       pgtype.Text          `json:"displayName"`
+	WorkEmail         pgtype.Text          `json:"workEmail"`
+	IsActive          bool                 `json:"isActive"`
+	ManagerID         pgtype.UUID          `json:"managerId"`
+	BusinessUnit      *BusinessUnitSummary `json:"businessUnit"`
+	Department        *DepartmentSummary   `json:"department"`
+	JobTitle          *JobTitleSummary     `json:"jobTitle"`
+	Depth             int                  `json:"depth"`
+	DirectReportCount int                  `json:"directReportCount"`
+	HasMoreReports    bool                 `json:"hasMoreReports"`
+	DirectReports     []*OrgChartNode      `json:"directReports,omitempty"`
+}
+
+// SpanOfControl summarises the reporting line below one manager
+type SpanOfControl struct {
+	Manager        *OrgChartNode `json:"manager"`
+	DirectReports  int           `json:"directReports"`
+	TotalHeadcount int           `json:"totalHeadcount"`
+	LayersBelow    int           `json:"layersBelow"`
+}
+
+// ClampOrgChartDepth normalises a requested depth into [0, MaxOrgChartDepth]
+func ClampOrgChartDepth(depth int) int {
+	if depth < 0 {
+		return 0
+	}
+	if depth > MaxOrgChartDepth {
+		return MaxOrgChartDepth
+	}
+	return depth
+}
+
+// GetOrgTree returns the employee as the root of a nested reporting tree limited to depth levels.
+// Nodes whose reports were cut off by the limit are flagged with HasMoreReports.
+func (s *EmployeeService) GetOrgTree(ctx context.Context, tenantID, employeeID pgtype.UUID, depth int) (*OrgChartNode, error) {
+	depth = ClampOrgChartDepth(depth)
+
+	rows, err := s.queries.GetEmployeeSubtree(ctx, domain.GetEmployeeSubtreeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+		MaxDepth: int32(depth),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	nodes := make(map[[16]byte]*OrgChartNode, len(rows))
+	var root *OrgChartNode
+	// Rows arrive ordered by depth, so every manager is registered before its reports
+	for _, row := range rows {
+		node := &OrgChartNode{
+			ID:                row.ID,
+			EmployeeNo:        row.EmployeeNo,
+			FirstName:         row.FirstName,
+			LastName:          row.LastName,
+			DisplayName:       row.DisplayName,
+			WorkEmail:         row.WorkEmail,
+			IsActive:          row.IsActive,
+			ManagerID:         row.ManagerID,
+			Depth:             int(row.Depth),
+			DirectReportCount: int(row.DirectReportCount),
+			HasMoreReports:    int(row.Depth) == depth && row.DirectReportCount > 0,
+		}
+		node.BusinessUnit, node.Department, node.JobTitle = orgSummaries(
+			row.BusinessUnitID, row.BusinessUnitCode, row.BusinessUnitName,
+			row.DepartmentID, row.DepartmentCode, row.DepartmentName,
+			row.JobTitleID, row.JobTitleCode, row.JobTitleName, row.JobTitleGrade,
+		)
+		nodes[row.ID.Bytes] = node
+
+		if row.Depth == 0 {
+			root = node
+			continue
+		}
+		if parent, ok := nodes[row.ManagerID.Bytes]; ok {
+			parent.DirectReports = append(parent.DirectReports, node)
+		}
+	}
+
+	return root, nil
+}
+
+// GetChainOfCommand returns the managers above an employee, starting with the direct manager.
+// A reporting cycle ends the chain at the first repeated employee.
+func (s *EmployeeService) GetChainOfCommand(ctx context.Context, tenantID, employeeID pgtype.UUID) ([]OrgChartNode, error) {
+	if _, err := s.GetEmployee(ctx, tenantID, employeeID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.GetEmployeeChainOfCommand(ctx, domain.GetEmployeeChainOfCommandParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chain := make([]OrgChartNode, len(rows))
+	for i, row := range rows {
+		chain[i] = OrgChartNode{
+			ID:                row.ID,
+			EmployeeNo:        row.EmployeeNo,
+			FirstName:         row.FirstName,
+			LastName:          row.LastName,
+			DisplayName:       row.DisplayName,
+			WorkEmail:         row.WorkEmail,
+			IsActive:          row.IsActive,
+			ManagerID:         row.ManagerID,
+			Depth:             int(row.Level),
+			DirectReportCount: int(row.DirectReportCount),
+		}
+		chain[i].BusinessUnit, chain[i].Department, chain[i].JobTitle = orgSummaries(
+			row.BusinessUnitID, row.BusinessUnitCode, row.BusinessUnitName,
+			row.DepartmentID, row.DepartmentCode, row.DepartmentName,
+			row.JobTitleID, row.JobTitleCode, row.JobTitleName, row.JobTitleGrade,
+		)
+	}
+	return chain, nil
+}
+
+// ListDirectReports returns only the employees reporting directly to the manager
+func (s *EmployeeService) ListDirectReports(ctx context.Context, tenantID, managerID pgtype.UUID) ([]OrgChartNode, error) {
+	if _, err := s.GetEmployee(ctx, tenantID, managerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListDirectReports(ctx, domain.ListDirectReportsParams{
+		TenantID:  tenantID,
+		ManagerID: managerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]OrgChartNode, len(rows))
+	for i, row := range rows {
+		reports[i] = OrgChartNode{
+			ID:                row.ID,
+			EmployeeNo:        row.EmployeeNo,
+			FirstName:         row.FirstName,
+			LastName:          row.LastName,
+			DisplayName:       row.DisplayName,
+			WorkEmail:         row.WorkEmail,
+			IsActive:          row.IsActive,
+			ManagerID:         row.ManagerID,
+			Depth:             1,
+			DirectReportCount: int(row.DirectReportCount),
+		}
+		reports[i].BusinessUnit, reports[i].Department, reports[i].JobTitle = orgSummaries(
+			row.BusinessUnitID, row.BusinessUnitCode, row.BusinessUnitName,
+			row.DepartmentID, row.DepartmentCode, row.DepartmentName,
+			row.JobTitleID, row.JobTitleCode, row.JobTitleName, row.JobTitleGrade,
+		)
+	}
+	return reports, nil
+}
+
+// orgGraph is the whole tenant's reporting structure held in memory
+type orgGraph struct {
+	nodes    []*OrgChartNode
+	byID     map[[16]byte]*OrgChartNode
+	children map[[16]byte][]*OrgChartNode
+}
+
+func (s *EmployeeService) loadOrgGraph(ctx context.Context, tenantID pgtype.UUID, includeInactive bool) (*orgGraph, error) {
+	rows, err := s.queries.ListOrgChartNodes(ctx, domain.ListOrgChartNodesParams{
+		TenantID:        tenantID,
+		IncludeInactive: includeInactive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &orgGraph{
+		nodes:    make([]*OrgChartNode, len(rows)),
+		byID:     make(map[[16]byte]*OrgChartNode, len(rows)),
+		children: make(map[[16]byte][]*OrgChartNode),
+	}
+	for i, row := range rows {
+		node := &OrgChartNode{
+			ID:          row.ID,
+			EmployeeNo:  row.EmployeeNo,
+			FirstName:   row.FirstName,
+			LastName:    row.LastName,
+			DisplayName: row.DisplayName,
+			WorkEmail:   row.WorkEmail,
+			IsActive:    row.IsActive,
+			ManagerID:   row.ManagerID,
+		}
+		node.BusinessUnit, node.Department, node.JobTitle = orgSummaries(
+			row.BusinessUnitID, row.BusinessUnitCode, row.BusinessUnitName,
+			row.DepartmentID, row.DepartmentCode, row.DepartmentName,
+			row.JobTitleID, row.JobTitleCode, row.JobTitleName, row.JobTitleGrade,
+		)
+		g.nodes[i] = node
+		g.byID[row.ID.Bytes] = node
+	}
+	for _, node := range g.nodes {
+		if node.ManagerID.Valid {
+			if _, ok := g.byID[node.ManagerID.Bytes]; ok {
+				g.children[node.ManagerID.Bytes] = append(g.children[node.ManagerID.Bytes], node)
+			}
+		}
+	}
+	for _, node := range g.nodes {
+		node.DirectReportCount = len(g.children[node.ID.Bytes])
+	}
+	return g, nil
+}
+
+// roots returns employees without a (visible) manager, followed by one entry point
+// into every reporting cycle so that no employee is left off the chart
+func (g *orgGraph) roots() []*OrgChartNode {
+	var roots []*OrgChartNode
+	reached := make(map[[16]byte]bool, len(g.nodes))
+	mark := func(root *OrgChartNode) {
+		stack := []*OrgChartNode{root}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if reached[n.ID.Bytes] {
+				continue
+			}
+			reached[n.ID.Bytes] = true
+			stack = append(stack, g.children[n.ID.Bytes]...)
+		}
+	}
+
+	for _, node := range g.nodes {
+		if _, ok := g.byID[node.ManagerID.Bytes]; !node.ManagerID.Valid || !ok {
+			roots = append(roots, node)
+			mark(node)
+		}
+	}
+	for _, node := range g.nodes {
+		if !reached[node.ID.Bytes] {
+			roots = append(roots, node)
+			mark(node)
+		}
+	}
+	return roots
+}
+
+// GetOrgChart returns the whole tenant as a forest of reporting trees limited to depth levels
+func (s *EmployeeService) GetOrgChart(ctx context.Context, tenantID pgtype.UUID, depth int, includeInactive bool) ([]*OrgChartNode, error) {
+	depth = ClampOrgChartDepth(depth)
+
+	g, err := s.loadOrgGraph(ctx, tenantID, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+
+	placed := make(map[[16]byte]bool, len(g.nodes))
+	var attach func(node *OrgChartNode, level int)
+	attach = func(node *OrgChartNode, level int) {
+		placed[node.ID.Bytes] = true
+		node.Depth = level
+		if level == depth {
+			node.HasMoreReports = node.DirectReportCount > 0
+			return
+		}
+		for _, child := range g.children[node.ID.Bytes] {
+			if placed[child.ID.Bytes] {
+				continue
+			}
+			node.DirectReports = append(node.DirectReports, child)
+			attach(child, level+1)
+		}
+	}
+
+	roots := g.roots()
+	for _, root := range roots {
+		attach(root, 0)
+	}
+	return roots, nil
+}
+
+// GetSpanOfControl computes, for every employee with direct reports, the number of direct
+// reports, the total headcount below them and how many reporting layers they span
+func (s *EmployeeService) GetSpanOfControl(ctx context.Context, tenantID pgtype.UUID, includeInactive bool) ([]SpanOfControl, error) {
+	g, err := s.loadOrgGraph(ctx, tenantID, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		node  *OrgChartNode
+		level int
+	}
+
+	var spans []SpanOfControl
+	for _, manager := range g.nodes {
+		if manager.DirectReportCount == 0 {
+			continue
+		}
+
+		span := SpanOfControl{Manager: manager, DirectReports: manager.DirectReportCount}
+		seen := map[[16]byte]bool{manager.ID.Bytes: true}
+		queue := []entry{{manager, 0}}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, child := range g.children[current.node.ID.Bytes] {
+				if seen[child.ID.Bytes] {
+					continue
+				}
+				seen[child.ID.Bytes] = true
+				span.TotalHeadcount++
+				if current.level+1 > span.LayersBelow {
+					span.LayersBelow = current.level + 1
+				}
+				queue = append(queue, entry{child, current.level + 1})
+			}
+		}
+		spans = append(spans, span)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].TotalHeadcount > spans[j].TotalHeadcount
+	})
+	return spans, nil
+}
+
+func orgSummaries(buID pgtype.UUID, buCode, buName pgtype.Text, deptID pgtype.UUID, deptCode, deptName pgtype.Text, jtID pgtype.UUID, jtCode, jtName, jtGrade pgtype.Text) (*BusinessUnitSummary, *DepartmentSummary, *JobTitleSummary) {
+	var bu *BusinessUnitSummary
+	if buID.Valid {
+		bu = &BusinessUnitSummary{ID: buID, Code: textPtr(buCode), Name: textPtr(buName)}
+	}
+	var dept *DepartmentSummary
+	if deptID.Valid {
+		dept = &DepartmentSummary{ID: deptID, Code: textPtr(deptCode), Name: textPtr(deptName)}
+	}
+	var jt *JobTitleSummary
+	if jtID.Valid {
+		jt = &JobTitleSummary{ID: jtID, Code: textPtr(jtCode), Name: textPtr(jtName), Grade: textPtr(jtGrade)}
+	}
+	return bu, dept, jt
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
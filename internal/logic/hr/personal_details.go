@@ -0,0 +1,397 @@
+package hr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/envelope"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// personalDetailsEntity is the audit log entity type of personal details reads and writes
+const personalDetailsEntity = "EmployeePersonalDetails"
+
+// niNumberPattern matches a UK National Insurance number without spaces, excluding the
+// prefixes and letters HMRC never issues
+var niNumberPattern = regexp.MustCompile(`^[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z][0-9]{6}[A-D]$`)
+
+var (
+	// ErrInvalidNINumber is returned for a malformed National Insurance number
+	ErrInvalidNINumber = errors.New("niNumber is not a valid National Insurance number")
+
+	// ErrInvalidDateOfBirth is returned for a date of birth that is malformed or in the future
+	ErrInvalidDateOfBirth = errors.New("dateOfBirth must be a past date in YYYY-MM-DD format")
+)
+
+// Address is a postal address
+type Address struct {
+	Line1    string `json:"line1" validate:"required,max=200"`
+	Line2    string `json:"line2,omitempty" validate:"max=200"`
+	City     string `json:"city" validate:"required,max=100"`
+	County   string `json:"county,omitempty" validate:"max=100"`
+	Postcode string `json:"postcode" validate:"required,max=20"`
+	Country  string `json:"country" validate:"required,max=100"`
+}
+
+// EmergencyContact is someone to call about the employee in an emergency
+type EmergencyContact struct {
+	Name         string `json:"name" validate:"required,max=200"`
+	Relationship string `json:"relationship,omitempty" validate:"max=100"`
+	Phone        string `json:"phone" validate:"required,max=50"`
+	Email        string `json:"email,omitempty" validate:"omitempty,email,max=320"`
+}
+
+// PersonalDetails is the decrypted personal data of an employee. Unset fields are null.
+type PersonalDetails struct {
+	EmployeeID        pgtype.UUID        `json:"employeeId"`
+	PersonalEmail     *string            `json:"personalEmail"`
+	Phone             *string            `json:"phone"`
+	Address           *Address           `json:"address"`
+	DateOfBirth       *string            `json:"dateOfBirth"`
+	EmergencyContacts []EmergencyContact `json:"emergencyContacts"`
+	NINumber          *string            `json:"niNumber"`
+	UpdatedByUserID   pgtype.UUID        `json:"updatedByUserId"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+}
+
+// PersonalDetailsInput replaces an employee's personal details; nil fields are cleared
+type PersonalDetailsInput struct {
+	PersonalEmail     *string
+	Phone             *string
+	Address           *Address
+	DateOfBirth       *string
+	EmergencyContacts []EmergencyContact
+	NINumber          *string
+}
+
+// PersonalDetailsService stores employee personal data encrypted under envelope keys. Only
+// this service sees it in plaintext, and every read is audited with the fields returned.
+type PersonalDetailsService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	keys     *envelope.Keyring
+	auditSvc *audit.AuditService
+}
+
+func NewPersonalDetailsService(database *db.DB, keys *envelope.Keyring, auditSvc *audit.AuditService) *PersonalDetailsService {
+	return &PersonalDetailsService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		keys:     keys,
+		auditSvc: auditSvc,
+	}
+}
+
+// GetPersonalDetails decrypts an employee's personal details on behalf of actorID and
+// records the read in the audit log. An employee without any recorded details gets an
+// empty record.
+func (s *PersonalDetailsService) GetPersonalDetails(ctx context.Context, tenantID, actorID, employeeID pgtype.UUID) (PersonalDetails, error) {
+	if _, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{TenantID: tenantID, ID: employeeID}); err != nil {
+		return PersonalDetails{}, err
+	}
+
+	row, err := s.queries.GetEmployeePersonalDetails(ctx, domain.GetEmployeePersonalDetailsParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PersonalDetails{EmployeeID: employeeID, EmergencyContacts: []EmergencyContact{}}, nil
+	}
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+
+	details, err := s.decrypt(row)
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "READ", personalDetailsEntity, employeeID.Bytes, map[string]interface{}{
+			"fields": details.fieldNames(),
+		})
+	}
+	return details, nil
+}
+
+// UpdatePersonalDetails replaces an employee's personal details. The audit entry names
+// the fields that changed but never their values.
+func (s *PersonalDetailsService) UpdatePersonalDetails(ctx context.Context, tenantID, actorID, employeeID pgtype.UUID, in PersonalDetailsInput) (PersonalDetails, error) {
+	next, err := normalizePersonalDetails(employeeID, in)
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return PersonalDetails{}, fmt.Errorf("failed to begin personal details transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := domain.New(tx)
+
+	// Locking the employee serialises the write with an erasure running at the same time
+	emp, err := qtx.GetEmployeeForUpdate(ctx, domain.GetEmployeeForUpdateParams{TenantID: tenantID, ID: employeeID})
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+	if emp.ErasedAt.Valid {
+		return PersonalDetails{}, ErrEmployeeErased
+	}
+
+	action := "UPDATE"
+	prev := PersonalDetails{EmployeeID: employeeID, EmergencyContacts: []EmergencyContact{}}
+	var dk *envelope.DataKey
+	row, err := qtx.GetEmployeePersonalDetailsForUpdate(ctx, domain.GetEmployeePersonalDetailsForUpdateParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+	})
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		action = "CREATE"
+		if dk, err = s.keys.NewDataKey(); err != nil {
+			return PersonalDetails{}, err
+		}
+	case err != nil:
+		return PersonalDetails{}, err
+	default:
+		if prev, err = s.decrypt(row); err != nil {
+			return PersonalDetails{}, err
+		}
+		if dk, err = s.keys.OpenDataKey(row.KeyID, row.WrappedKey); err != nil {
+			return PersonalDetails{}, err
+		}
+		// Writes move the record onto the active master key as a side effect
+		if _, err := s.keys.Rewrap(dk); err != nil {
+			return PersonalDetails{}, err
+		}
+	}
+
+	params := domain.UpsertEmployeePersonalDetailsParams{
+		EmployeeID:      employeeID,
+		TenantID:        tenantID,
+		KeyID:           dk.KeyID,
+		WrappedKey:      dk.Wrapped,
+		UpdatedByUserID: actorID,
+	}
+	for _, f := range personalFields(&next) {
+		var ciphertext []byte
+		if !f.empty() {
+			plaintext, err := json.Marshal(f.value)
+			if err != nil {
+				return PersonalDetails{}, err
+			}
+			if ciphertext, err = dk.Encrypt(plaintext, personalFieldAAD(employeeID, f.column)); err != nil {
+				return PersonalDetails{}, err
+			}
+		}
+		switch f.column {
+		case "personal_email":
+			params.PersonalEmail = ciphertext
+		case "phone":
+			params.Phone = ciphertext
+		case "address":
+			params.Address = ciphertext
+		case "date_of_birth":
+			params.DateOfBirth = ciphertext
+		case "emergency_contacts":
+			params.EmergencyContacts = ciphertext
+		case "ni_number":
+			params.NiNumber = ciphertext
+		}
+	}
+
+	saved, err := qtx.UpsertEmployeePersonalDetails(ctx, params)
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PersonalDetails{}, fmt.Errorf("failed to commit personal details transaction: %w", err)
+	}
+
+	next.UpdatedByUserID = saved.UpdatedByUserID
+	next.UpdatedAt = saved.UpdatedAt
+
+	if s.auditSvc != nil {
+		var changed []string
+		prevFields := personalFields(&prev)
+		for i, f := range personalFields(&next) {
+			if !reflect.DeepEqual(f.value, prevFields[i].value) && !(f.empty() && prevFields[i].empty()) {
+				changed = append(changed, f.column)
+			}
+		}
+		s.auditSvc.Log(ctx, tenantID, actorID, action, personalDetailsEntity, employeeID.Bytes, map[string]interface{}{
+			"fields": changed,
+		})
+	}
+	return next, nil
+}
+
+// RewrapKeys moves the data keys of records still wrapped with a retired master key onto
+// the active one, batch records at a time, and returns how many it re-wrapped. The
+// encrypted values themselves are untouched.
+func (s *PersonalDetailsService) RewrapKeys(ctx context.Context, batch int32) (int, error) {
+	active := s.keys.ActiveKeyID()
+	total := 0
+	for {
+		rows, err := s.queries.ListPersonalDetailsKeysNotWrappedWith(ctx, domain.ListPersonalDetailsKeysNotWrappedWithParams{
+			KeyID: active,
+			Limit: batch,
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			dk, err := s.keys.OpenDataKey(row.KeyID, row.WrappedKey)
+			if err != nil {
+				return total, fmt.Errorf("employee %x: %w", row.EmployeeID.Bytes, err)
+			}
+			if _, err := s.keys.Rewrap(dk); err != nil {
+				return total, err
+			}
+			// Matching on the old key ID skips records a concurrent write already moved
+			n, err := s.queries.RewrapEmployeePersonalDetailsKey(ctx, domain.RewrapEmployeePersonalDetailsKeyParams{
+				NewKeyID:   dk.KeyID,
+				WrappedKey: dk.Wrapped,
+				EmployeeID: row.EmployeeID,
+				OldKeyID:   row.KeyID,
+			})
+			if err != nil {
+				return total, err
+			}
+			total += int(n)
+		}
+	}
+}
+
+func (s *PersonalDetailsService) decrypt(row domain.EmployeePersonalDetail) (PersonalDetails, error) {
+	dk, err := s.keys.OpenDataKey(row.KeyID, row.WrappedKey)
+	if err != nil {
+		return PersonalDetails{}, err
+	}
+
+	details := PersonalDetails{
+		EmployeeID:        row.EmployeeID,
+		EmergencyContacts: []EmergencyContact{},
+		UpdatedByUserID:   row.UpdatedByUserID,
+		UpdatedAt:         row.UpdatedAt,
+	}
+	ciphertexts := map[string][]byte{
+		"personal_email":     row.PersonalEmail,
+		"phone":              row.Phone,
+		"address":            row.Address,
+		"date_of_birth":      row.DateOfBirth,
+		"emergency_contacts": row.EmergencyContacts,
+		"ni_number":          row.NiNumber,
+	}
+	for _, f := range personalFields(&details) {
+		plaintext, err := dk.Decrypt(ciphertexts[f.column], personalFieldAAD(row.EmployeeID, f.column))
+		if err != nil {
+			return PersonalDetails{}, fmt.Errorf("%s: %w", f.column, err)
+		}
+		if plaintext == nil {
+			continue
+		}
+		if err := json.Unmarshal(plaintext, f.target); err != nil {
+			return PersonalDetails{}, fmt.Errorf("%s: %w", f.column, err)
+		}
+	}
+	return details, nil
+}
+
+// personalField ties an encrypted column to its field of PersonalDetails
+type personalField struct {
+	column string
+	value  interface{} // the field's current value
+	target interface{} // pointer to the field, to decode into
+}
+
+func (f personalField) empty() bool {
+	if contacts, ok := f.value.([]EmergencyContact); ok {
+		return len(contacts) == 0
+	}
+	return reflect.ValueOf(f.value).IsNil()
+}
+
+func personalFields(d *PersonalDetails) []personalField {
+	return []personalField{
+		{"personal_email", d.PersonalEmail, &d.PersonalEmail},
+		{"phone", d.Phone, &d.Phone},
+		{"address", d.Address, &d.Address},
+		{"date_of_birth", d.DateOfBirth, &d.DateOfBirth},
+		{"emergency_contacts", d.EmergencyContacts, &d.EmergencyContacts},
+		{"ni_number", d.NINumber, &d.NINumber},
+	}
+}
+
+// fieldNames lists the fields that hold a value
+func (d *PersonalDetails) fieldNames() []string {
+	names := []string{}
+	for _, f := range personalFields(d) {
+		if !f.empty() {
+			names = append(names, f.column)
+		}
+	}
+	return names
+}
+
+// personalFieldAAD binds a ciphertext to its employee and column, so it cannot be copied
+// into another record or field and still decrypt
+func personalFieldAAD(employeeID pgtype.UUID, column string) []byte {
+	return []byte(fmt.Sprintf("employee_personal_details/%x/%s", employeeID.Bytes, column))
+}
+
+func normalizePersonalDetails(employeeID pgtype.UUID, in PersonalDetailsInput) (PersonalDetails, error) {
+	d := PersonalDetails{
+		EmployeeID:        employeeID,
+		PersonalEmail:     trimmedOrNil(in.PersonalEmail),
+		Phone:             trimmedOrNil(in.Phone),
+		Address:           in.Address,
+		DateOfBirth:       trimmedOrNil(in.DateOfBirth),
+		EmergencyContacts: in.EmergencyContacts,
+		NINumber:          trimmedOrNil(in.NINumber),
+	}
+	if d.EmergencyContacts == nil {
+		d.EmergencyContacts = []EmergencyContact{}
+	}
+
+	if d.NINumber != nil {
+		ni := strings.ToUpper(strings.ReplaceAll(*d.NINumber, " ", ""))
+		if !niNumberPattern.MatchString(ni) {
+			return PersonalDetails{}, ErrInvalidNINumber
+		}
+		d.NINumber = &ni
+	}
+	if d.DateOfBirth != nil {
+		dob, err := time.Parse("2006-01-02", *d.DateOfBirth)
+		if err != nil || !dob.Before(time.Now()) {
+			return PersonalDetails{}, ErrInvalidDateOfBirth
+		}
+	}
+	return d, nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,125 @@
+package iam
+
+import "sort"
+
+// Permissions checked by the API itself rather than through a role
+const (
+	// PermPersonalDataRead allows decrypting employees' personal details. ADMIN does not
+	// grant it, so access to personal data is given deliberately.
+	PermPersonalDataRead = "personal-data:read"
+	// PermPersonalDataManage allows editing employees' personal details
+	PermPersonalDataManage = "personal-data:manage"
+	// PermPersonalDataExport allows compiling everything held about an employee for a
+	// subject access request
+	PermPersonalDataExport = "personal-data:export"
+	// PermPersonalDataErase allows pseudonymising an employee under the right to erasure
+	PermPersonalDataErase = "personal-data:erase"
+	// PermExportsManage allows bulk exports of employees, users and the org structure
+	PermExportsManage = "exports:manage"
+	// PermAuditLogsRead allows reading the audit log, including who made each change
+	PermAuditLogsRead = "audit-logs:read"
+	// PermUsersImpersonate allows signing in as another user to see what they see
+	PermUsersImpersonate = "users:impersonate"
+)
+
+// rolePermissions names what each role lets a user do, so front-ends can show or hide
+// features without knowing role codes. Most routes authorise by role: every route
+// guarded by RequireRole("ADMIN") falls under one of the ADMIN permissions. Routes
+// guarded by RequirePermission check these permissions directly.
+var rolePermissions = map[string][]string{
+	"ADMIN": {
+		PermAuditLogsRead,
+		"competencies:manage",
+		"employees:manage",
+		PermExportsManage,
+		"integrations:manage",
+		"internal-audits:manage",
+		"notifications:manage",
+		"org:manage",
+		"roles:manage",
+		"tasks:manage",
+		"training:manage",
+		PermUsersImpersonate,
+		"users:manage",
+	},
+	"HR_ADMIN": {
+		PermPersonalDataErase,
+		PermPersonalDataExport,
+		PermPersonalDataManage,
+		PermPersonalDataRead,
+	},
+}
+
+// reservedRoleNames are the display names of the roles that grant permissions. Every
+// tenant gets these roles when it is created.
+var reservedRoleNames = map[string]string{
+	"ADMIN":    "Administrator",
+	"HR_ADMIN": "HR Administrator",
+}
+
+// Reserved reports whether code names a role that grants permissions. Such roles are
+// created by provisioning only, so nobody gains permissions by creating a role with the code.
+func Reserved(code string) bool {
+	return len(rolePermissions[code]) > 0
+}
+
+// ReservedRoles returns the sorted codes of the roles that grant permissions
+func ReservedRoles() []string {
+	codes := make([]string, 0, len(rolePermissions))
+	for code := range rolePermissions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ReservedRoleName returns the display name a reserved role is created with
+func ReservedRoleName(code string) string {
+	if name, ok := reservedRoleNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// HasPermission reports whether any of the role codes grants permission
+func HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions returns the sorted permissions granted by a set of role codes
+func Permissions(roles []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if !seen[p] {
+				seen[p] = true
+				out = append(out, p)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Covers reports whether held grants every permission that roles grant, so whoever holds
+// held gains nothing by handing roles to a key or acting as a user with them
+func Covers(held, roles []string) bool {
+	granted := make(map[string]bool)
+	for _, p := range Permissions(held) {
+		granted[p] = true
+	}
+	for _, p := range Permissions(roles) {
+		if !granted[p] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package iam
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	if !HasPermission([]string{"ADMIN"}, PermExportsManage) {
+		t.Error("ADMIN does not grant exports:manage")
+	}
+	if HasPermission([]string{"ADMIN"}, PermPersonalDataRead) {
+		t.Error("ADMIN grants personal-data:read")
+	}
+	if !HasPermission([]string{"EMPLOYEE", "HR_ADMIN"}, PermPersonalDataRead) {
+		t.Error("HR_ADMIN does not grant personal-data:read")
+	}
+	if HasPermission(nil, PermAuditLogsRead) {
+		t.Error("no roles grant audit-logs:read")
+	}
+}
+
+func TestCovers(t *testing.T) {
+	cases := []struct {
+		held, roles []string
+		want        bool
+	}{
+		{[]string{"ADMIN"}, []string{"ADMIN"}, true},
+		{[]string{"ADMIN"}, []string{"EMPLOYEE"}, true},
+		{[]string{"ADMIN"}, nil, true},
+		{[]string{"ADMIN"}, []string{"HR_ADMIN"}, false},
+		{[]string{"ADMIN"}, []string{"ADMIN", "HR_ADMIN"}, false},
+		{[]string{"HR_ADMIN"}, []string{"ADMIN"}, false},
+		{[]string{"ADMIN", "HR_ADMIN"}, []string{"HR_ADMIN"}, true},
+		{nil, []string{"ADMIN"}, false},
+		{nil, []string{"EMPLOYEE"}, true},
+	}
+	for _, tc := range cases {
+		if got := Covers(tc.held, tc.roles); got != tc.want {
+			t.Errorf("Covers(%v, %v) = %v, want %v", tc.held, tc.roles, got, tc.want)
+		}
+	}
+}
+
+func TestReserved(t *testing.T) {
+	for code, want := range map[string]bool{
+		"ADMIN":        true,
+		"HR_ADMIN":     true,
+		"EMPLOYEE":     false,
+		"SYSTEM_ADMIN": false,
+		"hr_admin":     false,
+		"":             false,
+	} {
+		if got := Reserved(code); got != want {
+			t.Errorf("Reserved(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestReservedRoles(t *testing.T) {
+	codes := ReservedRoles()
+	if len(codes) != 2 || codes[0] != "ADMIN" || codes[1] != "HR_ADMIN" {
+		t.Fatalf("ReservedRoles() = %v", codes)
+	}
+	for _, code := range codes {
+		if !Reserved(code) {
+			t.Errorf("ReservedRoles() returned %q, which Reserved rejects", code)
+		}
+		if ReservedRoleName(code) == code {
+			t.Errorf("ReservedRoleName(%q) has no display name", code)
+		}
+	}
+}
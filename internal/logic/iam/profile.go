@@ -0,0 +1,180 @@
+package iam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxPreferencesBytes bounds the preferences a user may store with one update
+const maxPreferencesBytes = 16 << 10
+
+// ErrPreferencesTooLarge is returned when a preferences update exceeds maxPreferencesBytes
+var ErrPreferencesTooLarge = errors.New("preferences must not exceed 16 KiB")
+
+// ProfileUser is the caller's own account as shown on their profile
+type ProfileUser struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenantId"`
+	EmployeeID  pgtype.UUID        `json:"employeeId"`
+	Email       string             `json:"email"`
+	DisplayName *string            `json:"displayName"`
+	AvatarURL   *string            `json:"avatarUrl"`
+	Locale      string             `json:"locale"`
+	Preferences json.RawMessage    `json:"preferences"`
+	LastLoginAt pgtype.Timestamptz `json:"lastLoginAt"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func toProfileUser(u domain.User) ProfileUser {
+	prefs := json.RawMessage(u.Preferences)
+	if len(prefs) == 0 {
+		prefs = json.RawMessage("{}")
+	}
+	return ProfileUser{
+		ID:          u.ID,
+		TenantID:    u.TenantID,
+		EmployeeID:  u.EmployeeID,
+		Email:       u.Email,
+		DisplayName: textPtr(u.DisplayName),
+		AvatarURL:   textPtr(u.AvatarUrl),
+		Locale:      u.Locale,
+		Preferences: prefs,
+		LastLoginAt: u.LastLoginAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+// RoleGrant is a role held by a user, with the site or department it is limited to when
+// it is not tenant-wide
+type RoleGrant struct {
+	RoleID           pgtype.UUID        `json:"roleId"`
+	Code             string             `json:"code"`
+	Name             string             `json:"name"`
+	BusinessUnitID   pgtype.UUID        `json:"businessUnitId"`
+	BusinessUnitName *string            `json:"businessUnitName"`
+	DepartmentID     pgtype.UUID        `json:"departmentId"`
+	DepartmentName   *string            `json:"departmentName"`
+	GrantedAt        pgtype.Timestamptz `json:"grantedAt"`
+}
+
+// Profile is what the signed-in user sees about themselves
+type Profile struct {
+	User        ProfileUser `json:"user"`
+	Roles       []RoleGrant `json:"roles"`
+	Permissions []string    `json:"permissions"`
+}
+
+// ProfileInput holds the self-editable fields. Nil fields are left unchanged and empty
+// strings clear them. Preferences are merged into the stored ones key by key; a null
+// value removes a key.
+type ProfileInput struct {
+	DisplayName *string
+	AvatarURL   *string
+	Preferences map[string]interface{}
+}
+
+// GetProfile returns a user's own account with their role grants and permissions
+func (s *UserService) GetProfile(ctx context.Context, tenantID, userID pgtype.UUID) (Profile, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return Profile{}, err
+	}
+	return s.profile(ctx, user)
+}
+
+// UpdateProfile edits the caller's self-editable fields
+func (s *UserService) UpdateProfile(ctx context.Context, tenantID, userID pgtype.UUID, in ProfileInput) (Profile, error) {
+	prefs := []byte("{}")
+	if len(in.Preferences) > 0 {
+		var err error
+		if prefs, err = json.Marshal(in.Preferences); err != nil {
+			return Profile{}, err
+		}
+		if len(prefs) > maxPreferencesBytes {
+			return Profile{}, ErrPreferencesTooLarge
+		}
+	}
+
+	params := domain.UpdateUserProfileParams{
+		TenantID:    tenantID,
+		ID:          userID,
+		Preferences: prefs,
+	}
+	if in.DisplayName != nil {
+		params.DisplayName = pgtype.Text{String: *in.DisplayName, Valid: true}
+	}
+	if in.AvatarURL != nil {
+		params.AvatarUrl = pgtype.Text{String: *in.AvatarURL, Valid: true}
+	}
+
+	user, err := s.queries.UpdateUserProfile(ctx, params)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if s.auditSvc != nil {
+		changes := map[string]interface{}{}
+		if in.DisplayName != nil {
+			changes["display_name"] = textPtr(user.DisplayName)
+		}
+		if in.AvatarURL != nil {
+			changes["avatar_url"] = textPtr(user.AvatarUrl)
+		}
+		if len(in.Preferences) > 0 {
+			keys := make([]string, 0, len(in.Preferences))
+			for k := range in.Preferences {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			changes["preferences"] = keys
+		}
+		s.auditSvc.Log(ctx, tenantID, userID, "UPDATE", "Users", userID.Bytes, changes)
+	}
+	return s.profile(ctx, user)
+}
+
+func (s *UserService) profile(ctx context.Context, user domain.User) (Profile, error) {
+	rows, err := s.queries.ListUserRoleGrants(ctx, domain.ListUserRoleGrantsParams{
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return Profile{}, err
+	}
+
+	grants := make([]RoleGrant, 0, len(rows))
+	codes := make([]string, 0, len(rows))
+	for _, r := range rows {
+		grants = append(grants, RoleGrant{
+			RoleID:           r.RoleID,
+			Code:             r.Code,
+			Name:             r.Name,
+			BusinessUnitID:   r.BusinessUnitID,
+			BusinessUnitName: textPtr(r.BusinessUnitName),
+			DepartmentID:     r.DepartmentID,
+			DepartmentName:   textPtr(r.DepartmentName),
+			GrantedAt:        r.GrantedAt,
+		})
+		codes = append(codes, r.Code)
+	}
+
+	return Profile{
+		User:        toProfileUser(user),
+		Roles:       grants,
+		Permissions: Permissions(codes),
+	}, nil
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
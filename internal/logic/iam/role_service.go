@@ -2,6 +2,7 @@ package iam
 
 import (
 	"context"
+	"errors"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
@@ -9,6 +10,10 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// ErrReservedRoleCode is returned when a role is created with the code of a role that
+// grants permissions
+var ErrReservedRoleCode = errors.New("this role code is reserved")
+
 type RoleService struct {
 	queries  *domain.Queries
 	auditSvc *audit.AuditService
@@ -22,6 +27,10 @@ func NewRoleService(database *db.DB, auditSvc *audit.AuditService) *RoleService
 }
 
 func (s *RoleService) CreateRole(ctx context.Context, id, tenantID, actorID pgtype.UUID, code, name string, description *string) (domain.RbacRole, error) {
+	if Reserved(code) {
+		return domain.RbacRole{}, ErrReservedRoleCode
+	}
+
 	var pgDesc pgtype.Text
 	if description != nil && *description != "" {
 		pgDesc.String = *description
@@ -37,7 +46,7 @@ func (s *RoleService) CreateRole(ctx context.Context, id, tenantID, actorID pgty
 	})
 
 	if err == nil && s.auditSvc != nil {
-		s.auditSvc.Log(tenantID, actorID, "CREATE", "Roles", id.Bytes, map[string]interface{}{
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Roles", id.Bytes, map[string]interface{}{
 			"code":        code,
 			"name":        name,
 			"description": description,
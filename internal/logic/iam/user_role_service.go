@@ -2,35 +2,90 @@ package iam
 
 import (
 	"context"
+	"errors"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// ErrRoleExceedsGranter is returned when a role grants a permission the user granting it
+// does not hold
+var ErrRoleExceedsGranter = errors.New("you cannot grant a role with permissions you do not hold")
+
 type UserRoleService struct {
-	queries *domain.Queries
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
 }
 
-func NewUserRoleService(database *db.DB) *UserRoleService {
+func NewUserRoleService(database *db.DB, auditSvc *audit.AuditService) *UserRoleService {
 	return &UserRoleService{
-		queries: domain.New(database.Pool),
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
 	}
 }
 
+// AssignUserRole grants a role to a user. Otherwise an administrator could grant, say,
+// HR_ADMIN to themselves, so the granter must hold every permission the role grants.
 func (s *UserRoleService) AssignUserRole(ctx context.Context, tenantID, userID, roleID, grantedByUserID pgtype.UUID) error {
-	return s.queries.AssignUserRole(ctx, domain.AssignUserRoleParams{
+	role, err := s.queries.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: roleID})
+	if err != nil {
+		return err
+	}
+	held, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: grantedByUserID})
+	if err != nil {
+		return err
+	}
+	if !Covers(held, []string{role.Code}) {
+		return ErrRoleExceedsGranter
+	}
+	return s.grant(ctx, tenantID, userID, roleID, grantedByUserID)
+}
+
+// ProvisionUserRole grants a role without checking who grants it. It is for operators
+// with database access, who need it to hand out the first HR_ADMIN of a tenant since
+// nobody holds the role's permissions yet.
+func (s *UserRoleService) ProvisionUserRole(ctx context.Context, tenantID, userID, roleID pgtype.UUID) error {
+	return s.grant(ctx, tenantID, userID, roleID, pgtype.UUID{})
+}
+
+func (s *UserRoleService) grant(ctx context.Context, tenantID, userID, roleID, grantedByUserID pgtype.UUID) error {
+	err := s.queries.AssignUserRole(ctx, domain.AssignUserRoleParams{
 		TenantID:        tenantID,
 		UserID:          userID,
 		RoleID:          roleID,
 		GrantedByUserID: grantedByUserID,
 	})
+
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, grantedByUserID, "CREATE", "UserRoles", userID.Bytes, s.roleChanges(ctx, tenantID, roleID))
+	}
+
+	return err
 }
 
-func (s *UserRoleService) RevokeUserRole(ctx context.Context, tenantID, userID, roleID pgtype.UUID) error {
-	return s.queries.RevokeUserRole(ctx, domain.RevokeUserRoleParams{
+func (s *UserRoleService) RevokeUserRole(ctx context.Context, tenantID, actorID, userID, roleID pgtype.UUID) error {
+	err := s.queries.RevokeUserRole(ctx, domain.RevokeUserRoleParams{
 		TenantID: tenantID,
 		UserID:   userID,
 		RoleID:   roleID,
 	})
+
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "UserRoles", userID.Bytes, s.roleChanges(ctx, tenantID, roleID))
+	}
+
+	return err
+}
+
+// roleChanges describes a grant for the audit trail. UserRoles entries are keyed by the user.
+func (s *UserRoleService) roleChanges(ctx context.Context, tenantID, roleID pgtype.UUID) map[string]interface{} {
+	changes := map[string]interface{}{
+		"role_id": roleID,
+	}
+	if role, err := s.queries.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: roleID}); err == nil {
+		changes["role_code"] = role.Code
+	}
+	return changes
 }
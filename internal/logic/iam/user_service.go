@@ -2,22 +2,41 @@ package iam
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/http/query"
 	"github.com/INOVA/DML/internal/logic/audit"
+	authLogic "github.com/INOVA/DML/internal/logic/auth"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+var (
+	// ErrImpersonateSelf is returned when an administrator tries to impersonate themselves
+	ErrImpersonateSelf = errors.New("you cannot impersonate yourself")
+	// ErrImpersonateAdmin is returned for targets holding the ADMIN role
+	ErrImpersonateAdmin = errors.New("administrators cannot be impersonated")
+	// ErrImpersonateElevated is returned for targets whose roles grant a permission the
+	// actor does not hold, such as HR_ADMIN's access to personal data
+	ErrImpersonateElevated = errors.New("you cannot impersonate a user with permissions you do not hold")
+	// ErrImpersonateForbidden is returned when the actor lacks the users:impersonate permission
+	ErrImpersonateForbidden = errors.New("you are not allowed to impersonate users")
+	// ErrImpersonateInactive is returned for deactivated targets
+	ErrImpersonateInactive = errors.New("deactivated users cannot be impersonated")
+)
+
 type UserService struct {
 	queries  *domain.Queries
+	authSvc  *authLogic.AuthService
 	auditSvc *audit.AuditService
 }
 
-func NewUserService(database *db.DB, auditSvc *audit.AuditService) *UserService {
+func NewUserService(database *db.DB, authSvc *authLogic.AuthService, auditSvc *audit.AuditService) *UserService {
 	return &UserService{
 		queries:  domain.New(database.Pool),
+		authSvc:  authSvc,
 		auditSvc: auditSvc,
 	}
 }
@@ -74,7 +93,7 @@ func (s *UserService) CreateUser(ctx context.Context, id, tenantID, actorID, emp
 	})
 
 	if err == nil && s.auditSvc != nil {
-		s.auditSvc.Log(tenantID, actorID, "CREATE", "Users", id.Bytes, map[string]interface{}{
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Users", id.Bytes, map[string]interface{}{
 			"email":        email,
 			"display_name": display,
 		})
@@ -89,3 +108,71 @@ func (s *UserService) GetUserByEmail(ctx context.Context, tenantID pgtype.UUID,
 		Email:    email,
 	})
 }
+
+// Impersonation is a session acting as another user
+type Impersonation struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+	UserID    pgtype.UUID `json:"userId"`
+	Email     string      `json:"email"`
+}
+
+// Impersonate issues actorID a short-lived token acting as targetID, so support staff see
+// what the user sees. Administrators, deactivated users and users whose roles grant a
+// permission the actor lacks cannot be impersonated.
+func (s *UserService) Impersonate(ctx context.Context, tenantID, actorID, targetID pgtype.UUID) (Impersonation, error) {
+	if actorID == targetID {
+		return Impersonation{}, ErrImpersonateSelf
+	}
+
+	target, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: targetID})
+	if err != nil {
+		return Impersonation{}, err
+	}
+	if !target.IsActive {
+		return Impersonation{}, ErrImpersonateInactive
+	}
+	actorRoles, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: actorID})
+	if err != nil {
+		return Impersonation{}, err
+	}
+	if !HasPermission(actorRoles, PermUsersImpersonate) {
+		return Impersonation{}, ErrImpersonateForbidden
+	}
+	roles, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: targetID})
+	if err != nil {
+		return Impersonation{}, err
+	}
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return Impersonation{}, ErrImpersonateAdmin
+		}
+	}
+	if !Covers(actorRoles, roles) {
+		return Impersonation{}, ErrImpersonateElevated
+	}
+
+	actor, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: actorID})
+	if err != nil {
+		return Impersonation{}, err
+	}
+
+	token, expiresAt, err := s.authSvc.IssueImpersonationToken(ctx, actor, target)
+	if err != nil {
+		return Impersonation{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "IMPERSONATE", "Users", target.ID.Bytes, map[string]interface{}{
+			"email":      target.Email,
+			"expires_at": expiresAt,
+		})
+	}
+
+	return Impersonation{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		UserID:    target.ID,
+		Email:     target.Email,
+	}, nil
+}
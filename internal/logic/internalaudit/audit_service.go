@@ -0,0 +1,646 @@
+package internalaudit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/capa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Internal audit statuses
+const (
+	StatusPlanned    = "planned"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+)
+
+// Audit team roles. Only auditors have to be independent of the audited department.
+const (
+	RoleAuditor         = "auditor"
+	RoleTechnicalExpert = "technical_expert"
+	RoleObserver        = "observer"
+)
+
+var (
+	// ErrProgrammeNotFound is returned when an audit references a programme outside the tenant
+	ErrProgrammeNotFound = errors.New("audit programme does not exist or is inaccessible")
+
+	// ErrProgrammeInactive is returned when scheduling an audit under a retired programme
+	ErrProgrammeInactive = errors.New("audit programme is inactive")
+
+	// ErrEmployeeNotFound is returned when an auditor is not an employee of the tenant
+	ErrEmployeeNotFound = errors.New("employee does not exist or is inaccessible")
+
+	// ErrDepartmentNotAtSite is returned when the audited department does not operate at the business unit
+	ErrDepartmentNotAtSite = errors.New("department does not operate at the selected business unit")
+
+	// ErrAuditorNotIndependent is returned when an auditor belongs to the department being audited
+	ErrAuditorNotIndependent = errors.New("auditors cannot audit their own department")
+
+	// ErrInvalidSchedule is returned when an audit is planned to end before it starts
+	ErrInvalidSchedule = errors.New("planned end must not be before planned start")
+
+	// ErrAuditNotPlanned is returned when changing the plan of an audit that has already started
+	ErrAuditNotPlanned = errors.New("audit is no longer in the planned status")
+
+	// ErrAuditNotInProgress is returned when recording results or findings outside a running audit
+	ErrAuditNotInProgress = errors.New("audit is not in progress")
+
+	// ErrChecklistIncomplete is returned when completing an audit with unanswered checklist items
+	ErrChecklistIncomplete = errors.New("all checklist items must be answered before the audit is completed")
+
+	// ErrNotLeadAuditor is returned when someone other than the lead auditor or an
+	// administrator plans, starts, completes or cancels an audit
+	ErrNotLeadAuditor = errors.New("only the lead auditor or an administrator can do this")
+
+	// ErrNotAuditTeam is returned when someone outside the audit team records results or findings
+	ErrNotAuditTeam = errors.New("only the lead auditor, an auditor or technical expert on the team, or an administrator can do this")
+
+	// ErrLeadAuditorChange is returned when someone other than an administrator replaces the lead auditor
+	ErrLeadAuditorChange = errors.New("only an administrator can change the lead auditor")
+)
+
+type InternalAuditService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	ncrSvc   *capa.NCRService
+	auditSvc *audit.AuditService
+}
+
+func NewInternalAuditService(database *db.DB, ncrSvc *capa.NCRService, auditSvc *audit.AuditService) *InternalAuditService {
+	return &InternalAuditService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		ncrSvc:   ncrSvc,
+		auditSvc: auditSvc,
+	}
+}
+
+// ProgrammeInput holds the fields of an audit programme
+type ProgrammeInput struct {
+	Name       string
+	Year       int32
+	Objectives *string
+	IsActive   bool
+}
+
+func (s *InternalAuditService) CreateProgramme(ctx context.Context, id, tenantID, actorID pgtype.UUID, in ProgrammeInput) (domain.AuditProgramme, error) {
+	programme, err := s.queries.CreateAuditProgramme(ctx, domain.CreateAuditProgrammeParams{
+		ID:              id,
+		TenantID:        tenantID,
+		Name:            in.Name,
+		Year:            in.Year,
+		Objectives:      optionalText(in.Objectives),
+		CreatedByUserID: actorID,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "AuditProgrammes", id.Bytes, map[string]interface{}{
+			"name": in.Name,
+			"year": in.Year,
+		})
+	}
+	return programme, err
+}
+
+func (s *InternalAuditService) UpdateProgramme(ctx context.Context, tenantID, actorID, id pgtype.UUID, in ProgrammeInput) (domain.AuditProgramme, error) {
+	programme, err := s.queries.UpdateAuditProgramme(ctx, domain.UpdateAuditProgrammeParams{
+		TenantID:   tenantID,
+		ID:         id,
+		Name:       in.Name,
+		Year:       in.Year,
+		Objectives: optionalText(in.Objectives),
+		IsActive:   in.IsActive,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "AuditProgrammes", id.Bytes, map[string]interface{}{
+			"name":      in.Name,
+			"year":      in.Year,
+			"is_active": in.IsActive,
+		})
+	}
+	return programme, err
+}
+
+func (s *InternalAuditService) GetProgramme(ctx context.Context, tenantID, id pgtype.UUID) (domain.AuditProgramme, error) {
+	return s.queries.GetAuditProgramme(ctx, domain.GetAuditProgrammeParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+func (s *InternalAuditService) ListProgrammes(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.AuditProgramme, int64, error) {
+	programmes, err := s.queries.ListAuditProgrammes(ctx, domain.ListAuditProgrammesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountAuditProgrammes(ctx, domain.CountAuditProgrammesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return programmes, total, nil
+}
+
+// TeamMember is an employee on an audit team besides the lead auditor
+type TeamMember struct {
+	EmployeeID pgtype.UUID `json:"employee_id"`
+	Role       string      `json:"role"`
+}
+
+// AuditInput holds the plan of an internal audit. Team replaces the whole audit team.
+type AuditInput struct {
+	ProgrammeID           pgtype.UUID
+	Title                 string
+	Scope                 *string
+	Criteria              *string
+	BusinessUnitID        pgtype.UUID
+	DepartmentID          pgtype.UUID
+	LeadAuditorEmployeeID pgtype.UUID
+	PlannedStart          time.Time
+	PlannedEnd            time.Time
+	Team                  []TeamMember
+}
+
+// CreateAudit schedules an internal audit under a programme with its lead auditor and team
+func (s *InternalAuditService) CreateAudit(ctx context.Context, id, tenantID, actorID pgtype.UUID, in AuditInput) (domain.InternalAudit, error) {
+	if err := s.checkPlan(ctx, tenantID, in); err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	created, err := qtx.CreateInternalAudit(ctx, domain.CreateInternalAuditParams{
+		ID:                    id,
+		TenantID:              tenantID,
+		ProgrammeID:           in.ProgrammeID,
+		Title:                 in.Title,
+		Scope:                 optionalText(in.Scope),
+		Criteria:              optionalText(in.Criteria),
+		BusinessUnitID:        in.BusinessUnitID,
+		DepartmentID:          in.DepartmentID,
+		LeadAuditorEmployeeID: in.LeadAuditorEmployeeID,
+		PlannedStart:          pgtype.Date{Time: in.PlannedStart, Valid: true},
+		PlannedEnd:            pgtype.Date{Time: in.PlannedEnd, Valid: true},
+		CreatedByUserID:       actorID,
+	})
+	if err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	if err := replaceTeam(ctx, qtx, tenantID, id, in.Team); err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to commit audit: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "InternalAudits", id.Bytes, map[string]interface{}{
+			"title":         in.Title,
+			"programme_id":  in.ProgrammeID,
+			"lead_auditor":  in.LeadAuditorEmployeeID,
+			"planned_start": in.PlannedStart.Format("2006-01-02"),
+			"planned_end":   in.PlannedEnd.Format("2006-01-02"),
+			"team_size":     len(in.Team),
+		})
+	}
+	return created, nil
+}
+
+// UpdateAudit replaces the plan and team of an audit that has not started yet. The lead
+// auditor may update their own audit, but only an administrator may hand it to someone else.
+func (s *InternalAuditService) UpdateAudit(ctx context.Context, tenantID, actorID, id pgtype.UUID, in AuditInput, asAdmin bool) (domain.InternalAudit, error) {
+	if err := s.checkPlan(ctx, tenantID, in); err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	current, err := qtx.GetInternalAuditForUpdate(ctx, domain.GetInternalAuditForUpdateParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.InternalAudit{}, err
+	}
+	if !asAdmin {
+		if err := s.authorize(ctx, qtx, tenantID, actorID, current, false); err != nil {
+			return domain.InternalAudit{}, err
+		}
+		if in.LeadAuditorEmployeeID != current.LeadAuditorEmployeeID {
+			return domain.InternalAudit{}, ErrLeadAuditorChange
+		}
+	}
+	if current.Status != StatusPlanned {
+		return domain.InternalAudit{}, ErrAuditNotPlanned
+	}
+
+	updated, err := qtx.UpdateInternalAudit(ctx, domain.UpdateInternalAuditParams{
+		TenantID:              tenantID,
+		ID:                    id,
+		Title:                 in.Title,
+		Scope:                 optionalText(in.Scope),
+		Criteria:              optionalText(in.Criteria),
+		BusinessUnitID:        in.BusinessUnitID,
+		DepartmentID:          in.DepartmentID,
+		LeadAuditorEmployeeID: in.LeadAuditorEmployeeID,
+		PlannedStart:          pgtype.Date{Time: in.PlannedStart, Valid: true},
+		PlannedEnd:            pgtype.Date{Time: in.PlannedEnd, Valid: true},
+	})
+	if err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	if err := replaceTeam(ctx, qtx, tenantID, id, in.Team); err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to commit audit: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "InternalAudits", id.Bytes, map[string]interface{}{
+			"title":         in.Title,
+			"lead_auditor":  map[string]interface{}{"from": current.LeadAuditorEmployeeID, "to": in.LeadAuditorEmployeeID},
+			"planned_start": in.PlannedStart.Format("2006-01-02"),
+			"planned_end":   in.PlannedEnd.Format("2006-01-02"),
+			"team_size":     len(in.Team),
+		})
+	}
+	return updated, nil
+}
+
+func replaceTeam(ctx context.Context, q *domain.Queries, tenantID, auditID pgtype.UUID, team []TeamMember) error {
+	if err := q.DeleteInternalAuditTeam(ctx, domain.DeleteInternalAuditTeamParams{
+		TenantID: tenantID,
+		AuditID:  auditID,
+	}); err != nil {
+		return fmt.Errorf("failed to clear audit team: %w", err)
+	}
+	for _, m := range team {
+		if err := q.AddInternalAuditTeamMember(ctx, domain.AddInternalAuditTeamMemberParams{
+			TenantID:   tenantID,
+			AuditID:    auditID,
+			EmployeeID: m.EmployeeID,
+			Role:       m.Role,
+		}); err != nil {
+			return fmt.Errorf("failed to add audit team member: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkPlan validates the programme, schedule, site and auditors of an audit plan. Following
+// ISO 19011, the lead auditor and team auditors may not belong to the audited department.
+func (s *InternalAuditService) checkPlan(ctx context.Context, tenantID pgtype.UUID, in AuditInput) error {
+	if in.PlannedEnd.Before(in.PlannedStart) {
+		return ErrInvalidSchedule
+	}
+
+	programme, err := s.queries.GetAuditProgramme(ctx, domain.GetAuditProgrammeParams{
+		TenantID: tenantID,
+		ID:       in.ProgrammeID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProgrammeNotFound
+	} else if err != nil {
+		return err
+	}
+	if !programme.IsActive {
+		return ErrProgrammeInactive
+	}
+
+	if in.DepartmentID.Valid {
+		links, err := s.queries.CountBusinessUnitDepartmentLinks(ctx, domain.CountBusinessUnitDepartmentLinksParams{
+			TenantID:       tenantID,
+			BusinessUnitID: in.BusinessUnitID,
+			DepartmentID:   in.DepartmentID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check site departments: %w", err)
+		}
+		if links == 0 {
+			return ErrDepartmentNotAtSite
+		}
+	}
+
+	auditors := []pgtype.UUID{in.LeadAuditorEmployeeID}
+	for _, m := range in.Team {
+		if m.EmployeeID == in.LeadAuditorEmployeeID {
+			continue
+		}
+		if m.Role == RoleAuditor {
+			auditors = append(auditors, m.EmployeeID)
+		} else if _, err := s.getEmployee(ctx, tenantID, m.EmployeeID); err != nil {
+			return err
+		}
+	}
+	for _, employeeID := range auditors {
+		emp, err := s.getEmployee(ctx, tenantID, employeeID)
+		if err != nil {
+			return err
+		}
+		if in.DepartmentID.Valid && emp.DepartmentID == in.DepartmentID {
+			return fmt.Errorf("%w: %s %s", ErrAuditorNotIndependent, emp.FirstName, emp.LastName)
+		}
+	}
+	return nil
+}
+
+func (s *InternalAuditService) getEmployee(ctx context.Context, tenantID, employeeID pgtype.UUID) (domain.Employee, error) {
+	emp, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Employee{}, ErrEmployeeNotFound
+	}
+	return emp, err
+}
+
+// AuditFilter narrows the audit list; empty fields do not filter
+type AuditFilter struct {
+	ProgrammeID    pgtype.UUID
+	Status         string
+	BusinessUnitID pgtype.UUID
+	DepartmentID   pgtype.UUID
+}
+
+func (s *InternalAuditService) ListAudits(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, filter AuditFilter) ([]domain.ListInternalAuditsRow, int64, error) {
+	audits, err := s.queries.ListInternalAudits(ctx, domain.ListInternalAuditsParams{
+		TenantID:       tenantID,
+		ProgrammeID:    filter.ProgrammeID,
+		Status:         filter.Status,
+		BusinessUnitID: filter.BusinessUnitID,
+		DepartmentID:   filter.DepartmentID,
+		Search:         params.Search,
+		Limit:          params.Limit(),
+		Offset:         params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountInternalAudits(ctx, domain.CountInternalAuditsParams{
+		TenantID:       tenantID,
+		ProgrammeID:    filter.ProgrammeID,
+		Status:         filter.Status,
+		BusinessUnitID: filter.BusinessUnitID,
+		DepartmentID:   filter.DepartmentID,
+		Search:         params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return audits, total, nil
+}
+
+// CalendarFilter selects the audits shown on the calendar. AuditorEmployeeID matches both
+// lead auditors and team members.
+type CalendarFilter struct {
+	From              time.Time
+	To                time.Time
+	BusinessUnitID    pgtype.UUID
+	AuditorEmployeeID pgtype.UUID
+}
+
+// Calendar lists the planned, running and completed audits whose schedule overlaps the range
+func (s *InternalAuditService) Calendar(ctx context.Context, tenantID pgtype.UUID, filter CalendarFilter) ([]domain.ListAuditCalendarRow, error) {
+	if filter.To.Before(filter.From) {
+		return nil, ErrInvalidSchedule
+	}
+	entries, err := s.queries.ListAuditCalendar(ctx, domain.ListAuditCalendarParams{
+		TenantID:          tenantID,
+		From:              pgtype.Date{Time: filter.From, Valid: true},
+		To:                pgtype.Date{Time: filter.To, Valid: true},
+		BusinessUnitID:    filter.BusinessUnitID,
+		AuditorEmployeeID: filter.AuditorEmployeeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []domain.ListAuditCalendarRow{}
+	}
+	return entries, nil
+}
+
+// AuditDetail is an internal audit with its team, checklist and findings
+type AuditDetail struct {
+	domain.InternalAudit
+	Team      []domain.ListInternalAuditTeamRow `json:"team"`
+	Checklist []domain.AuditChecklistItem       `json:"checklist"`
+	Findings  []domain.AuditFinding             `json:"findings"`
+}
+
+func (s *InternalAuditService) GetAudit(ctx context.Context, tenantID, id pgtype.UUID) (AuditDetail, error) {
+	a, err := s.queries.GetInternalAudit(ctx, domain.GetInternalAuditParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return AuditDetail{}, err
+	}
+
+	team, err := s.queries.ListInternalAuditTeam(ctx, domain.ListInternalAuditTeamParams{
+		TenantID: tenantID,
+		AuditID:  id,
+	})
+	if err != nil {
+		return AuditDetail{}, err
+	}
+	checklist, err := s.queries.ListAuditChecklistItems(ctx, domain.ListAuditChecklistItemsParams{
+		TenantID: tenantID,
+		AuditID:  id,
+	})
+	if err != nil {
+		return AuditDetail{}, err
+	}
+	findings, err := s.queries.ListAuditFindings(ctx, domain.ListAuditFindingsParams{
+		TenantID: tenantID,
+		AuditID:  id,
+	})
+	if err != nil {
+		return AuditDetail{}, err
+	}
+
+	detail := AuditDetail{
+		InternalAudit: a,
+		Team:          team,
+		Checklist:     checklist,
+		Findings:      findings,
+	}
+	if detail.Team == nil {
+		detail.Team = []domain.ListInternalAuditTeamRow{}
+	}
+	if detail.Checklist == nil {
+		detail.Checklist = []domain.AuditChecklistItem{}
+	}
+	if detail.Findings == nil {
+		detail.Findings = []domain.AuditFinding{}
+	}
+	return detail, nil
+}
+
+// StartAudit moves a planned audit to in progress. Status changes are reserved for the
+// lead auditor or an administrator.
+func (s *InternalAuditService) StartAudit(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (domain.InternalAudit, error) {
+	return s.setStatus(ctx, tenantID, actorID, id, StatusInProgress, nil, asAdmin)
+}
+
+// CompleteAudit closes a running audit once every checklist item has been answered
+func (s *InternalAuditService) CompleteAudit(ctx context.Context, tenantID, actorID, id pgtype.UUID, summary *string, asAdmin bool) (domain.InternalAudit, error) {
+	return s.setStatus(ctx, tenantID, actorID, id, StatusCompleted, summary, asAdmin)
+}
+
+// CancelAudit cancels an audit that has not started
+func (s *InternalAuditService) CancelAudit(ctx context.Context, tenantID, actorID, id pgtype.UUID, reason *string, asAdmin bool) (domain.InternalAudit, error) {
+	return s.setStatus(ctx, tenantID, actorID, id, StatusCancelled, reason, asAdmin)
+}
+
+func (s *InternalAuditService) setStatus(ctx context.Context, tenantID, actorID, id pgtype.UUID, to string, summary *string, asAdmin bool) (domain.InternalAudit, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	current, err := qtx.GetInternalAuditForUpdate(ctx, domain.GetInternalAuditForUpdateParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.InternalAudit{}, err
+	}
+	if !asAdmin {
+		if err := s.authorize(ctx, qtx, tenantID, actorID, current, false); err != nil {
+			return domain.InternalAudit{}, err
+		}
+	}
+
+	switch to {
+	case StatusInProgress, StatusCancelled:
+		if current.Status != StatusPlanned {
+			return domain.InternalAudit{}, ErrAuditNotPlanned
+		}
+	case StatusCompleted:
+		if current.Status != StatusInProgress {
+			return domain.InternalAudit{}, ErrAuditNotInProgress
+		}
+		open, err := qtx.CountUnansweredChecklistItems(ctx, domain.CountUnansweredChecklistItemsParams{
+			TenantID: tenantID,
+			AuditID:  id,
+		})
+		if err != nil {
+			return domain.InternalAudit{}, fmt.Errorf("failed to check checklist: %w", err)
+		}
+		if open > 0 {
+			return domain.InternalAudit{}, fmt.Errorf("%w (%d unanswered)", ErrChecklistIncomplete, open)
+		}
+	}
+
+	updated, err := qtx.SetInternalAuditStatus(ctx, domain.SetInternalAuditStatusParams{
+		TenantID: tenantID,
+		ID:       id,
+		Status:   to,
+		Summary:  optionalText(summary),
+	})
+	if err != nil {
+		return domain.InternalAudit{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.InternalAudit{}, fmt.Errorf("failed to commit audit status: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "InternalAudits", id.Bytes, map[string]interface{}{
+			"status":  map[string]interface{}{"from": current.Status, "to": to},
+			"summary": summary,
+		})
+	}
+	return updated, nil
+}
+
+// authorize allows the audit's lead auditor and, with team set, the auditors and technical
+// experts on its team. Observers only watch. Callers skip it for administrators.
+func (s *InternalAuditService) authorize(ctx context.Context, q *domain.Queries, tenantID, actorID pgtype.UUID, a domain.InternalAudit, team bool) error {
+	denied := ErrNotLeadAuditor
+	if team {
+		denied = ErrNotAuditTeam
+	}
+	if !actorID.Valid {
+		return denied
+	}
+
+	actor, err := q.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load actor: %w", err)
+	}
+	if !actor.EmployeeID.Valid {
+		return denied
+	}
+	if actor.EmployeeID == a.LeadAuditorEmployeeID {
+		return nil
+	}
+	if !team {
+		return denied
+	}
+
+	members, err := q.ListInternalAuditTeam(ctx, domain.ListInternalAuditTeamParams{
+		TenantID: tenantID,
+		AuditID:  a.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load audit team: %w", err)
+	}
+	for _, m := range members {
+		if m.EmployeeID == actor.EmployeeID && m.Role != RoleObserver {
+			return nil
+		}
+	}
+	return denied
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
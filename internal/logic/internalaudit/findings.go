@@ -0,0 +1,303 @@
+package internalaudit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/capa"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Finding classifications
+const (
+	FindingMajor       = "major"
+	FindingMinor       = "minor"
+	FindingObservation = "observation"
+)
+
+var (
+	// ErrChecklistItemNotFound is returned when a finding references a checklist item of another audit
+	ErrChecklistItemNotFound = errors.New("checklist item does not belong to this audit")
+
+	// ErrObservationNoCAPA is returned when raising a CAPA from an observation
+	ErrObservationNoCAPA = errors.New("observations do not raise a CAPA; only major and minor nonconformities do")
+
+	// ErrFindingHasNCR is returned when a CAPA has already been raised from the finding
+	ErrFindingHasNCR = errors.New("a non-conformance report has already been raised for this finding")
+
+	// ErrDepartmentRequired is returned when raising a CAPA from a site-wide audit without naming a department
+	ErrDepartmentRequired = errors.New("department is required because the audit covers the whole business unit")
+)
+
+// ChecklistItemInput is one question of an audit checklist
+type ChecklistItemInput struct {
+	Clause   *string
+	Question string
+}
+
+// SetChecklist replaces the checklist of a planned audit, numbering the items in order. It
+// is reserved for the lead auditor or an administrator.
+func (s *InternalAuditService) SetChecklist(ctx context.Context, tenantID, actorID, auditID pgtype.UUID, items []ChecklistItemInput, asAdmin bool) ([]domain.AuditChecklistItem, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin checklist transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	current, err := qtx.GetInternalAuditForUpdate(ctx, domain.GetInternalAuditForUpdateParams{
+		TenantID: tenantID,
+		ID:       auditID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !asAdmin {
+		if err := s.authorize(ctx, qtx, tenantID, actorID, current, false); err != nil {
+			return nil, err
+		}
+	}
+	if current.Status != StatusPlanned {
+		return nil, ErrAuditNotPlanned
+	}
+
+	if err := qtx.DeleteAuditChecklistItems(ctx, domain.DeleteAuditChecklistItemsParams{
+		TenantID: tenantID,
+		AuditID:  auditID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to clear checklist: %w", err)
+	}
+
+	out := make([]domain.AuditChecklistItem, 0, len(items))
+	for i, item := range items {
+		var id pgtype.UUID
+		id.Bytes = uuid.New()
+		id.Valid = true
+
+		created, err := qtx.CreateAuditChecklistItem(ctx, domain.CreateAuditChecklistItemParams{
+			ID:       id,
+			TenantID: tenantID,
+			AuditID:  auditID,
+			Position: int32(i + 1),
+			Clause:   optionalText(item.Clause),
+			Question: item.Question,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add checklist item: %w", err)
+		}
+		out = append(out, created)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit checklist: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "InternalAudits", auditID.Bytes, map[string]interface{}{
+			"checklist_items": len(items),
+		})
+	}
+	return out, nil
+}
+
+// RecordResult answers a checklist item of a running audit. Results and findings are
+// recorded by the audit team or an administrator.
+func (s *InternalAuditService) RecordResult(ctx context.Context, tenantID, actorID, auditID, itemID pgtype.UUID, result string, notes *string, asAdmin bool) (domain.AuditChecklistItem, error) {
+	if err := s.requireInProgress(ctx, tenantID, actorID, auditID, asAdmin); err != nil {
+		return domain.AuditChecklistItem{}, err
+	}
+
+	item, err := s.queries.RecordAuditChecklistResult(ctx, domain.RecordAuditChecklistResultParams{
+		TenantID:         tenantID,
+		AuditID:          auditID,
+		ID:               itemID,
+		Result:           pgtype.Text{String: result, Valid: true},
+		Notes:            optionalText(notes),
+		AnsweredByUserID: actorID,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "InternalAudits", auditID.Bytes, map[string]interface{}{
+			"checklist_item_id": itemID,
+			"result":            result,
+		})
+	}
+	return item, err
+}
+
+// FindingInput holds a finding raised during an audit, optionally against a checklist item
+type FindingInput struct {
+	ChecklistItemID pgtype.UUID
+	Classification  string
+	Clause          *string
+	Description     string
+	Evidence        *string
+}
+
+// AddFinding records a major or minor nonconformity or an observation on a running audit
+func (s *InternalAuditService) AddFinding(ctx context.Context, id, tenantID, actorID, auditID pgtype.UUID, in FindingInput, asAdmin bool) (domain.AuditFinding, error) {
+	if err := s.requireInProgress(ctx, tenantID, actorID, auditID, asAdmin); err != nil {
+		return domain.AuditFinding{}, err
+	}
+
+	clause := in.Clause
+	if in.ChecklistItemID.Valid {
+		item, err := s.queries.GetAuditChecklistItem(ctx, domain.GetAuditChecklistItemParams{
+			TenantID: tenantID,
+			AuditID:  auditID,
+			ID:       in.ChecklistItemID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.AuditFinding{}, ErrChecklistItemNotFound
+		} else if err != nil {
+			return domain.AuditFinding{}, err
+		}
+		if clause == nil && item.Clause.Valid {
+			clause = &item.Clause.String
+		}
+	}
+
+	finding, err := s.queries.CreateAuditFinding(ctx, domain.CreateAuditFindingParams{
+		ID:              id,
+		TenantID:        tenantID,
+		AuditID:         auditID,
+		ChecklistItemID: in.ChecklistItemID,
+		Classification:  in.Classification,
+		Clause:          optionalText(clause),
+		Description:     in.Description,
+		Evidence:        optionalText(in.Evidence),
+		CreatedByUserID: actorID,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "AuditFindings", id.Bytes, map[string]interface{}{
+			"audit_id":       auditID,
+			"classification": in.Classification,
+			"clause":         clause,
+		})
+	}
+	return finding, err
+}
+
+func (s *InternalAuditService) requireInProgress(ctx context.Context, tenantID, actorID, auditID pgtype.UUID, asAdmin bool) error {
+	a, err := s.queries.GetInternalAudit(ctx, domain.GetInternalAuditParams{
+		TenantID: tenantID,
+		ID:       auditID,
+	})
+	if err != nil {
+		return err
+	}
+	if !asAdmin {
+		if err := s.authorize(ctx, s.queries, tenantID, actorID, a, true); err != nil {
+			return err
+		}
+	}
+	if a.Status != StatusInProgress {
+		return ErrAuditNotInProgress
+	}
+	return nil
+}
+
+// RaiseNCRInput overrides what the CAPA raised from a finding is filed against. The department
+// defaults to the audited department and is required for audits of a whole business unit.
+type RaiseNCRInput struct {
+	DepartmentID    pgtype.UUID
+	OwnerEmployeeID pgtype.UUID
+}
+
+// RaiseNCR opens a non-conformance report classified as an audit finding from a major or minor
+// nonconformity. The lead auditor is recorded as the reporter and the finding is linked to
+// the NCR so it cannot be raised twice. It is reserved for the lead auditor or an administrator.
+func (s *InternalAuditService) RaiseNCR(ctx context.Context, tenantID, actorID, auditID, findingID pgtype.UUID, in RaiseNCRInput, asAdmin bool) (domain.Ncr, error) {
+	a, err := s.queries.GetInternalAudit(ctx, domain.GetInternalAuditParams{
+		TenantID: tenantID,
+		ID:       auditID,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if !asAdmin {
+		if err := s.authorize(ctx, s.queries, tenantID, actorID, a, false); err != nil {
+			return domain.Ncr{}, err
+		}
+	}
+	if a.Status != StatusInProgress && a.Status != StatusCompleted {
+		return domain.Ncr{}, ErrAuditNotInProgress
+	}
+
+	finding, err := s.queries.GetAuditFinding(ctx, domain.GetAuditFindingParams{
+		TenantID: tenantID,
+		AuditID:  auditID,
+		ID:       findingID,
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+	if finding.Classification == FindingObservation {
+		return domain.Ncr{}, ErrObservationNoCAPA
+	}
+	if finding.NcrID.Valid {
+		return domain.Ncr{}, ErrFindingHasNCR
+	}
+
+	departmentID := in.DepartmentID
+	if !departmentID.Valid {
+		departmentID = a.DepartmentID
+	}
+	if !departmentID.Valid {
+		return domain.Ncr{}, ErrDepartmentRequired
+	}
+
+	title := fmt.Sprintf("%s: %s nonconformity", a.Title, finding.Classification)
+	if finding.Clause.Valid {
+		title += " against " + finding.Clause.String
+	}
+	description := finding.Description
+	if finding.Evidence.Valid && strings.TrimSpace(finding.Evidence.String) != "" {
+		description += "\n\nEvidence: " + finding.Evidence.String
+	}
+
+	var ncrID pgtype.UUID
+	ncrID.Bytes = uuid.New()
+	ncrID.Valid = true
+
+	ncr, err := s.ncrSvc.CreateNCR(ctx, ncrID, tenantID, actorID, capa.NCRInput{
+		Title:                title,
+		Description:          description,
+		BusinessUnitID:       a.BusinessUnitID,
+		DepartmentID:         departmentID,
+		ReportedByEmployeeID: a.LeadAuditorEmployeeID,
+		OwnerEmployeeID:      in.OwnerEmployeeID,
+		DetectedOn:           time.Now(),
+		Severity:             finding.Classification,
+		Classification:       "audit",
+	})
+	if err != nil {
+		return domain.Ncr{}, err
+	}
+
+	if _, err := s.queries.LinkAuditFindingNCR(ctx, domain.LinkAuditFindingNCRParams{
+		TenantID: tenantID,
+		AuditID:  auditID,
+		ID:       findingID,
+		NcrID:    ncr.ID,
+	}); errors.Is(err, pgx.ErrNoRows) {
+		// Another request raised a CAPA for this finding in the meantime
+		return domain.Ncr{}, ErrFindingHasNCR
+	} else if err != nil {
+		return domain.Ncr{}, fmt.Errorf("failed to link finding to NCR: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "AuditFindings", findingID.Bytes, map[string]interface{}{
+			"ncr_id":    ncr.ID,
+			"reference": capa.Reference(ncr.Number),
+		})
+	}
+	return ncr, nil
+}
@@ -0,0 +1,414 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const (
+	// leaseDuration is how long a job stays owned by its runner without a renewal. Jobs
+	// whose lease lapses belong to a process that stopped and are failed by the others.
+	leaseDuration = 2 * time.Minute
+	leaseInterval = 30 * time.Second
+)
+
+// ErrNoFile is returned when a job has not (yet) produced a downloadable file.
+var ErrNoFile = errors.New("job has no downloadable file")
+
+// WorkFunc is the unit of work executed by the runner. The returned value is
+// serialized into the job's result column, even when an error is returned, so
+// partial reports remain available for polling clients.
+type WorkFunc func(ctx context.Context, progress *Progress) (interface{}, error)
+
+// FileResult is returned by work that writes an artifact to storage. The runner
+// records the file on the job so it can be downloaded once the job succeeds.
+type FileResult struct {
+	StorageKey  string `json:"-"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Rows        int    `json:"rows"`
+}
+
+// Job is the API representation of a background job.
+type Job struct {
+	ID              pgtype.UUID        `json:"id"`
+	Kind            string             `json:"kind"`
+	Status          string             `json:"status"`
+	Progress        int32              `json:"progress"`
+	Total           int32              `json:"total"`
+	Result          json.RawMessage    `json:"result"`
+	Error           pgtype.Text        `json:"error"`
+	FileName        pgtype.Text        `json:"fileName"`
+	ContentType     pgtype.Text        `json:"contentType"`
+	HasFile         bool               `json:"hasFile"`
+	CreatedByUserID pgtype.UUID        `json:"createdByUserId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	StartedAt       pgtype.Timestamptz `json:"startedAt"`
+	FinishedAt      pgtype.Timestamptz `json:"finishedAt"`
+}
+
+// ToJob maps a stored job onto its API representation.
+func ToJob(job domain.BackgroundJob) Job {
+	return Job{
+		ID:              job.ID,
+		Kind:            job.Kind,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		Total:           job.Total,
+		Result:          json.RawMessage(job.Result),
+		Error:           job.Error,
+		FileName:        job.FileName,
+		ContentType:     job.ContentType,
+		HasFile:         job.FileKey.Valid,
+		CreatedByUserID: job.CreatedByUserID,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+}
+
+type execution struct {
+	jobID pgtype.UUID
+	work  WorkFunc
+}
+
+// Runner persists job state in background_jobs and executes the work on a
+// small in-process worker pool, decoupled from the originating HTTP request.
+type Runner struct {
+	queries *domain.Queries
+	store   storage.Store
+	queue   chan execution
+	owner   pgtype.Text
+
+	mu     sync.Mutex
+	active map[pgtype.UUID]struct{}
+}
+
+// NewRunner creates a job runner and starts its background workers. The queue lives in
+// memory, so every job is leased to the runner that accepted it, which keeps renewing the
+// lease while it lives. Jobs whose lease lapsed belong to a process that stopped and can
+// never finish; they are marked failed, now and on every renewal, without touching the
+// jobs of other live replicas.
+func NewRunner(database *db.DB, store storage.Store, workers int) *Runner {
+	host, _ := os.Hostname()
+	r := &Runner{
+		queries: domain.New(database.Pool),
+		store:   store,
+		queue:   make(chan execution, 100),
+		owner:   pgtype.Text{String: fmt.Sprintf("%s/%s", host, uuid.NewString()), Valid: true},
+		active:  make(map[pgtype.UUID]struct{}),
+	}
+	r.failInterrupted(context.Background())
+	for i := 0; i < workers; i++ {
+		go r.worker()
+	}
+	go r.renewLeases(leaseInterval)
+	return r
+}
+
+func (r *Runner) renewLeases(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := context.Background()
+		if ids := r.activeJobs(); len(ids) > 0 {
+			if err := r.queries.RenewBackgroundJobLeases(ctx, domain.RenewBackgroundJobLeasesParams{
+				LeaseUntil: leaseUntil(),
+				LeaseOwner: r.owner,
+				Ids:        ids,
+			}); err != nil {
+				log.Printf("job runner failed renewing job leases: %v", err)
+			}
+		}
+		r.failInterrupted(ctx)
+	}
+}
+
+// failInterrupted fails the jobs whose runner stopped renewing their lease.
+func (r *Runner) failInterrupted(ctx context.Context) {
+	n, err := r.queries.FailInterruptedBackgroundJobs(ctx)
+	if err != nil {
+		log.Printf("job runner failed marking interrupted jobs failed: %v", err)
+	} else if n > 0 {
+		log.Printf("job runner marked %d interrupted jobs failed", n)
+	}
+}
+
+// activeJobs lists the jobs waiting in the queue or running, the only ones whose lease is
+// renewed, so a job lost to an error is eventually failed by the sweep.
+func (r *Runner) activeJobs() []pgtype.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]pgtype.UUID, 0, len(r.active))
+	for id := range r.active {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (r *Runner) setActive(jobID pgtype.UUID, active bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if active {
+		r.active[jobID] = struct{}{}
+	} else {
+		delete(r.active, jobID)
+	}
+}
+
+func leaseUntil() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now().Add(leaseDuration), Valid: true}
+}
+
+// Submit records a queued job and hands the work to the worker pool.
+func (r *Runner) Submit(ctx context.Context, tenantID, actorID pgtype.UUID, kind string, total int, work WorkFunc) (Job, error) {
+	var jobID pgtype.UUID
+	jobID.Bytes = uuid.New()
+	jobID.Valid = true
+
+	job, err := r.queries.CreateBackgroundJob(ctx, domain.CreateBackgroundJobParams{
+		ID:              jobID,
+		TenantID:        tenantID,
+		Kind:            kind,
+		Total:           int32(total),
+		CreatedByUserID: actorID,
+		LeaseOwner:      r.owner,
+		LeaseUntil:      leaseUntil(),
+	})
+	if err != nil {
+		return Job{}, fmt.Errorf("creating background job: %w", err)
+	}
+
+	r.setActive(jobID, true)
+	select {
+	case r.queue <- execution{jobID: jobID, work: work}:
+	default:
+		r.setActive(jobID, false)
+		// The job never ran, so it is dropped rather than left for the lease sweep
+		if err := r.queries.DeleteQueuedBackgroundJob(context.Background(), jobID); err != nil {
+			log.Printf("job runner failed removing rejected job: %v", err)
+		}
+		return Job{}, fmt.Errorf("job queue is full, try again later")
+	}
+
+	return ToJob(job), nil
+}
+
+// GetJob returns a job. Only its creator may see it, unless asAdmin is set; other users
+// get pgx.ErrNoRows, as job results and files can hold other people's data.
+func (r *Runner) GetJob(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (Job, error) {
+	job, err := r.getJob(ctx, tenantID, actorID, id, asAdmin)
+	if err != nil {
+		return Job{}, err
+	}
+	return ToJob(job), nil
+}
+
+// OpenFile returns a reader over the file produced by a succeeded job, on the same terms
+// as GetJob. The caller must close the returned reader.
+func (r *Runner) OpenFile(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (io.ReadCloser, Job, error) {
+	job, err := r.getJob(ctx, tenantID, actorID, id, asAdmin)
+	if err != nil {
+		return nil, Job{}, err
+	}
+	if job.Status != StatusSucceeded || !job.FileKey.Valid {
+		return nil, ToJob(job), ErrNoFile
+	}
+
+	rc, err := r.store.Open(ctx, job.FileKey.String)
+	if err != nil {
+		return nil, ToJob(job), fmt.Errorf("opening job file: %w", err)
+	}
+	return rc, ToJob(job), nil
+}
+
+func (r *Runner) getJob(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (domain.BackgroundJob, error) {
+	job, err := r.queries.GetBackgroundJob(ctx, domain.GetBackgroundJobParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.BackgroundJob{}, err
+	}
+	// Service account keys have no user, so they only see jobs as admins
+	if !asAdmin && (!actorID.Valid || job.CreatedByUserID != actorID) {
+		return domain.BackgroundJob{}, pgx.ErrNoRows
+	}
+	return job, nil
+}
+
+// Store exposes the storage backend so that work functions can write artifacts.
+func (r *Runner) Store() storage.Store {
+	return r.store
+}
+
+// ListJobs lists the tenant's jobs. Without asAdmin it lists only the actor's own jobs.
+func (r *Runner) ListJobs(ctx context.Context, tenantID, actorID pgtype.UUID, kind string, params query.PaginationParams, asAdmin bool) ([]Job, int64, error) {
+	var createdBy pgtype.UUID
+	if !asAdmin {
+		if !actorID.Valid {
+			return []Job{}, 0, nil
+		}
+		createdBy = actorID
+	}
+
+	jobs, err := r.queries.ListBackgroundJobs(ctx, domain.ListBackgroundJobsParams{
+		TenantID:        tenantID,
+		Kind:            kind,
+		CreatedByUserID: createdBy,
+		Limit:           params.Limit(),
+		Offset:          params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing background jobs: %w", err)
+	}
+
+	total, err := r.queries.CountBackgroundJobs(ctx, domain.CountBackgroundJobsParams{
+		TenantID:        tenantID,
+		Kind:            kind,
+		CreatedByUserID: createdBy,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("counting background jobs: %w", err)
+	}
+
+	items := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, ToJob(job))
+	}
+	return items, total, nil
+}
+
+func (r *Runner) worker() {
+	for exec := range r.queue {
+		r.run(exec)
+	}
+}
+
+func (r *Runner) run(exec execution) {
+	ctx := context.Background()
+	defer r.setActive(exec.jobID, false)
+
+	// A job this runner no longer owns was failed by the lease sweep and must not run
+	n, err := r.queries.MarkBackgroundJobRunning(ctx, domain.MarkBackgroundJobRunningParams{
+		ID:         exec.jobID,
+		LeaseOwner: r.owner,
+	})
+	if err != nil {
+		log.Printf("job runner failed marking job running: %v", err)
+		return
+	}
+	if n == 0 {
+		log.Printf("job runner skipped job %s: its lease lapsed before it started", uuid.UUID(exec.jobID.Bytes))
+		return
+	}
+
+	progress := &Progress{runner: r, jobID: exec.jobID}
+	result, err := r.safeExecute(ctx, exec.work, progress)
+	if err != nil {
+		r.fail(ctx, exec.jobID, result, err)
+		return
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		r.fail(ctx, exec.jobID, nil, fmt.Errorf("serializing job result: %w", err))
+		return
+	}
+
+	params := domain.CompleteBackgroundJobParams{
+		ID:         exec.jobID,
+		Result:     payload,
+		LeaseOwner: r.owner,
+	}
+	if file, ok := result.(FileResult); ok {
+		params.FileKey = pgtype.Text{String: file.StorageKey, Valid: true}
+		params.FileName = pgtype.Text{String: file.FileName, Valid: true}
+		params.ContentType = pgtype.Text{String: file.ContentType, Valid: true}
+	}
+
+	if err := r.queries.CompleteBackgroundJob(ctx, params); err != nil {
+		log.Printf("job runner failed completing job: %v", err)
+	}
+}
+
+// safeExecute shields the worker pool from panics inside job work.
+func (r *Runner) safeExecute(ctx context.Context, work WorkFunc, progress *Progress) (result interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("job panicked: %v", rec)
+		}
+	}()
+	return work(ctx, progress)
+}
+
+func (r *Runner) fail(ctx context.Context, jobID pgtype.UUID, result interface{}, cause error) {
+	var payload []byte
+	if result != nil {
+		payload, _ = json.Marshal(result)
+	}
+
+	if err := r.queries.FailBackgroundJob(ctx, domain.FailBackgroundJobParams{
+		ID:         jobID,
+		Result:     payload,
+		Error:      pgtype.Text{String: cause.Error(), Valid: true},
+		LeaseOwner: r.owner,
+	}); err != nil {
+		log.Printf("job runner failed recording job failure: %v", err)
+	}
+}
+
+// Progress throttles progress writes so that tight loops do not hammer the DB.
+type Progress struct {
+	runner    *Runner
+	jobID     pgtype.UUID
+	mu        sync.Mutex
+	done      int
+	lastFlush time.Time
+}
+
+// Add advances the job's progress counter by n processed items. A nil Progress
+// is valid and ignored, which lets synchronous callers share the same code path.
+func (p *Progress) Add(n int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += n
+	if time.Since(p.lastFlush) < 500*time.Millisecond {
+		return
+	}
+	p.lastFlush = time.Now()
+
+	if err := p.runner.queries.UpdateBackgroundJobProgress(context.Background(), domain.UpdateBackgroundJobProgressParams{
+		ID:       p.jobID,
+		Progress: int32(p.done),
+	}); err != nil {
+		log.Printf("job runner failed updating progress: %v", err)
+	}
+}
@@ -0,0 +1,660 @@
+// Package mfa manages TOTP second factors: enrolment, verification after the password
+// step, one-time recovery codes, per-tenant enforcement by role and admin resets.
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/envelope"
+	"github.com/INOVA/DML/internal/logic/audit"
+	authLogic "github.com/INOVA/DML/internal/logic/auth"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxFailedAttempts wrong codes in a row lock the second factor for lockoutMinutes
+	maxFailedAttempts = 5
+	lockoutMinutes    = 15
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrInvalidCode is returned when a TOTP or recovery code does not verify
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrLocked is returned while too many failed attempts block verification
+	ErrLocked = errors.New("too many failed attempts; try again later")
+	// ErrAlreadyEnrolled is returned when enrolling a user whose MFA is already on
+	ErrAlreadyEnrolled = errors.New("MFA is already enabled")
+	// ErrNotEnrolled is returned when MFA is needed but the user has none
+	ErrNotEnrolled = errors.New("MFA is not enabled")
+	// ErrRequiredByPolicy is returned when a user whose role requires MFA tries to turn it off
+	ErrRequiredByPolicy = errors.New("MFA is required for your role and cannot be turned off")
+	// ErrAccountDisabled is returned when completing sign-in for a deactivated user
+	ErrAccountDisabled = errors.New("account is disabled")
+)
+
+// Enrolment is a pending TOTP secret with the URI to show as a QR code
+type Enrolment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// Status describes a user's second factor
+type Status struct {
+	Enabled                bool               `json:"enabled"`
+	PendingEnrolment       bool               `json:"pendingEnrolment"`
+	Required               bool               `json:"required"`
+	ConfirmedAt            pgtype.Timestamptz `json:"confirmedAt"`
+	RecoveryCodesRemaining int64              `json:"recoveryCodesRemaining"`
+}
+
+// VerifyResult completes a sign-in with the same session details as a password login.
+// RecoveryCodes is only set when the sign-in also finished enrolment.
+type VerifyResult struct {
+	authLogic.LoginResult
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
+// RequiredRole is a role whose holders must use MFA
+type RequiredRole struct {
+	ID   pgtype.UUID `json:"id"`
+	Code string      `json:"code"`
+	Name string      `json:"name"`
+}
+
+type MFAService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	keys     *envelope.Keyring
+	authSvc  *authLogic.AuthService
+	auditSvc *audit.AuditService
+	// issuer names the account in authenticator apps
+	issuer string
+}
+
+func NewMFAService(database *db.DB, keys *envelope.Keyring, authSvc *authLogic.AuthService, auditSvc *audit.AuditService, issuer string) *MFAService {
+	return &MFAService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		keys:     keys,
+		authSvc:  authSvc,
+		auditSvc: auditSvc,
+		issuer:   issuer,
+	}
+}
+
+func (s *MFAService) Status(ctx context.Context, tenantID, userID pgtype.UUID) (Status, error) {
+	state, err := s.queries.GetMfaLoginState(ctx, domain.GetMfaLoginStateParams{UserID: userID, TenantID: tenantID})
+	if err != nil {
+		return Status{}, err
+	}
+	out := Status{Enabled: state.Enrolled, Required: state.Required}
+
+	m, err := s.queries.GetUserMfa(ctx, domain.GetUserMfaParams{TenantID: tenantID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return out, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	out.PendingEnrolment = !m.ConfirmedAt.Valid
+	out.ConfirmedAt = m.ConfirmedAt
+
+	out.RecoveryCodesRemaining, err = s.queries.CountUnusedMfaRecoveryCodes(ctx, domain.CountUnusedMfaRecoveryCodesParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	return out, err
+}
+
+// Enrol starts, or restarts, TOTP enrolment with a fresh secret. MFA is not on until the
+// user confirms a code from it.
+func (s *MFAService) Enrol(ctx context.Context, tenantID, userID pgtype.UUID) (Enrolment, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return Enrolment{}, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return Enrolment{}, err
+	}
+	dk, encrypted, err := s.encryptSecret(userID, secret)
+	if err != nil {
+		return Enrolment{}, err
+	}
+
+	_, err = s.queries.StartMfaEnrolment(ctx, domain.StartMfaEnrolmentParams{
+		UserID:          userID,
+		TenantID:        tenantID,
+		KeyID:           pgtype.Text{String: dk.KeyID, Valid: true},
+		WrappedKey:      dk.Wrapped,
+		EncryptedSecret: encrypted,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The upsert skips confirmed rows
+		return Enrolment{}, ErrAlreadyEnrolled
+	}
+	if err != nil {
+		return Enrolment{}, err
+	}
+	return Enrolment{Secret: secret, OtpauthURI: provisioningURI(s.issuer, user.Email, secret)}, nil
+}
+
+// Confirm turns MFA on once the user proves their authenticator with a code, and returns
+// their recovery codes
+func (s *MFAService) Confirm(ctx context.Context, tenantID, userID pgtype.UUID, code string) ([]string, error) {
+	m, err := s.queries.GetUserMfa(ctx, domain.GetUserMfaParams{TenantID: tenantID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.ConfirmedAt.Valid {
+		return nil, ErrAlreadyEnrolled
+	}
+	step, err := s.check(ctx, m, code)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	if _, err := q.ConfirmMfaEnrolment(ctx, domain.ConfirmMfaEnrolmentParams{
+		TenantID:     tenantID,
+		UserID:       userID,
+		LastUsedStep: step,
+	}); err != nil {
+		return nil, err
+	}
+	codes, err := replaceRecoveryCodes(ctx, q, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, userID, "CREATE", "UserMfa", userID.Bytes, map[string]interface{}{
+			"method": "TOTP",
+		})
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current TOTP code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, tenantID, userID pgtype.UUID, code string) ([]string, error) {
+	m, err := s.enabled(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	step, err := s.check(ctx, m, code)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	if err := claimStep(ctx, q, tenantID, userID, step); err != nil {
+		return nil, err
+	}
+	codes, err := replaceRecoveryCodes(ctx, q, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, userID, "UPDATE", "UserMfa", userID.Bytes, map[string]interface{}{
+			"recovery_codes_regenerated": true,
+		})
+	}
+	return codes, nil
+}
+
+// Disable turns a user's own MFA off after checking a current code. Users whose role
+// requires MFA cannot.
+func (s *MFAService) Disable(ctx context.Context, tenantID, userID pgtype.UUID, code string) error {
+	state, err := s.queries.GetMfaLoginState(ctx, domain.GetMfaLoginStateParams{UserID: userID, TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	if state.Required {
+		return ErrRequiredByPolicy
+	}
+	m, err := s.enabled(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	step, err := s.check(ctx, m, code)
+	if err != nil {
+		return err
+	}
+	// Claiming the step keeps an observed code from being replayed to turn MFA off
+	if err := claimStep(ctx, s.queries, tenantID, userID, step); err != nil {
+		return err
+	}
+
+	if err := s.remove(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, userID, "DELETE", "UserMfa", userID.Bytes, map[string]interface{}{
+			"method": "TOTP",
+		})
+	}
+	return nil
+}
+
+// Reset removes another user's second factor, e.g. after a lost phone. If their role
+// requires MFA they enrol again at their next sign-in.
+func (s *MFAService) Reset(ctx context.Context, tenantID, actorID, userID pgtype.UUID) error {
+	if _, err := s.queries.GetUserMfa(ctx, domain.GetUserMfaParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return err
+	}
+	if err := s.remove(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "UserMfa", userID.Bytes, map[string]interface{}{
+			"method": "TOTP",
+			"reset":  true,
+		})
+	}
+	return nil
+}
+
+func (s *MFAService) remove(ctx context.Context, tenantID, userID pgtype.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	if _, err := q.DeleteUserMfa(ctx, domain.DeleteUserMfaParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return err
+	}
+	if err := q.DeleteMfaRecoveryCodes(ctx, domain.DeleteMfaRecoveryCodesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// EnrolForLogin starts enrolment for a user the first sign-in step sent to enrol
+func (s *MFAService) EnrolForLogin(ctx context.Context, mfaToken string) (Enrolment, error) {
+	challenge, err := s.authSvc.ParseMFAChallenge(mfaToken)
+	if err != nil {
+		return Enrolment{}, err
+	}
+	if !challenge.Enrol {
+		return Enrolment{}, ErrAlreadyEnrolled
+	}
+	return s.Enrol(ctx, challenge.TenantID, challenge.UserID)
+}
+
+// VerifyLogin completes a sign-in, by password or single sign-on, with a TOTP code or a
+// recovery code. A user sent to enrol confirms their new authenticator here instead, and
+// gets their recovery codes.
+func (s *MFAService) VerifyLogin(ctx context.Context, mfaToken, code, recoveryCode string) (VerifyResult, error) {
+	challenge, err := s.authSvc.ParseMFAChallenge(mfaToken)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{TenantID: challenge.TenantID, ID: challenge.UserID})
+	if err != nil {
+		return VerifyResult{}, authLogic.ErrInvalidChallenge
+	}
+	if !user.IsActive {
+		return VerifyResult{}, ErrAccountDisabled
+	}
+
+	var result VerifyResult
+	if challenge.Enrol {
+		if result.RecoveryCodes, err = s.Confirm(ctx, user.TenantID, user.ID, code); err != nil {
+			return VerifyResult{}, err
+		}
+	} else if err := s.verify(ctx, user, code, recoveryCode); err != nil {
+		return VerifyResult{}, err
+	}
+
+	if result.LoginResult, err = s.authSvc.StartSession(ctx, user); err != nil {
+		return VerifyResult{}, err
+	}
+	return result, nil
+}
+
+func (s *MFAService) verify(ctx context.Context, user domain.User, code, recoveryCode string) error {
+	m, err := s.enabled(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if recoveryCode == "" {
+		step, err := s.check(ctx, m, code)
+		if err != nil {
+			return err
+		}
+		return claimStep(ctx, s.queries, user.TenantID, user.ID, step)
+	}
+
+	claimed, err := s.claimAttempt(ctx, m)
+	if err != nil {
+		return err
+	}
+	used, err := s.queries.UseMfaRecoveryCode(ctx, domain.UseMfaRecoveryCodeParams{
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		CodeHash: hashRecoveryCode(recoveryCode),
+	})
+	if err != nil {
+		return err
+	}
+	if used == 0 {
+		s.logLockout(ctx, claimed)
+		return ErrInvalidCode
+	}
+	if err := s.queries.ResetMfaFailures(ctx, domain.ResetMfaFailuresParams{TenantID: user.TenantID, UserID: user.ID}); err != nil {
+		return err
+	}
+
+	if s.auditSvc != nil {
+		remaining, _ := s.queries.CountUnusedMfaRecoveryCodes(ctx, domain.CountUnusedMfaRecoveryCodesParams{
+			TenantID: user.TenantID,
+			UserID:   user.ID,
+		})
+		s.auditSvc.Log(ctx, user.TenantID, user.ID, "UPDATE", "UserMfa", user.ID.Bytes, map[string]interface{}{
+			"recovery_code_used":       true,
+			"recovery_codes_remaining": remaining,
+		})
+	}
+	return nil
+}
+
+// claimStep records a successful TOTP check, claiming its time step so the code cannot be
+// used again. check compares against the step it read earlier, so of two requests racing
+// with the same code only the one whose update lands first succeeds.
+func claimStep(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID, step int64) error {
+	rows, err := q.RecordMfaSuccess(ctx, domain.RecordMfaSuccessParams{TenantID: tenantID, UserID: userID, Step: step})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// enabled loads a user's confirmed second factor
+func (s *MFAService) enabled(ctx context.Context, tenantID, userID pgtype.UUID) (domain.UserMfa, error) {
+	m, err := s.queries.GetUserMfa(ctx, domain.GetUserMfaParams{TenantID: tenantID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !m.ConfirmedAt.Valid) {
+		return domain.UserMfa{}, ErrNotEnrolled
+	}
+	return m, err
+}
+
+// check verifies a TOTP code, counting it towards a lockout, and returns the time step it
+// matched. Callers must claim the step, which also clears the attempt count.
+func (s *MFAService) check(ctx context.Context, m domain.UserMfa, code string) (int64, error) {
+	claimed, err := s.claimAttempt(ctx, m)
+	if err != nil {
+		return 0, err
+	}
+	secret, err := s.secret(claimed)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := verifyTOTP(secret, code, time.Now(), claimed.LastUsedStep)
+	if !ok {
+		s.logLockout(ctx, claimed)
+		return 0, ErrInvalidCode
+	}
+	return step, nil
+}
+
+func secretAAD(userID pgtype.UUID) []byte {
+	return []byte(fmt.Sprintf("user_mfa/%x/secret", userID.Bytes))
+}
+
+// encryptSecret seals a TOTP secret under a new data key bound to the user
+func (s *MFAService) encryptSecret(userID pgtype.UUID, secret string) (*envelope.DataKey, []byte, error) {
+	dk, err := s.keys.NewDataKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted, err := dk.Encrypt([]byte(secret), secretAAD(userID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return dk, encrypted, nil
+}
+
+// secret decrypts a user's TOTP secret. Factors confirmed before secrets were encrypted
+// hold it in plaintext until EncryptStoredSecrets has run.
+func (s *MFAService) secret(m domain.UserMfa) (string, error) {
+	if m.Secret.Valid {
+		return m.Secret.String, nil
+	}
+	dk, err := s.keys.OpenDataKey(m.KeyID.String, m.WrappedKey)
+	if err != nil {
+		return "", err
+	}
+	secret, err := dk.Decrypt(m.EncryptedSecret, secretAAD(m.UserID))
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// EncryptStoredSecrets encrypts the TOTP secrets still stored in plaintext, batch at a
+// time, and returns how many it encrypted. The server runs it at startup.
+func (s *MFAService) EncryptStoredSecrets(ctx context.Context, batch int32) (int, error) {
+	total := 0
+	for {
+		rows, err := s.queries.ListPlaintextMfaSecrets(ctx, batch)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			dk, encrypted, err := s.encryptSecret(row.UserID, row.Secret.String)
+			if err != nil {
+				return total, err
+			}
+			// Matching on a plaintext secret skips factors re-enrolled in the meantime
+			n, err := s.queries.EncryptMfaSecret(ctx, domain.EncryptMfaSecretParams{
+				KeyID:           pgtype.Text{String: dk.KeyID, Valid: true},
+				WrappedKey:      dk.Wrapped,
+				EncryptedSecret: encrypted,
+				UserID:          row.UserID,
+			})
+			if err != nil {
+				return total, err
+			}
+			total += int(n)
+		}
+	}
+}
+
+// RewrapKeys moves the data keys of TOTP secrets still wrapped with a retired master key
+// onto the active one, batch at a time, and returns how many it re-wrapped
+func (s *MFAService) RewrapKeys(ctx context.Context, batch int32) (int, error) {
+	active := pgtype.Text{String: s.keys.ActiveKeyID(), Valid: true}
+	total := 0
+	for {
+		rows, err := s.queries.ListMfaKeysNotWrappedWith(ctx, domain.ListMfaKeysNotWrappedWithParams{
+			KeyID: active,
+			Limit: batch,
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			dk, err := s.keys.OpenDataKey(row.KeyID.String, row.WrappedKey)
+			if err != nil {
+				return total, fmt.Errorf("user %x: %w", row.UserID.Bytes, err)
+			}
+			if _, err := s.keys.Rewrap(dk); err != nil {
+				return total, err
+			}
+			n, err := s.queries.RewrapUserMfaKey(ctx, domain.RewrapUserMfaKeyParams{
+				NewKeyID:   pgtype.Text{String: dk.KeyID, Valid: true},
+				WrappedKey: dk.Wrapped,
+				UserID:     row.UserID,
+				OldKeyID:   row.KeyID,
+			})
+			if err != nil {
+				return total, err
+			}
+			total += int(n)
+		}
+	}
+}
+
+// claimAttempt counts an attempt as failed before its code is compared, refusing it while
+// the factor is locked. Checking and counting in one update keeps concurrent requests from
+// all passing the lockout check; a correct code clears the count again.
+func (s *MFAService) claimAttempt(ctx context.Context, m domain.UserMfa) (domain.UserMfa, error) {
+	claimed, err := s.queries.ClaimMfaAttempt(ctx, domain.ClaimMfaAttemptParams{
+		TenantID:       m.TenantID,
+		UserID:         m.UserID,
+		MaxAttempts:    maxFailedAttempts,
+		LockoutMinutes: lockoutMinutes,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.UserMfa{}, ErrLocked
+	}
+	return claimed, err
+}
+
+// logLockout audits a failed attempt that locked the factor. An attempt is only claimed
+// while the factor is unlocked, so a lock on the claimed row was set by that attempt.
+func (s *MFAService) logLockout(ctx context.Context, claimed domain.UserMfa) {
+	if claimed.LockedUntil.Valid && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, claimed.TenantID, claimed.UserID, "UPDATE", "UserMfa", claimed.UserID.Bytes, map[string]interface{}{
+			"locked_until":    claimed.LockedUntil.Time,
+			"failed_attempts": claimed.FailedAttempts,
+		})
+	}
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode normalises a code as typed, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID) ([]string, error) {
+	if err := q.DeleteMfaRecoveryCodes(ctx, domain.DeleteMfaRecoveryCodesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	for len(codes) < recoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err := q.CreateMfaRecoveryCode(ctx, domain.CreateMfaRecoveryCodeParams{
+			ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			TenantID: tenantID,
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		}); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func (s *MFAService) GetPolicy(ctx context.Context, tenantID pgtype.UUID) ([]RequiredRole, error) {
+	rows, err := s.queries.ListMfaRequiredRoles(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]RequiredRole, 0, len(rows))
+	for _, r := range rows {
+		roles = append(roles, RequiredRole(r))
+	}
+	return roles, nil
+}
+
+// SetPolicy replaces the roles whose holders must use MFA. Holders without it are sent to
+// enrol at their next sign-in.
+func (s *MFAService) SetPolicy(ctx context.Context, tenantID, actorID pgtype.UUID, roleIDs []pgtype.UUID) ([]RequiredRole, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	if err := q.ClearMfaRequiredRoles(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		role, err := q.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: id})
+		if err != nil {
+			return nil, err
+		}
+		if err := q.AddMfaRequiredRole(ctx, domain.AddMfaRequiredRoleParams{TenantID: tenantID, RoleID: role.ID}); err != nil {
+			return nil, err
+		}
+		codes = append(codes, role.Code)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "MfaPolicies", tenantID.Bytes, map[string]interface{}{
+			"required_roles": codes,
+		})
+	}
+	return s.GetPolicy(ctx, tenantID)
+}
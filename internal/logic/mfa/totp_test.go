@@ -0,0 +1,147 @@
+package mfa
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/envelope"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rfcSecret is the key of the RFC 4226 and RFC 6238 SHA-1 test vectors, "12345678901234567890"
+var rfcSecret = base32NoPad.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	// RFC 6238 appendix B gives 94287082 at 59s, step 1; six digits keep the last six
+	at := time.Unix(59, 0)
+
+	step, ok := verifyTOTP(rfcSecret, "287082", at, 0)
+	if !ok || step != 1 {
+		t.Fatalf("verifyTOTP() = %d, %v; want 1, true", step, ok)
+	}
+	if _, ok := verifyTOTP(strings.ToLower(rfcSecret), "287 082", at, 0); !ok {
+		t.Error("verifyTOTP() refused a lower-case secret or a code with a space")
+	}
+	if _, ok := verifyTOTP(rfcSecret, "287083", at, 0); ok {
+		t.Error("verifyTOTP() accepted a wrong code")
+	}
+	if _, ok := verifyTOTP(rfcSecret, "28708", at, 0); ok {
+		t.Error("verifyTOTP() accepted a short code")
+	}
+	if _, ok := verifyTOTP("not base32!", "287082", at, 0); ok {
+		t.Error("verifyTOTP() accepted a malformed secret")
+	}
+}
+
+func TestVerifyTOTPAllowsOneStepOfDrift(t *testing.T) {
+	code := hotp([]byte("12345678901234567890"), 100)
+	for _, offset := range []int64{-1, 0, 1} {
+		at := time.Unix((100+offset)*totpPeriod, 0)
+		if step, ok := verifyTOTP(rfcSecret, code, at, 0); !ok || step != 100 {
+			t.Errorf("at step %d: verifyTOTP() = %d, %v; want 100, true", 100+offset, step, ok)
+		}
+	}
+	for _, offset := range []int64{-2, 2} {
+		at := time.Unix((100+offset)*totpPeriod, 0)
+		if _, ok := verifyTOTP(rfcSecret, code, at, 0); ok {
+			t.Errorf("at step %d: verifyTOTP() accepted a code from step 100", 100+offset)
+		}
+	}
+}
+
+func TestVerifyTOTPRefusesReplay(t *testing.T) {
+	code := hotp([]byte("12345678901234567890"), 100)
+	at := time.Unix(100*totpPeriod, 0)
+
+	step, ok := verifyTOTP(rfcSecret, code, at, 0)
+	if !ok {
+		t.Fatal("verifyTOTP() refused a current code")
+	}
+	// Once a step is recorded as used, neither it nor an earlier one verifies again
+	if _, ok := verifyTOTP(rfcSecret, code, at, step); ok {
+		t.Error("verifyTOTP() accepted a code for an already used step")
+	}
+	if _, ok := verifyTOTP(rfcSecret, hotp([]byte("12345678901234567890"), 99), at, step); ok {
+		t.Error("verifyTOTP() accepted a code older than the last used step")
+	}
+	if _, ok := verifyTOTP(rfcSecret, hotp([]byte("12345678901234567890"), 101), at, step); !ok {
+		t.Error("verifyTOTP() refused the next step's code")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	got := provisioningURI("DML", "jane@example.com", "ABC")
+	if !strings.HasPrefix(got, "otpauth://totp/DML:jane@example.com?") ||
+		!strings.Contains(got, "secret=ABC") ||
+		!strings.Contains(got, "issuer=DML") {
+		t.Fatalf("provisioningURI() = %q", got)
+	}
+}
+
+func TestRecoveryCodeHashIgnoresFormatting(t *testing.T) {
+	code, err := newRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Fatalf("newRecoveryCode() = %q, want xxxxx-xxxxx", code)
+	}
+	plain := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+	if hashRecoveryCode(code) != hashRecoveryCode(" "+plain+" ") {
+		t.Error("hashRecoveryCode() depends on case, spaces or dashes")
+	}
+}
+
+func TestSecretIsEncrypted(t *testing.T) {
+	keys, err := envelope.LoadKeyring(envelope.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &MFAService{keys: keys}
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	dk, encrypted, err := s.encryptSecret(userID, rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte(rfcSecret)) {
+		t.Fatal("encrypted secret contains the plaintext")
+	}
+
+	row := domain.UserMfa{
+		UserID:          userID,
+		KeyID:           pgtype.Text{String: dk.KeyID, Valid: true},
+		WrappedKey:      dk.Wrapped,
+		EncryptedSecret: encrypted,
+	}
+	got, err := s.secret(row)
+	if err != nil || got != rfcSecret {
+		t.Fatalf("secret() = %q, %v; want the enrolled secret", got, err)
+	}
+
+	// The ciphertext is bound to its user, so copying it onto another account fails
+	row.UserID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if _, err := s.secret(row); err == nil {
+		t.Error("secret() decrypted a secret copied to another user")
+	}
+
+	// Factors from before encryption are read as stored until they are encrypted
+	legacy := domain.UserMfa{UserID: userID, Secret: pgtype.Text{String: rfcSecret, Valid: true}}
+	if got, err := s.secret(legacy); err != nil || got != rfcSecret {
+		t.Fatalf("secret() = %q, %v for a plaintext secret", got, err)
+	}
+}
@@ -0,0 +1,343 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Outbox statuses
+const (
+	StatusQueued  = "queued"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+const (
+	// maxAttempts is how often delivery is tried before an email is marked failed
+	maxAttempts = 8
+	// batchSize caps the emails claimed per round trip
+	batchSize = 20
+	// sendLease is how long a claimed email stays reserved before another sender may retry it
+	sendLease = 5 * time.Minute
+	// sendTimeout bounds a single transport call
+	sendTimeout = time.Minute
+)
+
+var (
+	// ErrUnknownKind is returned for a notification kind without built-in templates
+	ErrUnknownKind = errors.New("unknown notification kind")
+
+	// ErrNotFailed is returned when retrying an email that has not failed
+	ErrNotFailed = errors.New("only failed emails can be retried")
+)
+
+// Mailer renders emails into the outbox and drains it through a transport with retries
+type Mailer struct {
+	db        *db.DB
+	queries   *domain.Queries
+	transport Transport
+	auditSvc  *audit.AuditService
+}
+
+// NewMailer creates a mailer and starts polling the outbox every interval
+func NewMailer(database *db.DB, transport Transport, auditSvc *audit.AuditService, interval time.Duration) *Mailer {
+	m := &Mailer{
+		db:        database,
+		queries:   domain.New(database.Pool),
+		transport: transport,
+		auditSvc:  auditSvc,
+	}
+	go m.poll(interval)
+	return m
+}
+
+// EnqueueTx renders the email of a notification kind for a user and queues it inside the
+// caller's transaction, so it is only sent if that transaction commits. It does not consult
+// the user's preferences; see PreferenceTx. Inactive users and kinds without a template are
+// skipped.
+func (m *Mailer) EnqueueTx(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID, kind string, data Data) error {
+	recipient, err := q.GetEmailRecipient(ctx, domain.GetEmailRecipientParams{
+		TenantID: tenantID,
+		ID:       userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("notify: loading recipient: %w", err)
+	}
+	if !recipient.IsActive {
+		return nil
+	}
+
+	tmpl, ok, err := resolveTemplate(ctx, q, tenantID, kind, recipient.Locale)
+	if err != nil || !ok {
+		return err
+	}
+
+	if data.RecipientName == "" {
+		data.RecipientName = recipient.Name
+	}
+	msg, err := tmpl.Render(recipient.Email, data)
+	if err != nil {
+		return err
+	}
+
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	// Like notifications, the email keeps a link to its record so an erasure can find it
+	var entityID pgtype.UUID
+	if parsed, err := uuid.Parse(data.EntityID); err == nil {
+		entityID = pgtype.UUID{Bytes: parsed, Valid: true}
+	}
+
+	if err := q.EnqueueEmail(ctx, domain.EnqueueEmailParams{
+		ID:         id,
+		TenantID:   tenantID,
+		UserID:     userID,
+		Kind:       kind,
+		ToAddress:  msg.To,
+		Subject:    msg.Subject,
+		HtmlBody:   msg.HTML,
+		TextBody:   msg.Text,
+		EntityType: pgtype.Text{String: data.EntityType, Valid: data.EntityType != ""},
+		EntityID:   entityID,
+	}); err != nil {
+		return fmt.Errorf("notify: queueing email: %w", err)
+	}
+	return nil
+}
+
+func (m *Mailer) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.dispatch(context.Background())
+	}
+}
+
+// dispatch sends every due email, a batch at a time
+func (m *Mailer) dispatch(ctx context.Context) {
+	for {
+		emails, err := m.queries.ClaimDueEmails(ctx, domain.ClaimDueEmailsParams{
+			LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(sendLease), Valid: true},
+			BatchSize:  batchSize,
+		})
+		if err != nil {
+			log.Printf("mailer failed claiming emails: %v", err)
+			return
+		}
+		for _, email := range emails {
+			m.deliver(ctx, email)
+		}
+		if len(emails) < batchSize {
+			return
+		}
+	}
+}
+
+func (m *Mailer) deliver(ctx context.Context, email domain.EmailOutbox) {
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	sendErr := m.transport.Send(sendCtx, Message{
+		To:      email.ToAddress,
+		Subject: email.Subject,
+		HTML:    email.HtmlBody,
+		Text:    email.TextBody,
+	})
+
+	var err error
+	switch {
+	case sendErr == nil:
+		err = m.queries.MarkEmailSent(ctx, email.ID)
+	case email.Attempts >= maxAttempts:
+		err = m.queries.FailEmail(ctx, domain.FailEmailParams{
+			ID:        email.ID,
+			LastError: pgtype.Text{String: sendErr.Error(), Valid: true},
+		})
+	default:
+		err = m.queries.RescheduleEmail(ctx, domain.RescheduleEmailParams{
+			ID:            email.ID,
+			LastError:     pgtype.Text{String: sendErr.Error(), Valid: true},
+			NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(backoff(email.Attempts)), Valid: true},
+		})
+	}
+	if err != nil {
+		log.Printf("mailer failed recording delivery: %v", err)
+	}
+}
+
+// backoff grows quadratically: 1, 4, 9 ... minutes after the first, second, third attempt
+func backoff(attempts int32) time.Duration {
+	return time.Duration(attempts*attempts) * time.Minute
+}
+
+func (m *Mailer) ListOutbox(ctx context.Context, tenantID pgtype.UUID, status string, params query.PaginationParams) ([]domain.EmailOutbox, int64, error) {
+	emails, err := m.queries.ListEmailOutbox(ctx, domain.ListEmailOutboxParams{
+		TenantID: tenantID,
+		Status:   status,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := m.queries.CountEmailOutbox(ctx, domain.CountEmailOutboxParams{
+		TenantID: tenantID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return emails, total, nil
+}
+
+// Retry queues a failed email for another round of delivery attempts
+func (m *Mailer) Retry(ctx context.Context, tenantID, actorID, id pgtype.UUID) (domain.EmailOutbox, error) {
+	email, err := m.queries.RequeueFailedEmail(ctx, domain.RequeueFailedEmailParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, getErr := m.queries.GetEmail(ctx, domain.GetEmailParams{TenantID: tenantID, ID: id}); getErr == nil {
+			return domain.EmailOutbox{}, ErrNotFailed
+		}
+		return domain.EmailOutbox{}, err
+	}
+	if err != nil {
+		return domain.EmailOutbox{}, err
+	}
+
+	if m.auditSvc != nil {
+		m.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "EmailOutbox", id.Bytes, map[string]interface{}{
+			"status": map[string]interface{}{"from": StatusFailed, "to": StatusQueued},
+		})
+	}
+	return email, nil
+}
+
+// EffectiveTemplate is the template used for a kind and locale and where it comes from
+type EffectiveTemplate struct {
+	Kind   string `json:"kind"`
+	Locale string `json:"locale"`
+	Source string `json:"source"` // tenant | builtin
+	Template
+}
+
+func (m *Mailer) ListTemplates(ctx context.Context, tenantID pgtype.UUID) ([]domain.NotificationTemplate, error) {
+	return m.queries.ListNotificationTemplates(ctx, tenantID)
+}
+
+// GetTemplate returns the tenant's override of a kind in a locale, or the built-in one
+func (m *Mailer) GetTemplate(ctx context.Context, tenantID pgtype.UUID, kind, locale string) (EffectiveTemplate, error) {
+	if !isKnownKind(kind) {
+		return EffectiveTemplate{}, ErrUnknownKind
+	}
+
+	out := EffectiveTemplate{Kind: kind, Locale: locale}
+	override, err := m.queries.GetNotificationTemplate(ctx, domain.GetNotificationTemplateParams{
+		TenantID: tenantID,
+		Kind:     kind,
+		Locale:   locale,
+	})
+	if err == nil {
+		out.Source = "tenant"
+		out.Template = Template{Subject: override.Subject, HTML: override.HtmlBody, Text: override.TextBody}
+		return out, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return EffectiveTemplate{}, err
+	}
+
+	builtin, ok := BuiltinTemplate(kind, locale)
+	if !ok {
+		return EffectiveTemplate{}, pgx.ErrNoRows
+	}
+	out.Source = "builtin"
+	out.Template = builtin
+	return out, nil
+}
+
+// SaveTemplate creates or replaces the tenant's template of a kind in a locale
+func (m *Mailer) SaveTemplate(ctx context.Context, tenantID, actorID pgtype.UUID, kind, locale string, tmpl Template) (domain.NotificationTemplate, error) {
+	if !isKnownKind(kind) {
+		return domain.NotificationTemplate{}, ErrUnknownKind
+	}
+	if err := tmpl.Validate(); err != nil {
+		return domain.NotificationTemplate{}, err
+	}
+
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	saved, err := m.queries.UpsertNotificationTemplate(ctx, domain.UpsertNotificationTemplateParams{
+		ID:       id,
+		TenantID: tenantID,
+		Kind:     kind,
+		Locale:   locale,
+		Subject:  tmpl.Subject,
+		HtmlBody: tmpl.HTML,
+		TextBody: tmpl.Text,
+	})
+	if err != nil {
+		return domain.NotificationTemplate{}, err
+	}
+
+	if m.auditSvc != nil {
+		m.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "NotificationTemplates", saved.ID.Bytes, map[string]interface{}{
+			"kind":    kind,
+			"locale":  locale,
+			"subject": tmpl.Subject,
+		})
+	}
+	return saved, nil
+}
+
+// DeleteTemplate removes a tenant override so the built-in template applies again
+func (m *Mailer) DeleteTemplate(ctx context.Context, tenantID, actorID pgtype.UUID, kind, locale string) error {
+	existing, err := m.queries.GetNotificationTemplate(ctx, domain.GetNotificationTemplateParams{
+		TenantID: tenantID,
+		Kind:     kind,
+		Locale:   locale,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.queries.DeleteNotificationTemplate(ctx, domain.DeleteNotificationTemplateParams{
+		TenantID: tenantID,
+		Kind:     kind,
+		Locale:   locale,
+	}); err != nil {
+		return err
+	}
+
+	if m.auditSvc != nil {
+		m.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "NotificationTemplates", existing.ID.Bytes, map[string]interface{}{
+			"kind":   kind,
+			"locale": locale,
+		})
+	}
+	return nil
+}
+
+func isKnownKind(kind string) bool {
+	_, ok := builtinTemplates[kind]
+	return ok
+}
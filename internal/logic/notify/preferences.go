@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Preference says which channels a user receives a notification kind on
+type Preference struct {
+	Kind  string `json:"kind"`
+	InApp bool   `json:"inApp"`
+	Email bool   `json:"email"`
+}
+
+// Settings are a user's notification locale and per-kind channel preferences
+type Settings struct {
+	Locale      string       `json:"locale"`
+	Preferences []Preference `json:"preferences"`
+}
+
+// PreferenceTx returns the user's preference for a kind. Both channels are on unless the
+// user opted out.
+func PreferenceTx(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID, kind string) (Preference, error) {
+	pref, err := q.GetNotificationPreference(ctx, domain.GetNotificationPreferenceParams{
+		TenantID: tenantID,
+		UserID:   userID,
+		Kind:     kind,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Preference{Kind: kind, InApp: true, Email: true}, nil
+	}
+	if err != nil {
+		return Preference{}, fmt.Errorf("notify: loading preference: %w", err)
+	}
+	return Preference{Kind: kind, InApp: pref.InApp, Email: pref.Email}, nil
+}
+
+// Settings returns the user's locale and a preference for every notification kind
+func (m *Mailer) Settings(ctx context.Context, tenantID, userID pgtype.UUID) (Settings, error) {
+	return settings(ctx, m.queries, tenantID, userID)
+}
+
+// UpdateSettings changes the user's locale, when given, and the listed preferences.
+// Kinds that are not listed keep their current preference.
+func (m *Mailer) UpdateSettings(ctx context.Context, tenantID, userID pgtype.UUID, locale *string, prefs []Preference) (Settings, error) {
+	for _, pref := range prefs {
+		if !isKnownKind(pref.Kind) {
+			return Settings{}, fmt.Errorf("%w: %s", ErrUnknownKind, pref.Kind)
+		}
+	}
+
+	tx, err := m.db.Pool.Begin(ctx)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to begin settings transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := domain.New(tx)
+
+	if locale != nil {
+		if err := qtx.SetUserLocale(ctx, domain.SetUserLocaleParams{
+			TenantID: tenantID,
+			ID:       userID,
+			Locale:   *locale,
+		}); err != nil {
+			return Settings{}, fmt.Errorf("failed to set locale: %w", err)
+		}
+	}
+
+	for _, pref := range prefs {
+		if _, err := qtx.UpsertNotificationPreference(ctx, domain.UpsertNotificationPreferenceParams{
+			TenantID: tenantID,
+			UserID:   userID,
+			Kind:     pref.Kind,
+			InApp:    pref.InApp,
+			Email:    pref.Email,
+		}); err != nil {
+			return Settings{}, fmt.Errorf("failed to save preference: %w", err)
+		}
+	}
+
+	out, err := settings(ctx, qtx, tenantID, userID)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Settings{}, fmt.Errorf("failed to commit settings: %w", err)
+	}
+	return out, nil
+}
+
+func settings(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID) (Settings, error) {
+	user, err := q.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       userID,
+	})
+	if err != nil {
+		return Settings{}, err
+	}
+
+	stored, err := q.ListNotificationPreferences(ctx, domain.ListNotificationPreferencesParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return Settings{}, err
+	}
+	byKind := make(map[string]domain.NotificationPreference, len(stored))
+	for _, pref := range stored {
+		byKind[pref.Kind] = pref
+	}
+
+	out := Settings{Locale: user.Locale, Preferences: []Preference{}}
+	for _, kind := range Kinds() {
+		pref := Preference{Kind: kind, InApp: true, Email: true}
+		if s, ok := byKind[kind]; ok {
+			pref.InApp = s.InApp
+			pref.Email = s.Email
+		}
+		out.Preferences = append(out.Preferences, pref)
+	}
+	return out, nil
+}
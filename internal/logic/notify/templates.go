@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Notification kinds with built-in email templates
+const (
+	KindTaskAssigned = "task_assigned"
+)
+
+// DefaultLocale is used when neither the recipient's locale nor its language has a template
+const DefaultLocale = "en"
+
+// ErrInvalidTemplate is returned when a template override does not parse
+var ErrInvalidTemplate = errors.New("template does not parse")
+
+// Data is what templates can reference, e.g. {{.RecipientName}} or {{.Title}}
+type Data struct {
+	RecipientName string
+	Title         string
+	Body          string
+	EntityType    string
+	EntityID      string
+}
+
+// Template is the source of an email in one locale
+type Template struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"htmlBody"`
+	Text    string `json:"textBody"`
+}
+
+// builtinTemplates are used unless the tenant overrides them, keyed by kind then locale
+var builtinTemplates = map[string]map[string]Template{
+	KindTaskAssigned: {
+		"en": {
+			Subject: "New task: {{.Title}}",
+			HTML: `<p>Hello {{.RecipientName}},</p>
+<p>You have a new task: <strong>{{.Title}}</strong></p>
+{{if .Body}}<p>{{.Body}}</p>
+{{end}}<p>Open your task list to pick it up.</p>`,
+			Text: `Hello {{.RecipientName}},
+
+You have a new task: {{.Title}}
+{{if .Body}}
+{{.Body}}
+{{end}}
+Open your task list to pick it up.
+`,
+		},
+	},
+}
+
+// Kinds lists the notification kinds that have built-in templates
+func Kinds() []string {
+	return []string{KindTaskAssigned}
+}
+
+// BuiltinTemplate returns the shipped template of a kind in a locale
+func BuiltinTemplate(kind, locale string) (Template, bool) {
+	t, ok := builtinTemplates[kind][locale]
+	return t, ok
+}
+
+// localeCandidates lists the locales to try for a recipient: de-AT, then de, then the default
+func localeCandidates(locale string) []string {
+	var out []string
+	if locale != "" {
+		out = append(out, locale)
+		if lang, _, found := strings.Cut(locale, "-"); found {
+			out = append(out, lang)
+		}
+	}
+	if locale != DefaultLocale {
+		out = append(out, DefaultLocale)
+	}
+	return out
+}
+
+// resolveTemplate picks the template for a kind and locale, preferring tenant overrides
+// over built-ins at each step of the locale fallback. ok is false when the kind has no
+// template at all, in which case no email is sent.
+func resolveTemplate(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, kind, locale string) (Template, bool, error) {
+	for _, candidate := range localeCandidates(locale) {
+		override, err := q.GetNotificationTemplate(ctx, domain.GetNotificationTemplateParams{
+			TenantID: tenantID,
+			Kind:     kind,
+			Locale:   candidate,
+		})
+		if err == nil {
+			return Template{Subject: override.Subject, HTML: override.HtmlBody, Text: override.TextBody}, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return Template{}, false, fmt.Errorf("notify: loading template: %w", err)
+		}
+		if t, ok := BuiltinTemplate(kind, candidate); ok {
+			return t, true, nil
+		}
+	}
+	return Template{}, false, nil
+}
+
+// Validate checks that every part of the template parses
+func (t Template) Validate() error {
+	if _, err := texttemplate.New("subject").Parse(t.Subject); err != nil {
+		return fmt.Errorf("%w: subject: %v", ErrInvalidTemplate, err)
+	}
+	if _, err := htmltemplate.New("html").Parse(t.HTML); err != nil {
+		return fmt.Errorf("%w: html body: %v", ErrInvalidTemplate, err)
+	}
+	if _, err := texttemplate.New("text").Parse(t.Text); err != nil {
+		return fmt.Errorf("%w: text body: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// Render executes the template against data. The HTML body is escaped contextually.
+func (t Template) Render(to string, data Data) (Message, error) {
+	msg := Message{To: to}
+
+	subject, err := executeText(t.Subject, data)
+	if err != nil {
+		return Message{}, fmt.Errorf("notify: rendering subject: %w", err)
+	}
+	// Header injection guard
+	msg.Subject = strings.Join(strings.Fields(subject), " ")
+
+	if msg.Text, err = executeText(t.Text, data); err != nil {
+		return Message{}, fmt.Errorf("notify: rendering text body: %w", err)
+	}
+
+	html, err := htmltemplate.New("html").Parse(t.HTML)
+	if err != nil {
+		return Message{}, fmt.Errorf("notify: rendering html body: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := html.Execute(&buf, data); err != nil {
+		return Message{}, fmt.Errorf("notify: rendering html body: %w", err)
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
+
+func executeText(src string, data Data) (string, error) {
+	tmpl, err := texttemplate.New("text").Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,190 @@
+// Package notify delivers notifications by email: per-tenant localised templates, a
+// transactional outbox drained by a retrying mailer, and per-user channel preferences.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a rendered email ready for delivery
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Transport hands a message to a delivery mechanism
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// TransportOptions selects and configures a transport. Kind is "smtp", "file" or "log".
+type TransportOptions struct {
+	Kind     string
+	From     string
+	SMTPHost string
+	SMTPPort int
+	Username string
+	Password string
+	Dir      string
+}
+
+// NewTransport builds the transport described by opts
+func NewTransport(opts TransportOptions) (Transport, error) {
+	if _, err := mail.ParseAddress(opts.From); err != nil {
+		return nil, fmt.Errorf("notify: invalid sender address %q: %w", opts.From, err)
+	}
+
+	switch opts.Kind {
+	case "smtp":
+		if opts.SMTPHost == "" {
+			return nil, fmt.Errorf("notify: SMTP transport needs a host")
+		}
+		return &SMTPTransport{
+			from:     opts.From,
+			addr:     net.JoinHostPort(opts.SMTPHost, strconv.Itoa(opts.SMTPPort)),
+			host:     opts.SMTPHost,
+			username: opts.Username,
+			password: opts.Password,
+		}, nil
+	case "file":
+		return &FileTransport{from: opts.From, dir: opts.Dir}, nil
+	case "", "log":
+		return &FileTransport{from: opts.From}, nil
+	default:
+		return nil, fmt.Errorf("notify: unknown transport %q", opts.Kind)
+	}
+}
+
+// SMTPTransport relays mail through an SMTP server, upgrading to TLS when the server
+// offers STARTTLS. Without a username it sends unauthenticated, which suits local
+// SMTP stand-ins such as Mailpit.
+type SMTPTransport struct {
+	from     string
+	addr     string
+	host     string
+	username string
+	password string
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg Message) error {
+	raw, err := buildMIME(t.from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if t.username != "" {
+		auth = smtp.PlainAuth("", t.username, t.password, t.host)
+	}
+
+	sender, _ := mail.ParseAddress(t.from)
+	if err := smtp.SendMail(t.addr, auth, sender.Address, []string{msg.To}, raw); err != nil {
+		return fmt.Errorf("notify: smtp send: %w", err)
+	}
+	return nil
+}
+
+// FileTransport is for local development. It logs each message and, when dir is set,
+// writes it there as an .eml file that mail clients can open.
+type FileTransport struct {
+	from string
+	dir  string
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg Message) error {
+	if t.dir == "" {
+		log.Printf("notify: email to %s: %s", msg.To, msg.Subject)
+		return nil
+	}
+
+	raw, err := buildMIME(t.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0o750); err != nil {
+		return fmt.Errorf("notify: creating mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), randomToken(4))
+	path := filepath.Join(t.dir, name)
+	if err := os.WriteFile(path, raw, 0o640); err != nil {
+		return fmt.Errorf("notify: writing message: %w", err)
+	}
+	log.Printf("notify: email to %s written to %s", msg.To, path)
+	return nil
+}
+
+// buildMIME renders msg as a multipart/alternative message with text and HTML parts
+func buildMIME(from string, msg Message) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("notify: building message: %w", err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("notify: building message: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("notify: building message: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("notify: building message: %w", err)
+	}
+
+	sender, _ := mail.ParseAddress(from)
+	domain := "localhost"
+	if at := strings.LastIndexByte(sender.Address, '@'); at >= 0 {
+		domain = sender.Address[at+1:]
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&out, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: <%s@%s>\r\n", randomToken(16), domain)
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,163 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrDepartmentInUse is returned when unlinking a department from a site that still has employees placed in it
+var ErrDepartmentInUse = errors.New("department still has employees at this business unit")
+
+// SiteDepartment is a department that operates at a business unit, with the number of
+// active employees placed in that department at that site
+type SiteDepartment struct {
+	ID                 pgtype.UUID        `json:"id"`
+	ParentDepartmentID pgtype.UUID        `json:"parentDepartmentId"`
+	Code               *string            `json:"code"`
+	Name               string             `json:"name"`
+	IsActive           bool               `json:"isActive"`
+	LinkedAt           pgtype.Timestamptz `json:"linkedAt"`
+	Headcount          int64              `json:"headcount"`
+}
+
+// MatrixCell is one business unit / department pairing in the site matrix
+type MatrixCell struct {
+	BusinessUnitID   pgtype.UUID `json:"businessUnitId"`
+	BusinessUnitCode *string     `json:"businessUnitCode"`
+	BusinessUnitName string      `json:"businessUnitName"`
+	DepartmentID     pgtype.UUID `json:"departmentId"`
+	DepartmentCode   *string     `json:"departmentCode"`
+	DepartmentName   string      `json:"departmentName"`
+	Headcount        int64       `json:"headcount"`
+}
+
+// ListSiteDepartments returns the departments that operate at a business unit with their site headcounts
+func (s *BusinessUnitService) ListSiteDepartments(ctx context.Context, tenantID, businessUnitID pgtype.UUID) ([]SiteDepartment, error) {
+	if _, err := s.GetBusinessUnit(ctx, tenantID, businessUnitID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListBusinessUnitDepartments(ctx, domain.ListBusinessUnitDepartmentsParams{
+		TenantID:       tenantID,
+		BusinessUnitID: businessUnitID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]SiteDepartment, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, SiteDepartment{
+			ID:                 row.ID,
+			ParentDepartmentID: row.ParentDepartmentID,
+			Code:               textPtr(row.Code),
+			Name:               row.Name,
+			IsActive:           row.IsActive,
+			LinkedAt:           row.LinkedAt,
+			Headcount:          row.Headcount,
+		})
+	}
+	return out, nil
+}
+
+// GetDepartmentMatrix lists every business unit / department pairing in the tenant
+func (s *BusinessUnitService) GetDepartmentMatrix(ctx context.Context, tenantID pgtype.UUID) ([]MatrixCell, error) {
+	rows, err := s.queries.ListBusinessUnitDepartmentMatrix(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]MatrixCell, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, MatrixCell{
+			BusinessUnitID:   row.BusinessUnitID,
+			BusinessUnitCode: textPtr(row.BusinessUnitCode),
+			BusinessUnitName: row.BusinessUnitName,
+			DepartmentID:     row.DepartmentID,
+			DepartmentCode:   textPtr(row.DepartmentCode),
+			DepartmentName:   row.DepartmentName,
+			Headcount:        row.Headcount,
+		})
+	}
+	return out, nil
+}
+
+// LinkDepartment records that a department operates at a business unit. Both must belong to the tenant;
+// a missing one is reported as pgx.ErrNoRows.
+func (s *BusinessUnitService) LinkDepartment(ctx context.Context, tenantID, actorID, businessUnitID, departmentID pgtype.UUID) (domain.BusinessUnitDepartment, error) {
+	if _, err := s.GetBusinessUnit(ctx, tenantID, businessUnitID); err != nil {
+		return domain.BusinessUnitDepartment{}, err
+	}
+	if _, err := s.queries.GetDepartment(ctx, domain.GetDepartmentParams{
+		TenantID: tenantID,
+		ID:       departmentID,
+	}); err != nil {
+		return domain.BusinessUnitDepartment{}, err
+	}
+
+	link, err := s.queries.LinkBusinessUnitDepartment(ctx, domain.LinkBusinessUnitDepartmentParams{
+		TenantID:        tenantID,
+		BusinessUnitID:  businessUnitID,
+		DepartmentID:    departmentID,
+		CreatedByUserID: actorID,
+	})
+	if err != nil {
+		return domain.BusinessUnitDepartment{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "BusinessUnitDepartments", businessUnitID.Bytes, map[string]interface{}{
+			"department_id": departmentID,
+		})
+	}
+
+	return link, nil
+}
+
+// UnlinkDepartment removes a department from a business unit. It is refused while any employee,
+// active or not, is still placed in that department at that site.
+func (s *BusinessUnitService) UnlinkDepartment(ctx context.Context, tenantID, actorID, businessUnitID, departmentID pgtype.UUID) error {
+	placed, err := s.queries.CountEmployeesAtBusinessUnitDepartment(ctx, domain.CountEmployeesAtBusinessUnitDepartmentParams{
+		TenantID:       tenantID,
+		BusinessUnitID: businessUnitID,
+		DepartmentID:   departmentID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count placed employees: %w", err)
+	}
+	if placed > 0 {
+		return fmt.Errorf("%w (%d employee(s))", ErrDepartmentInUse, placed)
+	}
+
+	removed, err := s.queries.UnlinkBusinessUnitDepartment(ctx, domain.UnlinkBusinessUnitDepartmentParams{
+		TenantID:       tenantID,
+		BusinessUnitID: businessUnitID,
+		DepartmentID:   departmentID,
+	})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "BusinessUnitDepartments", businessUnitID.Bytes, map[string]interface{}{
+			"department_id": departmentID,
+		})
+	}
+
+	return nil
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
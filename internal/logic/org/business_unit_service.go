@@ -6,16 +6,19 @@ import (
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type BusinessUnitService struct {
-	queries *domain.Queries
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
 }
 
-func NewBusinessUnitService(database *db.DB) *BusinessUnitService {
+func NewBusinessUnitService(database *db.DB, auditSvc *audit.AuditService) *BusinessUnitService {
 	return &BusinessUnitService{
-		queries: domain.New(database.Pool),
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
 	}
 }
 
@@ -38,20 +41,22 @@ func (s *BusinessUnitService) CreateBusinessUnit(ctx context.Context, id, tenant
 	})
 }
 
-func (s *BusinessUnitService) ListBusinessUnits(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.BusinessUnit, int64, error) {
+func (s *BusinessUnitService) ListBusinessUnits(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, customFields []byte) ([]domain.BusinessUnit, int64, error) {
 	bus, err := s.queries.ListBusinessUnits(ctx, domain.ListBusinessUnitsParams{
-		TenantID: tenantID,
-		Search:   params.Search,
-		Limit:    params.Limit(),
-		Offset:   params.Offset(),
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
+		Limit:        params.Limit(),
+		Offset:       params.Offset(),
 	})
 	if err != nil {
 		return nil, 0, err
 	}
 
 	total, err := s.queries.CountBusinessUnits(ctx, domain.CountBusinessUnitsParams{
-		TenantID: tenantID,
-		Search:   params.Search,
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -6,16 +6,19 @@ import (
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type DepartmentService struct {
-	queries *domain.Queries
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
 }
 
-func NewDepartmentService(database *db.DB) *DepartmentService {
+func NewDepartmentService(database *db.DB, auditSvc *audit.AuditService) *DepartmentService {
 	return &DepartmentService{
-		queries: domain.New(database.Pool),
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
 	}
 }
 
@@ -31,29 +34,32 @@ func (s *DepartmentService) CreateDepartment(ctx context.Context, id, tenantID p
 		pgParentID = *parentID
 	}
 
-	return s.queries.CreateDepartment(ctx, domain.CreateDepartmentParams{
+	dept, err := s.queries.CreateDepartment(ctx, domain.CreateDepartmentParams{
 		ID:                 id,
 		TenantID:           tenantID,
 		ParentDepartmentID: pgParentID,
 		Code:               pgCode,
 		Name:               name,
 	})
+	return dept, mapParentConstraintError(err)
 }
 
-func (s *DepartmentService) ListDepartments(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.Department, int64, error) {
+func (s *DepartmentService) ListDepartments(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, customFields []byte) ([]domain.Department, int64, error) {
 	deps, err := s.queries.ListDepartments(ctx, domain.ListDepartmentsParams{
-		TenantID: tenantID,
-		Search:   params.Search,
-		Limit:    params.Limit(),
-		Offset:   params.Offset(),
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
+		Limit:        params.Limit(),
+		Offset:       params.Offset(),
 	})
 	if err != nil {
 		return nil, 0, err
 	}
 
 	total, err := s.queries.CountDepartments(ctx, domain.CountDepartmentsParams{
-		TenantID: tenantID,
-		Search:   params.Search,
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
 	})
 	if err != nil {
 		return nil, 0, err
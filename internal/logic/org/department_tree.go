@@ -0,0 +1,338 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrSelfParent is returned when a department is designated as its own parent
+	ErrSelfParent = errors.New("a department cannot be its own parent")
+
+	// ErrParentNotFound is returned when the designated parent does not exist in the tenant
+	ErrParentNotFound = errors.New("parent department does not exist or is inaccessible")
+
+	// ErrDepartmentCycle is returned when a move would place a department beneath itself
+	ErrDepartmentCycle = errors.New("department move would create a hierarchy cycle")
+)
+
+// DepartmentNode is a department placed in the tenant's department hierarchy.
+// Headcount counts active employees assigned directly to the department, while
+// TotalHeadcount also includes every department below it.
+type DepartmentNode struct {
+	ID                 pgtype.UUID       `json:"id"`
+	ParentDepartmentID pgtype.UUID       `json:"parentDepartmentId"`
+	Code               *string           `json:"code"`
+	Name               string            `json:"name"`
+	IsActive           bool              `json:"isActive"`
+	Depth              int               `json:"depth"`
+	Headcount          int64             `json:"headcount"`
+	TotalHeadcount     int64             `json:"totalHeadcount"`
+	ChildCount         int               `json:"childCount"`
+	Children           []*DepartmentNode `json:"children,omitempty"`
+}
+
+// GetDepartmentTree returns the tenant's department hierarchy as nested roots with
+// headcounts rolled up from sub-departments. Inactive departments are left out unless
+// includeInactive is set; their active children are then promoted to the nearest
+// included ancestor rather than dropped.
+func (s *DepartmentService) GetDepartmentTree(ctx context.Context, tenantID pgtype.UUID, includeInactive bool) ([]*DepartmentNode, error) {
+	g, err := s.loadDepartmentGraph(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := g.roots()
+	out := make([]*DepartmentNode, 0, len(roots))
+	for _, root := range roots {
+		out = append(out, g.build(root, 0, includeInactive, map[[16]byte]bool{})...)
+	}
+	return out, nil
+}
+
+// ListChildren returns the immediate sub-departments of a department with their rolled-up headcounts
+func (s *DepartmentService) ListChildren(ctx context.Context, tenantID, id pgtype.UUID) ([]*DepartmentNode, error) {
+	g, err := s.loadDepartmentGraph(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, ok := g.byID[id.Bytes]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	children := make([]*DepartmentNode, 0, len(g.children[parent.ID.Bytes]))
+	for _, child := range g.children[parent.ID.Bytes] {
+		node := *child
+		node.Depth = 1
+		node.TotalHeadcount = g.totalHeadcount(child)
+		node.ChildCount = len(g.children[child.ID.Bytes])
+		children = append(children, &node)
+	}
+	return children, nil
+}
+
+// GetAncestors returns the departments above a department, nearest parent first.
+// Depth on each ancestor is the number of levels above the requested department.
+func (s *DepartmentService) GetAncestors(ctx context.Context, tenantID, id pgtype.UUID) ([]*DepartmentNode, error) {
+	g, err := s.loadDepartmentGraph(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := g.byID[id.Bytes]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	ancestors := []*DepartmentNode{}
+	seen := map[[16]byte]bool{node.ID.Bytes: true}
+	for parent := g.parent(node); parent != nil && !seen[parent.ID.Bytes]; parent = g.parent(parent) {
+		seen[parent.ID.Bytes] = true
+
+		ancestor := *parent
+		ancestor.Depth = len(ancestors) + 1
+		ancestor.TotalHeadcount = g.totalHeadcount(parent)
+		ancestor.ChildCount = len(g.children[parent.ID.Bytes])
+		ancestors = append(ancestors, &ancestor)
+	}
+	return ancestors, nil
+}
+
+// GetDepartmentNode returns a single department with its direct and rolled-up headcount
+func (s *DepartmentService) GetDepartmentNode(ctx context.Context, tenantID, id pgtype.UUID) (*DepartmentNode, error) {
+	g, err := s.loadDepartmentGraph(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	dept, ok := g.byID[id.Bytes]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	node := *dept
+	node.TotalHeadcount = g.totalHeadcount(dept)
+	node.ChildCount = len(g.children[dept.ID.Bytes])
+	return &node, nil
+}
+
+// MoveDepartment re-parents a department together with its whole subtree, or makes it a
+// root when parentID is not valid. The new parent must belong to the tenant and must not
+// sit inside the subtree being moved. A database trigger enforces the same rules for
+// concurrent writes.
+func (s *DepartmentService) MoveDepartment(ctx context.Context, tenantID, actorID, id, parentID pgtype.UUID) (domain.Department, error) {
+	current, err := s.queries.GetDepartment(ctx, domain.GetDepartmentParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.Department{}, err
+	}
+
+	if parentID.Valid {
+		if parentID == id {
+			return domain.Department{}, ErrSelfParent
+		}
+
+		g, err := s.loadDepartmentGraph(ctx, tenantID)
+		if err != nil {
+			return domain.Department{}, err
+		}
+		parent, ok := g.byID[parentID.Bytes]
+		if !ok {
+			return domain.Department{}, ErrParentNotFound
+		}
+
+		// The department must not appear anywhere above its proposed parent
+		seen := map[[16]byte]bool{}
+		for node := parent; node != nil && !seen[node.ID.Bytes]; node = g.parent(node) {
+			if node.ID == id {
+				return domain.Department{}, ErrDepartmentCycle
+			}
+			seen[node.ID.Bytes] = true
+		}
+	}
+
+	dept, err := s.queries.UpdateDepartmentParent(ctx, domain.UpdateDepartmentParentParams{
+		TenantID:           tenantID,
+		ID:                 id,
+		ParentDepartmentID: parentID,
+	})
+	if err != nil {
+		return domain.Department{}, mapParentConstraintError(err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "Departments", id.Bytes, map[string]interface{}{
+			"parent_department_id": map[string]interface{}{
+				"from": current.ParentDepartmentID,
+				"to":   parentID,
+			},
+		})
+	}
+
+	return dept, nil
+}
+
+func mapParentConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "departments_parent_no_cycle":
+		return ErrDepartmentCycle
+	case "departments_parent_same_tenant":
+		return ErrParentNotFound
+	}
+	return err
+}
+
+// departmentGraph is an in-memory copy of a tenant's department hierarchy.
+// Every walk over it tracks visited nodes so that legacy cycles cannot loop forever.
+type departmentGraph struct {
+	nodes    []*DepartmentNode
+	byID     map[[16]byte]*DepartmentNode
+	children map[[16]byte][]*DepartmentNode
+}
+
+func (s *DepartmentService) loadDepartmentGraph(ctx context.Context, tenantID pgtype.UUID) (*departmentGraph, error) {
+	depts, err := s.queries.ListAllDepartments(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+	counts, err := s.queries.CountActiveEmployeesByDepartment(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count department headcount: %w", err)
+	}
+
+	headcount := make(map[[16]byte]int64, len(counts))
+	for _, c := range counts {
+		headcount[c.DepartmentID.Bytes] = c.Headcount
+	}
+
+	g := &departmentGraph{
+		nodes:    make([]*DepartmentNode, 0, len(depts)),
+		byID:     make(map[[16]byte]*DepartmentNode, len(depts)),
+		children: make(map[[16]byte][]*DepartmentNode),
+	}
+	for _, d := range depts {
+		node := &DepartmentNode{
+			ID:                 d.ID,
+			ParentDepartmentID: d.ParentDepartmentID,
+			Code:               textPtr(d.Code),
+			Name:               d.Name,
+			IsActive:           d.IsActive,
+			Headcount:          headcount[d.ID.Bytes],
+		}
+		g.nodes = append(g.nodes, node)
+		g.byID[d.ID.Bytes] = node
+	}
+	for _, node := range g.nodes {
+		if parent := g.parent(node); parent != nil {
+			g.children[parent.ID.Bytes] = append(g.children[parent.ID.Bytes], node)
+		}
+	}
+	for _, children := range g.children {
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	}
+	return g, nil
+}
+
+func (g *departmentGraph) parent(node *DepartmentNode) *DepartmentNode {
+	if !node.ParentDepartmentID.Valid {
+		return nil
+	}
+	return g.byID[node.ParentDepartmentID.Bytes]
+}
+
+// roots returns departments without a resolvable parent, plus one member of any
+// cycle that would otherwise be unreachable from a root
+func (g *departmentGraph) roots() []*DepartmentNode {
+	var roots []*DepartmentNode
+	reached := map[[16]byte]bool{}
+
+	var mark func(node *DepartmentNode)
+	mark = func(node *DepartmentNode) {
+		if reached[node.ID.Bytes] {
+			return
+		}
+		reached[node.ID.Bytes] = true
+		for _, child := range g.children[node.ID.Bytes] {
+			mark(child)
+		}
+	}
+
+	for _, node := range g.nodes {
+		if g.parent(node) == nil {
+			roots = append(roots, node)
+			mark(node)
+		}
+	}
+	for _, node := range g.nodes {
+		if !reached[node.ID.Bytes] {
+			roots = append(roots, node)
+			mark(node)
+		}
+	}
+	return roots
+}
+
+// totalHeadcount sums the active headcount of a department and everything below it
+func (g *departmentGraph) totalHeadcount(node *DepartmentNode) int64 {
+	var total int64
+	seen := map[[16]byte]bool{}
+	stack := []*DepartmentNode{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[n.ID.Bytes] {
+			continue
+		}
+		seen[n.ID.Bytes] = true
+		total += n.Headcount
+		stack = append(stack, g.children[n.ID.Bytes]...)
+	}
+	return total
+}
+
+// build copies the subtree under node. When node is excluded (inactive and includeInactive
+// is false) its children are returned in its place at the same depth.
+func (g *departmentGraph) build(node *DepartmentNode, depth int, includeInactive bool, seen map[[16]byte]bool) []*DepartmentNode {
+	if seen[node.ID.Bytes] {
+		return nil
+	}
+	seen[node.ID.Bytes] = true
+
+	var children []*DepartmentNode
+	childDepth := depth + 1
+	if !node.IsActive && !includeInactive {
+		childDepth = depth
+	}
+	for _, child := range g.children[node.ID.Bytes] {
+		children = append(children, g.build(child, childDepth, includeInactive, seen)...)
+	}
+
+	if !node.IsActive && !includeInactive {
+		return children
+	}
+
+	out := *node
+	out.Depth = depth
+	out.Children = children
+	out.ChildCount = len(children)
+	out.TotalHeadcount = out.Headcount
+	for _, child := range children {
+		out.TotalHeadcount += child.TotalHeadcount
+	}
+	return []*DepartmentNode{&out}
+}
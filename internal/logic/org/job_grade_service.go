@@ -0,0 +1,129 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInvalidPayBand is returned when a grade's pay band is not ordered min <= mid <= max
+var ErrInvalidPayBand = errors.New("pay band must satisfy min <= mid <= max")
+
+// PayBand is the optional min/mid/max pay for a grade, in whole currency units
+type PayBand struct {
+	Currency string
+	Min      *float64
+	Mid      *float64
+	Max      *float64
+}
+
+func (b PayBand) validate() error {
+	bounds := []*float64{b.Min, b.Mid, b.Max}
+	var prev *float64
+	for _, v := range bounds {
+		if v == nil {
+			continue
+		}
+		if *v < 0 || (prev != nil && *prev > *v) {
+			return ErrInvalidPayBand
+		}
+		prev = v
+	}
+	return nil
+}
+
+type JobGradeService struct {
+	queries *domain.Queries
+}
+
+func NewJobGradeService(database *db.DB) *JobGradeService {
+	return &JobGradeService{
+		queries: domain.New(database.Pool),
+	}
+}
+
+func (s *JobGradeService) CreateJobGrade(ctx context.Context, id, tenantID pgtype.UUID, code, name string, level int32, band PayBand) (domain.JobGrade, error) {
+	if err := band.validate(); err != nil {
+		return domain.JobGrade{}, err
+	}
+
+	return s.queries.CreateJobGrade(ctx, domain.CreateJobGradeParams{
+		ID:       id,
+		TenantID: tenantID,
+		Code:     code,
+		Name:     name,
+		Level:    level,
+		Currency: currencyOrDefault(band.Currency),
+		MinPay:   numeric(band.Min),
+		MidPay:   numeric(band.Mid),
+		MaxPay:   numeric(band.Max),
+	})
+}
+
+func (s *JobGradeService) UpdateJobGrade(ctx context.Context, tenantID, id pgtype.UUID, code, name string, level int32, band PayBand, isActive bool) (domain.JobGrade, error) {
+	if err := band.validate(); err != nil {
+		return domain.JobGrade{}, err
+	}
+
+	return s.queries.UpdateJobGrade(ctx, domain.UpdateJobGradeParams{
+		TenantID: tenantID,
+		ID:       id,
+		Code:     code,
+		Name:     name,
+		Level:    level,
+		Currency: currencyOrDefault(band.Currency),
+		MinPay:   numeric(band.Min),
+		MidPay:   numeric(band.Mid),
+		MaxPay:   numeric(band.Max),
+		IsActive: isActive,
+	})
+}
+
+func (s *JobGradeService) ListJobGrades(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.JobGrade, int64, error) {
+	grades, err := s.queries.ListJobGrades(ctx, domain.ListJobGradesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountJobGrades(ctx, domain.CountJobGradesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return grades, total, nil
+}
+
+func (s *JobGradeService) GetJobGrade(ctx context.Context, tenantID, id pgtype.UUID) (domain.JobGrade, error) {
+	return s.queries.GetJobGrade(ctx, domain.GetJobGradeParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return "GBP"
+	}
+	return currency
+}
+
+func numeric(v *float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	if v != nil {
+		_ = n.Scan(strconv.FormatFloat(*v, 'f', 2, 64))
+	}
+	return n
+}
@@ -2,13 +2,23 @@ package org
 
 import (
 	"context"
+	"errors"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
 	"github.com/INOVA/DML/internal/http/query"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+var (
+	// ErrGradeNotFound is returned when a job grade does not exist in the tenant
+	ErrGradeNotFound = errors.New("job grade does not exist or is inaccessible")
+
+	// ErrDepartmentNotFound is returned when a default department does not exist in the tenant
+	ErrDepartmentNotFound = errors.New("department does not exist or is inaccessible")
+)
+
 type JobTitleService struct {
 	queries *domain.Queries
 }
@@ -19,42 +29,106 @@ func NewJobTitleService(database *db.DB) *JobTitleService {
 	}
 }
 
-func (s *JobTitleService) CreateJobTitle(ctx context.Context, id, tenantID pgtype.UUID, code, name, grade string) (domain.JobTitle, error) {
+func (s *JobTitleService) CreateJobTitle(ctx context.Context, id, tenantID pgtype.UUID, code, name string, gradeID, deptID pgtype.UUID) (domain.JobTitle, error) {
 	var pgCode pgtype.Text
 	if code != "" {
 		pgCode.String = code
 		pgCode.Valid = true
 	}
 
-	var pgGrade pgtype.Text
-	if grade != "" {
-		pgGrade.String = grade
-		pgGrade.Valid = true
+	if err := s.checkReferences(ctx, tenantID, gradeID, deptID); err != nil {
+		return domain.JobTitle{}, err
 	}
 
 	return s.queries.CreateJobTitle(ctx, domain.CreateJobTitleParams{
-		ID:       id,
+		ID:                  id,
+		TenantID:            tenantID,
+		Code:                pgCode,
+		Name:                name,
+		GradeID:             gradeID,
+		DefaultDepartmentID: deptID,
+	})
+}
+
+// UpdateJobTitle replaces a job title's code, name, grade, default department and active flag
+func (s *JobTitleService) UpdateJobTitle(ctx context.Context, tenantID, id pgtype.UUID, code, name string, gradeID, deptID pgtype.UUID, isActive bool) (domain.JobTitle, error) {
+	var pgCode pgtype.Text
+	if code != "" {
+		pgCode.String = code
+		pgCode.Valid = true
+	}
+
+	if err := s.checkReferences(ctx, tenantID, gradeID, deptID); err != nil {
+		return domain.JobTitle{}, err
+	}
+
+	return s.queries.UpdateJobTitle(ctx, domain.UpdateJobTitleParams{
+		TenantID:            tenantID,
+		ID:                  id,
+		Code:                pgCode,
+		Name:                name,
+		GradeID:             gradeID,
+		DefaultDepartmentID: deptID,
+		IsActive:            isActive,
+	})
+}
+
+// ResolveGradeCode looks up a grade by its code, for clients still sending the legacy free-text grade
+func (s *JobTitleService) ResolveGradeCode(ctx context.Context, tenantID pgtype.UUID, code string) (pgtype.UUID, error) {
+	grade, err := s.queries.GetJobGradeByCode(ctx, domain.GetJobGradeByCodeParams{
 		TenantID: tenantID,
-		Code:     pgCode,
-		Name:     name,
-		Grade:    pgGrade,
+		Code:     code,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, ErrGradeNotFound
+	}
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return grade.ID, nil
 }
 
-func (s *JobTitleService) ListJobTitles(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.JobTitle, int64, error) {
+// checkReferences makes sure an optional grade and default department belong to the tenant
+func (s *JobTitleService) checkReferences(ctx context.Context, tenantID, gradeID, deptID pgtype.UUID) error {
+	if gradeID.Valid {
+		if _, err := s.queries.GetJobGrade(ctx, domain.GetJobGradeParams{
+			TenantID: tenantID,
+			ID:       gradeID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return ErrGradeNotFound
+		} else if err != nil {
+			return err
+		}
+	}
+	if deptID.Valid {
+		if _, err := s.queries.GetDepartment(ctx, domain.GetDepartmentParams{
+			TenantID: tenantID,
+			ID:       deptID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return ErrDepartmentNotFound
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *JobTitleService) ListJobTitles(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, customFields []byte) ([]domain.JobTitle, int64, error) {
 	titles, err := s.queries.ListJobTitles(ctx, domain.ListJobTitlesParams{
-		TenantID: tenantID,
-		Search:   params.Search,
-		Limit:    params.Limit(),
-		Offset:   params.Offset(),
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
+		Limit:        params.Limit(),
+		Offset:       params.Offset(),
 	})
 	if err != nil {
 		return nil, 0, err
 	}
 
 	total, err := s.queries.CountJobTitles(ctx, domain.CountJobTitlesParams{
-		TenantID: tenantID,
-		Search:   params.Search,
+		TenantID:     tenantID,
+		Search:       params.Search,
+		CustomFields: customFields,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -0,0 +1,308 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// changeRequestBatchSize is the page size used while reading an employee's change requests
+const changeRequestBatchSize = 500
+
+// Employee is the employee record with its organisation placement. CustomFields shadows
+// the embedded column, which would otherwise be encoded as base64.
+type Employee struct {
+	domain.GetEmployeeWithDetailsRow
+	CustomFields json.RawMessage `json:"custom_fields"`
+}
+
+// Account is the employee's user account without its password hash
+type Account struct {
+	ID          pgtype.UUID        `json:"id"`
+	Email       string             `json:"email"`
+	DisplayName pgtype.Text        `json:"display_name"`
+	IsActive    bool               `json:"is_active"`
+	Locale      string             `json:"locale"`
+	ExternalID  pgtype.Text        `json:"external_id"`
+	AvatarUrl   pgtype.Text        `json:"avatar_url"`
+	Preferences json.RawMessage    `json:"preferences"`
+	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// AuditEntry is an audit log entry with its changes as JSON
+type AuditEntry struct {
+	domain.AuditLog
+	Changes json.RawMessage `json:"changes"`
+}
+
+// ChangeRequest is a change the employee proposed to their record
+type ChangeRequest struct {
+	domain.EmployeeChangeRequest
+	Changes json.RawMessage `json:"changes"`
+}
+
+// SubjectData is everything held about one employee, as compiled for a subject access
+// request. Account and RoleGrants are empty for employees without a user account.
+type SubjectData struct {
+	GeneratedAt     time.Time
+	Employee        Employee
+	PersonalDetails hr.PersonalDetails
+	Account         *Account
+	RoleGrants      []domain.ListUserRoleGrantsRow
+	Competencies    []domain.ListEmployeeCompetenciesRow
+	TrainingRecords []domain.ListEmployeeTrainingRecordsRow
+	ChangeRequests  []ChangeRequest
+	// AuditAbout holds the entries about the employee, their account and their records
+	AuditAbout []AuditEntry
+	// AuditActions holds the entries for what the employee did, or did while impersonating
+	AuditActions []AuditEntry
+
+	evidence []domain.ListEmployeeTrainingEvidenceRow
+}
+
+// FileName is the download name of the archive, e.g. dsar-UK-00001-20261018.zip
+func (d *SubjectData) FileName() string {
+	return fmt.Sprintf("dsar-%s-%s.zip", d.Employee.EmployeeNo, d.GeneratedAt.Format("20060102"))
+}
+
+// CollectSubjectData compiles everything held about an employee on behalf of actorID. The
+// records are read from one snapshot; personal details are decrypted afterwards, which
+// audits the read. The export itself is audited as EXPORT on the employee.
+func (s *PrivacyService) CollectSubjectData(ctx context.Context, tenantID, actorID, employeeID pgtype.UUID) (*SubjectData, error) {
+	tx, err := s.db.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start subject access snapshot: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	emp, err := qtx.GetEmployeeWithDetails(ctx, domain.GetEmployeeWithDetailsParams{TenantID: tenantID, ID: employeeID})
+	if err != nil {
+		return nil, err
+	}
+	data := &SubjectData{
+		GeneratedAt:     time.Now().UTC(),
+		Employee:        Employee{GetEmployeeWithDetailsRow: emp, CustomFields: rawJSON(emp.CustomFields)},
+		RoleGrants:      []domain.ListUserRoleGrantsRow{},
+		Competencies:    []domain.ListEmployeeCompetenciesRow{},
+		TrainingRecords: []domain.ListEmployeeTrainingRecordsRow{},
+		ChangeRequests:  []ChangeRequest{},
+		AuditActions:    []AuditEntry{},
+	}
+
+	var userID pgtype.UUID
+	user, err := qtx.GetUserByEmployee(ctx, domain.GetUserByEmployeeParams{TenantID: tenantID, EmployeeID: employeeID})
+	switch {
+	case err == nil:
+		userID = user.ID
+		data.Account = toAccount(user)
+		grants, err := qtx.ListUserRoleGrants(ctx, domain.ListUserRoleGrantsParams{TenantID: tenantID, UserID: user.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read role grants: %w", err)
+		}
+		data.RoleGrants = append(data.RoleGrants, grants...)
+		actions, err := qtx.ListUserActorAuditLogs(ctx, domain.ListUserActorAuditLogsParams{TenantID: tenantID, UserID: user.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit entries by the employee: %w", err)
+		}
+		data.AuditActions = toAuditEntries(actions)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to read user account: %w", err)
+	}
+
+	about, err := qtx.ListEmployeeSubjectAuditLogs(ctx, domain.ListEmployeeSubjectAuditLogsParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit entries about the employee: %w", err)
+	}
+	data.AuditAbout = toAuditEntries(about)
+
+	competencies, err := qtx.ListEmployeeCompetencies(ctx, domain.ListEmployeeCompetenciesParams{TenantID: tenantID, EmployeeID: employeeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read competencies: %w", err)
+	}
+	data.Competencies = append(data.Competencies, competencies...)
+
+	records, err := qtx.ListEmployeeTrainingRecords(ctx, domain.ListEmployeeTrainingRecordsParams{TenantID: tenantID, EmployeeID: employeeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read training records: %w", err)
+	}
+	data.TrainingRecords = append(data.TrainingRecords, records...)
+
+	if data.evidence, err = qtx.ListEmployeeTrainingEvidence(ctx, domain.ListEmployeeTrainingEvidenceParams{TenantID: tenantID, EmployeeID: employeeID}); err != nil {
+		return nil, fmt.Errorf("failed to read training evidence: %w", err)
+	}
+
+	for offset := int32(0); ; offset += changeRequestBatchSize {
+		rows, err := qtx.ListEmployeeChangeRequests(ctx, domain.ListEmployeeChangeRequestsParams{
+			TenantID:   tenantID,
+			EmployeeID: employeeID,
+			Limit:      changeRequestBatchSize,
+			Offset:     offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read change requests: %w", err)
+		}
+		for _, r := range rows {
+			data.ChangeRequests = append(data.ChangeRequests, ChangeRequest{EmployeeChangeRequest: r, Changes: rawJSON(r.Changes)})
+		}
+		if len(rows) < changeRequestBatchSize {
+			break
+		}
+	}
+
+	if data.PersonalDetails, err = s.personalDetails.GetPersonalDetails(ctx, tenantID, actorID, employeeID); err != nil {
+		return nil, fmt.Errorf("failed to read personal details: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "EXPORT", employeeEntity, employeeID.Bytes, map[string]interface{}{
+			"training_evidence": len(data.evidence),
+			"audit_entries":     len(data.AuditAbout) + len(data.AuditActions),
+		})
+	}
+	return data, nil
+}
+
+// archiveFile describes one file of the archive in its manifest
+type archiveFile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// WriteArchive writes the subject data as a zip of JSON files, with the training evidence
+// files alongside. Evidence missing from storage is listed in the manifest instead.
+func (s *PrivacyService) WriteArchive(ctx context.Context, w io.Writer, data *SubjectData) error {
+	zw := zip.NewWriter(w)
+
+	manifest := struct {
+		GeneratedAt     time.Time     `json:"generated_at"`
+		EmployeeID      pgtype.UUID   `json:"employee_id"`
+		EmployeeNo      string        `json:"employee_no"`
+		Files           []archiveFile `json:"files"`
+		MissingEvidence []pgtype.UUID `json:"missing_evidence,omitempty"`
+	}{
+		GeneratedAt: data.GeneratedAt,
+		EmployeeID:  data.Employee.ID,
+		EmployeeNo:  data.Employee.EmployeeNo,
+	}
+
+	files := []struct {
+		archiveFile
+		value interface{}
+	}{
+		{archiveFile{"employee.json", "Employee record, organisation placement and custom fields"}, data.Employee},
+		{archiveFile{"personal-details.json", "Personal email, phone, address, date of birth, emergency contacts and National Insurance number"}, data.PersonalDetails},
+		{archiveFile{"account.json", "User account; null when the employee has none"}, data.Account},
+		{archiveFile{"role-grants.json", "Roles granted to the user account, with their scope"}, data.RoleGrants},
+		{archiveFile{"competencies.json", "Competencies recorded for the employee"}, data.Competencies},
+		{archiveFile{"training-records.json", "Training completions"}, data.TrainingRecords},
+		{archiveFile{"change-requests.json", "Changes the employee proposed to their record"}, data.ChangeRequests},
+		{archiveFile{"audit-log/about-employee.json", "Audit entries about the employee, their account and their records"}, data.AuditAbout},
+		{archiveFile{"audit-log/actions.json", "Audit entries for changes the employee made"}, data.AuditActions},
+	}
+	for _, f := range files {
+		if err := writeJSON(zw, f.Name, f.value); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, f.archiveFile)
+	}
+
+	for _, ev := range data.evidence {
+		name := path.Join("training-evidence", uuid.UUID(ev.ID.Bytes).String(), path.Base(ev.EvidenceFileName.String))
+		err := s.copyEvidence(ctx, zw, name, ev.EvidenceKey.String)
+		if errors.Is(err, storage.ErrNotFound) {
+			manifest.MissingEvidence = append(manifest.MissingEvidence, ev.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, archiveFile{name, "Evidence attached to training record " + uuid.UUID(ev.ID.Bytes).String()})
+	}
+
+	if err := writeJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *PrivacyService) copyEvidence(ctx context.Context, zw *zip.Writer, name, key string) error {
+	rc, err := s.store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		return fmt.Errorf("failed to copy training evidence: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func toAccount(u domain.User) *Account {
+	return &Account{
+		ID:          u.ID,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		IsActive:    u.IsActive,
+		Locale:      u.Locale,
+		ExternalID:  u.ExternalID,
+		AvatarUrl:   u.AvatarUrl,
+		Preferences: rawJSON(u.Preferences),
+		LastLoginAt: u.LastLoginAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+func toAuditEntries(logs []domain.AuditLog) []AuditEntry {
+	out := make([]AuditEntry, 0, len(logs))
+	for _, l := range logs {
+		out = append(out, AuditEntry{AuditLog: l, Changes: rawJSON(l.Changes)})
+	}
+	return out
+}
+
+// rawJSON passes a JSONB column through unchanged, with SQL NULL as JSON null
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(b)
+}
@@ -0,0 +1,317 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/customfields"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// erasedText replaces free text scrubbed from audit entries and change requests
+const erasedText = "[erased]"
+
+// keptAuditKeys are audit change keys whose values are state names or field names rather
+// than anything about the person, so they survive redaction
+var keptAuditKeys = map[string]bool{
+	"action": true,
+	"fields": true,
+	"result": true,
+	"source": true,
+	"status": true,
+}
+
+// Erasure reports what erasing an employee scrubbed. It holds counts only, so it is also
+// the change set of the ERASE audit entry.
+type Erasure struct {
+	EmployeeID                pgtype.UUID        `json:"employeeId"`
+	UserID                    pgtype.UUID        `json:"userId"`
+	ErasedAt                  pgtype.Timestamptz `json:"erasedAt"`
+	PersonalDetails           int64              `json:"personalDetails"`
+	CustomFieldValues         int64              `json:"customFieldValues"`
+	ChangeRequests            int64              `json:"changeRequests"`
+	ChangeRequestTasks        int64              `json:"changeRequestTasks"`
+	ApproverNotifications     int64              `json:"approverNotifications"`
+	TrainingRecords           int64              `json:"trainingRecords"`
+	TrainingEvidence          int                `json:"trainingEvidence"`
+	Competencies              int64              `json:"competencies"`
+	RoleGrants                int64              `json:"roleGrants"`
+	APIKeysRevoked            int64              `json:"apiKeysRevoked"`
+	SsoIdentities             int64              `json:"ssoIdentities"`
+	Notifications             int64              `json:"notifications"`
+	Emails                    int64              `json:"emails"`
+	AuditEntriesRedacted      int                `json:"auditEntriesRedacted"`
+	WebhookDeliveriesRedacted int64              `json:"webhookDeliveriesRedacted"`
+}
+
+// EraseEmployee pseudonymises an employee under the right to erasure. confirmEmployeeNo
+// must repeat the employee number, as the erasure cannot be undone.
+//
+// Rows are scrubbed rather than deleted: the employee keeps its ID and employee number
+// with placeholder names, and the user account keeps its ID with a placeholder email, so
+// the users to employees foreign key (ON DELETE RESTRICT), audit entries, training records,
+// NCRs and audits that reference them stay intact. Personal details, custom field values,
+// credentials, notifications and training evidence are deleted; free text in change
+// requests, notes, audit entries about the employee and their webhook payloads is
+// replaced. Approval tasks and approvers' notifications for the employee's change requests
+// are re-titled with the placeholder name, and the emails about them are deleted. Audit
+// entries keep who did what and when. Everything happens in one transaction; evidence
+// files are removed from storage once it commits.
+func (s *PrivacyService) EraseEmployee(ctx context.Context, tenantID, actorID, employeeID pgtype.UUID, confirmEmployeeNo string) (Erasure, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return Erasure{}, fmt.Errorf("failed to begin erasure transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	emp, err := qtx.GetEmployeeForUpdate(ctx, domain.GetEmployeeForUpdateParams{TenantID: tenantID, ID: employeeID})
+	if err != nil {
+		return Erasure{}, err
+	}
+	if emp.ErasedAt.Valid {
+		return Erasure{}, ErrAlreadyErased
+	}
+	if confirmEmployeeNo != emp.EmployeeNo {
+		return Erasure{}, ErrConfirmationMismatch
+	}
+
+	res := Erasure{EmployeeID: employeeID}
+
+	user, err := qtx.GetUserByEmployee(ctx, domain.GetUserByEmployeeParams{TenantID: tenantID, EmployeeID: employeeID})
+	switch {
+	case err == nil:
+		if user.ID == actorID {
+			return Erasure{}, ErrSelfErasure
+		}
+		res.UserID = user.ID
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Erasure{}, fmt.Errorf("failed to read user account: %w", err)
+	}
+
+	// Audit entries are found through the records they are about, so redact them first
+	about, err := qtx.ListEmployeeSubjectAuditLogs(ctx, domain.ListEmployeeSubjectAuditLogsParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+		UserID:     res.UserID,
+	})
+	if err != nil {
+		return Erasure{}, fmt.Errorf("failed to read audit entries about the employee: %w", err)
+	}
+	for _, entry := range about {
+		if len(entry.Changes) == 0 {
+			continue
+		}
+		redacted, err := redactChanges(entry.Changes)
+		if err != nil {
+			return Erasure{}, fmt.Errorf("failed to redact audit entry %s: %w", uuid.UUID(entry.ID.Bytes), err)
+		}
+		if err := qtx.SetAuditLogChanges(ctx, domain.SetAuditLogChangesParams{ID: entry.ID, Changes: redacted}); err != nil {
+			return Erasure{}, fmt.Errorf("failed to redact audit entry: %w", err)
+		}
+		res.AuditEntriesRedacted++
+
+		// Webhook payloads carry the entry's changes as their data
+		n, err := qtx.SetWebhookDeliveryData(ctx, domain.SetWebhookDeliveryDataParams{TenantID: tenantID, EventID: entry.ID, Data: redacted})
+		if err != nil {
+			return Erasure{}, fmt.Errorf("failed to redact webhook deliveries: %w", err)
+		}
+		res.WebhookDeliveriesRedacted += n
+	}
+
+	evidence, err := qtx.ListEmployeeTrainingEvidence(ctx, domain.ListEmployeeTrainingEvidenceParams{TenantID: tenantID, EmployeeID: employeeID})
+	if err != nil {
+		return Erasure{}, fmt.Errorf("failed to read training evidence: %w", err)
+	}
+	res.TrainingEvidence = len(evidence)
+
+	// Task titles name the employee as they were called when each request was made
+	taskTitles, err := qtx.ListEmployeeChangeRequestTaskTitles(ctx, domain.ListEmployeeChangeRequestTaskTitlesParams{
+		TenantID:   tenantID,
+		EntityType: hr.ChangeRequestEntity,
+		EmployeeID: employeeID,
+	})
+	if err != nil {
+		return Erasure{}, fmt.Errorf("failed to read change request tasks: %w", err)
+	}
+
+	erased, err := qtx.EraseEmployee(ctx, domain.EraseEmployeeParams{TenantID: tenantID, ID: employeeID, ErasedByUserID: actorID})
+	if err != nil {
+		return Erasure{}, fmt.Errorf("failed to erase employee: %w", err)
+	}
+	res.ErasedAt = erased.ErasedAt
+
+	if res.PersonalDetails, err = qtx.DeleteEmployeePersonalDetails(ctx, domain.DeleteEmployeePersonalDetailsParams{TenantID: tenantID, EmployeeID: employeeID}); err != nil {
+		return Erasure{}, fmt.Errorf("failed to delete personal details: %w", err)
+	}
+	if res.CustomFieldValues, err = qtx.DeleteCustomFieldValues(ctx, domain.DeleteCustomFieldValuesParams{
+		TenantID:   tenantID,
+		EntityType: customfields.EntityEmployee,
+		EntityID:   employeeID,
+	}); err != nil {
+		return Erasure{}, fmt.Errorf("failed to delete custom field values: %w", err)
+	}
+	if res.ChangeRequests, err = qtx.ScrubEmployeeChangeRequests(ctx, domain.ScrubEmployeeChangeRequestsParams{TenantID: tenantID, EmployeeID: employeeID}); err != nil {
+		return Erasure{}, fmt.Errorf("failed to scrub change requests: %w", err)
+	}
+	if err := s.scrubChangeRequestMessages(ctx, qtx, tenantID, actorID, erased, taskTitles, &res); err != nil {
+		return Erasure{}, err
+	}
+	if res.TrainingRecords, err = qtx.ScrubEmployeeTrainingRecords(ctx, domain.ScrubEmployeeTrainingRecordsParams{TenantID: tenantID, EmployeeID: employeeID}); err != nil {
+		return Erasure{}, fmt.Errorf("failed to scrub training records: %w", err)
+	}
+	if res.Competencies, err = qtx.ScrubEmployeeCompetencies(ctx, domain.ScrubEmployeeCompetenciesParams{TenantID: tenantID, EmployeeID: employeeID}); err != nil {
+		return Erasure{}, fmt.Errorf("failed to scrub competencies: %w", err)
+	}
+
+	if res.UserID.Valid {
+		if err := s.eraseUser(ctx, qtx, tenantID, res.UserID, &res); err != nil {
+			return Erasure{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Erasure{}, fmt.Errorf("failed to commit erasure transaction: %w", err)
+	}
+
+	// The records no longer point at the files, so a failure here only leaves orphans behind
+	for _, ev := range evidence {
+		if err := s.store.Delete(ctx, ev.EvidenceKey.String); err != nil {
+			log.Printf("erasure of employee %s failed to delete training evidence %s: %v", uuid.UUID(employeeID.Bytes), ev.EvidenceKey.String, err)
+		}
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "ERASE", employeeEntity, employeeID.Bytes, res)
+	}
+	return res, nil
+}
+
+// scrubChangeRequestMessages re-titles the approval tasks of the employee's change requests,
+// cancelling open ones as their requests are cancelled, and the approvers' notifications
+// about them. Emails cannot be re-rendered, so the ones about the requests are deleted,
+// including those queued before emails were linked to their record, found by task title.
+func (s *PrivacyService) scrubChangeRequestMessages(ctx context.Context, qtx *domain.Queries, tenantID, actorID pgtype.UUID, erased domain.Employee, taskTitles []string, res *Erasure) error {
+	title := hr.ChangeRequestTaskTitle(erased)
+	var err error
+	if res.ChangeRequestTasks, err = qtx.ScrubEmployeeChangeRequestTasks(ctx, domain.ScrubEmployeeChangeRequestTasksParams{
+		Title:      title,
+		ActorID:    actorID,
+		TenantID:   tenantID,
+		EntityType: hr.ChangeRequestEntity,
+		EmployeeID: erased.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to scrub change request tasks: %w", err)
+	}
+	if res.ApproverNotifications, err = qtx.ScrubEmployeeChangeRequestNotifications(ctx, domain.ScrubEmployeeChangeRequestNotificationsParams{
+		Title:      title,
+		TenantID:   tenantID,
+		EntityType: hr.ChangeRequestEntity,
+		EmployeeID: erased.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to scrub change request notifications: %w", err)
+	}
+
+	n, err := qtx.DeleteEmployeeChangeRequestEmails(ctx, domain.DeleteEmployeeChangeRequestEmailsParams{
+		TenantID:   tenantID,
+		EntityType: hr.ChangeRequestEntity,
+		EmployeeID: erased.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete change request emails: %w", err)
+	}
+	res.Emails += n
+	for _, t := range taskTitles {
+		if t == title {
+			continue
+		}
+		n, err := qtx.DeleteUnlinkedEmailsMentioning(ctx, domain.DeleteUnlinkedEmailsMentioningParams{TenantID: tenantID, Text: t})
+		if err != nil {
+			return fmt.Errorf("failed to delete change request emails: %w", err)
+		}
+		res.Emails += n
+	}
+	return nil
+}
+
+// eraseUser pseudonymises the employee's user account and removes its credentials, role
+// grants and inbox. API keys are revoked rather than deleted, as audit entries name them.
+func (s *PrivacyService) eraseUser(ctx context.Context, qtx *domain.Queries, tenantID, userID pgtype.UUID, res *Erasure) error {
+	var err error
+	if err = qtx.EraseUser(ctx, domain.EraseUserParams{
+		TenantID: tenantID,
+		ID:       userID,
+		Email:    fmt.Sprintf("erased-%s@erased.invalid", uuid.UUID(userID.Bytes)),
+	}); err != nil {
+		return fmt.Errorf("failed to erase user account: %w", err)
+	}
+	if res.RoleGrants, err = qtx.RevokeAllUserRoles(ctx, domain.RevokeAllUserRolesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to revoke role grants: %w", err)
+	}
+	if res.APIKeysRevoked, err = qtx.RevokeUserApiKeys(ctx, domain.RevokeUserApiKeysParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+	if res.SsoIdentities, err = qtx.DeleteUserSsoIdentities(ctx, domain.DeleteUserSsoIdentitiesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to delete SSO identities: %w", err)
+	}
+	if _, err = qtx.DeleteUserMfa(ctx, domain.DeleteUserMfaParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to delete MFA enrolment: %w", err)
+	}
+	if err = qtx.DeleteMfaRecoveryCodes(ctx, domain.DeleteMfaRecoveryCodesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to delete MFA recovery codes: %w", err)
+	}
+	if res.Notifications, err = qtx.DeleteUserNotifications(ctx, domain.DeleteUserNotificationsParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	if _, err = qtx.DeleteUserNotificationPreferences(ctx, domain.DeleteUserNotificationPreferencesParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+	emails, err := qtx.DeleteUserEmails(ctx, domain.DeleteUserEmailsParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete emails: %w", err)
+	}
+	res.Emails += emails
+	return nil
+}
+
+// redactChanges replaces the free text in an audit entry's changes. Keys, numbers,
+// booleans and IDs survive, so the entry still shows what changed and which records it
+// linked; the values under keptAuditKeys survive as well.
+func redactChanges(changes []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(changes, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redact(v))
+}
+
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			if !keptAuditKeys[k] {
+				t[k] = redact(e)
+			}
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = redact(e)
+		}
+		return t
+	case string:
+		if _, err := uuid.Parse(t); err == nil {
+			return t
+		}
+		return erasedText
+	}
+	return v
+}
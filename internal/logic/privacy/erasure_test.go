@@ -0,0 +1,78 @@
+package privacy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedactChanges(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{
+			"free text",
+			`{"first_name": "Ada", "reason": "Moved to 1 High Street"}`,
+			`{"first_name": "[erased]", "reason": "[erased]"}`,
+		},
+		{
+			"numbers, booleans and nulls",
+			`{"hours": 37.5, "is_active": false, "display_name": null}`,
+			`{"hours": 37.5, "is_active": false, "display_name": null}`,
+		},
+		{
+			"nested maps",
+			`{"changes": {"work_email": {"from": "ada@example.com", "to": "ada.l@example.com"}}}`,
+			`{"changes": {"work_email": {"from": "[erased]", "to": "[erased]"}}}`,
+		},
+		{
+			"arrays",
+			`{"licences": ["FLT", "MEWP"], "contacts": [{"name": "Byron", "phone": "07700 900123"}, 3]}`,
+			`{"licences": ["[erased]", "[erased]"], "contacts": [{"name": "[erased]", "phone": "[erased]"}, 3]}`,
+		},
+		{
+			"UUIDs",
+			`{"employee_id": "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e", "department_id": {"from": "8e2d6b1a-0f3c-4d5e-a6b7-c8d9e0f1a2b3", "to": "Quality"}}`,
+			`{"employee_id": "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e", "department_id": {"from": "8e2d6b1a-0f3c-4d5e-a6b7-c8d9e0f1a2b3", "to": "[erased]"}}`,
+		},
+		{
+			"kept keys",
+			`{"status": "APPROVED", "fields": ["first_name", "work_email"], "action": "reset", "result": "ok", "source": "scim", "comment": "Looks right"}`,
+			`{"status": "APPROVED", "fields": ["first_name", "work_email"], "action": "reset", "result": "ok", "source": "scim", "comment": "[erased]"}`,
+		},
+		{
+			"kept keys when nested",
+			`{"request": {"status": "REJECTED", "reason": "Wrong surname"}}`,
+			`{"request": {"status": "REJECTED", "reason": "[erased]"}}`,
+		},
+		{
+			"top-level array",
+			`["Ada", 1, "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e"]`,
+			`["[erased]", 1, "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e"]`,
+		},
+		{"empty object", `{}`, `{}`},
+	}
+	for _, tc := range cases {
+		out, err := redactChanges([]byte(tc.in))
+		if err != nil {
+			t.Errorf("%s: redactChanges() error = %v", tc.name, err)
+			continue
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("%s: redactChanges() returned invalid JSON %s", tc.name, out)
+		}
+		if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: redactChanges() = %s, want %s", tc.name, out, tc.want)
+		}
+	}
+}
+
+func TestRedactChangesRefusesInvalidJSON(t *testing.T) {
+	if _, err := redactChanges([]byte(`{"first_name": "Ada"`)); err == nil {
+		t.Error("redactChanges() accepted truncated JSON")
+	}
+}
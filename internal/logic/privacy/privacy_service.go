@@ -0,0 +1,49 @@
+// Package privacy answers data subject requests under UK GDPR: subject access requests
+// (DSARs), which compile everything held about an employee into an archive, and erasure,
+// which pseudonymises the employee without breaking the records that reference them.
+package privacy
+
+import (
+	"errors"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/hr"
+	"github.com/INOVA/DML/internal/storage"
+)
+
+// employeeEntity is the audit log entity type of subject access exports and erasures
+const employeeEntity = "Employees"
+
+var (
+	// ErrAlreadyErased is returned when erasing an employee a second time
+	ErrAlreadyErased = errors.New("employee has already been erased")
+
+	// ErrSelfErasure is returned when the actor tries to erase their own employee record
+	ErrSelfErasure = errors.New("you cannot erase your own employee record")
+
+	// ErrConfirmationMismatch is returned when the confirmation does not repeat the
+	// employee number of the employee being erased
+	ErrConfirmationMismatch = errors.New("confirmation does not match the employee number")
+)
+
+// PrivacyService compiles subject access archives and erases employees. Personal details
+// are decrypted through the personal details service, so reading them is audited there.
+type PrivacyService struct {
+	db              *db.DB
+	queries         *domain.Queries
+	store           storage.Store
+	personalDetails *hr.PersonalDetailsService
+	auditSvc        *audit.AuditService
+}
+
+func NewPrivacyService(database *db.DB, store storage.Store, personalDetails *hr.PersonalDetailsService, auditSvc *audit.AuditService) *PrivacyService {
+	return &PrivacyService{
+		db:              database,
+		queries:         domain.New(database.Pool),
+		store:           store,
+		personalDetails: personalDetails,
+		auditSvc:        auditSvc,
+	}
+}
@@ -0,0 +1,513 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// attrPath addresses an attribute of a resource: an optional extension schema URN, an
+// attribute name and an optional sub-attribute. Core schema URNs are dropped when parsed.
+type attrPath struct {
+	schema string
+	attr   string
+	sub    string
+}
+
+func parseAttrPath(s string) (attrPath, error) {
+	var p attrPath
+	rest := s
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		if strings.EqualFold(s, EnterpriseUserSchema) {
+			return attrPath{schema: EnterpriseUserSchema}, nil
+		}
+		i := strings.LastIndex(s, ":")
+		schema := s[:i]
+		switch {
+		case strings.EqualFold(schema, UserSchema), strings.EqualFold(schema, GroupSchema):
+		case strings.EqualFold(schema, EnterpriseUserSchema):
+			p.schema = EnterpriseUserSchema
+		default:
+			return p, badRequest(ScimTypeInvalidPath, "unsupported schema %q", schema)
+		}
+		rest = s[i+1:]
+	}
+
+	p.attr = rest
+	if i := strings.Index(rest, "."); i >= 0 {
+		p.attr, p.sub = rest[:i], rest[i+1:]
+	}
+	if !validAttrName(p.attr) || (p.sub != "" && !validAttrName(p.sub)) {
+		return p, badRequest(ScimTypeInvalidPath, "invalid attribute path %q", s)
+	}
+	return p, nil
+}
+
+func validAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r):
+		case i > 0 && (unicode.IsDigit(r) || r == '_' || r == '-'):
+		case i == 0 && r == '$':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// container returns the map holding the path's attribute: the resource itself or one of its
+// extensions. With create set, a missing extension is added.
+func (p attrPath) container(res map[string]interface{}, create bool) map[string]interface{} {
+	if p.schema == "" {
+		return res
+	}
+	key := lookupKey(res, p.schema)
+	ext, ok := res[key].(map[string]interface{})
+	if !ok && create {
+		ext = make(map[string]interface{})
+		res[key] = ext
+	}
+	return ext
+}
+
+// values collects the values the path refers to. Elements of multi-valued complex attributes
+// stand for their "value" sub-attribute unless another sub-attribute is named.
+func (p attrPath) values(res map[string]interface{}) []interface{} {
+	c := p.container(res, false)
+	if c == nil {
+		return nil
+	}
+	if p.attr == "" {
+		return []interface{}{c}
+	}
+	v, ok := c[lookupKey(c, p.attr)]
+	if !ok {
+		return nil
+	}
+
+	elements := []interface{}{v}
+	if arr, ok := v.([]interface{}); ok {
+		elements = arr
+	}
+	var out []interface{}
+	for _, e := range elements {
+		m, isMap := e.(map[string]interface{})
+		switch {
+		case p.sub != "" && isMap:
+			if sv, ok := m[lookupKey(m, p.sub)]; ok {
+				out = append(out, sv)
+			}
+		case p.sub != "":
+		case isMap && isArray(v):
+			if sv, ok := m[lookupKey(m, "value")]; ok {
+				out = append(out, sv)
+			}
+		default:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func isArray(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// lookupKey finds the key of m matching name case-insensitively, as attribute names are.
+// It returns name itself when m has no such key.
+func lookupKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// Filter is a parsed SCIM filter expression
+type Filter interface {
+	Match(res map[string]interface{}) bool
+}
+
+type logicalFilter struct {
+	or          bool
+	left, right Filter
+}
+
+func (f logicalFilter) Match(res map[string]interface{}) bool {
+	if f.or {
+		return f.left.Match(res) || f.right.Match(res)
+	}
+	return f.left.Match(res) && f.right.Match(res)
+}
+
+type notFilter struct {
+	inner Filter
+}
+
+func (f notFilter) Match(res map[string]interface{}) bool {
+	return !f.inner.Match(res)
+}
+
+type presentFilter struct {
+	path attrPath
+}
+
+func (f presentFilter) Match(res map[string]interface{}) bool {
+	for _, v := range f.path.values(res) {
+		switch t := v.(type) {
+		case nil:
+		case string:
+			if t != "" {
+				return true
+			}
+		case []interface{}:
+			if len(t) > 0 {
+				return true
+			}
+		case map[string]interface{}:
+			if len(t) > 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  attrPath
+	op    string
+	value interface{}
+}
+
+func (f compareFilter) Match(res map[string]interface{}) bool {
+	values := f.path.values(res)
+	if f.op == "ne" {
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	if f.value == nil && f.op == "eq" && len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare applies a comparison operator. Strings compare case-insensitively, which holds for
+// every attribute this server exposes except ids, where case does not occur.
+func compare(actual interface{}, op string, expected interface{}) bool {
+	switch e := expected.(type) {
+	case nil:
+		return op == "eq" && actual == nil
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case "eq":
+			return a == e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches resources with an element of a multi-valued attribute that
+// satisfies the inner filter, e.g. emails[type eq "work"]
+type valuePathFilter struct {
+	path  attrPath
+	inner Filter
+}
+
+func (f valuePathFilter) Match(res map[string]interface{}) bool {
+	return len(f.matches(res)) > 0
+}
+
+// matches returns the indexes of the matching elements
+func (f valuePathFilter) matches(res map[string]interface{}) []int {
+	c := f.path.container(res, false)
+	if c == nil {
+		return nil
+	}
+	arr, _ := c[lookupKey(c, f.path.attr)].([]interface{})
+	var out []int
+	for i, e := range arr {
+		if m, ok := e.(map[string]interface{}); ok && f.inner.Match(m) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+var compareOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// ParseFilter parses a filter expression (RFC 7644 section 3.4.2.2)
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, badRequest(ScimTypeInvalidFilter, "unexpected %q in filter", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, badRequest(ScimTypeInvalidFilter, "unterminated string in filter")
+			}
+			var v string
+			if err := json.Unmarshal([]byte(s[i:j+1]), &v); err != nil {
+				return nil, badRequest(ScimTypeInvalidFilter, "invalid string in filter")
+			}
+			tokens = append(tokens, token{text: v, quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n\r()[]\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{text: s[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peekWord(word string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, word)
+}
+
+func (p *filterParser) expect(text string) error {
+	if !p.peekWord(text) {
+		return badRequest(ScimTypeInvalidFilter, "expected %q in filter", text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekWord("not") {
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return notFilter{inner: inner}, nil
+	}
+	if p.peekWord("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseAttrExpr()
+}
+
+func (p *filterParser) parseAttrExpr() (Filter, error) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted {
+		return nil, badRequest(ScimTypeInvalidFilter, "expected an attribute in filter")
+	}
+	path, err := parseAttrPath(p.tokens[p.pos].text)
+	if err != nil {
+		return nil, badRequest(ScimTypeInvalidFilter, "%s", err.Error())
+	}
+	p.pos++
+
+	if p.peekWord("[") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{path: path, inner: inner}, nil
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, badRequest(ScimTypeInvalidFilter, "expected an operator in filter")
+	}
+	op := strings.ToLower(p.tokens[p.pos].text)
+	p.pos++
+	if op == "pr" {
+		return presentFilter{path: path}, nil
+	}
+	if !compareOps[op] {
+		return nil, badRequest(ScimTypeInvalidFilter, "unsupported operator %q in filter", op)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, badRequest(ScimTypeInvalidFilter, "expected a value in filter")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	if tok.quoted {
+		return compareFilter{path: path, op: op, value: tok.text}, nil
+	}
+	switch strings.ToLower(tok.text) {
+	case "true":
+		return compareFilter{path: path, op: op, value: true}, nil
+	case "false":
+		return compareFilter{path: path, op: op, value: false}, nil
+	case "null":
+		return compareFilter{path: path, op: op, value: nil}, nil
+	}
+	n, err := strconv.ParseFloat(tok.text, 64)
+	if err != nil {
+		return nil, badRequest(ScimTypeInvalidFilter, "invalid value %q in filter", tok.text)
+	}
+	return compareFilter{path: path, op: op, value: n}, nil
+}
+
+// filterResources keeps the resources matching a filter expression; an empty one keeps all
+func filterResources(resources []map[string]interface{}, expr string) ([]map[string]interface{}, error) {
+	if strings.TrimSpace(expr) == "" {
+		return resources, nil
+	}
+	f, err := ParseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]interface{}
+	for _, r := range resources {
+		if f.Match(r) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
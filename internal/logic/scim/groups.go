@@ -0,0 +1,418 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// protectedRoleCode is the role a replace or patch may not leave without an active member,
+// so an identity provider cannot lock every administrator out of the tenant
+const protectedRoleCode = "ADMIN"
+
+// keepActiveHolder refuses membership changes that leave the protected role with no active
+// holders
+func keepActiveHolder(code string, activeHolders int64) error {
+	if code == protectedRoleCode && activeHolders == 0 {
+		return &Error{Status: http.StatusBadRequest, ScimType: ScimTypeMutability, Detail: "the " + protectedRoleCode + " role must keep at least one active member"}
+	}
+	return nil
+}
+
+// issuerCovers refuses membership changes to a role whose permissions the token's issuer
+// does not hold, so a SCIM token can neither hand out nor strip more than its issuer could
+func issuerCovers(held []string, code string) error {
+	if !iam.Covers(held, []string{code}) {
+		return &Error{Status: http.StatusForbidden, Detail: "the token's issuer cannot grant or revoke the " + code + " role"}
+	}
+	return nil
+}
+
+// deletable refuses to delete roles that grant permissions. The API cannot create them, so
+// a deleted one could only be restored in the database.
+func deletable(code string) error {
+	if iam.Reserved(code) {
+		return &Error{Status: http.StatusBadRequest, ScimType: ScimTypeMutability, Detail: "the " + code + " role cannot be deleted"}
+	}
+	return nil
+}
+
+func toGroup(role domain.RbacRole, members []Reference) Group {
+	return Group{
+		Schemas:     []string{GroupSchema},
+		ID:          idString(role.ID),
+		ExternalID:  role.ExternalID.String,
+		DisplayName: role.Name,
+		Members:     members,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      role.CreatedAt.Time,
+			LastModified: role.UpdatedAt.Time,
+		},
+	}
+}
+
+func memberRef(m domain.ListRoleMembersRow) Reference {
+	display := m.Email
+	if m.DisplayName.Valid {
+		display = m.DisplayName.String
+	}
+	return Reference{Value: idString(m.ID), Display: display}
+}
+
+// roleCode derives a role code from a group name, e.g. "QA Managers" becomes QA_MANAGERS
+func roleCode(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToUpper(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteRune('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// ListGroups returns the groups matching a filter, a page at a time
+func (s *ScimService) ListGroups(ctx context.Context, tenantID pgtype.UUID, params ListParams) (ListResponse, error) {
+	roles, err := s.queries.ListRoles(ctx, tenantID)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	grants, err := s.queries.ListRoleMembers(ctx, domain.ListRoleMembersParams{TenantID: tenantID})
+	if err != nil {
+		return ListResponse{}, err
+	}
+
+	members := make(map[[16]byte][]Reference)
+	for _, g := range grants {
+		members[g.RoleID.Bytes] = append(members[g.RoleID.Bytes], memberRef(g))
+	}
+
+	resources := make([]map[string]interface{}, 0, len(roles))
+	for _, role := range roles {
+		m, err := toMap(toGroup(role, members[role.ID.Bytes]))
+		if err != nil {
+			return ListResponse{}, err
+		}
+		resources = append(resources, m)
+	}
+
+	resources, err = filterResources(resources, params.Filter)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	return page(resources, params), nil
+}
+
+func (s *ScimService) GetGroup(ctx context.Context, tenantID pgtype.UUID, id string) (Group, error) {
+	roleID, err := parseID(id)
+	if err != nil {
+		return Group{}, err
+	}
+	return s.loadGroup(ctx, s.queries, tenantID, roleID)
+}
+
+func (s *ScimService) loadGroup(ctx context.Context, q *domain.Queries, tenantID, roleID pgtype.UUID) (Group, error) {
+	role, err := q.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: roleID})
+	if err != nil {
+		return Group{}, mapError(err)
+	}
+	rows, err := q.ListRoleMembers(ctx, domain.ListRoleMembersParams{TenantID: tenantID, RoleID: roleID})
+	if err != nil {
+		return Group{}, err
+	}
+	var members []Reference
+	for _, m := range rows {
+		members = append(members, memberRef(m))
+	}
+	return toGroup(role, members), nil
+}
+
+// CreateGroup creates a role named after the group and grants it to the members. issuedBy
+// is the user who issued the SCIM token.
+func (s *ScimService) CreateGroup(ctx context.Context, tenantID, issuedBy pgtype.UUID, in Group) (Group, error) {
+	name := strings.TrimSpace(in.DisplayName)
+	code := roleCode(name)
+	if code == "" {
+		return Group{}, badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+	if iam.Reserved(code) {
+		return Group{}, badRequest(ScimTypeInvalidValue, "displayName maps to the reserved role code %s", code)
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return Group{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	roleID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if _, err := q.CreateRole(ctx, domain.CreateRoleParams{
+		ID:       roleID,
+		TenantID: tenantID,
+		Code:     code,
+		Name:     name,
+	}); err != nil {
+		return Group{}, mapError(err)
+	}
+	role, err := q.UpdateScimRole(ctx, domain.UpdateScimRoleParams{
+		TenantID:   tenantID,
+		ID:         roleID,
+		Name:       name,
+		ExternalID: optionalText(strings.TrimSpace(in.ExternalID)),
+	})
+	if err != nil {
+		return Group{}, mapError(err)
+	}
+
+	added, _, err := s.setMembers(ctx, q, tenantID, issuedBy, role, nil, in.Members)
+	if err != nil {
+		return Group{}, err
+	}
+	group, err := s.loadGroup(ctx, q, tenantID, roleID)
+	if err != nil {
+		return Group{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Group{}, err
+	}
+
+	s.log(ctx, tenantID, "CREATE", "Roles", roleID.Bytes, map[string]interface{}{
+		"code": code,
+		"name": name,
+	})
+	s.logMembers(ctx, tenantID, role, added, nil)
+	return group, nil
+}
+
+// ReplaceGroup renames a group and sets its members to exactly those given
+func (s *ScimService) ReplaceGroup(ctx context.Context, tenantID, issuedBy pgtype.UUID, id string, in Group) (Group, error) {
+	roleID, err := parseID(id)
+	if err != nil {
+		return Group{}, err
+	}
+	name := strings.TrimSpace(in.DisplayName)
+	if name == "" {
+		return Group{}, badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return Group{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	current, err := s.loadGroup(ctx, q, tenantID, roleID)
+	if err != nil {
+		return Group{}, err
+	}
+	role, err := q.UpdateScimRole(ctx, domain.UpdateScimRoleParams{
+		TenantID:   tenantID,
+		ID:         roleID,
+		Name:       name,
+		ExternalID: optionalText(strings.TrimSpace(in.ExternalID)),
+	})
+	if err != nil {
+		return Group{}, mapError(err)
+	}
+
+	added, removed, err := s.setMembers(ctx, q, tenantID, issuedBy, role, current.Members, in.Members)
+	if err != nil {
+		return Group{}, err
+	}
+	if len(removed) > 0 {
+		holders, err := q.CountActiveRoleHolders(ctx, domain.CountActiveRoleHoldersParams{TenantID: tenantID, RoleID: roleID})
+		if err != nil {
+			return Group{}, err
+		}
+		if err := keepActiveHolder(role.Code, holders); err != nil {
+			return Group{}, err
+		}
+	}
+	group, err := s.loadGroup(ctx, q, tenantID, roleID)
+	if err != nil {
+		return Group{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Group{}, err
+	}
+
+	changes := map[string]interface{}{}
+	changed(changes, "name", current.DisplayName, name)
+	changed(changes, "external_id", current.ExternalID, role.ExternalID.String)
+	if len(changes) > 0 {
+		s.log(ctx, tenantID, "UPDATE", "Roles", roleID.Bytes, changes)
+	}
+	s.logMembers(ctx, tenantID, role, added, removed)
+	return group, nil
+}
+
+// PatchGroup applies PATCH operations to the current resource and stores the result
+func (s *ScimService) PatchGroup(ctx context.Context, tenantID, issuedBy pgtype.UUID, id string, req PatchRequest) (Group, error) {
+	current, err := s.GetGroup(ctx, tenantID, id)
+	if err != nil {
+		return Group{}, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return Group{}, err
+	}
+	if err := applyPatch(m, req); err != nil {
+		return Group{}, err
+	}
+
+	var patched Group
+	if err := fromMap(m, &patched); err != nil {
+		return Group{}, err
+	}
+	return s.ReplaceGroup(ctx, tenantID, issuedBy, id, patched)
+}
+
+// DeleteGroup revokes the role from everyone and deletes it. Roles that grant permissions
+// are never deleted, and other roles only while issuedBy could grant them.
+func (s *ScimService) DeleteGroup(ctx context.Context, tenantID, issuedBy pgtype.UUID, id string) error {
+	roleID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	role, err := q.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: roleID})
+	if err != nil {
+		return mapError(err)
+	}
+	if err := deletable(role.Code); err != nil {
+		return err
+	}
+	held, err := q.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: issuedBy})
+	if err != nil {
+		return err
+	}
+	if err := issuerCovers(held, role.Code); err != nil {
+		return err
+	}
+	revoked, err := q.RevokeRoleFromAllUsers(ctx, domain.RevokeRoleFromAllUsersParams{TenantID: tenantID, RoleID: roleID})
+	if err != nil {
+		return err
+	}
+	if _, err := q.DeleteRole(ctx, domain.DeleteRoleParams{TenantID: tenantID, ID: roleID}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.log(ctx, tenantID, "DELETE", "Roles", roleID.Bytes, map[string]interface{}{
+		"code":           role.Code,
+		"name":           role.Name,
+		"grants_revoked": revoked,
+	})
+	return nil
+}
+
+// setMembers grants and revokes the role so exactly the wanted users hold it, returning the
+// user ids added and removed. Members are only added or removed while issuedBy holds every
+// permission the role grants, so a SCIM token cannot hand out or strip more than the user
+// who issued it.
+func (s *ScimService) setMembers(ctx context.Context, q *domain.Queries, tenantID, issuedBy pgtype.UUID, role domain.RbacRole, current, wanted []Reference) ([]pgtype.UUID, []pgtype.UUID, error) {
+	have := make(map[string]bool, len(current))
+	for _, m := range current {
+		have[m.Value] = true
+	}
+	want := make(map[string]bool, len(wanted))
+
+	checked := false
+	checkIssuer := func() error {
+		if checked {
+			return nil
+		}
+		checked = true
+		held, err := q.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: issuedBy})
+		if err != nil {
+			return err
+		}
+		return issuerCovers(held, role.Code)
+	}
+
+	var added, removed []pgtype.UUID
+	for _, m := range wanted {
+		if want[m.Value] {
+			continue
+		}
+		want[m.Value] = true
+		if have[m.Value] {
+			continue
+		}
+		if err := checkIssuer(); err != nil {
+			return nil, nil, err
+		}
+		user, err := resolveUser(ctx, q, tenantID, m.Value, "member")
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := q.AssignUserRole(ctx, domain.AssignUserRoleParams{
+			TenantID: tenantID,
+			UserID:   user.ID,
+			RoleID:   role.ID,
+		}); err != nil {
+			return nil, nil, err
+		}
+		added = append(added, user.ID)
+	}
+
+	for _, m := range current {
+		if want[m.Value] {
+			continue
+		}
+		if err := checkIssuer(); err != nil {
+			return nil, nil, err
+		}
+		userID, err := parseID(m.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := q.RevokeUserRole(ctx, domain.RevokeUserRoleParams{
+			TenantID: tenantID,
+			UserID:   userID,
+			RoleID:   role.ID,
+		}); err != nil {
+			return nil, nil, err
+		}
+		removed = append(removed, userID)
+	}
+	return added, removed, nil
+}
+
+// logMembers audits membership changes as role grants, keyed by user like UserRoleService does
+func (s *ScimService) logMembers(ctx context.Context, tenantID pgtype.UUID, role domain.RbacRole, added, removed []pgtype.UUID) {
+	for _, userID := range added {
+		s.log(ctx, tenantID, "CREATE", "UserRoles", userID.Bytes, map[string]interface{}{
+			"role_id":   role.ID,
+			"role_code": role.Code,
+		})
+	}
+	for _, userID := range removed {
+		s.log(ctx, tenantID, "DELETE", "UserRoles", userID.Bytes, map[string]interface{}{
+			"role_id":   role.ID,
+			"role_code": role.Code,
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestKeepActiveHolder(t *testing.T) {
+	cases := []struct {
+		code    string
+		holders int64
+		refused bool
+	}{
+		{protectedRoleCode, 0, true},
+		{protectedRoleCode, 1, false},
+		{"QA_MANAGERS", 0, false},
+		{"HR_ADMIN", 0, false},
+	}
+	for _, tc := range cases {
+		err := keepActiveHolder(tc.code, tc.holders)
+		if (err != nil) != tc.refused {
+			t.Errorf("keepActiveHolder(%q, %d) = %v, want refused %v", tc.code, tc.holders, err, tc.refused)
+			continue
+		}
+		var scimErr *Error
+		if err != nil && (!errors.As(err, &scimErr) || scimErr.Status != http.StatusBadRequest || scimErr.ScimType != ScimTypeMutability) {
+			t.Errorf("keepActiveHolder(%q, %d) = %#v, want a 400 mutability error", tc.code, tc.holders, err)
+		}
+	}
+}
+
+// An identity provider emptying the ADMIN group, by PUT or PATCH, leaves no members to keep
+func TestEmptyingAdminGroupIsRefused(t *testing.T) {
+	admins := Group{
+		Schemas:     []string{GroupSchema},
+		DisplayName: "Admin",
+		Members:     []Reference{{Value: "3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e"}, {Value: "8e2d6b1a-0f3c-4d5e-a6b7-c8d9e0f1a2b3"}},
+	}
+	patches := map[string]string{
+		"remove members":    `{"Operations":[{"op":"remove","path":"members"}]}`,
+		"replace members":   `{"Operations":[{"op":"replace","path":"members","value":[]}]}`,
+		"remove each by id": `{"Operations":[{"op":"remove","path":"members[value eq \"3f1c0a52-5c1e-4b9a-9d43-6a0d1b2c3d4e\"]"},{"op":"remove","path":"members[value eq \"8e2d6b1a-0f3c-4d5e-a6b7-c8d9e0f1a2b3\"]"}]}`,
+	}
+	for name, body := range patches {
+		var req PatchRequest
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatal(err)
+		}
+		m, err := toMap(admins)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := applyPatch(m, req); err != nil {
+			t.Fatalf("%s: applyPatch() = %v", name, err)
+		}
+		var patched Group
+		if err := fromMap(m, &patched); err != nil {
+			t.Fatal(err)
+		}
+		if len(patched.Members) != 0 {
+			t.Fatalf("%s: patched members = %v, want none", name, patched.Members)
+		}
+		if err := keepActiveHolder(roleCode(patched.DisplayName), int64(len(patched.Members))); err == nil {
+			t.Errorf("%s: emptying the %s group was not refused", name, protectedRoleCode)
+		}
+	}
+}
+
+func TestIssuerCovers(t *testing.T) {
+	cases := []struct {
+		held    []string
+		code    string
+		refused bool
+	}{
+		{[]string{"ADMIN"}, "QA_MANAGERS", false},
+		{[]string{"ADMIN"}, "ADMIN", false},
+		{[]string{"ADMIN"}, "HR_ADMIN", true},
+		{[]string{"ADMIN", "HR_ADMIN"}, "HR_ADMIN", false},
+		{nil, "ADMIN", true},
+	}
+	for _, tc := range cases {
+		err := issuerCovers(tc.held, tc.code)
+		if (err != nil) != tc.refused {
+			t.Errorf("issuerCovers(%v, %q) = %v, want refused %v", tc.held, tc.code, err, tc.refused)
+			continue
+		}
+		var scimErr *Error
+		if err != nil && (!errors.As(err, &scimErr) || scimErr.Status != http.StatusForbidden) {
+			t.Errorf("issuerCovers(%v, %q) = %#v, want a 403", tc.held, tc.code, err)
+		}
+	}
+}
+
+func TestDeletable(t *testing.T) {
+	for code, refused := range map[string]bool{
+		"ADMIN":       true,
+		"HR_ADMIN":    true,
+		"QA_MANAGERS": false,
+	} {
+		err := deletable(code)
+		if (err != nil) != refused {
+			t.Errorf("deletable(%q) = %v, want refused %v", code, err, refused)
+			continue
+		}
+		var scimErr *Error
+		if err != nil && (!errors.As(err, &scimErr) || scimErr.Status != http.StatusBadRequest || scimErr.ScimType != ScimTypeMutability) {
+			t.Errorf("deletable(%q) = %#v, want a 400 mutability error", code, err)
+		}
+	}
+}
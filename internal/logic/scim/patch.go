@@ -0,0 +1,291 @@
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// PatchRequest is the body of a PATCH request (RFC 7644 section 3.5.2)
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// patchPath is a PATCH target: an attribute path, optionally narrowed to the elements of a
+// multi-valued attribute matching a filter and then to one of their sub-attributes, as in
+// members[value eq "..."] or emails[type eq "work"].value
+type patchPath struct {
+	attrPath
+	filter *valuePathFilter
+}
+
+func parsePatchPath(s string) (patchPath, error) {
+	lb := strings.Index(s, "[")
+	if lb < 0 {
+		p, err := parseAttrPath(s)
+		return patchPath{attrPath: p}, err
+	}
+	rb := strings.LastIndex(s, "]")
+	if rb < lb {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", s)
+	}
+
+	p, err := parseAttrPath(s[:lb])
+	if err != nil || p.sub != "" {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", s)
+	}
+	inner, err := ParseFilter(s[lb+1 : rb])
+	if err != nil {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid filter in path %q", s)
+	}
+
+	if rest := s[rb+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") || !validAttrName(rest[1:]) {
+			return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", s)
+		}
+		p.sub = rest[1:]
+	}
+	return patchPath{attrPath: p, filter: &valuePathFilter{path: attrPath{schema: p.schema, attr: p.attr}, inner: inner}}, nil
+}
+
+// applyPatch applies the operations in order to a rendered resource
+func applyPatch(res map[string]interface{}, req PatchRequest) error {
+	if len(req.Operations) == 0 {
+		return badRequest(ScimTypeInvalidSyntax, "no operations given")
+	}
+	for _, op := range req.Operations {
+		var value interface{}
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return badRequest(ScimTypeInvalidSyntax, "invalid operation value")
+			}
+		}
+		if err := applyOperation(res, strings.ToLower(op.Op), op.Path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(res map[string]interface{}, op, path string, value interface{}) error {
+	switch op {
+	case "add", "replace", "remove":
+	default:
+		return badRequest(ScimTypeInvalidSyntax, "unsupported operation %q", op)
+	}
+
+	if path == "" {
+		if op == "remove" {
+			return badRequest(ScimTypeNoTarget, "remove requires a path")
+		}
+		// Without a path the value holds attributes to set; keys may themselves be paths
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return badRequest(ScimTypeInvalidValue, "%s without a path requires an object value", op)
+		}
+		for k, v := range values {
+			if strings.EqualFold(k, "schemas") {
+				continue
+			}
+			if err := applyOperation(res, op, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	if p.attr == "" {
+		// The path names a whole extension
+		if op == "remove" {
+			delete(res, lookupKey(res, p.schema))
+			return nil
+		}
+		key := lookupKey(res, p.schema)
+		res[key] = merge(res[key], value)
+		return nil
+	}
+
+	c := p.container(res, op != "remove")
+	if c == nil {
+		return nil
+	}
+	key := lookupKey(c, p.attr)
+
+	if p.filter != nil {
+		return applyFiltered(c, key, p, op, value)
+	}
+
+	if p.sub != "" {
+		target, ok := c[key].(map[string]interface{})
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			target = make(map[string]interface{})
+			c[key] = target
+		}
+		subKey := lookupKey(target, p.sub)
+		if op == "remove" {
+			delete(target, subKey)
+		} else {
+			target[subKey] = merge(target[subKey], value)
+		}
+		return nil
+	}
+
+	switch op {
+	case "remove":
+		existing, isArr := c[key].([]interface{})
+		removals, hasValues := value.([]interface{})
+		if isArr && hasValues {
+			// Some providers remove members by listing them as the value
+			c[key] = withoutValues(existing, removals)
+			return nil
+		}
+		delete(c, key)
+	case "add":
+		if existing, ok := c[key].([]interface{}); ok {
+			additions, ok := value.([]interface{})
+			if !ok {
+				additions = []interface{}{value}
+			}
+			c[key] = appendUnique(existing, additions)
+			return nil
+		}
+		c[key] = merge(c[key], value)
+	case "replace":
+		c[key] = merge(c[key], value)
+	}
+	return nil
+}
+
+// applyFiltered applies an operation to the elements selected by a value filter
+func applyFiltered(c map[string]interface{}, key string, p patchPath, op string, value interface{}) error {
+	arr, _ := c[key].([]interface{})
+	matches := p.filter.matches(c)
+
+	if len(matches) == 0 {
+		if op == "remove" {
+			return nil
+		}
+		// Adding to an element that does not exist yet, e.g. emails[type eq "work"].value,
+		// creates it when the filter says what it should look like
+		cf, ok := p.filter.inner.(compareFilter)
+		if !ok || cf.op != "eq" || cf.path.sub != "" {
+			return badRequest(ScimTypeNoTarget, "no %s match the path filter", p.attr)
+		}
+		element := map[string]interface{}{cf.path.attr: cf.value}
+		if p.sub != "" {
+			element[p.sub] = value
+		} else if m, ok := value.(map[string]interface{}); ok {
+			for k, v := range m {
+				element[k] = v
+			}
+		}
+		c[key] = append(arr, element)
+		return nil
+	}
+
+	if op == "remove" && p.sub == "" {
+		drop := make(map[int]bool)
+		for _, i := range matches {
+			drop[i] = true
+		}
+		kept := []interface{}{}
+		for i, e := range arr {
+			if !drop[i] {
+				kept = append(kept, e)
+			}
+		}
+		c[key] = kept
+		return nil
+	}
+
+	for _, i := range matches {
+		element := arr[i].(map[string]interface{})
+		switch {
+		case p.sub != "" && op == "remove":
+			delete(element, lookupKey(element, p.sub))
+		case p.sub != "":
+			subKey := lookupKey(element, p.sub)
+			element[subKey] = merge(element[subKey], value)
+		case op == "replace":
+			arr[i] = value
+		default:
+			arr[i] = merge(element, value)
+		}
+	}
+	return nil
+}
+
+// merge combines a new value into an existing one. Objects merge attribute by attribute,
+// anything else is replaced.
+func merge(existing, value interface{}) interface{} {
+	em, ok1 := existing.(map[string]interface{})
+	vm, ok2 := value.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return value
+	}
+	for k, v := range vm {
+		key := lookupKey(em, k)
+		em[key] = merge(em[key], v)
+	}
+	return em
+}
+
+// sameElement reports whether two elements of a multi-valued attribute have the same value
+func sameElement(a, b interface{}) bool {
+	av, ok1 := elementValue(a).(string)
+	bv, ok2 := elementValue(b).(string)
+	return ok1 && ok2 && av == bv
+}
+
+func elementValue(e interface{}) interface{} {
+	if m, ok := e.(map[string]interface{}); ok {
+		return m[lookupKey(m, "value")]
+	}
+	return e
+}
+
+func appendUnique(existing, additions []interface{}) []interface{} {
+	for _, a := range additions {
+		duplicate := false
+		for _, e := range existing {
+			if sameElement(e, a) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			existing = append(existing, a)
+		}
+	}
+	return existing
+}
+
+func withoutValues(existing, removals []interface{}) []interface{} {
+	kept := []interface{}{}
+	for _, e := range existing {
+		remove := false
+		for _, r := range removals {
+			if sameElement(e, r) {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
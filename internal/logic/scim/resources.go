@@ -0,0 +1,270 @@
+// Package scim implements SCIM 2.0 (RFC 7643/7644) provisioning of users and groups.
+// A SCIM User is a user account together with its mandatory employee record; a SCIM Group
+// is an RBAC role, and group membership is a tenant-wide grant of that role.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Schema and message URNs
+const (
+	UserSchema           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	EnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	GroupSchema          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListResponseSchema   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema          = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIM error types (RFC 7644 section 3.12)
+const (
+	ScimTypeInvalidFilter = "invalidFilter"
+	ScimTypeInvalidPath   = "invalidPath"
+	ScimTypeInvalidSyntax = "invalidSyntax"
+	ScimTypeInvalidValue  = "invalidValue"
+	ScimTypeMutability    = "mutability"
+	ScimTypeNoTarget      = "noTarget"
+	ScimTypeUniqueness    = "uniqueness"
+)
+
+// Error is a protocol error reported to the client in the SCIM error format
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func badRequest(scimType, format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Boolean accepts JSON booleans as well as "true"/"false" strings, which some identity
+// providers send for active
+type Boolean bool
+
+func (b *Boolean) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = Boolean(t)
+	case string:
+		switch strings.ToLower(t) {
+		case "true":
+			*b = true
+		case "false":
+			*b = false
+		default:
+			return fmt.Errorf("invalid boolean %q", t)
+		}
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string  `json:"value"`
+	Type    string  `json:"type,omitempty"`
+	Primary Boolean `json:"primary,omitempty"`
+}
+
+// Reference points at another resource, e.g. a group member or a user's group
+type Reference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type Manager struct {
+	Value       string `json:"value,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type EnterpriseUser struct {
+	EmployeeNumber string   `json:"employeeNumber,omitempty"`
+	Manager        *Manager `json:"manager,omitempty"`
+}
+
+// User is a user account and its employee record. userName is the login email.
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []Email         `json:"emails,omitempty"`
+	Active      *Boolean        `json:"active,omitempty"`
+	Password    string          `json:"password,omitempty"`
+	Groups      []Reference     `json:"groups,omitempty"`
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+// workEmail picks the primary address, else the first work address, else the first one
+func (u User) workEmail() string {
+	for _, e := range u.Emails {
+		if bool(e.Primary) && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if strings.EqualFold(e.Type, "work") && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Value != "" {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// Group is an RBAC role; its members hold the role tenant-wide
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []Reference `json:"members,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+// ListResponse wraps a page of query results
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// ListParams are the query parameters of a list request. StartIndex is 1-based.
+type ListParams struct {
+	Filter     string
+	StartIndex int
+	Count      int
+}
+
+const (
+	// DefaultCount is the page size when a list request does not ask for one
+	DefaultCount = 100
+	// MaxCount caps the page size of a list request
+	MaxCount = 200
+)
+
+// page applies startIndex and count to a filtered result set
+func page(resources []map[string]interface{}, params ListParams) ListResponse {
+	start := params.StartIndex
+	if start < 1 {
+		start = 1
+	}
+	count := params.Count
+	if count < 0 {
+		count = 0
+	}
+	if count > MaxCount {
+		count = MaxCount
+	}
+
+	items := []interface{}{}
+	for i := start - 1; i < len(resources) && len(items) < count; i++ {
+		items = append(items, resources[i])
+	}
+	return ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: len(resources),
+		StartIndex:   start,
+		ItemsPerPage: len(items),
+		Resources:    items,
+	}
+}
+
+// toMap renders a resource as generic JSON so filters and patches can address it by path
+func toMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fromMap decodes a generic resource back into its typed form
+func fromMap(m map[string]interface{}, resource interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, resource); err != nil {
+		return badRequest(ScimTypeInvalidValue, "invalid resource: %v", err)
+	}
+	return nil
+}
+
+// Project applies the attributes and excludedAttributes query parameters to a rendered
+// resource. Only top-level attributes are considered; id, schemas and meta are always kept.
+func Project(resource map[string]interface{}, attributes, excluded []string) map[string]interface{} {
+	always := map[string]bool{"id": true, "schemas": true, "meta": true}
+	if len(attributes) > 0 {
+		keep := make(map[string]bool)
+		for _, a := range attributes {
+			keep[strings.ToLower(topLevel(a))] = true
+		}
+		for k := range resource {
+			if !always[k] && !keep[strings.ToLower(k)] {
+				delete(resource, k)
+			}
+		}
+		return resource
+	}
+	for _, a := range excluded {
+		for k := range resource {
+			if !always[k] && strings.EqualFold(k, topLevel(a)) {
+				delete(resource, k)
+			}
+		}
+	}
+	return resource
+}
+
+// topLevel reduces an attribute path to the top-level attribute it sits under, which for
+// extension attributes is the extension itself
+func topLevel(path string) string {
+	p, err := parseAttrPath(path)
+	if err != nil {
+		return path
+	}
+	if p.schema != "" {
+		return p.schema
+	}
+	return p.attr
+}
@@ -0,0 +1,111 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// auditSource tags audit entries written on behalf of the identity provider. They have no
+// actor, as SCIM requests authenticate with a tenant token rather than a user.
+const auditSource = "scim"
+
+type ScimService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
+}
+
+func NewScimService(database *db.DB, auditSvc *audit.AuditService) *ScimService {
+	return &ScimService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
+	}
+}
+
+func (s *ScimService) log(ctx context.Context, tenantID pgtype.UUID, action, entityType string, entityID [16]byte, changes map[string]interface{}) {
+	if s.auditSvc == nil {
+		return
+	}
+	changes["source"] = auditSource
+	s.auditSvc.Log(ctx, tenantID, pgtype.UUID{}, action, entityType, entityID, changes)
+}
+
+var errNotFound = &Error{Status: http.StatusNotFound, Detail: "Resource not found"}
+
+// errErased refuses changes to a user whose employee was erased under the right to erasure
+var errErased = &Error{Status: http.StatusConflict, Detail: "User has been erased"}
+
+// mapError turns missing rows and constraint violations into protocol errors
+func mapError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errNotFound
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "users_tenant_id_email_key":
+		return &Error{Status: http.StatusConflict, ScimType: ScimTypeUniqueness, Detail: "userName is already in use"}
+	case "uq_users_external_id", "uq_rbac_roles_external_id":
+		return &Error{Status: http.StatusConflict, ScimType: ScimTypeUniqueness, Detail: "externalId is already in use"}
+	case "employees_tenant_id_employee_no_key":
+		return &Error{Status: http.StatusConflict, ScimType: ScimTypeUniqueness, Detail: "employeeNumber is already in use"}
+	case "employees_tenant_id_work_email_key":
+		return &Error{Status: http.StatusConflict, ScimType: ScimTypeUniqueness, Detail: "email is already in use by another employee"}
+	case "rbac_roles_tenant_id_code_key":
+		return &Error{Status: http.StatusConflict, ScimType: ScimTypeUniqueness, Detail: "a group with this displayName already exists"}
+	case "employees_manager_no_cycle":
+		return badRequest(ScimTypeInvalidValue, "manager would create a reporting cycle")
+	}
+	return err
+}
+
+func parseID(id string) (pgtype.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, errNotFound
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}, nil
+}
+
+func idString(id pgtype.UUID) string {
+	return uuid.UUID(id.Bytes).String()
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// changed records a from/to pair in an audit change set when the value differs
+func changed(changes map[string]interface{}, field string, from, to interface{}) {
+	if from != to {
+		changes[field] = map[string]interface{}{"from": from, "to": to}
+	}
+}
+
+// resolveUser looks up a user referenced by SCIM id, e.g. a manager or a group member
+func resolveUser(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, ref, what string) (domain.User, error) {
+	id, err := uuid.Parse(ref)
+	if err != nil {
+		return domain.User{}, badRequest(ScimTypeInvalidValue, "%s %q is not a user id", what, ref)
+	}
+	user, err := q.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       pgtype.UUID{Bytes: id, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.User{}, badRequest(ScimTypeInvalidValue, "%s %q does not exist", what, ref)
+	}
+	return user, err
+}
@@ -0,0 +1,445 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func toUser(row domain.ListScimUsersRow, groups []Reference) User {
+	active := Boolean(row.IsActive)
+	formatted := strings.TrimSpace(row.FirstName + " " + row.LastName)
+
+	user := User{
+		Schemas:     []string{UserSchema, EnterpriseUserSchema},
+		ID:          idString(row.ID),
+		ExternalID:  row.ExternalID.String,
+		UserName:    row.Email,
+		Name:        &Name{Formatted: formatted, GivenName: row.FirstName, FamilyName: row.LastName},
+		DisplayName: row.DisplayName.String,
+		Active:      &active,
+		Groups:      groups,
+		Enterprise:  &EnterpriseUser{EmployeeNumber: row.EmployeeNo},
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      row.CreatedAt.Time,
+			LastModified: row.UpdatedAt.Time,
+		},
+	}
+	email := row.Email
+	if row.WorkEmail.Valid {
+		email = row.WorkEmail.String
+	}
+	user.Emails = []Email{{Value: email, Type: "work", Primary: true}}
+
+	if row.ManagerUserID.Valid {
+		user.Enterprise.Manager = &Manager{Value: idString(row.ManagerUserID)}
+	}
+	return user
+}
+
+// ListUsers returns the users matching a filter, a page at a time
+func (s *ScimService) ListUsers(ctx context.Context, tenantID pgtype.UUID, params ListParams) (ListResponse, error) {
+	rows, err := s.queries.ListScimUsers(ctx, tenantID)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	roles, err := s.queries.ListRoles(ctx, tenantID)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	grants, err := s.queries.ListRoleMembers(ctx, domain.ListRoleMembersParams{TenantID: tenantID})
+	if err != nil {
+		return ListResponse{}, err
+	}
+
+	roleNames := make(map[[16]byte]string, len(roles))
+	for _, r := range roles {
+		roleNames[r.ID.Bytes] = r.Name
+	}
+	groups := make(map[[16]byte][]Reference)
+	for _, g := range grants {
+		groups[g.ID.Bytes] = append(groups[g.ID.Bytes], Reference{Value: idString(g.RoleID), Display: roleNames[g.RoleID.Bytes]})
+	}
+
+	resources := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		m, err := toMap(toUser(row, groups[row.ID.Bytes]))
+		if err != nil {
+			return ListResponse{}, err
+		}
+		resources = append(resources, m)
+	}
+
+	resources, err = filterResources(resources, params.Filter)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	return page(resources, params), nil
+}
+
+func (s *ScimService) GetUser(ctx context.Context, tenantID pgtype.UUID, id string) (User, error) {
+	userID, err := parseID(id)
+	if err != nil {
+		return User{}, err
+	}
+	row, err := s.queries.GetScimUser(ctx, domain.GetScimUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return User{}, mapError(err)
+	}
+	return s.loadUser(ctx, tenantID, domain.ListScimUsersRow(row))
+}
+
+func (s *ScimService) loadUser(ctx context.Context, tenantID pgtype.UUID, row domain.ListScimUsersRow) (User, error) {
+	refs, err := s.queries.ListUserRoleRefs(ctx, domain.ListUserRoleRefsParams{TenantID: tenantID, UserID: row.ID})
+	if err != nil {
+		return User{}, err
+	}
+	var groups []Reference
+	for _, r := range refs {
+		groups = append(groups, Reference{Value: idString(r.ID), Display: r.Name})
+	}
+	return toUser(row, groups), nil
+}
+
+// userFields are the stored values a SCIM user resolves to
+type userFields struct {
+	email        string
+	displayName  pgtype.Text
+	externalID   pgtype.Text
+	isActive     bool
+	passwordHash pgtype.Text
+	employeeNo   string
+	firstName    string
+	lastName     string
+	workEmail    pgtype.Text
+	managerID    pgtype.UUID
+}
+
+// resolveUserFields maps a submitted resource onto stored values. current is the stored user
+// for replacements and nil on create; values the resource leaves out are kept from it.
+func (s *ScimService) resolveUserFields(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, in User, current *domain.ListScimUsersRow) (userFields, error) {
+	f := userFields{
+		email:       strings.TrimSpace(in.UserName),
+		displayName: optionalText(strings.TrimSpace(in.DisplayName)),
+		externalID:  optionalText(strings.TrimSpace(in.ExternalID)),
+		isActive:    true,
+	}
+	if f.email == "" {
+		return f, badRequest(ScimTypeInvalidValue, "userName is required")
+	}
+
+	if in.Name != nil {
+		f.firstName, f.lastName = strings.TrimSpace(in.Name.GivenName), strings.TrimSpace(in.Name.FamilyName)
+	}
+	if f.firstName == "" && f.lastName == "" {
+		// Employees need a name; fall back to the display name, then the userName
+		full := f.displayName.String
+		if full == "" {
+			full = f.email
+		}
+		f.firstName = full
+		if i := strings.LastIndex(full, " "); i > 0 {
+			f.firstName, f.lastName = full[:i], full[i+1:]
+		}
+	}
+
+	f.workEmail = optionalText(in.workEmail())
+	if !f.workEmail.Valid {
+		f.workEmail = optionalText(f.email)
+	}
+
+	if current != nil {
+		f.isActive = current.IsActive
+		f.employeeNo = current.EmployeeNo
+		f.managerID = current.ManagerID
+	}
+	if in.Active != nil {
+		f.isActive = bool(*in.Active)
+	}
+
+	if in.Enterprise != nil {
+		if n := strings.TrimSpace(in.Enterprise.EmployeeNumber); n != "" {
+			f.employeeNo = n
+		}
+		f.managerID = pgtype.UUID{}
+		if in.Enterprise.Manager != nil && in.Enterprise.Manager.Value != "" {
+			manager, err := resolveUser(ctx, q, tenantID, in.Enterprise.Manager.Value, "manager")
+			if err != nil {
+				return f, err
+			}
+			f.managerID = manager.EmployeeID
+		}
+	}
+	if f.employeeNo == "" {
+		f.employeeNo = newEmployeeNo()
+	}
+
+	if in.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return f, fmt.Errorf("hashing password: %w", err)
+		}
+		f.passwordHash = pgtype.Text{String: string(hash), Valid: true}
+	}
+	return f, nil
+}
+
+// newEmployeeNo numbers employees provisioned without an employeeNumber
+func newEmployeeNo() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "SCIM-" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// CreateUser provisions an employee and its user account together, as onboarding does,
+// so the 1:1 pairing holds. Roles are granted through group membership. SCIM carries no
+// custom field values, so required custom fields are left unset, as for SSO provisioning.
+func (s *ScimService) CreateUser(ctx context.Context, tenantID pgtype.UUID, in User) (User, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return User{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	f, err := s.resolveUserFields(ctx, q, tenantID, in, nil)
+	if err != nil {
+		return User{}, err
+	}
+
+	employeeID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if _, err := q.CreateEmployee(ctx, domain.CreateEmployeeParams{
+		ID:          employeeID,
+		TenantID:    tenantID,
+		EmployeeNo:  f.employeeNo,
+		FirstName:   f.firstName,
+		LastName:    f.lastName,
+		DisplayName: f.displayName,
+		WorkEmail:   f.workEmail,
+		ManagerID:   f.managerID,
+	}); err != nil {
+		return User{}, mapError(err)
+	}
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if _, err := q.CreateUser(ctx, domain.CreateUserParams{
+		ID:           userID,
+		TenantID:     tenantID,
+		EmployeeID:   employeeID,
+		Email:        f.email,
+		DisplayName:  f.displayName,
+		PasswordHash: f.passwordHash,
+	}); err != nil {
+		return User{}, mapError(err)
+	}
+	if _, err := q.UpdateScimUser(ctx, domain.UpdateScimUserParams{
+		TenantID:    tenantID,
+		ID:          userID,
+		Email:       f.email,
+		DisplayName: f.displayName,
+		ExternalID:  f.externalID,
+		IsActive:    f.isActive,
+	}); err != nil {
+		return User{}, mapError(err)
+	}
+
+	row, err := q.GetScimUser(ctx, domain.GetScimUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return User{}, err
+	}
+
+	s.log(ctx, tenantID, "CREATE", "Employees", employeeID.Bytes, map[string]interface{}{
+		"employee_no": f.employeeNo,
+		"first_name":  f.firstName,
+		"last_name":   f.lastName,
+		"work_email":  f.workEmail.String,
+	})
+	s.log(ctx, tenantID, "CREATE", "Users", userID.Bytes, map[string]interface{}{
+		"email":       f.email,
+		"external_id": f.externalID.String,
+		"is_active":   f.isActive,
+	})
+
+	return toUser(domain.ListScimUsersRow(row), nil), nil
+}
+
+// ReplaceUser overwrites a user and its employee record with a full resource. The employee
+// number and manager are kept when the enterprise extension is left out. Erased users are
+// refused with 409, as their placeholders must not be overwritten with personal data again.
+func (s *ScimService) ReplaceUser(ctx context.Context, tenantID pgtype.UUID, id string, in User) (User, error) {
+	userID, err := parseID(id)
+	if err != nil {
+		return User{}, err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return User{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	current, err := q.GetScimUser(ctx, domain.GetScimUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return User{}, mapError(err)
+	}
+	row := domain.ListScimUsersRow(current)
+
+	// Locking the employee serialises the write with an erasure running at the same time
+	emp, err := q.GetEmployeeForUpdate(ctx, domain.GetEmployeeForUpdateParams{TenantID: tenantID, ID: row.EmployeeID})
+	if err != nil {
+		return User{}, mapError(err)
+	}
+	if emp.ErasedAt.Valid {
+		return User{}, errErased
+	}
+
+	f, err := s.resolveUserFields(ctx, q, tenantID, in, &row)
+	if err != nil {
+		return User{}, err
+	}
+	if err := s.updateUser(ctx, q, tenantID, row, f); err != nil {
+		return User{}, err
+	}
+
+	updated, err := q.GetScimUser(ctx, domain.GetScimUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return User{}, err
+	}
+	return s.loadUser(ctx, tenantID, domain.ListScimUsersRow(updated))
+}
+
+// PatchUser applies PATCH operations to the current resource and stores the result
+func (s *ScimService) PatchUser(ctx context.Context, tenantID pgtype.UUID, id string, req PatchRequest) (User, error) {
+	current, err := s.GetUser(ctx, tenantID, id)
+	if err != nil {
+		return User{}, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return User{}, err
+	}
+	if err := applyPatch(m, req); err != nil {
+		return User{}, err
+	}
+
+	var patched User
+	if err := fromMap(m, &patched); err != nil {
+		return User{}, err
+	}
+	return s.ReplaceUser(ctx, tenantID, id, patched)
+}
+
+func (s *ScimService) updateUser(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, row domain.ListScimUsersRow, f userFields) error {
+	if _, err := q.UpdateScimEmployee(ctx, domain.UpdateScimEmployeeParams{
+		TenantID:    tenantID,
+		ID:          row.EmployeeID,
+		EmployeeNo:  f.employeeNo,
+		FirstName:   f.firstName,
+		LastName:    f.lastName,
+		DisplayName: f.displayName,
+		WorkEmail:   f.workEmail,
+		ManagerID:   f.managerID,
+	}); err != nil {
+		return mapError(err)
+	}
+	if _, err := q.UpdateScimUser(ctx, domain.UpdateScimUserParams{
+		TenantID:     tenantID,
+		ID:           row.ID,
+		Email:        f.email,
+		DisplayName:  f.displayName,
+		ExternalID:   f.externalID,
+		IsActive:     f.isActive,
+		PasswordHash: f.passwordHash,
+	}); err != nil {
+		return mapError(err)
+	}
+
+	employeeChanges := map[string]interface{}{}
+	changed(employeeChanges, "employee_no", row.EmployeeNo, f.employeeNo)
+	changed(employeeChanges, "first_name", row.FirstName, f.firstName)
+	changed(employeeChanges, "last_name", row.LastName, f.lastName)
+	changed(employeeChanges, "work_email", row.WorkEmail.String, f.workEmail.String)
+	changed(employeeChanges, "manager_id", row.ManagerID, f.managerID)
+	if len(employeeChanges) > 0 {
+		s.log(ctx, tenantID, "UPDATE", "Employees", row.EmployeeID.Bytes, employeeChanges)
+	}
+
+	userChanges := map[string]interface{}{}
+	changed(userChanges, "email", row.Email, f.email)
+	changed(userChanges, "display_name", row.DisplayName.String, f.displayName.String)
+	changed(userChanges, "external_id", row.ExternalID.String, f.externalID.String)
+	changed(userChanges, "is_active", row.IsActive, f.isActive)
+	if f.passwordHash.Valid {
+		userChanges["password"] = "changed"
+	}
+	if len(userChanges) > 0 {
+		s.log(ctx, tenantID, "UPDATE", "Users", row.ID.Bytes, userChanges)
+	}
+	return nil
+}
+
+// DeleteUser deprovisions a user: the account is deactivated and its roles revoked. The
+// employee record stays, as HR data outlives accounts, so the user remains readable with
+// active set to false.
+func (s *ScimService) DeleteUser(ctx context.Context, tenantID pgtype.UUID, id string) error {
+	userID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	current, err := q.GetScimUser(ctx, domain.GetScimUserParams{TenantID: tenantID, ID: userID})
+	if err != nil {
+		return mapError(err)
+	}
+	if _, err := q.UpdateScimUser(ctx, domain.UpdateScimUserParams{
+		TenantID:    tenantID,
+		ID:          userID,
+		Email:       current.Email,
+		DisplayName: current.DisplayName,
+		ExternalID:  current.ExternalID,
+		IsActive:    false,
+	}); err != nil {
+		return err
+	}
+	revoked, err := q.RevokeAllUserRoles(ctx, domain.RevokeAllUserRolesParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if current.IsActive {
+		s.log(ctx, tenantID, "UPDATE", "Users", userID.Bytes, map[string]interface{}{
+			"is_active": map[string]interface{}{"from": true, "to": false},
+		})
+	}
+	if revoked > 0 {
+		s.log(ctx, tenantID, "DELETE", "UserRoles", userID.Bytes, map[string]interface{}{
+			"all_roles": true,
+		})
+	}
+	return nil
+}
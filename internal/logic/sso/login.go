@@ -0,0 +1,504 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	authLogic "github.com/INOVA/DML/internal/logic/auth"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StateTTL is how long a user has to complete sign-in at the provider
+const StateTTL = 10 * time.Minute
+
+// auditSource tags audit entries written while signing a user in
+const auditSource = "sso"
+
+var (
+	// ErrInvalidState is returned when a callback's state is unknown, expired or already used
+	ErrInvalidState = errors.New("sign-in request is invalid or has expired")
+	// ErrProvider is returned when the identity provider cannot be reached or its response
+	// does not verify
+	ErrProvider = errors.New("identity provider sign-in failed")
+	// ErrNoAccount is returned when the external identity matches no user and just-in-time
+	// provisioning is off
+	ErrNoAccount = errors.New("no account is linked to this identity")
+	// ErrAccountDisabled is returned when the linked user is deactivated
+	ErrAccountDisabled = errors.New("account is disabled")
+)
+
+// LoginResult is the outcome of a completed sign-in at the provider: a session, or an MFA
+// challenge as after the password step, and where to hand it to
+type LoginResult struct {
+	authLogic.LoginResult
+	ReturnTo string
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// activeProvider resolves a tenant code to the tenant's active provider
+func (s *SSOService) activeProvider(ctx context.Context, tenantCode string) (domain.SsoProvider, error) {
+	tenant, err := s.queries.GetTenantByCode(ctx, tenantCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.SsoProvider{}, ErrNotConfigured
+	}
+	if err != nil {
+		return domain.SsoProvider{}, err
+	}
+	p, err := s.queries.GetSsoProvider(ctx, tenant.ID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !p.IsActive) {
+		return domain.SsoProvider{}, ErrNotConfigured
+	}
+	return p, err
+}
+
+// Start begins an authorization code flow with PKCE and returns the provider URL to send
+// the browser to. returnTo is where the callback hands the session token over. The returned
+// binding must be kept by the browser, e.g. in a cookie, and passed back to Callback, so
+// that a callback link started by someone else cannot sign the browser into their account.
+func (s *SSOService) Start(ctx context.Context, tenantCode, redirectURI, returnTo string) (authURL, binding string, err error) {
+	p, err := s.activeProvider(ctx, tenantCode)
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := s.oidc.Discover(ctx, p.Issuer)
+	if err != nil {
+		log.Printf("sso discovery failed for %s: %v", p.Issuer, err)
+		return "", "", ErrProvider
+	}
+
+	state, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	binding, err = randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.queries.DeleteExpiredSsoLoginStates(ctx); err != nil {
+		log.Printf("sso failed pruning login states: %v", err)
+	}
+	if err := s.queries.CreateSsoLoginState(ctx, domain.CreateSsoLoginStateParams{
+		State:        state,
+		TenantID:     p.TenantID,
+		ProviderID:   p.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     pgtype.Text{String: returnTo, Valid: returnTo != ""},
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(StateTTL), Valid: true},
+		BrowserHash:  hashBinding(binding),
+	}); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", p.Scopes)
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", pkceChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), binding, nil
+}
+
+// Callback completes a sign-in: it redeems the code, verifies the ID token, resolves the
+// user and syncs group-mapped roles, then issues a session token or, when the user has or
+// needs a second factor, an MFA challenge. binding is the value Start returned to the
+// browser that began the sign-in; a state presented with any other binding is refused.
+func (s *SSOService) Callback(ctx context.Context, tenantCode, redirectURI, code, state, binding string) (LoginResult, error) {
+	if binding == "" {
+		return LoginResult{}, ErrInvalidState
+	}
+
+	tenant, err := s.queries.GetTenantByCode(ctx, tenantCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LoginResult{}, ErrInvalidState
+	}
+	if err != nil {
+		return LoginResult{}, err
+	}
+	pending, err := s.queries.ConsumeSsoLoginState(ctx, domain.ConsumeSsoLoginStateParams{
+		State:       state,
+		TenantID:    tenant.ID,
+		BrowserHash: hashBinding(binding),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LoginResult{}, ErrInvalidState
+	}
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	p, err := s.activeProvider(ctx, tenantCode)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if p.ID != pending.ProviderID {
+		// The provider was reconfigured mid sign-in
+		return LoginResult{}, ErrInvalidState
+	}
+
+	doc, err := s.oidc.Discover(ctx, p.Issuer)
+	if err != nil {
+		log.Printf("sso discovery failed for %s: %v", p.Issuer, err)
+		return LoginResult{}, ErrProvider
+	}
+	idToken, err := s.oidc.Exchange(ctx, doc, p.ClientID, p.ClientSecret, code, redirectURI, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("sso code exchange failed for %s: %v", p.Issuer, err)
+		return LoginResult{}, ErrProvider
+	}
+	claims, err := s.oidc.VerifyIDToken(ctx, doc, p.ClientID, pending.Nonce, idToken)
+	if err != nil {
+		log.Printf("sso id token rejected for %s: %v", p.Issuer, err)
+		return LoginResult{}, ErrProvider
+	}
+	if claimString(claims, "sub") == "" {
+		log.Printf("sso id token from %s has no subject", p.Issuer)
+		return LoginResult{}, ErrProvider
+	}
+
+	user, err := s.signIn(ctx, p, claims)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	// The provider's sign-in stands in for the password only; the second factor is still ours
+	session, err := s.authSvc.CompleteLogin(ctx, user)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return LoginResult{LoginResult: session, ReturnTo: pending.ReturnTo.String}, nil
+}
+
+// signIn resolves the user for a verified identity and syncs their mapped roles
+func (s *SSOService) signIn(ctx context.Context, p domain.SsoProvider, claims jwt.MapClaims) (domain.User, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.User{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := domain.New(tx)
+
+	var logs []func()
+	user, err := s.resolveUser(ctx, q, p, claims, &logs)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !user.IsActive {
+		return domain.User{}, ErrAccountDisabled
+	}
+	if err := s.syncRoles(ctx, q, p, user, claimStrings(claims, p.GroupsClaim), &logs); err != nil {
+		return domain.User{}, err
+	}
+	if err := q.TouchUserLogin(ctx, user.ID); err != nil {
+		return domain.User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	for _, l := range logs {
+		l()
+	}
+	return user, nil
+}
+
+// emailVerified reports whether the provider vouches for the email claim. Only an explicit
+// email_verified of true counts; a missing claim could be any address the user typed in.
+func emailVerified(claims jwt.MapClaims) bool {
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// privileged reports whether the roles grant anything beyond a plain account. Such users
+// are never linked by email, as whoever controls the address at the provider would
+// inherit their rights. They keep signing in with a password.
+func privileged(roles []string) bool {
+	for _, role := range roles {
+		if role == "ADMIN" {
+			return true
+		}
+	}
+	return len(iam.Permissions(roles)) > 0
+}
+
+// pkceChallenge derives the S256 code challenge sent with the authorization request
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// hashBinding derives what is stored of a browser binding, so the login state table alone
+// cannot be used to complete someone's sign-in
+func hashBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveUser finds the user for an external identity: by linked subject first, then by
+// verified email, and finally by provisioning a new employee when the provider allows it
+func (s *SSOService) resolveUser(ctx context.Context, q *domain.Queries, p domain.SsoProvider, claims jwt.MapClaims, logs *[]func()) (domain.User, error) {
+	subject := claimString(claims, "sub")
+	email := strings.TrimSpace(claimString(claims, "email"))
+
+	identity, err := q.GetSsoIdentity(ctx, domain.GetSsoIdentityParams{ProviderID: p.ID, Subject: subject})
+	if err == nil {
+		if err := q.TouchSsoIdentity(ctx, domain.TouchSsoIdentityParams{
+			ID:    identity.ID,
+			Email: pgtype.Text{String: email, Valid: email != ""},
+		}); err != nil {
+			return domain.User{}, err
+		}
+		return q.GetUser(ctx, domain.GetUserParams{TenantID: p.TenantID, ID: identity.UserID})
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return domain.User{}, err
+	}
+
+	if email == "" || !emailVerified(claims) {
+		return domain.User{}, ErrNoAccount
+	}
+	user, err := q.GetUserByEmailFold(ctx, domain.GetUserByEmailFoldParams{TenantID: p.TenantID, Email: email})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if !p.JitProvisioning {
+			return domain.User{}, ErrNoAccount
+		}
+		user, err = s.provision(ctx, q, p, claims, email, logs)
+	} else if err == nil {
+		roles, err := q.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: p.TenantID, UserID: user.ID})
+		if err != nil {
+			return domain.User{}, err
+		}
+		if privileged(roles) {
+			log.Printf("sso refused to link %s to privileged user %s by email", subject, uuid.UUID(user.ID.Bytes))
+			return domain.User{}, ErrNoAccount
+		}
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	identityID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if _, err := q.CreateSsoIdentity(ctx, domain.CreateSsoIdentityParams{
+		ID:         identityID,
+		TenantID:   p.TenantID,
+		ProviderID: p.ID,
+		Subject:    subject,
+		UserID:     user.ID,
+		Email:      pgtype.Text{String: email, Valid: true},
+	}); err != nil {
+		return domain.User{}, err
+	}
+	*logs = append(*logs, func() {
+		s.log(ctx, p.TenantID, "CREATE", "SsoIdentities", identityID.Bytes, map[string]interface{}{
+			"user_id": user.ID,
+			"subject": subject,
+			"issuer":  p.Issuer,
+		})
+	})
+	return user, nil
+}
+
+// names picks first and last names from the standard claims, falling back to the full
+// name and then the email's local part
+func names(claims jwt.MapClaims, email string) (string, string) {
+	first := strings.TrimSpace(claimString(claims, "given_name"))
+	last := strings.TrimSpace(claimString(claims, "family_name"))
+	if first != "" && last != "" {
+		return first, last
+	}
+	if parts := strings.Fields(claimString(claims, "name")); len(parts) > 1 {
+		return strings.Join(parts[:len(parts)-1], " "), parts[len(parts)-1]
+	}
+	local := email
+	if at := strings.Index(email, "@"); at > 0 {
+		local = email[:at]
+	}
+	if first == "" {
+		first = local
+	}
+	if last == "" {
+		last = "-"
+	}
+	return first, last
+}
+
+func newEmployeeNo() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "SSO-" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// provision creates the user for a first sign-in, attaching it to an employee with the same
+// work email that has no account yet, or to a new employee otherwise. The provider sends no
+// custom field values, so required custom fields are left for HR to fill in: they bind the
+// records HR enters, not those identity providers create.
+func (s *SSOService) provision(ctx context.Context, q *domain.Queries, p domain.SsoProvider, claims jwt.MapClaims, email string, logs *[]func()) (domain.User, error) {
+	first, last := names(claims, email)
+	displayName := strings.TrimSpace(claimString(claims, "name"))
+	if displayName == "" {
+		displayName = first + " " + last
+	}
+
+	var employeeID pgtype.UUID
+	existing, err := q.GetUnlinkedEmployeeByEmail(ctx, domain.GetUnlinkedEmployeeByEmailParams{TenantID: p.TenantID, Email: email})
+	switch {
+	case err == nil:
+		employeeID = existing.ID
+	case errors.Is(err, pgx.ErrNoRows):
+		employeeID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+		employeeNo := newEmployeeNo()
+		if _, err := q.CreateEmployee(ctx, domain.CreateEmployeeParams{
+			ID:          employeeID,
+			TenantID:    p.TenantID,
+			EmployeeNo:  employeeNo,
+			FirstName:   first,
+			LastName:    last,
+			DisplayName: pgtype.Text{String: displayName, Valid: true},
+			WorkEmail:   pgtype.Text{String: email, Valid: true},
+		}); err != nil {
+			return domain.User{}, err
+		}
+		*logs = append(*logs, func() {
+			s.log(ctx, p.TenantID, "CREATE", "Employees", employeeID.Bytes, map[string]interface{}{
+				"employee_no": employeeNo,
+				"first_name":  first,
+				"last_name":   last,
+				"work_email":  email,
+			})
+		})
+	default:
+		return domain.User{}, err
+	}
+
+	user, err := q.CreateUser(ctx, domain.CreateUserParams{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TenantID:    p.TenantID,
+		EmployeeID:  employeeID,
+		Email:       email,
+		DisplayName: pgtype.Text{String: displayName, Valid: true},
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	*logs = append(*logs, func() {
+		s.log(ctx, p.TenantID, "CREATE", "Users", user.ID.Bytes, map[string]interface{}{
+			"email":       email,
+			"employee_id": employeeID,
+		})
+	})
+	return user, nil
+}
+
+// protectedRoleCode is the role a sign-in never revokes from its last active holder, so a
+// change of groups at the provider cannot lock every administrator out of the tenant
+const protectedRoleCode = "ADMIN"
+
+// syncRoles makes the user's tenant-wide grants of provider-managed roles match their
+// groups. Roles no mapping mentions, and scoped grants, are left alone.
+func (s *SSOService) syncRoles(ctx context.Context, q *domain.Queries, p domain.SsoProvider, user domain.User, groups []string, logs *[]func()) error {
+	mappings, err := q.ListSsoGroupMappings(ctx, domain.ListSsoGroupMappingsParams{
+		TenantID:   p.TenantID,
+		ProviderID: p.ID,
+	})
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+	wanted := make(map[[16]byte]bool)
+	codes := make(map[[16]byte]string)
+	for _, m := range mappings {
+		codes[m.RoleID.Bytes] = m.RoleCode
+		if member[m.GroupName] {
+			wanted[m.RoleID.Bytes] = true
+		}
+	}
+
+	for roleID, code := range codes {
+		role := pgtype.UUID{Bytes: roleID, Valid: true}
+		action := "DELETE"
+		var rows int64
+		if wanted[roleID] {
+			action = "CREATE"
+			rows, err = q.GrantUnscopedRole(ctx, domain.GrantUnscopedRoleParams{TenantID: p.TenantID, UserID: user.ID, RoleID: role})
+		} else {
+			if code == protectedRoleCode {
+				others, err := q.CountOtherActiveRoleHolders(ctx, domain.CountOtherActiveRoleHoldersParams{TenantID: p.TenantID, RoleID: role, UserID: user.ID})
+				if err != nil {
+					return err
+				}
+				if others == 0 {
+					log.Printf("sso left %s in place for user %s: no other active user holds it", code, uuid.UUID(user.ID.Bytes))
+					continue
+				}
+			}
+			rows, err = q.RevokeUnscopedRole(ctx, domain.RevokeUnscopedRoleParams{TenantID: p.TenantID, UserID: user.ID, RoleID: role})
+		}
+		if err != nil {
+			return err
+		}
+		if rows > 0 {
+			*logs = append(*logs, func() {
+				s.log(ctx, p.TenantID, action, "UserRoles", user.ID.Bytes, map[string]interface{}{
+					"role_id":   role,
+					"role_code": code,
+				})
+			})
+		}
+	}
+	return nil
+}
+
+// log writes an audit entry on behalf of the identity provider, with no actor
+func (s *SSOService) log(ctx context.Context, tenantID pgtype.UUID, action, entityType string, entityID [16]byte, changes map[string]interface{}) {
+	if s.auditSvc == nil {
+		return
+	}
+	changes["source"] = auditSource
+	s.auditSvc.Log(ctx, tenantID, pgtype.UUID{}, action, entityType, entityID, changes)
+}
@@ -0,0 +1,315 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// metadataTTL is how long discovery documents and key sets are cached. Keys are refetched
+// early when a token is signed with a key id that is not in the cached set.
+const metadataTTL = time.Hour
+
+// keyRefetchInterval limits how often an unknown key id forces a key set refetch
+const keyRefetchInterval = time.Minute
+
+// discovery is the part of an OpenID Provider's configuration document this service uses
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type cachedDiscovery struct {
+	doc     discovery
+	fetched time.Time
+}
+
+type cachedKeys struct {
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+// oidcClient talks to OpenID Providers: discovery, the token endpoint and key sets
+type oidcClient struct {
+	http *http.Client
+
+	mu        sync.Mutex
+	discovery map[string]cachedDiscovery
+	keys      map[string]cachedKeys
+}
+
+func newOIDCClient(timeout time.Duration) *oidcClient {
+	return &oidcClient{
+		http:      &http.Client{Timeout: timeout},
+		discovery: make(map[string]cachedDiscovery),
+		keys:      make(map[string]cachedKeys),
+	}
+}
+
+func (c *oidcClient) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// Discover fetches the provider configuration from the issuer's well-known location
+func (c *oidcClient) Discover(ctx context.Context, issuer string) (discovery, error) {
+	c.mu.Lock()
+	cached, ok := c.discovery[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetched) < metadataTTL {
+		return cached.doc, nil
+	}
+
+	var doc discovery
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return discovery{}, err
+	}
+	if doc.Issuer != issuer {
+		return discovery{}, fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return discovery{}, errors.New("discovery document is missing endpoints")
+	}
+
+	c.mu.Lock()
+	c.discovery[issuer] = cachedDiscovery{doc: doc, fetched: time.Now()}
+	c.mu.Unlock()
+	return doc, nil
+}
+
+// tokenResponse is the token endpoint's reply to an authorization code exchange
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems an authorization code for an ID token, authenticating with
+// client_secret_basic and proving possession of the PKCE verifier
+func (c *oidcClient) Exchange(ctx context.Context, doc discovery, clientID, clientSecret, code, redirectURI, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return "", fmt.Errorf("token endpoint: status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || out.Error != "" {
+		return "", fmt.Errorf("token endpoint: status %d: %s %s", resp.StatusCode, out.Error, out.ErrorDescription)
+	}
+	if out.IDToken == "" {
+		return "", errors.New("token endpoint returned no id_token")
+	}
+	return out.IDToken, nil
+}
+
+// VerifyIDToken checks an ID token's signature against the provider's keys along with its
+// issuer, audience, expiry and nonce, and returns its claims
+func (c *oidcClient) VerifyIDToken(ctx context.Context, doc discovery, clientID, nonce, idToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, doc.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, errors.New("id token nonce does not match")
+	}
+	return claims, nil
+}
+
+// key returns the verification key with the given id, refetching the key set when the id
+// is unknown so provider key rotation is picked up
+func (c *oidcClient) key(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	c.mu.Lock()
+	cached, ok := c.keys[jwksURI]
+	c.mu.Unlock()
+
+	fresh := ok && time.Since(cached.fetched) < metadataTTL
+	if fresh {
+		if k := pickKey(cached.keys, kid); k != nil {
+			return k, nil
+		}
+	}
+	if ok && time.Since(cached.fetched) < keyRefetchInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	c.mu.Lock()
+	c.keys[jwksURI] = cachedKeys{keys: keys, fetched: time.Now()}
+	c.mu.Unlock()
+
+	if k := pickKey(keys, kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// pickKey finds a key by id. Tokens without a key id are accepted when the set holds a
+// single key, as some providers omit it.
+func pickKey(keys map[string]interface{}, kid string) interface{} {
+	if k, ok := keys[kid]; ok {
+		return k
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k
+		}
+	}
+	return nil
+}
+
+// jwk is a JSON Web Key (RFC 7517) holding an RSA or EC public key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// claimString reads a string claim, returning "" when it is absent or not a string
+func claimString(claims jwt.MapClaims, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// claimStrings reads a claim holding a list of strings or a single string, as providers
+// differ in how they send groups
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "dml-client"
+	testClientSecret = "s3cret/with+symbols"
+	testCode         = "auth-code"
+	testNonce        = "nonce-123"
+)
+
+// mockProvider is a minimal OpenID Provider: discovery, a JWKS endpoint and a token
+// endpoint that redeems one authorization code bound to a PKCE challenge
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PrivateKey
+	jwksHits  int
+	challenge string
+	idToken   string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	p := &mockProvider{t: t, keys: make(map[string]*rsa.PrivateKey)}
+	p.addKey("k1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery{
+			Issuer:                p.issuer(),
+			AuthorizationEndpoint: p.issuer() + "/authorize",
+			TokenEndpoint:         p.issuer() + "/token",
+			JWKSURI:               p.issuer() + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jwksHits++
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		for kid, k := range p.keys {
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		if !ok || id != testClientID || secret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_client"})
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		challenge, idToken := p.challenge, p.idToken
+		p.mu.Unlock()
+		if r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("code") != testCode ||
+			pkceChallenge(r.PostForm.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(tokenResponse{IDToken: idToken})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockProvider) issuer() string { return p.server.URL }
+
+func (p *mockProvider) keySetFetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksHits
+}
+
+func (p *mockProvider) addKey(kid string) {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.keys[kid] = k
+	p.mu.Unlock()
+}
+
+// sign issues an ID token with standard claims for the test client, overridden by extra
+func (p *mockProvider) sign(kid string, extra jwt.MapClaims) string {
+	p.t.Helper()
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.issuer(),
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          testNonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	p.mu.Lock()
+	key := p.keys[kid]
+	p.mu.Unlock()
+	s, err := tok.SignedString(key)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return s
+}
+
+func TestDiscover(t *testing.T) {
+	p := newMockProvider(t)
+	c := newOIDCClient(5 * time.Second)
+
+	doc, err := c.Discover(context.Background(), p.issuer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.TokenEndpoint != p.issuer()+"/token" || doc.JWKSURI != p.issuer()+"/jwks" {
+		t.Fatalf("Discover() = %+v", doc)
+	}
+
+	// The document must name the issuer it was fetched for
+	if _, err := c.Discover(context.Background(), p.issuer()+"/other"); err == nil {
+		t.Fatal("Discover() accepted a document for a different issuer")
+	}
+}
+
+func TestExchangeSendsPKCEVerifierAndClientSecret(t *testing.T) {
+	p := newMockProvider(t)
+	c := newOIDCClient(5 * time.Second)
+	doc, err := c.Discover(context.Background(), p.issuer())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idToken := p.sign("k1", nil)
+	p.mu.Lock()
+	p.challenge, p.idToken = pkceChallenge(verifier), idToken
+	p.mu.Unlock()
+
+	got, err := c.Exchange(context.Background(), doc, testClientID, testClientSecret, testCode, "https://dml.example/cb", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != idToken {
+		t.Fatal("Exchange() did not return the provider's id_token")
+	}
+
+	if _, err := c.Exchange(context.Background(), doc, testClientID, testClientSecret, testCode, "https://dml.example/cb", "wrong-verifier"); err == nil {
+		t.Fatal("Exchange() succeeded with the wrong PKCE verifier")
+	}
+	if _, err := c.Exchange(context.Background(), doc, testClientID, "wrong", testCode, "https://dml.example/cb", verifier); err == nil {
+		t.Fatal("Exchange() succeeded with the wrong client secret")
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	got := pkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Fatalf("pkceChallenge() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	p := newMockProvider(t)
+	c := newOIDCClient(5 * time.Second)
+	doc, err := c.Discover(context.Background(), p.issuer())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := c.VerifyIDToken(context.Background(), doc, testClientID, testNonce, p.sign("k1", nil))
+	if err != nil {
+		t.Fatalf("VerifyIDToken() rejected a valid token: %v", err)
+	}
+	if claimString(claims, "sub") != "user-1" {
+		t.Fatalf("sub = %q, want user-1", claimString(claims, "sub"))
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": p.issuer(), "sub": "user-1", "aud": testClientID, "nonce": testNonce,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	forged.Header["kid"] = "k1"
+	forgedToken, err := forged.SignedString(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"wrong nonce":    p.sign("k1", jwt.MapClaims{"nonce": "replayed"}),
+		"no nonce":       p.sign("k1", jwt.MapClaims{"nonce": nil}),
+		"wrong audience": p.sign("k1", jwt.MapClaims{"aud": "another-client"}),
+		"wrong issuer":   p.sign("k1", jwt.MapClaims{"iss": "https://evil.example"}),
+		"expired":        p.sign("k1", jwt.MapClaims{"exp": time.Now().Add(-10 * time.Minute).Unix()}),
+		"no expiry":      p.sign("k1", jwt.MapClaims{"exp": nil}),
+		"forged":         forgedToken,
+		"unsigned":       unsignedToken(t, p.issuer()),
+	}
+	for name, token := range cases {
+		if _, err := c.VerifyIDToken(context.Background(), doc, testClientID, testNonce, token); err == nil {
+			t.Errorf("%s: VerifyIDToken() accepted the token", name)
+		}
+	}
+}
+
+func unsignedToken(t *testing.T, issuer string) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": issuer, "sub": "user-1", "aud": testClientID, "nonce": testNonce,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	s, err := tok.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyIDTokenPicksUpRotatedKeys(t *testing.T) {
+	p := newMockProvider(t)
+	c := newOIDCClient(5 * time.Second)
+	ctx := context.Background()
+	doc, err := c.Discover(ctx, p.issuer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.VerifyIDToken(ctx, doc, testClientID, testNonce, p.sign("k1", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A key published after the set was cached is only fetched once the refetch interval
+	// has passed, so a flood of made-up key ids cannot hammer the provider
+	p.addKey("k2")
+	if _, err := c.VerifyIDToken(ctx, doc, testClientID, testNonce, p.sign("k2", nil)); err == nil {
+		t.Fatal("VerifyIDToken() refetched the key set within the refetch interval")
+	}
+	if n := p.keySetFetches(); n != 1 {
+		t.Fatalf("key set fetched %d times, want 1", n)
+	}
+
+	c.mu.Lock()
+	cached := c.keys[doc.JWKSURI]
+	cached.fetched = time.Now().Add(-2 * keyRefetchInterval)
+	c.keys[doc.JWKSURI] = cached
+	c.mu.Unlock()
+
+	if _, err := c.VerifyIDToken(ctx, doc, testClientID, testNonce, p.sign("k2", nil)); err != nil {
+		t.Fatalf("VerifyIDToken() rejected a token signed with a rotated key: %v", err)
+	}
+	if n := p.keySetFetches(); n != 2 {
+		t.Fatalf("key set fetched %d times, want 2", n)
+	}
+}
+
+func TestEmailVerified(t *testing.T) {
+	cases := []struct {
+		claim interface{}
+		want  bool
+	}{
+		{true, true},
+		{"true", true},
+		{"TRUE", true},
+		{false, false},
+		{"false", false},
+		{"yes", false},
+		{nil, false},
+	}
+	for _, tc := range cases {
+		claims := jwt.MapClaims{"email": "jane@example.com"}
+		if tc.claim != nil {
+			claims["email_verified"] = tc.claim
+		}
+		if got := emailVerified(claims); got != tc.want {
+			t.Errorf("emailVerified(%v) = %v, want %v", tc.claim, got, tc.want)
+		}
+	}
+}
+
+func TestPrivileged(t *testing.T) {
+	if privileged(nil) || privileged([]string{"EMPLOYEE"}) {
+		t.Error("plain roles reported as privileged")
+	}
+	for _, roles := range [][]string{{"ADMIN"}, {"HR_ADMIN"}, {"EMPLOYEE", "ADMIN"}} {
+		if !privileged(roles) {
+			t.Errorf("privileged(%v) = false, want true", roles)
+		}
+	}
+}
+
+func TestClaimStrings(t *testing.T) {
+	claims := jwt.MapClaims{
+		"one":   "admins",
+		"many":  []interface{}{"a", 1, "b"},
+		"other": 5,
+	}
+	if got := claimStrings(claims, "one"); strings.Join(got, ",") != "admins" {
+		t.Errorf("claimStrings(one) = %v", got)
+	}
+	if got := claimStrings(claims, "many"); strings.Join(got, ",") != "a,b" {
+		t.Errorf("claimStrings(many) = %v", got)
+	}
+	if got := claimStrings(claims, "other"); got != nil {
+		t.Errorf("claimStrings(other) = %v", got)
+	}
+}
@@ -0,0 +1,302 @@
+// Package sso signs users in through their tenant's OpenID Connect provider, linking
+// external subjects to users, optionally provisioning employees just in time and mapping
+// provider groups onto roles.
+package sso
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/audit"
+	authLogic "github.com/INOVA/DML/internal/logic/auth"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrNotConfigured is returned when a tenant has no active identity provider
+	ErrNotConfigured = errors.New("single sign-on is not configured for this tenant")
+	// ErrClientSecretRequired is returned when a provider is first configured without a secret
+	ErrClientSecretRequired = errors.New("clientSecret is required")
+	// ErrInvalidIssuer is returned when the issuer is not an absolute https URL
+	ErrInvalidIssuer = errors.New("issuer must be an https URL")
+	// ErrRoleExceedsActor is returned when a group is mapped to a role granting a permission
+	// the administrator creating the mapping does not hold
+	ErrRoleExceedsActor = errors.New("you cannot map a group to a role with permissions you do not hold")
+)
+
+// Provider is the API representation of a tenant's identity provider. The client secret is
+// write-only.
+type Provider struct {
+	ID              pgtype.UUID        `json:"id"`
+	Protocol        string             `json:"protocol"`
+	Issuer          string             `json:"issuer"`
+	ClientID        string             `json:"clientId"`
+	HasClientSecret bool               `json:"hasClientSecret"`
+	Scopes          string             `json:"scopes"`
+	GroupsClaim     string             `json:"groupsClaim"`
+	JitProvisioning bool               `json:"jitProvisioning"`
+	IsActive        bool               `json:"isActive"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func toProvider(p domain.SsoProvider) Provider {
+	return Provider{
+		ID:              p.ID,
+		Protocol:        p.Protocol,
+		Issuer:          p.Issuer,
+		ClientID:        p.ClientID,
+		HasClientSecret: p.ClientSecret != "",
+		Scopes:          p.Scopes,
+		GroupsClaim:     p.GroupsClaim,
+		JitProvisioning: p.JitProvisioning,
+		IsActive:        p.IsActive,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+}
+
+// GroupMapping is the API representation of a provider group granting a role
+type GroupMapping struct {
+	ID        pgtype.UUID        `json:"id"`
+	GroupName string             `json:"groupName"`
+	RoleID    pgtype.UUID        `json:"roleId"`
+	RoleCode  string             `json:"roleCode"`
+	RoleName  string             `json:"roleName"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
+// ProviderInput is the editable configuration of a provider. An empty ClientSecret keeps
+// the stored one.
+type ProviderInput struct {
+	Issuer          string
+	ClientID        string
+	ClientSecret    string
+	Scopes          string
+	GroupsClaim     string
+	JitProvisioning bool
+	IsActive        bool
+}
+
+type SSOService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	authSvc  *authLogic.AuthService
+	auditSvc *audit.AuditService
+	oidc     *oidcClient
+}
+
+// NewSSOService creates the service. Calls to identity providers time out after timeout.
+func NewSSOService(database *db.DB, authSvc *authLogic.AuthService, auditSvc *audit.AuditService, timeout time.Duration) *SSOService {
+	return &SSOService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		authSvc:  authSvc,
+		auditSvc: auditSvc,
+		oidc:     newOIDCClient(timeout),
+	}
+}
+
+// validIssuer accepts https URLs, and plain http for a provider on localhost during
+// development
+func validIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		return u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1"
+	}
+	return false
+}
+
+func (s *SSOService) GetProvider(ctx context.Context, tenantID pgtype.UUID) (Provider, error) {
+	p, err := s.queries.GetSsoProvider(ctx, tenantID)
+	if err != nil {
+		return Provider{}, err
+	}
+	return toProvider(p), nil
+}
+
+// SaveProvider creates or replaces the tenant's identity provider configuration
+func (s *SSOService) SaveProvider(ctx context.Context, id, tenantID, actorID pgtype.UUID, in ProviderInput) (Provider, error) {
+	issuer := strings.TrimSpace(in.Issuer)
+	if !validIssuer(issuer) {
+		return Provider{}, ErrInvalidIssuer
+	}
+
+	current, err := s.queries.GetSsoProvider(ctx, tenantID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return Provider{}, err
+	}
+	secret := in.ClientSecret
+	if secret == "" {
+		if !exists {
+			return Provider{}, ErrClientSecretRequired
+		}
+		secret = current.ClientSecret
+	}
+	if exists {
+		id = current.ID
+	}
+
+	scopes := strings.Join(strings.Fields(in.Scopes), " ")
+	if scopes == "" {
+		scopes = "openid email profile"
+	}
+	if !strings.Contains(" "+scopes+" ", " openid ") {
+		scopes = "openid " + scopes
+	}
+	groupsClaim := strings.TrimSpace(in.GroupsClaim)
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	p, err := s.queries.UpsertSsoProvider(ctx, domain.UpsertSsoProviderParams{
+		ID:              id,
+		TenantID:        tenantID,
+		Issuer:          issuer,
+		ClientID:        strings.TrimSpace(in.ClientID),
+		ClientSecret:    secret,
+		Scopes:          scopes,
+		GroupsClaim:     groupsClaim,
+		JitProvisioning: in.JitProvisioning,
+		IsActive:        in.IsActive,
+	})
+	if err != nil {
+		return Provider{}, err
+	}
+
+	if s.auditSvc != nil {
+		action := "CREATE"
+		if exists {
+			action = "UPDATE"
+		}
+		s.auditSvc.Log(ctx, tenantID, actorID, action, "SsoProviders", p.ID.Bytes, map[string]interface{}{
+			"issuer":           p.Issuer,
+			"client_id":        p.ClientID,
+			"client_secret":    in.ClientSecret != "",
+			"scopes":           p.Scopes,
+			"groups_claim":     p.GroupsClaim,
+			"jit_provisioning": p.JitProvisioning,
+			"is_active":        p.IsActive,
+		})
+	}
+	return toProvider(p), nil
+}
+
+// DeleteProvider removes the tenant's identity provider with its mappings and linked
+// identities. Users keep their accounts.
+func (s *SSOService) DeleteProvider(ctx context.Context, tenantID, actorID pgtype.UUID) error {
+	current, err := s.queries.GetSsoProvider(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.queries.DeleteSsoProvider(ctx, tenantID); err != nil {
+		return err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "SsoProviders", current.ID.Bytes, map[string]interface{}{
+			"issuer": current.Issuer,
+		})
+	}
+	return nil
+}
+
+func (s *SSOService) ListGroupMappings(ctx context.Context, tenantID pgtype.UUID) ([]GroupMapping, error) {
+	p, err := s.queries.GetSsoProvider(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListSsoGroupMappings(ctx, domain.ListSsoGroupMappingsParams{
+		TenantID:   tenantID,
+		ProviderID: p.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]GroupMapping, 0, len(rows))
+	for _, m := range rows {
+		items = append(items, GroupMapping(m))
+	}
+	return items, nil
+}
+
+// CreateGroupMapping grants a role to members of a provider group on their next sign-in.
+// The actor must hold every permission the role grants, as for any other grant.
+func (s *SSOService) CreateGroupMapping(ctx context.Context, id, tenantID, actorID pgtype.UUID, groupName string, roleID pgtype.UUID) (GroupMapping, error) {
+	p, err := s.queries.GetSsoProvider(ctx, tenantID)
+	if err != nil {
+		return GroupMapping{}, err
+	}
+	role, err := s.queries.GetRole(ctx, domain.GetRoleParams{TenantID: tenantID, ID: roleID})
+	if err != nil {
+		return GroupMapping{}, err
+	}
+	held, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{TenantID: tenantID, UserID: actorID})
+	if err != nil {
+		return GroupMapping{}, err
+	}
+	if !iam.Covers(held, []string{role.Code}) {
+		return GroupMapping{}, ErrRoleExceedsActor
+	}
+
+	m, err := s.queries.CreateSsoGroupMapping(ctx, domain.CreateSsoGroupMappingParams{
+		ID:         id,
+		TenantID:   tenantID,
+		ProviderID: p.ID,
+		GroupName:  strings.TrimSpace(groupName),
+		RoleID:     role.ID,
+	})
+	if err != nil {
+		return GroupMapping{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "SsoGroupMappings", id.Bytes, map[string]interface{}{
+			"group_name": m.GroupName,
+			"role_id":    role.ID,
+			"role_code":  role.Code,
+		})
+	}
+	return GroupMapping{
+		ID:        m.ID,
+		GroupName: m.GroupName,
+		RoleID:    role.ID,
+		RoleCode:  role.Code,
+		RoleName:  role.Name,
+		CreatedAt: m.CreatedAt,
+	}, nil
+}
+
+// DeleteGroupMapping stops a group granting a role. Grants already made are revoked on the
+// members' next sign-in only while another mapping still manages the role.
+func (s *SSOService) DeleteGroupMapping(ctx context.Context, tenantID, actorID, id pgtype.UUID) error {
+	rows, err := s.queries.DeleteSsoGroupMapping(ctx, domain.DeleteSsoGroupMappingParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "SsoGroupMappings", id.Bytes, nil)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/notify"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Notification kinds
+const (
+	NotificationTaskAssigned = notify.KindTaskAssigned
+)
+
+// NotificationService manages a user's in-app notifications and hands them to the mailer
+// for users who want them by email
+type NotificationService struct {
+	queries *domain.Queries
+	mailer  *notify.Mailer
+}
+
+func NewNotificationService(database *db.DB, mailer *notify.Mailer) *NotificationService {
+	return &NotificationService{
+		queries: domain.New(database.Pool),
+		mailer:  mailer,
+	}
+}
+
+// NotificationInput is the content of a notification and the entity it links to, if any
+type NotificationInput struct {
+	Kind       string
+	Title      string
+	Body       *string
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+// Notify sends an in-app notification to a single user
+func (s *NotificationService) Notify(ctx context.Context, tenantID, userID pgtype.UUID, in NotificationInput) error {
+	return s.notifyUser(ctx, s.queries, tenantID, userID, in)
+}
+
+// NotifyRole sends an in-app notification to every active user holding a role
+func (s *NotificationService) NotifyRole(ctx context.Context, tenantID pgtype.UUID, roleCode string, in NotificationInput) error {
+	return s.notifyRole(ctx, s.queries, tenantID, roleCode, in)
+}
+
+func (s *NotificationService) List(ctx context.Context, tenantID, userID pgtype.UUID, params query.PaginationParams, unreadOnly bool) ([]domain.Notification, int64, error) {
+	notifications, err := s.queries.ListNotifications(ctx, domain.ListNotificationsParams{
+		TenantID:   tenantID,
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+		Limit:      params.Limit(),
+		Offset:     params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountNotifications(ctx, domain.CountNotificationsParams{
+		TenantID:   tenantID,
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, tenantID, userID pgtype.UUID) (int64, error) {
+	return s.queries.CountNotifications(ctx, domain.CountNotificationsParams{
+		TenantID:   tenantID,
+		UserID:     userID,
+		UnreadOnly: true,
+	})
+}
+
+// SetRead marks one of the user's notifications read or unread
+func (s *NotificationService) SetRead(ctx context.Context, tenantID, userID, id pgtype.UUID, read bool) (domain.Notification, error) {
+	if read {
+		return s.queries.MarkNotificationRead(ctx, domain.MarkNotificationReadParams{
+			TenantID: tenantID,
+			UserID:   userID,
+			ID:       id,
+		})
+	}
+	return s.queries.MarkNotificationUnread(ctx, domain.MarkNotificationUnreadParams{
+		TenantID: tenantID,
+		UserID:   userID,
+		ID:       id,
+	})
+}
+
+// MarkAllRead marks every unread notification of the user read and returns how many changed
+func (s *NotificationService) MarkAllRead(ctx context.Context, tenantID, userID pgtype.UUID) (int64, error) {
+	return s.queries.MarkAllNotificationsRead(ctx, domain.MarkAllNotificationsReadParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+}
+
+// notifyUser delivers a notification on the channels the user has not opted out of
+func (s *NotificationService) notifyUser(ctx context.Context, q *domain.Queries, tenantID, userID pgtype.UUID, in NotificationInput) error {
+	pref, err := notify.PreferenceTx(ctx, q, tenantID, userID, in.Kind)
+	if err != nil {
+		return err
+	}
+
+	if pref.InApp {
+		var id pgtype.UUID
+		id.Bytes = uuid.New()
+		id.Valid = true
+
+		err := q.CreateNotification(ctx, domain.CreateNotificationParams{
+			ID:         id,
+			TenantID:   tenantID,
+			UserID:     userID,
+			Kind:       in.Kind,
+			Title:      in.Title,
+			Body:       optionalText(in.Body),
+			EntityType: pgtype.Text{String: in.EntityType, Valid: in.EntityType != ""},
+			EntityID:   in.EntityID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create notification: %w", err)
+		}
+	}
+
+	if pref.Email && s.mailer != nil {
+		data := notify.Data{
+			Title:      in.Title,
+			EntityType: in.EntityType,
+		}
+		if in.Body != nil {
+			data.Body = *in.Body
+		}
+		if in.EntityID.Valid {
+			data.EntityID = uuid.UUID(in.EntityID.Bytes).String()
+		}
+		if err := s.mailer.EnqueueTx(ctx, q, tenantID, userID, in.Kind, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyEmployee notifies the user account of an employee. Employees without an account are skipped.
+func (s *NotificationService) notifyEmployee(ctx context.Context, q *domain.Queries, tenantID, employeeID pgtype.UUID, in NotificationInput) error {
+	user, err := q.GetUserByEmployee(ctx, domain.GetUserByEmployeeParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to load assignee account: %w", err)
+	}
+	if !user.IsActive {
+		return nil
+	}
+	return s.notifyUser(ctx, q, tenantID, user.ID, in)
+}
+
+func (s *NotificationService) notifyRole(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, roleCode string, in NotificationInput) error {
+	userIDs, err := q.ListActiveRoleUserIDs(ctx, domain.ListActiveRoleUserIDsParams{
+		TenantID: tenantID,
+		Code:     roleCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load role holders: %w", err)
+	}
+	for _, userID := range userIDs {
+		if err := s.notifyUser(ctx, q, tenantID, userID, in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyAssignees tells the assignee of a new task about it
+func (s *NotificationService) notifyAssignees(ctx context.Context, q *domain.Queries, task domain.Task) error {
+	in := NotificationInput{
+		Kind:       NotificationTaskAssigned,
+		Title:      task.Title,
+		EntityType: task.EntityType,
+		EntityID:   task.EntityID,
+	}
+	if task.Description.Valid {
+		in.Body = &task.Description.String
+	}
+	if task.AssigneeEmployeeID.Valid {
+		return s.notifyEmployee(ctx, q, task.TenantID, task.AssigneeEmployeeID, in)
+	}
+	return s.notifyRole(ctx, q, task.TenantID, task.AssigneeRoleCode.String, in)
+}
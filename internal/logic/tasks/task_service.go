@@ -0,0 +1,340 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Task kinds
+const (
+	KindApproval        = "approval"
+	KindAcknowledgement = "acknowledgement"
+	KindReview          = "review"
+	KindAction          = "action"
+	KindOther           = "other"
+)
+
+// Task statuses
+const (
+	StatusOpen      = "open"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	// ErrAssigneeRequired is returned unless exactly one of an assignee employee or role is given
+	ErrAssigneeRequired = errors.New("a task is assigned to either an employee or a role")
+
+	// ErrEmployeeNotFound is returned when the assignee is not an employee of the tenant
+	ErrEmployeeNotFound = errors.New("employee does not exist or is inaccessible")
+
+	// ErrRoleNotFound is returned when the assignee role does not exist or is inactive
+	ErrRoleNotFound = errors.New("role does not exist or is inactive")
+
+	// ErrTaskClosed is returned when completing or cancelling a task that is no longer open
+	ErrTaskClosed = errors.New("task is already completed or cancelled")
+
+	// ErrNotAssignee is returned when someone the task is not assigned to tries to complete it
+	ErrNotAssignee = errors.New("task is not assigned to you")
+)
+
+type TaskService struct {
+	db              *db.DB
+	queries         *domain.Queries
+	notificationSvc *NotificationService
+	auditSvc        *audit.AuditService
+}
+
+func NewTaskService(database *db.DB, notificationSvc *NotificationService, auditSvc *audit.AuditService) *TaskService {
+	return &TaskService{
+		db:              database,
+		queries:         domain.New(database.Pool),
+		notificationSvc: notificationSvc,
+		auditSvc:        auditSvc,
+	}
+}
+
+// TaskInput describes a task about an entity. EntityType uses the audit log entity names,
+// e.g. "NCRActions". Exactly one of AssigneeEmployeeID and AssigneeRoleCode is set.
+type TaskInput struct {
+	EntityType         string
+	EntityID           pgtype.UUID
+	Kind               string
+	Title              string
+	Description        *string
+	AssigneeEmployeeID pgtype.UUID
+	AssigneeRoleCode   string
+	DueDate            *time.Time
+}
+
+// CreateTask assigns a task and notifies the assignee, or every user holding the assignee role
+func (s *TaskService) CreateTask(ctx context.Context, id, tenantID, actorID pgtype.UUID, in TaskInput) (domain.Task, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("failed to begin task transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	task, err := s.CreateTaskTx(ctx, domain.New(tx), id, tenantID, actorID, in)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Task{}, fmt.Errorf("failed to commit task: %w", err)
+	}
+	return task, nil
+}
+
+// CreateTaskTx is CreateTask inside the caller's transaction, so workflows can open a task
+// atomically with the record it is about
+func (s *TaskService) CreateTaskTx(ctx context.Context, q *domain.Queries, id, tenantID, actorID pgtype.UUID, in TaskInput) (domain.Task, error) {
+	if in.AssigneeEmployeeID.Valid == (in.AssigneeRoleCode != "") {
+		return domain.Task{}, ErrAssigneeRequired
+	}
+	if in.AssigneeEmployeeID.Valid {
+		if _, err := q.GetEmployee(ctx, domain.GetEmployeeParams{
+			TenantID: tenantID,
+			ID:       in.AssigneeEmployeeID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, ErrEmployeeNotFound
+		} else if err != nil {
+			return domain.Task{}, err
+		}
+	} else {
+		roles, err := q.CountRolesByCode(ctx, domain.CountRolesByCodeParams{
+			TenantID: tenantID,
+			Code:     in.AssigneeRoleCode,
+		})
+		if err != nil {
+			return domain.Task{}, fmt.Errorf("failed to check role: %w", err)
+		}
+		if roles == 0 {
+			return domain.Task{}, ErrRoleNotFound
+		}
+	}
+
+	task, err := q.CreateTask(ctx, domain.CreateTaskParams{
+		ID:                 id,
+		TenantID:           tenantID,
+		EntityType:         in.EntityType,
+		EntityID:           in.EntityID,
+		Kind:               in.Kind,
+		Title:              in.Title,
+		Description:        optionalText(in.Description),
+		AssigneeEmployeeID: in.AssigneeEmployeeID,
+		AssigneeRoleCode:   pgtype.Text{String: in.AssigneeRoleCode, Valid: in.AssigneeRoleCode != ""},
+		DueDate:            optionalDate(in.DueDate),
+		CreatedByUserID:    actorID,
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := s.notificationSvc.notifyAssignees(ctx, q, task); err != nil {
+		return domain.Task{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "Tasks", id.Bytes, map[string]interface{}{
+			"entity_type": in.EntityType,
+			"entity_id":   in.EntityID,
+			"kind":        in.Kind,
+			"assignee":    in.AssigneeEmployeeID,
+			"role":        in.AssigneeRoleCode,
+		})
+	}
+	return task, nil
+}
+
+// CloseEntityTasksTx closes every open task about an entity, e.g. when the CAPA action it
+// tracks is done. status is StatusCompleted or StatusCancelled.
+func (s *TaskService) CloseEntityTasksTx(ctx context.Context, q *domain.Queries, tenantID, actorID pgtype.UUID, entityType string, entityID pgtype.UUID, status string, outcome *string) error {
+	err := q.CloseEntityTasks(ctx, domain.CloseEntityTasksParams{
+		TenantID:          tenantID,
+		EntityType:        entityType,
+		EntityID:          entityID,
+		Status:            status,
+		Outcome:           optionalText(outcome),
+		CompletedByUserID: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close tasks: %w", err)
+	}
+	return nil
+}
+
+// ReassignEntityTasksTx moves the open employee tasks about an entity to another employee
+// and due date, notifying the new assignee
+func (s *TaskService) ReassignEntityTasksTx(ctx context.Context, q *domain.Queries, tenantID pgtype.UUID, entityType string, entityID, employeeID pgtype.UUID, dueDate *time.Time, title string) error {
+	err := q.ReassignEntityTasks(ctx, domain.ReassignEntityTasksParams{
+		TenantID:           tenantID,
+		EntityType:         entityType,
+		EntityID:           entityID,
+		AssigneeEmployeeID: employeeID,
+		DueDate:            optionalDate(dueDate),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reassign tasks: %w", err)
+	}
+	return s.notificationSvc.notifyEmployee(ctx, q, tenantID, employeeID, NotificationInput{
+		Kind:       NotificationTaskAssigned,
+		Title:      title,
+		EntityType: entityType,
+		EntityID:   entityID,
+	})
+}
+
+// MyTaskFilter narrows a user's task list. Status defaults to open.
+type MyTaskFilter struct {
+	Status      string
+	OverdueOnly bool
+}
+
+// ListMyTasks returns the tasks assigned to the user's employee record or to any of their roles
+func (s *TaskService) ListMyTasks(ctx context.Context, tenantID, userID pgtype.UUID, params query.PaginationParams, filter MyTaskFilter) ([]domain.ListUserTasksRow, int64, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       userID,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	tasks, err := s.queries.ListUserTasks(ctx, domain.ListUserTasksParams{
+		TenantID:    tenantID,
+		EmployeeID:  user.EmployeeID,
+		UserID:      userID,
+		Status:      filter.Status,
+		OverdueOnly: filter.OverdueOnly,
+		Limit:       params.Limit(),
+		Offset:      params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountUserTasks(ctx, domain.CountUserTasksParams{
+		TenantID:    tenantID,
+		EmployeeID:  user.EmployeeID,
+		UserID:      userID,
+		Status:      filter.Status,
+		OverdueOnly: filter.OverdueOnly,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (s *TaskService) GetTask(ctx context.Context, tenantID, id pgtype.UUID) (domain.Task, error) {
+	return s.queries.GetTask(ctx, domain.GetTaskParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+// CompleteTask marks an open task completed with an optional outcome. Only the assignee, a
+// holder of the assignee role, or an administrator may complete a task.
+func (s *TaskService) CompleteTask(ctx context.Context, tenantID, actorID, id pgtype.UUID, outcome *string, asAdmin bool) (domain.Task, error) {
+	task, err := s.GetTask(ctx, tenantID, id)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if task.Status != StatusOpen {
+		return domain.Task{}, ErrTaskClosed
+	}
+	if !asAdmin {
+		assigned, err := s.isAssignedTo(ctx, task, actorID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		if !assigned {
+			return domain.Task{}, ErrNotAssignee
+		}
+	}
+	return s.closeTask(ctx, tenantID, actorID, task, StatusCompleted, outcome)
+}
+
+// CancelTask withdraws an open task
+func (s *TaskService) CancelTask(ctx context.Context, tenantID, actorID, id pgtype.UUID, reason *string) (domain.Task, error) {
+	task, err := s.GetTask(ctx, tenantID, id)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if task.Status != StatusOpen {
+		return domain.Task{}, ErrTaskClosed
+	}
+	return s.closeTask(ctx, tenantID, actorID, task, StatusCancelled, reason)
+}
+
+func (s *TaskService) closeTask(ctx context.Context, tenantID, actorID pgtype.UUID, task domain.Task, status string, outcome *string) (domain.Task, error) {
+	closed, err := s.queries.CloseTask(ctx, domain.CloseTaskParams{
+		TenantID:          tenantID,
+		ID:                task.ID,
+		Status:            status,
+		Outcome:           optionalText(outcome),
+		CompletedByUserID: actorID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Closed concurrently since it was loaded
+		return domain.Task{}, ErrTaskClosed
+	}
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "Tasks", task.ID.Bytes, map[string]interface{}{
+			"status":  map[string]interface{}{"from": task.Status, "to": status},
+			"outcome": outcome,
+		})
+	}
+	return closed, err
+}
+
+func (s *TaskService) isAssignedTo(ctx context.Context, task domain.Task, userID pgtype.UUID) (bool, error) {
+	user, err := s.queries.GetUser(ctx, domain.GetUserParams{
+		TenantID: task.TenantID,
+		ID:       userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to load user: %w", err)
+	}
+	if task.AssigneeEmployeeID.Valid {
+		return task.AssigneeEmployeeID == user.EmployeeID, nil
+	}
+
+	codes, err := s.queries.GetUserRoles(ctx, domain.GetUserRolesParams{
+		TenantID: task.TenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to load roles: %w", err)
+	}
+	for _, code := range codes {
+		if code == task.AssigneeRoleCode.String {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
+
+func optionalDate(v *time.Time) pgtype.Date {
+	if v == nil {
+		return pgtype.Date{}
+	}
+	return pgtype.Date{Time: *v, Valid: true}
+}
@@ -2,30 +2,62 @@ package tenancy
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/INOVA/DML/internal/db"
 	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/iam"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Service struct {
+	db      *db.DB
 	queries *domain.Queries
 }
 
 func NewService(database *db.DB) *Service {
 	// Initialize SQLC queries wrapper with our connection pool
 	return &Service{
+		db:      database,
 		queries: domain.New(database.Pool),
 	}
 }
 
-// CreateTenant creates a new tenant
+// CreateTenant creates a new tenant with the roles that grant permissions. The API refuses
+// to create those roles, so this is the only way a tenant gets them.
 func (s *Service) CreateTenant(ctx context.Context, id pgtype.UUID, code, name string) (domain.Tenant, error) {
-	return s.queries.CreateTenant(ctx, domain.CreateTenantParams{
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.Tenant{}, fmt.Errorf("failed to begin tenant transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+	tenant, err := qtx.CreateTenant(ctx, domain.CreateTenantParams{
 		ID:   id,
 		Code: code,
 		Name: name,
 	})
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+
+	for _, roleCode := range iam.ReservedRoles() {
+		if _, err := qtx.CreateRole(ctx, domain.CreateRoleParams{
+			ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			TenantID: tenant.ID,
+			Code:     roleCode,
+			Name:     iam.ReservedRoleName(roleCode),
+		}); err != nil {
+			return domain.Tenant{}, fmt.Errorf("failed to create role %s: %w", roleCode, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Tenant{}, fmt.Errorf("failed to commit tenant: %w", err)
+	}
+	return tenant, nil
 }
 
 // ListTenants retrieves all tenants
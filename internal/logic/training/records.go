@@ -0,0 +1,489 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/logic/competency"
+	"github.com/INOVA/DML/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultExpiringWithinDays is the look-ahead used for expiring certifications when none is given
+const DefaultExpiringWithinDays = 30
+
+var (
+	// ErrAlreadySignedOff is returned when changing or re-signing a record the trainer has signed off
+	ErrAlreadySignedOff = errors.New("training record is already signed off")
+
+	// ErrNotTrainer is returned when someone other than the session trainer or an administrator signs off a record
+	ErrNotTrainer = errors.New("only the session trainer or an administrator can sign off this record")
+
+	// ErrSelfSignOff is returned when an employee tries to sign off their own training record
+	ErrSelfSignOff = errors.New("a training record cannot be signed off by the trainee")
+
+	// ErrNoEvidence is returned when downloading evidence from a record that has none
+	ErrNoEvidence = errors.New("training record has no evidence attached")
+)
+
+// RecordInput describes one completion of a course. When ExpiresOn is nil the expiry is
+// derived from the course's validity period, if it has one.
+type RecordInput struct {
+	EmployeeID  pgtype.UUID
+	CourseID    pgtype.UUID
+	SessionID   pgtype.UUID
+	CompletedOn time.Time
+	ExpiresOn   *time.Time
+	Result      string
+	Notes       *string
+}
+
+// RecordTraining stores a course completion for an employee. The record does not count
+// towards compliance until it is signed off.
+func (s *TrainingService) RecordTraining(ctx context.Context, tenantID, actorID pgtype.UUID, in RecordInput) (domain.TrainingRecord, error) {
+	rec, err := s.recordTraining(ctx, s.queries, tenantID, actorID, in)
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "TrainingRecords", rec.ID.Bytes, map[string]interface{}{
+			"employee_id":  in.EmployeeID,
+			"course_id":    in.CourseID,
+			"completed_on": rec.CompletedOn,
+			"expires_on":   rec.ExpiresOn,
+			"result":       rec.Result,
+		})
+	}
+	return rec, nil
+}
+
+func (s *TrainingService) recordTraining(ctx context.Context, q *domain.Queries, tenantID, actorID pgtype.UUID, in RecordInput) (domain.TrainingRecord, error) {
+	if _, err := q.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       in.EmployeeID,
+	}); err != nil {
+		return domain.TrainingRecord{}, err
+	}
+
+	course, err := s.getCourse(ctx, q, tenantID, in.CourseID)
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+	if !course.IsActive {
+		return domain.TrainingRecord{}, ErrCourseInactive
+	}
+
+	if in.SessionID.Valid {
+		session, err := q.GetTrainingSession(ctx, domain.GetTrainingSessionParams{
+			TenantID: tenantID,
+			ID:       in.SessionID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.TrainingRecord{}, ErrSessionNotFound
+		}
+		if err != nil {
+			return domain.TrainingRecord{}, err
+		}
+		if session.CourseID != in.CourseID {
+			return domain.TrainingRecord{}, ErrSessionCourseMismatch
+		}
+	}
+
+	var expires pgtype.Date
+	switch {
+	case in.ExpiresOn != nil:
+		if in.ExpiresOn.Before(in.CompletedOn) {
+			return domain.TrainingRecord{}, ErrInvalidExpiry
+		}
+		expires = pgtype.Date{Time: *in.ExpiresOn, Valid: true}
+	case course.ValidityMonths.Valid:
+		expires = pgtype.Date{Time: in.CompletedOn.AddDate(0, int(course.ValidityMonths.Int32), 0), Valid: true}
+	}
+
+	result := in.Result
+	if result == "" {
+		result = ResultPass
+	}
+
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+
+	return q.CreateTrainingRecord(ctx, domain.CreateTrainingRecordParams{
+		ID:               id,
+		TenantID:         tenantID,
+		EmployeeID:       in.EmployeeID,
+		CourseID:         in.CourseID,
+		SessionID:        in.SessionID,
+		CompletedOn:      pgtype.Date{Time: in.CompletedOn, Valid: true},
+		ExpiresOn:        expires,
+		Result:           result,
+		Notes:            optionalText(in.Notes),
+		RecordedByUserID: actorID,
+	})
+}
+
+func (s *TrainingService) GetRecord(ctx context.Context, tenantID, id pgtype.UUID) (domain.TrainingRecord, error) {
+	return s.queries.GetTrainingRecord(ctx, domain.GetTrainingRecordParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+// SignOff records the trainer's confirmation of a training record. Only the employee who
+// delivered the record's session may sign it off, unless asAdmin is set; nobody may sign
+// off their own record. Signing off a passed completion of a course linked to a competency
+// also records that competency for the employee.
+func (s *TrainingService) SignOff(ctx context.Context, tenantID, actorID, id pgtype.UUID, asAdmin bool) (domain.TrainingRecord, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return domain.TrainingRecord{}, fmt.Errorf("failed to begin sign-off transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	rec, err := qtx.GetTrainingRecord(ctx, domain.GetTrainingRecordParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+	if rec.SignedOffAt.Valid {
+		return domain.TrainingRecord{}, ErrAlreadySignedOff
+	}
+
+	signer, err := qtx.GetUser(ctx, domain.GetUserParams{
+		TenantID: tenantID,
+		ID:       actorID,
+	})
+	if err != nil {
+		return domain.TrainingRecord{}, fmt.Errorf("failed to load signer: %w", err)
+	}
+	if signer.EmployeeID == rec.EmployeeID {
+		return domain.TrainingRecord{}, ErrSelfSignOff
+	}
+	if !asAdmin {
+		if !rec.SessionID.Valid {
+			return domain.TrainingRecord{}, ErrNotTrainer
+		}
+		session, err := qtx.GetTrainingSession(ctx, domain.GetTrainingSessionParams{
+			TenantID: tenantID,
+			ID:       rec.SessionID,
+		})
+		if err != nil {
+			return domain.TrainingRecord{}, fmt.Errorf("failed to load session: %w", err)
+		}
+		if !session.TrainerEmployeeID.Valid || session.TrainerEmployeeID != signer.EmployeeID {
+			return domain.TrainingRecord{}, ErrNotTrainer
+		}
+	}
+
+	course, err := s.getCourse(ctx, qtx, tenantID, rec.CourseID)
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+
+	var achievedID pgtype.UUID
+	if rec.Result == ResultPass && course.CompetencyID.Valid && s.competencySvc != nil {
+		var expiresOn *time.Time
+		if rec.ExpiresOn.Valid {
+			expiresOn = &rec.ExpiresOn.Time
+		}
+		note := fmt.Sprintf("Training course %s", course.Code)
+		achieved, err := s.competencySvc.RecordCompetencyTx(ctx, qtx, tenantID, actorID, rec.EmployeeID, course.CompetencyID, rec.CompletedOn.Time, expiresOn, competency.SourceTraining, &note)
+		if err != nil {
+			return domain.TrainingRecord{}, fmt.Errorf("failed to record competency: %w", err)
+		}
+		achievedID = achieved.ID
+	}
+
+	signed, err := qtx.SignOffTrainingRecord(ctx, domain.SignOffTrainingRecordParams{
+		TenantID:             tenantID,
+		ID:                   id,
+		SignedOffByUserID:    actorID,
+		EmployeeCompetencyID: achievedID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Signed off concurrently between the read and the update
+		return domain.TrainingRecord{}, ErrAlreadySignedOff
+	}
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.TrainingRecord{}, fmt.Errorf("failed to commit sign-off: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "TrainingRecords", id.Bytes, map[string]interface{}{
+			"signed_off":             true,
+			"as_admin":               asAdmin,
+			"employee_competency_id": achievedID,
+		})
+	}
+
+	return signed, nil
+}
+
+// AttachEvidence stores an uploaded certificate or attendance sheet against a record,
+// replacing any earlier file. Evidence is frozen once the record is signed off.
+func (s *TrainingService) AttachEvidence(ctx context.Context, tenantID, actorID, id pgtype.UUID, fileName, contentType string, r io.Reader) (domain.TrainingRecord, error) {
+	rec, err := s.GetRecord(ctx, tenantID, id)
+	if err != nil {
+		return domain.TrainingRecord{}, err
+	}
+	if rec.SignedOffAt.Valid {
+		return domain.TrainingRecord{}, ErrAlreadySignedOff
+	}
+
+	key := path.Join("training", uuid.UUID(tenantID.Bytes).String(), uuid.UUID(id.Bytes).String(), uuid.NewString())
+	w, err := s.store.Create(ctx, key)
+	if err != nil {
+		return domain.TrainingRecord{}, fmt.Errorf("failed to store evidence: %w", err)
+	}
+	size, err := io.Copy(w, r)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = s.store.Delete(ctx, key)
+		return domain.TrainingRecord{}, fmt.Errorf("failed to store evidence: %w", err)
+	}
+
+	updated, err := s.queries.SetTrainingRecordEvidence(ctx, domain.SetTrainingRecordEvidenceParams{
+		TenantID:            tenantID,
+		ID:                  id,
+		EvidenceKey:         pgtype.Text{String: key, Valid: true},
+		EvidenceFileName:    pgtype.Text{String: path.Base(fileName), Valid: fileName != ""},
+		EvidenceContentType: pgtype.Text{String: contentType, Valid: contentType != ""},
+		EvidenceSize:        pgtype.Int8{Int64: size, Valid: true},
+	})
+	if err != nil {
+		_ = s.store.Delete(ctx, key)
+		return domain.TrainingRecord{}, err
+	}
+
+	if rec.EvidenceKey.Valid {
+		_ = s.store.Delete(ctx, rec.EvidenceKey.String)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "TrainingRecords", id.Bytes, map[string]interface{}{
+			"evidence_file_name": updated.EvidenceFileName,
+			"evidence_size":      size,
+		})
+	}
+
+	return updated, nil
+}
+
+// OpenEvidence opens a record's evidence file. The caller must close the reader.
+func (s *TrainingService) OpenEvidence(ctx context.Context, tenantID, id pgtype.UUID) (io.ReadCloser, domain.TrainingRecord, error) {
+	rec, err := s.GetRecord(ctx, tenantID, id)
+	if err != nil {
+		return nil, domain.TrainingRecord{}, err
+	}
+	if !rec.EvidenceKey.Valid {
+		return nil, rec, ErrNoEvidence
+	}
+
+	rc, err := s.store.Open(ctx, rec.EvidenceKey.String)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, rec, ErrNoEvidence
+	}
+	if err != nil {
+		return nil, rec, err
+	}
+	return rc, rec, nil
+}
+
+// HistoryEntry is one record in an employee's training history
+type HistoryEntry struct {
+	ID                   pgtype.UUID        `json:"id"`
+	CourseID             pgtype.UUID        `json:"courseId"`
+	CourseCode           string             `json:"courseCode"`
+	CourseName           string             `json:"courseName"`
+	IsMandatory          bool               `json:"isMandatory"`
+	SessionID            pgtype.UUID        `json:"sessionId"`
+	SessionStartsAt      pgtype.Timestamptz `json:"sessionStartsAt"`
+	CompletedOn          pgtype.Date        `json:"completedOn"`
+	ExpiresOn            pgtype.Date        `json:"expiresOn"`
+	Expired              bool               `json:"expired"`
+	Result               string             `json:"result"`
+	Notes                *string            `json:"notes"`
+	EvidenceFileName     *string            `json:"evidenceFileName"`
+	EvidenceContentType  *string            `json:"evidenceContentType"`
+	EvidenceSize         *int64             `json:"evidenceSize"`
+	SignedOff            bool               `json:"signedOff"`
+	SignedOffByUserID    pgtype.UUID        `json:"signedOffByUserId"`
+	SignedOffAt          pgtype.Timestamptz `json:"signedOffAt"`
+	EmployeeCompetencyID pgtype.UUID        `json:"employeeCompetencyId"`
+	RecordedByUserID     pgtype.UUID        `json:"recordedByUserId"`
+	CreatedAt            pgtype.Timestamptz `json:"createdAt"`
+}
+
+// ListEmployeeHistory returns every training record of an employee, most recent first
+func (s *TrainingService) ListEmployeeHistory(ctx context.Context, tenantID, employeeID pgtype.UUID) ([]HistoryEntry, error) {
+	if _, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+		TenantID: tenantID,
+		ID:       employeeID,
+	}); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListEmployeeTrainingRecords(ctx, domain.ListEmployeeTrainingRecordsParams{
+		TenantID:   tenantID,
+		EmployeeID: employeeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(time.Now())
+	out := make([]HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := HistoryEntry{
+			ID:                   row.ID,
+			CourseID:             row.CourseID,
+			CourseCode:           row.CourseCode,
+			CourseName:           row.CourseName,
+			IsMandatory:          row.IsMandatory,
+			SessionID:            row.SessionID,
+			SessionStartsAt:      row.SessionStartsAt,
+			CompletedOn:          row.CompletedOn,
+			ExpiresOn:            row.ExpiresOn,
+			Expired:              row.ExpiresOn.Valid && truncateDay(row.ExpiresOn.Time).Before(today),
+			Result:               row.Result,
+			Notes:                textPtr(row.Notes),
+			EvidenceFileName:     textPtr(row.EvidenceFileName),
+			EvidenceContentType:  textPtr(row.EvidenceContentType),
+			SignedOff:            row.SignedOffAt.Valid,
+			SignedOffByUserID:    row.SignedOffByUserID,
+			SignedOffAt:          row.SignedOffAt,
+			EmployeeCompetencyID: row.EmployeeCompetencyID,
+			RecordedByUserID:     row.RecordedByUserID,
+			CreatedAt:            row.CreatedAt,
+		}
+		if row.EvidenceSize.Valid {
+			entry.EvidenceSize = &row.EvidenceSize.Int64
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// ExpiringFilter selects expiring certifications. Only the latest signed-off pass of each
+// employee and course is considered, so a renewed certification drops out of the list.
+type ExpiringFilter struct {
+	WithinDays            int
+	IncludeExpired        bool
+	DepartmentID          pgtype.UUID
+	IncludeSubDepartments bool
+}
+
+// ExpiringCertification is an employee's latest completion of a course that is due to expire
+type ExpiringCertification struct {
+	RecordID    pgtype.UUID `json:"recordId"`
+	EmployeeID  pgtype.UUID `json:"employeeId"`
+	EmployeeNo  string      `json:"employeeNo"`
+	FirstName   string      `json:"firstName"`
+	LastName    string      `json:"lastName"`
+	CourseID    pgtype.UUID `json:"courseId"`
+	CourseCode  string      `json:"courseCode"`
+	CourseName  string      `json:"courseName"`
+	IsMandatory bool        `json:"isMandatory"`
+	CompletedOn pgtype.Date `json:"completedOn"`
+	ExpiresOn   pgtype.Date `json:"expiresOn"`
+	DaysLeft    int         `json:"daysLeft"`
+}
+
+// DepartmentExpiries groups expiring certifications by the employee's department.
+// Employees without a department are grouped under an invalid department ID.
+type DepartmentExpiries struct {
+	DepartmentID   pgtype.UUID             `json:"departmentId"`
+	DepartmentCode *string                 `json:"departmentCode"`
+	DepartmentName *string                 `json:"departmentName"`
+	Expiring       int                     `json:"expiring"`
+	Expired        int                     `json:"expired"`
+	Records        []ExpiringCertification `json:"records"`
+}
+
+// ListExpiring returns certifications expiring within the look-ahead window, grouped by department
+func (s *TrainingService) ListExpiring(ctx context.Context, tenantID pgtype.UUID, filter ExpiringFilter) ([]DepartmentExpiries, error) {
+	days := filter.WithinDays
+	if days <= 0 {
+		days = DefaultExpiringWithinDays
+	}
+	today := truncateDay(time.Now())
+
+	rows, err := s.queries.ListExpiringTrainingRecords(ctx, domain.ListExpiringTrainingRecordsParams{
+		TenantID:              tenantID,
+		Until:                 pgtype.Date{Time: today.AddDate(0, 0, days), Valid: true},
+		IncludeExpired:        filter.IncludeExpired,
+		DepartmentID:          filter.DepartmentID,
+		IncludeSubDepartments: filter.IncludeSubDepartments,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring training: %w", err)
+	}
+
+	groups := map[[16]byte]*DepartmentExpiries{}
+	var order [][16]byte
+	for _, row := range rows {
+		g, ok := groups[row.DepartmentID.Bytes]
+		if !ok {
+			g = &DepartmentExpiries{
+				DepartmentID:   row.DepartmentID,
+				DepartmentCode: textPtr(row.DepartmentCode),
+				DepartmentName: textPtr(row.DepartmentName),
+				Records:        []ExpiringCertification{},
+			}
+			groups[row.DepartmentID.Bytes] = g
+			order = append(order, row.DepartmentID.Bytes)
+		}
+
+		daysLeft := int(truncateDay(row.ExpiresOn.Time).Sub(today).Hours() / 24)
+		if daysLeft < 0 {
+			g.Expired++
+		} else {
+			g.Expiring++
+		}
+		g.Records = append(g.Records, ExpiringCertification{
+			RecordID:    row.ID,
+			EmployeeID:  row.EmployeeID,
+			EmployeeNo:  row.EmployeeNo,
+			FirstName:   row.FirstName,
+			LastName:    row.LastName,
+			CourseID:    row.CourseID,
+			CourseCode:  row.CourseCode,
+			CourseName:  row.CourseName,
+			IsMandatory: row.IsMandatory,
+			CompletedOn: row.CompletedOn,
+			ExpiresOn:   row.ExpiresOn,
+			DaysLeft:    daysLeft,
+		})
+	}
+
+	// Rows arrive ordered by department name with unassigned employees last
+	out := make([]DepartmentExpiries, 0, len(order))
+	for _, id := range order {
+		out = append(out, *groups[id])
+	}
+	return out, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
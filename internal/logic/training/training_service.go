@@ -0,0 +1,426 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/INOVA/DML/internal/logic/competency"
+	"github.com/INOVA/DML/internal/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Training session statuses
+const (
+	SessionScheduled = "scheduled"
+	SessionCompleted = "completed"
+	SessionCancelled = "cancelled"
+)
+
+// Training record results
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+)
+
+var (
+	// ErrCourseNotFound is returned when a referenced course does not exist in the tenant
+	ErrCourseNotFound = errors.New("training course does not exist or is inaccessible")
+
+	// ErrCourseInactive is returned when recording or scheduling training for a retired course
+	ErrCourseInactive = errors.New("training course is not active")
+
+	// ErrSessionNotFound is returned when a referenced session does not exist in the tenant
+	ErrSessionNotFound = errors.New("training session does not exist or is inaccessible")
+
+	// ErrSessionCourseMismatch is returned when a record's session delivers a different course
+	ErrSessionCourseMismatch = errors.New("training session is for a different course")
+
+	// ErrSessionClosed is returned when completing or cancelling a session that is no longer scheduled
+	ErrSessionClosed = errors.New("training session is already completed or cancelled")
+
+	// ErrTrainerNotFound is returned when a session's trainer is not an employee of the tenant
+	ErrTrainerNotFound = errors.New("trainer does not exist or is inaccessible")
+
+	// ErrInvalidExpiry is returned when a record expires before it was completed
+	ErrInvalidExpiry = errors.New("expiry date must not be before the completion date")
+)
+
+type TrainingService struct {
+	db            *db.DB
+	queries       *domain.Queries
+	store         storage.Store
+	competencySvc *competency.CompetencyService
+	auditSvc      *audit.AuditService
+}
+
+// NewTrainingService creates the training service. Evidence files are kept in store, and
+// signed-off completions of courses linked to a competency are recorded through competencySvc.
+func NewTrainingService(database *db.DB, store storage.Store, competencySvc *competency.CompetencyService, auditSvc *audit.AuditService) *TrainingService {
+	return &TrainingService{
+		db:            database,
+		queries:       domain.New(database.Pool),
+		store:         store,
+		competencySvc: competencySvc,
+		auditSvc:      auditSvc,
+	}
+}
+
+// CourseInput holds the editable fields of a training course
+type CourseInput struct {
+	Code           string
+	Name           string
+	Description    *string
+	CompetencyID   pgtype.UUID
+	ValidityMonths *int32
+	IsMandatory    bool
+	IsActive       bool
+}
+
+func (s *TrainingService) CreateCourse(ctx context.Context, id, tenantID, actorID pgtype.UUID, in CourseInput) (domain.TrainingCourse, error) {
+	if err := s.checkCompetency(ctx, tenantID, in.CompetencyID); err != nil {
+		return domain.TrainingCourse{}, err
+	}
+
+	course, err := s.queries.CreateTrainingCourse(ctx, domain.CreateTrainingCourseParams{
+		ID:             id,
+		TenantID:       tenantID,
+		Code:           in.Code,
+		Name:           in.Name,
+		Description:    optionalText(in.Description),
+		CompetencyID:   in.CompetencyID,
+		ValidityMonths: optionalInt4(in.ValidityMonths),
+		IsMandatory:    in.IsMandatory,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "TrainingCourses", id.Bytes, map[string]interface{}{
+			"code":         in.Code,
+			"name":         in.Name,
+			"is_mandatory": in.IsMandatory,
+		})
+	}
+	return course, err
+}
+
+func (s *TrainingService) UpdateCourse(ctx context.Context, tenantID, actorID, id pgtype.UUID, in CourseInput) (domain.TrainingCourse, error) {
+	if err := s.checkCompetency(ctx, tenantID, in.CompetencyID); err != nil {
+		return domain.TrainingCourse{}, err
+	}
+
+	course, err := s.queries.UpdateTrainingCourse(ctx, domain.UpdateTrainingCourseParams{
+		TenantID:       tenantID,
+		ID:             id,
+		Code:           in.Code,
+		Name:           in.Name,
+		Description:    optionalText(in.Description),
+		CompetencyID:   in.CompetencyID,
+		ValidityMonths: optionalInt4(in.ValidityMonths),
+		IsMandatory:    in.IsMandatory,
+		IsActive:       in.IsActive,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "TrainingCourses", id.Bytes, map[string]interface{}{
+			"code":         in.Code,
+			"name":         in.Name,
+			"is_mandatory": in.IsMandatory,
+			"is_active":    in.IsActive,
+		})
+	}
+	return course, err
+}
+
+func (s *TrainingService) ListCourses(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]domain.TrainingCourse, int64, error) {
+	courses, err := s.queries.ListTrainingCourses(ctx, domain.ListTrainingCoursesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountTrainingCourses(ctx, domain.CountTrainingCoursesParams{
+		TenantID: tenantID,
+		Search:   params.Search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return courses, total, nil
+}
+
+func (s *TrainingService) GetCourse(ctx context.Context, tenantID, id pgtype.UUID) (domain.TrainingCourse, error) {
+	return s.queries.GetTrainingCourse(ctx, domain.GetTrainingCourseParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+}
+
+// SessionInput describes a scheduled delivery of a course. The trainer is either an
+// employee, who may then sign off attendees' records, or a named external provider.
+type SessionInput struct {
+	CourseID          pgtype.UUID
+	TrainerEmployeeID pgtype.UUID
+	ExternalTrainer   *string
+	BusinessUnitID    pgtype.UUID
+	Location          *string
+	StartsAt          time.Time
+	EndsAt            *time.Time
+}
+
+func (s *TrainingService) CreateSession(ctx context.Context, id, tenantID, actorID pgtype.UUID, in SessionInput) (domain.TrainingSession, error) {
+	course, err := s.getCourse(ctx, s.queries, tenantID, in.CourseID)
+	if err != nil {
+		return domain.TrainingSession{}, err
+	}
+	if !course.IsActive {
+		return domain.TrainingSession{}, ErrCourseInactive
+	}
+
+	if in.TrainerEmployeeID.Valid {
+		if _, err := s.queries.GetEmployee(ctx, domain.GetEmployeeParams{
+			TenantID: tenantID,
+			ID:       in.TrainerEmployeeID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			return domain.TrainingSession{}, ErrTrainerNotFound
+		} else if err != nil {
+			return domain.TrainingSession{}, err
+		}
+	}
+
+	var endsAt pgtype.Timestamptz
+	if in.EndsAt != nil {
+		endsAt = pgtype.Timestamptz{Time: *in.EndsAt, Valid: true}
+	}
+
+	session, err := s.queries.CreateTrainingSession(ctx, domain.CreateTrainingSessionParams{
+		ID:                id,
+		TenantID:          tenantID,
+		CourseID:          in.CourseID,
+		TrainerEmployeeID: in.TrainerEmployeeID,
+		ExternalTrainer:   optionalText(in.ExternalTrainer),
+		BusinessUnitID:    in.BusinessUnitID,
+		Location:          optionalText(in.Location),
+		StartsAt:          pgtype.Timestamptz{Time: in.StartsAt, Valid: true},
+		EndsAt:            endsAt,
+		CreatedByUserID:   actorID,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "TrainingSessions", id.Bytes, map[string]interface{}{
+			"course_id": in.CourseID,
+			"starts_at": in.StartsAt,
+		})
+	}
+	return session, err
+}
+
+// SessionFilter narrows the session list; unset fields do not filter
+type SessionFilter struct {
+	CourseID pgtype.UUID
+	Status   string
+}
+
+func (s *TrainingService) ListSessions(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams, filter SessionFilter) ([]domain.TrainingSession, int64, error) {
+	sessions, err := s.queries.ListTrainingSessions(ctx, domain.ListTrainingSessionsParams{
+		TenantID: tenantID,
+		CourseID: filter.CourseID,
+		Status:   filter.Status,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountTrainingSessions(ctx, domain.CountTrainingSessionsParams{
+		TenantID: tenantID,
+		CourseID: filter.CourseID,
+		Status:   filter.Status,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+// SessionDetail is a session together with the records of its attendees
+type SessionDetail struct {
+	domain.TrainingSession
+	Attendees []domain.ListSessionTrainingRecordsRow `json:"attendees"`
+}
+
+func (s *TrainingService) GetSession(ctx context.Context, tenantID, id pgtype.UUID) (SessionDetail, error) {
+	session, err := s.queries.GetTrainingSession(ctx, domain.GetTrainingSessionParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return SessionDetail{}, err
+	}
+
+	attendees, err := s.queries.ListSessionTrainingRecords(ctx, domain.ListSessionTrainingRecordsParams{
+		TenantID:  tenantID,
+		SessionID: id,
+	})
+	if err != nil {
+		return SessionDetail{}, err
+	}
+	if attendees == nil {
+		attendees = []domain.ListSessionTrainingRecordsRow{}
+	}
+
+	return SessionDetail{TrainingSession: session, Attendees: attendees}, nil
+}
+
+// Attendee is one employee's outcome in a completed session
+type Attendee struct {
+	EmployeeID pgtype.UUID
+	Result     string
+	Notes      *string
+}
+
+// CompleteSession closes a scheduled session and creates a training record for every
+// attendee, all in one transaction. Records still need the trainer's sign-off to count.
+func (s *TrainingService) CompleteSession(ctx context.Context, tenantID, actorID, id pgtype.UUID, completedOn time.Time, attendees []Attendee) (SessionDetail, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return SessionDetail{}, fmt.Errorf("failed to begin session transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := domain.New(tx)
+
+	session, err := qtx.GetTrainingSession(ctx, domain.GetTrainingSessionParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return SessionDetail{}, err
+	}
+	if session.Status != SessionScheduled {
+		return SessionDetail{}, ErrSessionClosed
+	}
+
+	seen := make(map[[16]byte]bool, len(attendees))
+	for _, a := range attendees {
+		if seen[a.EmployeeID.Bytes] {
+			continue
+		}
+		seen[a.EmployeeID.Bytes] = true
+
+		if _, err := s.recordTraining(ctx, qtx, tenantID, actorID, RecordInput{
+			EmployeeID:  a.EmployeeID,
+			CourseID:    session.CourseID,
+			SessionID:   session.ID,
+			CompletedOn: completedOn,
+			Result:      a.Result,
+			Notes:       a.Notes,
+		}); err != nil {
+			return SessionDetail{}, fmt.Errorf("employee %s: %w", uuid.UUID(a.EmployeeID.Bytes), err)
+		}
+	}
+
+	if _, err := qtx.UpdateTrainingSessionStatus(ctx, domain.UpdateTrainingSessionStatusParams{
+		TenantID: tenantID,
+		ID:       id,
+		Status:   SessionCompleted,
+	}); err != nil {
+		return SessionDetail{}, fmt.Errorf("failed to complete session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return SessionDetail{}, fmt.Errorf("failed to commit session: %w", err)
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "TrainingSessions", id.Bytes, map[string]interface{}{
+			"status":    map[string]interface{}{"from": session.Status, "to": SessionCompleted},
+			"attendees": len(seen),
+		})
+	}
+
+	return s.GetSession(ctx, tenantID, id)
+}
+
+// CancelSession marks a scheduled session as cancelled
+func (s *TrainingService) CancelSession(ctx context.Context, tenantID, actorID, id pgtype.UUID) (domain.TrainingSession, error) {
+	session, err := s.queries.GetTrainingSession(ctx, domain.GetTrainingSessionParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return domain.TrainingSession{}, err
+	}
+	if session.Status != SessionScheduled {
+		return domain.TrainingSession{}, ErrSessionClosed
+	}
+
+	updated, err := s.queries.UpdateTrainingSessionStatus(ctx, domain.UpdateTrainingSessionStatusParams{
+		TenantID: tenantID,
+		ID:       id,
+		Status:   SessionCancelled,
+	})
+	if err == nil && s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "TrainingSessions", id.Bytes, map[string]interface{}{
+			"status": map[string]interface{}{"from": session.Status, "to": SessionCancelled},
+		})
+	}
+	return updated, err
+}
+
+func (s *TrainingService) getCourse(ctx context.Context, q *domain.Queries, tenantID, id pgtype.UUID) (domain.TrainingCourse, error) {
+	course, err := q.GetTrainingCourse(ctx, domain.GetTrainingCourseParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TrainingCourse{}, ErrCourseNotFound
+	}
+	return course, err
+}
+
+func (s *TrainingService) checkCompetency(ctx context.Context, tenantID, competencyID pgtype.UUID) error {
+	if !competencyID.Valid {
+		return nil
+	}
+	if _, err := s.queries.GetCompetency(ctx, domain.GetCompetencyParams{
+		TenantID: tenantID,
+		ID:       competencyID,
+	}); errors.Is(err, pgx.ErrNoRows) {
+		return competency.ErrCompetencyNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
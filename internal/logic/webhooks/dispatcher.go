@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Delivery statuses
+const (
+	StatusPending    = "pending"
+	StatusDelivering = "delivering"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+)
+
+// Delivery request headers
+const (
+	HeaderEvent     = "X-DML-Event"
+	HeaderDelivery  = "X-DML-Delivery"
+	HeaderSignature = "X-DML-Signature"
+)
+
+const (
+	// maxAttempts is how often a delivery is tried before it is marked failed
+	maxAttempts = 10
+	// batchSize caps the deliveries claimed per round trip
+	batchSize = 20
+	// deliveryTimeout bounds a single request to an endpoint
+	deliveryTimeout = 10 * time.Second
+	// deliveryLease is how long a claimed delivery stays reserved before it is retried
+	deliveryLease = 2 * time.Minute
+)
+
+// Sign returns the X-DML-Signature value for a body sent at a time: "t=<unix seconds>,v1=<hex>",
+// where v1 is the HMAC-SHA256 of "<unix seconds>.<body>" keyed with the endpoint secret.
+// Receivers recompute it and should reject stale timestamps to prevent replay by third parties.
+func Sign(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (s *WebhookService) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.dispatch(context.Background())
+	}
+}
+
+// dispatch sends every due delivery, a batch at a time
+func (s *WebhookService) dispatch(ctx context.Context) {
+	for {
+		deliveries, err := s.queries.ClaimDueWebhookDeliveries(ctx, domain.ClaimDueWebhookDeliveriesParams{
+			LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(deliveryLease), Valid: true},
+			BatchSize:  batchSize,
+		})
+		if err != nil {
+			log.Printf("webhooks failed claiming deliveries: %v", err)
+			return
+		}
+		for _, d := range deliveries {
+			s.deliver(ctx, d)
+		}
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, d domain.WebhookDelivery) {
+	endpoint, err := s.queries.GetWebhookEndpoint(ctx, domain.GetWebhookEndpointParams{
+		TenantID: d.TenantID,
+		ID:       d.EndpointID,
+	})
+	if err != nil {
+		s.record(ctx, d, 0, "", fmt.Errorf("loading endpoint: %w", err))
+		return
+	}
+	if !endpoint.IsActive {
+		s.record(ctx, d, 0, "", fmt.Errorf("endpoint is disabled"))
+		return
+	}
+
+	status, body, err := s.post(ctx, endpoint, d)
+	s.record(ctx, d, status, body, err)
+}
+
+// post sends one delivery and returns the response status code and status line. Response
+// bodies are discarded rather than logged, so the delivery log cannot be used to read
+// back what an endpoint returned.
+func (s *WebhookService) post(ctx context.Context, endpoint domain.WebhookEndpoint, d domain.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DML-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, uuid.UUID(d.ID.Bytes).String())
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, time.Now(), d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, resp.Status, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, resp.Status, nil
+}
+
+// record stores the outcome of an attempt, rescheduling failures until attempts run out
+func (s *WebhookService) record(ctx context.Context, d domain.WebhookDelivery, status int, body string, sendErr error) {
+	respStatus := pgtype.Int4{Int32: int32(status), Valid: status != 0}
+	respBody := pgtype.Text{String: body, Valid: status != 0}
+
+	var err error
+	switch {
+	case sendErr == nil:
+		err = s.queries.MarkWebhookDeliverySucceeded(ctx, domain.MarkWebhookDeliverySucceededParams{
+			ID:             d.ID,
+			ResponseStatus: respStatus,
+			ResponseBody:   respBody,
+		})
+	case d.Attempts >= maxAttempts:
+		err = s.queries.FailWebhookDelivery(ctx, domain.FailWebhookDeliveryParams{
+			ID:             d.ID,
+			ResponseStatus: respStatus,
+			ResponseBody:   respBody,
+			LastError:      pgtype.Text{String: sendErr.Error(), Valid: true},
+		})
+	default:
+		err = s.queries.RescheduleWebhookDelivery(ctx, domain.RescheduleWebhookDeliveryParams{
+			ID:             d.ID,
+			ResponseStatus: respStatus,
+			ResponseBody:   respBody,
+			LastError:      pgtype.Text{String: sendErr.Error(), Valid: true},
+			NextAttemptAt:  pgtype.Timestamptz{Time: time.Now().Add(backoff(d.Attempts)), Valid: true},
+		})
+	}
+	if err != nil {
+		log.Printf("webhooks failed recording delivery: %v", err)
+	}
+}
+
+// backoff doubles from 30 seconds: 30s, 1m, 2m ... about 2h before the last attempt
+func backoff(attempts int32) time.Duration {
+	return 30 * time.Second << (attempts - 1)
+}
@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"employee.created"}`)
+	at := time.Unix(1700000000, 0)
+
+	got := Sign("whsec_test", at, body)
+	if !strings.HasPrefix(got, "t=1700000000,v1=") {
+		t.Fatalf("Sign() = %q, want t=1700000000,v1=<hex>", got)
+	}
+
+	// A receiver recomputes the MAC over "<t>.<body>"
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+	if got != want {
+		t.Fatalf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSignDependsOnSecretTimeAndBody(t *testing.T) {
+	body := []byte(`{}`)
+	at := time.Unix(1700000000, 0)
+	base := Sign("secret", at, body)
+
+	if Sign("other", at, body) == base {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("secret", at.Add(time.Second), body) == base {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if Sign("secret", at, []byte(`{"a":1}`)) == base {
+		t.Error("signature does not depend on the body")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if got := backoff(1); got != 30*time.Second {
+		t.Errorf("backoff(1) = %v, want 30s", got)
+	}
+	if got := backoff(4); got != 4*time.Minute {
+		t.Errorf("backoff(4) = %v, want 4m", got)
+	}
+}
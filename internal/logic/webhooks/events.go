@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Event types endpoints can subscribe to
+const (
+	EventEmployeeCreated           = "employee.created"
+	EventEmployeeUpdated           = "employee.updated"
+	EventEmployeeDepartmentChanged = "employee.department_changed"
+	EventEmployeeManagerChanged    = "employee.manager_changed"
+	EventEmployeeTerminated        = "employee.terminated"
+	EventEmployeeErased            = "employee.erased"
+	EventUserCreated               = "user.created"
+	EventUserRoleAssigned          = "user.role_assigned"
+	EventUserRoleRevoked           = "user.role_revoked"
+	EventRoleCreated               = "role.created"
+	EventDepartmentUpdated         = "department.updated"
+	EventTrainingRecordCreated     = "training_record.created"
+	EventTrainingRecordUpdated     = "training_record.updated"
+	EventNCRCreated                = "ncr.created"
+	EventNCRUpdated                = "ncr.updated"
+
+	// EventAll subscribes an endpoint to every event type
+	EventAll = "*"
+)
+
+// auditEvents maps audit log entity types and actions onto the event types they raise
+var auditEvents = map[string]map[string][]string{
+	"Employees": {
+		"CREATE": {EventEmployeeCreated},
+		"UPDATE": {EventEmployeeUpdated},
+		"ERASE":  {EventEmployeeErased},
+	},
+	"Users": {
+		"CREATE":  {EventUserCreated},
+		"ONBOARD": {EventUserCreated},
+	},
+	"UserRoles": {
+		"CREATE": {EventUserRoleAssigned},
+		"DELETE": {EventUserRoleRevoked},
+	},
+	"Roles": {
+		"CREATE": {EventRoleCreated},
+	},
+	"Departments": {
+		"UPDATE": {EventDepartmentUpdated},
+	},
+	"TrainingRecords": {
+		"CREATE": {EventTrainingRecordCreated},
+		"UPDATE": {EventTrainingRecordUpdated},
+	},
+	"NCRs": {
+		"CREATE": {EventNCRCreated},
+		"UPDATE": {EventNCRUpdated},
+	},
+}
+
+// EventTypes lists every event type in the catalogue
+func EventTypes() []string {
+	return []string{
+		EventEmployeeCreated,
+		EventEmployeeUpdated,
+		EventEmployeeDepartmentChanged,
+		EventEmployeeManagerChanged,
+		EventEmployeeTerminated,
+		EventEmployeeErased,
+		EventUserCreated,
+		EventUserRoleAssigned,
+		EventUserRoleRevoked,
+		EventRoleCreated,
+		EventDepartmentUpdated,
+		EventTrainingRecordCreated,
+		EventTrainingRecordUpdated,
+		EventNCRCreated,
+		EventNCRUpdated,
+	}
+}
+
+func isEventType(eventType string) bool {
+	if eventType == EventAll {
+		return true
+	}
+	for _, t := range EventTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTypesFor derives the event types raised by an audit entry. Employee updates also
+// raise the more specific event for the field that changed.
+func eventTypesFor(entry domain.AuditLog) []string {
+	types := append([]string(nil), auditEvents[entry.EntityType][entry.Action]...)
+	if entry.EntityType != "Employees" || entry.Action != "UPDATE" || len(entry.Changes) == 0 {
+		return types
+	}
+
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+		return types
+	}
+	if _, ok := changes["department_id"]; ok {
+		types = append(types, EventEmployeeDepartmentChanged)
+	}
+	if _, ok := changes["manager_id"]; ok {
+		types = append(types, EventEmployeeManagerChanged)
+	}
+	if raw, ok := changes["status"]; ok {
+		var status struct {
+			To string `json:"to"`
+		}
+		if json.Unmarshal(raw, &status) == nil && status.To == "terminated" {
+			types = append(types, EventEmployeeTerminated)
+		}
+	}
+	return types
+}
+
+// Event is the JSON body POSTed to endpoints. ID identifies the underlying mutation; it is
+// the same across retries and replays, so receivers can deduplicate on ID and Type.
+type Event struct {
+	ID         pgtype.UUID `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	TenantID   pgtype.UUID `json:"tenantId"`
+	ActorID    pgtype.UUID `json:"actorId"`
+	// ActorAPIKeyID is set when the change was made through an API key
+	ActorAPIKeyID pgtype.UUID `json:"actorApiKeyId"`
+	// ImpersonatorID is set when an administrator made the change acting as the actor
+	ImpersonatorID pgtype.UUID     `json:"impersonatorId"`
+	Entity         EventEntity     `json:"entity"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// EventEntity is the record the event is about, named as in the audit log
+type EventEntity struct {
+	Type string      `json:"type"`
+	ID   pgtype.UUID `json:"id"`
+}
+
+func newEvent(entry domain.AuditLog, eventType string) Event {
+	data := json.RawMessage(entry.Changes)
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+	return Event{
+		ID:             entry.ID,
+		Type:           eventType,
+		OccurredAt:     entry.CreatedAt.Time,
+		TenantID:       entry.TenantID,
+		ActorID:        entry.ActorID,
+		ActorAPIKeyID:  entry.ActorApiKeyID,
+		ImpersonatorID: entry.ImpersonatorID,
+		Entity:         EventEntity{Type: entry.EntityType, ID: entry.EntityID},
+		Data:           data,
+	}
+}
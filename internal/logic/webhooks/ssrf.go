@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrUnsafeURL is returned when an endpoint URL is not http(s) or names an address the
+	// server must not call, such as loopback, private networks or cloud metadata
+	ErrUnsafeURL = errors.New("webhook URL must be a public http or https address")
+
+	// errBlockedAddress is returned at dial time when a host resolves to a blocked address
+	errBlockedAddress = errors.New("address is not publicly routable")
+)
+
+// blockedPrefixes are the ranges netip has no predicate for: "this network", carrier-grade
+// NAT, the IETF protocol block, benchmarking and the reserved class E space
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPublicAddr reports whether the server may send webhooks to addr. Loopback, private,
+// link-local (which includes the 169.254.169.254 metadata service), multicast and
+// reserved addresses are refused, also when written as IPv4-mapped IPv6.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkURL rejects endpoint URLs that are not http(s) or whose host is a blocked IP
+// literal or localhost. Host names are resolved and checked again on every delivery, as
+// their addresses can change after registration.
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrUnsafeURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrUnsafeURL
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return ErrUnsafeURL
+	}
+	return nil
+}
+
+// dialControl runs after DNS resolution, just before each connection is made, so it also
+// catches host names that resolve, or are rebound, to blocked addresses
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addr) {
+		return fmt.Errorf("refusing to connect to %s: %w", addr, errBlockedAddress)
+	}
+	return nil
+}
+
+// newDeliveryClient returns the HTTP client deliveries are sent with. It connects directly
+// rather than through a proxy, refuses blocked addresses at dial time and does not follow
+// redirects, so a receiver can neither bounce signed payloads elsewhere nor make the
+// server reach internal services.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: dialControl,
+	}
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
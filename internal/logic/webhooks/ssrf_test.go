@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::6810:84e5", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.public)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/dml", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"ftp://hooks.example.com/dml", false},
+		{"https://localhost/hook", false},
+		{"https://api.localhost./hook", false},
+		{"http://127.0.0.1:9000/", false},
+		{"http://[::1]/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://10.0.0.5/internal", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		err := checkURL(tt.url)
+		if tt.ok && err != nil {
+			t.Errorf("checkURL(%q) = %v, want nil", tt.url, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrUnsafeURL) {
+			t.Errorf("checkURL(%q) = %v, want ErrUnsafeURL", tt.url, err)
+		}
+	}
+}
+
+func TestDeliveryClientRefusesLoopback(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	_, err := newDeliveryClient().Get(srv.URL)
+	if !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("Get(%s) error = %v, want errBlockedAddress", srv.URL, err)
+	}
+	if called {
+		t.Fatal("loopback server was reached")
+	}
+}
+
+func TestDeliveryClientDoesNotFollowRedirects(t *testing.T) {
+	client := newDeliveryClient()
+	// Loopback is refused by the dialer, so use the default transport to reach the test server
+	client.Transport = http.DefaultTransport
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/internal", http.StatusFound)
+			return
+		}
+		t.Errorf("redirect to %s was followed", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+}
@@ -0,0 +1,414 @@
+// Package webhooks fans audited domain mutations out to tenant-registered HTTP endpoints as
+// HMAC-signed JSON events, with retries and a replayable delivery log.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/INOVA/DML/internal/db"
+	"github.com/INOVA/DML/internal/domain"
+	"github.com/INOVA/DML/internal/http/query"
+	"github.com/INOVA/DML/internal/logic/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrUnknownEvent is returned when an endpoint subscribes to an event type outside the catalogue
+	ErrUnknownEvent = errors.New("unknown event type")
+)
+
+// Endpoint is the API representation of a webhook endpoint. The signing secret is only
+// revealed when it is generated.
+type Endpoint struct {
+	ID              pgtype.UUID        `json:"id"`
+	URL             string             `json:"url"`
+	Description     pgtype.Text        `json:"description"`
+	Events          []string           `json:"events"`
+	IsActive        bool               `json:"isActive"`
+	Secret          string             `json:"secret,omitempty"`
+	CreatedByUserID pgtype.UUID        `json:"createdByUserId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+}
+
+// ToEndpoint maps a stored endpoint onto its API representation, without the secret
+func ToEndpoint(e domain.WebhookEndpoint) Endpoint {
+	return Endpoint{
+		ID:              e.ID,
+		URL:             e.Url,
+		Description:     e.Description,
+		Events:          e.Events,
+		IsActive:        e.IsActive,
+		CreatedByUserID: e.CreatedByUserID,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+	}
+}
+
+// Delivery is the API representation of a delivery log entry. ResponseBody holds only the
+// status line of the last response, e.g. "404 Not Found"; bodies are not kept.
+type Delivery struct {
+	ID             pgtype.UUID        `json:"id"`
+	EndpointID     pgtype.UUID        `json:"endpointId"`
+	EventID        pgtype.UUID        `json:"eventId"`
+	EventType      string             `json:"eventType"`
+	Payload        json.RawMessage    `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"nextAttemptAt"`
+	ResponseStatus pgtype.Int4        `json:"responseStatus"`
+	ResponseBody   pgtype.Text        `json:"responseBody"`
+	LastError      pgtype.Text        `json:"lastError"`
+	DeliveredAt    pgtype.Timestamptz `json:"deliveredAt"`
+	ReplayOfID     pgtype.UUID        `json:"replayOfId"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+}
+
+// ToDelivery maps a stored delivery onto its API representation
+func ToDelivery(d domain.WebhookDelivery) Delivery {
+	return Delivery{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		ReplayOfID:     d.ReplayOfID,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+type WebhookService struct {
+	db       *db.DB
+	queries  *domain.Queries
+	auditSvc *audit.AuditService
+	client   *http.Client
+}
+
+// NewWebhookService creates the service, subscribes it to the audit stream and starts
+// delivering pending events every interval
+func NewWebhookService(database *db.DB, auditSvc *audit.AuditService, interval time.Duration) *WebhookService {
+	s := &WebhookService{
+		db:       database,
+		queries:  domain.New(database.Pool),
+		auditSvc: auditSvc,
+		client:   newDeliveryClient(),
+	}
+	if auditSvc != nil {
+		auditSvc.Subscribe(s)
+	}
+	go s.poll(interval)
+	return s
+}
+
+// EndpointInput is the editable part of an endpoint
+type EndpointInput struct {
+	URL         string
+	Description *string
+	Events      []string
+	IsActive    bool
+}
+
+func (in EndpointInput) validate() error {
+	if err := checkURL(in.URL); err != nil {
+		return err
+	}
+	for _, e := range in.Events {
+		if !isEventType(e) {
+			return fmt.Errorf("%w: %s", ErrUnknownEvent, e)
+		}
+	}
+	return nil
+}
+
+// CreateEndpoint registers an endpoint with a freshly generated signing secret, which is
+// returned only here and by RotateSecret
+func (s *WebhookService) CreateEndpoint(ctx context.Context, id, tenantID, actorID pgtype.UUID, in EndpointInput) (Endpoint, error) {
+	if err := in.validate(); err != nil {
+		return Endpoint{}, err
+	}
+
+	secret := newSecret()
+	endpoint, err := s.queries.CreateWebhookEndpoint(ctx, domain.CreateWebhookEndpointParams{
+		ID:              id,
+		TenantID:        tenantID,
+		Url:             in.URL,
+		Description:     optionalText(in.Description),
+		Secret:          secret,
+		Events:          in.Events,
+		IsActive:        in.IsActive,
+		CreatedByUserID: actorID,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "WebhookEndpoints", id.Bytes, map[string]interface{}{
+			"url":    in.URL,
+			"events": in.Events,
+		})
+	}
+
+	out := ToEndpoint(endpoint)
+	out.Secret = secret
+	return out, nil
+}
+
+func (s *WebhookService) GetEndpoint(ctx context.Context, tenantID, id pgtype.UUID) (Endpoint, error) {
+	endpoint, err := s.queries.GetWebhookEndpoint(ctx, domain.GetWebhookEndpointParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return ToEndpoint(endpoint), nil
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context, tenantID pgtype.UUID, params query.PaginationParams) ([]Endpoint, int64, error) {
+	endpoints, err := s.queries.ListWebhookEndpoints(ctx, domain.ListWebhookEndpointsParams{
+		TenantID: tenantID,
+		Limit:    params.Limit(),
+		Offset:   params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountWebhookEndpoints(ctx, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		items = append(items, ToEndpoint(e))
+	}
+	return items, total, nil
+}
+
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, tenantID, actorID, id pgtype.UUID, in EndpointInput) (Endpoint, error) {
+	if err := in.validate(); err != nil {
+		return Endpoint{}, err
+	}
+
+	current, err := s.queries.GetWebhookEndpoint(ctx, domain.GetWebhookEndpointParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	endpoint, err := s.queries.UpdateWebhookEndpoint(ctx, domain.UpdateWebhookEndpointParams{
+		TenantID:    tenantID,
+		ID:          id,
+		Url:         in.URL,
+		Description: optionalText(in.Description),
+		Events:      in.Events,
+		IsActive:    in.IsActive,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "WebhookEndpoints", id.Bytes, map[string]interface{}{
+			"url":       map[string]interface{}{"from": current.Url, "to": in.URL},
+			"events":    map[string]interface{}{"from": current.Events, "to": in.Events},
+			"is_active": map[string]interface{}{"from": current.IsActive, "to": in.IsActive},
+		})
+	}
+	return ToEndpoint(endpoint), nil
+}
+
+// RotateSecret replaces the signing secret and returns the endpoint with the new one
+func (s *WebhookService) RotateSecret(ctx context.Context, tenantID, actorID, id pgtype.UUID) (Endpoint, error) {
+	secret := newSecret()
+	endpoint, err := s.queries.SetWebhookEndpointSecret(ctx, domain.SetWebhookEndpointSecretParams{
+		TenantID: tenantID,
+		ID:       id,
+		Secret:   secret,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "UPDATE", "WebhookEndpoints", id.Bytes, map[string]interface{}{
+			"secret": "rotated",
+		})
+	}
+
+	out := ToEndpoint(endpoint)
+	out.Secret = secret
+	return out, nil
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, tenantID, actorID, id pgtype.UUID) error {
+	current, err := s.queries.GetWebhookEndpoint(ctx, domain.GetWebhookEndpointParams{
+		TenantID: tenantID,
+		ID:       id,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.queries.DeleteWebhookEndpoint(ctx, domain.DeleteWebhookEndpointParams{
+		TenantID: tenantID,
+		ID:       id,
+	}); err != nil {
+		return err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "DELETE", "WebhookEndpoints", id.Bytes, map[string]interface{}{
+			"url": current.Url,
+		})
+	}
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, tenantID, endpointID pgtype.UUID, status string, params query.PaginationParams) ([]Delivery, int64, error) {
+	deliveries, err := s.queries.ListWebhookDeliveries(ctx, domain.ListWebhookDeliveriesParams{
+		TenantID:   tenantID,
+		EndpointID: endpointID,
+		Status:     status,
+		Limit:      params.Limit(),
+		Offset:     params.Offset(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountWebhookDeliveries(ctx, domain.CountWebhookDeliveriesParams{
+		TenantID:   tenantID,
+		EndpointID: endpointID,
+		Status:     status,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		items = append(items, ToDelivery(d))
+	}
+	return items, total, nil
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, tenantID, endpointID, id pgtype.UUID) (Delivery, error) {
+	delivery, err := s.queries.GetWebhookDelivery(ctx, domain.GetWebhookDeliveryParams{
+		TenantID:   tenantID,
+		EndpointID: endpointID,
+		ID:         id,
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+	return ToDelivery(delivery), nil
+}
+
+// Replay queues a new delivery of the same event to the same endpoint. The original stays in
+// the log unchanged.
+func (s *WebhookService) Replay(ctx context.Context, tenantID, actorID, endpointID, id pgtype.UUID) (Delivery, error) {
+	original, err := s.GetDelivery(ctx, tenantID, endpointID, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	replay, err := s.queries.CreateWebhookDelivery(ctx, domain.CreateWebhookDeliveryParams{
+		ID:         newID(),
+		TenantID:   tenantID,
+		EndpointID: endpointID,
+		EventID:    original.EventID,
+		EventType:  original.EventType,
+		Payload:    original.Payload,
+		ReplayOfID: original.ID,
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	if s.auditSvc != nil {
+		s.auditSvc.Log(ctx, tenantID, actorID, "CREATE", "WebhookDeliveries", replay.ID.Bytes, map[string]interface{}{
+			"replay_of":  original.ID,
+			"event_type": original.EventType,
+		})
+	}
+	return ToDelivery(replay), nil
+}
+
+// HandleAuditLog queues a delivery to every active endpoint subscribed to the event types the
+// audit entry raises
+func (s *WebhookService) HandleAuditLog(ctx context.Context, entry domain.AuditLog) {
+	for _, eventType := range eventTypesFor(entry) {
+		endpoints, err := s.queries.ListSubscribedWebhookEndpoints(ctx, domain.ListSubscribedWebhookEndpointsParams{
+			TenantID:  entry.TenantID,
+			EventType: eventType,
+		})
+		if err != nil {
+			log.Printf("webhooks failed loading endpoints for %s: %v", eventType, err)
+			continue
+		}
+		if len(endpoints) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(newEvent(entry, eventType))
+		if err != nil {
+			log.Printf("webhooks failed serializing %s: %v", eventType, err)
+			continue
+		}
+
+		for _, endpoint := range endpoints {
+			if _, err := s.queries.CreateWebhookDelivery(ctx, domain.CreateWebhookDeliveryParams{
+				ID:         newID(),
+				TenantID:   entry.TenantID,
+				EndpointID: endpoint.ID,
+				EventID:    entry.ID,
+				EventType:  eventType,
+				Payload:    payload,
+			}); err != nil {
+				log.Printf("webhooks failed queueing %s delivery: %v", eventType, err)
+			}
+		}
+	}
+}
+
+func newID() pgtype.UUID {
+	var id pgtype.UUID
+	id.Bytes = uuid.New()
+	id.Valid = true
+	return id
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil || *v == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
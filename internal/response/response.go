@@ -78,6 +78,9 @@ func DBError(w http.ResponseWriter, err error) {
 		case "23503": // foreign_key_violation
 			Error(w, http.StatusBadRequest, "Invalid reference to a related record")
 			return
+		case "23514": // check_violation
+			Error(w, http.StatusBadRequest, "The change violates a data integrity rule")
+			return
 		}
 	}
 	// Log the actual error for debugging, but hide it from the client
@@ -0,0 +1,76 @@
+// Package storage abstracts where generated and uploaded files live, so that
+// the local-disk implementation can later be swapped for an object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when no object exists under the requested key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store persists opaque blobs addressed by slash-separated keys.
+type Store interface {
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStore keeps objects as plain files below a root directory.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at dir. Directories are created lazily.
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{root: dir}
+}
+
+func (s *LocalStore) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	p, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return nil, fmt.Errorf("storage: creating directory: %w", err)
+	}
+	return os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// resolve maps a key onto the filesystem and rejects keys escaping the root.
+func (s *LocalStore) resolve(key string) (string, error) {
+	clean := filepath.Clean("/" + strings.TrimSpace(key))
+	if clean == "/" {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
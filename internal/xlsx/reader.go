@@ -0,0 +1,234 @@
+// Package xlsx implements just enough of the Office Open XML spreadsheet format
+// to exchange flat tables (one header row followed by data rows) without
+// pulling a full spreadsheet library into the build.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrNoSheets is returned when the workbook does not contain any worksheet.
+var ErrNoSheets = errors.New("xlsx: workbook contains no worksheets")
+
+// ErrTooManyCells is returned when the rows of a worksheet span more cells than
+// the reader is willing to hold in memory.
+var ErrTooManyCells = errors.New("xlsx: worksheet is too large")
+
+// MaxColumns is the number of columns in a worksheet (A to XFD).
+const MaxColumns = 16384
+
+// maxCells bounds the cells held for one worksheet, counting the blanks that
+// pad each row out to its last referenced column.
+const maxCells = 4 << 20
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type sharedStringsXML struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadFirstSheet returns every row of the first worksheet as plain strings.
+// Ragged rows are padded so that cells stay aligned with their column letter.
+func ReadFirstSheet(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: not a valid workbook archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst sharedStringsXML
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, fmt.Errorf("xlsx: reading shared strings: %w", err)
+		}
+		shared = make([]string, len(sst.Items))
+		for i, item := range sst.Items {
+			if len(item.Runs) == 0 {
+				shared[i] = item.Text
+				continue
+			}
+			var sb strings.Builder
+			for _, run := range item.Runs {
+				sb.WriteString(run.Text)
+			}
+			shared[i] = sb.String()
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: worksheet %s missing from archive", sheetPath)
+	}
+
+	var sheet worksheetXML
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, fmt.Errorf("xlsx: reading worksheet: %w", err)
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	total := 0
+	for _, row := range sheet.Rows {
+		// Cells are placed by column index so that sparse or out-of-order
+		// references only ever size the row to its widest referenced column.
+		width := 0
+		cols := make([]int, len(row.Cells))
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				idx, err := columnIndex(cell.Ref)
+				if err != nil {
+					return nil, err
+				}
+				col = idx
+			}
+			if col >= MaxColumns {
+				return nil, fmt.Errorf("xlsx: cell %s is beyond the last column", cell.Ref)
+			}
+			cols[i] = col
+			if col+1 > width {
+				width = col + 1
+			}
+		}
+		total += width
+		if total > maxCells {
+			return nil, ErrTooManyCells
+		}
+
+		values := make([]string, width)
+		for i, cell := range row.Cells {
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("xlsx: cell %s references unknown shared string", cell.Ref)
+				}
+				value = shared[idx]
+			case "inlineStr":
+				value = cell.Inline.Text
+			default:
+				value = cell.Value
+			}
+			values[cols[i]] = value
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("xlsx: workbook.xml missing from archive")
+	}
+
+	var wb workbookXML
+	if err := decodeZipXML(wbFile, &wb); err != nil {
+		return "", fmt.Errorf("xlsx: reading workbook: %w", err)
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrNoSheets
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+
+	var rels relationshipsXML
+	if err := decodeZipXML(relsFile, &rels); err != nil {
+		return "", fmt.Errorf("xlsx: reading workbook relationships: %w", err)
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), nil
+		}
+		return path.Join("xl", target), nil
+	}
+
+	return "", fmt.Errorf("xlsx: relationship %s for first sheet not found", wb.Sheets[0].RID)
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, 256<<20)).Decode(v)
+}
+
+// columnIndex converts a cell reference such as "AB12" into a zero-based column index.
+// References past the last worksheet column (XFD) are rejected.
+func columnIndex(ref string) (int, error) {
+	idx := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		idx = idx*26 + int(r-'A'+1)
+		n++
+		if idx > MaxColumns {
+			return 0, fmt.Errorf("xlsx: cell reference %q is beyond the last column", ref)
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	return idx - 1, nil
+}
@@ -0,0 +1,170 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+		ok   bool
+	}{
+		{"A1", 0, true},
+		{"Z9", 25, true},
+		{"AA10", 26, true},
+		{"AB12", 27, true},
+		{"XFD1", MaxColumns - 1, true},
+		{"XFE1", 0, false},
+		{"ZZZZZZZZ1", 0, false},
+		{"ZZZZZZZZZZZZZZZZZZZZ1", 0, false},
+		{"12", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := columnIndex(tt.ref)
+		if (err == nil) != tt.ok {
+			t.Errorf("columnIndex(%q) error = %v, want ok %v", tt.ref, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
+
+// workbook builds a minimal archive whose first sheet holds sheetData.
+func workbook(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="S" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": workbookRelsXML,
+		"xl/sharedStrings.xml":       `<sst><si><t>shared</t></si><si><r><t>ru</t></r><r><t>ns</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadFirstSheet(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheet string
+		want  [][]string
+		err   bool
+	}{
+		{
+			name:  "values and string types",
+			sheet: `<row><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>inline</t></is></c><c r="D1"><v>42</v></c></row>`,
+			want:  [][]string{{"shared", "runs", "inline", "42"}},
+		},
+		{
+			name:  "sparse cells are padded",
+			sheet: `<row><c r="B1"><v>b</v></c><c r="D1"><v>d</v></c></row>`,
+			want:  [][]string{{"", "b", "", "d"}},
+		},
+		{
+			name:  "out of order cells keep their column",
+			sheet: `<row><c r="C1"><v>c</v></c><c r="A1"><v>a</v></c></row>`,
+			want:  [][]string{{"a", "", "c"}},
+		},
+		{
+			name:  "cells without references follow their position",
+			sheet: `<row><c><v>x</v></c><c><v>y</v></c></row>`,
+			want:  [][]string{{"x", "y"}},
+		},
+		{
+			name:  "last column",
+			sheet: `<row><c r="XFD1"><v>end</v></c></row>`,
+			want:  [][]string{append(make([]string, MaxColumns-1), "end")},
+		},
+		{
+			name:  "column beyond XFD",
+			sheet: `<row><c r="ZZZZZZZZ1"><v>x</v></c></row>`,
+			err:   true,
+		},
+		{
+			name:  "unknown shared string",
+			sheet: `<row><c r="A1" t="s"><v>7</v></c></row>`,
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFirstSheet(workbook(t, tt.sheet))
+			if tt.err {
+				if err == nil {
+					t.Fatalf("ReadFirstSheet() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadFirstSheet() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadFirstSheet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFirstSheetTooManyCells(t *testing.T) {
+	row := `<row><c r="XFD1"><v>x</v></c></row>`
+	rows := strings.Repeat(row, maxCells/MaxColumns+1)
+	if _, err := ReadFirstSheet(workbook(t, rows)); !errors.Is(err, ErrTooManyCells) {
+		t.Fatalf("ReadFirstSheet() error = %v, want ErrTooManyCells", err)
+	}
+}
+
+func TestReadFirstSheetRoundTrip(t *testing.T) {
+	want := [][]string{
+		{"Employee No", "Name"},
+		{"E001", "Ada <Lovelace> & co"},
+		{"E002", ""},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Employees")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range want {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadFirstSheet(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadFirstSheet() error = %v", err)
+	}
+	// The writer may omit trailing empty cells
+	for i := range got {
+		for len(got[i]) < len(want[i]) {
+			got[i] = append(got[i], "")
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFirstSheet() = %q, want %q", got, want)
+	}
+}
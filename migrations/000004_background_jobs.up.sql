@@ -0,0 +1,17 @@
+CREATE TABLE background_jobs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    kind TEXT NOT NULL, -- employee_import, ...
+    status TEXT NOT NULL DEFAULT 'queued', -- queued | running | succeeded | failed
+    progress INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    result JSONB,
+    error TEXT,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_background_jobs_tenant ON background_jobs (tenant_id, created_at DESC);
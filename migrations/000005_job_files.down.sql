@@ -0,0 +1,4 @@
+ALTER TABLE background_jobs
+DROP COLUMN IF EXISTS content_type,
+DROP COLUMN IF EXISTS file_name,
+DROP COLUMN IF EXISTS file_key;
@@ -0,0 +1,5 @@
+-- Downloadable artifacts produced by background jobs (exports, ...)
+ALTER TABLE background_jobs
+ADD COLUMN file_key TEXT,
+ADD COLUMN file_name TEXT,
+ADD COLUMN content_type TEXT;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_employees_manager_cycle ON employees;
+DROP FUNCTION IF EXISTS prevent_employee_manager_cycle();
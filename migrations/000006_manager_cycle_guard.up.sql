@@ -0,0 +1,51 @@
+-- Reject manager assignments that point at another tenant or close a reporting cycle.
+-- The service layer performs the same checks with friendlier errors; this trigger is
+-- the backstop for concurrent updates and for writes that bypass the API.
+CREATE OR REPLACE FUNCTION prevent_employee_manager_cycle() RETURNS trigger AS $$
+DECLARE
+    current_id UUID := NEW.manager_id;
+    hops INTEGER := 0;
+BEGIN
+    IF NEW.manager_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.manager_id = NEW.id THEN
+        RAISE EXCEPTION 'employee % cannot be their own manager', NEW.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'employees_manager_no_cycle';
+    END IF;
+
+    -- Serialise hierarchy changes per tenant so two concurrent updates cannot each
+    -- pass the walk below and together form a cycle (A->B and B->A).
+    PERFORM pg_advisory_xact_lock(hashtext('employees.manager_id:' || NEW.tenant_id::text));
+
+    IF NOT EXISTS (
+        SELECT 1 FROM employees WHERE id = NEW.manager_id AND tenant_id = NEW.tenant_id
+    ) THEN
+        RAISE EXCEPTION 'manager % does not belong to tenant %', NEW.manager_id, NEW.tenant_id
+            USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'employees_manager_same_tenant';
+    END IF;
+
+    WHILE current_id IS NOT NULL LOOP
+        IF current_id = NEW.id THEN
+            RAISE EXCEPTION 'assigning manager % to employee % would create a reporting cycle', NEW.manager_id, NEW.id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'employees_manager_no_cycle';
+        END IF;
+
+        -- Guard against pre-existing cycles further up that do not involve this employee
+        hops := hops + 1;
+        EXIT WHEN hops > 10000;
+
+        SELECT manager_id INTO current_id
+        FROM employees
+        WHERE id = current_id AND tenant_id = NEW.tenant_id;
+    END LOOP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_employees_manager_cycle
+BEFORE INSERT OR UPDATE OF manager_id ON employees
+FOR EACH ROW
+EXECUTE FUNCTION prevent_employee_manager_cycle();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_employees_department;
+DROP INDEX IF EXISTS idx_departments_parent;
+DROP TRIGGER IF EXISTS trg_departments_parent_cycle ON departments;
+DROP FUNCTION IF EXISTS prevent_department_parent_cycle();
@@ -0,0 +1,50 @@
+-- Reject parent department assignments that point at another tenant or close a cycle.
+-- Mirrors prevent_employee_manager_cycle for the department hierarchy.
+CREATE OR REPLACE FUNCTION prevent_department_parent_cycle() RETURNS trigger AS $$
+DECLARE
+    current_id UUID := NEW.parent_department_id;
+    hops INTEGER := 0;
+BEGIN
+    IF NEW.parent_department_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.parent_department_id = NEW.id THEN
+        RAISE EXCEPTION 'department % cannot be its own parent', NEW.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'departments_parent_no_cycle';
+    END IF;
+
+    PERFORM pg_advisory_xact_lock(hashtext('departments.parent_department_id:' || NEW.tenant_id::text));
+
+    IF NOT EXISTS (
+        SELECT 1 FROM departments WHERE id = NEW.parent_department_id AND tenant_id = NEW.tenant_id
+    ) THEN
+        RAISE EXCEPTION 'parent department % does not belong to tenant %', NEW.parent_department_id, NEW.tenant_id
+            USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'departments_parent_same_tenant';
+    END IF;
+
+    WHILE current_id IS NOT NULL LOOP
+        IF current_id = NEW.id THEN
+            RAISE EXCEPTION 'moving department % under % would create a cycle', NEW.id, NEW.parent_department_id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'departments_parent_no_cycle';
+        END IF;
+
+        hops := hops + 1;
+        EXIT WHEN hops > 10000;
+
+        SELECT parent_department_id INTO current_id
+        FROM departments
+        WHERE id = current_id AND tenant_id = NEW.tenant_id;
+    END LOOP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_departments_parent_cycle
+BEFORE INSERT OR UPDATE OF parent_department_id ON departments
+FOR EACH ROW
+EXECUTE FUNCTION prevent_department_parent_cycle();
+
+CREATE INDEX idx_departments_parent ON departments (tenant_id, parent_department_id);
+CREATE INDEX idx_employees_department ON employees (tenant_id, department_id);
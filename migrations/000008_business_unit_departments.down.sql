@@ -0,0 +1,3 @@
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_business_unit_department_fk;
+
+DROP TABLE IF EXISTS business_unit_departments;
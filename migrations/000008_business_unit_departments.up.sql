@@ -0,0 +1,25 @@
+-- Which departments operate at which business unit (site)
+CREATE TABLE business_unit_departments (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    business_unit_id UUID NOT NULL REFERENCES business_units (id) ON DELETE CASCADE,
+    department_id UUID NOT NULL REFERENCES departments (id) ON DELETE CASCADE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (business_unit_id, department_id)
+);
+
+CREATE INDEX idx_business_unit_departments_tenant ON business_unit_departments (tenant_id, department_id);
+
+-- Keep every existing placement valid
+INSERT INTO business_unit_departments (tenant_id, business_unit_id, department_id)
+SELECT DISTINCT tenant_id, business_unit_id, department_id
+FROM employees
+WHERE business_unit_id IS NOT NULL AND department_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- An employee with both a site and a department must sit in a department that exists at that site.
+-- MATCH SIMPLE skips the check when either column is NULL.
+ALTER TABLE employees
+ADD CONSTRAINT employees_business_unit_department_fk
+FOREIGN KEY (business_unit_id, department_id)
+REFERENCES business_unit_departments (business_unit_id, department_id);
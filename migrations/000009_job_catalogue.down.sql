@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS employee_competencies;
+DROP TABLE IF EXISTS job_title_competencies;
+DROP TABLE IF EXISTS competencies;
+
+ALTER TABLE job_titles ADD COLUMN grade TEXT;
+
+UPDATE job_titles jt
+SET grade = g.code
+FROM job_grades g
+WHERE g.id = jt.grade_id;
+
+ALTER TABLE job_titles
+DROP COLUMN IF EXISTS default_department_id,
+DROP COLUMN IF EXISTS grade_id;
+
+DROP TABLE IF EXISTS job_grades;
@@ -0,0 +1,84 @@
+-- Job grades with pay bands
+CREATE TABLE job_grades (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    level INTEGER NOT NULL DEFAULT 0, -- ordering, 1 = most senior
+    currency TEXT NOT NULL DEFAULT 'GBP',
+    min_pay NUMERIC(12, 2),
+    mid_pay NUMERIC(12, 2),
+    max_pay NUMERIC(12, 2),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code),
+    CONSTRAINT job_grades_pay_band_order CHECK (
+        (min_pay IS NULL OR mid_pay IS NULL OR min_pay <= mid_pay)
+        AND (mid_pay IS NULL OR max_pay IS NULL OR mid_pay <= max_pay)
+        AND (min_pay IS NULL OR max_pay IS NULL OR min_pay <= max_pay)
+    )
+);
+
+CREATE INDEX idx_job_grades_tenant ON job_grades (tenant_id, level);
+
+-- Promote the free-text job_titles.grade values to grades
+INSERT INTO job_grades (id, tenant_id, code, name, level)
+SELECT gen_random_uuid(), tenant_id, grade, grade, (DENSE_RANK() OVER (PARTITION BY tenant_id ORDER BY grade))::int
+FROM (SELECT DISTINCT tenant_id, grade FROM job_titles WHERE grade IS NOT NULL AND grade <> '') g;
+
+ALTER TABLE job_titles
+ADD COLUMN grade_id UUID REFERENCES job_grades (id) ON DELETE SET NULL,
+ADD COLUMN default_department_id UUID REFERENCES departments (id) ON DELETE SET NULL;
+
+UPDATE job_titles jt
+SET grade_id = g.id
+FROM job_grades g
+WHERE g.tenant_id = jt.tenant_id AND g.code = jt.grade;
+
+ALTER TABLE job_titles DROP COLUMN grade;
+
+-- Competencies: skills, certifications and controlled documents an employee must be trained on
+CREATE TABLE competencies (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    kind TEXT NOT NULL DEFAULT 'skill', -- skill | certification | document
+    document_ref TEXT, -- controlled document number for read-and-understood training
+    validity_months INTEGER, -- NULL = does not expire
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code),
+    CONSTRAINT competencies_kind_check CHECK (kind IN ('skill', 'certification', 'document')),
+    CONSTRAINT competencies_validity_check CHECK (validity_months IS NULL OR validity_months > 0)
+);
+
+-- Competencies required by a job title
+CREATE TABLE job_title_competencies (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    job_title_id UUID NOT NULL REFERENCES job_titles (id) ON DELETE CASCADE,
+    competency_id UUID NOT NULL REFERENCES competencies (id) ON DELETE CASCADE,
+    is_mandatory BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_title_id, competency_id)
+);
+
+-- Competencies an employee has achieved; one row per achievement so history is kept
+CREATE TABLE employee_competencies (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    employee_id UUID NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    competency_id UUID NOT NULL REFERENCES competencies (id) ON DELETE CASCADE,
+    achieved_on DATE NOT NULL,
+    expires_on DATE,
+    source TEXT NOT NULL DEFAULT 'manual', -- manual | training
+    notes TEXT,
+    recorded_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT employee_competencies_dates_check CHECK (expires_on IS NULL OR expires_on >= achieved_on)
+);
+
+CREATE INDEX idx_employee_competencies_employee ON employee_competencies (tenant_id, employee_id, competency_id);
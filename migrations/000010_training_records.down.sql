@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS training_records;
+DROP TABLE IF EXISTS training_sessions;
+DROP TABLE IF EXISTS training_courses;
@@ -0,0 +1,66 @@
+-- Training courses; completing a course can grant a competency
+CREATE TABLE training_courses (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    competency_id UUID NULL REFERENCES competencies (id) ON DELETE SET NULL,
+    validity_months INTEGER, -- NULL = completion does not expire
+    is_mandatory BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code),
+    CONSTRAINT training_courses_validity_check CHECK (validity_months IS NULL OR validity_months > 0)
+);
+
+-- Scheduled deliveries of a course
+CREATE TABLE training_sessions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    course_id UUID NOT NULL REFERENCES training_courses (id) ON DELETE CASCADE,
+    trainer_employee_id UUID NULL REFERENCES employees (id) ON DELETE SET NULL,
+    external_trainer TEXT, -- trainer name when delivered by a third party
+    business_unit_id UUID NULL REFERENCES business_units (id) ON DELETE SET NULL,
+    location TEXT,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'scheduled', -- scheduled | completed | cancelled
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT training_sessions_status_check CHECK (status IN ('scheduled', 'completed', 'cancelled')),
+    CONSTRAINT training_sessions_dates_check CHECK (ends_at IS NULL OR ends_at >= starts_at)
+);
+
+CREATE INDEX idx_training_sessions_course ON training_sessions (tenant_id, course_id, starts_at);
+
+-- One row per completion so history is kept. A record only counts once signed off by the trainer.
+CREATE TABLE training_records (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    employee_id UUID NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    course_id UUID NOT NULL REFERENCES training_courses (id) ON DELETE CASCADE,
+    session_id UUID NULL REFERENCES training_sessions (id) ON DELETE SET NULL,
+    completed_on DATE NOT NULL,
+    expires_on DATE,
+    result TEXT NOT NULL DEFAULT 'pass', -- pass | fail
+    notes TEXT,
+    evidence_key TEXT, -- storage key of the uploaded certificate or attendance sheet
+    evidence_file_name TEXT,
+    evidence_content_type TEXT,
+    evidence_size BIGINT,
+    signed_off_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    signed_off_at TIMESTAMPTZ,
+    employee_competency_id UUID NULL REFERENCES employee_competencies (id) ON DELETE SET NULL,
+    recorded_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT training_records_result_check CHECK (result IN ('pass', 'fail')),
+    CONSTRAINT training_records_dates_check CHECK (expires_on IS NULL OR expires_on >= completed_on)
+);
+
+CREATE INDEX idx_training_records_employee ON training_records (tenant_id, employee_id, course_id);
+CREATE INDEX idx_training_records_expiry ON training_records (tenant_id, expires_on)
+WHERE signed_off_at IS NOT NULL;
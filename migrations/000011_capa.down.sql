@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ncr_status_history;
+DROP TABLE IF EXISTS ncr_actions;
+DROP TABLE IF EXISTS ncrs;
@@ -0,0 +1,83 @@
+-- Non-conformance reports (NCRs) and their corrective / preventive actions (CAPA)
+CREATE TABLE ncrs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    number INTEGER NOT NULL, -- sequential per tenant, shown as NCR-000123
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    business_unit_id UUID NOT NULL REFERENCES business_units (id),
+    department_id UUID NOT NULL REFERENCES departments (id),
+    reported_by_employee_id UUID NOT NULL REFERENCES employees (id),
+    owner_employee_id UUID NULL REFERENCES employees (id) ON DELETE SET NULL,
+    detected_on DATE NOT NULL,
+    severity TEXT NOT NULL, -- minor | major | critical
+    classification TEXT NOT NULL, -- product | process | supplier | customer_complaint | audit | safety | environmental | other
+    status TEXT NOT NULL DEFAULT 'open',
+    -- Root cause analysis
+    containment_action TEXT,
+    root_cause_method TEXT, -- five_whys | fishbone | fault_tree | other
+    root_cause TEXT,
+    -- Verification of effectiveness
+    verified_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    verified_at TIMESTAMPTZ,
+    verification_notes TEXT,
+    is_effective BOOLEAN,
+    closed_at TIMESTAMPTZ,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ncrs_tenant_number_key UNIQUE (tenant_id, number),
+    CONSTRAINT ncrs_severity_check CHECK (severity IN ('minor', 'major', 'critical')),
+    CONSTRAINT ncrs_classification_check CHECK (
+        classification IN (
+            'product', 'process', 'supplier', 'customer_complaint', 'audit', 'safety', 'environmental', 'other'
+        )
+    ),
+    CONSTRAINT ncrs_status_check CHECK (
+        status IN (
+            'open', 'investigation', 'action_planned', 'implementation', 'verification', 'closed', 'cancelled'
+        )
+    ),
+    CONSTRAINT ncrs_root_cause_method_check CHECK (
+        root_cause_method IS NULL OR root_cause_method IN ('five_whys', 'fishbone', 'fault_tree', 'other')
+    )
+);
+
+CREATE INDEX idx_ncrs_tenant_status ON ncrs (tenant_id, status);
+CREATE INDEX idx_ncrs_business_unit ON ncrs (tenant_id, business_unit_id);
+
+-- Corrective, preventive and containment actions raised against an NCR
+CREATE TABLE ncr_actions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    ncr_id UUID NOT NULL REFERENCES ncrs (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL, -- corrective | preventive | containment
+    description TEXT NOT NULL,
+    assignee_employee_id UUID NOT NULL REFERENCES employees (id),
+    due_date DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open', -- open | done | cancelled
+    completed_at TIMESTAMPTZ,
+    completion_notes TEXT,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ncr_actions_kind_check CHECK (kind IN ('corrective', 'preventive', 'containment')),
+    CONSTRAINT ncr_actions_status_check CHECK (status IN ('open', 'done', 'cancelled'))
+);
+
+CREATE INDEX idx_ncr_actions_ncr ON ncr_actions (tenant_id, ncr_id);
+CREATE INDEX idx_ncr_actions_assignee ON ncr_actions (tenant_id, assignee_employee_id, status);
+
+-- Every status change of an NCR, in order
+CREATE TABLE ncr_status_history (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    ncr_id UUID NOT NULL REFERENCES ncrs (id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    comment TEXT,
+    actor_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ncr_status_history_ncr ON ncr_status_history (tenant_id, ncr_id, created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_findings;
+DROP TABLE IF EXISTS audit_checklist_items;
+DROP TABLE IF EXISTS internal_audit_team;
+DROP TABLE IF EXISTS internal_audits;
+DROP TABLE IF EXISTS audit_programmes;
@@ -0,0 +1,89 @@
+-- Internal audit programmes, the audits scheduled under them, their checklists and findings
+CREATE TABLE audit_programmes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    name TEXT NOT NULL,
+    year INTEGER NOT NULL,
+    objectives TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT audit_programmes_tenant_name_key UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE internal_audits (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    programme_id UUID NOT NULL REFERENCES audit_programmes (id),
+    title TEXT NOT NULL,
+    scope TEXT,
+    criteria TEXT, -- e.g. ISO 9001:2015 clauses 7-8
+    business_unit_id UUID NOT NULL REFERENCES business_units (id),
+    department_id UUID NULL REFERENCES departments (id), -- NULL audits the whole business unit
+    lead_auditor_employee_id UUID NOT NULL REFERENCES employees (id),
+    planned_start DATE NOT NULL,
+    planned_end DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'planned', -- planned | in_progress | completed | cancelled
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    summary TEXT,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT internal_audits_dates_check CHECK (planned_end >= planned_start),
+    CONSTRAINT internal_audits_status_check CHECK (
+        status IN ('planned', 'in_progress', 'completed', 'cancelled')
+    )
+);
+
+CREATE INDEX idx_internal_audits_programme ON internal_audits (tenant_id, programme_id);
+CREATE INDEX idx_internal_audits_schedule ON internal_audits (tenant_id, planned_start, planned_end);
+
+-- Audit team members besides the lead auditor
+CREATE TABLE internal_audit_team (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    audit_id UUID NOT NULL REFERENCES internal_audits (id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES employees (id),
+    role TEXT NOT NULL DEFAULT 'auditor', -- auditor | technical_expert | observer
+    PRIMARY KEY (audit_id, employee_id),
+    CONSTRAINT internal_audit_team_role_check CHECK (role IN ('auditor', 'technical_expert', 'observer'))
+);
+
+CREATE TABLE audit_checklist_items (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    audit_id UUID NOT NULL REFERENCES internal_audits (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    clause TEXT,
+    question TEXT NOT NULL,
+    result TEXT, -- conforming | nonconforming | not_applicable, NULL until answered
+    notes TEXT,
+    answered_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    answered_at TIMESTAMPTZ,
+    CONSTRAINT audit_checklist_items_result_check CHECK (
+        result IS NULL OR result IN ('conforming', 'nonconforming', 'not_applicable')
+    )
+);
+
+CREATE INDEX idx_audit_checklist_items_audit ON audit_checklist_items (tenant_id, audit_id, position);
+
+CREATE TABLE audit_findings (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    audit_id UUID NOT NULL REFERENCES internal_audits (id) ON DELETE CASCADE,
+    checklist_item_id UUID NULL REFERENCES audit_checklist_items (id) ON DELETE SET NULL,
+    classification TEXT NOT NULL, -- major | minor | observation
+    clause TEXT,
+    description TEXT NOT NULL,
+    evidence TEXT,
+    ncr_id UUID NULL REFERENCES ncrs (id) ON DELETE SET NULL, -- the CAPA raised from this finding
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT audit_findings_classification_check CHECK (
+        classification IN ('major', 'minor', 'observation')
+    )
+);
+
+CREATE INDEX idx_audit_findings_audit ON audit_findings (tenant_id, audit_id);
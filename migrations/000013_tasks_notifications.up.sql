@@ -0,0 +1,49 @@
+-- Work items assigned to an employee or to everyone holding a role. entity_type / entity_id
+-- point at the record the task is about, using the same names as audit_logs.entity_type.
+CREATE TABLE tasks (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    kind TEXT NOT NULL, -- approval | acknowledgement | review | action | other
+    title TEXT NOT NULL,
+    description TEXT,
+    assignee_employee_id UUID NULL REFERENCES employees (id),
+    assignee_role_code TEXT NULL,
+    due_date DATE,
+    status TEXT NOT NULL DEFAULT 'open', -- open | completed | cancelled
+    outcome TEXT, -- e.g. approved / rejected for approvals
+    completed_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    completed_at TIMESTAMPTZ,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tasks_assignee_check CHECK (
+        (assignee_employee_id IS NULL) <> (assignee_role_code IS NULL)
+    ),
+    CONSTRAINT tasks_kind_check CHECK (
+        kind IN ('approval', 'acknowledgement', 'review', 'action', 'other')
+    ),
+    CONSTRAINT tasks_status_check CHECK (status IN ('open', 'completed', 'cancelled'))
+);
+
+CREATE INDEX idx_tasks_employee ON tasks (tenant_id, assignee_employee_id, status);
+CREATE INDEX idx_tasks_role ON tasks (tenant_id, assignee_role_code, status);
+CREATE INDEX idx_tasks_entity ON tasks (tenant_id, entity_type, entity_id);
+
+-- In-app notifications, one row per recipient user
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL, -- e.g. task_assigned
+    title TEXT NOT NULL,
+    body TEXT,
+    entity_type TEXT,
+    entity_id UUID,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user ON notifications (tenant_id, user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications (tenant_id, user_id) WHERE read_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS email_outbox;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_templates;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
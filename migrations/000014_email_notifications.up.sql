@@ -0,0 +1,58 @@
+-- Preferred language for notification templates, e.g. en or de-AT
+ALTER TABLE users ADD COLUMN locale TEXT NOT NULL DEFAULT 'en';
+
+-- Tenant overrides of the built-in notification templates. Subjects and text bodies use
+-- text/template syntax, HTML bodies html/template.
+CREATE TABLE notification_templates (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    kind TEXT NOT NULL, -- notifications.kind, e.g. task_assigned
+    locale TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, kind, locale)
+);
+
+-- Per-user opt-outs by notification kind. A missing row means both channels are on.
+CREATE TABLE notification_preferences (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind)
+);
+
+-- Rendered emails waiting to be sent. Rows are written in the same transaction as the
+-- event that caused them and delivered by the mailer with retries. While a row is being
+-- sent, next_attempt_at holds the lease expiry so a crashed sender's rows are picked up again.
+CREATE TABLE email_outbox (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    kind TEXT NOT NULL,
+    to_address TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued', -- queued | sending | sent | failed
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT email_outbox_status_check CHECK (
+        status IN ('queued', 'sending', 'sent', 'failed')
+    )
+);
+
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at)
+WHERE
+    status IN ('queued', 'sending');
+
+CREATE INDEX idx_email_outbox_tenant ON email_outbox (tenant_id, created_at DESC);
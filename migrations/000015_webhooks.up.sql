@@ -0,0 +1,49 @@
+-- Tenant-registered receivers of domain events. events lists the subscribed event types,
+-- e.g. employee.created, or '*' for all of them. The secret signs each delivery (HMAC-SHA256).
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    url TEXT NOT NULL,
+    description TEXT,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT webhook_endpoints_events_check CHECK (cardinality(events) > 0)
+);
+
+CREATE INDEX idx_webhook_endpoints_tenant ON webhook_endpoints (tenant_id)
+WHERE
+    is_active;
+
+-- One row per event per endpoint, retried with exponential backoff. While a delivery is in
+-- flight, next_attempt_at holds the lease expiry. Replays are new rows pointing at the original.
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL, -- audit_logs.id of the mutation
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending | delivering | succeeded | failed
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_status INT,
+    response_body TEXT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    replay_of_id UUID NULL REFERENCES webhook_deliveries (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT webhook_deliveries_status_check CHECK (
+        status IN ('pending', 'delivering', 'succeeded', 'failed')
+    )
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+WHERE
+    status IN ('pending', 'delivering');
+
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries (endpoint_id, created_at DESC);
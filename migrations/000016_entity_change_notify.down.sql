@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_audit_logs_notify ON audit_logs;
+DROP FUNCTION IF EXISTS notify_entity_change();
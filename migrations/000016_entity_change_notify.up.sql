@@ -0,0 +1,22 @@
+-- Announce every stored audit entry on the entity_changes channel so each API replica can
+-- push it to its connected event streams. Raising it from the table rather than the API
+-- means writes from any replica reach listeners on all of them.
+CREATE OR REPLACE FUNCTION notify_entity_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('entity_changes', json_build_object(
+        'id', NEW.id,
+        'tenantId', NEW.tenant_id,
+        'entityType', NEW.entity_type,
+        'entityId', NEW.entity_id,
+        'action', NEW.action,
+        'actorId', NEW.actor_id,
+        'occurredAt', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_notify
+AFTER INSERT ON audit_logs
+FOR EACH ROW
+EXECUTE FUNCTION notify_entity_change();
@@ -0,0 +1,25 @@
+DELETE FROM user_rbac_roles
+WHERE
+    business_unit_id IS NULL
+    OR department_id IS NULL;
+
+ALTER TABLE user_rbac_roles DROP CONSTRAINT user_rbac_roles_scope_key;
+
+ALTER TABLE user_rbac_roles
+ADD PRIMARY KEY (
+    tenant_id,
+    user_id,
+    role_id,
+    business_unit_id,
+    department_id
+);
+
+DROP INDEX IF EXISTS uq_rbac_roles_external_id;
+
+DROP INDEX IF EXISTS uq_users_external_id;
+
+ALTER TABLE rbac_roles DROP COLUMN IF EXISTS external_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
+
+DROP TABLE IF EXISTS scim_tokens;
@@ -0,0 +1,45 @@
+-- Bearer tokens an identity provider uses to provision a tenant over SCIM 2.0. Only the
+-- SHA-256 of each token is stored; the token itself is shown once when it is issued.
+CREATE TABLE scim_tokens (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scim_tokens_tenant ON scim_tokens (tenant_id);
+
+-- The identity provider's own identifiers for provisioned users and groups
+ALTER TABLE users ADD COLUMN external_id TEXT;
+
+ALTER TABLE rbac_roles ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX uq_users_external_id ON users (tenant_id, external_id)
+WHERE
+    external_id IS NOT NULL;
+
+CREATE UNIQUE INDEX uq_rbac_roles_external_id ON rbac_roles (tenant_id, external_id)
+WHERE
+    external_id IS NOT NULL;
+
+-- Role grants may be tenant-wide: SCIM group memberships carry no site or department. The
+-- primary key made both scope columns NOT NULL, so it becomes a unique constraint that
+-- treats a missing scope as one value.
+ALTER TABLE user_rbac_roles DROP CONSTRAINT user_rbac_roles_pkey;
+
+ALTER TABLE user_rbac_roles ALTER COLUMN business_unit_id DROP NOT NULL;
+
+ALTER TABLE user_rbac_roles ALTER COLUMN department_id DROP NOT NULL;
+
+ALTER TABLE user_rbac_roles
+ADD CONSTRAINT user_rbac_roles_scope_key UNIQUE NULLS NOT DISTINCT (
+    tenant_id,
+    user_id,
+    role_id,
+    business_unit_id,
+    department_id
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS sso_login_states;
+
+DROP TABLE IF EXISTS sso_identities;
+
+DROP TABLE IF EXISTS sso_group_mappings;
+
+DROP TABLE IF EXISTS sso_providers;
@@ -0,0 +1,57 @@
+-- Per-tenant single sign-on. A tenant has at most one identity provider; only OIDC is
+-- supported for now, the protocol column leaves room for SAML.
+CREATE TABLE sso_providers (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL UNIQUE REFERENCES tenants (id),
+    protocol TEXT NOT NULL DEFAULT 'OIDC' CHECK (protocol IN ('OIDC')),
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT 'openid email profile',
+    groups_claim TEXT NOT NULL DEFAULT 'groups',
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Identity provider groups that grant a role tenant-wide on sign-in. Roles that appear in
+-- a mapping are managed by the provider: they are revoked when the group goes away.
+CREATE TABLE sso_group_mappings (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    provider_id UUID NOT NULL REFERENCES sso_providers (id) ON DELETE CASCADE,
+    group_name TEXT NOT NULL,
+    role_id UUID NOT NULL REFERENCES rbac_roles (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider_id, group_name, role_id)
+);
+
+-- External subjects linked to users, so a changed email at the provider still signs in to
+-- the same account
+CREATE TABLE sso_identities (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    provider_id UUID NOT NULL REFERENCES sso_providers (id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT,
+    last_login_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider_id, subject),
+    UNIQUE (provider_id, user_id)
+);
+
+-- In-flight authorization requests, consumed by the callback
+CREATE TABLE sso_login_states (
+    state TEXT PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    provider_id UUID NOT NULL REFERENCES sso_providers (id) ON DELETE CASCADE,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    return_to TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sso_login_states_expires ON sso_login_states (expires_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS mfa_required_roles;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,33 @@
+-- TOTP second factor. A row without confirmed_at is an enrolment the user has not yet
+-- proven with a code; it does not count as MFA being on.
+CREATE TABLE user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    secret TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    -- The last time step a code was accepted for, so a code cannot be replayed
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One-time codes for when the authenticator is lost. Only their SHA-256 is stored.
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Roles whose holders must use MFA to sign in with a password
+CREATE TABLE mfa_required_roles (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    role_id UUID NOT NULL REFERENCES rbac_roles (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, role_id)
+);
@@ -0,0 +1,64 @@
+-- Non-human principals for integrations. They cannot log in; they call the API with the
+-- keys issued to them.
+CREATE TABLE service_accounts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    name TEXT NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
+
+-- API keys belong to a service account or, as personal keys, to a user. Only the SHA-256 of
+-- each key is stored; its prefix is kept so a key can be recognised in listings.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    service_account_id UUID NULL REFERENCES service_accounts (id) ON DELETE CASCADE,
+    user_id UUID NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((service_account_id IS NULL) <> (user_id IS NULL))
+);
+
+CREATE INDEX idx_api_keys_tenant ON api_keys (tenant_id);
+
+CREATE INDEX idx_api_keys_service_account ON api_keys (service_account_id);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
+
+-- Roles a key acts with. A personal key only keeps those its owner still holds.
+CREATE TABLE api_key_roles (
+    api_key_id UUID NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES rbac_roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (api_key_id, role_id)
+);
+
+-- Changes made through an API key name the key; actor_id stays empty for service accounts
+ALTER TABLE audit_logs
+ADD COLUMN actor_api_key_id UUID NULL REFERENCES api_keys (id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION notify_entity_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('entity_changes', json_build_object(
+        'id', NEW.id,
+        'tenantId', NEW.tenant_id,
+        'entityType', NEW.entity_type,
+        'entityId', NEW.entity_id,
+        'action', NEW.action,
+        'actorId', NEW.actor_id,
+        'actorApiKeyId', NEW.actor_api_key_id,
+        'occurredAt', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,17 @@
+CREATE OR REPLACE FUNCTION notify_entity_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('entity_changes', json_build_object(
+        'id', NEW.id,
+        'tenantId', NEW.tenant_id,
+        'entityType', NEW.entity_type,
+        'entityId', NEW.entity_id,
+        'action', NEW.action,
+        'actorId', NEW.actor_id,
+        'actorApiKeyId', NEW.actor_api_key_id,
+        'occurredAt', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS impersonator_id;
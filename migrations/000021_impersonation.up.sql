@@ -0,0 +1,21 @@
+-- Changes made while an administrator impersonates a user are attributed to the user as
+-- actor_id and to the administrator as impersonator_id
+ALTER TABLE audit_logs
+ADD COLUMN impersonator_id UUID NULL REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION notify_entity_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('entity_changes', json_build_object(
+        'id', NEW.id,
+        'tenantId', NEW.tenant_id,
+        'entityType', NEW.entity_type,
+        'entityId', NEW.entity_id,
+        'action', NEW.action,
+        'actorId', NEW.actor_id,
+        'actorApiKeyId', NEW.actor_api_key_id,
+        'impersonatorId', NEW.impersonator_id,
+        'occurredAt', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS preferences;
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
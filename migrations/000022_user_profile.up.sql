@@ -0,0 +1,5 @@
+-- Self-editable profile fields. Preferences hold front-end settings such as theme or table
+-- layouts; the API stores them without interpreting them.
+ALTER TABLE users ADD COLUMN avatar_url TEXT;
+
+ALTER TABLE users ADD COLUMN preferences JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,33 @@
+-- Changes employees propose to their own HR record. changes holds the proposed diff as
+-- {"field": {"from": ..., "to": ...}}; the from values are checked against the record
+-- when the request is approved. The request is decided by approver_employee_id, the
+-- employee's manager when it was raised, or otherwise by holders of approver_role_code.
+CREATE TABLE employee_change_requests (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    employee_id UUID NOT NULL REFERENCES employees (id) ON DELETE CASCADE,
+    changes JSONB NOT NULL,
+    reason TEXT,
+    approver_employee_id UUID NULL REFERENCES employees (id) ON DELETE SET NULL,
+    approver_role_code TEXT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending | approved | rejected | cancelled
+    decision_notes TEXT,
+    decided_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    requested_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT employee_change_requests_status_check CHECK (
+        status IN ('pending', 'approved', 'rejected', 'cancelled')
+    ),
+    CONSTRAINT employee_change_requests_rejection_notes_check CHECK (
+        status <> 'rejected' OR decision_notes IS NOT NULL
+    )
+);
+
+-- An employee has at most one pending request, so approvals never race each other
+CREATE UNIQUE INDEX idx_employee_change_requests_pending ON employee_change_requests (tenant_id, employee_id)
+WHERE
+    status = 'pending';
+
+CREATE INDEX idx_employee_change_requests_status ON employee_change_requests (tenant_id, status, created_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS custom_field_values;
+
+DROP TABLE IF EXISTS custom_field_definitions;
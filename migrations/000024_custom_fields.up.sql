@@ -0,0 +1,42 @@
+-- Tenant-defined attributes of employees and org entities, e.g. shift pattern or cost
+-- centre. options lists the choices of select fields; validation holds optional rules
+-- such as {"maxLength": 20} or {"min": 0, "max": 5}.
+CREATE TABLE custom_field_definitions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    entity_type TEXT NOT NULL, -- employee | business_unit | department | job_title
+    key TEXT NOT NULL,
+    label TEXT NOT NULL,
+    field_type TEXT NOT NULL, -- text | number | boolean | date | select | multi_select
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    options JSONB NOT NULL DEFAULT '[]',
+    validation JSONB NOT NULL DEFAULT '{}',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT custom_field_definitions_key_unique UNIQUE (tenant_id, entity_type, key),
+    CONSTRAINT custom_field_definitions_entity_type_check CHECK (
+        entity_type IN ('employee', 'business_unit', 'department', 'job_title')
+    ),
+    CONSTRAINT custom_field_definitions_field_type_check CHECK (
+        field_type IN ('text', 'number', 'boolean', 'date', 'select', 'multi_select')
+    ),
+    CONSTRAINT custom_field_definitions_key_check CHECK (key ~ '^[a-z][a-z0-9_]{0,62}$')
+);
+
+-- Custom field values of one record as a JSON object keyed by definition key. entity_type
+-- and entity_id point at the record like tasks do.
+CREATE TABLE custom_field_values (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    field_values JSONB NOT NULL DEFAULT '{}',
+    updated_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, entity_type, entity_id)
+);
+
+-- Serves the containment (@>) filters of list endpoints
+CREATE INDEX idx_custom_field_values_field_values ON custom_field_values USING GIN (field_values jsonb_path_ops);
@@ -0,0 +1,22 @@
+-- Personal data HR holds about an employee. Every column below wrapped_key is encrypted
+-- by the application with AES-256-GCM under a data key of the row's own; wrapped_key is
+-- that data key encrypted with the master key named by key_id. Rotating master keys only
+-- needs the data keys re-wrapped, and the database never sees plaintext.
+CREATE TABLE employee_personal_details (
+    employee_id UUID PRIMARY KEY REFERENCES employees (id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    key_id TEXT NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    personal_email BYTEA,
+    phone BYTEA,
+    address BYTEA,
+    date_of_birth BYTEA,
+    emergency_contacts BYTEA,
+    ni_number BYTEA,
+    updated_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Finds rows still wrapped with a retired master key
+CREATE INDEX idx_employee_personal_details_key ON employee_personal_details (key_id);
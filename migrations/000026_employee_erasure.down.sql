@@ -0,0 +1,3 @@
+ALTER TABLE employees DROP COLUMN IF EXISTS erased_by_user_id;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS erased_at;
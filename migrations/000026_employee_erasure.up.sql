@@ -0,0 +1,6 @@
+-- Erased employees keep their rows, pseudonymised, so the users row (ON DELETE RESTRICT),
+-- audit entries and QMS records that reference them stay intact
+ALTER TABLE employees ADD COLUMN erased_at TIMESTAMPTZ;
+
+ALTER TABLE employees
+ADD COLUMN erased_by_user_id UUID NULL REFERENCES users (id) ON DELETE SET NULL;
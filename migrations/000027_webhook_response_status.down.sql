@@ -0,0 +1,2 @@
+-- Cleared response bodies cannot be restored
+SELECT 1;
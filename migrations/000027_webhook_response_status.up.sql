@@ -0,0 +1,7 @@
+-- Deliveries keep only the status line of the response from now on. Clear the bodies
+-- stored so far, which could hold whatever an internal address answered.
+UPDATE webhook_deliveries
+SET
+    response_body = NULL
+WHERE
+    response_body IS NOT NULL;
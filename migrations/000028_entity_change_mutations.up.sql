@@ -0,0 +1,23 @@
+-- Only announce changes to records. Reads, exports and impersonation are audited but
+-- change nothing, and announcing them would tell every subscriber in the tenant who
+-- looked at whose personal data.
+CREATE OR REPLACE FUNCTION notify_entity_change() RETURNS trigger AS $$
+BEGIN
+    IF NEW.action NOT IN ('CREATE', 'UPDATE', 'DELETE', 'ONBOARD', 'ERASE') THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM pg_notify('entity_changes', json_build_object(
+        'id', NEW.id,
+        'tenantId', NEW.tenant_id,
+        'entityType', NEW.entity_type,
+        'entityId', NEW.entity_id,
+        'action', NEW.action,
+        'actorId', NEW.actor_id,
+        'actorApiKeyId', NEW.actor_api_key_id,
+        'impersonatorId', NEW.impersonator_id,
+        'occurredAt', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,12 @@
+-- Encrypted secrets cannot be decrypted in SQL. Their users lose MFA and enrol again,
+-- which a role that requires MFA enforces at the next sign-in.
+DELETE FROM user_mfa WHERE secret IS NULL;
+DELETE FROM mfa_recovery_codes c WHERE NOT EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = c.user_id);
+
+DROP INDEX IF EXISTS idx_user_mfa_key;
+
+ALTER TABLE user_mfa
+    DROP COLUMN encrypted_secret,
+    DROP COLUMN wrapped_key,
+    DROP COLUMN key_id,
+    ALTER COLUMN secret SET NOT NULL;
@@ -0,0 +1,14 @@
+-- TOTP secrets are encrypted by the application like personal details: encrypted_secret
+-- is sealed with a data key of the row's own, wrapped with the master key named by key_id.
+-- secret keeps the plaintext of factors confirmed before this migration only until the
+-- server encrypts them at startup. Unconfirmed enrolments are simply started again.
+DELETE FROM user_mfa WHERE confirmed_at IS NULL;
+
+ALTER TABLE user_mfa
+    ALTER COLUMN secret DROP NOT NULL,
+    ADD COLUMN key_id TEXT,
+    ADD COLUMN wrapped_key BYTEA,
+    ADD COLUMN encrypted_secret BYTEA;
+
+-- Finds rows still wrapped with a retired master key
+CREATE INDEX idx_user_mfa_key ON user_mfa (key_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_email_outbox_entity;
+
+ALTER TABLE email_outbox
+    DROP COLUMN entity_id,
+    DROP COLUMN entity_type;
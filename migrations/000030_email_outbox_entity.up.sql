@@ -0,0 +1,9 @@
+-- Emails link to the record they are about, like notifications, so erasing an employee
+-- can find the emails that name them, e.g. approval requests for their change requests
+ALTER TABLE email_outbox
+    ADD COLUMN entity_type TEXT,
+    ADD COLUMN entity_id UUID;
+
+CREATE INDEX idx_email_outbox_entity ON email_outbox (tenant_id, entity_type, entity_id)
+WHERE
+    entity_id IS NOT NULL;
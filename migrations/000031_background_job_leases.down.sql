@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_background_jobs_unfinished;
+
+ALTER TABLE background_jobs
+    DROP COLUMN lease_expires_at,
+    DROP COLUMN lease_owner;
@@ -0,0 +1,9 @@
+-- Jobs run in the memory of the process that accepted them. That process holds a lease it
+-- keeps renewing, so other replicas only fail jobs whose owner has stopped renewing.
+ALTER TABLE background_jobs
+    ADD COLUMN lease_owner TEXT,
+    ADD COLUMN lease_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_background_jobs_unfinished ON background_jobs (lease_expires_at)
+WHERE
+    status IN ('queued', 'running');
@@ -0,0 +1 @@
+ALTER TABLE sso_login_states DROP COLUMN browser_hash;
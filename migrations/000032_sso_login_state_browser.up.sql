@@ -0,0 +1,6 @@
+-- A sign-in can only be completed by the browser that started it: the start sets a cookie
+-- holding a random binding and the state keeps its hash. Pending sign-ins cannot be bound
+-- after the fact and are dropped.
+DELETE FROM sso_login_states;
+
+ALTER TABLE sso_login_states ADD COLUMN browser_hash TEXT NOT NULL;
@@ -0,0 +1,3 @@
+-- The roles may have been granted since, and existing roles cannot be told apart from
+-- the ones created by the up migration, so they are left in place
+SELECT 1;
@@ -0,0 +1,11 @@
+-- Roles that grant permissions cannot be created through the API, so every existing
+-- tenant gets them here; new tenants get them when they are created
+INSERT INTO
+    rbac_roles (id, tenant_id, code, name)
+SELECT gen_random_uuid(), t.id, r.code, r.name
+FROM
+    tenants t
+    CROSS JOIN (
+        VALUES ('ADMIN', 'Administrator'), ('HR_ADMIN', 'HR Administrator')
+    ) AS r (code, name)
+ON CONFLICT (tenant_id, code) DO NOTHING;
@@ -0,0 +1,155 @@
+-- name: CreateServiceAccount :one
+INSERT INTO
+    service_accounts (
+        id,
+        tenant_id,
+        name,
+        description,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: ListServiceAccounts :many
+SELECT * FROM service_accounts WHERE tenant_id = $1 ORDER BY name;
+
+-- name: GetServiceAccount :one
+SELECT * FROM service_accounts WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: UpdateServiceAccount :one
+UPDATE service_accounts
+SET
+    name = $3,
+    description = $4,
+    is_active = $5,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: CreateApiKey :one
+INSERT INTO
+    api_keys (
+        id,
+        tenant_id,
+        service_account_id,
+        user_id,
+        name,
+        prefix,
+        key_hash,
+        expires_at,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    *;
+
+-- name: AddApiKeyRole :exec
+INSERT INTO api_key_roles (api_key_id, role_id) VALUES ($1, $2);
+
+-- name: ListGrantableApiKeyRoles :many
+SELECT r.id, r.code
+FROM rbac_roles r
+WHERE
+    r.tenant_id = sqlc.arg('tenant_id')
+    AND r.id = ANY(sqlc.arg('role_ids')::uuid[])
+    AND (
+        sqlc.narg('user_id')::uuid IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM user_rbac_roles ur
+            WHERE
+                ur.tenant_id = r.tenant_id
+                AND ur.user_id = sqlc.narg('user_id')
+                AND ur.role_id = r.id
+        )
+    );
+
+-- name: ListApiKeys :many
+SELECT *
+FROM api_keys
+WHERE
+    tenant_id = sqlc.arg('tenant_id')
+    AND (
+        sqlc.narg('service_account_id')::uuid IS NULL
+        OR service_account_id = sqlc.narg('service_account_id')
+    )
+    AND (
+        sqlc.narg('user_id')::uuid IS NULL
+        OR user_id = sqlc.narg('user_id')
+    )
+ORDER BY created_at DESC;
+
+-- name: ListApiKeyRoles :many
+SELECT kr.api_key_id, r.id AS role_id, r.code AS role_code
+FROM
+    api_key_roles kr
+    JOIN rbac_roles r ON r.id = kr.role_id
+WHERE
+    kr.api_key_id = ANY(sqlc.arg('api_key_ids')::uuid[])
+ORDER BY r.code;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET
+    revoked_at = NOW()
+WHERE
+    tenant_id = sqlc.arg('tenant_id')
+    AND id = sqlc.arg('id')
+    AND (
+        sqlc.narg('user_id')::uuid IS NULL
+        OR user_id = sqlc.narg('user_id')
+    )
+    AND revoked_at IS NULL;
+
+-- name: GetActiveApiKeyByHash :one
+SELECT k.*
+FROM
+    api_keys k
+    LEFT JOIN service_accounts sa ON sa.id = k.service_account_id
+    LEFT JOIN users u ON u.id = k.user_id
+WHERE
+    k.key_hash = $1
+    AND k.revoked_at IS NULL
+    AND (
+        k.expires_at IS NULL
+        OR k.expires_at > NOW()
+    )
+    AND COALESCE(sa.is_active, u.is_active)
+LIMIT 1;
+
+-- name: ListApiKeyRoleCodes :many
+SELECT r.code
+FROM
+    api_key_roles kr
+    JOIN api_keys k ON k.id = kr.api_key_id
+    JOIN rbac_roles r ON r.id = kr.role_id
+    AND r.tenant_id = k.tenant_id
+WHERE
+    kr.api_key_id = $1
+    AND (
+        k.user_id IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM user_rbac_roles ur
+            WHERE
+                ur.tenant_id = k.tenant_id
+                AND ur.user_id = k.user_id
+                AND ur.role_id = kr.role_id
+        )
+    )
+ORDER BY r.code;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET
+    last_used_at = NOW()
+WHERE
+    id = $1
+    AND (
+        last_used_at IS NULL
+        OR last_used_at < NOW() - INTERVAL '1 minute'
+    );
@@ -0,0 +1,295 @@
+-- name: NextNCRNumber :one
+SELECT (COALESCE(max(number), 0) + 1)::integer AS next_number
+FROM ncrs
+WHERE tenant_id = $1;
+
+-- name: CreateNCR :one
+INSERT INTO
+    ncrs (
+        id,
+        tenant_id,
+        number,
+        title,
+        description,
+        business_unit_id,
+        department_id,
+        reported_by_employee_id,
+        owner_employee_id,
+        detected_on,
+        severity,
+        classification,
+        containment_action,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING
+    *;
+
+-- name: GetNCR :one
+SELECT * FROM ncrs WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: GetNCRForUpdate :one
+SELECT * FROM ncrs WHERE tenant_id = $1 AND id = $2 LIMIT 1 FOR UPDATE;
+
+-- name: UpdateNCR :one
+UPDATE ncrs
+SET
+    title = $3,
+    description = $4,
+    business_unit_id = $5,
+    department_id = $6,
+    owner_employee_id = $7,
+    detected_on = $8,
+    severity = $9,
+    classification = $10,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: UpdateNCRRootCause :one
+UPDATE ncrs
+SET
+    containment_action = $3,
+    root_cause_method = $4,
+    root_cause = $5,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: UpdateNCRStatus :one
+UPDATE ncrs
+SET
+    status = $3,
+    closed_at = CASE
+        WHEN $3 IN ('closed', 'cancelled') THEN NOW()
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: RecordNCRVerification :one
+UPDATE ncrs
+SET
+    verified_by_user_id = $3,
+    verified_at = NOW(),
+    verification_notes = $4,
+    is_effective = $5,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: ListNCRs :many
+SELECT
+    n.id,
+    n.number,
+    n.title,
+    n.severity,
+    n.classification,
+    n.status,
+    n.detected_on,
+    n.business_unit_id,
+    bu.name AS business_unit_name,
+    n.department_id,
+    d.name AS department_name,
+    n.reported_by_employee_id,
+    r.first_name AS reporter_first_name,
+    r.last_name AS reporter_last_name,
+    n.owner_employee_id,
+    (
+        SELECT count(*)
+        FROM ncr_actions a
+        WHERE
+            a.ncr_id = n.id
+            AND a.status = 'open'
+    )::bigint AS open_actions,
+    (
+        SELECT count(*)
+        FROM ncr_actions a
+        WHERE
+            a.ncr_id = n.id
+            AND a.status = 'open'
+            AND a.due_date < CURRENT_DATE
+    )::bigint AS overdue_actions,
+    n.created_at,
+    n.updated_at,
+    n.closed_at
+FROM
+    ncrs n
+    JOIN business_units bu ON bu.id = n.business_unit_id
+    JOIN departments d ON d.id = n.department_id
+    JOIN employees r ON r.id = n.reported_by_employee_id
+WHERE
+    n.tenant_id = $1
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR n.status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.arg ('severity')::text = ''
+        OR n.severity = sqlc.arg ('severity')::text
+    )
+    AND (
+        sqlc.arg ('classification')::text = ''
+        OR n.classification = sqlc.arg ('classification')::text
+    )
+    AND (
+        sqlc.narg ('business_unit_id')::uuid IS NULL
+        OR n.business_unit_id = sqlc.narg ('business_unit_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR n.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR n.title ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR n.description ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY n.number DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountNCRs :one
+SELECT count(*)
+FROM ncrs n
+WHERE
+    n.tenant_id = $1
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR n.status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.arg ('severity')::text = ''
+        OR n.severity = sqlc.arg ('severity')::text
+    )
+    AND (
+        sqlc.arg ('classification')::text = ''
+        OR n.classification = sqlc.arg ('classification')::text
+    )
+    AND (
+        sqlc.narg ('business_unit_id')::uuid IS NULL
+        OR n.business_unit_id = sqlc.narg ('business_unit_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR n.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR n.title ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR n.description ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: CreateNCRAction :one
+INSERT INTO
+    ncr_actions (
+        id,
+        tenant_id,
+        ncr_id,
+        kind,
+        description,
+        assignee_employee_id,
+        due_date,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: GetNCRAction :one
+SELECT *
+FROM ncr_actions
+WHERE
+    tenant_id = $1
+    AND ncr_id = $2
+    AND id = $3
+LIMIT 1;
+
+-- name: UpdateNCRAction :one
+UPDATE ncr_actions
+SET
+    description = $4,
+    assignee_employee_id = $5,
+    due_date = $6,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND ncr_id = $2
+    AND id = $3
+RETURNING
+    *;
+
+-- name: SetNCRActionStatus :one
+UPDATE ncr_actions
+SET
+    status = $4,
+    completion_notes = $5,
+    completed_at = CASE
+        WHEN $4 = 'done' THEN NOW()
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND ncr_id = $2
+    AND id = $3
+RETURNING
+    *;
+
+-- name: ListNCRActions :many
+SELECT
+    a.id,
+    a.ncr_id,
+    a.kind,
+    a.description,
+    a.assignee_employee_id,
+    e.first_name AS assignee_first_name,
+    e.last_name AS assignee_last_name,
+    a.due_date,
+    a.status,
+    a.completed_at,
+    a.completion_notes,
+    a.created_by_user_id,
+    a.created_at,
+    a.updated_at
+FROM ncr_actions a
+    JOIN employees e ON e.id = a.assignee_employee_id
+WHERE
+    a.tenant_id = $1
+    AND a.ncr_id = $2
+ORDER BY a.due_date, a.created_at;
+
+-- name: CreateNCRStatusHistory :exec
+INSERT INTO
+    ncr_status_history (
+        id,
+        tenant_id,
+        ncr_id,
+        from_status,
+        to_status,
+        comment,
+        actor_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListNCRStatusHistory :many
+SELECT *
+FROM ncr_status_history
+WHERE
+    tenant_id = $1
+    AND ncr_id = $2
+ORDER BY created_at;
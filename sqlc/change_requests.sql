@@ -0,0 +1,104 @@
+-- name: CreateEmployeeChangeRequest :one
+INSERT INTO
+    employee_change_requests (
+        id,
+        tenant_id,
+        employee_id,
+        changes,
+        reason,
+        approver_employee_id,
+        approver_role_code,
+        requested_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: GetEmployeeChangeRequest :one
+SELECT *
+FROM employee_change_requests
+WHERE
+    tenant_id = $1
+    AND id = $2
+LIMIT 1;
+
+-- name: GetEmployeeChangeRequestForUpdate :one
+SELECT *
+FROM employee_change_requests
+WHERE
+    tenant_id = $1
+    AND id = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListEmployeeChangeRequests :many
+SELECT *
+FROM employee_change_requests
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.narg ('employee_id')::uuid IS NULL
+        OR employee_id = sqlc.narg ('employee_id')::uuid
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountEmployeeChangeRequests :one
+SELECT count(*)
+FROM employee_change_requests
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.narg ('employee_id')::uuid IS NULL
+        OR employee_id = sqlc.narg ('employee_id')::uuid
+    );
+
+-- name: DecideEmployeeChangeRequest :one
+UPDATE employee_change_requests
+SET
+    status = $3,
+    decision_notes = $4,
+    decided_by_user_id = $5,
+    decided_at = NOW(),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+    AND status = 'pending'
+RETURNING
+    *;
+
+-- name: GetEmployeeForUpdate :one
+SELECT *
+FROM employees
+WHERE
+    tenant_id = $1
+    AND id = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateEmployeeDetails :one
+UPDATE employees
+SET
+    first_name = $3,
+    last_name = $4,
+    display_name = $5,
+    work_email = $6,
+    business_unit_id = $7,
+    department_id = $8,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
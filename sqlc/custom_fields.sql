@@ -0,0 +1,102 @@
+-- name: CreateCustomFieldDefinition :one
+INSERT INTO
+    custom_field_definitions (
+        id,
+        tenant_id,
+        entity_type,
+        key,
+        label,
+        field_type,
+        is_required,
+        options,
+        validation,
+        sort_order,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING
+    *;
+
+-- name: GetCustomFieldDefinition :one
+SELECT *
+FROM custom_field_definitions
+WHERE
+    tenant_id = $1
+    AND id = $2
+LIMIT 1;
+
+-- name: ListCustomFieldDefinitions :many
+SELECT *
+FROM custom_field_definitions
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND (
+        sqlc.arg ('entity_type')::text = ''
+        OR entity_type = sqlc.arg ('entity_type')::text
+    )
+    AND (
+        sqlc.arg ('include_inactive')::boolean
+        OR is_active
+    )
+ORDER BY entity_type, sort_order, label;
+
+-- name: UpdateCustomFieldDefinition :one
+UPDATE custom_field_definitions
+SET
+    label = $3,
+    is_required = $4,
+    options = $5,
+    validation = $6,
+    sort_order = $7,
+    is_active = $8,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: GetCustomFieldValuesForUpdate :one
+SELECT *
+FROM custom_field_values
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND entity_id = $3
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetCustomFieldValues :one
+SELECT *
+FROM custom_field_values
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND entity_id = $3
+LIMIT 1;
+
+-- name: UpsertCustomFieldValues :one
+INSERT INTO
+    custom_field_values (
+        tenant_id,
+        entity_type,
+        entity_id,
+        field_values,
+        updated_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant_id, entity_type, entity_id) DO UPDATE
+SET
+    field_values = EXCLUDED.field_values,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING
+    *;
+
+-- name: ListCustomFieldMatches :many
+SELECT entity_id
+FROM custom_field_values
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND field_values @> sqlc.arg ('custom_fields')::jsonb;
@@ -0,0 +1,393 @@
+-- name: CreateAuditProgramme :one
+INSERT INTO
+    audit_programmes (
+        id,
+        tenant_id,
+        name,
+        year,
+        objectives,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: UpdateAuditProgramme :one
+UPDATE audit_programmes
+SET
+    name = $3,
+    year = $4,
+    objectives = $5,
+    is_active = $6,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: GetAuditProgramme :one
+SELECT * FROM audit_programmes WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListAuditProgrammes :many
+SELECT *
+FROM audit_programmes
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY year DESC, name
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountAuditProgrammes :one
+SELECT count(*)
+FROM audit_programmes
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: CreateInternalAudit :one
+INSERT INTO
+    internal_audits (
+        id,
+        tenant_id,
+        programme_id,
+        title,
+        scope,
+        criteria,
+        business_unit_id,
+        department_id,
+        lead_auditor_employee_id,
+        planned_start,
+        planned_end,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING
+    *;
+
+-- name: GetInternalAudit :one
+SELECT * FROM internal_audits WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: GetInternalAuditForUpdate :one
+SELECT * FROM internal_audits WHERE tenant_id = $1 AND id = $2 LIMIT 1 FOR UPDATE;
+
+-- name: UpdateInternalAudit :one
+UPDATE internal_audits
+SET
+    title = $3,
+    scope = $4,
+    criteria = $5,
+    business_unit_id = $6,
+    department_id = $7,
+    lead_auditor_employee_id = $8,
+    planned_start = $9,
+    planned_end = $10,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: SetInternalAuditStatus :one
+UPDATE internal_audits
+SET
+    status = $3,
+    summary = COALESCE(sqlc.narg ('summary')::text, summary),
+    started_at = CASE
+        WHEN $3 = 'in_progress' THEN NOW()
+        ELSE started_at
+    END,
+    completed_at = CASE
+        WHEN $3 = 'completed' THEN NOW()
+        ELSE completed_at
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: ListInternalAudits :many
+SELECT
+    a.id,
+    a.programme_id,
+    p.name AS programme_name,
+    a.title,
+    a.business_unit_id,
+    bu.name AS business_unit_name,
+    a.department_id,
+    d.name AS department_name,
+    a.lead_auditor_employee_id,
+    l.first_name AS lead_auditor_first_name,
+    l.last_name AS lead_auditor_last_name,
+    a.planned_start,
+    a.planned_end,
+    a.status,
+    (
+        SELECT count(*)
+        FROM audit_findings f
+        WHERE
+            f.audit_id = a.id
+            AND f.classification IN ('major', 'minor')
+    )::bigint AS nonconformities,
+    (
+        SELECT count(*)
+        FROM audit_findings f
+        WHERE
+            f.audit_id = a.id
+            AND f.classification = 'observation'
+    )::bigint AS observations,
+    a.created_at,
+    a.updated_at
+FROM
+    internal_audits a
+    JOIN audit_programmes p ON p.id = a.programme_id
+    JOIN business_units bu ON bu.id = a.business_unit_id
+    LEFT JOIN departments d ON d.id = a.department_id
+    JOIN employees l ON l.id = a.lead_auditor_employee_id
+WHERE
+    a.tenant_id = $1
+    AND (
+        sqlc.narg ('programme_id')::uuid IS NULL
+        OR a.programme_id = sqlc.narg ('programme_id')
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR a.status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.narg ('business_unit_id')::uuid IS NULL
+        OR a.business_unit_id = sqlc.narg ('business_unit_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR a.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR a.title ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY a.planned_start DESC, a.title
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountInternalAudits :one
+SELECT count(*)
+FROM internal_audits a
+WHERE
+    a.tenant_id = $1
+    AND (
+        sqlc.narg ('programme_id')::uuid IS NULL
+        OR a.programme_id = sqlc.narg ('programme_id')
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR a.status = sqlc.arg ('status')::text
+    )
+    AND (
+        sqlc.narg ('business_unit_id')::uuid IS NULL
+        OR a.business_unit_id = sqlc.narg ('business_unit_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR a.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR a.title ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: ListAuditCalendar :many
+SELECT
+    a.id,
+    a.programme_id,
+    p.name AS programme_name,
+    a.title,
+    a.business_unit_id,
+    bu.name AS business_unit_name,
+    a.department_id,
+    d.name AS department_name,
+    a.lead_auditor_employee_id,
+    l.first_name AS lead_auditor_first_name,
+    l.last_name AS lead_auditor_last_name,
+    a.planned_start,
+    a.planned_end,
+    a.status
+FROM
+    internal_audits a
+    JOIN audit_programmes p ON p.id = a.programme_id
+    JOIN business_units bu ON bu.id = a.business_unit_id
+    LEFT JOIN departments d ON d.id = a.department_id
+    JOIN employees l ON l.id = a.lead_auditor_employee_id
+WHERE
+    a.tenant_id = $1
+    AND a.status <> 'cancelled'
+    AND a.planned_end >= sqlc.arg ('from')::date
+    AND a.planned_start <= sqlc.arg ('to')::date
+    AND (
+        sqlc.narg ('business_unit_id')::uuid IS NULL
+        OR a.business_unit_id = sqlc.narg ('business_unit_id')
+    )
+    AND (
+        sqlc.narg ('auditor_employee_id')::uuid IS NULL
+        OR a.lead_auditor_employee_id = sqlc.narg ('auditor_employee_id')
+        OR EXISTS (
+            SELECT 1
+            FROM internal_audit_team t
+            WHERE
+                t.audit_id = a.id
+                AND t.employee_id = sqlc.narg ('auditor_employee_id')
+        )
+    )
+ORDER BY a.planned_start, a.title;
+
+-- name: DeleteInternalAuditTeam :exec
+DELETE FROM internal_audit_team WHERE tenant_id = $1 AND audit_id = $2;
+
+-- name: AddInternalAuditTeamMember :exec
+INSERT INTO
+    internal_audit_team (
+        tenant_id,
+        audit_id,
+        employee_id,
+        role
+    )
+VALUES ($1, $2, $3, $4);
+
+-- name: ListInternalAuditTeam :many
+SELECT
+    t.employee_id,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    t.role
+FROM internal_audit_team t
+    JOIN employees e ON e.id = t.employee_id
+WHERE
+    t.tenant_id = $1
+    AND t.audit_id = $2
+ORDER BY e.last_name, e.first_name;
+
+-- name: DeleteAuditChecklistItems :exec
+DELETE FROM audit_checklist_items WHERE tenant_id = $1 AND audit_id = $2;
+
+-- name: CreateAuditChecklistItem :one
+INSERT INTO
+    audit_checklist_items (
+        id,
+        tenant_id,
+        audit_id,
+        position,
+        clause,
+        question
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: GetAuditChecklistItem :one
+SELECT *
+FROM audit_checklist_items
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND id = $3
+LIMIT 1;
+
+-- name: ListAuditChecklistItems :many
+SELECT *
+FROM audit_checklist_items
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+ORDER BY position;
+
+-- name: RecordAuditChecklistResult :one
+UPDATE audit_checklist_items
+SET
+    result = $4,
+    notes = $5,
+    answered_by_user_id = $6,
+    answered_at = NOW()
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND id = $3
+RETURNING
+    *;
+
+-- name: CountUnansweredChecklistItems :one
+SELECT count(*)
+FROM audit_checklist_items
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND result IS NULL;
+
+-- name: CreateAuditFinding :one
+INSERT INTO
+    audit_findings (
+        id,
+        tenant_id,
+        audit_id,
+        checklist_item_id,
+        classification,
+        clause,
+        description,
+        evidence,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    *;
+
+-- name: GetAuditFinding :one
+SELECT *
+FROM audit_findings
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND id = $3
+LIMIT 1;
+
+-- name: GetAuditFindingForUpdate :one
+SELECT *
+FROM audit_findings
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND id = $3
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListAuditFindings :many
+SELECT *
+FROM audit_findings
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+ORDER BY created_at;
+
+-- name: LinkAuditFindingNCR :one
+UPDATE audit_findings
+SET
+    ncr_id = $4,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND audit_id = $2
+    AND id = $3
+    AND ncr_id IS NULL
+RETURNING
+    *;
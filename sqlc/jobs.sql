@@ -0,0 +1,131 @@
+-- name: CreateBackgroundJob :one
+INSERT INTO
+    background_jobs (
+        id,
+        tenant_id,
+        kind,
+        total,
+        created_by_user_id,
+        lease_owner,
+        lease_expires_at
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        sqlc.arg ('lease_owner'),
+        sqlc.arg ('lease_until')
+    )
+RETURNING
+    *;
+
+-- name: GetBackgroundJob :one
+SELECT * FROM background_jobs WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListBackgroundJobs :many
+SELECT *
+FROM background_jobs
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('kind')::text = ''
+        OR kind = sqlc.arg ('kind')::text
+    )
+    AND (
+        sqlc.narg ('created_by_user_id')::uuid IS NULL
+        OR created_by_user_id = sqlc.narg ('created_by_user_id')::uuid
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountBackgroundJobs :one
+SELECT count(*)
+FROM background_jobs
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('kind')::text = ''
+        OR kind = sqlc.arg ('kind')::text
+    )
+    AND (
+        sqlc.narg ('created_by_user_id')::uuid IS NULL
+        OR created_by_user_id = sqlc.narg ('created_by_user_id')::uuid
+    );
+
+-- name: MarkBackgroundJobRunning :execrows
+UPDATE background_jobs
+SET
+    status = 'running',
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+    AND status = 'queued'
+    AND lease_owner = sqlc.arg ('lease_owner');
+
+-- name: UpdateBackgroundJobProgress :exec
+UPDATE background_jobs
+SET
+    progress = $2,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: CompleteBackgroundJob :exec
+UPDATE background_jobs
+SET
+    status = 'succeeded',
+    progress = total,
+    result = $2,
+    file_key = sqlc.narg ('file_key'),
+    file_name = sqlc.narg ('file_name'),
+    content_type = sqlc.narg ('content_type'),
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+    AND status = 'running'
+    AND lease_owner = sqlc.arg ('lease_owner');
+
+-- name: FailBackgroundJob :exec
+UPDATE background_jobs
+SET
+    status = 'failed',
+    result = $2,
+    error = $3,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+    AND status = 'running'
+    AND lease_owner = sqlc.arg ('lease_owner');
+
+-- name: DeleteQueuedBackgroundJob :exec
+DELETE FROM background_jobs WHERE id = $1 AND status = 'queued';
+
+-- name: RenewBackgroundJobLeases :exec
+UPDATE background_jobs
+SET
+    lease_expires_at = sqlc.arg ('lease_until')
+WHERE
+    lease_owner = sqlc.arg ('lease_owner')
+    AND id = ANY (sqlc.arg ('ids')::uuid[])
+    AND status IN ('queued', 'running');
+
+-- name: FailInterruptedBackgroundJobs :execrows
+UPDATE background_jobs
+SET
+    status = 'failed',
+    error = 'Interrupted: the server running it stopped',
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE
+    status IN ('queued', 'running')
+    AND (
+        lease_expires_at IS NULL
+        OR lease_expires_at < NOW()
+    );
@@ -0,0 +1,192 @@
+-- name: GetMfaLoginState :one
+SELECT
+    EXISTS (
+        SELECT 1
+        FROM user_mfa m
+        WHERE
+            m.user_id = sqlc.arg ('user_id')::uuid
+            AND m.confirmed_at IS NOT NULL
+    )::boolean AS enrolled,
+    EXISTS (
+        SELECT 1
+        FROM
+            user_rbac_roles ur
+            JOIN mfa_required_roles rr ON rr.tenant_id = ur.tenant_id
+            AND rr.role_id = ur.role_id
+        WHERE
+            ur.tenant_id = sqlc.arg ('tenant_id')::uuid
+            AND ur.user_id = sqlc.arg ('user_id')::uuid
+    )::boolean AS required;
+
+-- name: GetUserMfa :one
+SELECT * FROM user_mfa WHERE tenant_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: StartMfaEnrolment :one
+INSERT INTO
+    user_mfa (
+        user_id,
+        tenant_id,
+        key_id,
+        wrapped_key,
+        encrypted_secret
+    )
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    secret = NULL,
+    key_id = EXCLUDED.key_id,
+    wrapped_key = EXCLUDED.wrapped_key,
+    encrypted_secret = EXCLUDED.encrypted_secret,
+    last_used_step = 0,
+    failed_attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE
+    user_mfa.confirmed_at IS NULL
+RETURNING
+    *;
+
+-- name: ConfirmMfaEnrolment :one
+UPDATE user_mfa
+SET
+    confirmed_at = NOW(),
+    last_used_step = $3,
+    failed_attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND confirmed_at IS NULL
+RETURNING
+    *;
+
+-- name: RecordMfaSuccess :execrows
+UPDATE user_mfa
+SET
+    last_used_step = sqlc.arg ('step')::bigint,
+    failed_attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND last_used_step < sqlc.arg ('step')::bigint;
+
+-- name: ClaimMfaAttempt :one
+UPDATE user_mfa
+SET
+    failed_attempts = failed_attempts + 1,
+    locked_until = CASE
+        WHEN failed_attempts + 1 >= sqlc.arg ('max_attempts')::int THEN NOW() + make_interval(mins => sqlc.arg ('lockout_minutes')::int)
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND (
+        locked_until IS NULL
+        OR locked_until <= NOW()
+    )
+RETURNING
+    *;
+
+-- name: ResetMfaFailures :exec
+UPDATE user_mfa
+SET
+    failed_attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2;
+
+-- name: ListPlaintextMfaSecrets :many
+SELECT user_id, tenant_id, secret
+FROM user_mfa
+WHERE
+    secret IS NOT NULL
+ORDER BY user_id
+LIMIT $1;
+
+-- name: EncryptMfaSecret :execrows
+UPDATE user_mfa
+SET
+    secret = NULL,
+    key_id = sqlc.arg ('key_id'),
+    wrapped_key = sqlc.arg ('wrapped_key'),
+    encrypted_secret = sqlc.arg ('encrypted_secret'),
+    updated_at = NOW()
+WHERE
+    user_id = sqlc.arg ('user_id')
+    AND secret IS NOT NULL;
+
+-- name: ListMfaKeysNotWrappedWith :many
+SELECT user_id, tenant_id, key_id, wrapped_key
+FROM user_mfa
+WHERE
+    key_id <> $1
+ORDER BY user_id
+LIMIT $2;
+
+-- name: RewrapUserMfaKey :execrows
+UPDATE user_mfa
+SET
+    key_id = sqlc.arg ('new_key_id'),
+    wrapped_key = sqlc.arg ('wrapped_key')
+WHERE
+    user_id = sqlc.arg ('user_id')
+    AND key_id = sqlc.arg ('old_key_id');
+
+-- name: DeleteUserMfa :execrows
+DELETE FROM user_mfa WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: CreateMfaRecoveryCode :exec
+INSERT INTO
+    mfa_recovery_codes (
+        id,
+        tenant_id,
+        user_id,
+        code_hash
+    )
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: UseMfaRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET
+    used_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND code_hash = $3
+    AND used_at IS NULL;
+
+-- name: CountUnusedMfaRecoveryCodes :one
+SELECT count(*)
+FROM mfa_recovery_codes
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND used_at IS NULL;
+
+-- name: ListMfaRequiredRoles :many
+SELECT r.id, r.code, r.name
+FROM
+    mfa_required_roles rr
+    JOIN rbac_roles r ON r.id = rr.role_id
+WHERE
+    rr.tenant_id = $1
+ORDER BY r.code;
+
+-- name: ClearMfaRequiredRoles :exec
+DELETE FROM mfa_required_roles WHERE tenant_id = $1;
+
+-- name: AddMfaRequiredRole :exec
+INSERT INTO
+    mfa_required_roles (tenant_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,207 @@
+-- name: GetNotificationTemplate :one
+SELECT *
+FROM notification_templates
+WHERE
+    tenant_id = $1
+    AND kind = $2
+    AND locale = $3
+LIMIT 1;
+
+-- name: ListNotificationTemplates :many
+SELECT *
+FROM notification_templates
+WHERE
+    tenant_id = $1
+ORDER BY kind, locale;
+
+-- name: UpsertNotificationTemplate :one
+INSERT INTO
+    notification_templates (
+        id,
+        tenant_id,
+        kind,
+        locale,
+        subject,
+        html_body,
+        text_body
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (tenant_id, kind, locale) DO
+UPDATE
+SET
+    subject = EXCLUDED.subject,
+    html_body = EXCLUDED.html_body,
+    text_body = EXCLUDED.text_body,
+    updated_at = NOW()
+RETURNING
+    *;
+
+-- name: DeleteNotificationTemplate :execrows
+DELETE FROM notification_templates
+WHERE
+    tenant_id = $1
+    AND kind = $2
+    AND locale = $3;
+
+-- name: GetNotificationPreference :one
+SELECT *
+FROM notification_preferences
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND kind = $3
+LIMIT 1;
+
+-- name: ListNotificationPreferences :many
+SELECT *
+FROM notification_preferences
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+ORDER BY kind;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO
+    notification_preferences (
+        tenant_id,
+        user_id,
+        kind,
+        in_app,
+        email
+    )
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, kind) DO
+UPDATE
+SET
+    in_app = EXCLUDED.in_app,
+    email = EXCLUDED.email,
+    updated_at = NOW()
+RETURNING
+    *;
+
+-- name: GetEmailRecipient :one
+SELECT u.id, u.email, u.locale, u.is_active, COALESCE(
+        u.display_name, e.display_name, e.first_name
+    )::text AS name
+FROM users u
+    JOIN employees e ON e.id = u.employee_id
+WHERE
+    u.tenant_id = $1
+    AND u.id = $2
+LIMIT 1;
+
+-- name: EnqueueEmail :exec
+INSERT INTO
+    email_outbox (
+        id,
+        tenant_id,
+        user_id,
+        kind,
+        to_address,
+        subject,
+        html_body,
+        text_body,
+        entity_type,
+        entity_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ClaimDueEmails :many
+UPDATE email_outbox
+SET
+    status = 'sending',
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg ('lease_until')::timestamptz,
+    updated_at = NOW()
+WHERE
+    id IN (
+        SELECT o.id
+        FROM email_outbox o
+        WHERE
+            o.status IN ('queued', 'sending')
+            AND o.next_attempt_at <= NOW()
+        ORDER BY o.next_attempt_at
+        LIMIT sqlc.arg ('batch_size')
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING
+    *;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET
+    status = 'sent',
+    sent_at = NOW(),
+    last_error = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: RescheduleEmail :exec
+UPDATE email_outbox
+SET
+    status = 'queued',
+    last_error = $2,
+    next_attempt_at = $3,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: FailEmail :exec
+UPDATE email_outbox
+SET
+    status = 'failed',
+    last_error = $2,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: RequeueFailedEmail :one
+UPDATE email_outbox
+SET
+    status = 'queued',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+    AND status = 'failed'
+RETURNING
+    *;
+
+-- name: GetEmail :one
+SELECT * FROM email_outbox WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListEmailOutbox :many
+SELECT *
+FROM email_outbox
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountEmailOutbox :one
+SELECT count(*)
+FROM email_outbox
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    );
+
+-- name: SetUserLocale :exec
+UPDATE users
+SET
+    locale = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2;
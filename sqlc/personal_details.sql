@@ -0,0 +1,64 @@
+-- name: GetEmployeePersonalDetails :one
+SELECT *
+FROM employee_personal_details
+WHERE
+    tenant_id = $1
+    AND employee_id = $2
+LIMIT 1;
+
+-- name: GetEmployeePersonalDetailsForUpdate :one
+SELECT *
+FROM employee_personal_details
+WHERE
+    tenant_id = $1
+    AND employee_id = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpsertEmployeePersonalDetails :one
+INSERT INTO
+    employee_personal_details (
+        employee_id,
+        tenant_id,
+        key_id,
+        wrapped_key,
+        personal_email,
+        phone,
+        address,
+        date_of_birth,
+        emergency_contacts,
+        ni_number,
+        updated_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (employee_id) DO UPDATE
+SET
+    key_id = EXCLUDED.key_id,
+    wrapped_key = EXCLUDED.wrapped_key,
+    personal_email = EXCLUDED.personal_email,
+    phone = EXCLUDED.phone,
+    address = EXCLUDED.address,
+    date_of_birth = EXCLUDED.date_of_birth,
+    emergency_contacts = EXCLUDED.emergency_contacts,
+    ni_number = EXCLUDED.ni_number,
+    updated_by_user_id = EXCLUDED.updated_by_user_id,
+    updated_at = NOW()
+RETURNING
+    *;
+
+-- name: ListPersonalDetailsKeysNotWrappedWith :many
+SELECT employee_id, tenant_id, key_id, wrapped_key
+FROM employee_personal_details
+WHERE
+    key_id <> $1
+ORDER BY employee_id
+LIMIT $2;
+
+-- name: RewrapEmployeePersonalDetailsKey :execrows
+UPDATE employee_personal_details
+SET
+    key_id = sqlc.arg ('new_key_id'),
+    wrapped_key = sqlc.arg ('wrapped_key')
+WHERE
+    employee_id = sqlc.arg ('employee_id')
+    AND key_id = sqlc.arg ('old_key_id');
@@ -0,0 +1,261 @@
+-- name: ListEmployeeSubjectAuditLogs :many
+SELECT *
+FROM audit_logs
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND (
+        entity_id = sqlc.arg ('employee_id')::uuid
+        OR entity_id = sqlc.narg ('user_id')::uuid
+        OR entity_id IN (
+            SELECT id
+            FROM training_records
+            WHERE
+                tenant_id = sqlc.arg ('tenant_id')::uuid
+                AND employee_id = sqlc.arg ('employee_id')::uuid
+        )
+        OR entity_id IN (
+            SELECT id
+            FROM employee_competencies
+            WHERE
+                tenant_id = sqlc.arg ('tenant_id')::uuid
+                AND employee_id = sqlc.arg ('employee_id')::uuid
+        )
+        OR entity_id IN (
+            SELECT id
+            FROM employee_change_requests
+            WHERE
+                tenant_id = sqlc.arg ('tenant_id')::uuid
+                AND employee_id = sqlc.arg ('employee_id')::uuid
+        )
+    )
+ORDER BY created_at;
+
+-- name: ListUserActorAuditLogs :many
+SELECT *
+FROM audit_logs
+WHERE
+    tenant_id = $1
+    AND (
+        actor_id = sqlc.arg ('user_id')::uuid
+        OR impersonator_id = sqlc.arg ('user_id')::uuid
+    )
+ORDER BY created_at;
+
+-- name: SetAuditLogChanges :exec
+UPDATE audit_logs SET changes = $2 WHERE id = $1;
+
+-- name: SetWebhookDeliveryData :execrows
+UPDATE webhook_deliveries
+SET
+    payload = jsonb_set(
+        payload,
+        '{data}',
+        sqlc.arg ('data')::jsonb
+    ),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND event_id = $2;
+
+-- name: ListEmployeeTrainingEvidence :many
+SELECT
+    id,
+    evidence_key,
+    evidence_file_name,
+    evidence_content_type
+FROM training_records
+WHERE
+    tenant_id = $1
+    AND employee_id = $2
+    AND evidence_key IS NOT NULL
+ORDER BY completed_on, id;
+
+-- name: EraseEmployee :one
+UPDATE employees
+SET
+    first_name = 'Erased',
+    last_name = 'Employee',
+    display_name = NULL,
+    work_email = NULL,
+    is_active = FALSE,
+    erased_at = NOW(),
+    erased_by_user_id = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: EraseUser :exec
+UPDATE users
+SET
+    email = $3,
+    display_name = NULL,
+    password_hash = NULL,
+    is_active = FALSE,
+    external_id = NULL,
+    avatar_url = NULL,
+    preferences = '{}',
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2;
+
+-- name: DeleteEmployeePersonalDetails :execrows
+DELETE FROM employee_personal_details
+WHERE
+    tenant_id = $1
+    AND employee_id = $2;
+
+-- name: DeleteCustomFieldValues :execrows
+DELETE FROM custom_field_values
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND entity_id = $3;
+
+-- name: ScrubEmployeeChangeRequests :execrows
+UPDATE employee_change_requests
+SET
+    changes = '{}',
+    reason = NULL,
+    decision_notes = CASE
+        WHEN decision_notes IS NULL THEN NULL
+        ELSE '[erased]'
+    END,
+    status = CASE
+        WHEN status = 'pending' THEN 'cancelled'
+        ELSE status
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND employee_id = $2;
+
+-- name: ScrubEmployeeTrainingRecords :execrows
+UPDATE training_records
+SET
+    notes = NULL,
+    evidence_key = NULL,
+    evidence_file_name = NULL,
+    evidence_content_type = NULL,
+    evidence_size = NULL,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND employee_id = $2;
+
+-- name: ScrubEmployeeCompetencies :execrows
+UPDATE employee_competencies
+SET
+    notes = NULL
+WHERE
+    tenant_id = $1
+    AND employee_id = $2;
+
+-- name: ListEmployeeChangeRequestTaskTitles :many
+SELECT DISTINCT
+    title
+FROM tasks
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND entity_type = sqlc.arg ('entity_type')::text
+    AND entity_id IN (
+        SELECT id
+        FROM employee_change_requests
+        WHERE
+            tenant_id = sqlc.arg ('tenant_id')::uuid
+            AND employee_id = sqlc.arg ('employee_id')::uuid
+    );
+
+-- name: ScrubEmployeeChangeRequestTasks :execrows
+UPDATE tasks
+SET
+    title = sqlc.arg ('title')::text,
+    status = CASE
+        WHEN status = 'open' THEN 'cancelled'
+        ELSE status
+    END,
+    completed_by_user_id = CASE
+        WHEN status = 'open' THEN sqlc.narg ('actor_id')::uuid
+        ELSE completed_by_user_id
+    END,
+    completed_at = CASE
+        WHEN status = 'open' THEN NOW()
+        ELSE completed_at
+    END,
+    updated_at = NOW()
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND entity_type = sqlc.arg ('entity_type')::text
+    AND entity_id IN (
+        SELECT id
+        FROM employee_change_requests
+        WHERE
+            tenant_id = sqlc.arg ('tenant_id')::uuid
+            AND employee_id = sqlc.arg ('employee_id')::uuid
+    );
+
+-- name: ScrubEmployeeChangeRequestNotifications :execrows
+UPDATE notifications
+SET
+    title = sqlc.arg ('title')::text
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND entity_type = sqlc.arg ('entity_type')::text
+    AND entity_id IN (
+        SELECT id
+        FROM employee_change_requests
+        WHERE
+            tenant_id = sqlc.arg ('tenant_id')::uuid
+            AND employee_id = sqlc.arg ('employee_id')::uuid
+    );
+
+-- name: DeleteEmployeeChangeRequestEmails :execrows
+DELETE FROM email_outbox
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND entity_type = sqlc.arg ('entity_type')::text
+    AND entity_id IN (
+        SELECT id
+        FROM employee_change_requests
+        WHERE
+            tenant_id = sqlc.arg ('tenant_id')::uuid
+            AND employee_id = sqlc.arg ('employee_id')::uuid
+    );
+
+-- name: DeleteUnlinkedEmailsMentioning :execrows
+DELETE FROM email_outbox
+WHERE
+    tenant_id = sqlc.arg ('tenant_id')::uuid
+    AND entity_id IS NULL
+    AND (
+        strpos(subject, sqlc.arg ('text')::text) > 0
+        OR strpos(text_body, sqlc.arg ('text')::text) > 0
+        OR strpos(html_body, sqlc.arg ('text')::text) > 0
+    );
+
+-- name: DeleteUserSsoIdentities :execrows
+DELETE FROM sso_identities WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: RevokeUserApiKeys :execrows
+UPDATE api_keys
+SET
+    revoked_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND revoked_at IS NULL;
+
+-- name: DeleteUserNotifications :execrows
+DELETE FROM notifications WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: DeleteUserNotificationPreferences :execrows
+DELETE FROM notification_preferences
+WHERE
+    tenant_id = $1
+    AND user_id = $2;
+
+-- name: DeleteUserEmails :execrows
+DELETE FROM email_outbox WHERE tenant_id = $1 AND user_id = $2;
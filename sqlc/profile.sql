@@ -0,0 +1,41 @@
+-- name: ListUserRoleGrants :many
+SELECT
+    r.id AS role_id,
+    r.code,
+    r.name,
+    ur.business_unit_id,
+    bu.name AS business_unit_name,
+    ur.department_id,
+    d.name AS department_name,
+    ur.granted_at
+FROM
+    user_rbac_roles ur
+    JOIN rbac_roles r ON r.id = ur.role_id
+    AND r.tenant_id = ur.tenant_id
+    LEFT JOIN business_units bu ON bu.id = ur.business_unit_id
+    LEFT JOIN departments d ON d.id = ur.department_id
+WHERE
+    ur.tenant_id = $1
+    AND ur.user_id = $2
+ORDER BY r.code, bu.name, d.name;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    display_name = NULLIF(
+        COALESCE(sqlc.narg('display_name')::text, display_name),
+        ''
+    ),
+    avatar_url = NULLIF(
+        COALESCE(sqlc.narg('avatar_url')::text, avatar_url),
+        ''
+    ),
+    preferences = jsonb_strip_nulls(
+        preferences || sqlc.arg('preferences')::jsonb
+    ),
+    updated_at = NOW()
+WHERE
+    tenant_id = sqlc.arg('tenant_id')
+    AND id = sqlc.arg('id')
+RETURNING
+    *;
@@ -35,6 +35,16 @@ LIMIT sqlc.arg ('limit')
 OFFSET
     sqlc.arg ('offset');
 
+-- name: ListUserRoleCodes :many
+SELECT ur.user_id, r.code
+FROM
+    user_rbac_roles ur
+    JOIN rbac_roles r ON ur.role_id = r.id
+    AND ur.tenant_id = r.tenant_id
+WHERE
+    ur.tenant_id = $1
+ORDER BY r.code;
+
 -- name: CountUsers :one
 SELECT count(*)
 FROM users
@@ -86,16 +96,37 @@ SELECT
     d.name AS department_name,
     jt.code AS job_title_code,
     jt.name AS job_title_name,
-    jt.grade AS job_title_grade,
+    jg.code AS job_title_grade,
     m.employee_no AS manager_employee_no,
     m.first_name AS manager_first_name,
     m.last_name AS manager_last_name,
-    m.display_name AS manager_display_name
+    m.display_name AS manager_display_name,
+    EXISTS (
+        SELECT 1
+        FROM
+            training_records tr
+            JOIN training_courses tc ON tc.id = tr.course_id
+        WHERE
+            tr.tenant_id = e.tenant_id
+            AND tr.employee_id = e.id
+            AND tc.is_mandatory
+            AND tc.is_active
+            AND tr.signed_off_at IS NOT NULL
+            AND tr.result = 'pass'
+        GROUP BY
+            tr.course_id
+        HAVING
+            bool_and(tr.expires_on IS NOT NULL)
+            AND max(tr.expires_on) < CURRENT_DATE
+    ) AS has_expired_mandatory_training,
+    COALESCE(cfv.field_values, '{}')::jsonb AS custom_fields
 FROM employees e
 LEFT JOIN business_units bu ON e.business_unit_id = bu.id AND e.tenant_id = bu.tenant_id
 LEFT JOIN departments d ON e.department_id = d.id AND e.tenant_id = d.tenant_id
 LEFT JOIN job_titles jt ON e.job_title_id = jt.id AND e.tenant_id = jt.tenant_id
+LEFT JOIN job_grades jg ON jt.grade_id = jg.id
 LEFT JOIN employees m ON e.manager_id = m.id AND e.tenant_id = m.tenant_id
+LEFT JOIN custom_field_values cfv ON cfv.tenant_id = e.tenant_id AND cfv.entity_type = 'employee' AND cfv.entity_id = e.id
 WHERE e.tenant_id = $1 AND e.id = $2 LIMIT 1;
 
 -- name: ListEmployees :many
@@ -138,16 +169,37 @@ SELECT
     d.name AS department_name,
     jt.code AS job_title_code,
     jt.name AS job_title_name,
-    jt.grade AS job_title_grade,
+    jg.code AS job_title_grade,
     m.employee_no AS manager_employee_no,
     m.first_name AS manager_first_name,
     m.last_name AS manager_last_name,
-    m.display_name AS manager_display_name
+    m.display_name AS manager_display_name,
+    EXISTS (
+        SELECT 1
+        FROM
+            training_records tr
+            JOIN training_courses tc ON tc.id = tr.course_id
+        WHERE
+            tr.tenant_id = e.tenant_id
+            AND tr.employee_id = e.id
+            AND tc.is_mandatory
+            AND tc.is_active
+            AND tr.signed_off_at IS NOT NULL
+            AND tr.result = 'pass'
+        GROUP BY
+            tr.course_id
+        HAVING
+            bool_and(tr.expires_on IS NOT NULL)
+            AND max(tr.expires_on) < CURRENT_DATE
+    ) AS has_expired_mandatory_training,
+    COALESCE(cfv.field_values, '{}')::jsonb AS custom_fields
 FROM employees e
 LEFT JOIN business_units bu ON e.business_unit_id = bu.id AND e.tenant_id = bu.tenant_id
 LEFT JOIN departments d ON e.department_id = d.id AND e.tenant_id = d.tenant_id
 LEFT JOIN job_titles jt ON e.job_title_id = jt.id AND e.tenant_id = jt.tenant_id
+LEFT JOIN job_grades jg ON jt.grade_id = jg.id
 LEFT JOIN employees m ON e.manager_id = m.id AND e.tenant_id = m.tenant_id
+LEFT JOIN custom_field_values cfv ON cfv.tenant_id = e.tenant_id AND cfv.entity_type = 'employee' AND cfv.entity_id = e.id
 WHERE
     e.tenant_id = $1
     AND (
@@ -157,7 +209,45 @@ WHERE
         OR e.display_name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR e.work_email ILIKE '%' || sqlc.arg ('search')::text || '%'
     )
-ORDER BY e.last_name, e.first_name
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR e.department_id = sqlc.narg ('department_id')::uuid
+        OR (
+            sqlc.arg ('include_sub_departments')::boolean
+            AND e.department_id IN (
+                WITH RECURSIVE
+                    dept_tree AS (
+                        SELECT d1.id, ARRAY[d1.id]::uuid[] AS path
+                        FROM departments d1
+                        WHERE
+                            d1.tenant_id = $1
+                            AND d1.id = sqlc.narg ('department_id')::uuid
+                        UNION ALL
+                        SELECT d2.id, dt.path || d2.id
+                        FROM departments d2
+                            INNER JOIN dept_tree dt ON d2.parent_department_id = dt.id
+                        WHERE
+                            d2.tenant_id = $1
+                            AND NOT d2.id = ANY (dt.path)
+                    )
+                SELECT id
+                FROM dept_tree
+            )
+        )
+    )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfx
+            WHERE
+                cfx.tenant_id = $1
+                AND cfx.entity_type = 'employee'
+                AND cfx.entity_id = e.id
+                AND cfx.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
+    )
+ORDER BY e.last_name, e.first_name, e.id
 LIMIT sqlc.arg ('limit')
 OFFSET
     sqlc.arg ('offset');
@@ -173,6 +263,44 @@ WHERE
         OR last_name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR display_name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR work_email ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR department_id = sqlc.narg ('department_id')::uuid
+        OR (
+            sqlc.arg ('include_sub_departments')::boolean
+            AND department_id IN (
+                WITH RECURSIVE
+                    dept_tree AS (
+                        SELECT d1.id, ARRAY[d1.id]::uuid[] AS path
+                        FROM departments d1
+                        WHERE
+                            d1.tenant_id = $1
+                            AND d1.id = sqlc.narg ('department_id')::uuid
+                        UNION ALL
+                        SELECT d2.id, dt.path || d2.id
+                        FROM departments d2
+                            INNER JOIN dept_tree dt ON d2.parent_department_id = dt.id
+                        WHERE
+                            d2.tenant_id = $1
+                            AND NOT d2.id = ANY (dt.path)
+                    )
+                SELECT id
+                FROM dept_tree
+            )
+        )
+    )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'employee'
+                AND cfv.entity_id = employees.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
     );
 
 -- name: CreateEmployee :one
@@ -206,6 +334,24 @@ VALUES (
 RETURNING
     *;
 
+
+-- name: ListEmployeeRefs :many
+SELECT id, employee_no, work_email, is_active
+FROM employees
+WHERE
+    tenant_id = $1;
+
+-- name: UpdateEmployeeManager :one
+UPDATE employees
+SET
+    manager_id = sqlc.narg ('manager_id'),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
 -- name: GetBusinessUnit :one
 SELECT *
 FROM business_units
@@ -224,11 +370,26 @@ WHERE
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
     )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'business_unit'
+                AND cfv.entity_id = business_units.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
+    )
 ORDER BY name
 LIMIT sqlc.arg ('limit')
 OFFSET
     sqlc.arg ('offset');
 
+-- name: ListAllBusinessUnits :many
+SELECT * FROM business_units WHERE tenant_id = $1 ORDER BY name;
+
 -- name: CountBusinessUnits :one
 SELECT count(*)
 FROM business_units
@@ -238,6 +399,18 @@ WHERE
         sqlc.arg ('search')::text = ''
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'business_unit'
+                AND cfv.entity_id = business_units.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
     );
 
 -- name: CreateBusinessUnit :one
@@ -247,6 +420,105 @@ VALUES ($1, $2, $3, $4)
 RETURNING
     *;
 
+
+-- name: ListBusinessUnitRefs :many
+SELECT id, code, is_active
+FROM business_units
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL;
+
+-- name: LinkBusinessUnitDepartment :one
+INSERT INTO
+    business_unit_departments (
+        tenant_id,
+        business_unit_id,
+        department_id,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: UnlinkBusinessUnitDepartment :execrows
+DELETE FROM business_unit_departments
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3;
+
+-- name: CountBusinessUnitDepartmentLinks :one
+SELECT count(*)
+FROM business_unit_departments
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3;
+
+-- name: CountEmployeesAtBusinessUnitDepartment :one
+SELECT count(*)
+FROM employees
+WHERE
+    tenant_id = $1
+    AND business_unit_id = $2
+    AND department_id = $3;
+
+-- name: ListBusinessUnitDepartments :many
+SELECT
+    d.id,
+    d.parent_department_id,
+    d.code,
+    d.name,
+    d.is_active,
+    bud.created_at AS linked_at,
+    (
+        SELECT count(*)
+        FROM employees e
+        WHERE
+            e.tenant_id = bud.tenant_id
+            AND e.business_unit_id = bud.business_unit_id
+            AND e.department_id = bud.department_id
+            AND e.is_active
+    )::bigint AS headcount
+FROM
+    business_unit_departments bud
+    JOIN departments d ON d.id = bud.department_id
+WHERE
+    bud.tenant_id = $1
+    AND bud.business_unit_id = $2
+ORDER BY d.name;
+
+-- name: ListBusinessUnitDepartmentMatrix :many
+SELECT
+    bud.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    bud.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    (
+        SELECT count(*)
+        FROM employees e
+        WHERE
+            e.tenant_id = bud.tenant_id
+            AND e.business_unit_id = bud.business_unit_id
+            AND e.department_id = bud.department_id
+            AND e.is_active
+    )::bigint AS headcount
+FROM
+    business_unit_departments bud
+    JOIN business_units bu ON bu.id = bud.business_unit_id
+    JOIN departments d ON d.id = bud.department_id
+WHERE
+    bud.tenant_id = $1
+ORDER BY bu.name, d.name;
+
+-- name: ListBusinessUnitDepartmentRefs :many
+SELECT business_unit_id, department_id
+FROM business_unit_departments
+WHERE
+    tenant_id = $1;
+
 -- name: GetDepartment :one
 SELECT * FROM departments WHERE tenant_id = $1 AND id = $2 LIMIT 1;
 
@@ -260,11 +532,47 @@ WHERE
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
     )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'department'
+                AND cfv.entity_id = departments.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
+    )
 ORDER BY name
 LIMIT sqlc.arg ('limit')
 OFFSET
     sqlc.arg ('offset');
 
+-- name: ListAllDepartments :many
+SELECT * FROM departments WHERE tenant_id = $1 ORDER BY name;
+
+-- name: UpdateDepartmentParent :one
+UPDATE departments
+SET
+    parent_department_id = sqlc.narg ('parent_department_id'),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: CountActiveEmployeesByDepartment :many
+SELECT department_id, count(*) AS headcount
+FROM employees
+WHERE
+    tenant_id = $1
+    AND is_active
+    AND department_id IS NOT NULL
+GROUP BY
+    department_id;
+
 -- name: CountDepartments :one
 SELECT count(*)
 FROM departments
@@ -274,6 +582,18 @@ WHERE
         sqlc.arg ('search')::text = ''
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'department'
+                AND cfv.entity_id = departments.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
     );
 
 -- name: CreateDepartment :one
@@ -289,6 +609,14 @@ VALUES ($1, $2, $3, $4, $5)
 RETURNING
     *;
 
+
+-- name: ListDepartmentRefs :many
+SELECT id, code, is_active
+FROM departments
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL;
+
 -- name: GetJobTitle :one
 SELECT * FROM job_titles WHERE tenant_id = $1 AND id = $2 LIMIT 1;
 
@@ -302,11 +630,26 @@ WHERE
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
     )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'job_title'
+                AND cfv.entity_id = job_titles.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
+    )
 ORDER BY name
 LIMIT sqlc.arg ('limit')
 OFFSET
     sqlc.arg ('offset');
 
+-- name: ListAllJobTitles :many
+SELECT * FROM job_titles WHERE tenant_id = $1 ORDER BY name;
+
 -- name: CountJobTitles :one
 SELECT count(*)
 FROM job_titles
@@ -316,6 +659,18 @@ WHERE
         sqlc.arg ('search')::text = ''
         OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
         OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+    AND (
+        sqlc.narg ('custom_fields')::jsonb IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM custom_field_values cfv
+            WHERE
+                cfv.tenant_id = $1
+                AND cfv.entity_type = 'job_title'
+                AND cfv.entity_id = job_titles.id
+                AND cfv.field_values @> sqlc.narg ('custom_fields')::jsonb
+        )
     );
 
 -- name: CreateJobTitle :one
@@ -325,15 +680,112 @@ INSERT INTO
         tenant_id,
         code,
         name,
-        grade
+        grade_id,
+        default_department_id
     )
-VALUES ($1, $2, $3, $4, $5)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: UpdateJobTitle :one
+UPDATE job_titles
+SET
+    code = $3,
+    name = $4,
+    grade_id = $5,
+    default_department_id = $6,
+    is_active = $7,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
 RETURNING
     *;
 
+-- name: GetJobGrade :one
+SELECT * FROM job_grades WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: GetJobGradeByCode :one
+SELECT * FROM job_grades WHERE tenant_id = $1 AND lower(code) = lower(sqlc.arg ('code')::text) LIMIT 1;
+
+-- name: ListJobGrades :many
+SELECT *
+FROM job_grades
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY level, code
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: ListAllJobGrades :many
+SELECT * FROM job_grades WHERE tenant_id = $1 ORDER BY level, code;
+
+-- name: CountJobGrades :one
+SELECT count(*)
+FROM job_grades
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: CreateJobGrade :one
+INSERT INTO
+    job_grades (
+        id,
+        tenant_id,
+        code,
+        name,
+        level,
+        currency,
+        min_pay,
+        mid_pay,
+        max_pay
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    *;
+
+-- name: UpdateJobGrade :one
+UPDATE job_grades
+SET
+    code = $3,
+    name = $4,
+    level = $5,
+    currency = $6,
+    min_pay = $7,
+    mid_pay = $8,
+    max_pay = $9,
+    is_active = $10,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+
+-- name: ListJobTitleRefs :many
+SELECT id, code, is_active
+FROM job_titles
+WHERE
+    tenant_id = $1
+    AND code IS NOT NULL;
+
 -- name: GetRole :one
 SELECT * FROM rbac_roles WHERE tenant_id = $1 AND id = $2 LIMIT 1;
 
+-- name: GetRoleByCode :one
+SELECT * FROM rbac_roles WHERE tenant_id = $1 AND code = $2 LIMIT 1;
+
 -- name: ListRoles :many
 SELECT * FROM rbac_roles WHERE tenant_id = $1 ORDER BY name;
 
@@ -386,24 +838,269 @@ WHERE
     AND user_id = $2
     AND role_id = $3;
 
--- name: GetEmployeeHierarchy :many
+-- name: GetEmployeeSubtree :many
 WITH RECURSIVE
-    employee_tree AS (
-        SELECT *
+    subtree AS (
+        SELECT e1.id, 0::int AS depth, ARRAY[e1.id]::uuid[] AS path
         FROM employees e1
         WHERE
             e1.tenant_id = $1
             AND e1.id = $2
         UNION ALL
-        SELECT e2.*
+        SELECT e2.id, st.depth + 1, st.path || e2.id
         FROM
             employees e2
-            INNER JOIN employee_tree et ON e2.manager_id = et.id
+            INNER JOIN subtree st ON e2.manager_id = st.id
         WHERE
             e2.tenant_id = $1
+            AND NOT e2.id = ANY (st.path)
+            AND st.depth < sqlc.arg ('max_depth')::int
     )
-SELECT *
-FROM employee_tree;
+SELECT
+    st.depth::int AS depth,
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    subtree st
+    JOIN employees e ON e.id = st.id
+    AND e.tenant_id = $1
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+ORDER BY st.depth, e.last_name, e.first_name, e.id;
+
+-- name: GetEmployeeChainOfCommand :many
+WITH RECURSIVE
+    chain AS (
+        SELECT e1.id, e1.manager_id, 0::int AS level, ARRAY[e1.id]::uuid[] AS path
+        FROM employees e1
+        WHERE
+            e1.tenant_id = $1
+            AND e1.id = $2
+        UNION ALL
+        SELECT m.id, m.manager_id, c.level + 1, c.path || m.id
+        FROM employees m
+            INNER JOIN chain c ON m.id = c.manager_id
+        WHERE
+            m.tenant_id = $1
+            AND NOT m.id = ANY (c.path)
+    )
+SELECT
+    c.level::int AS level,
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    chain c
+    JOIN employees e ON e.id = c.id
+    AND e.tenant_id = $1
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+WHERE
+    c.level > 0
+ORDER BY c.level;
+
+-- name: ListDirectReports :many
+SELECT
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade,
+    (
+        SELECT count(*)
+        FROM employees r
+        WHERE
+            r.tenant_id = e.tenant_id
+            AND r.manager_id = e.id
+    )::int AS direct_report_count
+FROM
+    employees e
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+WHERE
+    e.tenant_id = $1
+    AND e.manager_id = $2
+ORDER BY e.last_name, e.first_name, e.id;
+
+-- name: ListOrgChartNodes :many
+SELECT
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.display_name,
+    e.work_email,
+    e.status,
+    e.is_active,
+    e.created_at,
+    e.updated_at,
+    e.manager_id,
+    e.business_unit_id,
+    bu.code AS business_unit_code,
+    bu.name AS business_unit_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    e.job_title_id,
+    jt.code AS job_title_code,
+    jt.name AS job_title_name,
+    jg.code AS job_title_grade
+FROM
+    employees e
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN job_titles jt ON e.job_title_id = jt.id
+    AND e.tenant_id = jt.tenant_id
+    LEFT JOIN job_grades jg ON jt.grade_id = jg.id
+WHERE
+    e.tenant_id = $1
+    AND (
+        sqlc.arg ('include_inactive')::boolean
+        OR e.is_active
+    )
+ORDER BY e.last_name, e.first_name, e.id;
+
+-- name: ListEmployeesWithForeignManager :many
+SELECT e.id, e.employee_no, e.first_name, e.last_name, e.manager_id
+FROM employees e
+WHERE
+    e.tenant_id = $1
+    AND e.manager_id IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM employees m
+        WHERE
+            m.id = e.manager_id
+            AND m.tenant_id = e.tenant_id
+    )
+ORDER BY e.employee_no;
+
+-- name: ListInactiveManagersWithActiveReports :many
+SELECT m.id, m.employee_no, m.first_name, m.last_name, count(r.id) AS active_report_count
+FROM employees m
+    JOIN employees r ON r.manager_id = m.id
+    AND r.tenant_id = m.tenant_id
+WHERE
+    m.tenant_id = $1
+    AND NOT m.is_active
+    AND r.is_active
+GROUP BY
+    m.id,
+    m.employee_no,
+    m.first_name,
+    m.last_name
+ORDER BY m.employee_no;
+
+-- name: ListEmployeesInInactiveOrgUnits :many
+SELECT
+    e.id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    d.name AS department_name,
+    COALESCE(NOT d.is_active, false)::boolean AS department_inactive,
+    e.business_unit_id,
+    bu.name AS business_unit_name,
+    COALESCE(NOT bu.is_active, false)::boolean AS business_unit_inactive
+FROM
+    employees e
+    LEFT JOIN departments d ON e.department_id = d.id
+    AND e.tenant_id = d.tenant_id
+    LEFT JOIN business_units bu ON e.business_unit_id = bu.id
+    AND e.tenant_id = bu.tenant_id
+WHERE
+    e.tenant_id = $1
+    AND e.is_active
+    AND (
+        NOT d.is_active
+        OR NOT bu.is_active
+    )
+ORDER BY e.employee_no;
 
 -- name: InsertAuditLog :one
 INSERT INTO
@@ -414,9 +1111,11 @@ INSERT INTO
         action,
         entity_type,
         entity_id,
-        changes
+        changes,
+        actor_api_key_id,
+        impersonator_id
     )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
     *;
 
@@ -450,4 +1149,203 @@ WHERE
     AND (
         sqlc.arg ('action')::text = ''
         OR action = sqlc.arg ('action')::text
-    );
\ No newline at end of file
+    );
+
+-- name: GetCompetency :one
+SELECT * FROM competencies WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListCompetencies :many
+SELECT *
+FROM competencies
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR document_ref ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY code
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountCompetencies :one
+SELECT count(*)
+FROM competencies
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR document_ref ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: CreateCompetency :one
+INSERT INTO
+    competencies (
+        id,
+        tenant_id,
+        code,
+        name,
+        description,
+        kind,
+        document_ref,
+        validity_months
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: UpdateCompetency :one
+UPDATE competencies
+SET
+    code = $3,
+    name = $4,
+    description = $5,
+    kind = $6,
+    document_ref = $7,
+    validity_months = $8,
+    is_active = $9,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: ListJobTitleRequirements :many
+SELECT
+    c.id,
+    c.code,
+    c.name,
+    c.kind,
+    c.document_ref,
+    c.validity_months,
+    c.is_active,
+    jtc.is_mandatory
+FROM
+    job_title_competencies jtc
+    JOIN competencies c ON c.id = jtc.competency_id
+WHERE
+    jtc.tenant_id = $1
+    AND jtc.job_title_id = $2
+ORDER BY c.code;
+
+-- name: DeleteJobTitleRequirements :exec
+DELETE FROM job_title_competencies WHERE tenant_id = $1 AND job_title_id = $2;
+
+-- name: AddJobTitleRequirement :exec
+INSERT INTO
+    job_title_competencies (
+        tenant_id,
+        job_title_id,
+        competency_id,
+        is_mandatory
+    )
+VALUES ($1, $2, $3, $4);
+
+-- name: CreateEmployeeCompetency :one
+INSERT INTO
+    employee_competencies (
+        id,
+        tenant_id,
+        employee_id,
+        competency_id,
+        achieved_on,
+        expires_on,
+        source,
+        notes,
+        recorded_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    *;
+
+-- name: ListEmployeeCompetencies :many
+SELECT
+    ec.id,
+    ec.competency_id,
+    c.code AS competency_code,
+    c.name AS competency_name,
+    c.kind AS competency_kind,
+    ec.achieved_on,
+    ec.expires_on,
+    ec.source,
+    ec.notes,
+    ec.recorded_by_user_id,
+    ec.created_at
+FROM
+    employee_competencies ec
+    JOIN competencies c ON c.id = ec.competency_id
+WHERE
+    ec.tenant_id = $1
+    AND ec.employee_id = $2
+ORDER BY ec.achieved_on DESC, c.code;
+
+-- name: ListCompetencyRequirementsForEmployees :many
+SELECT
+    e.id AS employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    e.job_title_id,
+    jt.name AS job_title_name,
+    c.id AS competency_id,
+    c.code AS competency_code,
+    c.name AS competency_name,
+    c.kind AS competency_kind,
+    jtc.is_mandatory
+FROM
+    employees e
+    JOIN job_titles jt ON jt.id = e.job_title_id
+    JOIN job_title_competencies jtc ON jtc.job_title_id = e.job_title_id
+    JOIN competencies c ON c.id = jtc.competency_id
+WHERE
+    e.tenant_id = $1
+    AND e.is_active
+    AND c.is_active
+    AND (
+        sqlc.narg ('employee_id')::uuid IS NULL
+        OR e.id = sqlc.narg ('employee_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR e.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.narg ('job_title_id')::uuid IS NULL
+        OR e.job_title_id = sqlc.narg ('job_title_id')
+    )
+ORDER BY e.last_name, e.first_name, e.id, c.code;
+
+-- name: ListLatestEmployeeCompetencies :many
+SELECT
+    ec.employee_id,
+    ec.competency_id,
+    max(ec.achieved_on)::date AS last_achieved_on,
+    bool_or(ec.expires_on IS NULL)::boolean AS has_non_expiring,
+    max(ec.expires_on)::date AS latest_expires_on
+FROM
+    employee_competencies ec
+    JOIN employees e ON e.id = ec.employee_id
+WHERE
+    ec.tenant_id = $1
+    AND e.is_active
+    AND (
+        sqlc.narg ('employee_id')::uuid IS NULL
+        OR e.id = sqlc.narg ('employee_id')
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR e.department_id = sqlc.narg ('department_id')
+    )
+    AND (
+        sqlc.narg ('job_title_id')::uuid IS NULL
+        OR e.job_title_id = sqlc.narg ('job_title_id')
+    )
+GROUP BY
+    ec.employee_id,
+    ec.competency_id;
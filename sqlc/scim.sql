@@ -0,0 +1,190 @@
+-- name: CreateScimToken :one
+INSERT INTO
+    scim_tokens (
+        id,
+        tenant_id,
+        name,
+        token_hash,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: ListScimTokens :many
+SELECT *
+FROM scim_tokens
+WHERE
+    tenant_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeScimToken :execrows
+UPDATE scim_tokens
+SET
+    revoked_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+    AND revoked_at IS NULL;
+
+-- name: GetActiveScimTokenByHash :one
+SELECT *
+FROM scim_tokens
+WHERE
+    token_hash = $1
+    AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: TouchScimToken :exec
+UPDATE scim_tokens SET last_used_at = NOW() WHERE id = $1;
+
+-- name: ListScimUsers :many
+SELECT
+    u.id,
+    u.external_id,
+    u.email,
+    u.display_name,
+    u.is_active,
+    u.created_at,
+    u.updated_at,
+    e.id AS employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.work_email,
+    e.manager_id,
+    mu.id AS manager_user_id
+FROM
+    users u
+    JOIN employees e ON e.id = u.employee_id
+    AND e.tenant_id = u.tenant_id
+    LEFT JOIN users mu ON mu.employee_id = e.manager_id
+    AND mu.tenant_id = e.tenant_id
+WHERE
+    u.tenant_id = $1
+ORDER BY u.created_at, u.id;
+
+-- name: GetScimUser :one
+SELECT
+    u.id,
+    u.external_id,
+    u.email,
+    u.display_name,
+    u.is_active,
+    u.created_at,
+    u.updated_at,
+    e.id AS employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.work_email,
+    e.manager_id,
+    mu.id AS manager_user_id
+FROM
+    users u
+    JOIN employees e ON e.id = u.employee_id
+    AND e.tenant_id = u.tenant_id
+    LEFT JOIN users mu ON mu.employee_id = e.manager_id
+    AND mu.tenant_id = e.tenant_id
+WHERE
+    u.tenant_id = $1
+    AND u.id = $2
+LIMIT 1;
+
+-- name: UpdateScimUser :one
+UPDATE users
+SET
+    email = $3,
+    display_name = $4,
+    external_id = $5,
+    is_active = $6,
+    password_hash = COALESCE(
+        sqlc.narg ('password_hash')::text,
+        password_hash
+    ),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: UpdateScimEmployee :one
+UPDATE employees
+SET
+    employee_no = $3,
+    first_name = $4,
+    last_name = $5,
+    display_name = $6,
+    work_email = $7,
+    manager_id = $8,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: RevokeAllUserRoles :execrows
+DELETE FROM user_rbac_roles WHERE tenant_id = $1 AND user_id = $2;
+
+-- name: ListRoleMembers :many
+SELECT DISTINCT
+    ur.role_id,
+    u.id,
+    u.email,
+    u.display_name
+FROM
+    user_rbac_roles ur
+    JOIN users u ON u.id = ur.user_id
+    AND u.tenant_id = ur.tenant_id
+WHERE
+    ur.tenant_id = $1
+    AND (
+        sqlc.narg ('role_id')::uuid IS NULL
+        OR ur.role_id = sqlc.narg ('role_id')::uuid
+    )
+ORDER BY u.email;
+
+-- name: CountActiveRoleHolders :one
+SELECT
+    COUNT(DISTINCT u.id)
+FROM
+    user_rbac_roles ur
+    JOIN users u ON u.id = ur.user_id
+    AND u.tenant_id = ur.tenant_id
+WHERE
+    ur.tenant_id = $1
+    AND ur.role_id = $2
+    AND u.is_active;
+
+-- name: ListUserRoleRefs :many
+SELECT DISTINCT
+    r.id,
+    r.name
+FROM
+    user_rbac_roles ur
+    JOIN rbac_roles r ON r.id = ur.role_id
+    AND r.tenant_id = ur.tenant_id
+WHERE
+    ur.tenant_id = $1
+    AND ur.user_id = $2
+ORDER BY r.name;
+
+-- name: UpdateScimRole :one
+UPDATE rbac_roles
+SET
+    name = $3,
+    external_id = $4,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: RevokeRoleFromAllUsers :execrows
+DELETE FROM user_rbac_roles WHERE tenant_id = $1 AND role_id = $2;
+
+-- name: DeleteRole :execrows
+DELETE FROM rbac_roles WHERE tenant_id = $1 AND id = $2;
@@ -0,0 +1,178 @@
+-- name: GetTenantByCode :one
+SELECT * FROM tenants WHERE code = $1 LIMIT 1;
+
+-- name: GetSsoProvider :one
+SELECT * FROM sso_providers WHERE tenant_id = $1 LIMIT 1;
+
+-- name: UpsertSsoProvider :one
+INSERT INTO
+    sso_providers (
+        id,
+        tenant_id,
+        issuer,
+        client_id,
+        client_secret,
+        scopes,
+        groups_claim,
+        jit_provisioning,
+        is_active
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (tenant_id) DO UPDATE
+SET
+    issuer = EXCLUDED.issuer,
+    client_id = EXCLUDED.client_id,
+    client_secret = EXCLUDED.client_secret,
+    scopes = EXCLUDED.scopes,
+    groups_claim = EXCLUDED.groups_claim,
+    jit_provisioning = EXCLUDED.jit_provisioning,
+    is_active = EXCLUDED.is_active,
+    updated_at = NOW()
+RETURNING
+    *;
+
+-- name: DeleteSsoProvider :execrows
+DELETE FROM sso_providers WHERE tenant_id = $1;
+
+-- name: ListSsoGroupMappings :many
+SELECT m.id, m.group_name, m.role_id, r.code AS role_code, r.name AS role_name, m.created_at
+FROM
+    sso_group_mappings m
+    JOIN rbac_roles r ON r.id = m.role_id
+WHERE
+    m.tenant_id = $1
+    AND m.provider_id = $2
+ORDER BY m.group_name, r.code;
+
+-- name: CreateSsoGroupMapping :one
+INSERT INTO
+    sso_group_mappings (
+        id,
+        tenant_id,
+        provider_id,
+        group_name,
+        role_id
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: DeleteSsoGroupMapping :execrows
+DELETE FROM sso_group_mappings WHERE tenant_id = $1 AND id = $2;
+
+-- name: CreateSsoLoginState :exec
+INSERT INTO
+    sso_login_states (
+        state,
+        tenant_id,
+        provider_id,
+        nonce,
+        code_verifier,
+        return_to,
+        expires_at,
+        browser_hash
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ConsumeSsoLoginState :one
+DELETE FROM sso_login_states
+WHERE
+    state = $1
+    AND tenant_id = $2
+    AND browser_hash = $3
+    AND expires_at > NOW()
+RETURNING
+    *;
+
+-- name: DeleteExpiredSsoLoginStates :exec
+DELETE FROM sso_login_states WHERE expires_at <= NOW();
+
+-- name: GetSsoIdentity :one
+SELECT *
+FROM sso_identities
+WHERE
+    provider_id = $1
+    AND subject = $2
+LIMIT 1;
+
+-- name: CreateSsoIdentity :one
+INSERT INTO
+    sso_identities (
+        id,
+        tenant_id,
+        provider_id,
+        subject,
+        user_id,
+        email,
+        last_login_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING
+    *;
+
+-- name: TouchSsoIdentity :exec
+UPDATE sso_identities
+SET
+    email = $2,
+    last_login_at = NOW()
+WHERE
+    id = $1;
+
+-- name: GetUserByEmailFold :one
+SELECT *
+FROM users
+WHERE
+    tenant_id = $1
+    AND lower(email) = lower(sqlc.arg ('email')::text)
+LIMIT 1;
+
+-- name: TouchUserLogin :exec
+UPDATE users SET last_login_at = NOW() WHERE id = $1;
+
+-- name: GrantUnscopedRole :execrows
+INSERT INTO
+    user_rbac_roles (tenant_id, user_id, role_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (
+    tenant_id,
+    user_id,
+    role_id,
+    business_unit_id,
+    department_id
+) DO NOTHING;
+
+-- name: RevokeUnscopedRole :execrows
+DELETE FROM user_rbac_roles
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND role_id = $3
+    AND business_unit_id IS NULL
+    AND department_id IS NULL;
+
+-- name: CountOtherActiveRoleHolders :one
+SELECT
+    COUNT(DISTINCT u.id)
+FROM
+    user_rbac_roles ur
+    JOIN users u ON u.id = ur.user_id
+    AND u.tenant_id = ur.tenant_id
+WHERE
+    ur.tenant_id = $1
+    AND ur.role_id = $2
+    AND u.id <> sqlc.arg ('user_id')
+    AND u.is_active;
+
+-- name: GetUnlinkedEmployeeByEmail :one
+SELECT e.*
+FROM employees e
+WHERE
+    e.tenant_id = $1
+    AND lower(e.work_email) = lower(sqlc.arg ('email')::text)
+    AND NOT EXISTS (
+        SELECT 1
+        FROM users u
+        WHERE
+            u.employee_id = e.id
+    )
+LIMIT 1;
@@ -0,0 +1,213 @@
+-- name: CreateTask :one
+INSERT INTO
+    tasks (
+        id,
+        tenant_id,
+        entity_type,
+        entity_id,
+        kind,
+        title,
+        description,
+        assignee_employee_id,
+        assignee_role_code,
+        due_date,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING
+    *;
+
+-- name: GetTask :one
+SELECT * FROM tasks WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: CloseTask :one
+UPDATE tasks
+SET
+    status = $3,
+    outcome = $4,
+    completed_by_user_id = $5,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+    AND status = 'open'
+RETURNING
+    *;
+
+-- name: CloseEntityTasks :exec
+UPDATE tasks
+SET
+    status = $4,
+    outcome = $5,
+    completed_by_user_id = $6,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND entity_id = $3
+    AND status = 'open';
+
+-- name: ReassignEntityTasks :exec
+UPDATE tasks
+SET
+    assignee_employee_id = $4,
+    due_date = $5,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND entity_type = $2
+    AND entity_id = $3
+    AND status = 'open'
+    AND assignee_employee_id IS NOT NULL;
+
+-- name: ListUserTasks :many
+SELECT *
+FROM tasks t
+WHERE
+    t.tenant_id = $1
+    AND (
+        t.assignee_employee_id = sqlc.arg ('employee_id')
+        OR t.assignee_role_code IN (
+            SELECT r.code
+            FROM
+                user_rbac_roles ur
+                JOIN rbac_roles r ON ur.role_id = r.id
+            WHERE
+                ur.tenant_id = t.tenant_id
+                AND ur.user_id = sqlc.arg ('user_id')
+        )
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR t.status = sqlc.arg ('status')::text
+    )
+    AND (
+        NOT sqlc.arg ('overdue_only')::boolean
+        OR t.due_date < CURRENT_DATE
+    )
+ORDER BY t.due_date NULLS LAST, t.created_at
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountUserTasks :one
+SELECT count(*)
+FROM tasks t
+WHERE
+    t.tenant_id = $1
+    AND (
+        t.assignee_employee_id = sqlc.arg ('employee_id')
+        OR t.assignee_role_code IN (
+            SELECT r.code
+            FROM
+                user_rbac_roles ur
+                JOIN rbac_roles r ON ur.role_id = r.id
+            WHERE
+                ur.tenant_id = t.tenant_id
+                AND ur.user_id = sqlc.arg ('user_id')
+        )
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR t.status = sqlc.arg ('status')::text
+    )
+    AND (
+        NOT sqlc.arg ('overdue_only')::boolean
+        OR t.due_date < CURRENT_DATE
+    );
+
+-- name: CountRolesByCode :one
+SELECT count(*)
+FROM rbac_roles
+WHERE
+    tenant_id = $1
+    AND code = $2
+    AND is_active = TRUE;
+
+-- name: GetUserByEmployee :one
+SELECT * FROM users WHERE tenant_id = $1 AND employee_id = $2 LIMIT 1;
+
+-- name: CreateNotification :exec
+INSERT INTO
+    notifications (
+        id,
+        tenant_id,
+        user_id,
+        kind,
+        title,
+        body,
+        entity_type,
+        entity_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListActiveRoleUserIDs :many
+SELECT DISTINCT
+    u.id
+FROM
+    user_rbac_roles ur
+    JOIN rbac_roles r ON ur.role_id = r.id
+    JOIN users u ON u.id = ur.user_id
+WHERE
+    ur.tenant_id = $1
+    AND r.code = $2
+    AND u.is_active = TRUE;
+
+-- name: ListNotifications :many
+SELECT *
+FROM notifications
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND (
+        NOT sqlc.arg ('unread_only')::boolean
+        OR read_at IS NULL
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountNotifications :one
+SELECT count(*)
+FROM notifications
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND (
+        NOT sqlc.arg ('unread_only')::boolean
+        OR read_at IS NULL
+    );
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET
+    read_at = COALESCE(read_at, NOW())
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND id = $3
+RETURNING
+    *;
+
+-- name: MarkNotificationUnread :one
+UPDATE notifications
+SET
+    read_at = NULL
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND id = $3
+RETURNING
+    *;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET
+    read_at = NOW()
+WHERE
+    tenant_id = $1
+    AND user_id = $2
+    AND read_at IS NULL;
@@ -0,0 +1,302 @@
+-- name: CreateTrainingCourse :one
+INSERT INTO
+    training_courses (
+        id,
+        tenant_id,
+        code,
+        name,
+        description,
+        competency_id,
+        validity_months,
+        is_mandatory
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: UpdateTrainingCourse :one
+UPDATE training_courses
+SET
+    code = $3,
+    name = $4,
+    description = $5,
+    competency_id = $6,
+    validity_months = $7,
+    is_mandatory = $8,
+    is_active = $9,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: GetTrainingCourse :one
+SELECT * FROM training_courses WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListTrainingCourses :many
+SELECT *
+FROM training_courses
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    )
+ORDER BY code
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountTrainingCourses :one
+SELECT count(*)
+FROM training_courses
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.arg ('search')::text = ''
+        OR name ILIKE '%' || sqlc.arg ('search')::text || '%'
+        OR code ILIKE '%' || sqlc.arg ('search')::text || '%'
+    );
+
+-- name: CreateTrainingSession :one
+INSERT INTO
+    training_sessions (
+        id,
+        tenant_id,
+        course_id,
+        trainer_employee_id,
+        external_trainer,
+        business_unit_id,
+        location,
+        starts_at,
+        ends_at,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING
+    *;
+
+-- name: GetTrainingSession :one
+SELECT * FROM training_sessions WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: UpdateTrainingSessionStatus :one
+UPDATE training_sessions
+SET
+    status = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: ListTrainingSessions :many
+SELECT *
+FROM training_sessions
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.narg ('course_id')::uuid IS NULL
+        OR course_id = sqlc.narg ('course_id')
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    )
+ORDER BY starts_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountTrainingSessions :one
+SELECT count(*)
+FROM training_sessions
+WHERE
+    tenant_id = $1
+    AND (
+        sqlc.narg ('course_id')::uuid IS NULL
+        OR course_id = sqlc.narg ('course_id')
+    )
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    );
+
+-- name: CreateTrainingRecord :one
+INSERT INTO
+    training_records (
+        id,
+        tenant_id,
+        employee_id,
+        course_id,
+        session_id,
+        completed_on,
+        expires_on,
+        result,
+        notes,
+        recorded_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING
+    *;
+
+-- name: GetTrainingRecord :one
+SELECT * FROM training_records WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: SetTrainingRecordEvidence :one
+UPDATE training_records
+SET
+    evidence_key = $3,
+    evidence_file_name = $4,
+    evidence_content_type = $5,
+    evidence_size = $6,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: SignOffTrainingRecord :one
+UPDATE training_records
+SET
+    signed_off_by_user_id = $3,
+    signed_off_at = NOW(),
+    employee_competency_id = $4,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+    AND signed_off_at IS NULL
+RETURNING
+    *;
+
+-- name: ListEmployeeTrainingRecords :many
+SELECT
+    tr.id,
+    tr.employee_id,
+    tr.course_id,
+    c.code AS course_code,
+    c.name AS course_name,
+    c.is_mandatory,
+    tr.session_id,
+    s.starts_at AS session_starts_at,
+    tr.completed_on,
+    tr.expires_on,
+    tr.result,
+    tr.notes,
+    tr.evidence_file_name,
+    tr.evidence_content_type,
+    tr.evidence_size,
+    tr.signed_off_by_user_id,
+    tr.signed_off_at,
+    tr.employee_competency_id,
+    tr.recorded_by_user_id,
+    tr.created_at
+FROM
+    training_records tr
+    JOIN training_courses c ON c.id = tr.course_id
+    LEFT JOIN training_sessions s ON s.id = tr.session_id
+WHERE
+    tr.tenant_id = $1
+    AND tr.employee_id = $2
+ORDER BY tr.completed_on DESC, c.code;
+
+-- name: ListSessionTrainingRecords :many
+SELECT
+    tr.id,
+    tr.employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    tr.completed_on,
+    tr.expires_on,
+    tr.result,
+    tr.evidence_file_name,
+    tr.signed_off_by_user_id,
+    tr.signed_off_at
+FROM
+    training_records tr
+    JOIN employees e ON e.id = tr.employee_id
+WHERE
+    tr.tenant_id = $1
+    AND tr.session_id = $2
+ORDER BY e.last_name, e.first_name;
+
+-- name: ListExpiringTrainingRecords :many
+WITH
+    latest AS (
+        SELECT DISTINCT
+            ON (tr.employee_id, tr.course_id) tr.id,
+            tr.employee_id,
+            tr.course_id,
+            tr.completed_on,
+            tr.expires_on
+        FROM training_records tr
+        WHERE
+            tr.tenant_id = $1
+            AND tr.signed_off_at IS NOT NULL
+            AND tr.result = 'pass'
+        ORDER BY
+            tr.employee_id,
+            tr.course_id,
+            tr.expires_on DESC NULLS FIRST
+    )
+SELECT
+    l.id,
+    l.employee_id,
+    e.employee_no,
+    e.first_name,
+    e.last_name,
+    e.department_id,
+    d.code AS department_code,
+    d.name AS department_name,
+    l.course_id,
+    c.code AS course_code,
+    c.name AS course_name,
+    c.is_mandatory,
+    l.completed_on,
+    l.expires_on
+FROM
+    latest l
+    JOIN employees e ON e.id = l.employee_id
+    JOIN training_courses c ON c.id = l.course_id
+    LEFT JOIN departments d ON d.id = e.department_id
+WHERE
+    e.is_active
+    AND c.is_active
+    AND l.expires_on IS NOT NULL
+    AND l.expires_on <= sqlc.arg ('until')::date
+    AND (
+        sqlc.arg ('include_expired')::boolean
+        OR l.expires_on >= CURRENT_DATE
+    )
+    AND (
+        sqlc.narg ('department_id')::uuid IS NULL
+        OR e.department_id = sqlc.narg ('department_id')::uuid
+        OR (
+            sqlc.arg ('include_sub_departments')::boolean
+            AND e.department_id IN (
+                WITH RECURSIVE
+                    dept_tree AS (
+                        SELECT d1.id, ARRAY[d1.id]::uuid[] AS path
+                        FROM departments d1
+                        WHERE
+                            d1.tenant_id = $1
+                            AND d1.id = sqlc.narg ('department_id')::uuid
+                        UNION ALL
+                        SELECT d2.id, dt.path || d2.id
+                        FROM departments d2
+                            INNER JOIN dept_tree dt ON d2.parent_department_id = dt.id
+                        WHERE
+                            d2.tenant_id = $1
+                            AND NOT d2.id = ANY (dt.path)
+                    )
+                SELECT id
+                FROM dept_tree
+            )
+        )
+    )
+ORDER BY d.name NULLS LAST, l.expires_on, e.last_name;
@@ -0,0 +1,176 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO
+    webhook_endpoints (
+        id,
+        tenant_id,
+        url,
+        description,
+        secret,
+        events,
+        is_active,
+        created_by_user_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints WHERE tenant_id = $1 AND id = $2 LIMIT 1;
+
+-- name: ListWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+WHERE
+    tenant_id = $1
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountWebhookEndpoints :one
+SELECT count(*) FROM webhook_endpoints WHERE tenant_id = $1;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET
+    url = $3,
+    description = $4,
+    events = $5,
+    is_active = $6,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: SetWebhookEndpointSecret :one
+UPDATE webhook_endpoints
+SET
+    secret = $3,
+    updated_at = NOW()
+WHERE
+    tenant_id = $1
+    AND id = $2
+RETURNING
+    *;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints WHERE tenant_id = $1 AND id = $2;
+
+-- name: ListSubscribedWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+WHERE
+    tenant_id = $1
+    AND is_active = TRUE
+    AND (
+        sqlc.arg ('event_type')::text = ANY (events)
+        OR '*' = ANY (events)
+    );
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO
+    webhook_deliveries (
+        id,
+        tenant_id,
+        endpoint_id,
+        event_id,
+        event_type,
+        payload,
+        replay_of_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE
+    tenant_id = $1
+    AND endpoint_id = $2
+    AND id = $3
+LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE
+    tenant_id = $1
+    AND endpoint_id = $2
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    )
+ORDER BY created_at DESC
+LIMIT sqlc.arg ('limit')
+OFFSET
+    sqlc.arg ('offset');
+
+-- name: CountWebhookDeliveries :one
+SELECT count(*)
+FROM webhook_deliveries
+WHERE
+    tenant_id = $1
+    AND endpoint_id = $2
+    AND (
+        sqlc.arg ('status')::text = ''
+        OR status = sqlc.arg ('status')::text
+    );
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    status = 'delivering',
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg ('lease_until')::timestamptz,
+    updated_at = NOW()
+WHERE
+    id IN (
+        SELECT d.id
+        FROM webhook_deliveries d
+        WHERE
+            d.status IN ('pending', 'delivering')
+            AND d.next_attempt_at <= NOW()
+        ORDER BY d.next_attempt_at
+        LIMIT sqlc.arg ('batch_size')
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING
+    *;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET
+    status = 'succeeded',
+    response_status = $2,
+    response_body = $3,
+    last_error = NULL,
+    delivered_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: RescheduleWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = 'pending',
+    response_status = $2,
+    response_body = $3,
+    last_error = $4,
+    next_attempt_at = $5,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: FailWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = 'failed',
+    response_status = $2,
+    response_body = $3,
+    last_error = $4,
+    updated_at = NOW()
+WHERE
+    id = $1;